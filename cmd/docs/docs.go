@@ -20,39 +20,63 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/products/all": {
+        "/admin/audit": {
             "get": {
-                "description": "List all available products",
+                "description": "List the most recent changes made to the products, oldest first. The history of a product includes\nthe changes of the duplicates merged into it (product.merged entries).",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Products"
+                    "Audit"
+                ],
+                "summary": "List audit entries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token",
+                        "name": "token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only the changes of this product",
+                        "name": "product_id",
+                        "in": "query"
+                    }
                 ],
-                "summary": "List all products",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/web.Response"
                         }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/products/new": {
+        "/admin/backup": {
             "post": {
-                "description": "Create a new product and store it in the database",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Take a snapshot of every product with its SHA-256 checksum. The snapshot is written to the backup\ndirectory, or downloaded if there is none or download is true.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Products"
+                    "Backups"
                 ],
-                "summary": "Create a new product",
+                "summary": "Back up the products",
                 "parameters": [
                     {
                         "type": "string",
@@ -62,30 +86,33 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "new product",
-                        "name": "newProduct",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/domain.ProductRequest"
-                        }
+                        "type": "boolean",
+                        "description": "Download the snapshot instead of writing it to the backup directory",
+                        "name": "download",
+                        "in": "query"
                     }
                 ],
                 "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.Snapshot"
+                        }
+                    },
                     "201": {
                         "description": "Created",
                         "schema": {
                             "$ref": "#/definitions/web.Response"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/web.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/web.ErrorResponse"
                         }
@@ -93,71 +120,108 @@ const docTemplate = `{
                 }
             }
         },
-        "/products/search": {
+        "/admin/expiry-subscriptions": {
             "get": {
-                "description": "Get all products with a price greater than the provided value",
+                "description": "List the subscriptions to the notifications of the stock about to expire, of a recipient or of all of them.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Products"
+                    "Expiry"
                 ],
-                "summary": "Get all products based on its price",
+                "summary": "List expiry subscriptions",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Price",
-                        "name": "priceGt",
-                        "in": "query",
+                        "type": "string",
+                        "description": "Token",
+                        "name": "token",
+                        "in": "header",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Recipient of the subscriptions, ignoring the case",
+                        "name": "recipient",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/web.Response"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/web.ErrorResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/web.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.ExpirySubscription"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/web.ErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/products/{id}": {
-            "get": {
-                "description": "Get a specific product based on its ID",
+            },
+            "post": {
+                "description": "Subscribe a recipient to a daily notification of the products and lots expiring within its window of\ndays, or the one of the server. The notifications are a signed POST to the url, like the ones of the\nwebhooks with the products.expiring event, an email if the server has an SMTP server, or both.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Products"
+                    "Expiry"
                 ],
-                "summary": "Get a specific product",
+                "summary": "Subscribe to the expiry notifications",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Product ID",
-                        "name": "id",
-                        "in": "path",
+                        "type": "string",
+                        "description": "Token",
+                        "name": "token",
+                        "in": "header",
                         "required": true
+                    },
+                    {
+                        "description": "new expiry subscription",
+                        "name": "subscription",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.ExpirySubscriptionRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/web.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/web.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.ExpirySubscription"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
@@ -166,16 +230,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/web.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/web.ErrorResponse"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/admin/expiry-subscriptions/{id}": {
             "put": {
-                "description": "Update all the fields of a product.",
+                "description": "Replace the recipient, the destinations and the window of an expiry subscription.",
                 "consumes": [
                     "application/json"
                 ],
@@ -183,9 +249,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Products"
+                    "Expiry"
                 ],
-                "summary": "Update a product",
+                "summary": "Replace an expiry subscription",
                 "parameters": [
                     {
                         "type": "string",
@@ -196,18 +262,18 @@ const docTemplate = `{
                     },
                     {
                         "type": "integer",
-                        "description": "Product ID",
+                        "description": "Expiry subscription ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "updated product",
-                        "name": "partialUpdateData",
+                        "description": "expiry subscription",
+                        "name": "subscription",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.ProductRequest"
+                            "$ref": "#/definitions/domain.ExpirySubscriptionRequest"
                         }
                     }
                 ],
@@ -215,7 +281,19 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/web.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/web.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.ExpirySubscription"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
@@ -224,6 +302,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/web.ErrorResponse"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -233,17 +317,11 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "Delete permanently a product",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
+                "description": "Stop notifying the stock about to expire to a subscription",
                 "tags": [
-                    "Products"
+                    "Expiry"
                 ],
-                "summary": "Delete a product",
+                "summary": "Delete an expiry subscription",
                 "parameters": [
                     {
                         "type": "string",
@@ -254,7 +332,7 @@ const docTemplate = `{
                     },
                     {
                         "type": "integer",
-                        "description": "Product ID",
+                        "description": "Expiry subscription ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -280,19 +358,18 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "patch": {
-                "description": "Update some product fields data",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/admin/flush": {
+            "post": {
+                "description": "Save the current products in the store file and empty the journal, instead of waiting for the periodic\nflush, like before a planned restart. Answers the products and bytes written, the journal entries and\nbytes compacted, and the duration in nanoseconds. The catalogs of the tenants are flushed as well.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Products"
+                    "Tasks"
                 ],
-                "summary": "Partially update a product",
+                "summary": "Flush the products",
                 "parameters": [
                     {
                         "type": "string",
@@ -300,23 +377,95 @@ const docTemplate = `{
                         "name": "token",
                         "in": "header",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/web.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/store.FlushStats"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
                     },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/jobs": {
+            "get": {
+                "description": "List the background jobs with their schedule, next run and the outcome of their last run",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Jobs"
+                ],
+                "summary": "List background jobs",
+                "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Product ID",
-                        "name": "id",
-                        "in": "path",
+                        "type": "string",
+                        "description": "Token",
+                        "name": "token",
+                        "in": "header",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/web.Response"
+                        }
                     },
-                    {
-                        "description": "updated product",
-                        "name": "partialUpdateData",
-                        "in": "body",
-                        "required": true,
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/domain.ProductRequest"
+                            "$ref": "#/definitions/web.ErrorResponse"
                         }
                     }
+                }
+            }
+        },
+        "/admin/loadtest": {
+            "get": {
+                "description": "Tell whether the API serves the load test dataset instead of the catalog.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Development"
+                ],
+                "summary": "Get the load test dataset status",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token",
+                        "name": "token",
+                        "in": "header",
+                        "required": true
+                    }
                 ],
                 "responses": {
                     "200": {
@@ -325,50 +474,4868 @@ const docTemplate = `{
                             "$ref": "#/definitions/web.Response"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Serve the given number of generated products, with the IDs 1 to count, instead of the catalog. The dataset\nlives in memory only: its changes are never saved nor published, and the catalog is left untouched. The\nsame seed generates the same dataset on the same day. Only available in load test mode.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Development"
+                ],
+                "summary": "Serve a load test dataset",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token",
+                        "name": "token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of products, 1000000 by default and 5000000 at most",
+                        "name": "count",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Seed of the generator",
+                        "name": "seed",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/web.Response"
+                        }
+                    },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/web.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/web.ErrorResponse"
                         }
                     }
                 }
-            }
-        }
-    },
-    "definitions": {
-        "domain.ProductRequest": {
-            "type": "object",
-            "properties": {
-                "code_value": {
-                    "type": "string",
-                    "example": "COD123"
-                },
-                "expiration": {
-                    "type": "string",
-                    "example": "25/08/2030"
-                },
-                "is_published": {
-                    "type": "boolean",
-                    "example": true
-                },
+            },
+            "delete": {
+                "description": "Discard the load test dataset and serve the catalog again.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Development"
+                ],
+                "summary": "Serve the catalog again",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token",
+                        "name": "token",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/web.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/log-level": {
+            "get": {
+                "description": "Get the lowest level of the logged messages and whether the request and response bodies are logged.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Settings"
+                ],
+                "summary": "Get the logging settings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token",
+                        "name": "token",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/web.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/logging.Options"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Change the lowest level of the logged messages, debug, info, warn or error, for example to turn on the\ndebug messages during an incident, and turn on or off the log of the request and response bodies. The\ncredential headers, like the token, and the JSON fields named in LOG_REDACT_FIELDS are redacted from the\nbodies. The omitted settings are kept. The settings last until the server restarts, which applies\nLOG_LEVEL and LOG_BODIES again, or until a SIGHUP reloads LOG_LEVEL from the environment file.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Settings"
+                ],
+                "summary": "Change the logging settings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token",
+                        "name": "token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "logging settings",
+                        "name": "settings",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.LogLevelRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/web.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/logging.Options"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/maintenance": {
+            "get": {
+                "description": "Tell whether the catalog is read-only for a maintenance, since when and why.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Settings"
+                ],
+                "summary": "Get the maintenance mode",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token",
+                        "name": "token",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/web.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/maintenance.Status"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Start or end a maintenance. During a maintenance the product changes, except the dry runs, are rejected\nwith 503 and a Retry-After header, while the reads keep working. The administration endpoints, like the\nrestores, stay available. The mode is kept until the server restarts, which applies MAINTENANCE_MODE again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Settings"
+                ],
+                "summary": "Toggle the maintenance mode",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token",
+                        "name": "token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "maintenance mode",
+                        "name": "mode",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.MaintenanceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/web.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/maintenance.Status"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/reload": {
+            "post": {
+                "description": "Reload the products from the store file, keeping the changes not yet saved in it. Nothing changes if the\nfile holds invalid products, and reloaded is false if it holds the current products.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "Reload the products",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token",
+                        "name": "token",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/web.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/reload-config": {
+            "post": {
+                "description": "Reload the settings that can change while the server runs from the environment file, without dropping\nthe connections: the token, the signing secret, the API quotas, the CORS origins and the log level.\nIf any of them is invalid, nothing changes and the invalid ones are listed in the fields of the error.\nA SIGHUP sent to the server reloads them too.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "Reload the configuration",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token",
+                        "name": "token",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/web.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler.ConfigReloadResult"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/restore": {
+            "post": {
+                "description": "Replace every product with those of a snapshot, either a file of the backup directory or an uploaded\nsnapshot. The snapshot is rejected if its checksum does not match or if its products are not valid.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Backups"
+                ],
+                "summary": "Restore the products",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token",
+                        "name": "token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Name of a snapshot file of the backup directory",
+                        "name": "file",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Uploaded snapshot, if no file is given",
+                        "name": "snapshot",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/store.Snapshot"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/web.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/reviews": {
+            "get": {
+                "description": "List every review, hidden ones included and with their moderation fields, newest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reviews"
+                ],
+                "summary": "List the reviews to moderate",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token",
+                        "name": "token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only the reviews of this product",
+                        "name": "product_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only the flagged (true) or unflagged (false) reviews",
+                        "name": "flagged",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only the hidden (true) or visible (false) reviews",
+                        "name": "hidden",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/web.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.Review"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/reviews/{id}": {
+            "patch": {
+                "description": "Flag a review for a closer look, hide it or show it again, and note why. A hidden review is left out\nof the reviews listed to the customers and of the rating of its product.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reviews"
+                ],
+                "summary": "Moderate a review",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token",
+                        "name": "token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Review ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "moderation fields to change",
+                        "name": "moderation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.ReviewModeration"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/web.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.Review"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/seed": {
+            "post": {
+                "description": "Create the given number of realistic fake products, for load testing and local development. The same seed\ngenerates the same products on the same day; a random seed is used if none is given, and returned.\nOnly available in development mode.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Development"
+                ],
+                "summary": "Generate fake products",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token",
+                        "name": "token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of products, 100 by default and 10000 at most",
+                        "name": "count",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Seed of the generator",
+                        "name": "seed",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/web.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/settings/code-format": {
+            "get": {
+                "description": "Get the format the code values of the created and updated products must follow.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Settings"
+                ],
+                "summary": "Get the code values format",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token",
+                        "name": "token",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/web.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.CodeFormat"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Change the format the code values must follow: a regular expression (pattern), or a barcode whose check\ndigit is verified (ean13, upc or gtin for both). The stored code values are only checked again when they\nchange. The format is kept until the server restarts, which applies CODE_VALUE_FORMAT again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Settings"
+                ],
+                "summary": "Change the code values format",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token",
+                        "name": "token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "code values format",
+                        "name": "format",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.CodeFormat"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/web.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.CodeFormat"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/store/stats": {
+            "get": {
+                "description": "Report the health of the data layer: the number of products, the size and last write of the store file\nand its journal, the number of entries of the indexes, and the hit ratio of the caches since the start.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "Get the store statistics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token",
+                        "name": "token",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/web.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler.StoreStats"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/tasks/apply-publish-windows": {
+            "post": {
+                "description": "Publish the drafts whose publish window opened and archive the published products whose publish window closed, without waiting for the background job. Returns the changed products.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "Apply the publish windows",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token",
+                        "name": "token",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/web.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/tasks/notify-expiring": {
+            "post": {
+                "description": "Notify the stock about to expire to every expiry subscription, without waiting for the daily job.\nReturns the number of subscriptions notified, and of the webhook notifications and emails sent.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "Notify the expiring stock",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token",
+                        "name": "token",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/web.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/expiry.Report"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/tasks/unpublish-expired": {
+            "post": {
+                "description": "Unpublish every published product whose expiration date has passed, without waiting for the nightly job. Returns the unpublished products.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "Unpublish expired products",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token",
+                        "name": "token",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/web.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/undo": {
+            "post": {
+                "description": "Revert the most recent creation, update or deletion of a product made with the credential of the\nrequest. The X-Actor header is not trusted, so it only names who made the change, and any actor with\nthe same credential can revert it. A deleted product is created again with a new ID. A product changed\nagain since then cannot be reverted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "Undo the last product change",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token",
+                        "name": "token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Name of the person or system behind the credential, only informative",
+                        "name": "X-Actor",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/web.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/undo.Result"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/usage": {
+            "get": {
+                "description": "List the requests made today with every API key and its daily quota. Keys are masked.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Usage"
+                ],
+                "summary": "List API keys usage",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token",
+                        "name": "token",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/web.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/webhooks": {
+            "get": {
+                "description": "List all registered webhooks",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "List webhooks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token",
+                        "name": "token",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/web.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Register a URL that receives a signed POST notification every time a product is created, updated or deleted. Leave events empty to receive every event type.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Register a webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token",
+                        "name": "token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "new webhook",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.WebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/web.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/webhooks/{id}": {
+            "delete": {
+                "description": "Stop notifying a webhook",
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Delete a webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token",
+                        "name": "token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "$ref": "#/definitions/web.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/debug/vars": {
+            "get": {
+                "description": "Expvar variables of the process (command line, memory stats, goroutines), the stats of the product\nstore and the rolling latency percentiles of every route",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Debug"
+                ],
+                "summary": "Runtime variables",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token",
+                        "name": "token",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/products": {
+            "get": {
+                "description": "List all available products, or only the ones matching the filter expression, like\n` + "`" + `price \u003e 100 AND quantity \u003c 5 AND status = \"published\"` + "`" + `. The comparisons (=, !=, \u003e, \u003e=,\n\u003c, \u003c= and contains) of the product fields with numbers, true or false, or quoted texts and\ndates are combined with AND, OR, NOT and parentheses.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "List all products",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter expression",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated statuses of the products listed, like draft,archived",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated storage zones of the products, like chilled,frozen",
+                        "name": "storage",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated allergens the products are free of, like milk,peanuts",
+                        "name": "excludeAllergens",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated diets the products are suitable for, like vegan,gluten-free",
+                        "name": "diet",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number, starting at 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Products per page (20 by default, 100 at most)",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ISO 4217 code of the currency the prices are converted to",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "markdown",
+                            "html"
+                        ],
+                        "type": "string",
+                        "description": "Format of the descriptions: markdown, as written (default), or html, rendered and sanitized",
+                        "name": "description_format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/web.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a new product and store it in the database",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Create a new product",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token",
+                        "name": "token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key that makes retries of the creation return the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "return=minimal to receive only the ID of the new product, handling=dry-run for a dry run",
+                        "name": "Prefer",
+                        "in": "header"
+                    },
+                    {
+                        "description": "new product",
+                        "name": "newProduct",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.Product"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only validate the change and answer the result, without applying it",
+                        "name": "dryRun",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Result of a dry run",
+                        "schema": {
+                            "$ref": "#/definitions/web.Response"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/web.Response"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "Path of the new product"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Update some fields of every product matching the filter expression, all or none of them. The\ncode value cannot be changed in batch. With dryRun, the products are only validated and the\nresponse tells how many would change.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Partially update the products matching a filter",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token",
+                        "name": "token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter expression, like the one of the products list (this or status is required)",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated statuses of the products changed, like the ones of the products list",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated storage zones of the products, like chilled,frozen",
+                        "name": "storage",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated allergens the products are free of, like milk,peanuts",
+                        "name": "excludeAllergens",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated diets the products are suitable for, like vegan,gluten-free",
+                        "name": "diet",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only validate the change and count the affected products",
+                        "name": "dryRun",
+                        "in": "query"
+                    },
+                    {
+                        "description": "updated fields",
+                        "name": "partialUpdateData",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProductRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/web.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/product.BatchResult"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/dietary-tags": {
+            "get": {
+                "description": "Get the controlled vocabulary of the allergens and diets of the nutrition facts, which are the only\nones accepted in the products and in the excludeAllergens and diet filters.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "List the dietary tags",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/web.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.DietaryTags"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/products/events": {
+            "get": {
+                "description": "Stream the created, updated and deleted products as Server-Sent Events. The event name is the change type (product.created, product.updated or product.deleted), and a merge of a duplicate is also streamed as product.merged after the deletion and update.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Stream product changes",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/events.Event"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/export": {
+            "get": {
+                "description": "Download the products catalog as a CSV or XLSX file. Accepts the same filters as the search endpoint.",
+                "produces": [
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Export products",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "xlsx"
+                        ],
+                        "type": "string",
+                        "description": "File format (csv or xlsx)",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Price",
+                        "name": "priceGt",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter expression, like the one of the products list",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated statuses of the products, like the ones of the products list",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated storage zones of the products, like chilled,frozen",
+                        "name": "storage",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated allergens the products are free of, like milk,peanuts",
+                        "name": "excludeAllergens",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated diets the products are suitable for, like vegan,gluten-free",
+                        "name": "diet",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/labels": {
+            "post": {
+                "description": "Generate a printable PDF with the shelf labels of the given products, with their name, price and the\nCode 128 barcode of their code value, laid out on A4 pages of 3 by 8 labels of 70 x 37 mm. The\nlabels follow the order of the IDs, and at most 240 of them are printed at once.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/pdf"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Print shelf labels",
+                "parameters": [
+                    {
+                        "description": "IDs of the labeled products",
+                        "name": "labels",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.LabelsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/search": {
+            "get": {
+                "description": "Get all products with a price greater than the provided value, or the products\nmatching the text in q, most relevant first and tolerating typos. Both filters can\nbe combined. An empty list is returned when no product matches, unless the server\nkeeps the legacy 404.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Search products",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Text searched in the name, category and code value",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Price, required without q",
+                        "name": "priceGt",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated allergens the products are free of, like milk,peanuts",
+                        "name": "excludeAllergens",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated diets the products are suitable for, like vegan,gluten-free",
+                        "name": "diet",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number, starting at 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Products per page (20 by default, 100 at most)",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ISO 4217 code of the currency the prices are converted to",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "markdown",
+                            "html"
+                        ],
+                        "type": "string",
+                        "description": "Format of the descriptions: markdown, as written (default), or html, rendered and sanitized",
+                        "name": "description_format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/web.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/stream": {
+            "get": {
+                "description": "Stream the products catalog as newline-delimited JSON using chunked transfer encoding. Accepts the same filters as the search endpoint.",
+                "produces": [
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Stream products",
+                "parameters": [
+                    {
+                        "type": "number",
+                        "description": "Price",
+                        "name": "priceGt",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter expression, like the one of the products list",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated statuses of the products, like the ones of the products list",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated storage zones of the products, like chilled,frozen",
+                        "name": "storage",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated allergens the products are free of, like milk,peanuts",
+                        "name": "excludeAllergens",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated diets the products are suitable for, like vegan,gluten-free",
+                        "name": "diet",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.ProductV1"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/suggest": {
+            "get": {
+                "description": "Suggest the names of the published products with a word starting with the typed text, ignoring case\nand accents. The names starting with the text come first, then the most viewed ones.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Suggest product names",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Typed text",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of suggestions (10 by default, 50 at most)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/web.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/suggest.Suggestion"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}": {
+            "get": {
+                "description": "Get a specific product based on its ID. If prices can be scheduled, the product includes its\nupcoming_prices, sorted by effective date. If the products can be reviewed, the product includes the\nrating of its visible reviews, when it has any.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Get a specific product",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ISO 4217 code of the currency the price is converted to",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "markdown",
+                            "html"
+                        ],
+                        "type": "string",
+                        "description": "Format of the descriptions: markdown, as written (default), or html, rendered and sanitized",
+                        "name": "description_format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/web.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update all the fields of a product.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Update a product",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token",
+                        "name": "token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "updated product",
+                        "name": "newProductData",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.Product"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only validate the change and answer the result, without applying it",
+                        "name": "dryRun",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/web.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete permanently a product",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Delete a product",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token",
+                        "name": "token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only validate the change and answer the result, without applying it",
+                        "name": "dryRun",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Result of a dry run",
+                        "schema": {
+                            "$ref": "#/definitions/web.Response"
+                        }
+                    },
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "$ref": "#/definitions/web.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Update some product fields data",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Partially update a product",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token",
+                        "name": "token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "updated product",
+                        "name": "partialUpdateData",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProductRequest"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only validate the change and answer the result, without applying it",
+                        "name": "dryRun",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/web.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}/clone": {
+            "post": {
+                "description": "Create a copy of a product with a new ID. The body must have the code value of the copy, and the\nother fields present in it replace the copied ones.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Clone a product",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token",
+                        "name": "token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key that makes retries of the creation return the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID of the copied product",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "code value of the copy and changed fields",
+                        "name": "changes",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProductRequest"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only validate the change and answer the result, without applying it",
+                        "name": "dryRun",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Result of a dry run",
+                        "schema": {
+                            "$ref": "#/definitions/web.Response"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/web.Response"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "Path of the new product"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}/lots": {
+            "get": {
+                "description": "List the lots of the stock of a product, first-expire-first-out. Given a quantity, it also\nsuggests the lots to pick it from: the units are taken from the lots expiring first that have not\nexpired yet, and the ones they cannot cover are answered as missing.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "List the lots of a product",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Units to pick from the lots",
+                        "name": "quantity",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/web.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler.LotsResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace the lots of the stock of a product, whose quantities add up to the quantity of the product\nfrom then on. The numbers are unique in the product, and an empty list stops tracking the lots,\nkeeping the quantity.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Set the lots of a product",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token",
+                        "name": "token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Lots",
+                        "name": "lots",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Lot"
+                            }
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only validate the change and answer the result, without applying it",
+                        "name": "dryRun",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/web.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.Lot"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}/merge/{otherId}": {
+            "post": {
+                "description": "Consolidate a duplicate into a product, like the possible duplicates reported. The product keeps its\nID, code value and fields, gets the stock of the duplicate and the category, description and\ntranslations it has none of, and the duplicate is deleted. In the main catalog, the scheduled prices,\nreviews and views of the duplicate are moved to the product. The merge is audited as product.merged, with both products as\nthey were, and the audit history of the duplicate is kept in the one of the product.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Merge a duplicate into a product",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token",
+                        "name": "token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID of the product kept",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID of the duplicate",
+                        "name": "otherId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only validate the change and answer the result, without applying it",
+                        "name": "dryRun",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/web.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}/nutrition": {
+            "get": {
+                "description": "Get the calories, nutrients and allergens of a product, per 100 g or 100 mL of it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Get the nutrition facts of a product",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/web.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.Nutrition"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Create or replace the nutrition facts of a product, per 100 g or 100 mL of it. The nutrients are in\ngrams, the saturated fat and the sugars cannot be more than the fat and the carbohydrates, and the\nallergens and diets are the ones of /products/dietary-tags. The products with nutrition facts can be\nfiltered by their allergens and diets, with the excludeAllergens and diet parameters or with filter\nexpressions like ` + "`" + `nutrition = true AND NOT allergens contains \"milk\"` + "`" + `.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Set the nutrition facts of a product",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token",
+                        "name": "token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Nutrition facts",
+                        "name": "nutrition",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.Nutrition"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only validate the change and answer the result, without applying it",
+                        "name": "dryRun",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/web.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.Nutrition"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}/prices": {
+            "get": {
+                "description": "List the prices scheduled for a product, sorted by effective date.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Prices"
+                ],
+                "summary": "List the upcoming prices of a product",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/web.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.ScheduledPrice"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Schedule a future price of a product. A background job makes it the current price of the product\nat its effective date, as a regular update. Without currency, the price keeps the currency of the product.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Prices"
+                ],
+                "summary": "Schedule a price",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token",
+                        "name": "token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "scheduled price",
+                        "name": "price",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.ScheduledPriceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/web.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.ScheduledPrice"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}/prices/{priceId}": {
+            "delete": {
+                "description": "Cancel a price scheduled for a product before its effective date",
+                "tags": [
+                    "Prices"
+                ],
+                "summary": "Cancel a scheduled price",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token",
+                        "name": "token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Scheduled price ID",
+                        "name": "priceId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "$ref": "#/definitions/web.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}/related": {
+            "get": {
+                "description": "List the published products related to a product, most similar first, for a \"you may also like\"\nsection. The products are scored by their shared category, the shared words of their names\nand a price within 25% in the same currency; a similar price alone does not relate them. The\nproducts outside their publish window are left out.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "List the related products",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of products (5 by default, 20 at most)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ISO 4217 code of the currency the prices are converted to",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "markdown",
+                            "html"
+                        ],
+                        "type": "string",
+                        "description": "Format of the descriptions: markdown, as written (default), or html, rendered and sanitized",
+                        "name": "description_format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/web.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}/reviews": {
+            "get": {
+                "description": "List the reviews of a product, newest first. The reviews hidden by the administrators are left out.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reviews"
+                ],
+                "summary": "List the reviews of a product",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number, starting at 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of reviews per page",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/web.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/domain.Review"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Post the review of a product, with a rating from 1 to 5. The review counts in the rating of the\nproduct at once, unless an administrator hides it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reviews"
+                ],
+                "summary": "Review a product",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "review",
+                        "name": "review",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.ReviewRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/web.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.Review"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}/translations": {
+            "get": {
+                "description": "Get the name and description of a product in every locale other than the base one, by locale.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "List the translations of a product",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/web.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object",
+                                            "additionalProperties": {
+                                                "$ref": "#/definitions/domain.Translation"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}/translations/{locale}": {
+            "put": {
+                "description": "Create or replace the name and description of a product in a locale other than the base one.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Set a translation of a product",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token",
+                        "name": "token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Language tag of the locale, like es or pt-BR",
+                        "name": "locale",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Translation",
+                        "name": "translation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.Translation"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only validate the change and answer the result, without applying it",
+                        "name": "dryRun",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/web.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.Translation"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete the name and description of a product in a locale, which falls back to the base one.",
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Delete a translation of a product",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token",
+                        "name": "token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Language tag of the locale, like es or pt-BR",
+                        "name": "locale",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only validate the change and answer the result, without applying it",
+                        "name": "dryRun",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Result of a dry run",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/web.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/domain.Translation"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "$ref": "#/definitions/web.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/possible-duplicates": {
+            "get": {
+                "description": "List the pairs of products of the main catalog that are likely the same SKU entered twice: products\nwith the same price and currency and similar names, like names with a typo or with the words in another\norder. The most similar pairs come first, and the product of every pair is the oldest one, usually the\none to keep when merging them.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reports"
+                ],
+                "summary": "List the possible duplicate products",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token",
+                        "name": "token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of pairs (10 by default, 100 at most)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum similarity of the names, above 0 and up to 1 (0.8 by default)",
+                        "name": "threshold",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/web.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/dedupe.Pair"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/searches": {
+            "get": {
+                "description": "Summarize the text searches of the customers: the most searched queries and the queries whose searches\nfound no product, which merchandising should look into. Queries differing only in case, accents or\nspacing are the same. Only the most recent searches are kept, in memory, and nothing about the\ncustomers; the searches of the clients sending \"DNT: 1\" or \"Sec-GPC: 1\" are not recorded.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reports"
+                ],
+                "summary": "Summarize the searches",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token",
+                        "name": "token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of queries of each list (10 by default, 100 at most)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only the searches of the last days (1 to 365); every search kept by default",
+                        "name": "days",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/web.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/analytics.Summary"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/top-products": {
+            "get": {
+                "description": "List the most viewed products of the main catalog, most viewed first, with their number of views.\nThe views of the clients sending \"DNT: 1\" or \"Sec-GPC: 1\" are not counted, and only the number of\nviews of every product is kept. The deleted products are left out.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reports"
+                ],
+                "summary": "List the most viewed products",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token",
+                        "name": "token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of products (10 by default, 100 at most)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/web.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/handler.TopProduct"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/ws": {
+            "get": {
+                "description": "Open a websocket that receives the product change events. Clients choose what they receive by sending {\"action\": \"subscribe\", \"topic\": \"...\"} messages, where the topic is \"products\" (every change), \"product:{id}\" or \"category:{category}\".",
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Live catalog updates",
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols"
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "analytics.QueryStats": {
+            "type": "object",
+            "properties": {
+                "last_searched": {
+                    "type": "string"
+                },
+                "searches": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "term": {
+                    "type": "string",
+                    "example": "pineapple"
+                },
+                "zero_results": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "analytics.Summary": {
+            "type": "object",
+            "properties": {
+                "searches": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "since": {
+                    "type": "string"
+                },
+                "top_queries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/analytics.QueryStats"
+                    }
+                },
+                "zero_result_queries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/analytics.QueryStats"
+                    }
+                },
+                "zero_result_searches": {
+                    "type": "integer",
+                    "example": 9
+                }
+            }
+        },
+        "cache.Stats": {
+            "type": "object",
+            "properties": {
+                "hit_ratio": {
+                    "type": "number"
+                },
+                "hits": {
+                    "type": "integer"
+                },
+                "misses": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dedupe.Pair": {
+            "type": "object",
+            "properties": {
+                "duplicate": {
+                    "$ref": "#/definitions/domain.Product"
+                },
+                "product": {
+                    "$ref": "#/definitions/domain.Product"
+                },
+                "similarity": {
+                    "type": "number",
+                    "example": 0.92
+                }
+            }
+        },
+        "domain.CodeFormat": {
+            "type": "object",
+            "properties": {
+                "format": {
+                    "type": "string",
+                    "example": "pattern"
+                },
+                "pattern": {
+                    "type": "string",
+                    "example": "^[A-Za-z0-9][A-Za-z0-9_-]{0,31}$"
+                }
+            }
+        },
+        "domain.DietaryTags": {
+            "type": "object",
+            "properties": {
+                "allergens": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "diets": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "domain.ExpirySubscription": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "madrid@example.com"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "recipient": {
+                    "type": "string",
+                    "example": "Madrid warehouse"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com/hooks/expiry"
+                },
+                "window_days": {
+                    "description": "Days ahead the expirations are notified, the default window of the server if 0",
+                    "type": "integer",
+                    "example": 7
+                }
+            }
+        },
+        "domain.ExpirySubscriptionRequest": {
+            "type": "object",
+            "required": [
+                "recipient"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "madrid@example.com"
+                },
+                "recipient": {
+                    "type": "string",
+                    "example": "Madrid warehouse"
+                },
+                "secret": {
+                    "type": "string",
+                    "example": "s3cr3t"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com/hooks/expiry"
+                },
+                "window_days": {
+                    "type": "integer",
+                    "maximum": 365,
+                    "minimum": 0,
+                    "example": 7
+                }
+            }
+        },
+        "domain.Lot": {
+            "type": "object",
+            "properties": {
+                "expiration": {
+                    "type": "string",
+                    "example": "01/06/2030"
+                },
+                "number": {
+                    "type": "string",
+                    "example": "L2301"
+                },
+                "quantity": {
+                    "type": "integer",
+                    "example": 40
+                }
+            }
+        },
+        "domain.Nutrition": {
+            "type": "object",
+            "properties": {
+                "allergens": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "sulphites"
+                    ]
+                },
+                "calories": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 50
+                },
+                "carbohydrates": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 13.1
+                },
+                "diets": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "vegan"
+                    ]
+                },
+                "fat": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 0.1
+                },
+                "fiber": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 1.4
+                },
+                "protein": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 0.5
+                },
+                "salt": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 0.01
+                },
+                "saturated_fat": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 0
+                },
+                "sugars": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 9.9
+                }
+            }
+        },
+        "domain.Product": {
+            "type": "object",
+            "required": [
+                "code_value",
+                "expiration",
+                "name",
+                "price",
+                "quantity"
+            ],
+            "properties": {
+                "category": {
+                    "type": "string",
+                    "example": "fruits"
+                },
+                "code_value": {
+                    "type": "string",
+                    "example": "COD123"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Sweet pineapple from Costa Rica"
+                },
+                "expiration": {
+                    "type": "string",
+                    "example": "25/08/2030"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "lots": {
+                    "description": "Lots of the stock, first-expire-first-out, which the quantity is the sum of if there are any",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Lot"
+                    }
+                },
+                "max_temperature": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 6
+                },
+                "min_temperature": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 2
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Pineapple"
+                },
+                "nutrition": {
+                    "description": "Nutrition facts, nil if they are unknown",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.Nutrition"
+                        }
+                    ]
+                },
+                "pack_size": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 1.5
+                },
+                "price": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 299
+                },
+                "price_per_unit": {
+                    "description": "Price of one unit of measure, like the price per kg, set on the responses only and never stored",
+                    "type": "number",
+                    "format": "float64",
+                    "example": 199.33
+                },
+                "publish_from": {
+                    "description": "Publish window: the product is sold from PublishFrom until PublishUntil, if they are set",
+                    "type": "string",
+                    "example": "2030-01-01T09:00:00Z"
+                },
+                "publish_until": {
+                    "type": "string",
+                    "example": "2030-03-01T00:00:00Z"
+                },
+                "quantity": {
+                    "type": "integer",
+                    "example": 100
+                },
+                "rating": {
+                    "description": "Summary of the visible reviews, set on the responses only and never stored",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.Rating"
+                        }
+                    ]
+                },
+                "status": {
+                    "enum": [
+                        "draft",
+                        "published",
+                        "archived",
+                        "discontinued"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.Status"
+                        }
+                    ],
+                    "example": "published"
+                },
+                "storage": {
+                    "description": "Storage requirements: the zone the product is kept in, and the range of temperatures in °C it needs",
+                    "enum": [
+                        "ambient",
+                        "chilled",
+                        "frozen"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.StorageZone"
+                        }
+                    ],
+                    "example": "chilled"
+                },
+                "translations": {
+                    "description": "Name and description in other locales than the base one, by locale",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/domain.Translation"
+                    }
+                },
+                "unit": {
+                    "description": "Content of the package: PackSize of the Unit of measure, like 0.5 kg",
+                    "enum": [
+                        "kg",
+                        "L",
+                        "unit"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.Unit"
+                        }
+                    ],
+                    "example": "kg"
+                }
+            }
+        },
+        "domain.ProductRequest": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string",
+                    "example": "fruits"
+                },
+                "code_value": {
+                    "type": "string",
+                    "example": "COD123"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Sweet pineapple from Costa Rica"
+                },
+                "expiration": {
+                    "type": "string",
+                    "example": "25/08/2030"
+                },
+                "lots": {
+                    "description": "Lots of the stock, replaced as a whole, or removed if the list is empty",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Lot"
+                    }
+                },
+                "max_temperature": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 6
+                },
+                "min_temperature": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 2
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Pineapple"
+                },
+                "nutrition": {
+                    "description": "Nutrition facts, replaced as a whole",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.Nutrition"
+                        }
+                    ]
+                },
+                "pack_size": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 1.5
+                },
+                "price": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 299
+                },
+                "publish_from": {
+                    "description": "Bounds of the publish window, removed if they are the zero time",
+                    "type": "string",
+                    "example": "2030-01-01T09:00:00Z"
+                },
+                "publish_until": {
+                    "type": "string",
+                    "example": "2030-03-01T00:00:00Z"
+                },
+                "quantity": {
+                    "type": "integer",
+                    "example": 100
+                },
+                "status": {
+                    "enum": [
+                        "draft",
+                        "published",
+                        "archived",
+                        "discontinued"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.Status"
+                        }
+                    ],
+                    "example": "published"
+                },
+                "storage": {
+                    "description": "Storage requirements, removed along with the temperatures if the zone is empty",
+                    "enum": [
+                        "ambient",
+                        "chilled",
+                        "frozen"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.StorageZone"
+                        }
+                    ],
+                    "example": "chilled"
+                },
+                "translations": {
+                    "description": "Translations set, or removed if null, by locale",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/domain.Translation"
+                    }
+                },
+                "unit": {
+                    "description": "Content of the package, removed if they are empty",
+                    "enum": [
+                        "kg",
+                        "L",
+                        "unit"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.Unit"
+                        }
+                    ],
+                    "example": "kg"
+                }
+            }
+        },
+        "domain.ProductV1": {
+            "type": "object",
+            "required": [
+                "code_value",
+                "expiration",
+                "name",
+                "price",
+                "quantity"
+            ],
+            "properties": {
+                "category": {
+                    "type": "string",
+                    "example": "fruits"
+                },
+                "code_value": {
+                    "type": "string",
+                    "example": "COD123"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Sweet pineapple from Costa Rica"
+                },
+                "expiration": {
+                    "type": "string",
+                    "example": "25/08/2030"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "is_published": {
+                    "description": "Deprecated: use the status instead. True only for the published products",
+                    "type": "boolean",
+                    "x-deprecated": "true",
+                    "example": true
+                },
+                "lots": {
+                    "description": "Lots of the stock, first-expire-first-out, which the quantity is the sum of if there are any",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Lot"
+                    }
+                },
+                "max_temperature": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 6
+                },
+                "min_temperature": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 2
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Pineapple"
+                },
+                "nutrition": {
+                    "description": "Nutrition facts, nil if they are unknown",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.Nutrition"
+                        }
+                    ]
+                },
+                "pack_size": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 1.5
+                },
+                "price": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 299
+                },
+                "price_per_unit": {
+                    "description": "Price of one unit of measure, like the price per kg, set on the responses only and never stored",
+                    "type": "number",
+                    "format": "float64",
+                    "example": 199.33
+                },
+                "publish_from": {
+                    "description": "Publish window: the product is sold from PublishFrom until PublishUntil, if they are set",
+                    "type": "string",
+                    "example": "2030-01-01T09:00:00Z"
+                },
+                "publish_until": {
+                    "type": "string",
+                    "example": "2030-03-01T00:00:00Z"
+                },
+                "quantity": {
+                    "type": "integer",
+                    "example": 100
+                },
+                "rating": {
+                    "description": "Summary of the visible reviews, set on the responses only and never stored",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.Rating"
+                        }
+                    ]
+                },
+                "status": {
+                    "enum": [
+                        "draft",
+                        "published",
+                        "archived",
+                        "discontinued"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.Status"
+                        }
+                    ],
+                    "example": "published"
+                },
+                "storage": {
+                    "description": "Storage requirements: the zone the product is kept in, and the range of temperatures in °C it needs",
+                    "enum": [
+                        "ambient",
+                        "chilled",
+                        "frozen"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.StorageZone"
+                        }
+                    ],
+                    "example": "chilled"
+                },
+                "translations": {
+                    "description": "Name and description in other locales than the base one, by locale",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/domain.Translation"
+                    }
+                },
+                "unit": {
+                    "description": "Content of the package: PackSize of the Unit of measure, like 0.5 kg",
+                    "enum": [
+                        "kg",
+                        "L",
+                        "unit"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.Unit"
+                        }
+                    ],
+                    "example": "kg"
+                }
+            }
+        },
+        "domain.Rating": {
+            "type": "object",
+            "properties": {
+                "average": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 4.33
+                },
+                "count": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "domain.Review": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "type": "string",
+                    "example": "Jane"
+                },
+                "comment": {
+                    "type": "string",
+                    "example": "Sweet and juicy"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "flagged": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "hidden": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "moderation_note": {
+                    "type": "string",
+                    "example": "Off-topic"
+                },
+                "product_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "rating": {
+                    "type": "integer",
+                    "example": 4
+                }
+            }
+        },
+        "domain.ReviewModeration": {
+            "type": "object",
+            "properties": {
+                "flagged": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "hidden": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "moderation_note": {
+                    "type": "string",
+                    "example": "Off-topic"
+                }
+            }
+        },
+        "domain.ReviewRequest": {
+            "type": "object",
+            "required": [
+                "author",
+                "rating"
+            ],
+            "properties": {
+                "author": {
+                    "type": "string",
+                    "example": "Jane"
+                },
+                "comment": {
+                    "type": "string",
+                    "example": "Sweet and juicy"
+                },
+                "rating": {
+                    "type": "integer",
+                    "example": 4
+                }
+            }
+        },
+        "domain.ScheduledPrice": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "effective_from": {
+                    "type": "string",
+                    "example": "2030-01-01T00:00:00Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "price": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 349
+                },
+                "product_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "domain.ScheduledPriceRequest": {
+            "type": "object",
+            "required": [
+                "effective_from",
+                "price"
+            ],
+            "properties": {
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "effective_from": {
+                    "type": "string",
+                    "example": "2030-01-01T00:00:00Z"
+                },
+                "price": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 349
+                }
+            }
+        },
+        "domain.Status": {
+            "type": "string",
+            "enum": [
+                "draft",
+                "published",
+                "archived",
+                "discontinued"
+            ],
+            "x-enum-varnames": [
+                "StatusDraft",
+                "StatusPublished",
+                "StatusArchived",
+                "StatusDiscontinued"
+            ]
+        },
+        "domain.StorageZone": {
+            "type": "string",
+            "enum": [
+                "ambient",
+                "chilled",
+                "frozen"
+            ],
+            "x-enum-varnames": [
+                "StorageAmbient",
+                "StorageChilled",
+                "StorageFrozen"
+            ]
+        },
+        "domain.Translation": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "Piña dulce de Costa Rica"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Piña"
+                }
+            }
+        },
+        "domain.Unit": {
+            "type": "string",
+            "enum": [
+                "kg",
+                "L",
+                "unit"
+            ],
+            "x-enum-varnames": [
+                "UnitKilogram",
+                "UnitLiter",
+                "UnitPiece"
+            ]
+        },
+        "domain.WebhookRequest": {
+            "type": "object",
+            "required": [
+                "secret",
+                "url"
+            ],
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "product.created"
+                    ]
+                },
+                "secret": {
+                    "type": "string",
+                    "example": "s3cr3t"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com/hooks/products"
+                }
+            }
+        },
+        "events.Event": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "type": "string"
+                },
+                "data": {},
+                "id": {
+                    "type": "integer"
+                },
+                "previous": {},
+                "timestamp": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "expiry.Report": {
+            "type": "object",
+            "properties": {
+                "emails": {
+                    "type": "integer"
+                },
+                "notified": {
+                    "type": "integer"
+                },
+                "webhooks": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.ConfigReloadResult": {
+            "type": "object",
+            "properties": {
+                "reloaded": {
+                    "description": "Names of the reloaded settings",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "log_level",
+                        "quotas"
+                    ]
+                }
+            }
+        },
+        "handler.LabelsRequest": {
+            "type": "object",
+            "properties": {
+                "ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1,
+                        2,
+                        3
+                    ]
+                }
+            }
+        },
+        "handler.LogLevelRequest": {
+            "type": "object",
+            "properties": {
+                "bodies": {
+                    "type": "boolean"
+                },
+                "level": {
+                    "type": "string",
+                    "example": "debug"
+                }
+            }
+        },
+        "handler.LotsResponse": {
+            "type": "object",
+            "properties": {
+                "lots": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Lot"
+                    }
+                },
+                "missing": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "picks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Lot"
+                    }
+                }
+            }
+        },
+        "handler.MaintenanceRequest": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "retry_after": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.StoreStats": {
+            "type": "object",
+            "properties": {
+                "caches": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/cache.Stats"
+                    }
+                },
+                "file": {
+                    "$ref": "#/definitions/store.FileStats"
+                },
+                "indexes": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "records": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.TopProduct": {
+            "type": "object",
+            "required": [
+                "code_value",
+                "expiration",
+                "name",
+                "price",
+                "quantity"
+            ],
+            "properties": {
+                "category": {
+                    "type": "string",
+                    "example": "fruits"
+                },
+                "code_value": {
+                    "type": "string",
+                    "example": "COD123"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Sweet pineapple from Costa Rica"
+                },
+                "expiration": {
+                    "type": "string",
+                    "example": "25/08/2030"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "lots": {
+                    "description": "Lots of the stock, first-expire-first-out, which the quantity is the sum of if there are any",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Lot"
+                    }
+                },
+                "max_temperature": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 6
+                },
+                "min_temperature": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 2
+                },
                 "name": {
                     "type": "string",
                     "example": "Pineapple"
                 },
+                "nutrition": {
+                    "description": "Nutrition facts, nil if they are unknown",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.Nutrition"
+                        }
+                    ]
+                },
+                "pack_size": {
+                    "type": "number",
+                    "format": "float64",
+                    "example": 1.5
+                },
                 "price": {
                     "type": "number",
                     "format": "float64",
                     "example": 299
                 },
+                "price_per_unit": {
+                    "description": "Price of one unit of measure, like the price per kg, set on the responses only and never stored",
+                    "type": "number",
+                    "format": "float64",
+                    "example": 199.33
+                },
+                "publish_from": {
+                    "description": "Publish window: the product is sold from PublishFrom until PublishUntil, if they are set",
+                    "type": "string",
+                    "example": "2030-01-01T09:00:00Z"
+                },
+                "publish_until": {
+                    "type": "string",
+                    "example": "2030-03-01T00:00:00Z"
+                },
                 "quantity": {
                     "type": "integer",
                     "example": 100
+                },
+                "rating": {
+                    "description": "Summary of the visible reviews, set on the responses only and never stored",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.Rating"
+                        }
+                    ]
+                },
+                "status": {
+                    "enum": [
+                        "draft",
+                        "published",
+                        "archived",
+                        "discontinued"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.Status"
+                        }
+                    ],
+                    "example": "published"
+                },
+                "storage": {
+                    "description": "Storage requirements: the zone the product is kept in, and the range of temperatures in °C it needs",
+                    "enum": [
+                        "ambient",
+                        "chilled",
+                        "frozen"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.StorageZone"
+                        }
+                    ],
+                    "example": "chilled"
+                },
+                "translations": {
+                    "description": "Name and description in other locales than the base one, by locale",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/domain.Translation"
+                    }
+                },
+                "unit": {
+                    "description": "Content of the package: PackSize of the Unit of measure, like 0.5 kg",
+                    "enum": [
+                        "kg",
+                        "L",
+                        "unit"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.Unit"
+                        }
+                    ],
+                    "example": "kg"
+                },
+                "views": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "logging.Options": {
+            "type": "object",
+            "properties": {
+                "bodies": {
+                    "type": "boolean"
+                },
+                "level": {
+                    "type": "string",
+                    "example": "info"
+                }
+            }
+        },
+        "maintenance.Status": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "retry_after": {
+                    "type": "integer"
+                },
+                "since": {
+                    "type": "string"
+                }
+            }
+        },
+        "product.BatchResult": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "description": "Number and IDs of the changed products, or of the ones that would change in a dry run",
+                    "type": "integer",
+                    "example": 2
+                },
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "store.FileStats": {
+            "type": "object",
+            "properties": {
+                "bytes": {
+                    "type": "integer"
+                },
+                "journal_bytes": {
+                    "type": "integer"
+                },
+                "journal_entries": {
+                    "type": "integer"
+                },
+                "last_write": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                }
+            }
+        },
+        "store.FlushStats": {
+            "type": "object",
+            "properties": {
+                "bytes": {
+                    "type": "integer"
+                },
+                "duration_ns": {
+                    "type": "integer"
+                },
+                "journal_bytes": {
+                    "type": "integer"
+                },
+                "journal_entries": {
+                    "type": "integer"
+                },
+                "records": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.Snapshot": {
+            "type": "object",
+            "properties": {
+                "checksum": {
+                    "type": "string"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "products": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Product"
+                    }
+                }
+            }
+        },
+        "suggest.Suggestion": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "Milk"
+                },
+                "products": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "views": {
+                    "type": "integer",
+                    "example": 40
+                }
+            }
+        },
+        "undo.Operation": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "type": "string"
+                },
+                "previous": {
+                    "$ref": "#/definitions/domain.Product"
+                },
+                "product": {
+                    "$ref": "#/definitions/domain.Product"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "timestamp": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "undo.Result": {
+            "type": "object",
+            "properties": {
+                "operation": {
+                    "$ref": "#/definitions/undo.Operation"
+                },
+                "product": {
+                    "$ref": "#/definitions/domain.Product"
                 }
             }
         },
@@ -378,6 +5345,15 @@ const docTemplate = `{
                 "code": {
                     "type": "string"
                 },
+                "error_code": {
+                    "type": "string"
+                },
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/web.FieldError"
+                    }
+                },
                 "message": {
                     "type": "string"
                 },
@@ -386,10 +5362,53 @@ const docTemplate = `{
                 }
             }
         },
+        "web.FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "rule": {
+                    "type": "string"
+                }
+            }
+        },
+        "web.Meta": {
+            "type": "object",
+            "properties": {
+                "first": {
+                    "type": "string"
+                },
+                "last": {
+                    "type": "string"
+                },
+                "next": {
+                    "type": "string"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "page_size": {
+                    "type": "integer"
+                },
+                "prev": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
         "web.Response": {
             "type": "object",
             "properties": {
-                "data": {}
+                "data": {},
+                "meta": {
+                    "$ref": "#/definitions/web.Meta"
+                }
             }
         }
     }
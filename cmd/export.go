@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"github.com/JoseObreque/go-web/pkg/export"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+)
+
+// The newExportCommand function returns the command that writes the products as CSV, NDJSON or XLSX.
+func newExportCommand() *cobra.Command {
+	var format, output string
+	command := &cobra.Command{
+		Use:   "export",
+		Short: "Export the products as CSV, NDJSON or XLSX",
+		Long: `Write every product of the store file, with the changes not saved yet, in the given format. The
+products are written to the standard output unless an output file is given.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			products, err := loadStoreProducts()
+			if err != nil {
+				return err
+			}
+
+			var write func(io.Writer) error
+			switch format {
+			case "csv":
+				write = func(w io.Writer) error { return export.WriteCSV(w, export.Header, products) }
+			case "ndjson":
+				write = func(w io.Writer) error { return export.WriteNDJSON(w, products) }
+			case "xlsx":
+				write = func(w io.Writer) error { return export.WriteXLSX(w, export.Header, products) }
+			default:
+				return fmt.Errorf("unknown format %q, expected csv, ndjson or xlsx", format)
+			}
+
+			if output == "" {
+				return write(cmd.OutOrStdout())
+			}
+			file, err := os.Create(output)
+			if err != nil {
+				return err
+			}
+			if err = write(file); err != nil {
+				file.Close()
+				return err
+			}
+			return file.Close()
+		},
+	}
+	command.Flags().StringVarP(&format, "format", "f", "csv", "file format: csv, ndjson or xlsx")
+	command.Flags().StringVarP(&output, "output", "o", "", "output file, the standard output by default")
+	return command
+}
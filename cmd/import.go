@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/JoseObreque/go-web/pkg/export"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+/*
+The newImportCommand function returns the command that creates the products of a CSV file. It
+shares the service of the API, so the imported products follow the same rules.
+*/
+func newImportCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "import file.csv",
+		Short: "Import the products of a CSV file",
+		Long: `Create a product for every record of a CSV file with the columns of the CSV export. The products
+get new IDs, and nothing is imported if any record is invalid or uses a code value already taken.
+Run it while the server is stopped, or with STORE_WATCH=true so the server reloads the products.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+
+			records, err := export.ReadCSV(file)
+			if err != nil {
+				return err
+			}
+			imported, err := importProducts(records)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%d products imported into %s\n", imported, storeFile())
+			return nil
+		},
+	}
+}
+
+/*
+The importProducts function creates the given products in the store file and returns how many were
+created. The store is only saved once every product has been created.
+*/
+func importProducts(records []domain.Product) (int, error) {
+	jsonStore, journal, err := openStore()
+	if err != nil {
+		return 0, err
+	}
+	defer journal.Close()
+
+	state, err := loadState(jsonStore, journal)
+	if err != nil {
+		return 0, err
+	}
+	service := product.NewService(product.NewRepository(state.Products), nil).(*product.ServiceImpl)
+	service.ReserveIds(state.LastId)
+
+	for i, record := range records {
+		if _, err = domain.ParseExpiration(record.Expiration); err != nil {
+			return 0, fmt.Errorf("record %d: %w", i+1, err)
+		}
+		if _, err = service.Create(record); err != nil {
+			return 0, fmt.Errorf("record %d: %w", i+1, err)
+		}
+	}
+	if err = product.ValidateCatalog(service.GetAll()).Err(); err != nil {
+		return 0, err
+	}
+
+	// The journal is replayed in the saved products, so it is emptied along with the save
+	err = journal.Compact(func() error {
+		return jsonStore.SaveState(serviceState(service))
+	})
+	return len(records), err
+}
@@ -1,18 +1,24 @@
 package main
 
 import (
-	docs "github.com/JoseObreque/go-web/cmd/docs"
-	"github.com/JoseObreque/go-web/cmd/server/handler"
-	"github.com/JoseObreque/go-web/cmd/server/middleware"
+	"encoding/base64"
+	"errors"
+	"github.com/JoseObreque/go-web/cmd/server/dataset"
+	"github.com/JoseObreque/go-web/internal/domain"
 	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/JoseObreque/go-web/pkg/config"
 	"github.com/JoseObreque/go-web/pkg/store"
-	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
-	swaggerfiles "github.com/swaggo/files"
-	ginSwagger "github.com/swaggo/gin-swagger"
-	"net/http"
+	"github.com/spf13/cobra"
+	"io/fs"
+	"log"
+	"os"
+	"time"
 )
 
+// Path of the optional file with the environment variables of the server, set by the --env-file flag.
+var envFile = "./cmd/local.env"
+
 // @BasePath /api/v1
 
 // @title MELI Bootcamp API
@@ -23,65 +29,262 @@ import (
 // @contact.name API Support
 // @contact.url https://developers.mercadolibre.cl/es_ar/support
 func main() {
-	// Load environment variables
-	err := godotenv.Load("./cmd/local.env")
+	if err := newRootCommand().Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+/*
+The newRootCommand function returns the go-web command line. Every command loads and validates the
+environment variables first, and the servers are started when no command is given, like with serve.
+The --env-file flag of every command selects the environment file.
+*/
+func newRootCommand() *cobra.Command {
+	root := &cobra.Command{
+		Use:          "go-web",
+		Short:        "MELI products API and catalog tools",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return configure(cmd == cmd.Root() || cmd.Name() == "serve")
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return serve()
+		},
+	}
+	root.PersistentFlags().StringVar(&envFile, "env-file", envFile,
+		"file with the environment variables, which override none of the ones already set; optional")
+	root.AddCommand(newServeCommand(), newImportCommand(), newExportCommand(), newValidateCommand())
+	return root
+}
+
+/*
+The configure function loads the environment file, if it exists, and applies the date, currency,
+locale and code settings. Without the file, like in a container whose variables are injected, only the
+variables of the environment are used. Every invalid variable is reported at once, together with
+the ones of the servers if the command starts them, and the warnings are logged.
+*/
+func configure(serving bool) error {
+	var report config.EnvReport
+	values, err := readEnvFile()
+	switch {
+	case err != nil:
+		report.Fail(envFile, "%s", err)
+	case values == nil:
+		log.Printf("config: %s not found, using the environment variables only\n", envFile)
+	default:
+		for key, value := range values {
+			if _, set := os.LookupEnv(key); !set {
+				_ = os.Setenv(key, value)
+			}
+		}
+	}
+
+	// Expiration dates are returned as DATE_FORMAT (dmy or iso) and compared in DATE_TIMEZONE
+	var location *time.Location
+	if timezone := os.Getenv("DATE_TIMEZONE"); timezone != "" {
+		var err error
+		if location, err = time.LoadLocation(timezone); err != nil {
+			report.Fail("DATE_TIMEZONE", "%s", err)
+		}
+	}
+	if err := domain.ConfigureDates(os.Getenv("DATE_FORMAT"), location); err != nil {
+		report.Fail("DATE_FORMAT", "%s", err)
+	}
+
+	// Prices without a currency are in DEFAULT_CURRENCY (USD by default)
+	if err := domain.ConfigureCurrency(os.Getenv("DEFAULT_CURRENCY")); err != nil {
+		report.Fail("DEFAULT_CURRENCY", "%s", err)
+	}
+
+	// Names and descriptions are in DEFAULT_LOCALE (en by default), and the other locales in their translations
+	if err := domain.ConfigureLocale(os.Getenv("DEFAULT_LOCALE")); err != nil {
+		report.Fail("DEFAULT_LOCALE", "%s", err)
+	}
+
+	// Code values follow CODE_VALUE_FORMAT: a CODE_VALUE_PATTERN regular expression, or a barcode
+	// format (ean13, upc or gtin) whose check digit is verified
+	if err := domain.ConfigureCodeValues(domain.CodeFormat{
+		Format:  os.Getenv("CODE_VALUE_FORMAT"),
+		Pattern: os.Getenv("CODE_VALUE_PATTERN"),
+	}); err != nil {
+		report.Fail("CODE_VALUE_FORMAT", "%s", err)
+	}
+
+	// The key of an encrypted store file is needed by every command reading it
+	if _, err := storeEncryptionKey(); err != nil {
+		report.Fail("STORE_ENCRYPTION_KEY", "%s", err)
+	}
+
+	if serving {
+		checkServerEnvironment(&report)
+	}
+	for _, warning := range report.Warnings() {
+		log.Printf("config: %s\n", warning)
+	}
+	return report.Err()
+}
+
+// The readEnvFile function returns the variables of the environment file, or nil if it does not exist.
+func readEnvFile() (map[string]string, error) {
+	values, err := godotenv.Read(envFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return values, err
+}
+
+// The openStore function returns the store of the products file and its journal.
+func openStore() (store.Store, *store.Journal, error) {
+	journal, err := store.OpenJournal(storeFile() + ".journal")
 	if err != nil {
-		panic(err)
+		return nil, nil, err
 	}
+	return store.NewJsonStore(storeFile(), storeOptions()...), journal, nil
+}
 
-	// Extract products data from the JSON file
-	jsonStore := store.NewJsonStore("products.json")
-	productList, err := jsonStore.GetAll()
+// The loadStoreProducts function returns the products of the store file with the changes of its journal replayed.
+func loadStoreProducts() ([]domain.Product, error) {
+	jsonStore, journal, err := openStore()
 	if err != nil {
+		return nil, err
+	}
+	defer journal.Close()
+	return loadProducts(jsonStore, journal)
+}
+
+/*
+The validateProducts function checks the loaded products and stops the server with a report of
+every issue found. With DATA_VALIDATION=degraded the server starts anyway without the products
+with issues, which are quarantined in the products.quarantine.json file to be fixed by hand.
+*/
+func validateProducts(products []domain.Product) []domain.Product {
+	report := product.ValidateCatalog(products)
+	if report.Err() == nil {
+		return products
+	}
+	if os.Getenv("DATA_VALIDATION") != "degraded" {
+		panic(report.Err())
+	}
+
+	log.Printf("starting in degraded mode, %s\n", report)
+	if err := store.NewJsonStore("products.quarantine.json").Save(report.Quarantined); err != nil {
 		panic(err)
 	}
+	return report.Valid
+}
 
-	// New product handler initialization
-	repository := product.NewRepository(productList)
-	service := product.NewService(repository)
-	productHandler := handler.NewProductHandler(service)
+/*
+The seedStore function creates the store file when it does not exist, with the default products
+embedded in the binary or, if STORE_EMPTY_START is "true", with no products at all.
+*/
+func seedStore(jsonStore store.Store) {
+	if _, err := os.Stat(storeFile()); !errors.Is(err, fs.ErrNotExist) {
+		return
+	}
 
-	// Create new router
-	router := gin.New()
-	router.Use(middleware.PanicLogger())
-	docs.SwaggerInfo.BasePath = "/api/v1"
+	var products []domain.Product
+	if os.Getenv("STORE_EMPTY_START") != "true" {
+		defaults, err := dataset.Products()
+		if err != nil {
+			panic(err)
+		}
+		products = defaults
+	}
+	log.Printf("store: %s not found, starting with %d default products\n", storeFile(), len(products))
+	if err := jsonStore.Save(products); err != nil {
+		panic(err)
+	}
+}
 
-	// Products endpoints
-	generalGroup := router.Group("/api/v1")
+// The loadProducts function returns the products of the store file with the changes of the journal replayed over them.
+func loadProducts(jsonStore store.Store, journal *store.Journal) ([]domain.Product, error) {
+	state, err := loadState(jsonStore, journal)
+	return state.Products, err
+}
 
-	// Ping endpoint
-	router.GET("/ping", func(c *gin.Context) {
-		c.String(http.StatusOK, "pong")
-	})
+/*
+The loadState function returns the state of the store file with the changes of the journal replayed
+over it: the products and the highest ID ever assigned.
+*/
+func loadState(jsonStore store.Store, journal *store.Journal) (store.State, error) {
+	state, err := jsonStore.LoadState()
+	if err != nil {
+		return store.State{}, err
+	}
+	entries, err := journal.Entries()
+	if err != nil {
+		return store.State{}, err
+	}
+	return store.ReplayState(state, entries), nil
+}
 
-	// Panic endpoint
-	router.GET("/panic", func(c *gin.Context) {
-		panic("oh no!")
-	})
+/*
+The loadInto function returns the load of the products of the store file for the reloads of the
+service, which reserves the IDs ever assigned in the file so they are not given again.
+*/
+func loadInto(service *product.ServiceImpl, jsonStore store.Store, journal *store.Journal) func() ([]domain.Product, error) {
+	return func() ([]domain.Product, error) {
+		state, err := loadState(jsonStore, journal)
+		if err != nil {
+			return nil, err
+		}
+		service.ReserveIds(state.LastId)
+		return state.Products, nil
+	}
+}
 
-	// Swagger documentation endpoint
-	generalGroup.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
+// The serviceState function returns the products of the service and the highest ID it ever assigned.
+func serviceState(service *product.ServiceImpl) store.State {
+	products := service.GetAll()
+	// Read after the products, so it is never below their IDs
+	return store.State{Products: products, LastId: service.LastId()}
+}
 
-	// Products endpoints
-	productGroup := generalGroup.Group("/products")
-	{
-		productGroup.GET("/all", productHandler.GetAll())
-		productGroup.GET("/:id", productHandler.GetById())
-		productGroup.GET("/search", productHandler.GetByPriceGt())
+/*
+The storeFile function returns the path of the products file, STORE_FILE or products.json by default.
+A name ending with .gz makes the saved file compressed.
+*/
+func storeFile() string {
+	if file := os.Getenv("STORE_FILE"); file != "" {
+		return file
 	}
+	return "products.json"
+}
 
-	protectedProductGroup := generalGroup.Group("/products")
-	protectedProductGroup.Use(middleware.TokenValidator())
-	{
-		protectedProductGroup.POST("/new", productHandler.Create())
-		protectedProductGroup.PUT("/:id", productHandler.FullUpdate())
-		protectedProductGroup.PATCH("/:id", productHandler.PartialUpdate())
-		protectedProductGroup.DELETE("/:id", productHandler.Delete())
+/*
+The storeOptions function returns the options of the products store: the progress log of the load
+and, if STORE_ENCRYPTION_KEY is set, the base64 AES key (16, 24 or 32 bytes) encrypting the file.
+*/
+func storeOptions() []store.Option {
+	options := []store.Option{
+		store.WithProgress(100000, func(records int) {
+			log.Printf("store: %d products loaded\n", records)
+		}),
 	}
 
-	// Start server
-	err = router.Run(":8080")
+	key, err := storeEncryptionKey()
 	if err != nil {
 		panic(err)
 	}
+	if key != nil {
+		options = append(options, store.WithEncryptionKey(key))
+	}
+	return options
+}
+
+// The storeEncryptionKey function returns the decoded and validated STORE_ENCRYPTION_KEY, or nil if it is not set.
+func storeEncryptionKey() ([]byte, error) {
+	encodedKey := os.Getenv("STORE_ENCRYPTION_KEY")
+	if encodedKey == "" {
+		return nil, nil
+	}
+	key, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil {
+		return nil, err
+	}
+	if err = store.ValidateEncryptionKey(key); err != nil {
+		return nil, err
+	}
+	return key, nil
 }
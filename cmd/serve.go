@@ -0,0 +1,1227 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/JoseObreque/go-web/cmd/server/command"
+	"github.com/JoseObreque/go-web/cmd/server/handler"
+	"github.com/JoseObreque/go-web/cmd/server/middleware"
+	"github.com/JoseObreque/go-web/cmd/server/router"
+	"github.com/JoseObreque/go-web/cmd/server/rpc"
+	"github.com/JoseObreque/go-web/internal/analytics"
+	"github.com/JoseObreque/go-web/internal/audit"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/expiry"
+	"github.com/JoseObreque/go-web/internal/maintenance"
+	"github.com/JoseObreque/go-web/internal/popularity"
+	"github.com/JoseObreque/go-web/internal/pricing"
+	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/JoseObreque/go-web/internal/review"
+	"github.com/JoseObreque/go-web/internal/search"
+	"github.com/JoseObreque/go-web/internal/suggest"
+	"github.com/JoseObreque/go-web/internal/tenant"
+	"github.com/JoseObreque/go-web/internal/undo"
+	"github.com/JoseObreque/go-web/internal/usage"
+	"github.com/JoseObreque/go-web/internal/webhook"
+	"github.com/JoseObreque/go-web/pkg/adapter"
+	"github.com/JoseObreque/go-web/pkg/cache"
+	"github.com/JoseObreque/go-web/pkg/config"
+	"github.com/JoseObreque/go-web/pkg/currency"
+	"github.com/JoseObreque/go-web/pkg/events"
+	"github.com/JoseObreque/go-web/pkg/kafka"
+	"github.com/JoseObreque/go-web/pkg/lock"
+	"github.com/JoseObreque/go-web/pkg/logging"
+	"github.com/JoseObreque/go-web/pkg/mail"
+	"github.com/JoseObreque/go-web/pkg/migrate"
+	"github.com/JoseObreque/go-web/pkg/nats"
+	"github.com/JoseObreque/go-web/pkg/outbox"
+	"github.com/JoseObreque/go-web/pkg/resilience"
+	"github.com/JoseObreque/go-web/pkg/scheduler"
+	"github.com/JoseObreque/go-web/pkg/secrets"
+	"github.com/JoseObreque/go-web/pkg/store"
+	"github.com/JoseObreque/go-web/pkg/ws"
+	"github.com/gin-gonic/gin"
+	"github.com/go-chi/chi/v5"
+	_ "github.com/lib/pq"
+	natsgo "github.com/nats-io/nats.go"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"io/fs"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// The newServeCommand function returns the command that starts the HTTP and gRPC servers.
+func newServeCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "serve",
+		Short: "Start the HTTP and gRPC servers",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return serve()
+		},
+	}
+}
+
+/*
+The serve function loads the products of the store file, or of the database, and serves them over
+HTTP and gRPC, along with the background jobs and the optional event relays, until the HTTP server
+stops.
+*/
+func serve() error {
+	// The secrets, like TOKEN and SIGNING_SECRET, come from the secret manager of SECRETS_PROVIDER,
+	// if any, instead of the environment file, and are fetched again every SECRETS_REFRESH (5m by default)
+	signingSecret := config.NewValue(os.Getenv("SIGNING_SECRET"))
+	if err := loadSecrets(signingSecret); err != nil {
+		return err
+	}
+
+	// The products are kept in the PostgreSQL database of DATABASE_URL, if set, and in the store
+	// file otherwise. Hits and misses of the caches of the data layer, reported on GET /admin/store/stats
+	bus := events.NewBus()
+	database, err := config.LoadDatabase()
+	if err != nil {
+		return err
+	}
+	caches := make(map[string]func() cache.Stats)
+	var service *product.ServiceImpl
+	var jsonStore store.Store
+	var journal *store.Journal
+	// Circuit breakers of the backends the products cannot be served without, failing the products
+	// requests fast with 503 while they are open
+	var breakers []*resilience.Breaker
+	if database.Enabled() {
+		var breaker *resilience.Breaker
+		service, breaker, err = newDatabaseService(database, bus, caches)
+		breakers = append(breakers, breaker)
+	} else {
+		service, jsonStore, journal, err = newStoreService(bus, caches)
+	}
+	if err != nil {
+		return err
+	}
+
+	// With MAINTENANCE_MODE=true the server starts read-only, toggled on /admin/maintenance; the rejected
+	// changes are retried after MAINTENANCE_RETRY_AFTER (1m by default)
+	retryAfter, err := time.ParseDuration(os.Getenv("MAINTENANCE_RETRY_AFTER"))
+	if err != nil {
+		retryAfter = time.Minute
+	}
+	maintenanceMode := maintenance.NewMode(os.Getenv("MAINTENANCE_MODE") == "true", retryAfter)
+
+	// Websocket hub broadcasting the product events
+	maxConnections, err := strconv.Atoi(os.Getenv("WS_MAX_CONNECTIONS"))
+	if err != nil {
+		maxConnections = 100
+	}
+	hub := ws.NewHub(maxConnections, handler.ProductTopics)
+	go hub.Run(bus)
+
+	// Webhooks dispatcher notifying the product events
+	webhookService := webhook.NewService(webhook.NewRepository())
+	dispatcher := webhook.NewDispatcher(webhookService, &http.Client{Timeout: 10 * time.Second}, 5, 30*time.Second)
+	go dispatcher.Run(bus)
+
+	// Subscriptions to the stock expiring within EXPIRY_WINDOW_DAYS (7 by default), notified every day by
+	// webhook and, with an SMTP server at SMTP_ADDR, by email from SMTP_FROM
+	expiryWindow, err := strconv.Atoi(os.Getenv("EXPIRY_WINDOW_DAYS"))
+	if err != nil || expiryWindow < 1 {
+		expiryWindow = 7
+	}
+	expirySubscriptions := expiry.NewService(expiry.NewRepository())
+	expiryNotifier := expiry.NewNotifier(expirySubscriptions, service.GetAll, expiryWindow, dispatcher)
+	if smtpAddr := os.Getenv("SMTP_ADDR"); smtpAddr != "" {
+		expiryNotifier.WithMail(mail.NewSMTP(smtpAddr, os.Getenv("SMTP_FROM"), os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD")))
+	}
+
+	// Audit log recording every product change
+	auditLog := audit.NewLog(1000)
+	auditLog.Listen(bus)
+
+	// Last product changes of every credential, recorded as part of the changes and reverted on POST /admin/undo
+	undoLog := undo.NewLog(service, 100)
+	service.WithRecorder(undoLog.Record)
+
+	// Optional Kafka publishing of the product events through a durable outbox
+	if brokers := os.Getenv("KAFKA_BROKERS"); brokers != "" {
+		startKafkaRelay(service, strings.Split(brokers, ","))
+	}
+
+	// Optional NATS publishing of the product events and remote commands
+	if natsURL := os.Getenv("NATS_URL"); natsURL != "" {
+		startNats(service, natsURL, maintenanceMode)
+	}
+
+	// The products of the store file are reloaded from it on POST /admin/reload, and on every change
+	// of the file if STORE_WATCH is "true"
+	var reload func() (bool, error)
+	if journal != nil {
+		reload = func() (bool, error) {
+			return reloadProducts(service, jsonStore, journal)
+		}
+		if os.Getenv("STORE_WATCH") == "true" {
+			watchStore(reload)
+		}
+	}
+
+	// Names of the published products suggested to the search box, updated on every product event
+	suggestions := suggest.NewIndex(service.GetAll())
+	suggestions.Listen(bus, service.GetAll)
+
+	// Optional full-text search of the products, indexed on every product event
+	searchEngine, err := newSearchEngine(service.GetAll())
+	if err != nil {
+		return err
+	}
+	if searchEngine != nil {
+		search.Listen(bus, searchEngine, service.GetAll)
+	}
+
+	// Future prices of the products, kept in PRICE_SCHEDULE_FILE (scheduled_prices.json by default)
+	priceFile := os.Getenv("PRICE_SCHEDULE_FILE")
+	if priceFile == "" {
+		priceFile = "scheduled_prices.json"
+	}
+	priceRepository, err := pricing.NewRepository(priceFile)
+	if err != nil {
+		return err
+	}
+	prices := pricing.NewService(priceRepository, service)
+
+	// Reviews of the products, kept in REVIEWS_FILE (reviews.json by default)
+	reviewFile := os.Getenv("REVIEWS_FILE")
+	if reviewFile == "" {
+		reviewFile = "reviews.json"
+	}
+	reviewRepository, err := review.NewRepository(reviewFile)
+	if err != nil {
+		return err
+	}
+	reviews := review.NewService(reviewRepository, service)
+
+	// Views of the products, counted in memory and kept in VIEWS_FILE (views.json by default)
+	viewFile := os.Getenv("VIEWS_FILE")
+	if viewFile == "" {
+		viewFile = "views.json"
+	}
+	views, err := popularity.NewCounter(viewFile, popularity.DefaultBuffer)
+	if err != nil {
+		return err
+	}
+
+	// The merges move the scheduled prices, reviews and views of the duplicate, whatever API they come from
+	service.WithReferrers(prices, reviews, views)
+
+	// The most recent SEARCH_LOG_SIZE text searches (10000 by default) are kept in memory for the reports
+	searchLogSize, err := strconv.Atoi(os.Getenv("SEARCH_LOG_SIZE"))
+	if err != nil || searchLogSize < 1 {
+		searchLogSize = 10000
+	}
+
+	// Optional catalogs of the tenants sharing the deployment, isolated from the main one
+	tenants, err := newTenants()
+	if err != nil {
+		return err
+	}
+	if tenants != nil {
+		log.Printf("tenants: serving the catalogs of %s\n", strings.Join(tenants.Tenants(), ", "))
+	}
+
+	// The products of the store file are saved in it on POST /admin/flush, along with the catalogs of
+	// the tenants, and the size and last write of the file and its journal are reported on GET
+	// /admin/store/stats
+	var flush func() (store.FlushStats, error)
+	var storeFileStats func() (store.FileStats, error)
+	if journal != nil {
+		flush = func() (store.FlushStats, error) {
+			stats, err := journal.Flush(jsonStore, storeFile(), func() store.State { return serviceState(service) })
+			if err == nil && tenants != nil {
+				err = tenants.Flush()
+			}
+			return stats, err
+		}
+		storeFileStats = func() (store.FileStats, error) {
+			return journal.FileStats(storeFile())
+		}
+	}
+
+	// Background jobs
+	jobs := newScheduler(service, prices, views, jsonStore, journal, dispatcher, expiryNotifier, tenants, maintenanceMode)
+	jobs.Start()
+
+	// Cached product responses live for RESPONSE_CACHE_TTL (5s by default, 0 disables the cache)
+	cacheTTL, err := time.ParseDuration(os.Getenv("RESPONSE_CACHE_TTL"))
+	if err != nil {
+		cacheTTL = 5 * time.Second
+	}
+
+	// Request limits: MAX_BODY_SIZE bytes (1 MiB by default) and REQUEST_TIMEOUT (10s by default)
+	maxBodySize, err := strconv.ParseInt(os.Getenv("MAX_BODY_SIZE"), 10, 64)
+	if err != nil {
+		maxBodySize = 1 << 20
+	}
+	requestTimeout, err := time.ParseDuration(os.Getenv("REQUEST_TIMEOUT"))
+	if err != nil {
+		requestTimeout = 10 * time.Second
+	}
+
+	// Networks allowed to write (WRITE_ALLOWED_CIDRS) and denied (WRITE_DENIED_CIDRS). The client
+	// address is the one of the connection, unless it comes from a proxy of TRUSTED_PROXIES, whose
+	// X-Forwarded-For header is honored then
+	writeNetworks, err := middleware.ParseIPRules(os.Getenv("WRITE_ALLOWED_CIDRS"), os.Getenv("WRITE_DENIED_CIDRS"))
+	if err != nil {
+		panic(err)
+	}
+
+	// Responses of the creations with an Idempotency-Key are kept for IDEMPOTENCY_TTL (24h by default)
+	idempotencyRetention, err := time.ParseDuration(os.Getenv("IDEMPOTENCY_TTL"))
+	if err != nil {
+		idempotencyRetention = 24 * time.Hour
+	}
+
+	// Daily quotas of the partner API keys (API_QUOTAS=key:limit,...); the main token is unlimited,
+	// and the signed and anonymous requests are limited with the signature and anonymous keys
+	quotas, err := loadQuotas(os.Getenv)
+	if err != nil {
+		panic(err)
+	}
+	tracker := usage.NewTracker(quotas)
+
+	// Requests slower than SLOW_REQUEST_THRESHOLD (1s by default) are logged with their full context
+	slowThreshold, err := time.ParseDuration(os.Getenv("SLOW_REQUEST_THRESHOLD"))
+	if err != nil {
+		slowThreshold = time.Second
+	}
+
+	// Messages below LOG_LEVEL (info by default) are not logged. With LOG_BODIES=true the request and
+	// response bodies are logged from the start, up to LOG_BODY_LIMIT bytes, with the JSON fields of
+	// LOG_REDACT_FIELDS (comma separated) redacted
+	logLevel, err := logging.ParseLevel(os.Getenv("LOG_LEVEL"))
+	if err != nil {
+		return err
+	}
+	logSettings := logging.Default()
+	logSettings.Apply(logging.Options{Level: logLevel, Bodies: os.Getenv("LOG_BODIES") == "true"})
+
+	// Browsers can call the API from the CORS_ALLOWED_ORIGINS (comma separated, * for any origin)
+	corsOrigins := config.NewValue(config.SplitList(os.Getenv("CORS_ALLOWED_ORIGINS")))
+
+	// The token, the signing secret, the quotas, the CORS origins and the log level are reloaded from
+	// the environment file by POST /admin/reload-config and by a SIGHUP
+	reloader := newConfigReloader(tracker, signingSecret, corsOrigins, logSettings)
+	go reloadConfigOnHangup(reloader)
+	redactedFields := logging.DefaultFields
+	if fields := os.Getenv("LOG_REDACT_FIELDS"); fields != "" {
+		redactedFields = strings.Split(fields, ",")
+	}
+	logBodyLimit, _ := strconv.Atoi(os.Getenv("LOG_BODY_LIMIT"))
+
+	// Optional Sentry reporting of the panics and the server errors
+	var reporters []middleware.Reporter
+	if dsn := os.Getenv("SENTRY_DSN"); dsn != "" {
+		sentryReporter, err := middleware.NewSentryReporter(dsn, os.Getenv("SENTRY_ENVIRONMENT"))
+		if err != nil {
+			panic(err)
+		}
+		reporters = append(reporters, sentryReporter)
+	}
+
+	// Prices can be requested in other currencies with the EXCHANGE_RATES_URL API or the fixed EXCHANGE_RATES
+	converter, err := newCurrencyConverter()
+	if err != nil {
+		return err
+	}
+
+	// With LOADTEST_MODE=true the HTTP API can serve a generated dataset instead of the catalog, toggled
+	// on /admin/loadtest, while the background jobs, the event relays and gRPC keep using the catalog
+	var httpService product.Service = service
+	var sandbox *product.SandboxService
+	if os.Getenv("LOADTEST_MODE") == "true" {
+		sandbox = product.NewSandboxService(service)
+		httpService = sandbox
+	}
+
+	// Create new router and map the API endpoints. Signed requests use SIGNING_SECRET, and
+	// AUTH_MODE=signature makes them mandatory instead of accepting them along with the token (the
+	// default, AUTH_MODE=token). EMPTY_FILTER_STATUS=404 keeps the legacy answer of
+	// the filters matching no product, the backup snapshots are written to BACKUP_DIR, DEV_MODE=true
+	// enables the development endpoints, and the sitemap and the product feed link to PUBLIC_URL
+	requireSignature := os.Getenv("AUTH_MODE") == "signature"
+	engine := gin.New()
+	if err = engine.SetTrustedProxies(trustedProxies()); err != nil {
+		return err
+	}
+	router.NewRouter(engine, router.Dependencies{
+		Products:             httpService,
+		Webhooks:             webhookService,
+		Bus:                  bus,
+		Hub:                  hub,
+		Audit:                auditLog,
+		Undo:                 undoLog,
+		Scheduler:            jobs,
+		CacheTTL:             cacheTTL,
+		MaxBodySize:          maxBodySize,
+		RequestTimeout:       requestTimeout,
+		IdempotencyRetention: idempotencyRetention,
+		WriteNetworks:        writeNetworks,
+		SigningSecret:        signingSecret,
+		RequireSignature:     requireSignature,
+		EmptyFilterNotFound:  os.Getenv("EMPTY_FILTER_STATUS") == "404",
+		Reporters:            reporters,
+		SlowRequestThreshold: slowThreshold,
+		Logging:              logSettings,
+		Redactor:             logging.NewRedactor(redactedFields...),
+		LogBodyLimit:         logBodyLimit,
+		CORSOrigins:          corsOrigins,
+		Reloader:             reloader,
+		Usage:                tracker,
+		BackupDir:            os.Getenv("BACKUP_DIR"),
+		Reload:               reload,
+		Flush:                flush,
+		StoreFile:            storeFileStats,
+		Caches:               caches,
+		DevMode:              os.Getenv("DEV_MODE") == "true",
+		Currency:             converter,
+		Prices:               prices,
+		Reviews:              reviews,
+		Expiry:               expirySubscriptions,
+		ExpiryNotifier:       expiryNotifier,
+		Views:                views,
+		Searches:             analytics.NewSearchLog(searchLogSize),
+		Suggestions:          suggestions,
+		Search:               searchEngine,
+		Tenants:              tenants,
+		Maintenance:          maintenanceMode,
+		Sandbox:              sandbox,
+		Breakers:             breakers,
+		PublicURL:            os.Getenv("PUBLIC_URL"),
+	}).MapRoutes()
+
+	// Start the gRPC server on its own port, reusing the same service. Its calls cannot be signed, so
+	// it only answers the queries when the signatures are mandatory
+	listener, err := net.Listen("tcp", ":"+portOf("GRPC_PORT", "9090"))
+	if err != nil {
+		return err
+	}
+	interceptors := []grpc.UnaryServerInterceptor{rpc.ReadOnlyInterceptor(maintenanceMode.Check)}
+	if !writeNetworks.Empty() {
+		interceptors = append(interceptors, rpc.NetworkInterceptor(writeNetworks.Allowed))
+	}
+	if requireSignature {
+		interceptors = append(interceptors, rpc.SignedOnlyInterceptor)
+	}
+	go func() {
+		if err := rpc.NewServer(service, interceptors...).Serve(listener); err != nil {
+			log.Printf("gRPC server stopped: %s\n", err)
+		}
+	}()
+
+	// Start server on PORT (8080 by default). HTTP_ROUTER=chi serves the portable endpoints natively
+	// with a chi router, which hands the rest of the requests to gin
+	address := ":" + portOf("PORT", "8080")
+	if os.Getenv("HTTP_ROUTER") == "chi" {
+		return http.ListenAndServe(address, newChiFront(engine))
+	}
+	return engine.Run(address)
+}
+
+/*
+The trustedProxies function returns the addresses and networks of TRUSTED_PROXIES, a comma separated
+list, or nil to trust no proxy if it is not set.
+*/
+func trustedProxies() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
+
+// The portOf function returns the port of the given variable, or the default port if it is not set.
+func portOf(variable, defaultPort string) string {
+	if port := os.Getenv(variable); port != "" {
+		return port
+	}
+	return defaultPort
+}
+
+/*
+The newStoreService function returns the service of the products of the store file, created from the
+default dataset if missing, along with the store and its journal. The changes recorded in the
+journal since the last save are replayed, and saved in the file.
+*/
+func newStoreService(bus events.Bus, caches map[string]func() cache.Stats) (*product.ServiceImpl, store.Store, *store.Journal, error) {
+	jsonStore, journal, err := openStore()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if err = shareStore(journal); err != nil {
+		return nil, nil, nil, err
+	}
+	seedStore(jsonStore)
+	state, err := loadState(jsonStore, journal)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// With a shared store, the products are loaded again if another instance changed them meanwhile
+	journal.OnStale(func() error {
+		loaded, err := loadState(jsonStore, journal)
+		if err != nil {
+			return err
+		}
+		state = loaded
+		state.Products = validateProducts(state.Products)
+		domain.NormalizeExpirations(state.Products)
+		return nil
+	})
+	state.Products = validateProducts(state.Products)
+	domain.NormalizeExpirations(state.Products)
+	if err = journal.Compact(func() error { return jsonStore.SaveState(state) }); err != nil {
+		return nil, nil, nil, err
+	}
+
+	// New product service initialization
+	repository := withRedisCache(product.NewJournaledRepository(product.NewRepository(state.Products), journal), caches)
+	service := shareService(product.NewService(repository, bus), jsonStore, journal)
+	service.ReserveIds(state.LastId)
+	return service, jsonStore, journal, nil
+}
+
+/*
+The newDatabaseService function returns the service of the products of the PostgreSQL database,
+whose migrations are applied first, along with the circuit breaker of its calls. The reads are
+balanced across its replicas, if any, while the writes and the reads they depend on go to the
+primary.
+*/
+func newDatabaseService(database config.Database, bus events.Bus, caches map[string]func() cache.Stats) (*product.ServiceImpl, *resilience.Breaker, error) {
+	primary, replicas, err := database.Open("postgres")
+	if err != nil {
+		return nil, nil, err
+	}
+	applied, err := migrate.Up(primary, product.Migrations())
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(applied) > 0 {
+		log.Printf("database: applied the migrations %s\n", strings.Join(applied, ", "))
+	}
+	log.Printf("database: serving the products of the database, reading from %d replicas\n", len(replicas))
+
+	breaker, retry := newBackendGuard("database")
+	repository := product.NewSQLRepository(primary, product.WithBreaker(breaker, retry), product.WithReplicas(replicas...))
+	return product.NewService(withRedisCache(repository, caches), bus).(*product.ServiceImpl), breaker, nil
+}
+
+/*
+The withRedisCache function caches the reads of the repository in the Redis server of REDIS_URL, if
+set, adding the hits and misses of the cache to the given ones.
+*/
+func withRedisCache(repository product.Repository, caches map[string]func() cache.Stats) product.Repository {
+	redisURL := os.Getenv("REDIS_URL")
+	if redisURL == "" {
+		return repository
+	}
+	cachedRepository := newCachedRepository(repository, redisURL)
+	caches["repository"] = cachedRepository.CacheStats
+	return cachedRepository
+}
+
+/*
+The checkServerEnvironment function adds the problems of the variables of the servers to the
+report: the token, unless it comes from a secret manager, and the signing secret must not be
+trivial (only a warning with DEV_MODE=true), the authentication mode must be token or signature,
+the latter with a signing secret, the router must be gin or chi, the trusted proxies must be
+addresses or ranges, the store file must be readable, the ports valid, the SMTP server sending from
+an email address, and the lists, levels and windows parseable. The store file is only checked
+without a database, and the read replicas need a primary.
+*/
+func checkServerEnvironment(report *config.EnvReport) {
+	devMode := os.Getenv("DEV_MODE") == "true"
+	if os.Getenv("SECRETS_PROVIDER") == "" {
+		report.CheckSecret("TOKEN", os.Getenv("TOKEN"), devMode)
+	} else if _, err := newSecretsProvider(); err != nil {
+		report.Fail("SECRETS_PROVIDER", "%s", err)
+	}
+	if secret := os.Getenv("SIGNING_SECRET"); secret != "" {
+		report.CheckSecret("SIGNING_SECRET", secret, devMode)
+	}
+	switch authMode := os.Getenv("AUTH_MODE"); authMode {
+	case "", "token":
+	case "signature":
+		// The secret of a secret manager is checked once it is loaded
+		if os.Getenv("SECRETS_PROVIDER") == "" && os.Getenv("SIGNING_SECRET") == "" {
+			report.Fail("SIGNING_SECRET", "is required with AUTH_MODE=signature")
+		}
+	default:
+		report.Fail("AUTH_MODE", "must be token or signature, not %q", authMode)
+	}
+
+	if err := gin.New().SetTrustedProxies(trustedProxies()); err != nil {
+		report.Fail("TRUSTED_PROXIES", "must be a comma separated list of addresses or CIDR ranges: %s", err)
+	}
+
+	switch httpRouter := os.Getenv("HTTP_ROUTER"); httpRouter {
+	case "", "gin", "chi":
+	default:
+		report.Fail("HTTP_ROUTER", "must be gin or chi, not %q", httpRouter)
+	}
+
+	if _, err := config.LoadDatabase(); err != nil {
+		report.Fail("DATABASE_REPLICA_URLS", "%s", err)
+	} else if os.Getenv("DATABASE_URL") == "" {
+		report.CheckFile("STORE_FILE", storeFile())
+	}
+	report.CheckPort("PORT", portOf("PORT", "8080"))
+	report.CheckPort("GRPC_PORT", portOf("GRPC_PORT", "9090"))
+
+	if _, err := usage.ParseQuotas(os.Getenv("API_QUOTAS")); err != nil {
+		report.Fail("API_QUOTAS", "%s", err)
+	}
+	if _, err := logging.ParseLevel(os.Getenv("LOG_LEVEL")); err != nil {
+		report.Fail("LOG_LEVEL", "%s", err)
+	}
+	if days := os.Getenv("EXPIRY_WINDOW_DAYS"); days != "" {
+		if window, err := strconv.Atoi(days); err != nil || window < 1 {
+			report.Fail("EXPIRY_WINDOW_DAYS", "must be a positive number of days, like 7")
+		}
+	}
+	if smtpAddr := os.Getenv("SMTP_ADDR"); smtpAddr != "" {
+		if _, port, err := net.SplitHostPort(smtpAddr); err != nil {
+			report.Fail("SMTP_ADDR", "must be host:port, like smtp.example.com:587")
+		} else {
+			report.CheckPort("SMTP_ADDR", port)
+		}
+		if !mail.ValidAddress(os.Getenv("SMTP_FROM")) {
+			report.Fail("SMTP_FROM", "must be the email address the notifications are sent from")
+		}
+	}
+	if publicURL := os.Getenv("PUBLIC_URL"); publicURL != "" {
+		if parsed, err := url.Parse(publicURL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			report.Fail("PUBLIC_URL", "must be an absolute http or https URL, like https://shop.example.com")
+		}
+	}
+}
+
+/*
+The newChiFront function returns a chi router serving the portable endpoints natively, in front of
+the gin engine serving the rest of them. The front is deliberately limited to the liveness probe:
+the other handlers written against the adapter, like the flush and the store stats, need the
+authentication and network filter middlewares, which are gin ones, so they are still served by gin.
+The endpoints served by chi are logged on start.
+*/
+func newChiFront(engine *gin.Engine) http.Handler {
+	mux := chi.NewRouter()
+	router.MapPortableRoutes(adapter.NewChiRouter(mux))
+	var routes []string
+	_ = chi.Walk(mux, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		routes = append(routes, method+" "+route)
+		return nil
+	})
+	log.Printf("http: serving %s with chi, and the rest of the endpoints with gin\n", strings.Join(routes, ", "))
+
+	mux.NotFound(engine.ServeHTTP)
+	mux.MethodNotAllowed(engine.ServeHTTP)
+	return mux
+}
+
+/*
+The startKafkaRelay function records every product event of the service in the outbox file
+(KAFKA_OUTBOX), as part of the change, and relays the pending messages to the Kafka topic
+(KAFKA_TOPIC), encoded as JSON or Avro depending on KAFKA_ENCODING.
+*/
+func startKafkaRelay(service *product.ServiceImpl, brokers []string) {
+	encoder := outbox.JSONEncoder
+	if os.Getenv("KAFKA_ENCODING") == "avro" {
+		avroEncoder, err := kafka.NewAvroEncoder()
+		if err != nil {
+			panic(err)
+		}
+		encoder = avroEncoder
+	}
+
+	outboxFile := os.Getenv("KAFKA_OUTBOX")
+	if outboxFile == "" {
+		outboxFile = "outbox.json"
+	}
+	kafkaOutbox, err := outbox.NewOutbox(outboxFile)
+	if err != nil {
+		panic(err)
+	}
+	service.WithRecorder(kafkaOutbox.Recorder(encoder))
+
+	topic := os.Getenv("KAFKA_TOPIC")
+	if topic == "" {
+		topic = "products"
+	}
+	relay := outbox.NewRelay(kafkaOutbox, kafka.NewPublisher(brokers, topic))
+	go relay.Run(time.Second, nil)
+}
+
+/*
+The startNats function relays every product event of the service to NATS under the NATS_PREFIX
+subject prefix. Events are recorded as part of the change and kept in memory until published, or
+in the NATS_OUTBOX file if it is set. If
+NATS_COMMANDS is "true", remote commands are also accepted on the "<prefix>.commands" subject,
+except during a maintenance.
+*/
+func startNats(service *product.ServiceImpl, url string, maintenanceMode *maintenance.Mode) {
+	conn, err := natsgo.Connect(url, natsgo.Name("go-web"), natsgo.MaxReconnects(-1))
+	if err != nil {
+		panic(err)
+	}
+
+	prefix := os.Getenv("NATS_PREFIX")
+	if prefix == "" {
+		prefix = "catalog"
+	}
+
+	natsOutbox, err := outbox.NewOutbox(os.Getenv("NATS_OUTBOX"))
+	if err != nil {
+		panic(err)
+	}
+	service.WithRecorder(natsOutbox.Recorder(outbox.JSONEncoder))
+	relay := outbox.NewRelay(natsOutbox, nats.NewPublisher(conn, prefix))
+	go relay.Run(time.Second, nil)
+
+	if os.Getenv("NATS_COMMANDS") == "true" {
+		if _, err = command.NewListener(service).WithReadOnly(maintenanceMode.Check).Subscribe(conn, prefix+".commands"); err != nil {
+			panic(err)
+		}
+	}
+}
+
+/*
+The newScheduler function returns a scheduler with the background jobs of the server: the nightly
+unpublish of expired products, the publish windows applied every minute, the activation of the
+scheduled prices, the periodic compaction of the journal into the store file (STORE_FLUSH_INTERVAL,
+1m by default) unless the products are kept in a database, along with the ones of the tenants and the view counts, the sweep of the webhook
+deliveries due for a retry, and the morning notification of the stock about to expire. The jobs
+changing the catalog are skipped during a maintenance, like the requests changing it, and run again
+on their next schedule once the server is writable.
+*/
+func newScheduler(service *product.ServiceImpl, prices pricing.Service, views *popularity.Counter, jsonStore store.Store, journal *store.Journal, dispatcher *webhook.Dispatcher, notifier *expiry.Notifier, tenants *tenant.Registry, maintenanceMode *maintenance.Mode) *scheduler.Scheduler {
+	flushInterval, err := time.ParseDuration(os.Getenv("STORE_FLUSH_INTERVAL"))
+	if err != nil {
+		flushInterval = time.Minute
+	}
+	writable := func(task scheduler.Task) scheduler.Task {
+		return func(ctx context.Context) error {
+			if maintenanceMode.Check() != nil {
+				return nil
+			}
+			return task(ctx)
+		}
+	}
+
+	jobs := scheduler.NewScheduler()
+	mustAddJob(jobs, "unpublish-expired", scheduler.Daily(0, 0), writable(func(ctx context.Context) error {
+		unpublished, err := service.UnpublishExpired()
+		log.Printf("jobs: unpublished %d expired products\n", len(unpublished))
+		return err
+	}))
+	mustAddJob(jobs, "publish-windows", scheduler.Every(time.Minute), writable(func(ctx context.Context) error {
+		scheduled, err := service.ApplyPublishWindows(time.Now())
+		if len(scheduled) > 0 {
+			log.Printf("jobs: published or archived %d products by their publish window\n", len(scheduled))
+		}
+		return err
+	}))
+	mustAddJob(jobs, "activate-prices", scheduler.Every(10*time.Second), writable(func(ctx context.Context) error {
+		activated, err := prices.ActivateDue(time.Now())
+		if len(activated) > 0 {
+			log.Printf("jobs: activated the scheduled prices of %d products\n", len(activated))
+		}
+		return err
+	}))
+	if journal != nil {
+		mustAddJob(jobs, "store-flush", scheduler.Every(flushInterval), func(ctx context.Context) error {
+			return journal.Compact(func() error {
+				return jsonStore.SaveState(serviceState(service))
+			})
+		})
+	}
+	mustAddJob(jobs, "views-flush", scheduler.Every(flushInterval), func(ctx context.Context) error {
+		flushed, dropped, err := views.Flush()
+		if dropped > 0 {
+			log.Printf("jobs: flushed %d product views, %d dropped by a full queue\n", flushed, dropped)
+		}
+		return err
+	})
+	if tenants != nil {
+		mustAddJob(jobs, "tenants-flush", scheduler.Every(flushInterval), func(ctx context.Context) error {
+			return tenants.Flush()
+		})
+	}
+	mustAddJob(jobs, "webhook-retries", scheduler.Every(10*time.Second), func(ctx context.Context) error {
+		dispatcher.RetryDue()
+		return nil
+	})
+	mustAddJob(jobs, "notify-expiring", scheduler.Daily(8, 0), func(ctx context.Context) error {
+		report, err := notifier.Check(time.Now())
+		log.Printf("jobs: notified the expiring stock to %d subscriptions\n", report.Notified)
+		return err
+	})
+	return jobs
+}
+
+// The mustAddJob function adds a job to the scheduler, panicking if it cannot be registered.
+func mustAddJob(jobs *scheduler.Scheduler, name string, schedule scheduler.Schedule, task scheduler.Task) {
+	if err := jobs.Add(name, schedule, task); err != nil {
+		panic(err)
+	}
+}
+
+// The newCachedRepository function caches the reads of the repository in Redis for REDIS_TTL (30s by default).
+func newCachedRepository(repository product.Repository, redisURL string) *product.CachedRepository {
+	redisCache, err := cache.NewRedis(redisURL)
+	if err != nil {
+		panic(err)
+	}
+
+	ttl, err := time.ParseDuration(os.Getenv("REDIS_TTL"))
+	if err != nil {
+		ttl = 30 * time.Second
+	}
+	// The breaker of Redis is left out of the breakers of the router, since the products are still
+	// served from the repository while it is open
+	breaker, retry := newBackendGuard("redis")
+	guardedCache := cache.NewResilientCache(redisCache, breaker, retry)
+	return product.NewCachedRepository(repository, guardedCache, ttl).(*product.CachedRepository)
+}
+
+/*
+The newBackendGuard function returns the circuit breaker and the retry policy of the calls to the
+named external backend. The circuit opens after BACKEND_BREAKER_THRESHOLD (5 by default) failures
+in a row for BACKEND_BREAKER_COOLDOWN (30s by default), and the calls are tried BACKEND_RETRIES (3
+by default) times in total, waiting up to BACKEND_RETRY_DELAY (50ms by default) doubled on every
+retry and capped at 1s, at random.
+*/
+func newBackendGuard(name string) (*resilience.Breaker, resilience.Retry) {
+	threshold, err := strconv.Atoi(os.Getenv("BACKEND_BREAKER_THRESHOLD"))
+	if err != nil || threshold < 1 {
+		threshold = 5
+	}
+	cooldown, err := time.ParseDuration(os.Getenv("BACKEND_BREAKER_COOLDOWN"))
+	if err != nil || cooldown <= 0 {
+		cooldown = 30 * time.Second
+	}
+	attempts, err := strconv.Atoi(os.Getenv("BACKEND_RETRIES"))
+	if err != nil || attempts < 1 {
+		attempts = 3
+	}
+	delay, err := time.ParseDuration(os.Getenv("BACKEND_RETRY_DELAY"))
+	if err != nil || delay < 0 {
+		delay = 50 * time.Millisecond
+	}
+
+	retry := resilience.Retry{
+		Attempts:  attempts,
+		BaseDelay: delay,
+		MaxDelay:  time.Second,
+	}
+	return resilience.NewBreaker(name, threshold, cooldown), retry
+}
+
+/*
+The newCurrencyConverter function returns the converter of the prices requested in other currencies.
+The rates come from the EXCHANGE_RATES_URL API, where {base} is replaced by the currency of the
+prices, cached for EXCHANGE_RATES_TTL (1h by default). Otherwise, the fixed EXCHANGE_RATES (like
+"EUR=0.92,CLP=950") from the base currency are used. Without either, it returns nil.
+*/
+func newCurrencyConverter() (*currency.Converter, error) {
+	if url := os.Getenv("EXCHANGE_RATES_URL"); url != "" {
+		ttl, err := time.ParseDuration(os.Getenv("EXCHANGE_RATES_TTL"))
+		if err != nil {
+			ttl = time.Hour
+		}
+		provider := currency.NewHTTPProvider(url, &http.Client{Timeout: 5 * time.Second})
+		return currency.NewConverter(currency.NewCachedProvider(provider, cache.NewMemory(), ttl)), nil
+	}
+
+	if fixedRates := os.Getenv("EXCHANGE_RATES"); fixedRates != "" {
+		rates, err := currency.ParseRates(fixedRates)
+		if err != nil {
+			return nil, err
+		}
+		return currency.NewConverter(currency.NewStaticProvider(domain.BaseCurrency(), rates)), nil
+	}
+	return nil, nil
+}
+
+/*
+The reloadProducts function replaces the products of the service with those of the store file, with
+the unsaved changes of the journal replayed over them. The reloaded products are saved right away,
+so the replacement recorded in the journal never hides the next edits of the file.
+*/
+func reloadProducts(service *product.ServiceImpl, jsonStore store.Store, journal *store.Journal) (bool, error) {
+	reloaded, err := service.Reload(loadInto(service, jsonStore, journal))
+	if err != nil || !reloaded {
+		return reloaded, err
+	}
+	return true, journal.Compact(func() error {
+		return jsonStore.SaveState(serviceState(service))
+	})
+}
+
+/*
+The shareService function makes the writes of the service hold the lock of the journal, so with a
+shared store they start from the products of this instance refreshed through the service, with its
+events, if another instance changed them since the last write of this one.
+*/
+func shareService(service product.Service, jsonStore store.Store, journal *store.Journal) *product.ServiceImpl {
+	shared := service.(*product.ServiceImpl)
+	journal.OnStale(func() error {
+		return shared.Refresh(loadInto(shared, jsonStore, journal))
+	})
+	return shared.WithStoreLock(journal.Lock)
+}
+
+/*
+The shareStore function coordinates the writes to the store file and its journal with the other
+instances of the server sharing them, like on a network volume, with the lock selected by
+STORE_LOCK: "file" for a lock file next to the store file, or "redis" for a key of the Redis
+server at REDIS_URL. A lock expires after STORE_LOCK_TTL (30s by default), and a write waits up to
+STORE_LOCK_TIMEOUT (10s by default) for it. Without a lock, the store is not shared.
+*/
+func shareStore(journal *store.Journal) error {
+	ttl, err := time.ParseDuration(os.Getenv("STORE_LOCK_TTL"))
+	if err != nil || ttl <= 0 {
+		ttl = 30 * time.Second
+	}
+	timeout, err := time.ParseDuration(os.Getenv("STORE_LOCK_TIMEOUT"))
+	if err != nil || timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+
+	var locker lock.Locker
+	switch kind := os.Getenv("STORE_LOCK"); kind {
+	case "":
+		return nil
+	case "file":
+		locker = lock.NewFileLock(storeFile()+".lock", ttl)
+	case "redis":
+		redisLock, err := lock.NewRedisLock(os.Getenv("REDIS_URL"), "go-web:store-lock:"+storeFile(), ttl)
+		if err != nil {
+			return err
+		}
+		locker = redisLock
+	default:
+		return fmt.Errorf("unknown STORE_LOCK %q, expected file or redis", kind)
+	}
+	journal.WithLock(locker, timeout)
+	return nil
+}
+
+/*
+The watchStore function reloads the products every time the store file changes, once it has been
+quiet for a second. The saves of the server itself leave the products unchanged, and a file with
+invalid products is logged and ignored until it is fixed.
+*/
+func watchStore(reload func() (bool, error)) {
+	watcher, err := store.NewWatcher(storeFile(), time.Second)
+	if err != nil {
+		panic(err)
+	}
+	go watcher.Run(func() {
+		reloaded, err := reload()
+		switch {
+		case err != nil:
+			log.Printf("store: reload of %s rejected: %s\n", storeFile(), err)
+		case reloaded:
+			log.Printf("store: products reloaded from %s\n", storeFile())
+		}
+	})
+}
+
+/*
+The newSearchEngine function returns the full-text search engine selected by SEARCH_BACKEND, with
+the given products indexed: "memory" for an embedded index, or "elasticsearch" for the cluster at
+ELASTICSEARCH_URL, in the ELASTICSEARCH_INDEX index (products by default). Without a backend, it
+returns nil.
+*/
+func newSearchEngine(products []domain.Product) (search.Engine, error) {
+	var engine search.Engine
+	switch backend := os.Getenv("SEARCH_BACKEND"); backend {
+	case "":
+		return nil, nil
+	case "memory":
+		engine = search.NewMemoryEngine()
+	case "elasticsearch":
+		url := os.Getenv("ELASTICSEARCH_URL")
+		if url == "" {
+			return nil, errors.New("ELASTICSEARCH_URL is required by the elasticsearch search backend")
+		}
+		index := os.Getenv("ELASTICSEARCH_INDEX")
+		if index == "" {
+			index = "products"
+		}
+		engine = search.NewElasticEngine(url, index, &http.Client{Timeout: 10 * time.Second})
+	default:
+		return nil, fmt.Errorf("unknown search backend %q", backend)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if err := engine.Rebuild(ctx, products); err != nil {
+		return nil, err
+	}
+	return engine, nil
+}
+
+/*
+The newTenants function returns the registry of the tenants sharing the deployment, the ones of
+TENANTS and of the API keys of TENANT_KEYS (key:tenant,...), or nil if there are none. The catalog
+of every tenant is kept in TENANTS_DIR (tenants by default), in a store file of its own.
+*/
+func newTenants() (*tenant.Registry, error) {
+	keys, err := tenant.ParseKeys(os.Getenv("TENANT_KEYS"))
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, id := range strings.Split(os.Getenv("TENANTS"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 && len(keys) == 0 {
+		return nil, nil
+	}
+
+	dir := os.Getenv("TENANTS_DIR")
+	if dir == "" {
+		dir = "tenants"
+	}
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return tenant.NewRegistry(ids, keys, func(id string) (tenant.Catalog, error) {
+		return openTenantCatalog(filepath.Join(dir, id+".json"))
+	})
+}
+
+/*
+The openTenantCatalog function opens the catalog of a tenant kept in the given store file, which
+starts empty. Like the main catalog, its changes are recorded in a journal until they are saved in
+the file. The tenants have no product events, so their changes are not relayed nor audited.
+*/
+func openTenantCatalog(file string) (tenant.Catalog, error) {
+	journal, err := store.OpenJournal(file + ".journal")
+	if err != nil {
+		return tenant.Catalog{}, err
+	}
+	jsonStore := store.NewJsonStore(file, storeOptions()...)
+	if _, err = os.Stat(file); errors.Is(err, fs.ErrNotExist) {
+		err = jsonStore.Save(nil)
+	}
+	var state store.State
+	if err == nil {
+		state, err = loadState(jsonStore, journal)
+	}
+	if err == nil {
+		domain.NormalizeExpirations(state.Products)
+		err = journal.Compact(func() error { return jsonStore.SaveState(state) })
+	}
+	if err != nil {
+		_ = journal.Close()
+		return tenant.Catalog{}, err
+	}
+
+	service := product.NewService(product.NewJournaledRepository(product.NewRepository(state.Products), journal), nil).(*product.ServiceImpl)
+	service.ReserveIds(state.LastId)
+	return tenant.Catalog{
+		Service: service,
+		Flush: func() error {
+			return journal.Compact(func() error {
+				return jsonStore.SaveState(serviceState(service))
+			})
+		},
+	}, nil
+}
+
+/*
+The newSecretsProvider function returns the secret manager selected by SECRETS_PROVIDER: vault
+reads the KV version 2 secret VAULT_SECRET_PATH (go-web by default) mounted at VAULT_MOUNT (secret
+by default) of the VAULT_ADDR server with VAULT_TOKEN, and aws reads the AWS_SECRET_ID secret of
+AWS Secrets Manager in AWS_REGION with the AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and
+AWS_SESSION_TOKEN credentials. It returns nil if no secret manager is selected.
+*/
+func newSecretsProvider() (secrets.Provider, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	switch kind := os.Getenv("SECRETS_PROVIDER"); kind {
+	case "":
+		return nil, nil
+	case "vault":
+		address, token := os.Getenv("VAULT_ADDR"), os.Getenv("VAULT_TOKEN")
+		if address == "" || token == "" {
+			return nil, errors.New("SECRETS_PROVIDER=vault requires VAULT_ADDR and VAULT_TOKEN")
+		}
+		mount, path := os.Getenv("VAULT_MOUNT"), os.Getenv("VAULT_SECRET_PATH")
+		if mount == "" {
+			mount = "secret"
+		}
+		if path == "" {
+			path = "go-web"
+		}
+		return secrets.NewVaultProvider(address, token, mount, path, client), nil
+	case "aws":
+		region, secretId := os.Getenv("AWS_REGION"), os.Getenv("AWS_SECRET_ID")
+		credentials := secrets.AWSCredentials{
+			AccessKeyId:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		}
+		if region == "" || secretId == "" || credentials.AccessKeyId == "" || credentials.SecretAccessKey == "" {
+			return nil, errors.New("SECRETS_PROVIDER=aws requires AWS_REGION, AWS_SECRET_ID, AWS_ACCESS_KEY_ID and " +
+				"AWS_SECRET_ACCESS_KEY")
+		}
+		return secrets.NewAWSProvider(region, secretId, credentials, client), nil
+	default:
+		return nil, fmt.Errorf("unknown SECRETS_PROVIDER %q, expected vault or aws", kind)
+	}
+}
+
+/*
+The loadSecrets function applies the secrets of the secret manager, if any, as environment
+variables, so they are read like the ones of the environment file, and keeps them applied while
+they are rotated. The server does not start without its secrets, but a failed refresh keeps the
+current ones.
+*/
+func loadSecrets(signingSecret *config.Value[string]) error {
+	provider, err := newSecretsProvider()
+	if err != nil || provider == nil {
+		return err
+	}
+	refresh, err := time.ParseDuration(os.Getenv("SECRETS_REFRESH"))
+	if err != nil || refresh <= 0 {
+		refresh = 5 * time.Minute
+	}
+
+	rotator := secrets.NewRotator(provider, func(values map[string]string) {
+		for name, value := range values {
+			if err := os.Setenv(name, value); err != nil {
+				log.Printf("secrets: could not apply %s: %s\n", name, err)
+			}
+		}
+		if secret, ok := values["SIGNING_SECRET"]; ok {
+			signingSecret.Set(secret)
+		}
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if _, err = rotator.Load(ctx); err != nil {
+		return err
+	}
+	go rotator.Run(refresh, nil)
+
+	// The token of the secret manager is checked like the one of the environment, and so is the
+	// signing secret the signed requests need
+	var report config.EnvReport
+	report.CheckSecret("TOKEN", os.Getenv("TOKEN"), os.Getenv("DEV_MODE") == "true")
+	if os.Getenv("AUTH_MODE") == "signature" && signingSecret.Get() == "" {
+		report.Fail("SIGNING_SECRET", "is required with AUTH_MODE=signature")
+	}
+	for _, warning := range report.Warnings() {
+		log.Printf("config: %s\n", warning)
+	}
+	return report.Err()
+}
+
+/*
+The loadQuotas function returns the daily quotas of the API keys of API_QUOTAS, with the main
+token unlimited, from the given environment. The reserved keys "signature" and "anonymous" set the
+quota of the signed requests, unlimited by default, and the one of each client address without a
+credential, unmetered by default.
+*/
+func loadQuotas(env config.Env) (map[string]int, error) {
+	quotas, err := usage.ParseQuotas(env("API_QUOTAS"))
+	if err != nil {
+		return nil, err
+	}
+	if token := env("TOKEN"); token != "" {
+		if _, ok := quotas[token]; !ok {
+			quotas[token] = 0
+		}
+	}
+	if env("SIGNING_SECRET") != "" {
+		if _, ok := quotas[usage.Signature]; !ok {
+			quotas[usage.Signature] = 0
+		}
+	}
+	return quotas, nil
+}
+
+/*
+The newConfigReloader function returns the reload of the settings that can change while the
+server runs, read from the environment file. The token is read on every request, so it follows the
+environment without being applied, but it cannot be removed.
+*/
+func newConfigReloader(tracker *usage.Tracker, signingSecret *config.Value[string], corsOrigins *config.Value[[]string],
+	logSettings *logging.Settings) *config.Reloader {
+	reloader := config.NewReloader(func() (map[string]string, error) {
+		return readEnvFile()
+	})
+	reloader.Register("token", func(env config.Env) (func(), error) {
+		if env("TOKEN") == "" && os.Getenv("TOKEN") != "" {
+			return nil, errors.New("TOKEN cannot be removed")
+		}
+		return func() {}, nil
+	})
+	reloader.Register("signing_secret", func(env config.Env) (func(), error) {
+		secret := env("SIGNING_SECRET")
+		if secret == "" && signingSecret.Get() != "" {
+			return nil, errors.New("SIGNING_SECRET cannot be removed")
+		}
+		return func() { signingSecret.Set(secret) }, nil
+	})
+	reloader.Register("quotas", func(env config.Env) (func(), error) {
+		quotas, err := loadQuotas(env)
+		if err != nil {
+			return nil, err
+		}
+		return func() { tracker.SetQuotas(quotas) }, nil
+	})
+	reloader.Register("cors_origins", func(env config.Env) (func(), error) {
+		origins := config.SplitList(env("CORS_ALLOWED_ORIGINS"))
+		return func() { corsOrigins.Set(origins) }, nil
+	})
+	reloader.Register("log_level", func(env config.Env) (func(), error) {
+		level, err := logging.ParseLevel(env("LOG_LEVEL"))
+		if err != nil {
+			return nil, err
+		}
+		return func() {
+			options := logSettings.Options()
+			options.Level = level
+			logSettings.Apply(options)
+		}, nil
+	})
+	return reloader
+}
+
+// The reloadConfigOnHangup function reloads the configuration on every SIGHUP, logging the outcome.
+func reloadConfigOnHangup(reloader *config.Reloader) {
+	hangups := make(chan os.Signal, 1)
+	signal.Notify(hangups, syscall.SIGHUP)
+	for range hangups {
+		reloaded, err := reloader.Reload()
+		if err != nil {
+			log.Printf("config: SIGHUP reload failed: %s\n", err)
+			continue
+		}
+		log.Printf("config: %s reloaded by SIGHUP\n", strings.Join(reloaded, ", "))
+	}
+}
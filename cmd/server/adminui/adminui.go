@@ -0,0 +1,24 @@
+/*
+Package adminui contains the admin web UI embedded in the binary: a single page application,
+without build step, that browses, searches, creates, edits and deletes the products through the
+JSON API, with the token typed in the page.
+*/
+package adminui
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+//go:embed static
+var files embed.FS
+
+// The FS function returns the files of the admin UI, with index.html at its root.
+func FS() http.FileSystem {
+	static, err := fs.Sub(files, "static")
+	if err != nil {
+		panic(err)
+	}
+	return http.FS(static)
+}
@@ -0,0 +1,26 @@
+package adminui
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFS(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Group("/admin/ui").StaticFS("", FS())
+
+	page := httptest.NewRecorder()
+	router.ServeHTTP(page, httptest.NewRequest(http.MethodGet, "/admin/ui/", nil))
+	script := httptest.NewRecorder()
+	router.ServeHTTP(script, httptest.NewRequest(http.MethodGet, "/admin/ui/app.js", nil))
+
+	// Assertions
+	assert.Equal(t, http.StatusOK, page.Code)
+	assert.Contains(t, page.Body.String(), `<script src="app.js"`)
+	assert.Equal(t, http.StatusOK, script.Code)
+	assert.Contains(t, script.Header().Get("Content-Type"), "javascript")
+}
@@ -0,0 +1,180 @@
+// Admin UI of the products, talking to the JSON API of the same server.
+(function () {
+  'use strict';
+
+  const api = '/api/v1/products';
+  const pageSize = 20;
+  const state = {page: 1, lastPage: 1, query: ''};
+  const $ = (id) => document.getElementById(id);
+
+  // The token is kept for the browser tab only
+  $('token').value = sessionStorage.getItem('token') || '';
+  $('token-form').addEventListener('submit', (event) => {
+    event.preventDefault();
+    sessionStorage.setItem('token', $('token').value);
+    show('Token saved for this tab.');
+  });
+
+  // Sends a request to the API and returns the body, throwing the error response if it failed.
+  async function request(method, url, body) {
+    // The products are edited in their base locale, whatever the languages of the browser
+    const headers = {'Accept': 'application/json', 'Accept-Language': '*', 'token': sessionStorage.getItem('token') || ''};
+    if (body !== undefined) {
+      headers['Content-Type'] = 'application/json';
+    }
+    const response = await fetch(url, {method, headers, body: body === undefined ? undefined : JSON.stringify(body)});
+    if (response.status === 204) {
+      return null;
+    }
+    const payload = await response.json().catch(() => ({message: response.statusText}));
+    if (!response.ok) {
+      throw payload;
+    }
+    return payload;
+  }
+
+  function show(message, isError) {
+    $('message').textContent = message || '';
+    $('message').className = isError ? 'error' : '';
+  }
+
+  async function load() {
+    const params = new URLSearchParams({page: state.page, page_size: pageSize});
+    let url = api + '?' + params;
+    if (state.query) {
+      params.set('q', state.query);
+      url = api + '/search?' + params;
+    }
+    try {
+      const payload = await request('GET', url);
+      render(payload.data || [], payload.meta);
+      show('');
+    } catch (error) {
+      render([], null);
+      show(error.message || 'The products could not be loaded.', true);
+    }
+  }
+
+  function render(products, meta) {
+    const rows = products.map((product) => {
+      const row = document.createElement('tr');
+      const cells = [product.id, product.name, product.code_value, product.category || '', product.quantity,
+        product.price + ' ' + (product.currency || ''), product.expiration, product.status || 'draft'];
+      for (const value of cells) {
+        const cell = document.createElement('td');
+        cell.textContent = value;
+        row.appendChild(cell);
+      }
+      const actions = document.createElement('td');
+      actions.append(button('Edit', () => edit(product)), button('Delete', () => remove(product)));
+      row.appendChild(actions);
+      return row;
+    });
+    $('products').replaceChildren(...rows);
+
+    state.lastPage = meta ? Math.max(1, Math.ceil(meta.total / meta.page_size)) : 1;
+    $('page').textContent = meta ? `Page ${meta.page} of ${state.lastPage} (${meta.total} products)` : '';
+    $('prev').disabled = state.page <= 1;
+    $('next').disabled = state.page >= state.lastPage;
+  }
+
+  function button(label, onClick) {
+    const element = document.createElement('button');
+    element.type = 'button';
+    element.textContent = label;
+    element.addEventListener('click', onClick);
+    return element;
+  }
+
+  function edit(product) {
+    const form = $('product-form');
+    form.reset();
+    $('errors').replaceChildren();
+    $('editor-title').textContent = product ? `Edit product ${product.id}` : 'New product';
+    if (product) {
+      for (const field of ['id', 'name', 'code_value', 'category', 'description', 'quantity', 'price', 'currency', 'expiration']) {
+        form.elements[field].value = product[field] === undefined ? '' : product[field];
+      }
+      form.elements.status.value = product.status || 'draft';
+    } else {
+      form.elements.id.value = '';
+    }
+    $('editor').showModal();
+  }
+
+  async function save(event) {
+    event.preventDefault();
+    const form = $('product-form');
+    const product = {
+      name: form.elements.name.value,
+      code_value: form.elements.code_value.value,
+      category: form.elements.category.value,
+      description: form.elements.description.value,
+      quantity: Number(form.elements.quantity.value),
+      price: Number(form.elements.price.value),
+      currency: form.elements.currency.value.toUpperCase(),
+      expiration: form.elements.expiration.value,
+      status: form.elements.status.value,
+    };
+    const id = form.elements.id.value;
+    try {
+      if (id) {
+        await request('PUT', `${api}/${id}`, product);
+      } else {
+        await request('POST', api, product);
+      }
+      $('editor').close();
+      show(id ? `Product ${id} saved.` : 'Product created.');
+      await load();
+    } catch (error) {
+      const items = (error.fields || []).map((field) => `${field.field}: ${field.message}`);
+      if (items.length === 0) {
+        items.push(error.message || 'The product could not be saved.');
+      }
+      $('errors').replaceChildren(...items.map((text) => {
+        const item = document.createElement('li');
+        item.textContent = text;
+        return item;
+      }));
+    }
+  }
+
+  async function remove(product) {
+    if (!confirm(`Delete the product ${product.id} (${product.name})?`)) {
+      return;
+    }
+    try {
+      await request('DELETE', `${api}/${product.id}`);
+      show(`Product ${product.id} deleted.`);
+      await load();
+    } catch (error) {
+      show(error.message || 'The product could not be deleted.', true);
+    }
+  }
+
+  $('search-form').addEventListener('submit', (event) => {
+    event.preventDefault();
+    state.query = $('query').value.trim();
+    state.page = 1;
+    load();
+  });
+  $('clear').addEventListener('click', () => {
+    $('query').value = '';
+    state.query = '';
+    state.page = 1;
+    load();
+  });
+  $('prev').addEventListener('click', () => {
+    state.page--;
+    load();
+  });
+  $('next').addEventListener('click', () => {
+    state.page++;
+    load();
+  });
+  $('new').addEventListener('click', () => edit(null));
+  $('cancel').addEventListener('click', () => $('editor').close());
+  $('product-form').addEventListener('submit', save);
+
+  load();
+})();
@@ -0,0 +1,125 @@
+package command
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/JoseObreque/go-web/pkg/secrets"
+	natsgo "github.com/nats-io/nats.go"
+	"log"
+)
+
+// Actions that can be requested through a remote command.
+const (
+	ActionPublish   = "publish"
+	ActionUnpublish = "unpublish"
+	ActionDelete    = "delete"
+)
+
+var (
+	ErrInvalidToken  = errors.New("invalid token")
+	ErrInvalidAction = errors.New("invalid action")
+)
+
+/*
+The Command struct represents a remote command received through NATS. Commands modify products,
+so they must carry the API token, just like the HTTP and gRPC mutations.
+*/
+type Command struct {
+	Action string `json:"action"`
+	Id     int    `json:"id"`
+	Token  string `json:"token"`
+}
+
+// The Reply struct is the answer sent back to the requester of a command.
+type Reply struct {
+	Product *domain.Product `json:"product,omitempty"`
+	Error   string          `json:"error,omitempty"`
+}
+
+// The Listener struct executes the remote commands over the product service.
+type Listener struct {
+	service product.Service
+	// Check rejecting the commands while the catalog is read-only, nil if it is never read-only
+	readOnly func() error
+}
+
+// The NewListener function returns a new Listener backed by the given service.
+func NewListener(service product.Service) *Listener {
+	return &Listener{
+		service: service,
+	}
+}
+
+/*
+The WithReadOnly method makes the listener reject the commands while readOnly returns an error, like
+during a maintenance.
+*/
+func (l *Listener) WithReadOnly(readOnly func() error) *Listener {
+	l.readOnly = readOnly
+	return l
+}
+
+/*
+The Subscribe method starts listening for commands on the given subject. Requesters get a Reply
+with the updated product or the error of the command.
+*/
+func (l *Listener) Subscribe(conn *natsgo.Conn, subject string) (*natsgo.Subscription, error) {
+	return conn.Subscribe(subject, func(msg *natsgo.Msg) {
+		reply := l.Handle(msg.Data)
+		if msg.Reply == "" {
+			return
+		}
+
+		data, err := json.Marshal(reply)
+		if err != nil {
+			log.Printf("command: could not encode reply: %s\n", err)
+			return
+		}
+		if err = msg.Respond(data); err != nil {
+			log.Printf("command: could not send reply: %s\n", err)
+		}
+	})
+}
+
+// The Handle method decodes and executes a command, returning the reply for the requester.
+func (l *Listener) Handle(data []byte) Reply {
+	var cmd Command
+	if err := json.Unmarshal(data, &cmd); err != nil {
+		return Reply{Error: err.Error()}
+	}
+
+	updatedProduct, err := l.execute(cmd)
+	if err != nil {
+		return Reply{Error: err.Error()}
+	}
+	return Reply{Product: updatedProduct}
+}
+
+// Auxiliary method that executes an already decoded command.
+func (l *Listener) execute(cmd Command) (*domain.Product, error) {
+	if !secrets.ValidToken(cmd.Token) {
+		return nil, ErrInvalidToken
+	}
+	if l.readOnly != nil {
+		if err := l.readOnly(); err != nil {
+			return nil, err
+		}
+	}
+
+	switch cmd.Action {
+	case ActionPublish, ActionUnpublish:
+		// Only the status changes: a published product is unpublished back to a draft
+		status := domain.StatusOf(cmd.Action == ActionPublish)
+		updatedProduct, err := l.service.Patch(cmd.Id, domain.ProductRequest{Status: &status})
+		if err != nil {
+			return nil, err
+		}
+		return &updatedProduct, nil
+	case ActionDelete:
+		return nil, l.service.Delete(cmd.Id)
+	default:
+		return nil, ErrInvalidAction
+	}
+}
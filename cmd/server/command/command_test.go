@@ -0,0 +1,71 @@
+package command
+
+import (
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func createListenerForTestProducts() (*Listener, product.Service) {
+	// Token settings
+	if err := os.Setenv("TOKEN", "12345"); err != nil {
+		panic(err)
+	}
+
+	repository := product.NewRepository([]domain.Product{
+		{Id: 1, Name: "Oil", Quantity: 10, CodeValue: "A1", Status: domain.StatusPublished, Expiration: "15/12/2030", Price: 71.42},
+		{Id: 2, Name: "Pineapple", Quantity: 20, CodeValue: "B2", Status: domain.StatusPublished, Expiration: "09/08/2030", Price: 352.79},
+	})
+	service := product.NewService(repository, nil)
+	return NewListener(service), service
+}
+
+func TestListener_Handle(t *testing.T) {
+	t.Run("Unpublish", func(t *testing.T) {
+		listener, service := createListenerForTestProducts()
+
+		reply := listener.Handle([]byte(`{"action":"unpublish","id":1,"token":"12345"}`))
+		storedProduct, _ := service.GetById(1)
+
+		// Assertions
+		assert.Empty(t, reply.Error)
+		assert.False(t, reply.Product.Published())
+		assert.False(t, storedProduct.Published())
+		assert.Equal(t, "Oil", storedProduct.Name)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		listener, service := createListenerForTestProducts()
+
+		reply := listener.Handle([]byte(`{"action":"delete","id":2,"token":"12345"}`))
+		_, err := service.GetById(2)
+
+		// Assertions
+		assert.Empty(t, reply.Error)
+		assert.Nil(t, reply.Product)
+		assert.Error(t, err)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		listener, _ := createListenerForTestProducts()
+
+		// Assertions
+		assert.Equal(t, ErrInvalidToken.Error(), listener.Handle([]byte(`{"action":"delete","id":1}`)).Error)
+		assert.Equal(t, ErrInvalidAction.Error(), listener.Handle([]byte(`{"action":"rename","id":1,"token":"12345"}`)).Error)
+		assert.NotEmpty(t, listener.Handle([]byte(`not json`)).Error)
+	})
+
+	t.Run("Without a configured token", func(t *testing.T) {
+		listener, service := createListenerForTestProducts()
+		t.Setenv("TOKEN", "")
+
+		reply := listener.Handle([]byte(`{"action":"delete","id":1,"token":""}`))
+		_, err := service.GetById(1)
+
+		// Assertions
+		assert.Equal(t, ErrInvalidToken.Error(), reply.Error)
+		assert.NoError(t, err)
+	})
+}
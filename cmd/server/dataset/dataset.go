@@ -0,0 +1,23 @@
+/*
+Package dataset contains the default products embedded in the binary, used when the store file
+does not exist yet, so the server runs out of the box in demos and CI.
+*/
+package dataset
+
+import (
+	_ "embed"
+	"encoding/json"
+	"github.com/JoseObreque/go-web/internal/domain"
+)
+
+//go:embed products.json
+var raw []byte
+
+// The Products function returns a copy of the default products.
+func Products() ([]domain.Product, error) {
+	var products []domain.Product
+	if err := json.Unmarshal(raw, &products); err != nil {
+		return nil, err
+	}
+	return products, nil
+}
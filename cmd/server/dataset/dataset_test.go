@@ -0,0 +1,16 @@
+package dataset
+
+import (
+	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestProducts(t *testing.T) {
+	products, err := Products()
+
+	// Assertions
+	assert.NoError(t, err)
+	assert.NotEmpty(t, products)
+	assert.NoError(t, product.ValidateCatalog(products).Err())
+}
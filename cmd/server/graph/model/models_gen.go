@@ -0,0 +1,45 @@
+// Code generated by github.com/99designs/gqlgen, DO NOT EDIT.
+
+package model
+
+import (
+	"github.com/JoseObreque/go-web/internal/domain"
+)
+
+type NewProduct struct {
+	Name        string  `json:"name"`
+	Quantity    int     `json:"quantity"`
+	CodeValue   string  `json:"codeValue"`
+	Status      *string `json:"status,omitempty"`
+	IsPublished *bool   `json:"isPublished,omitempty"`
+	Expiration  string  `json:"expiration"`
+	Price       float64 `json:"price"`
+	Category    *string `json:"category,omitempty"`
+}
+
+type ProductFilter struct {
+	Name        *string  `json:"name,omitempty"`
+	Status      *string  `json:"status,omitempty"`
+	IsPublished *bool    `json:"isPublished,omitempty"`
+	PriceGt     *float64 `json:"priceGt,omitempty"`
+	PriceLt     *float64 `json:"priceLt,omitempty"`
+	Category    *string  `json:"category,omitempty"`
+}
+
+type ProductPage struct {
+	Items  []*domain.Product `json:"items"`
+	Total  int               `json:"total"`
+	Offset int               `json:"offset"`
+	Limit  int               `json:"limit"`
+}
+
+type ProductUpdate struct {
+	Name        *string  `json:"name,omitempty"`
+	Quantity    *int     `json:"quantity,omitempty"`
+	CodeValue   *string  `json:"codeValue,omitempty"`
+	Status      *string  `json:"status,omitempty"`
+	IsPublished *bool    `json:"isPublished,omitempty"`
+	Expiration  *string  `json:"expiration,omitempty"`
+	Price       *float64 `json:"price,omitempty"`
+	Category    *string  `json:"category,omitempty"`
+}
@@ -0,0 +1,153 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/JoseObreque/go-web/cmd/server/graph/model"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/JoseObreque/go-web/pkg/secrets"
+	"github.com/gin-gonic/gin"
+	"github.com/vektah/gqlparser/v2/ast"
+	"net/http"
+	"strings"
+)
+
+// This file will not be regenerated automatically.
+//
+// It serves as dependency injection for your app, add any dependencies you require here.
+
+var ErrInvalidToken = errors.New("invalid token")
+
+// Key of the result of the authentication of the request stored in the resolvers context.
+type authKey struct{}
+
+// The authResult struct is the outcome of the authentication of a request, nil if it succeeded.
+type authResult struct {
+	err error
+}
+
+// The Resolver struct is the root resolver of the GraphQL schema.
+type Resolver struct {
+	service product.Service
+}
+
+// The NewResolver function returns a new root resolver backed by the given product service.
+func NewResolver(service product.Service) *Resolver {
+	return &Resolver{
+		service: service,
+	}
+}
+
+/*
+The WithAuth function returns a gin middleware that authenticates the request with the given check
+and stores its result in the context, so mutation resolvers can authorize the caller. The queries
+need no authentication, so the request goes on either way.
+*/
+func WithAuth(check func(c *gin.Context) error) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := context.WithValue(c.Request.Context(), authKey{}, authResult{err: check(c)})
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// The WithToken function returns a WithAuth middleware authenticating the "token" header of the request.
+func WithToken() gin.HandlerFunc {
+	return WithAuth(CheckToken)
+}
+
+// The CheckToken function checks that the "token" header of the request is the API token.
+func CheckToken(c *gin.Context) error {
+	token := c.GetHeader("token")
+	if !secrets.ValidToken(token) {
+		return ErrInvalidToken
+	}
+	return nil
+}
+
+// The Option type configures the GraphQL server.
+type Option func(server *handler.Server)
+
+/*
+The WithReadOnly function returns an Option that rejects the mutations while readOnly returns an
+error, like during a maintenance. The queries keep being resolved.
+*/
+func WithReadOnly(readOnly func() error) Option {
+	return func(server *handler.Server) {
+		server.AroundOperations(func(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+			if graphql.GetOperationContext(ctx).Operation.Operation == ast.Mutation {
+				if err := readOnly(); err != nil {
+					return graphql.OneShot(graphql.ErrorResponse(ctx, "%s", err))
+				}
+			}
+			return next(ctx)
+		})
+	}
+}
+
+/*
+The NewServer function returns an http.Handler that executes GraphQL requests against the schema,
+resolving them with the given product service.
+*/
+func NewServer(service product.Service, options ...Option) http.Handler {
+	server := handler.NewDefaultServer(NewExecutableSchema(Config{Resolvers: NewResolver(service)}))
+	for _, option := range options {
+		option(server)
+	}
+	return server
+}
+
+// Auxiliary function that returns the error of the authentication stored in the context, if any.
+func isAuthorized(ctx context.Context) error {
+	result, ok := ctx.Value(authKey{}).(authResult)
+	if !ok {
+		return ErrInvalidToken
+	}
+	return result.err
+}
+
+// Auxiliary function that checks if a product matches every present field of a filter.
+func matchesFilter(p domain.Product, filter *model.ProductFilter) bool {
+	if filter == nil {
+		return true
+	}
+	if filter.Name != nil && !strings.Contains(strings.ToLower(p.Name), strings.ToLower(*filter.Name)) {
+		return false
+	}
+	if filter.Status != nil && p.Status.OrDraft() != domain.Status(*filter.Status) {
+		return false
+	}
+	if filter.IsPublished != nil && p.Published() != *filter.IsPublished {
+		return false
+	}
+	if filter.PriceGt != nil && p.Price <= *filter.PriceGt {
+		return false
+	}
+	if filter.PriceLt != nil && p.Price >= *filter.PriceLt {
+		return false
+	}
+	if filter.Category != nil && p.Category != *filter.Category {
+		return false
+	}
+	return true
+}
+
+/*
+Auxiliary function that returns the status of a product input, nil if it has none. The status wins
+over the isPublished flag it replaced, which is the published or draft status.
+*/
+func inputStatus(status *string, isPublished *bool) *domain.Status {
+	switch {
+	case status != nil:
+		inputStatus := domain.Status(*status)
+		return &inputStatus
+	case isPublished != nil:
+		inputStatus := domain.StatusOf(*isPublished)
+		return &inputStatus
+	default:
+		return nil
+	}
+}
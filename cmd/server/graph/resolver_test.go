@@ -0,0 +1,146 @@
+package graph
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func createServerForTestGraphQL() *gin.Engine {
+	return createServerForTestGraphQLWithAuth(WithToken())
+}
+
+func createServerForTestGraphQLWithAuth(auth gin.HandlerFunc) *gin.Engine {
+	// Token settings
+	if err := os.Setenv("TOKEN", "12345"); err != nil {
+		panic(err)
+	}
+
+	// Create a service with a few products
+	repository := product.NewRepository([]domain.Product{
+		{Id: 1, Name: "Oil - Margarine", Quantity: 10, CodeValue: "A1", Status: domain.StatusPublished, Expiration: "15/12/2030", Price: 71.42},
+		{Id: 2, Name: "Pineapple - Canned", Quantity: 20, CodeValue: "B2", Status: domain.StatusPublished, Expiration: "09/08/2030", Price: 352.79},
+		{Id: 3, Name: "Wine - Red", Quantity: 30, CodeValue: "C3", Status: domain.StatusDraft, Expiration: "24/05/2030", Price: 179.23},
+	})
+	service := product.NewService(repository, nil)
+
+	router := gin.New()
+	router.POST("/graphql", auth, gin.WrapH(NewServer(service)))
+	return router
+}
+
+func createGraphQLRequest(query string, token string) (*http.Request, *httptest.ResponseRecorder) {
+	body, err := json.Marshal(map[string]string{"query": query})
+	if err != nil {
+		panic(err)
+	}
+
+	request := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewBuffer(body))
+	request.Header.Add("Content-Type", "application/json")
+	if token != "" {
+		request.Header.Add("token", token)
+	}
+
+	return request, httptest.NewRecorder()
+}
+
+func TestGraphQL_Products(t *testing.T) {
+	router := createServerForTestGraphQL()
+	request, responseRecorder := createGraphQLRequest(
+		`{ products(filter: {isPublished: true, priceGt: 100}, limit: 10) { total items { id codeValue } } }`,
+		"",
+	)
+
+	// Actual response
+	router.ServeHTTP(responseRecorder, request)
+	var actualResponse struct {
+		Data struct {
+			Products struct {
+				Total int
+				Items []struct {
+					Id        string
+					CodeValue string
+				}
+			}
+		}
+	}
+	err := json.Unmarshal(responseRecorder.Body.Bytes(), &actualResponse)
+	if err != nil {
+		panic(err)
+	}
+
+	// Assertions
+	assert.Equal(t, http.StatusOK, responseRecorder.Code)
+	assert.Equal(t, 1, actualResponse.Data.Products.Total)
+	assert.Equal(t, "B2", actualResponse.Data.Products.Items[0].CodeValue)
+}
+
+func TestGraphQL_Mutations(t *testing.T) {
+	t.Run("Unauthorized", func(t *testing.T) {
+		router := createServerForTestGraphQL()
+		request, responseRecorder := createGraphQLRequest(`mutation { deleteProduct(id: 1) }`, "")
+
+		// Actual response
+		router.ServeHTTP(responseRecorder, request)
+		actualResponse := map[string]interface{}{}
+		err := json.Unmarshal(responseRecorder.Body.Bytes(), &actualResponse)
+		if err != nil {
+			panic(err)
+		}
+
+		// Assertions
+		assert.NotEmpty(t, actualResponse["errors"])
+	})
+	t.Run("Token when signatures are required", func(t *testing.T) {
+		router := createServerForTestGraphQLWithAuth(WithAuth(func(c *gin.Context) error { return errors.New("invalid signature") }))
+		request, responseRecorder := createGraphQLRequest(`mutation { deleteProduct(id: 1) }`, "12345")
+
+		// Actual response
+		router.ServeHTTP(responseRecorder, request)
+		actualResponse := map[string]interface{}{}
+		err := json.Unmarshal(responseRecorder.Body.Bytes(), &actualResponse)
+		if err != nil {
+			panic(err)
+		}
+
+		// Assertions
+		assert.NotEmpty(t, actualResponse["errors"])
+		assert.Nil(t, actualResponse["data"])
+	})
+	t.Run("Partial update", func(t *testing.T) {
+		router := createServerForTestGraphQL()
+		request, responseRecorder := createGraphQLRequest(
+			`mutation { updateProduct(id: 3, input: {price: 200}) { price isPublished codeValue } }`,
+			"12345",
+		)
+
+		// Actual response
+		router.ServeHTTP(responseRecorder, request)
+		var actualResponse struct {
+			Data struct {
+				UpdateProduct struct {
+					Price       float64
+					IsPublished bool
+					CodeValue   string
+				}
+			}
+		}
+		err := json.Unmarshal(responseRecorder.Body.Bytes(), &actualResponse)
+		if err != nil {
+			panic(err)
+		}
+
+		// Assertions
+		assert.Equal(t, 200.0, actualResponse.Data.UpdateProduct.Price)
+		assert.False(t, actualResponse.Data.UpdateProduct.IsPublished)
+		assert.Equal(t, "C3", actualResponse.Data.UpdateProduct.CodeValue)
+	})
+}
@@ -0,0 +1,154 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.31
+
+import (
+	"context"
+	"errors"
+
+	"github.com/JoseObreque/go-web/cmd/server/graph/model"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/product"
+)
+
+// CreateProduct is the resolver for the createProduct field.
+func (r *mutationResolver) CreateProduct(ctx context.Context, input model.NewProduct) (*domain.Product, error) {
+	if err := isAuthorized(ctx); err != nil {
+		return nil, err
+	}
+
+	newProduct := domain.Product{
+		Name:       input.Name,
+		Quantity:   input.Quantity,
+		CodeValue:  input.CodeValue,
+		Expiration: input.Expiration,
+		Price:      input.Price,
+	}
+	if status := inputStatus(input.Status, input.IsPublished); status != nil {
+		newProduct.Status = *status
+	}
+	if input.Category != nil {
+		newProduct.Category = *input.Category
+	}
+	if err := domain.ValidateExpiration(newProduct.Expiration); err != nil {
+		return nil, err
+	}
+
+	createdProduct, err := r.service.Create(newProduct)
+	if err != nil {
+		return nil, err
+	}
+	return &createdProduct, nil
+}
+
+// UpdateProduct is the resolver for the updateProduct field.
+func (r *mutationResolver) UpdateProduct(ctx context.Context, id int, input model.ProductUpdate) (*domain.Product, error) {
+	if err := isAuthorized(ctx); err != nil {
+		return nil, err
+	}
+
+	// Only the fields present in the input are changed
+	if input.Expiration != nil {
+		if err := domain.ValidateExpiration(*input.Expiration); err != nil {
+			return nil, err
+		}
+	}
+	update := domain.ProductRequest{
+		Name:       input.Name,
+		Quantity:   input.Quantity,
+		CodeValue:  input.CodeValue,
+		Status:     inputStatus(input.Status, input.IsPublished),
+		Expiration: input.Expiration,
+		Price:      input.Price,
+		Category:   input.Category,
+	}
+
+	updatedProduct, err := r.service.Patch(id, update)
+	if err != nil {
+		return nil, err
+	}
+	return &updatedProduct, nil
+}
+
+// DeleteProduct is the resolver for the deleteProduct field.
+func (r *mutationResolver) DeleteProduct(ctx context.Context, id int) (bool, error) {
+	if err := isAuthorized(ctx); err != nil {
+		return false, err
+	}
+
+	if err := r.service.Delete(id); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Status is the resolver for the status field.
+func (r *productResolver) Status(ctx context.Context, obj *domain.Product) (string, error) {
+	return string(obj.Status.OrDraft()), nil
+}
+
+// Product is the resolver for the product field.
+func (r *queryResolver) Product(ctx context.Context, id int) (*domain.Product, error) {
+	targetProduct, err := r.service.GetById(id)
+	if errors.Is(err, product.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &targetProduct, nil
+}
+
+// Products is the resolver for the products field.
+func (r *queryResolver) Products(ctx context.Context, filter *model.ProductFilter, offset *int, limit *int) (*model.ProductPage, error) {
+	// Apply the filters
+	products, err := r.service.List()
+	if err != nil {
+		return nil, err
+	}
+	var filtered []*domain.Product
+	for _, p := range products {
+		if matchesFilter(p, filter) {
+			p := p
+			filtered = append(filtered, &p)
+		}
+	}
+
+	// Apply the pagination
+	page := &model.ProductPage{
+		Items:  []*domain.Product{},
+		Total:  len(filtered),
+		Offset: 0,
+		Limit:  50,
+	}
+	if offset != nil && *offset > 0 {
+		page.Offset = *offset
+	}
+	if limit != nil && *limit >= 0 {
+		page.Limit = *limit
+	}
+	if page.Offset < len(filtered) {
+		end := page.Offset + page.Limit
+		if end > len(filtered) {
+			end = len(filtered)
+		}
+		page.Items = filtered[page.Offset:end]
+	}
+
+	return page, nil
+}
+
+// Mutation returns MutationResolver implementation.
+func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
+
+// Product returns ProductResolver implementation.
+func (r *Resolver) Product() ProductResolver { return &productResolver{r} }
+
+// Query returns QueryResolver implementation.
+func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
+
+type mutationResolver struct{ *Resolver }
+type productResolver struct{ *Resolver }
+type queryResolver struct{ *Resolver }
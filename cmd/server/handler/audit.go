@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"github.com/JoseObreque/go-web/internal/audit"
+	"github.com/JoseObreque/go-web/pkg/web"
+	"github.com/gin-gonic/gin"
+	"strconv"
+)
+
+// AuditHandler is a handler for the audit log administration endpoints.
+type AuditHandler struct {
+	log *audit.Log
+}
+
+// The NewAuditHandler function returns a new AuditHandler that reads the provided audit log.
+func NewAuditHandler(log *audit.Log) *AuditHandler {
+	return &AuditHandler{
+		log: log,
+	}
+}
+
+// GetAll godoc
+// @Summary List audit entries
+// @Tags Audit
+// @Description List the most recent changes made to the products, oldest first. The history of a product includes
+// @Description the changes of the duplicates merged into it (product.merged entries).
+// @Produce json
+// @Param token header string true "Token"
+// @Param product_id query int false "Only the changes of this product"
+// @Success 200 {object} web.Response
+// @Failure 400 {object} web.ErrorResponse
+// @Failure 401 {object} web.ErrorResponse
+// @Router /admin/audit [get]
+func (h *AuditHandler) GetAll() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		stringId, ok := c.GetQuery("product_id")
+		if !ok {
+			web.Success(c, 200, h.log.Entries())
+			return
+		}
+		id, err := strconv.Atoi(stringId)
+		if err != nil {
+			web.Failure(c, 400, ErrInvalidId)
+			return
+		}
+		web.Success(c, 200, h.log.History(id))
+	}
+}
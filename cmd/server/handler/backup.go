@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/JoseObreque/go-web/pkg/store"
+	"github.com/JoseObreque/go-web/pkg/web"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+var (
+	ErrBackupDirNotConfigured = errors.New("no backup directory configured")
+	ErrInvalidSnapshot        = errors.New("invalid snapshot")
+)
+
+// The BackupResult struct describes a snapshot written to the backup directory or restored.
+type BackupResult struct {
+	File      string    `json:"file,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	Count     int       `json:"count"`
+	Checksum  string    `json:"checksum"`
+}
+
+// BackupHandler is a handler for the backup and restore endpoints.
+type BackupHandler struct {
+	service product.Service
+	dir     string
+}
+
+/*
+The NewBackupHandler function returns a new BackupHandler for the given service. Snapshots are
+written to and restored from the given directory; if it is empty, they can only be downloaded
+and uploaded.
+*/
+func NewBackupHandler(service product.Service, dir string) *BackupHandler {
+	return &BackupHandler{
+		service: service,
+		dir:     dir,
+	}
+}
+
+// Backup godoc
+// @Summary Back up the products
+// @Tags Backups
+// @Description Take a snapshot of every product with its SHA-256 checksum. The snapshot is written to the backup
+// @Description directory, or downloaded if there is none or download is true.
+// @Produce json
+// @Param token header string true "Token"
+// @Param download query bool false "Download the snapshot instead of writing it to the backup directory"
+// @Success 200 {object} store.Snapshot
+// @Success 201 {object} web.Response
+// @Failure 401 {object} web.ErrorResponse
+// @Failure 500 {object} web.ErrorResponse
+// @Router /admin/backup [post]
+func (h *BackupHandler) Backup() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		snapshot, err := store.NewSnapshot(h.service.GetAll(), time.Now())
+		if err != nil {
+			web.Failure(c, 500, err)
+			return
+		}
+
+		if h.dir == "" || c.Query("download") == "true" {
+			c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", snapshot.FileName()))
+			c.JSON(http.StatusOK, snapshot)
+			return
+		}
+
+		if _, err = store.WriteSnapshot(h.dir, snapshot); err != nil {
+			web.Failure(c, 500, err)
+			return
+		}
+		web.Created(c, BackupResult{
+			File:      snapshot.FileName(),
+			CreatedAt: snapshot.CreatedAt,
+			Count:     snapshot.Count,
+			Checksum:  snapshot.Checksum,
+		})
+	}
+}
+
+// Restore godoc
+// @Summary Restore the products
+// @Tags Backups
+// @Description Replace every product with those of a snapshot, either a file of the backup directory or an uploaded
+// @Description snapshot. The snapshot is rejected if its checksum does not match or if its products are not valid.
+// @Accept json
+// @Produce json
+// @Param token header string true "Token"
+// @Param file query string false "Name of a snapshot file of the backup directory"
+// @Param snapshot body store.Snapshot false "Uploaded snapshot, if no file is given"
+// @Success 200 {object} web.Response
+// @Failure 400 {object} web.ErrorResponse
+// @Failure 401 {object} web.ErrorResponse
+// @Failure 404 {object} web.ErrorResponse
+// @Failure 422 {object} web.ErrorResponse
+// @Router /admin/restore [post]
+func (h *BackupHandler) Restore() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var snapshot store.Snapshot
+		var err error
+		file := c.Query("file")
+		switch {
+		case file != "" && h.dir == "":
+			err = ErrBackupDirNotConfigured
+		case file != "":
+			snapshot, err = store.ReadSnapshot(h.dir, file)
+		default:
+			if c.ShouldBindJSON(&snapshot) != nil {
+				err = ErrInvalidSnapshot
+			}
+		}
+		if err != nil {
+			web.Error(c, err)
+			return
+		}
+
+		// Nothing is replaced unless the snapshot is intact and its products are valid
+		if err = snapshot.Verify(); err != nil {
+			web.Error(c, err)
+			return
+		}
+		if report := product.ValidateCatalog(snapshot.Products); report.Err() != nil {
+			web.Error(c, web.WithParams(report.Err(), web.Params{"quarantined": len(report.Quarantined), "total": report.Total}))
+			return
+		}
+
+		h.service.Restore(snapshot.Products)
+		web.Success(c, 200, BackupResult{
+			File:      file,
+			CreatedAt: snapshot.CreatedAt,
+			Count:     snapshot.Count,
+			Checksum:  snapshot.Checksum,
+		})
+	}
+}
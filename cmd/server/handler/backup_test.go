@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/JoseObreque/go-web/pkg/store"
+	"github.com/JoseObreque/go-web/pkg/webtest"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestBackupHandler(t *testing.T) {
+	service := product.NewService(product.NewRepository([]domain.Product{
+		{Id: 1, Name: "Milk", Quantity: 10, CodeValue: "A1", Status: domain.StatusPublished, Expiration: "15/12/2030", Price: 10},
+		{Id: 2, Name: "Oil", Quantity: 10, CodeValue: "B2", Status: domain.StatusPublished, Expiration: "15/12/2030", Price: 20},
+	}), nil)
+	backupHandler := NewBackupHandler(service, t.TempDir())
+
+	router := gin.New()
+	router.POST("/admin/backup", backupHandler.Backup())
+	router.POST("/admin/restore", backupHandler.Restore())
+
+	client := webtest.NewClient(t, router)
+
+	backup := client.Post("/admin/backup", nil)
+	result := webtest.Data[BackupResult](backup)
+
+	// The products change after the backup, and the restore brings them back
+	_ = service.Delete(2)
+	restore := client.Post("/admin/restore?file="+result.File, nil)
+
+	// A tampered snapshot is rejected
+	products := append([]domain.Product{}, service.GetAll()...)
+	snapshot, _ := store.NewSnapshot(products, result.CreatedAt)
+	snapshot.Products[0].Price = 1
+	tampered := client.Post("/admin/restore", snapshot)
+
+	// An intact snapshot with invalid products is rejected too
+	invalidSnapshot, _ := store.NewSnapshot([]domain.Product{products[0], products[0]}, result.CreatedAt)
+	invalid := client.Post("/admin/restore", invalidSnapshot)
+
+	// Assertions
+	assert.Equal(t, http.StatusCreated, backup.Code)
+	assert.Equal(t, 2, result.Count)
+	assert.Equal(t, http.StatusOK, restore.Code)
+	assert.Len(t, service.GetAll(), 2)
+	assert.Equal(t, http.StatusUnprocessableEntity, tampered.Code)
+	assert.Equal(t, "invalid_catalog", invalid.Error().ErrorCode)
+	assert.Equal(t, 10.0, service.GetAll()[0].Price)
+}
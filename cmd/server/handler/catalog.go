@@ -0,0 +1,296 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/JoseObreque/go-web/cmd/server/storefront"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/JoseObreque/go-web/internal/related"
+	"github.com/JoseObreque/go-web/pkg/export"
+	"github.com/JoseObreque/go-web/pkg/markdown"
+	"github.com/JoseObreque/go-web/pkg/web"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
+	"html/template"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Number of related products shown on the page of a product.
+const relatedShown = 4
+
+/*
+The CatalogHandler struct is a handler for the HTML pages of the public catalog, a simple
+storefront of the published products rendered by the server, and for the sitemap and the product
+feed pointing to them. The unpublished products, and the published ones outside their publish
+window, are not shown, as if they did not exist.
+*/
+type CatalogHandler struct {
+	service   product.Service
+	templates *template.Template
+	// Absolute URL the pages are served at, without trailing slash; empty uses the host of each request
+	publicURL string
+	// Generated sitemaps and feeds of the current catalog version, by name and URL of the pages
+	mu        sync.Mutex
+	version   string
+	documents map[string]catalogDocument
+}
+
+// The catalogDocument struct is a generated sitemap or feed.
+type catalogDocument struct {
+	content []byte
+	// Moment a publish window of the catalog opens or closes, which outdates the document; zero if none does
+	expires time.Time
+}
+
+// The catalogPage struct holds the data of the page listing the products.
+type catalogPage struct {
+	Title    string
+	Products []domain.Product
+	Total    int
+	Page     int
+	LastPage int
+	// Links to the previous and next pages, empty if they do not exist
+	Prev, Next string
+}
+
+// The productPage struct holds the data of the page of a single product.
+type productPage struct {
+	Title   string
+	Product domain.Product
+	// Description rendered from Markdown to sanitized HTML
+	Description template.HTML
+	// Products shown as "you may also like"
+	Related []domain.Product
+}
+
+// The errorPage struct holds the data of the page of a failed request.
+type errorPage struct {
+	Title   string
+	Message string
+}
+
+// The NewCatalogHandler function returns a CatalogHandler showing the products of the given service.
+func NewCatalogHandler(service product.Service) *CatalogHandler {
+	return &CatalogHandler{
+		service:   service,
+		templates: storefront.Templates(),
+		documents: make(map[string]catalogDocument),
+	}
+}
+
+/*
+The WithPublicURL method sets the absolute URL the catalog pages are served at, like
+https://shop.example.com, used by the links of the sitemap and the product feed. Without it, the
+links use the scheme and host of each request.
+*/
+func (h *CatalogHandler) WithPublicURL(publicURL string) *CatalogHandler {
+	h.publicURL = strings.TrimSuffix(publicURL, "/")
+	return h
+}
+
+/*
+The List method returns a handler that renders a page of the published products, selected with the
+page and page_size query parameters like in the products list of the API.
+*/
+func (h *CatalogHandler) List() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, _, err := web.ParsePage(c)
+		if err != nil {
+			h.renderError(c, http.StatusBadRequest, "The page number and size must be positive numbers.")
+			return
+		}
+		if page.Size == 0 {
+			page = web.Page{Number: 1, Size: web.DefaultPageSize}
+		}
+
+		published, _ := h.live(time.Now())
+		start, end := page.Bounds(len(published))
+		data := catalogPage{
+			Title:    "Catalog",
+			Products: localizeProducts(c, published[start:end]),
+			Total:    len(published),
+			Page:     page.Number,
+			LastPage: (len(published) + page.Size - 1) / page.Size,
+		}
+		if data.LastPage < 1 {
+			data.LastPage = 1
+		}
+		switch {
+		case page.Number > data.LastPage+1:
+			// Pages beyond the end go back to the last one
+			data.Prev = catalogLink(data.LastPage, page.Size)
+		case page.Number > 1:
+			data.Prev = catalogLink(page.Number-1, page.Size)
+		}
+		if page.Number < data.LastPage {
+			data.Next = catalogLink(page.Number+1, page.Size)
+		}
+		h.render(c, http.StatusOK, "catalog.html", data)
+	}
+}
+
+// The Get method returns a handler that renders the page of a published product, selected by its ID.
+func (h *CatalogHandler) Get() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			h.renderError(c, http.StatusNotFound, "The product does not exist.")
+			return
+		}
+
+		p, err := h.service.GetById(id)
+		switch {
+		case errors.Is(err, product.ErrNotFound) || err == nil && !p.Live(time.Now()):
+			h.renderError(c, http.StatusNotFound, "The product does not exist.")
+			return
+		case err != nil:
+			_ = c.Error(err)
+			h.renderError(c, http.StatusInternalServerError, "The product could not be loaded, please try again later.")
+			return
+		}
+		localized, locale := p.Localize(web.AcceptedLanguages(c))
+		c.Header("Content-Language", locale)
+		c.Writer.Header().Add("Vary", "Accept-Language")
+		h.render(c, http.StatusOK, "product.html", productPage{
+			Title:       localized.Name,
+			Product:     localized,
+			Description: template.HTML(markdown.ToHTML(localized.Description)),
+			Related:     localizeProducts(c, related.Find(p, h.service.GetAll(), relatedShown, time.Now())),
+		})
+	}
+}
+
+/*
+The Sitemap method returns a handler that answers the sitemap of the catalog pages, for the search
+engines. It is generated on demand and kept until the catalog changes.
+*/
+func (h *CatalogHandler) Sitemap() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		h.serveDocument(c, "sitemap", func(w io.Writer, baseURL string, products []domain.Product) error {
+			urls := []string{baseURL + "/catalog"}
+			for _, p := range products {
+				urls = append(urls, productLink(baseURL, p))
+			}
+			return export.WriteSitemap(w, urls)
+		})
+	}
+}
+
+/*
+The Feed method returns a handler that answers the Google Merchant Center feed of the published
+products, for the shopping integrations. It is generated on demand and kept until the catalog changes.
+*/
+func (h *CatalogHandler) Feed() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		h.serveDocument(c, "feed", func(w io.Writer, baseURL string, products []domain.Product) error {
+			return export.WriteMerchantFeed(w, "MELI products", baseURL+"/catalog", products, func(p domain.Product) string {
+				return productLink(baseURL, p)
+			})
+		})
+	}
+}
+
+/*
+Auxiliary method that answers an XML document of the live products, generated with write unless the
+one of the current catalog version and base URL was already generated, and no publish window opened
+or closed since.
+*/
+func (h *CatalogHandler) serveDocument(c *gin.Context, name string, write func(w io.Writer, baseURL string, products []domain.Product) error) {
+	baseURL := h.baseURL(c)
+	key := name + "|" + baseURL
+	version := h.service.Version()
+	now := time.Now()
+
+	h.mu.Lock()
+	if h.version != version {
+		h.version = version
+		h.documents = make(map[string]catalogDocument)
+	}
+	document, found := h.documents[key]
+	h.mu.Unlock()
+
+	if !found || !document.expires.IsZero() && !now.Before(document.expires) {
+		products, expires := h.live(now)
+		var buffer bytes.Buffer
+		if err := write(&buffer, baseURL, products); err != nil {
+			web.Error(c, err)
+			return
+		}
+		document = catalogDocument{content: buffer.Bytes(), expires: expires}
+
+		h.mu.Lock()
+		if h.version == version {
+			h.documents[key] = document
+		}
+		h.mu.Unlock()
+	}
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", document.content)
+}
+
+// Auxiliary method that returns the absolute URL of the catalog pages for the given request.
+func (h *CatalogHandler) baseURL(c *gin.Context) string {
+	if h.publicURL != "" {
+		return h.publicURL
+	}
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if proto := c.GetHeader("X-Forwarded-Proto"); proto == "http" || proto == "https" {
+		scheme = proto
+	}
+	return scheme + "://" + c.Request.Host
+}
+
+/*
+Auxiliary method that returns the products live at the given moment, along with the next moment a
+publish window of a published product opens or closes, zero if none does.
+*/
+func (h *CatalogHandler) live(now time.Time) ([]domain.Product, time.Time) {
+	var live []domain.Product
+	var next time.Time
+	for _, p := range h.service.GetAll() {
+		if !p.Published() {
+			continue
+		}
+		if p.Live(now) {
+			live = append(live, p)
+		}
+		for _, bound := range []*time.Time{p.PublishFrom, p.PublishUntil} {
+			if bound != nil && bound.After(now) && (next.IsZero() || bound.Before(next)) {
+				next = *bound
+			}
+		}
+	}
+	return live, next
+}
+
+// Auxiliary method that renders the given template with its data.
+func (h *CatalogHandler) render(c *gin.Context, status int, name string, data interface{}) {
+	c.Render(status, render.HTML{Template: h.templates, Name: name, Data: data})
+}
+
+// Auxiliary method that renders the error page with the given status and message.
+func (h *CatalogHandler) renderError(c *gin.Context, status int, message string) {
+	h.render(c, status, "error.html", errorPage{Title: http.StatusText(status), Message: message})
+}
+
+// Auxiliary function that returns the absolute URL of the page of a product.
+func productLink(baseURL string, p domain.Product) string {
+	return fmt.Sprintf("%s/catalog/%d", baseURL, p.Id)
+}
+
+// Auxiliary function that returns the link to a page of the catalog.
+func catalogLink(number, size int) string {
+	if size == web.DefaultPageSize {
+		return fmt.Sprintf("/catalog?page=%d", number)
+	}
+	return fmt.Sprintf("/catalog?page=%d&page_size=%d", number, size)
+}
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/JoseObreque/go-web/pkg/webtest"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCatalogHandler(t *testing.T) {
+	launch := time.Now().Add(time.Hour)
+	products := []domain.Product{
+		{Id: 1, Name: "Milk", CodeValue: "A1", Status: domain.StatusPublished, Expiration: "15/12/2030", Price: 10, Quantity: 5},
+		{Id: 2, Name: "Hidden <b>cheese</b>", CodeValue: "B2", Expiration: "15/12/2030", Price: 20},
+		{Id: 3, Name: "Bread & butter", CodeValue: "C3", Status: domain.StatusPublished, Expiration: "15/12/2030", Price: 30,
+			Description: "**Fresh** every day<script>alert(1)</script>"},
+		{Id: 4, Name: "Upcoming cheese", CodeValue: "D4", Status: domain.StatusPublished, Expiration: "15/12/2030", Price: 20,
+			PublishFrom: &launch},
+	}
+	catalogHandler := NewCatalogHandler(product.NewService(product.NewRepository(products), nil))
+	router := gin.New()
+	router.GET("/catalog", catalogHandler.List())
+	router.GET("/catalog/:id", catalogHandler.Get())
+	client := webtest.NewClient(t, router)
+
+	// Actual responses
+	firstPage := client.Get("/catalog?page_size=1")
+	lastPage := client.Get("/catalog?page=2&page_size=1")
+	invalidPage := client.Get("/catalog?page=0")
+	published := client.Get("/catalog/3")
+	unpublished := client.Get("/catalog/2")
+	upcoming := client.Get("/catalog/4")
+	missing := client.Get("/catalog/abc")
+
+	// Assertions
+	assert.Equal(t, http.StatusOK, firstPage.Code)
+	assert.Contains(t, firstPage.Header().Get("Content-Type"), "text/html")
+	assert.Contains(t, firstPage.Body.String(), `<a href="/catalog/1">Milk</a>`)
+	assert.NotContains(t, firstPage.Body.String(), "cheese")
+	assert.Contains(t, firstPage.Body.String(), "Page 1 of 2 (2 products)")
+	assert.Contains(t, firstPage.Body.String(), `href="/catalog?page=2&amp;page_size=1" rel="next"`)
+	assert.Contains(t, lastPage.Body.String(), "Bread &amp; butter")
+	assert.NotContains(t, lastPage.Body.String(), `rel="next"`)
+	assert.Equal(t, http.StatusBadRequest, invalidPage.Code)
+	assert.Equal(t, http.StatusOK, published.Code)
+	assert.Contains(t, published.Body.String(), "30.00 USD")
+	assert.Contains(t, published.Body.String(), "<strong>Fresh</strong> every day")
+	assert.NotContains(t, published.Body.String(), "<script>alert(1)")
+	assert.NotContains(t, published.Body.String(), "You may also like")
+	assert.Equal(t, http.StatusNotFound, unpublished.Code)
+	assert.NotContains(t, unpublished.Body.String(), "cheese")
+	assert.Equal(t, http.StatusNotFound, upcoming.Code)
+	assert.Equal(t, http.StatusNotFound, missing.Code)
+}
+
+func TestCatalogHandler_Sitemap(t *testing.T) {
+	products := []domain.Product{
+		{Id: 1, Name: "Milk", CodeValue: "A1", Status: domain.StatusPublished, Expiration: "15/12/2030", Price: 10, Quantity: 5},
+		{Id: 2, Name: "Cheese", CodeValue: "B2", Expiration: "15/12/2030", Price: 20},
+	}
+	service := product.NewService(product.NewRepository(products), nil)
+	catalogHandler := NewCatalogHandler(service)
+	router := gin.New()
+	router.GET("/sitemap.xml", catalogHandler.Sitemap())
+	client := webtest.NewClient(t, router)
+
+	// The second sitemap is generated again since the catalog changed
+	sitemap := client.Get("https://shop.test/sitemap.xml")
+	published := domain.StatusPublished
+	if _, err := service.Patch(2, domain.ProductRequest{Status: &published}); err != nil {
+		panic(err)
+	}
+	updatedSitemap := client.Get("https://shop.test/sitemap.xml")
+	feed := NewCatalogHandler(service).WithPublicURL("https://cdn.test/")
+	feedRouter := gin.New()
+	feedRouter.GET("/feed.xml", feed.Feed())
+	feedResponse := webtest.NewClient(t, feedRouter).Get("/feed.xml")
+
+	// Assertions
+	assert.Equal(t, http.StatusOK, sitemap.Code)
+	assert.Equal(t, "application/xml; charset=utf-8", sitemap.Header().Get("Content-Type"))
+	assert.Contains(t, sitemap.Body.String(), "<loc>https://shop.test/catalog/1</loc>")
+	assert.NotContains(t, sitemap.Body.String(), "/catalog/2")
+	assert.Contains(t, updatedSitemap.Body.String(), "<loc>https://shop.test/catalog/2</loc>")
+	assert.Equal(t, http.StatusOK, feedResponse.Code)
+	assert.Contains(t, feedResponse.Body.String(), "<link>https://cdn.test/catalog/2</link>")
+	assert.Contains(t, feedResponse.Body.String(), "<g:price>20.00 USD</g:price>")
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/pkg/web"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// CodeFormatHandler is a handler for the endpoints that read and change the format of the code values.
+type CodeFormatHandler struct{}
+
+// The NewCodeFormatHandler function returns a new CodeFormatHandler.
+func NewCodeFormatHandler() *CodeFormatHandler {
+	return &CodeFormatHandler{}
+}
+
+// Get godoc
+// @Summary Get the code values format
+// @Tags Settings
+// @Description Get the format the code values of the created and updated products must follow.
+// @Produce json
+// @Param token header string true "Token"
+// @Success 200 {object} web.Response{data=domain.CodeFormat}
+// @Failure 401 {object} web.ErrorResponse
+// @Router /admin/settings/code-format [get]
+func (h *CodeFormatHandler) Get() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		web.Success(c, http.StatusOK, domain.CodeValueFormat())
+	}
+}
+
+// Update godoc
+// @Summary Change the code values format
+// @Tags Settings
+// @Description Change the format the code values must follow: a regular expression (pattern), or a barcode whose check
+// @Description digit is verified (ean13, upc or gtin for both). The stored code values are only checked again when they
+// @Description change. The format is kept until the server restarts, which applies CODE_VALUE_FORMAT again.
+// @Accept json
+// @Produce json
+// @Param token header string true "Token"
+// @Param format body domain.CodeFormat true "code values format"
+// @Success 200 {object} web.Response{data=domain.CodeFormat}
+// @Failure 400 {object} web.ErrorResponse
+// @Failure 401 {object} web.ErrorResponse
+// @Router /admin/settings/code-format [put]
+func (h *CodeFormatHandler) Update() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var format domain.CodeFormat
+		if err := c.ShouldBindJSON(&format); err != nil {
+			web.Failure(c, http.StatusBadRequest, ErrInvalidData)
+			return
+		}
+		if err := domain.ConfigureCodeValues(format); err != nil {
+			web.Error(c, err)
+			return
+		}
+		web.Success(c, http.StatusOK, domain.CodeValueFormat())
+	}
+}
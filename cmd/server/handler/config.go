@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"errors"
+	"github.com/JoseObreque/go-web/pkg/config"
+	"github.com/JoseObreque/go-web/pkg/web"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"sort"
+)
+
+// The ConfigReloadResult struct describes the outcome of a reload of the configuration.
+type ConfigReloadResult struct {
+	// Names of the reloaded settings
+	Reloaded []string `json:"reloaded" example:"log_level,quotas"`
+}
+
+// ConfigHandler is a handler for the endpoint that reloads the configuration without restarting the server.
+type ConfigHandler struct {
+	reloader *config.Reloader
+}
+
+// The NewConfigHandler function returns a new ConfigHandler that uses the given reloader.
+func NewConfigHandler(reloader *config.Reloader) *ConfigHandler {
+	return &ConfigHandler{
+		reloader: reloader,
+	}
+}
+
+// Reload godoc
+// @Summary Reload the configuration
+// @Tags Tasks
+// @Description Reload the settings that can change while the server runs from the environment file, without dropping
+// @Description the connections: the token, the signing secret, the API quotas, the CORS origins and the log level.
+// @Description If any of them is invalid, nothing changes and the invalid ones are listed in the fields of the error.
+// @Description A SIGHUP sent to the server reloads them too.
+// @Produce json
+// @Param token header string true "Token"
+// @Success 200 {object} web.Response{data=ConfigReloadResult}
+// @Failure 401 {object} web.ErrorResponse
+// @Failure 422 {object} web.ErrorResponse
+// @Router /admin/reload-config [post]
+func (h *ConfigHandler) Reload() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reloaded, err := h.reloader.Reload()
+		if err != nil {
+			web.Error(c, reloadFields(err))
+			return
+		}
+		web.Success(c, http.StatusOK, ConfigReloadResult{Reloaded: reloaded})
+	}
+}
+
+// Auxiliary function that attaches the invalid settings of a failed reload to its error.
+func reloadFields(err error) error {
+	var reloadErr *config.ReloadError
+	if !errors.As(err, &reloadErr) {
+		return err
+	}
+
+	fields := make([]web.FieldError, 0, len(reloadErr.Failures))
+	for name, failure := range reloadErr.Failures {
+		fields = append(fields, web.FieldError{Field: name, Rule: "invalid", Message: failure.Error()})
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Field < fields[j].Field })
+	return web.WithFields(err, fields)
+}
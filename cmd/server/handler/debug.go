@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"encoding/json"
+	"expvar"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/JoseObreque/go-web/pkg/metrics"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+)
+
+// The ProductStats struct summarizes the products kept in the store.
+type ProductStats struct {
+	Total     int                   `json:"total"`
+	Published int                   `json:"published"`
+	ByStatus  map[domain.Status]int `json:"by_status"`
+	Version   string                `json:"version"`
+}
+
+// DebugHandler is a handler for the runtime debugging endpoints.
+type DebugHandler struct {
+	service product.Service
+	latency *metrics.Latency
+}
+
+/*
+The NewDebugHandler function returns a new DebugHandler that reports the stats of the given service
+and the latency percentiles of the routes.
+*/
+func NewDebugHandler(service product.Service, latency *metrics.Latency) *DebugHandler {
+	return &DebugHandler{
+		service: service,
+		latency: latency,
+	}
+}
+
+// Vars godoc
+// @Summary Runtime variables
+// @Tags Debug
+// @Description Expvar variables of the process (command line, memory stats, goroutines), the stats of the product
+// @Description store and the rolling latency percentiles of every route
+// @Produce json
+// @Param token header string true "Token"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} web.ErrorResponse
+// @Router /debug/vars [get]
+func (h *DebugHandler) Vars() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Same format as the expvar handler, so the usual expvar tools can read it
+		vars := map[string]json.RawMessage{}
+		expvar.Do(func(kv expvar.KeyValue) {
+			vars[kv.Key] = json.RawMessage(kv.Value.String())
+		})
+		vars["goroutines"], _ = json.Marshal(runtime.NumGoroutine())
+		vars["products"], _ = json.Marshal(h.productStats())
+		vars["latency"], _ = json.Marshal(h.latency.Summaries())
+
+		c.JSON(http.StatusOK, vars)
+	}
+}
+
+/*
+The Profile method returns a handler serving the pprof profiles. The index, cmdline, profile,
+symbol and trace endpoints are served as in net/http/pprof, and any other name is served as the
+runtime profile of that name (heap, goroutine, block, mutex...).
+*/
+func (h *DebugHandler) Profile() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch name := c.Param("name"); name {
+		case "", "/":
+			pprof.Index(c.Writer, c.Request)
+		case "/cmdline":
+			pprof.Cmdline(c.Writer, c.Request)
+		case "/profile":
+			pprof.Profile(c.Writer, c.Request)
+		case "/symbol":
+			pprof.Symbol(c.Writer, c.Request)
+		case "/trace":
+			pprof.Trace(c.Writer, c.Request)
+		default:
+			pprof.Handler(name[1:]).ServeHTTP(c.Writer, c.Request)
+		}
+	}
+}
+
+// Auxiliary method that counts the stored products.
+func (h *DebugHandler) productStats() ProductStats {
+	products := h.service.GetAll()
+	stats := ProductStats{
+		Total:    len(products),
+		ByStatus: make(map[domain.Status]int),
+		Version:  h.service.Version(),
+	}
+	for _, p := range products {
+		if p.Published() {
+			stats.Published++
+		}
+		stats.ByStatus[p.Status.OrDraft()]++
+	}
+	return stats
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/JoseObreque/go-web/pkg/metrics"
+	"github.com/JoseObreque/go-web/pkg/webtest"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestDebugHandler(t *testing.T) {
+	service := product.NewService(product.NewRepository([]domain.Product{
+		{Id: 1, Name: "Milk", Quantity: 10, CodeValue: "A1", Status: domain.StatusPublished, Expiration: "15/12/2030", Price: 10},
+		{Id: 2, Name: "Oil", Quantity: 10, CodeValue: "B2", Status: domain.StatusDraft, Expiration: "15/12/2030", Price: 20},
+	}), nil)
+	latency := metrics.NewLatency(10)
+	latency.Observe("GET /products", 20*time.Millisecond)
+	debugHandler := NewDebugHandler(service, latency)
+
+	router := gin.New()
+	router.GET("/debug/vars", debugHandler.Vars())
+	router.GET("/debug/pprof/*name", debugHandler.Profile())
+	client := webtest.NewClient(t, router)
+	vars := client.Get("/debug/vars")
+	heap := client.Get("/debug/pprof/heap?debug=1")
+
+	response := webtest.Decode[struct {
+		Goroutines int                        `json:"goroutines"`
+		Products   ProductStats               `json:"products"`
+		MemStats   interface{}                `json:"memstats"`
+		Latency    map[string]metrics.Summary `json:"latency"`
+	}](vars)
+
+	// Assertions
+	assert.Equal(t, http.StatusOK, vars.Code)
+	assert.Positive(t, response.Goroutines)
+	assert.Equal(t, ProductStats{
+		Total:     2,
+		Published: 1,
+		ByStatus:  map[domain.Status]int{domain.StatusDraft: 1, domain.StatusPublished: 1},
+		Version:   service.Version(),
+	}, response.Products)
+	assert.NotNil(t, response.MemStats)
+	assert.Equal(t, 20.0, response.Latency["GET /products"].P99)
+	assert.Equal(t, http.StatusOK, heap.Code)
+	assert.Contains(t, heap.Body.String(), "heap profile")
+}
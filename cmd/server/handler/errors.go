@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/expiry"
+	"github.com/JoseObreque/go-web/internal/filter"
+	"github.com/JoseObreque/go-web/internal/maintenance"
+	"github.com/JoseObreque/go-web/internal/pricing"
+	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/JoseObreque/go-web/internal/review"
+	"github.com/JoseObreque/go-web/internal/search"
+	"github.com/JoseObreque/go-web/internal/tenant"
+	"github.com/JoseObreque/go-web/internal/undo"
+	"github.com/JoseObreque/go-web/pkg/config"
+	"github.com/JoseObreque/go-web/pkg/currency"
+	"github.com/JoseObreque/go-web/pkg/export"
+	"github.com/JoseObreque/go-web/pkg/lock"
+	"github.com/JoseObreque/go-web/pkg/logging"
+	"github.com/JoseObreque/go-web/pkg/resilience"
+	"github.com/JoseObreque/go-web/pkg/store"
+	"github.com/JoseObreque/go-web/pkg/web"
+	"net/http"
+)
+
+/*
+Mapping of the errors returned by the handlers and the product service to HTTP statuses and
+error codes. Malformed requests are 400, requests that are well formed but break a business rule
+are 422, and a code value already taken by another product is a 409 conflict.
+*/
+func init() {
+	web.RegisterError(ErrInvalidId, http.StatusBadRequest, "invalid_id")
+	web.RegisterError(ErrInvalidData, http.StatusBadRequest, "invalid_data")
+	web.RegisterError(ErrInvalidPrice, http.StatusBadRequest, "invalid_price")
+	web.RegisterError(ErrInvalidFormat, http.StatusBadRequest, "invalid_format")
+	web.RegisterError(ErrMissingFilter, http.StatusBadRequest, "missing_filter")
+	web.RegisterError(ErrInvalidDescriptionFormat, http.StatusBadRequest, "invalid_description_format")
+	web.RegisterError(ErrInvalidLabels, http.StatusBadRequest, "invalid_labels")
+	web.RegisterError(export.ErrInvalidBarcode, http.StatusUnprocessableEntity, "invalid_barcode")
+	web.RegisterError(ErrInvalidRelatedLimit, http.StatusBadRequest, "invalid_related_limit")
+	web.RegisterError(ErrInvalidPickQuantity, http.StatusBadRequest, "invalid_pick_quantity")
+	web.RegisterError(web.ErrInvalidDryRun, http.StatusBadRequest, "invalid_dry_run")
+	web.RegisterError(web.ErrInvalidPage, http.StatusBadRequest, "invalid_page")
+	web.RegisterError(filter.ErrInvalidFilter, http.StatusBadRequest, "invalid_filter")
+	web.RegisterError(domain.ErrInvalidExpirationFormat, http.StatusUnprocessableEntity, "invalid_expiration_format")
+	web.RegisterError(domain.ErrExpiredDate, http.StatusUnprocessableEntity, "expired_date")
+	web.RegisterError(domain.ErrInvalidProduct, http.StatusUnprocessableEntity, "invalid_product")
+	web.RegisterError(domain.ErrInvalidCodeFormat, http.StatusBadRequest, "invalid_code_format")
+	web.RegisterError(domain.ErrInvalidCodePattern, http.StatusBadRequest, "invalid_code_pattern")
+	web.RegisterError(domain.ErrInvalidStatus, http.StatusBadRequest, "invalid_status")
+	web.RegisterError(domain.ErrInvalidStorage, http.StatusBadRequest, "invalid_storage")
+	web.RegisterError(domain.ErrInvalidCurrency, http.StatusBadRequest, "invalid_currency")
+	web.RegisterError(domain.ErrInvalidLocale, http.StatusBadRequest, "invalid_locale")
+	web.RegisterError(domain.ErrTranslationNotFound, http.StatusNotFound, "translation_not_found")
+	web.RegisterError(domain.ErrNutritionNotFound, http.StatusNotFound, "nutrition_not_found")
+	web.RegisterError(domain.ErrInvalidAllergen, http.StatusBadRequest, "invalid_allergen")
+	web.RegisterError(domain.ErrInvalidDiet, http.StatusBadRequest, "invalid_diet")
+	web.RegisterError(currency.ErrUnsupportedCurrency, http.StatusBadRequest, "unsupported_currency")
+	web.RegisterError(currency.ErrRatesUnavailable, http.StatusServiceUnavailable, "exchange_rates_unavailable")
+	web.RegisterError(product.ErrNotFound, http.StatusNotFound, "product_not_found")
+	web.RegisterError(product.ErrInvalidCode, http.StatusConflict, "duplicate_code_value")
+	web.RegisterError(product.ErrNoProducts, http.StatusNotFound, "no_products_found")
+	web.RegisterError(product.ErrUnavailable, http.StatusServiceUnavailable, "products_unavailable")
+	web.RegisterError(product.ErrSelfMerge, http.StatusBadRequest, "self_merge")
+	web.RegisterError(ErrInvalidPriceId, http.StatusBadRequest, "invalid_price_id")
+	web.RegisterError(ErrInvalidPriceData, http.StatusBadRequest, "invalid_price_data")
+	web.RegisterError(pricing.ErrNotFound, http.StatusNotFound, "scheduled_price_not_found")
+	web.RegisterError(ErrInvalidReviewId, http.StatusBadRequest, "invalid_review_id")
+	web.RegisterError(ErrInvalidReviewData, http.StatusBadRequest, "invalid_review_data")
+	web.RegisterError(ErrInvalidReviewFilter, http.StatusBadRequest, "invalid_review_filter")
+	web.RegisterError(review.ErrNotFound, http.StatusNotFound, "review_not_found")
+	web.RegisterError(ErrInvalidSubscriptionId, http.StatusBadRequest, "invalid_subscription_id")
+	web.RegisterError(ErrInvalidSubscriptionData, http.StatusBadRequest, "invalid_subscription_data")
+	web.RegisterError(domain.ErrInvalidSubscription, http.StatusBadRequest, "invalid_subscription")
+	web.RegisterError(expiry.ErrNotFound, http.StatusNotFound, "subscription_not_found")
+	web.RegisterError(ErrInvalidReportLimit, http.StatusBadRequest, "invalid_report_limit")
+	web.RegisterError(ErrInvalidReportPeriod, http.StatusBadRequest, "invalid_report_period")
+	web.RegisterError(ErrInvalidReportThreshold, http.StatusBadRequest, "invalid_report_threshold")
+	web.RegisterError(ErrInvalidQuery, http.StatusBadRequest, "invalid_query")
+	web.RegisterError(ErrInvalidLimit, http.StatusBadRequest, "invalid_limit")
+	web.RegisterError(search.ErrUnavailable, http.StatusServiceUnavailable, "search_unavailable")
+	web.RegisterError(tenant.ErrUnknownTenant, http.StatusNotFound, "tenant_not_found")
+	web.RegisterError(tenant.ErrForbidden, http.StatusForbidden, "tenant_forbidden")
+	web.RegisterError(tenant.ErrUnsupported, http.StatusBadRequest, "tenant_unsupported")
+	web.RegisterError(undo.ErrNothingToUndo, http.StatusNotFound, "nothing_to_undo")
+	web.RegisterError(undo.ErrChanged, http.StatusConflict, "undo_conflict")
+	web.RegisterError(product.ErrInvalidCatalog, http.StatusUnprocessableEntity, "invalid_catalog")
+	web.RegisterError(ErrBackupDirNotConfigured, http.StatusBadRequest, "backup_dir_not_configured")
+	web.RegisterError(ErrInvalidSnapshot, http.StatusBadRequest, "invalid_snapshot")
+	web.RegisterError(store.ErrSnapshotNotFound, http.StatusNotFound, "snapshot_not_found")
+	web.RegisterError(store.ErrChecksumMismatch, http.StatusUnprocessableEntity, "checksum_mismatch")
+	web.RegisterError(ErrInvalidSeedCount, http.StatusBadRequest, "invalid_seed_count")
+	web.RegisterError(ErrInvalidSeed, http.StatusBadRequest, "invalid_seed")
+	web.RegisterError(ErrInvalidLoadTestCount, http.StatusBadRequest, "invalid_loadtest_count")
+	web.RegisterError(ErrInvalidRetryAfter, http.StatusBadRequest, "invalid_retry_after")
+	web.RegisterError(maintenance.ErrReadOnly, http.StatusServiceUnavailable, "maintenance")
+	web.RegisterError(logging.ErrInvalidLevel, http.StatusBadRequest, "invalid_log_level")
+	web.RegisterError(config.ErrInvalidReload, http.StatusUnprocessableEntity, "invalid_config")
+	web.RegisterError(resilience.ErrOpen, http.StatusServiceUnavailable, "backend_unavailable")
+	web.RegisterError(lock.ErrTimeout, http.StatusServiceUnavailable, "store_locked")
+}
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/pkg/events"
+	"github.com/gin-gonic/gin"
+	"io"
+	"time"
+)
+
+// Interval between two keep-alive comments sent to idle SSE clients.
+const keepAliveInterval = 15 * time.Second
+
+// EventHandler is a handler for the product change events endpoints.
+type EventHandler struct {
+	bus     events.Bus
+	version APIVersion
+}
+
+/*
+The NewEventHandler function returns a new EventHandler. It forwards the events published in the
+given bus, converting the products into the representation of the given API version.
+*/
+func NewEventHandler(bus events.Bus, version APIVersion) *EventHandler {
+	return &EventHandler{
+		bus:     bus,
+		version: version,
+	}
+}
+
+// Stream godoc
+// @Summary Stream product changes
+// @Tags Products
+// @Description Stream the created, updated and deleted products as Server-Sent Events. The event name is the change type (product.created, product.updated or product.deleted), and a merge of a duplicate is also streamed as product.merged after the deletion and update.
+// @Produce text/event-stream
+// @Success 200 {object} events.Event
+// @Router /products/events [get]
+func (h *EventHandler) Stream() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		subscription, unsubscribe := h.bus.Subscribe()
+		defer unsubscribe()
+
+		ticker := time.NewTicker(keepAliveInterval)
+		defer ticker.Stop()
+
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		c.Status(200)
+		c.Writer.Flush()
+
+		for {
+			select {
+			case <-c.Request.Context().Done():
+				return
+			case <-ticker.C:
+				if _, err := io.WriteString(c.Writer, ": keep-alive\n\n"); err != nil {
+					return
+				}
+			case event, ok := <-subscription:
+				if !ok {
+					return
+				}
+				if p, isProduct := event.Data.(domain.Product); isProduct {
+					event.Data = h.version.Response(p)
+				}
+				c.SSEvent(event.Type, event)
+			}
+			c.Writer.Flush()
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"errors"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/expiry"
+	"github.com/JoseObreque/go-web/pkg/web"
+	"github.com/gin-gonic/gin"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrInvalidSubscriptionId   = errors.New("invalid expiry subscription id")
+	ErrInvalidSubscriptionData = errors.New("invalid expiry subscription data")
+)
+
+// ExpiryHandler is a handler for the subscriptions to the notifications of the stock about to expire.
+type ExpiryHandler struct {
+	service  expiry.Service
+	notifier *expiry.Notifier
+}
+
+// The NewExpiryHandler function returns a new ExpiryHandler that uses the provided service and notifier.
+func NewExpiryHandler(service expiry.Service, notifier *expiry.Notifier) *ExpiryHandler {
+	return &ExpiryHandler{
+		service:  service,
+		notifier: notifier,
+	}
+}
+
+// GetAll godoc
+// @Summary List expiry subscriptions
+// @Tags Expiry
+// @Description List the subscriptions to the notifications of the stock about to expire, of a recipient or of all of them.
+// @Produce json
+// @Param token header string true "Token"
+// @Param recipient query string false "Recipient of the subscriptions, ignoring the case"
+// @Success 200 {object} web.Response{data=[]domain.ExpirySubscription}
+// @Failure 401 {object} web.ErrorResponse
+// @Router /admin/expiry-subscriptions [get]
+func (h *ExpiryHandler) GetAll() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		web.Success(c, 200, h.service.GetAll(c.Query("recipient")))
+	}
+}
+
+// Create godoc
+// @Summary Subscribe to the expiry notifications
+// @Tags Expiry
+// @Description Subscribe a recipient to a daily notification of the products and lots expiring within its window of
+// @Description days, or the one of the server. The notifications are a signed POST to the url, like the ones of the
+// @Description webhooks with the products.expiring event, an email if the server has an SMTP server, or both.
+// @Accept json
+// @Produce json
+// @Param token header string true "Token"
+// @Param subscription body domain.ExpirySubscriptionRequest true "new expiry subscription"
+// @Success 201 {object} web.Response{data=domain.ExpirySubscription}
+// @Failure 400 {object} web.ErrorResponse
+// @Failure 401 {object} web.ErrorResponse
+// @Router /admin/expiry-subscriptions [post]
+func (h *ExpiryHandler) Create() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request domain.ExpirySubscriptionRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			web.Failure(c, 400, ErrInvalidSubscriptionData)
+			return
+		}
+
+		subscription, err := h.service.Create(request)
+		if err != nil {
+			web.Error(c, err)
+			return
+		}
+		web.Created(c, subscription)
+	}
+}
+
+// Update godoc
+// @Summary Replace an expiry subscription
+// @Tags Expiry
+// @Description Replace the recipient, the destinations and the window of an expiry subscription.
+// @Accept json
+// @Produce json
+// @Param token header string true "Token"
+// @Param id path int true "Expiry subscription ID"
+// @Param subscription body domain.ExpirySubscriptionRequest true "expiry subscription"
+// @Success 200 {object} web.Response{data=domain.ExpirySubscription}
+// @Failure 400 {object} web.ErrorResponse
+// @Failure 401 {object} web.ErrorResponse
+// @Failure 404 {object} web.ErrorResponse
+// @Router /admin/expiry-subscriptions/{id} [put]
+func (h *ExpiryHandler) Update() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			web.Failure(c, 400, ErrInvalidSubscriptionId)
+			return
+		}
+
+		var request domain.ExpirySubscriptionRequest
+		if err = c.ShouldBindJSON(&request); err != nil {
+			web.Failure(c, 400, ErrInvalidSubscriptionData)
+			return
+		}
+
+		subscription, err := h.service.Update(id, request)
+		if err != nil {
+			web.Error(c, web.WithParams(err, web.Params{"id": id}))
+			return
+		}
+		web.Success(c, 200, subscription)
+	}
+}
+
+// Delete godoc
+// @Summary Delete an expiry subscription
+// @Tags Expiry
+// @Description Stop notifying the stock about to expire to a subscription
+// @Param token header string true "Token"
+// @Param id path int true "Expiry subscription ID"
+// @Success 204 {object} web.Response
+// @Failure 400 {object} web.ErrorResponse
+// @Failure 404 {object} web.ErrorResponse
+// @Router /admin/expiry-subscriptions/{id} [delete]
+func (h *ExpiryHandler) Delete() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			web.Failure(c, 400, ErrInvalidSubscriptionId)
+			return
+		}
+
+		if err = h.service.Delete(id); err != nil {
+			web.Error(c, web.WithParams(err, web.Params{"id": id}))
+			return
+		}
+
+		web.NoContent(c)
+	}
+}
+
+// Check godoc
+// @Summary Notify the expiring stock
+// @Tags Tasks
+// @Description Notify the stock about to expire to every expiry subscription, without waiting for the daily job.
+// @Description Returns the number of subscriptions notified, and of the webhook notifications and emails sent.
+// @Produce json
+// @Param token header string true "Token"
+// @Success 200 {object} web.Response{data=expiry.Report}
+// @Failure 401 {object} web.ErrorResponse
+// @Failure 500 {object} web.ErrorResponse
+// @Router /admin/tasks/notify-expiring [post]
+func (h *ExpiryHandler) Check() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report, err := h.notifier.Check(time.Now())
+		if err != nil {
+			web.Failure(c, 500, err)
+			return
+		}
+		web.Success(c, 200, report)
+	}
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/expiry"
+	"github.com/JoseObreque/go-web/internal/webhook"
+	"github.com/JoseObreque/go-web/pkg/webtest"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestExpiryHandler_Subscriptions(t *testing.T) {
+	service := expiry.NewService(expiry.NewRepository())
+	dispatcher := webhook.NewDispatcher(webhook.NewService(webhook.NewRepository()), http.DefaultClient, 1, 0)
+	notifier := expiry.NewNotifier(service, func() []domain.Product { return nil }, 7, dispatcher)
+	expiryHandler := NewExpiryHandler(service, notifier)
+	router := gin.New()
+	router.GET("/subscriptions", expiryHandler.GetAll())
+	router.POST("/subscriptions", expiryHandler.Create())
+	router.PUT("/subscriptions/:id", expiryHandler.Update())
+	router.DELETE("/subscriptions/:id", expiryHandler.Delete())
+	router.POST("/check", expiryHandler.Check())
+	client := webtest.NewClient(t, router)
+
+	// Actual responses
+	created := client.Post("/subscriptions", `{"recipient":"Store","email":"store@example.com","window_days":3}`)
+	client.Post("/subscriptions", `{"recipient":"Warehouse","url":"https://example.com/hook","secret":"s3cr3t"}`)
+	withoutSecret := client.Post("/subscriptions", `{"recipient":"Store","url":"https://example.com/hook"}`)
+	invalidEmail := client.Post("/subscriptions", `{"recipient":"Store","email":"store"}`)
+	updated := client.Put("/subscriptions/1", `{"recipient":"Store","email":"shop@example.com"}`)
+	unknown := client.Put("/subscriptions/9", `{"recipient":"Store","email":"shop@example.com"}`)
+	store := client.Get("/subscriptions?recipient=store")
+	checked := client.Post("/check", "")
+	deleted := client.Delete("/subscriptions/1")
+
+	// Assertions
+	assert.Equal(t, http.StatusCreated, created.Code)
+	assert.Equal(t, 3, webtest.Data[domain.ExpirySubscription](created).WindowDays)
+	assert.Equal(t, "invalid_subscription", withoutSecret.Error().ErrorCode)
+	assert.Equal(t, "invalid_subscription_data", invalidEmail.Error().ErrorCode)
+	assert.Equal(t, http.StatusOK, updated.Code)
+	assert.Equal(t, webtest.Data[domain.ExpirySubscription](created).CreatedAt, webtest.Data[domain.ExpirySubscription](updated).CreatedAt)
+	assert.Equal(t, http.StatusNotFound, unknown.Code)
+	assert.Equal(t, "subscription_not_found", unknown.Error().ErrorCode)
+	subscriptions := webtest.Data[[]domain.ExpirySubscription](store)
+	if assert.Len(t, subscriptions, 1) {
+		assert.Equal(t, "shop@example.com", subscriptions[0].Email)
+		assert.Zero(t, subscriptions[0].WindowDays)
+	}
+	assert.Equal(t, expiry.Report{}, webtest.Data[expiry.Report](checked))
+	assert.Equal(t, http.StatusNoContent, deleted.Code)
+}
@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"github.com/JoseObreque/go-web/pkg/adapter"
+	"github.com/JoseObreque/go-web/pkg/store"
+	"net/http"
+)
+
+// FlushHandler is a handler for the endpoint that saves the products in the store file right away.
+type FlushHandler struct {
+	flush func() (store.FlushStats, error)
+}
+
+// The NewFlushHandler function returns a new FlushHandler that uses the given flush function.
+func NewFlushHandler(flush func() (store.FlushStats, error)) *FlushHandler {
+	return &FlushHandler{
+		flush: flush,
+	}
+}
+
+// Flush godoc
+// @Summary Flush the products
+// @Tags Tasks
+// @Description Save the current products in the store file and empty the journal, instead of waiting for the periodic
+// @Description flush, like before a planned restart. Answers the products and bytes written, the journal entries and
+// @Description bytes compacted, and the duration in nanoseconds. The catalogs of the tenants are flushed as well.
+// @Produce json
+// @Param token header string true "Token"
+// @Success 200 {object} web.Response{data=store.FlushStats}
+// @Failure 401 {object} web.ErrorResponse
+// @Failure 500 {object} web.ErrorResponse
+// @Router /admin/flush [post]
+func (h *FlushHandler) Flush() adapter.HandlerFunc {
+	return func(c adapter.Context) {
+		stats, err := h.flush()
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.Success(http.StatusOK, stats)
+	}
+}
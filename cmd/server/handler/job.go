@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"github.com/JoseObreque/go-web/pkg/scheduler"
+	"github.com/JoseObreque/go-web/pkg/web"
+	"github.com/gin-gonic/gin"
+)
+
+// JobHandler is a handler for the background jobs administration endpoints.
+type JobHandler struct {
+	scheduler *scheduler.Scheduler
+}
+
+// The NewJobHandler function returns a new JobHandler that reports the jobs of the given scheduler.
+func NewJobHandler(scheduler *scheduler.Scheduler) *JobHandler {
+	return &JobHandler{
+		scheduler: scheduler,
+	}
+}
+
+// GetAll godoc
+// @Summary List background jobs
+// @Tags Jobs
+// @Description List the background jobs with their schedule, next run and the outcome of their last run
+// @Produce json
+// @Param token header string true "Token"
+// @Success 200 {object} web.Response
+// @Failure 401 {object} web.ErrorResponse
+// @Router /admin/jobs [get]
+func (h *JobHandler) GetAll() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		web.Success(c, 200, h.scheduler.Status())
+	}
+}
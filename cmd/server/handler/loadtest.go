@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"errors"
+	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/JoseObreque/go-web/internal/seed"
+	"github.com/JoseObreque/go-web/pkg/web"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Number of products of the load test dataset when no count is given, and the largest dataset allowed.
+const (
+	defaultLoadTestCount = 1000000
+	maxLoadTestCount     = 5000000
+)
+
+var ErrInvalidLoadTestCount = errors.New("count must be between 1 and 5000000")
+
+/*
+The LoadTestStatus struct describes the dataset served by the API.
+
+	Enabled (bool): Whether the load test dataset is served instead of the catalog.
+	Count (int): Number of products the dataset was generated with.
+	Seed (int64): Seed the dataset was generated with.
+*/
+type LoadTestStatus struct {
+	Enabled bool  `json:"enabled"`
+	Count   int   `json:"count"`
+	Seed    int64 `json:"seed,omitempty"`
+}
+
+// LoadTestHandler is a handler for the endpoints that swap the catalog for a load test dataset.
+type LoadTestHandler struct {
+	sandbox *product.SandboxService
+}
+
+// The NewLoadTestHandler function returns a new LoadTestHandler that toggles the given sandbox.
+func NewLoadTestHandler(sandbox *product.SandboxService) *LoadTestHandler {
+	return &LoadTestHandler{
+		sandbox: sandbox,
+	}
+}
+
+// Status godoc
+// @Summary Get the load test dataset status
+// @Tags Development
+// @Description Tell whether the API serves the load test dataset instead of the catalog.
+// @Produce json
+// @Param token header string true "Token"
+// @Success 200 {object} web.Response
+// @Failure 401 {object} web.ErrorResponse
+// @Router /admin/loadtest [get]
+func (h *LoadTestHandler) Status() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		enabled, count := h.sandbox.Enabled()
+		web.Success(c, http.StatusOK, LoadTestStatus{
+			Enabled: enabled,
+			Count:   count,
+		})
+	}
+}
+
+// Enable godoc
+// @Summary Serve a load test dataset
+// @Tags Development
+// @Description Serve the given number of generated products, with the IDs 1 to count, instead of the catalog. The dataset
+// @Description lives in memory only: its changes are never saved nor published, and the catalog is left untouched. The
+// @Description same seed generates the same dataset on the same day. Only available in load test mode.
+// @Produce json
+// @Param token header string true "Token"
+// @Param count query int false "Number of products, 1000000 by default and 5000000 at most"
+// @Param seed query int false "Seed of the generator"
+// @Success 201 {object} web.Response
+// @Failure 400 {object} web.ErrorResponse
+// @Failure 401 {object} web.ErrorResponse
+// @Router /admin/loadtest [post]
+func (h *LoadTestHandler) Enable() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		count := defaultLoadTestCount
+		if raw := c.Query("count"); raw != "" {
+			var err error
+			count, err = strconv.Atoi(raw)
+			if err != nil || count < 1 || count > maxLoadTestCount {
+				web.Error(c, ErrInvalidLoadTestCount)
+				return
+			}
+		}
+		seedValue := time.Now().UnixNano()
+		if raw := c.Query("seed"); raw != "" {
+			var err error
+			if seedValue, err = strconv.ParseInt(raw, 10, 64); err != nil {
+				web.Error(c, ErrInvalidSeed)
+				return
+			}
+		}
+
+		today := time.Now().Truncate(24 * time.Hour)
+		products := seed.NewGenerator(seedValue, today).Products(count)
+		for i := range products {
+			products[i].Id = i + 1
+		}
+		h.sandbox.Enable(products)
+
+		web.Created(c, LoadTestStatus{
+			Enabled: true,
+			Count:   count,
+			Seed:    seedValue,
+		})
+	}
+}
+
+// Disable godoc
+// @Summary Serve the catalog again
+// @Tags Development
+// @Description Discard the load test dataset and serve the catalog again.
+// @Produce json
+// @Param token header string true "Token"
+// @Success 200 {object} web.Response
+// @Failure 401 {object} web.ErrorResponse
+// @Router /admin/loadtest [delete]
+func (h *LoadTestHandler) Disable() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		h.sandbox.Disable()
+		web.Success(c, http.StatusOK, LoadTestStatus{})
+	}
+}
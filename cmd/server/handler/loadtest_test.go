@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/JoseObreque/go-web/pkg/testutil"
+	"github.com/JoseObreque/go-web/pkg/webtest"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+// The newLoadTestRouter function returns a router serving the products of the sandbox and its load test endpoints.
+func newLoadTestRouter(sandbox *product.SandboxService) *gin.Engine {
+	router := gin.New()
+	productHandler := NewProductHandler(sandbox)
+	router.GET("/products", productHandler.GetAll())
+	router.GET("/products/search", productHandler.GetByPriceGt())
+	router.POST("/products", productHandler.Create())
+
+	loadTestHandler := NewLoadTestHandler(sandbox)
+	router.GET("/admin/loadtest", loadTestHandler.Status())
+	router.POST("/admin/loadtest", loadTestHandler.Enable())
+	router.DELETE("/admin/loadtest", loadTestHandler.Disable())
+	return router
+}
+
+func TestLoadTestHandler(t *testing.T) {
+	catalog := product.NewService(product.NewRepository(testProducts()), nil)
+	client := webtest.NewClient(t, newLoadTestRouter(product.NewSandboxService(catalog)))
+
+	enabled := client.Post("/admin/loadtest?count=500&seed=7", nil)
+	status := webtest.Data[LoadTestStatus](client.Get("/admin/loadtest"))
+	_, meta := webtest.Envelope[[]domain.Product](client.Get("/products?page=1"))
+
+	// Changes made during the load test are discarded with the dataset
+	created := client.Post("/products", newTestProduct())
+	disabled := client.Delete("/admin/loadtest")
+	_, catalogMeta := webtest.Envelope[[]domain.Product](client.Get("/products?page=1"))
+	invalid := client.Post("/admin/loadtest?count=0", nil)
+
+	// Assertions
+	assert.Equal(t, http.StatusCreated, enabled.Code)
+	assert.Equal(t, LoadTestStatus{Enabled: true, Count: 500}, status)
+	assert.Equal(t, 500, meta.Total)
+	assert.Equal(t, 501, webtest.Data[domain.Product](created).Id)
+	assert.Equal(t, http.StatusOK, disabled.Code)
+	assert.Equal(t, len(testProducts()), catalogMeta.Total)
+	assert.Len(t, catalog.GetAll(), len(testProducts()))
+	assert.Equal(t, http.StatusBadRequest, invalid.Code)
+	assert.Equal(t, "invalid_loadtest_count", invalid.Error().ErrorCode)
+}
+
+func BenchmarkProductHandler_LoadTest(b *testing.B) {
+	sandbox := product.NewSandboxService(product.NewService(product.NewRepository(nil), nil))
+	sandbox.Enable(testutil.Fixtures(1000000))
+	client := webtest.NewClient(b, newLoadTestRouter(sandbox))
+
+	for _, url := range []string{"/products", "/products?page=40000", "/products/search?priceGt=999"} {
+		b.Run(url, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if response := client.Get(url); response.Code != http.StatusOK {
+					b.Fatalf("%s: %d %s", url, response.Code, response.Body)
+				}
+			}
+		})
+	}
+}
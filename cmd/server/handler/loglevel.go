@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"github.com/JoseObreque/go-web/pkg/logging"
+	"github.com/JoseObreque/go-web/pkg/web"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+/*
+The LogLevelRequest struct is the body of a change of the logging settings. The omitted settings
+are kept.
+
+	Level (*string): Lowest level of the logged messages, debug, info, warn or error.
+	Bodies (*bool): Whether the request and response bodies are logged, redacted.
+*/
+type LogLevelRequest struct {
+	Level  *string `json:"level" example:"debug"`
+	Bodies *bool   `json:"bodies"`
+}
+
+// LogLevelHandler is a handler for the endpoints that read and change the logging settings at runtime.
+type LogLevelHandler struct {
+	settings *logging.Settings
+}
+
+// The NewLogLevelHandler function returns a new LogLevelHandler that changes the given settings.
+func NewLogLevelHandler(settings *logging.Settings) *LogLevelHandler {
+	return &LogLevelHandler{
+		settings: settings,
+	}
+}
+
+// Get godoc
+// @Summary Get the logging settings
+// @Tags Settings
+// @Description Get the lowest level of the logged messages and whether the request and response bodies are logged.
+// @Produce json
+// @Param token header string true "Token"
+// @Success 200 {object} web.Response{data=logging.Options}
+// @Failure 401 {object} web.ErrorResponse
+// @Router /admin/log-level [get]
+func (h *LogLevelHandler) Get() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		web.Success(c, http.StatusOK, h.settings.Options())
+	}
+}
+
+// Update godoc
+// @Summary Change the logging settings
+// @Tags Settings
+// @Description Change the lowest level of the logged messages, debug, info, warn or error, for example to turn on the
+// @Description debug messages during an incident, and turn on or off the log of the request and response bodies. The
+// @Description credential headers, like the token, and the JSON fields named in LOG_REDACT_FIELDS are redacted from the
+// @Description bodies. The omitted settings are kept. The settings last until the server restarts, which applies
+// @Description LOG_LEVEL and LOG_BODIES again, or until a SIGHUP reloads LOG_LEVEL from the environment file.
+// @Accept json
+// @Produce json
+// @Param token header string true "Token"
+// @Param settings body LogLevelRequest true "logging settings"
+// @Success 200 {object} web.Response{data=logging.Options}
+// @Failure 400 {object} web.ErrorResponse
+// @Failure 401 {object} web.ErrorResponse
+// @Router /admin/log-level [put]
+func (h *LogLevelHandler) Update() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request LogLevelRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			web.Failure(c, http.StatusBadRequest, ErrInvalidData)
+			return
+		}
+
+		options := h.settings.Options()
+		if request.Level != nil {
+			level, err := logging.ParseLevel(*request.Level)
+			if err != nil {
+				web.Error(c, err)
+				return
+			}
+			options.Level = level
+		}
+		if request.Bodies != nil {
+			options.Bodies = *request.Bodies
+		}
+		h.settings.Apply(options)
+		web.Success(c, http.StatusOK, options)
+	}
+}
@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"github.com/JoseObreque/go-web/pkg/logging"
+	"github.com/JoseObreque/go-web/pkg/webtest"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestLogLevelHandler(t *testing.T) {
+	settings := logging.NewSettings(logging.Options{})
+	router := gin.New()
+	logLevelHandler := NewLogLevelHandler(settings)
+	router.GET("/admin/log-level", logLevelHandler.Get())
+	router.PUT("/admin/log-level", logLevelHandler.Update())
+	client := webtest.NewClient(t, router)
+
+	debug := client.Put("/admin/log-level", `{"level":"debug"}`)
+	bodies := client.Put("/admin/log-level", `{"bodies":true}`)
+	current := webtest.Data[logging.Options](client.Get("/admin/log-level"))
+	invalid := client.Put("/admin/log-level", `{"level":"verbose"}`)
+
+	// Assertions
+	assert.Equal(t, http.StatusOK, debug.Code)
+	assert.Equal(t, http.StatusOK, bodies.Code)
+	assert.Equal(t, logging.Options{Level: logging.LevelDebug, Bodies: true}, current)
+	assert.Equal(t, http.StatusBadRequest, invalid.Code)
+	assert.Equal(t, "invalid_log_level", invalid.Error().ErrorCode)
+	assert.True(t, settings.Enabled(logging.LevelDebug))
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"errors"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/pkg/web"
+	"github.com/gin-gonic/gin"
+	"strconv"
+	"time"
+)
+
+var ErrInvalidPickQuantity = errors.New("invalid quantity to pick")
+
+/*
+The LotsResponse struct is the lots of a product, first-expire-first-out, with the picking
+suggestions for a quantity of it, if one is asked for.
+
+	Lots ([]domain.Lot): Every lot of the product, the ones expiring first before.
+	Picks ([]domain.Lot): Lots to pick the quantity from, with the units to take from each of them.
+	Missing (int): Units of the quantity that the lots not expired cannot cover.
+*/
+type LotsResponse struct {
+	Lots    []domain.Lot `json:"lots"`
+	Picks   []domain.Lot `json:"picks,omitempty"`
+	Missing int          `json:"missing,omitempty" example:"0"`
+}
+
+// GetLots godoc
+// @Summary List the lots of a product
+// @Tags Products
+// @Description List the lots of the stock of a product, first-expire-first-out. Given a quantity, it also
+// @Description suggests the lots to pick it from: the units are taken from the lots expiring first that have not
+// @Description expired yet, and the ones they cannot cover are answered as missing.
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param quantity query int false "Units to pick from the lots"
+// @Success 200 {object} web.Response{data=LotsResponse}
+// @Failure 400 {object} web.ErrorResponse
+// @Failure 404 {object} web.ErrorResponse
+// @Router /products/{id}/lots [get]
+func (h *ProductHandler) GetLots() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			web.Failure(c, 400, ErrInvalidId)
+			return
+		}
+		quantity := 0
+		if stringQuantity, ok := c.GetQuery("quantity"); ok {
+			if quantity, err = strconv.Atoi(stringQuantity); err != nil || quantity < 1 {
+				web.Failure(c, 400, ErrInvalidPickQuantity)
+				return
+			}
+		}
+
+		service, err := h.catalog(c)
+		if err != nil {
+			web.Error(c, err)
+			return
+		}
+		targetProduct, err := service.GetById(id)
+		if err != nil {
+			web.Error(c, web.WithParams(err, web.Params{"id": id}))
+			return
+		}
+
+		response := LotsResponse{Lots: targetProduct.Lots}
+		if response.Lots == nil {
+			response.Lots = []domain.Lot{}
+		}
+		if quantity > 0 {
+			response.Picks, response.Missing = domain.PickLots(targetProduct.Lots, quantity, time.Now())
+		}
+		web.Success(c, 200, response)
+	}
+}
+
+// PutLots godoc
+// @Summary Set the lots of a product
+// @Tags Products
+// @Description Replace the lots of the stock of a product, whose quantities add up to the quantity of the product
+// @Description from then on. The numbers are unique in the product, and an empty list stops tracking the lots,
+// @Description keeping the quantity.
+// @Accept json
+// @Produce json
+// @Param token header string true "Token"
+// @Param id path int true "Product ID"
+// @Param lots body []domain.Lot true "Lots"
+// @Param dryRun query bool false "Only validate the change and answer the result, without applying it"
+// @Success 200 {object} web.Response{data=[]domain.Lot}
+// @Failure 400 {object} web.ErrorResponse
+// @Failure 404 {object} web.ErrorResponse
+// @Failure 422 {object} web.ErrorResponse
+// @Router /products/{id}/lots [put]
+func (h *ProductHandler) PutLots() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		service, dryRun, err := h.mutations(c)
+		if err != nil {
+			web.Error(c, err)
+			return
+		}
+
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			web.Failure(c, 400, ErrInvalidId)
+			return
+		}
+
+		var lots []domain.Lot
+		if err = c.ShouldBindJSON(&lots); err != nil {
+			web.Failure(c, 400, ErrInvalidData)
+			return
+		}
+		if lots == nil {
+			lots = []domain.Lot{}
+		}
+
+		// Only the lots and the quantity change, so concurrent changes of other fields are kept
+		updatedProduct, err := service.Patch(id, domain.ProductRequest{Lots: lots})
+		if err != nil {
+			web.Error(c, h.invalidFields(web.WithParams(err, web.Params{"id": id})))
+			return
+		}
+		if updatedProduct.Lots == nil {
+			updatedProduct.Lots = []domain.Lot{}
+		}
+		web.Success(c, 200, updatedProduct.Lots, web.WithDryRun(dryRun))
+	}
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"github.com/JoseObreque/go-web/cmd/server/middleware"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/JoseObreque/go-web/pkg/webtest"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestProductHandler_Lots(t *testing.T) {
+	t.Setenv("TOKEN", "secret")
+	products := []domain.Product{
+		{Id: 1, Name: "Milk", Quantity: 5, CodeValue: "A1", Status: domain.StatusPublished, Expiration: "15/12/2030", Price: 1},
+	}
+	productHandler := NewProductHandler(product.NewService(product.NewRepository(products), nil))
+	router := gin.New()
+	router.GET("/products/:id", productHandler.GetById())
+	router.PATCH("/products/:id", middleware.TokenValidator(), productHandler.PartialUpdate())
+	router.GET("/products/:id/lots", productHandler.GetLots())
+	router.PUT("/products/:id/lots", middleware.TokenValidator(), productHandler.PutLots())
+	client := webtest.NewClient(t, router).WithToken("secret")
+	lots := []domain.Lot{
+		{Number: "L2", Quantity: 30, Expiration: "2030-03-01"},
+		{Number: "L1", Quantity: 10, Expiration: "01/02/2030"},
+		{Number: "L0", Quantity: 50, Expiration: "01/01/2020"},
+	}
+
+	// Actual responses
+	untracked := client.Get("/products/1/lots")
+	set := client.Put("/products/1/lots", lots)
+	tracked := client.Get("/products/1")
+	picked := client.Get("/products/1/lots?quantity=25")
+	short := client.Get("/products/1/lots?quantity=100")
+	invalidQuantity := client.Get("/products/1/lots?quantity=0")
+	quantityChange := client.Patch("/products/1", `{"quantity":3}`)
+	duplicated := client.Put("/products/1/lots", []domain.Lot{{Number: "L1", Quantity: 1, Expiration: "01/02/2030"}, {Number: "L1", Expiration: "x"}})
+	removed := client.Put("/products/1/lots", []domain.Lot{})
+	kept := client.Get("/products/1")
+
+	// Assertions
+	assert.Equal(t, http.StatusOK, untracked.Code)
+	assert.Equal(t, LotsResponse{Lots: []domain.Lot{}}, webtest.Data[LotsResponse](untracked))
+	assert.Equal(t, http.StatusOK, set.Code)
+	assert.Equal(t, []domain.Lot{
+		{Number: "L0", Quantity: 50, Expiration: "01/01/2020"},
+		{Number: "L1", Quantity: 10, Expiration: "01/02/2030"},
+		{Number: "L2", Quantity: 30, Expiration: "01/03/2030"},
+	}, webtest.Data[[]domain.Lot](set))
+	assert.Equal(t, 90, webtest.Data[domain.Product](tracked).Quantity)
+	assert.Equal(t, []domain.Lot{
+		{Number: "L1", Quantity: 10, Expiration: "01/02/2030"},
+		{Number: "L2", Quantity: 15, Expiration: "01/03/2030"},
+	}, webtest.Data[LotsResponse](picked).Picks)
+	assert.Equal(t, 0, webtest.Data[LotsResponse](picked).Missing)
+	assert.Equal(t, 60, webtest.Data[LotsResponse](short).Missing)
+	assert.Equal(t, "invalid_pick_quantity", invalidQuantity.Error().ErrorCode)
+	assert.Equal(t, http.StatusUnprocessableEntity, quantityChange.Code)
+	assert.Equal(t, "quantity", quantityChange.Error().Fields[0].Field)
+	assert.Equal(t, http.StatusUnprocessableEntity, duplicated.Code)
+	assert.Len(t, duplicated.Error().Fields, 2)
+	assert.Equal(t, []domain.Lot{}, webtest.Data[[]domain.Lot](removed))
+	assert.Equal(t, 90, webtest.Data[domain.Product](kept).Quantity)
+	assert.Nil(t, webtest.Data[domain.Product](kept).Lots)
+}
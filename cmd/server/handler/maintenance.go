@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"errors"
+	"github.com/JoseObreque/go-web/internal/maintenance"
+	"github.com/JoseObreque/go-web/pkg/web"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+var ErrInvalidRetryAfter = errors.New("retry_after must be a positive number of seconds")
+
+/*
+The MaintenanceRequest struct is the body of a change of the maintenance mode.
+
+	Enabled (bool): Whether the maintenance starts or ends.
+	Reason (string): Why the catalog is under maintenance, shown to the clients.
+	RetryAfter (int): Seconds the clients are asked to wait, the current ones if omitted.
+*/
+type MaintenanceRequest struct {
+	Enabled    bool   `json:"enabled"`
+	Reason     string `json:"reason"`
+	RetryAfter int    `json:"retry_after"`
+}
+
+// MaintenanceHandler is a handler for the endpoints that read and toggle the read-only maintenance mode.
+type MaintenanceHandler struct {
+	mode *maintenance.Mode
+}
+
+// The NewMaintenanceHandler function returns a new MaintenanceHandler that toggles the given mode.
+func NewMaintenanceHandler(mode *maintenance.Mode) *MaintenanceHandler {
+	return &MaintenanceHandler{
+		mode: mode,
+	}
+}
+
+// Status godoc
+// @Summary Get the maintenance mode
+// @Tags Settings
+// @Description Tell whether the catalog is read-only for a maintenance, since when and why.
+// @Produce json
+// @Param token header string true "Token"
+// @Success 200 {object} web.Response{data=maintenance.Status}
+// @Failure 401 {object} web.ErrorResponse
+// @Router /admin/maintenance [get]
+func (h *MaintenanceHandler) Status() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		web.Success(c, http.StatusOK, h.mode.Status())
+	}
+}
+
+// Update godoc
+// @Summary Toggle the maintenance mode
+// @Tags Settings
+// @Description Start or end a maintenance. During a maintenance the product changes, except the dry runs, are rejected
+// @Description with 503 and a Retry-After header, while the reads keep working. The administration endpoints, like the
+// @Description restores, stay available. The mode is kept until the server restarts, which applies MAINTENANCE_MODE again.
+// @Accept json
+// @Produce json
+// @Param token header string true "Token"
+// @Param mode body MaintenanceRequest true "maintenance mode"
+// @Success 200 {object} web.Response{data=maintenance.Status}
+// @Failure 400 {object} web.ErrorResponse
+// @Failure 401 {object} web.ErrorResponse
+// @Router /admin/maintenance [post]
+func (h *MaintenanceHandler) Update() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request MaintenanceRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			web.Failure(c, http.StatusBadRequest, ErrInvalidData)
+			return
+		}
+		if request.RetryAfter < 0 {
+			web.Error(c, ErrInvalidRetryAfter)
+			return
+		}
+
+		if request.Enabled {
+			h.mode.Enable(request.Reason, time.Duration(request.RetryAfter)*time.Second)
+		} else {
+			h.mode.Disable()
+		}
+		web.Success(c, http.StatusOK, h.mode.Status())
+	}
+}
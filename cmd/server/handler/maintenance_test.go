@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"github.com/JoseObreque/go-web/cmd/server/middleware"
+	"github.com/JoseObreque/go-web/internal/maintenance"
+	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/JoseObreque/go-web/pkg/webtest"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestMaintenanceHandler(t *testing.T) {
+	mode := maintenance.NewMode(false, time.Minute)
+	router := gin.New()
+	productHandler := NewProductHandler(product.NewService(product.NewRepository(testProducts()), nil))
+	router.GET("/products", middleware.ReadOnly(mode), productHandler.GetAll())
+	router.POST("/products", middleware.ReadOnly(mode), productHandler.Create())
+	maintenanceHandler := NewMaintenanceHandler(mode)
+	router.GET("/admin/maintenance", maintenanceHandler.Status())
+	router.POST("/admin/maintenance", maintenanceHandler.Update())
+	client := webtest.NewClient(t, router)
+
+	enabled := client.Post("/admin/maintenance", `{"enabled":true,"reason":"store migration","retry_after":120}`)
+	status := webtest.Data[maintenance.Status](client.Get("/admin/maintenance"))
+	rejected := client.Post("/products", newTestProduct())
+	dryRun := client.Post("/products?dryRun=true", newTestProduct())
+	read := client.Get("/products")
+	disabled := client.Post("/admin/maintenance", `{"enabled":false}`)
+	created := client.Post("/products", newTestProduct())
+	invalid := client.Post("/admin/maintenance", `{"enabled":true,"retry_after":-1}`)
+
+	// Assertions
+	assert.Equal(t, http.StatusOK, enabled.Code)
+	assert.True(t, status.Enabled)
+	assert.Equal(t, "store migration", status.Reason)
+	assert.Equal(t, 120, status.RetryAfter)
+	assert.NotNil(t, status.Since)
+	assert.Equal(t, http.StatusServiceUnavailable, rejected.Code)
+	assert.Equal(t, "120", rejected.Header().Get("Retry-After"))
+	assert.Equal(t, "maintenance", rejected.Error().ErrorCode)
+	assert.Equal(t, http.StatusOK, dryRun.Code)
+	assert.Equal(t, http.StatusOK, read.Code)
+	assert.Equal(t, http.StatusOK, disabled.Code)
+	assert.False(t, webtest.Data[maintenance.Status](disabled).Enabled)
+	assert.Equal(t, http.StatusCreated, created.Code)
+	assert.Equal(t, http.StatusBadRequest, invalid.Code)
+}
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/pkg/web"
+	"github.com/gin-gonic/gin"
+	"strconv"
+)
+
+// GetNutrition godoc
+// @Summary Get the nutrition facts of a product
+// @Tags Products
+// @Description Get the calories, nutrients and allergens of a product, per 100 g or 100 mL of it.
+// @Produce json
+// @Param id path int true "Product ID"
+// @Success 200 {object} web.Response{data=domain.Nutrition}
+// @Failure 400 {object} web.ErrorResponse
+// @Failure 404 {object} web.ErrorResponse
+// @Router /products/{id}/nutrition [get]
+func (h *ProductHandler) GetNutrition() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			web.Failure(c, 400, ErrInvalidId)
+			return
+		}
+
+		service, err := h.catalog(c)
+		if err != nil {
+			web.Error(c, err)
+			return
+		}
+		targetProduct, err := service.GetById(id)
+		if err != nil {
+			web.Error(c, web.WithParams(err, web.Params{"id": id}))
+			return
+		}
+		if targetProduct.Nutrition == nil {
+			web.Error(c, web.WithParams(domain.ErrNutritionNotFound, web.Params{"id": id}))
+			return
+		}
+		web.Success(c, 200, targetProduct.Nutrition)
+	}
+}
+
+// GetDietaryTags godoc
+// @Summary List the dietary tags
+// @Tags Products
+// @Description Get the controlled vocabulary of the allergens and diets of the nutrition facts, which are the only
+// @Description ones accepted in the products and in the excludeAllergens and diet filters.
+// @Produce json
+// @Success 200 {object} web.Response{data=domain.DietaryTags}
+// @Router /products/dietary-tags [get]
+func (h *ProductHandler) GetDietaryTags() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		web.Success(c, 200, domain.NewDietaryTags())
+	}
+}
+
+// PutNutrition godoc
+// @Summary Set the nutrition facts of a product
+// @Tags Products
+// @Description Create or replace the nutrition facts of a product, per 100 g or 100 mL of it. The nutrients are in
+// @Description grams, the saturated fat and the sugars cannot be more than the fat and the carbohydrates, and the
+// @Description allergens and diets are the ones of /products/dietary-tags. The products with nutrition facts can be
+// @Description filtered by their allergens and diets, with the excludeAllergens and diet parameters or with filter
+// @Description expressions like `nutrition = true AND NOT allergens contains "milk"`.
+// @Accept json
+// @Produce json
+// @Param token header string true "Token"
+// @Param id path int true "Product ID"
+// @Param nutrition body domain.Nutrition true "Nutrition facts"
+// @Param dryRun query bool false "Only validate the change and answer the result, without applying it"
+// @Success 200 {object} web.Response{data=domain.Nutrition}
+// @Failure 400 {object} web.ErrorResponse
+// @Failure 404 {object} web.ErrorResponse
+// @Failure 422 {object} web.ErrorResponse
+// @Router /products/{id}/nutrition [put]
+func (h *ProductHandler) PutNutrition() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		service, dryRun, err := h.mutations(c)
+		if err != nil {
+			web.Error(c, err)
+			return
+		}
+
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			web.Failure(c, 400, ErrInvalidId)
+			return
+		}
+
+		var nutrition domain.Nutrition
+		if err = c.ShouldBindJSON(&nutrition); err != nil {
+			web.Failure(c, 400, ErrInvalidData)
+			return
+		}
+		if nutrition.Allergens == nil {
+			nutrition.Allergens = []string{}
+		}
+		if nutrition.Diets == nil {
+			nutrition.Diets = []string{}
+		}
+
+		// Only the nutrition facts change, so concurrent changes of other fields are kept
+		updatedProduct, err := service.Patch(id, domain.ProductRequest{Nutrition: &nutrition})
+		if err != nil {
+			web.Error(c, h.invalidFields(web.WithParams(err, web.Params{"id": id})))
+			return
+		}
+		web.Success(c, 200, updatedProduct.Nutrition, web.WithDryRun(dryRun))
+	}
+}
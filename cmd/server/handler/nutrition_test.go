@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"github.com/JoseObreque/go-web/cmd/server/middleware"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/JoseObreque/go-web/pkg/webtest"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestProductHandler_Nutrition(t *testing.T) {
+	t.Setenv("TOKEN", "secret")
+	products := []domain.Product{
+		{Id: 1, Name: "Wine", CodeValue: "A1", Status: domain.StatusPublished, Expiration: "15/12/2030", Price: 10},
+		{Id: 2, Name: "Water", CodeValue: "A2", Status: domain.StatusPublished, Expiration: "15/12/2030", Price: 1},
+		{Id: 3, Name: "Bread", CodeValue: "A3", Status: domain.StatusPublished, Expiration: "15/12/2030", Price: 2},
+	}
+	productHandler := NewProductHandler(product.NewService(product.NewRepository(products), nil))
+	router := gin.New()
+	router.GET("/products", productHandler.GetAll())
+	router.GET("/products/search", productHandler.GetByPriceGt())
+	router.GET("/products/dietary-tags", productHandler.GetDietaryTags())
+	router.GET("/products/:id/nutrition", productHandler.GetNutrition())
+	router.PUT("/products/:id/nutrition", middleware.TokenValidator(), productHandler.PutNutrition())
+	client := webtest.NewClient(t, router).WithToken("secret")
+	wine := domain.Nutrition{Calories: 83, Carbohydrates: 2.6, Sugars: 0.6, Allergens: []string{"sulphites"}, Diets: []string{"vegan"}}
+
+	// Actual responses
+	unknown := client.Get("/products/1/nutrition")
+	created := client.Put("/products/1/nutrition", wine)
+	water := client.Put("/products/2/nutrition", domain.Nutrition{})
+	invalid := client.Put("/products/1/nutrition", domain.Nutrition{Fat: 1, SaturatedFat: 2, Allergens: []string{"Milk"}})
+	found := client.Get("/products/1/nutrition")
+	allergenFree := client.Get("/products?filter=" + url.QueryEscape(`nutrition = true AND NOT allergens contains "sulphites"`))
+	excluded := client.Get("/products/search?priceGt=0&excludeAllergens=Sulphites,milk")
+	vegan := client.Get("/products?diet=vegan")
+	unknownDiet := client.Get("/products/search?priceGt=0&diet=paleo")
+	tags := client.Get("/products/dietary-tags")
+
+	// Assertions
+	assert.Equal(t, http.StatusNotFound, unknown.Code)
+	assert.Equal(t, "nutrition_not_found", unknown.Error().ErrorCode)
+	assert.Equal(t, http.StatusOK, created.Code)
+	assert.Equal(t, []string{}, webtest.Data[domain.Nutrition](water).Allergens)
+	assert.Equal(t, http.StatusUnprocessableEntity, invalid.Code)
+	assert.Len(t, invalid.Error().Fields, 2)
+	assert.Equal(t, wine, webtest.Data[domain.Nutrition](found))
+	assert.Equal(t, "Water", webtest.Data[[]domain.Product](allergenFree)[0].Name)
+	assert.Len(t, webtest.Data[[]domain.Product](allergenFree), 1)
+	assert.Equal(t, webtest.Data[[]domain.Product](allergenFree), webtest.Data[[]domain.Product](excluded))
+	assert.Equal(t, "Wine", webtest.Data[[]domain.Product](vegan)[0].Name)
+	assert.Len(t, webtest.Data[[]domain.Product](vegan), 1)
+	assert.Equal(t, "invalid_diet", unknownDiet.Error().ErrorCode)
+	assert.Equal(t, domain.NewDietaryTags(), webtest.Data[domain.DietaryTags](tags))
+}
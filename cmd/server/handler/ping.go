@@ -0,0 +1,13 @@
+package handler
+
+import (
+	"github.com/JoseObreque/go-web/pkg/adapter"
+	"net/http"
+)
+
+// The Ping function returns the handler of the liveness probe, which answers pong.
+func Ping() adapter.HandlerFunc {
+	return func(c adapter.Context) {
+		c.String(http.StatusOK, "pong")
+	}
+}
@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"errors"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/pricing"
+	"github.com/JoseObreque/go-web/pkg/web"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+var (
+	ErrInvalidPriceId   = errors.New("invalid scheduled price id")
+	ErrInvalidPriceData = errors.New("invalid scheduled price data")
+)
+
+// PriceHandler is a handler for the scheduled prices endpoints.
+type PriceHandler struct {
+	service pricing.Service
+}
+
+// The NewPriceHandler function returns a new PriceHandler that uses the provided service.
+func NewPriceHandler(service pricing.Service) *PriceHandler {
+	return &PriceHandler{
+		service: service,
+	}
+}
+
+// GetAll godoc
+// @Summary List the upcoming prices of a product
+// @Tags Prices
+// @Description List the prices scheduled for a product, sorted by effective date.
+// @Produce json
+// @Param id path int true "Product ID"
+// @Success 200 {object} web.Response{data=[]domain.ScheduledPrice}
+// @Failure 400 {object} web.ErrorResponse
+// @Router /products/{id}/prices [get]
+func (h *PriceHandler) GetAll() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		productId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			web.Failure(c, http.StatusBadRequest, ErrInvalidId)
+			return
+		}
+
+		web.Success(c, http.StatusOK, h.service.Upcoming(productId))
+	}
+}
+
+// Create godoc
+// @Summary Schedule a price
+// @Tags Prices
+// @Description Schedule a future price of a product. A background job makes it the current price of the product
+// @Description at its effective date, as a regular update. Without currency, the price keeps the currency of the product.
+// @Accept json
+// @Produce json
+// @Param token header string true "Token"
+// @Param id path int true "Product ID"
+// @Param price body domain.ScheduledPriceRequest true "scheduled price"
+// @Success 201 {object} web.Response{data=domain.ScheduledPrice}
+// @Failure 400 {object} web.ErrorResponse
+// @Failure 401 {object} web.ErrorResponse
+// @Failure 404 {object} web.ErrorResponse
+// @Failure 422 {object} web.ErrorResponse
+// @Router /products/{id}/prices [post]
+func (h *PriceHandler) Create() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		productId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			web.Failure(c, http.StatusBadRequest, ErrInvalidId)
+			return
+		}
+		var request domain.ScheduledPriceRequest
+		if err = c.ShouldBindJSON(&request); err != nil {
+			web.Failure(c, http.StatusBadRequest, ErrInvalidPriceData)
+			return
+		}
+
+		price, err := h.service.Schedule(productId, request)
+		if err != nil {
+			web.Error(c, withInvalidFields(web.WithParams(err, web.Params{"id": productId}), V1.FieldName))
+			return
+		}
+		web.Created(c, price)
+	}
+}
+
+// Delete godoc
+// @Summary Cancel a scheduled price
+// @Tags Prices
+// @Description Cancel a price scheduled for a product before its effective date
+// @Param token header string true "Token"
+// @Param id path int true "Product ID"
+// @Param priceId path int true "Scheduled price ID"
+// @Success 204 {object} web.Response
+// @Failure 400 {object} web.ErrorResponse
+// @Failure 401 {object} web.ErrorResponse
+// @Failure 404 {object} web.ErrorResponse
+// @Router /products/{id}/prices/{priceId} [delete]
+func (h *PriceHandler) Delete() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		productId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			web.Failure(c, http.StatusBadRequest, ErrInvalidId)
+			return
+		}
+		id, err := strconv.Atoi(c.Param("priceId"))
+		if err != nil {
+			web.Failure(c, http.StatusBadRequest, ErrInvalidPriceId)
+			return
+		}
+
+		if err = h.service.Cancel(productId, id); err != nil {
+			web.Error(c, err)
+			return
+		}
+		web.NoContent(c)
+	}
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/JoseObreque/go-web/cmd/server/middleware"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/pricing"
+	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/JoseObreque/go-web/pkg/webtest"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+)
+
+/*
+The upcomingPricesResponse struct is the upcoming prices of the single product response. The product
+is decoded on its own, since domain.Product decodes itself and would hide the other fields if embedded.
+*/
+type upcomingPricesResponse struct {
+	UpcomingPrices []domain.ScheduledPrice `json:"upcoming_prices"`
+}
+
+func TestPriceHandler(t *testing.T) {
+	if err := os.Setenv("TOKEN", "12345"); err != nil {
+		panic(err)
+	}
+	service := product.NewService(product.NewRepository(testProducts()), nil)
+	repository, _ := pricing.NewRepository("")
+	prices := pricing.NewService(repository, service)
+
+	router := gin.New()
+	productHandler := NewProductHandler(service).WithPriceSchedule(prices)
+	priceHandler := NewPriceHandler(prices)
+	router.GET("/products/:id", productHandler.GetById())
+	router.GET("/products/:id/prices", priceHandler.GetAll())
+	router.POST("/products/:id/prices", middleware.TokenValidator(), priceHandler.Create())
+	router.DELETE("/products/:id/prices/:priceId", middleware.TokenValidator(), priceHandler.Delete())
+	client := webtest.NewClient(t, router).WithToken("12345")
+	effectiveFrom := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+
+	// Actual responses
+	created := client.Post("/products/1/prices", domain.ScheduledPriceRequest{Price: 42, EffectiveFrom: effectiveFrom})
+	past := client.Post("/products/1/prices", domain.ScheduledPriceRequest{Price: 42, EffectiveFrom: time.Now().Add(-time.Hour)})
+	missing := client.Post("/products/999/prices", domain.ScheduledPriceRequest{Price: 42, EffectiveFrom: effectiveFrom})
+	detail := client.Get("/products/1")
+	upcoming := webtest.Data[upcomingPricesResponse](detail)
+	listed := webtest.Data[[]domain.ScheduledPrice](client.Get("/products/1/prices"))
+	scheduled := webtest.Data[domain.ScheduledPrice](created)
+	deleted := client.Delete(fmt.Sprintf("/products/1/prices/%d", scheduled.Id))
+	deletedAgain := client.Delete(fmt.Sprintf("/products/1/prices/%d", scheduled.Id))
+
+	// Assertions
+	assert.Equal(t, http.StatusCreated, created.Code)
+	assert.Equal(t, 1, scheduled.ProductId)
+	assert.True(t, effectiveFrom.Equal(scheduled.EffectiveFrom))
+	assert.Equal(t, http.StatusUnprocessableEntity, past.Code)
+	assert.Equal(t, "effective_from", past.Error().Fields[0].Field)
+	assert.Equal(t, http.StatusNotFound, missing.Code)
+	assert.Equal(t, testProducts()[0], webtest.Data[domain.Product](detail))
+	assert.Len(t, upcoming.UpcomingPrices, 1)
+	assert.Equal(t, 42.0, upcoming.UpcomingPrices[0].Price)
+	assert.Len(t, listed, 1)
+	assert.Equal(t, http.StatusNoContent, deleted.Code)
+	assert.Equal(t, http.StatusNotFound, deletedAgain.Code)
+	assert.Equal(t, "scheduled_price_not_found", deletedAgain.Error().ErrorCode)
+}
@@ -1,63 +1,235 @@
 package handler
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
 	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/filter"
+	"github.com/JoseObreque/go-web/internal/pricing"
 	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/JoseObreque/go-web/internal/review"
+	"github.com/JoseObreque/go-web/internal/search"
+	"github.com/JoseObreque/go-web/internal/tenant"
+	"github.com/JoseObreque/go-web/pkg/currency"
+	"github.com/JoseObreque/go-web/pkg/export"
+	"github.com/JoseObreque/go-web/pkg/markdown"
 	"github.com/JoseObreque/go-web/pkg/web"
 	"github.com/gin-gonic/gin"
-	"net/http"
-	"os"
+	"io"
+	"path"
 	"strconv"
-	"time"
+	"strings"
 )
 
 var (
-	ErrInvalidId    = errors.New("invalid product id")
-	ErrInvalidPrice = errors.New("invalid product price")
-	ErrInvalidData  = errors.New("invalid product data")
-	ErrNotFound     = errors.New("product not found")
-	ErrInvalidCode  = errors.New("invalid product code value")
+	ErrInvalidId     = errors.New("invalid product id")
+	ErrInvalidPrice  = errors.New("invalid product price")
+	ErrInvalidData   = errors.New("invalid product data")
+	ErrInvalidFormat = errors.New("invalid export format")
+	ErrMissingFilter = errors.New("a filter is required")
+	// ErrInvalidDescriptionFormat is returned when the description_format is neither markdown nor html.
+	ErrInvalidDescriptionFormat = errors.New("invalid description format")
+	// ErrInvalidLabels is returned when the labels request has no product IDs, or too many of them.
+	ErrInvalidLabels = errors.New("invalid labels request")
 )
 
+// Preference of the clients that only need the ID of the created products.
+const preferMinimal = "return=minimal"
+
+// Maximum number of products returned by a full-text search.
+const maxSearchResults = 1000
+
+// Maximum number of labels printed at once, 10 A4 pages.
+const maxLabels = 10 * export.LabelsPerPage
+
+/*
+The LabelsRequest struct is the body of a printing of shelf labels.
+
+	Ids ([]int): IDs of the labeled products, in the order of the labels. A repeated ID prints its
+	label again.
+*/
+type LabelsRequest struct {
+	Ids []int `json:"ids" example:"1,2,3"`
+}
+
+// CreatedId is the body of a creation answered with the minimal representation.
+type CreatedId struct {
+	Id int `json:"id" example:"1"`
+}
+
 // ProductHandler is a handler for the product endpoints.
 type ProductHandler struct {
 	service product.Service
+	version APIVersion
+	// Whether the filters matching no product answer 404 instead of an empty list
+	emptyFilterNotFound bool
+	// Converter of the prices to the currency requested with the currency query parameter
+	converter *currency.Converter
+	// Scheduled prices returned along with a single product, nil if the prices cannot be scheduled
+	prices pricing.Service
+	// Reviews whose rating is returned along with the products, nil if the products cannot be reviewed
+	reviews review.Service
+	// Recorder of the views of the single products, nil if they are not recorded
+	views ViewRecorder
+	// Engine of the full-text searches, nil if the products cannot be searched by text
+	engine search.Engine
+	// Catalogs of the tenants, nil if the deployment only serves the main catalog
+	tenants *tenant.Registry
+}
+
+// The ViewRecorder interface is implemented by the components counting the views of the products.
+type ViewRecorder interface {
+	View(productName string)
 }
 
 /*
 The NewProductHandler function returns a new ProductHandler. It uses the provided service for
-make CRUD operations for products.
+make CRUD operations for products, and exposes them using the first version of the API.
 */
 func NewProductHandler(service product.Service) *ProductHandler {
+	return NewVersionedProductHandler(service, V1)
+}
+
+/*
+The NewVersionedProductHandler function returns a new ProductHandler that exposes the products
+using the representation of the given API version.
+*/
+func NewVersionedProductHandler(service product.Service, version APIVersion) *ProductHandler {
 	return &ProductHandler{
-		service: service,
+		service:   service,
+		version:   version,
+		converter: currency.NewConverter(nil),
 	}
 }
 
+/*
+The WithEmptyFilterNotFound method restores the legacy behavior of the filter endpoints, which
+answered 404 when no product matched. By default they answer 200 with an empty list.
+*/
+func (h *ProductHandler) WithEmptyFilterNotFound(enabled bool) *ProductHandler {
+	h.emptyFilterNotFound = enabled
+	return h
+}
+
+/*
+The WithCurrencyConverter method sets the converter of the prices requested in another currency. By
+default, the prices can only be requested in their own currency.
+*/
+func (h *ProductHandler) WithCurrencyConverter(converter *currency.Converter) *ProductHandler {
+	if converter != nil {
+		h.converter = converter
+	}
+	return h
+}
+
+/*
+The WithPriceSchedule method makes the single product responses include the upcoming prices of the
+product, from the given service.
+*/
+func (h *ProductHandler) WithPriceSchedule(prices pricing.Service) *ProductHandler {
+	h.prices = prices
+	return h
+}
+
+/*
+The WithReviews method makes the product responses include the rating of the visible reviews of
+every product, from the given service.
+*/
+func (h *ProductHandler) WithReviews(reviews review.Service) *ProductHandler {
+	h.reviews = reviews
+	return h
+}
+
+/*
+The WithViewRecorder method makes the handler record the views of the single products in the given
+recorder. The views answered from the response cache, or with 304, are not recorded.
+*/
+func (h *ProductHandler) WithViewRecorder(views ViewRecorder) *ProductHandler {
+	h.views = views
+	return h
+}
+
+/*
+The WithSearchEngine method makes the search endpoint accept a q parameter, whose text is searched
+in the given engine. Without an engine, the text searches answer 503.
+*/
+func (h *ProductHandler) WithSearchEngine(engine search.Engine) *ProductHandler {
+	h.engine = engine
+	return h
+}
+
+/*
+The WithTenants method makes the handler serve the catalog of the tenant of every request, from the
+given registry. The catalogs of the tenants have no scheduled prices, reviews, views or full-text search.
+*/
+func (h *ProductHandler) WithTenants(tenants *tenant.Registry) *ProductHandler {
+	h.tenants = tenants
+	return h
+}
+
 // GetAll godoc
 // @Summary List all products
 // @Tags Products
-// @Description List all available products
+// @Description List all available products, or only the ones matching the filter expression, like
+// @Description `price > 100 AND quantity < 5 AND status = "published"`. The comparisons (=, !=, >, >=,
+// @Description <, <= and contains) of the product fields with numbers, true or false, or quoted texts and
+// @Description dates are combined with AND, OR, NOT and parentheses.
 // @Produce json
+// @Param filter query string false "Filter expression"
+// @Param status query string false "Comma separated statuses of the products listed, like draft,archived"
+// @Param storage query string false "Comma separated storage zones of the products, like chilled,frozen"
+// @Param excludeAllergens query string false "Comma separated allergens the products are free of, like milk,peanuts"
+// @Param diet query string false "Comma separated diets the products are suitable for, like vegan,gluten-free"
+// @Param page query int false "Page number, starting at 1"
+// @Param page_size query int false "Products per page (20 by default, 100 at most)"
+// @Param currency query string false "ISO 4217 code of the currency the prices are converted to"
+// @Param description_format query string false "Format of the descriptions: markdown, as written (default), or html, rendered and sanitized" Enums(markdown, html)
 // @Success 200 {object} web.Response
-// @Router /products/all [get]
+// @Failure 400 {object} web.ErrorResponse
+// @Failure 503 {object} web.ErrorResponse
+// @Router /products [get]
 func (h *ProductHandler) GetAll() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		products := h.service.GetAll()
-		web.Success(c, 200, products)
+		expr, ok, err := parseFilter(c)
+		if err != nil {
+			web.Error(c, err)
+			return
+		}
+		service, err := h.catalog(c)
+		if err != nil {
+			web.Error(c, err)
+			return
+		}
+		if ok {
+			filteredProducts, err := service.Filter(expr)
+			h.successFilter(c, filteredProducts, err)
+			return
+		}
+
+		products, err := service.List()
+		if err != nil {
+			web.Error(c, err)
+			return
+		}
+		h.successList(c, products)
 	}
 }
 
 // GetById godoc
 // @Summary Get a specific product
 // @Tags Products
-// @Description Get a specific product based on its ID
+// @Description Get a specific product based on its ID. If prices can be scheduled, the product includes its
+// @Description upcoming_prices, sorted by effective date. If the products can be reviewed, the product includes the
+// @Description rating of its visible reviews, when it has any.
 // @Produce json
 // @Param id path int true "Product ID"
+// @Param currency query string false "ISO 4217 code of the currency the price is converted to"
+// @Param description_format query string false "Format of the descriptions: markdown, as written (default), or html, rendered and sanitized" Enums(markdown, html)
 // @Success 200 {object} web.Response
 // @Failure 400 {object} web.ErrorResponse
 // @Failure 404 {object} web.ErrorResponse
+// @Failure 503 {object} web.ErrorResponse
 // @Router /products/{id} [get]
 func (h *ProductHandler) GetById() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -68,42 +240,264 @@ func (h *ProductHandler) GetById() gin.HandlerFunc {
 			return
 		}
 
-		targetProduct, err := h.service.GetById(id)
+		service, err := h.catalog(c)
+		if err != nil {
+			web.Error(c, err)
+			return
+		}
+		targetProduct, err := service.GetById(id)
+		if err != nil {
+			web.Error(c, web.WithParams(err, web.Params{"id": id}))
+			return
+		}
+		mainCatalog := web.Tenant(c) == ""
+		if h.views != nil && mainCatalog {
+			h.views.View(targetProduct.Name)
+		}
+
+		localized, locale := targetProduct.Localize(web.AcceptedLanguages(c))
+		c.Header("Content-Language", locale)
+		c.Writer.Header().Add("Vary", "Accept-Language")
+		formatted, err := formatDescriptions(c, []domain.Product{localized})
 		if err != nil {
-			web.Failure(c, 404, err)
+			web.Error(c, err)
+			return
+		}
+		converted, err := h.convertPrices(c, h.rateProducts(c, formatted))
+		if err != nil {
+			web.Error(c, err)
+			return
+		}
+		if h.prices == nil || !mainCatalog {
+			web.Success(c, 200, h.version.Response(converted[0]))
 			return
 		}
 
-		web.Success(c, 200, targetProduct)
+		upcoming, err := h.convertUpcomingPrices(c, targetProduct, h.prices.Upcoming(id))
+		if err != nil {
+			web.Error(c, err)
+			return
+		}
+		web.Success(c, 200, h.version.Detail(converted[0], upcoming))
 	}
 }
 
 // GetByPriceGt godoc
-// @Summary Get all products based on its price
+// @Summary Search products
 // @Tags Products
-// @Description Get all products with a price greater than the provided value
+// @Description Get all products with a price greater than the provided value, or the products
+// @Description matching the text in q, most relevant first and tolerating typos. Both filters can
+// @Description be combined. An empty list is returned when no product matches, unless the server
+// @Description keeps the legacy 404.
 // @Produce json
-// @Param priceGt query int true "Price"
+// @Param q query string false "Text searched in the name, category and code value"
+// @Param priceGt query number false "Price, required without q"
+// @Param excludeAllergens query string false "Comma separated allergens the products are free of, like milk,peanuts"
+// @Param diet query string false "Comma separated diets the products are suitable for, like vegan,gluten-free"
+// @Param page query int false "Page number, starting at 1"
+// @Param page_size query int false "Products per page (20 by default, 100 at most)"
+// @Param currency query string false "ISO 4217 code of the currency the prices are converted to"
+// @Param description_format query string false "Format of the descriptions: markdown, as written (default), or html, rendered and sanitized" Enums(markdown, html)
 // @Success 200 {object} web.Response
 // @Failure 400 {object} web.ErrorResponse
 // @Failure 404 {object} web.ErrorResponse
+// @Failure 503 {object} web.ErrorResponse
 // @Router /products/search [get]
 func (h *ProductHandler) GetByPriceGt() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		stringPriceGt := c.Query("priceGt")
-		priceGt, err := strconv.ParseFloat(stringPriceGt, 64)
+		stringPriceGt, hasPrice := c.GetQuery("priceGt")
+		query := strings.TrimSpace(c.Query("q"))
+		if query == "" {
+			hasPrice = true
+		}
+
+		var priceGt float64
+		if hasPrice {
+			var err error
+			if priceGt, err = strconv.ParseFloat(stringPriceGt, 64); err != nil {
+				web.Failure(c, 400, ErrInvalidPrice)
+				return
+			}
+		}
+		dietaryExpr, err := parseDietary(c)
+		if err != nil {
+			web.Error(c, err)
+			return
+		}
+
+		if query == "" {
+			service, err := h.catalog(c)
+			if err != nil {
+				web.Error(c, err)
+				return
+			}
+			if dietaryExpr == nil {
+				filteredProducts, err := service.GetByPriceGt(priceGt)
+				h.successFilter(c, filteredProducts, err)
+				return
+			}
+			priceFilter := filter.Comparison{Field: "price", Kind: filter.Number, Operator: filter.Greater, Value: filter.Value{Number: priceGt}}
+			filteredProducts, err := service.Filter(filter.And{Left: priceFilter, Right: dietaryExpr})
+			h.successFilter(c, filteredProducts, err)
+			return
+		}
+
+		foundProducts, err := h.searchProducts(c, query)
+		if err != nil {
+			web.Error(c, err)
+			return
+		}
+		if hasPrice || dietaryExpr != nil {
+			filtered := foundProducts[:0]
+			for _, p := range foundProducts {
+				if (!hasPrice || p.Price > priceGt) && (dietaryExpr == nil || dietaryExpr.Match(p)) {
+					filtered = append(filtered, p)
+				}
+			}
+			foundProducts = filtered
+		}
+		if len(foundProducts) == 0 {
+			err = product.ErrNoProducts
+		}
+		h.successFilter(c, foundProducts, err)
+	}
+}
+
+// Export godoc
+// @Summary Export products
+// @Tags Products
+// @Description Download the products catalog as a CSV or XLSX file. Accepts the same filters as the search endpoint.
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "File format (csv or xlsx)" Enums(csv, xlsx)
+// @Param priceGt query number false "Price"
+// @Param filter query string false "Filter expression, like the one of the products list"
+// @Param status query string false "Comma separated statuses of the products, like the ones of the products list"
+// @Param storage query string false "Comma separated storage zones of the products, like chilled,frozen"
+// @Param excludeAllergens query string false "Comma separated allergens the products are free of, like milk,peanuts"
+// @Param diet query string false "Comma separated diets the products are suitable for, like vegan,gluten-free"
+// @Success 200 {file} file
+// @Failure 400 {object} web.ErrorResponse
+// @Router /products/export [get]
+func (h *ProductHandler) Export() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Obtains the requested file format (CSV by default)
+		format := c.DefaultQuery("format", "csv")
+		var contentType string
+		var write func(w io.Writer, header []string, products []domain.Product) error
+		switch format {
+		case "csv":
+			contentType = "text/csv"
+			write = export.WriteCSV
+		case "xlsx":
+			contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+			write = export.WriteXLSX
+		default:
+			web.Failure(c, 400, ErrInvalidFormat)
+			return
+		}
+
+		// Obtains the products matching the request filters
+		products, err := h.filterProducts(c)
 		if err != nil {
-			web.Failure(c, 400, ErrInvalidPrice)
+			web.Failure(c, 400, err)
 			return
 		}
 
-		filteredProducts, err := h.service.GetByPriceGt(priceGt)
+		// Streams the file to the client
+		c.Header("Content-Type", contentType)
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="products.%s"`, format))
+		c.Status(200)
+		// The columns are named like the fields of the version of the API
+		if err = write(c.Writer, export.RenamedHeader(h.version.FieldName), products); err != nil {
+			_ = c.Error(err)
+		}
+	}
+}
+
+// Stream godoc
+// @Summary Stream products
+// @Tags Products
+// @Description Stream the products catalog as newline-delimited JSON using chunked transfer encoding. Accepts the same filters as the search endpoint.
+// @Produce application/x-ndjson
+// @Param priceGt query number false "Price"
+// @Param filter query string false "Filter expression, like the one of the products list"
+// @Param status query string false "Comma separated statuses of the products, like the ones of the products list"
+// @Param storage query string false "Comma separated storage zones of the products, like chilled,frozen"
+// @Param excludeAllergens query string false "Comma separated allergens the products are free of, like milk,peanuts"
+// @Param diet query string false "Comma separated diets the products are suitable for, like vegan,gluten-free"
+// @Success 200 {array} domain.ProductV1
+// @Failure 400 {object} web.ErrorResponse
+// @Router /products/stream [get]
+func (h *ProductHandler) Stream() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Obtains the products matching the request filters
+		products, err := h.filterProducts(c)
 		if err != nil {
-			web.Failure(c, 404, err)
+			web.Failure(c, 400, err)
 			return
 		}
 
-		web.Success(c, 200, filteredProducts)
+		// Streams one product per line to the client, in the representation of the version of the API
+		records := make([]interface{}, len(products))
+		for i, p := range products {
+			records[i] = h.version.Response(p)
+		}
+		c.Header("Content-Type", "application/x-ndjson")
+		c.Status(200)
+		if err = export.WriteNDJSON(c.Writer, records); err != nil {
+			_ = c.Error(err)
+		}
+	}
+}
+
+// Labels godoc
+// @Summary Print shelf labels
+// @Tags Products
+// @Description Generate a printable PDF with the shelf labels of the given products, with their name, price and the
+// @Description Code 128 barcode of their code value, laid out on A4 pages of 3 by 8 labels of 70 x 37 mm. The
+// @Description labels follow the order of the IDs, and at most 240 of them are printed at once.
+// @Accept json
+// @Produce application/pdf
+// @Param labels body LabelsRequest true "IDs of the labeled products"
+// @Success 200 {file} file
+// @Failure 400 {object} web.ErrorResponse
+// @Failure 404 {object} web.ErrorResponse
+// @Failure 422 {object} web.ErrorResponse
+// @Router /products/labels [post]
+func (h *ProductHandler) Labels() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request LabelsRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			web.Failure(c, 400, ErrInvalidData)
+			return
+		}
+		if len(request.Ids) == 0 || len(request.Ids) > maxLabels {
+			web.Error(c, web.WithParams(ErrInvalidLabels, web.Params{"max": maxLabels}))
+			return
+		}
+
+		service, err := h.catalog(c)
+		if err != nil {
+			web.Error(c, err)
+			return
+		}
+		products := make([]domain.Product, len(request.Ids))
+		for i, id := range request.Ids {
+			if products[i], err = service.GetById(id); err != nil {
+				web.Error(c, web.WithParams(err, web.Params{"id": id}))
+				return
+			}
+		}
+
+		// The document is built before answering, so an invalid barcode is still an error response
+		var document bytes.Buffer
+		if err = export.WriteLabels(&document, products); err != nil {
+			web.Error(c, err)
+			return
+		}
+		c.Header("Content-Disposition", `inline; filename="labels.pdf"`)
+		c.Data(200, "application/pdf", document.Bytes())
 	}
 }
 
@@ -114,35 +508,174 @@ func (h *ProductHandler) GetByPriceGt() gin.HandlerFunc {
 // @Accept json
 // @Produce json
 // @Param token header string true "Token"
-// @Param newProduct body domain.ProductRequest true "new product"
+// @Param Idempotency-Key header string false "Key that makes retries of the creation return the first response"
+// @Param Prefer header string false "return=minimal to receive only the ID of the new product, handling=dry-run for a dry run"
+// @Param newProduct body domain.Product true "new product"
+// @Param dryRun query bool false "Only validate the change and answer the result, without applying it"
 // @Success 201 {object} web.Response
+// @Success 200 {object} web.Response "Result of a dry run"
+// @Header 201 {string} Location "Path of the new product"
 // @Failure 400 {object} web.ErrorResponse
 // @Failure 404 {object} web.ErrorResponse
-// @Router /products/new [post]
+// @Failure 409 {object} web.ErrorResponse
+// @Failure 413 {object} web.ErrorResponse
+// @Failure 422 {object} web.ErrorResponse
+// @Router /products [post]
 func (h *ProductHandler) Create() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		service, dryRun, err := h.mutations(c)
+		if err != nil {
+			web.Error(c, err)
+			return
+		}
+
 		// Obtains the new product data from the request body
-		var newProduct domain.Product
-		if err := c.ShouldBindJSON(&newProduct); err != nil {
+		newProduct, err := h.version.BindProduct(c)
+		if err != nil {
 			web.Failure(c, 400, ErrInvalidData)
 			return
 		}
 
-		// Checks if the product expiration date is valid (DD/MM/YYYY)
+		// Checks if the product expiration date is valid (DD/MM/YYYY or YYYY-MM-DD)
 		validDate, err := validateDate(newProduct.Expiration)
 		if !validDate {
-			web.Failure(c, 400, err)
+			web.Error(c, err)
 			return
 		}
 
 		// Creates the new product
-		createdProduct, err := h.service.Create(newProduct)
+		createdProduct, err := service.Create(newProduct)
 		if err != nil {
-			web.Failure(c, 400, err)
+			web.Error(c, h.invalidFields(web.WithParams(err, web.Params{"code": newProduct.CodeValue})))
+			return
+		}
+		if dryRun {
+			web.Success(c, 200, h.version.Response(createdProduct), web.WithDryRun(true))
+			return
+		}
+
+		// The Location header points to the new product, whose representation is omitted on request
+		location := web.WithLocation(productLocation(c, createdProduct.Id))
+		if web.Prefers(c, preferMinimal) {
+			web.Created(c, CreatedId{Id: createdProduct.Id}, location, web.WithHeader("Preference-Applied", preferMinimal))
+			return
+		}
+		web.Created(c, h.version.Response(createdProduct), location)
+	}
+}
+
+// Clone godoc
+// @Summary Clone a product
+// @Tags Products
+// @Description Create a copy of a product with a new ID. The body must have the code value of the copy, and the
+// @Description other fields present in it replace the copied ones.
+// @Accept json
+// @Produce json
+// @Param token header string true "Token"
+// @Param Idempotency-Key header string false "Key that makes retries of the creation return the first response"
+// @Param id path int true "ID of the copied product"
+// @Param changes body domain.ProductRequest true "code value of the copy and changed fields"
+// @Param dryRun query bool false "Only validate the change and answer the result, without applying it"
+// @Success 201 {object} web.Response
+// @Success 200 {object} web.Response "Result of a dry run"
+// @Header 201 {string} Location "Path of the new product"
+// @Failure 400 {object} web.ErrorResponse
+// @Failure 404 {object} web.ErrorResponse
+// @Failure 409 {object} web.ErrorResponse
+// @Failure 422 {object} web.ErrorResponse
+// @Router /products/{id}/clone [post]
+func (h *ProductHandler) Clone() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		service, dryRun, err := h.mutations(c)
+		if err != nil {
+			web.Error(c, err)
+			return
+		}
+
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			web.Failure(c, 400, ErrInvalidId)
 			return
 		}
+		changes, err := h.version.BindPartial(c)
+		if err != nil {
+			web.Failure(c, 400, ErrInvalidData)
+			return
+		}
+
+		// A new expiration date must be valid, like in a creation
+		if changes.Expiration != nil {
+			if validDate, err := validateDate(*changes.Expiration); !validDate {
+				web.Error(c, err)
+				return
+			}
+		}
 
-		web.Success(c, 201, createdProduct)
+		copied, err := service.Clone(id, changes)
+		if err != nil {
+			code := ""
+			if changes.CodeValue != nil {
+				code = *changes.CodeValue
+			}
+			web.Error(c, h.invalidFields(web.WithParams(err, web.Params{"id": id, "code": code})))
+			return
+		}
+		if dryRun {
+			web.Success(c, 200, h.version.Response(copied), web.WithDryRun(true))
+			return
+		}
+		web.Created(c, h.version.Response(copied), web.WithLocation(productLocation(c, copied.Id)))
+	}
+}
+
+// Merge godoc
+// @Summary Merge a duplicate into a product
+// @Tags Products
+// @Description Consolidate a duplicate into a product, like the possible duplicates reported. The product keeps its
+// @Description ID, code value and fields, gets the stock of the duplicate and the category, description and
+// @Description translations it has none of, and the duplicate is deleted. In the main catalog, the scheduled prices,
+// @Description reviews and views of the duplicate are moved to the product. The merge is audited as product.merged, with both products as
+// @Description they were, and the audit history of the duplicate is kept in the one of the product.
+// @Produce json
+// @Param token header string true "Token"
+// @Param id path int true "ID of the product kept"
+// @Param otherId path int true "ID of the duplicate"
+// @Param dryRun query bool false "Only validate the change and answer the result, without applying it"
+// @Success 200 {object} web.Response
+// @Failure 400 {object} web.ErrorResponse
+// @Failure 404 {object} web.ErrorResponse
+// @Failure 422 {object} web.ErrorResponse
+// @Router /products/{id}/merge/{otherId} [post]
+func (h *ProductHandler) Merge() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		service, dryRun, err := h.mutations(c)
+		if err != nil {
+			web.Error(c, err)
+			return
+		}
+
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			web.Failure(c, 400, ErrInvalidId)
+			return
+		}
+		otherId, err := strconv.Atoi(c.Param("otherId"))
+		if err != nil {
+			web.Failure(c, 400, ErrInvalidId)
+			return
+		}
+
+		// Tell which of the products does not exist
+		if _, err = service.GetById(id); err != nil {
+			web.Error(c, web.WithParams(err, web.Params{"id": id}))
+			return
+		}
+		merged, err := service.Merge(id, otherId)
+		if err != nil {
+			web.Error(c, h.invalidFields(web.WithParams(err, web.Params{"id": otherId})))
+			return
+		}
+		web.Success(c, 200, h.version.Response(merged), web.WithDryRun(dryRun))
 	}
 }
 
@@ -154,17 +687,20 @@ func (h *ProductHandler) Create() gin.HandlerFunc {
 // @Produce json
 // @Param token header string true "Token"
 // @Param id path int true "Product ID"
-// @Param partialUpdateData body domain.ProductRequest true "updated product"
+// @Param newProductData body domain.Product true "updated product"
+// @Param dryRun query bool false "Only validate the change and answer the result, without applying it"
 // @Success 200 {object} web.Response
 // @Failure 400 {object} web.ErrorResponse
 // @Failure 404 {object} web.ErrorResponse
+// @Failure 409 {object} web.ErrorResponse
+// @Failure 413 {object} web.ErrorResponse
+// @Failure 422 {object} web.ErrorResponse
 // @Router /products/{id} [put]
 func (h *ProductHandler) FullUpdate() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Checks if the given token is valid
-		err := isAuthorized(c)
+		service, dryRun, err := h.mutations(c)
 		if err != nil {
-			web.Failure(c, 401, err)
+			web.Error(c, err)
 			return
 		}
 
@@ -177,33 +713,26 @@ func (h *ProductHandler) FullUpdate() gin.HandlerFunc {
 		}
 
 		// Extract the product data from the request body
-		var newProductData domain.Product
-		if err := c.ShouldBindJSON(&newProductData); err != nil {
+		newProductData, err := h.version.BindProduct(c)
+		if err != nil {
 			web.Failure(c, 400, ErrInvalidData)
 			return
 		}
-		// Checks if the product expiration date is valid (DD/MM/YYYY)
+		// Checks if the product expiration date is valid (DD/MM/YYYY or YYYY-MM-DD)
 		isValidDate, err := validateDate(newProductData.Expiration)
 		if !isValidDate {
-			web.Failure(c, 400, err)
+			web.Error(c, err)
 			return
 		}
 
 		// Updates the product
-		updatedProduct, err := h.service.Update(id, newProductData)
-
-		// Check for errors
-		if err != nil && err.Error() == ErrNotFound.Error() {
-			web.Failure(c, 404, err)
-			return
-		}
-
-		if err != nil && err.Error() == ErrInvalidCode.Error() {
-			web.Failure(c, 400, err)
+		updatedProduct, err := service.Update(id, newProductData)
+		if err != nil {
+			web.Error(c, h.invalidFields(web.WithParams(err, web.Params{"id": id, "code": newProductData.CodeValue})))
 			return
 		}
 
-		web.Success(c, 200, updatedProduct)
+		web.Success(c, 200, h.version.Response(updatedProduct), web.WithDryRun(dryRun))
 	}
 }
 
@@ -216,16 +745,19 @@ func (h *ProductHandler) FullUpdate() gin.HandlerFunc {
 // @Param token header string true "Token"
 // @Param id path int true "Product ID"
 // @Param partialUpdateData body domain.ProductRequest true "updated product"
+// @Param dryRun query bool false "Only validate the change and answer the result, without applying it"
 // @Success 200 {object} web.Response
 // @Failure 400 {object} web.ErrorResponse
 // @Failure 404 {object} web.ErrorResponse
+// @Failure 409 {object} web.ErrorResponse
+// @Failure 413 {object} web.ErrorResponse
+// @Failure 422 {object} web.ErrorResponse
 // @Router /products/{id} [patch]
 func (h *ProductHandler) PartialUpdate() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Checks if the given token is valid
-		err := isAuthorized(c)
+		service, dryRun, err := h.mutations(c)
 		if err != nil {
-			web.Failure(c, 401, err)
+			web.Error(c, err)
 			return
 		}
 
@@ -238,44 +770,95 @@ func (h *ProductHandler) PartialUpdate() gin.HandlerFunc {
 		}
 
 		// Extract the product data from the request body
-		var partialUpdateData domain.ProductRequest
-		if err := c.ShouldBindJSON(&partialUpdateData); err != nil {
+		partialUpdateData, err := h.version.BindPartial(c)
+		if err != nil {
 			web.Failure(c, 400, ErrInvalidData)
 			return
 		}
 
-		update := domain.Product{
-			Name:        partialUpdateData.Name,
-			Quantity:    partialUpdateData.Quantity,
-			CodeValue:   partialUpdateData.CodeValue,
-			IsPublished: partialUpdateData.IsPublished,
-			Expiration:  partialUpdateData.Expiration,
-			Price:       partialUpdateData.Price,
-		}
-
-		// Checks if the product expiration date is valid (DD/MM/YYYY)
-		if update.Expiration != "" {
-			isValidDate, err := validateDate(update.Expiration)
+		// Checks if the product expiration date is valid (DD/MM/YYYY or YYYY-MM-DD)
+		if partialUpdateData.Expiration != nil {
+			isValidDate, err := validateDate(*partialUpdateData.Expiration)
 			if !isValidDate {
-				web.Failure(c, 400, err)
+				web.Error(c, err)
 				return
 			}
 		}
 
-		// Updates the product
-		updatedProduct, err := h.service.Update(id, update)
+		// Updates only the fields present in the request
+		updatedProduct, err := service.Patch(id, partialUpdateData)
+		if err != nil {
+			code := ""
+			if partialUpdateData.CodeValue != nil {
+				code = *partialUpdateData.CodeValue
+			}
+			web.Error(c, h.invalidFields(web.WithParams(err, web.Params{"id": id, "code": code})))
+			return
+		}
 
-		// Check for errors
-		if err != nil && err.Error() == ErrNotFound.Error() {
-			web.Failure(c, 404, err)
+		web.Success(c, 200, h.version.Response(updatedProduct), web.WithDryRun(dryRun))
+	}
+}
+
+// BatchPatch godoc
+// @Summary Partially update the products matching a filter
+// @Tags Products
+// @Description Update some fields of every product matching the filter expression, all or none of them. The
+// @Description code value cannot be changed in batch. With dryRun, the products are only validated and the
+// @Description response tells how many would change.
+// @Accept json
+// @Produce json
+// @Param token header string true "Token"
+// @Param filter query string false "Filter expression, like the one of the products list (this or status is required)"
+// @Param status query string false "Comma separated statuses of the products changed, like the ones of the products list"
+// @Param storage query string false "Comma separated storage zones of the products, like chilled,frozen"
+// @Param excludeAllergens query string false "Comma separated allergens the products are free of, like milk,peanuts"
+// @Param diet query string false "Comma separated diets the products are suitable for, like vegan,gluten-free"
+// @Param dryRun query bool false "Only validate the change and count the affected products"
+// @Param partialUpdateData body domain.ProductRequest true "updated fields"
+// @Success 200 {object} web.Response{data=product.BatchResult}
+// @Failure 400 {object} web.ErrorResponse
+// @Failure 413 {object} web.ErrorResponse
+// @Failure 422 {object} web.ErrorResponse
+// @Router /products [patch]
+func (h *ProductHandler) BatchPatch() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// A filter is required, so a missing one never changes the whole catalog
+		expr, ok, err := parseFilter(c)
+		if err != nil {
+			web.Error(c, err)
 			return
 		}
-		if err != nil && err.Error() == ErrInvalidCode.Error() {
-			web.Failure(c, 400, err)
+		if !ok {
+			web.Error(c, ErrMissingFilter)
+			return
+		}
+		service, dryRun, err := h.mutations(c)
+		if err != nil {
+			web.Error(c, err)
 			return
 		}
 
-		web.Success(c, 200, updatedProduct)
+		// Extract the changes from the request body
+		partialUpdateData, err := h.version.BindPartial(c)
+		if err != nil {
+			web.Failure(c, 400, ErrInvalidData)
+			return
+		}
+		if partialUpdateData.Expiration != nil {
+			isValidDate, err := validateDate(*partialUpdateData.Expiration)
+			if !isValidDate {
+				web.Error(c, err)
+				return
+			}
+		}
+
+		result, err := service.PatchMatching(expr, partialUpdateData, dryRun)
+		if err != nil {
+			web.Error(c, h.invalidFields(err))
+			return
+		}
+		web.Success(c, 200, result, web.WithDryRun(dryRun))
 	}
 }
 
@@ -287,16 +870,17 @@ func (h *ProductHandler) PartialUpdate() gin.HandlerFunc {
 // @Produce json
 // @Param token header string true "Token"
 // @Param id path int true "Product ID"
+// @Param dryRun query bool false "Only validate the change and answer the result, without applying it"
 // @Success 204 {object} web.Response
+// @Success 200 {object} web.Response "Result of a dry run"
 // @Failure 400 {object} web.ErrorResponse
 // @Failure 404 {object} web.ErrorResponse
 // @Router /products/{id} [delete]
 func (h *ProductHandler) Delete() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Checks if the given token is valid
-		err := isAuthorized(c)
+		service, dryRun, err := h.mutations(c)
 		if err != nil {
-			web.Failure(c, 401, err)
+			web.Error(c, err)
 			return
 		}
 
@@ -309,14 +893,207 @@ func (h *ProductHandler) Delete() gin.HandlerFunc {
 		}
 
 		// Deletes the product
-		err = h.service.Delete(id)
+		err = service.Delete(id)
 		if err != nil {
-			web.Failure(c, 404, err)
+			web.Error(c, web.WithParams(err, web.Params{"id": id}))
 			return
 		}
 
-		web.Success(c, http.StatusNoContent, nil)
+		// A dry run answers the product that would be deleted
+		if dryRun {
+			deletedProduct, err := service.GetById(id)
+			if err != nil {
+				web.Error(c, web.WithParams(err, web.Params{"id": id}))
+				return
+			}
+			web.Success(c, 200, h.version.Response(deletedProduct), web.WithDryRun(true))
+			return
+		}
+
+		web.NoContent(c)
+	}
+}
+
+/*
+The filterProducts method returns the products matching the filters present in the request query,
+priceGt and the filter expression. Without filters, it returns every product.
+*/
+func (h *ProductHandler) filterProducts(c *gin.Context) ([]domain.Product, error) {
+	expr, hasFilter, err := parseFilter(c)
+	if err != nil {
+		return nil, err
+	}
+	service, err := h.catalog(c)
+	if err != nil {
+		return nil, err
 	}
+	stringPriceGt, hasPrice := c.GetQuery("priceGt")
+	if !hasFilter && !hasPrice {
+		return service.List()
+	}
+
+	var priceGt float64
+	if hasPrice {
+		if priceGt, err = strconv.ParseFloat(stringPriceGt, 64); err != nil {
+			return nil, ErrInvalidPrice
+		}
+	}
+
+	// An empty result is still a valid (empty) export
+	var products []domain.Product
+	switch {
+	case !hasFilter:
+		products, err = service.GetByPriceGt(priceGt)
+	case hasPrice:
+		priceFilter := filter.Comparison{Field: "price", Kind: filter.Number, Operator: filter.Greater, Value: filter.Value{Number: priceGt}}
+		products, err = service.Filter(filter.And{Left: priceFilter, Right: expr})
+	default:
+		products, err = service.Filter(expr)
+	}
+	if errors.Is(err, product.ErrNoProducts) {
+		return []domain.Product{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+// Auxiliary method that returns the service of the catalog of the tenant of the request.
+func (h *ProductHandler) catalog(c *gin.Context) (product.Service, error) {
+	id := web.Tenant(c)
+	if id == "" || h.tenants == nil {
+		return h.service, nil
+	}
+	return h.tenants.Service(id)
+}
+
+/*
+Auxiliary method that returns the service applying the mutation of the request on behalf of its
+actor, and whether it is a dry run, in which case the service only validates the mutation.
+*/
+func (h *ProductHandler) mutations(c *gin.Context) (product.Service, bool, error) {
+	dryRun, err := web.DryRun(c)
+	if err != nil {
+		return nil, false, err
+	}
+	service, err := h.catalog(c)
+	if err != nil {
+		return nil, false, err
+	}
+	if dryRun {
+		return service.DryRun(), true, nil
+	}
+	if actor := web.Actor(c); actor != "" {
+		return service.As(actor), false, nil
+	}
+	return service, false, nil
+}
+
+/*
+Auxiliary function that parses the filter expression of the request, reporting whether there is one.
+The syntax errors carry their position and reason for the error message. The status parameter, a
+comma separated list of statuses, narrows the expression to the products with any of them, the
+storage parameter likewise to the products kept in any of the storage zones, and the
+excludeAllergens and diet parameters to the products of parseDietary.
+*/
+func parseFilter(c *gin.Context) (filter.Expr, bool, error) {
+	var narrowing []filter.Expr
+	if list, ok := c.GetQuery("status"); ok {
+		statuses, err := domain.ParseStatuses(list)
+		if err != nil {
+			return nil, false, err
+		}
+		narrowing = append(narrowing, filter.Statuses(statuses))
+	}
+	if list, ok := c.GetQuery("storage"); ok {
+		zones, err := domain.ParseStorageZones(list)
+		if err != nil {
+			return nil, false, err
+		}
+		narrowing = append(narrowing, filter.StorageZones(zones))
+	}
+	dietaryExpr, err := parseDietary(c)
+	if err != nil {
+		return nil, false, err
+	}
+	if dietaryExpr != nil {
+		narrowing = append(narrowing, dietaryExpr)
+	}
+	var narrowExpr filter.Expr
+	for _, expr := range narrowing {
+		if narrowExpr == nil {
+			narrowExpr = expr
+		} else {
+			narrowExpr = filter.And{Left: narrowExpr, Right: expr}
+		}
+	}
+
+	expression, ok := c.GetQuery("filter")
+	if !ok || strings.TrimSpace(expression) == "" {
+		return narrowExpr, narrowExpr != nil, nil
+	}
+
+	expr, err := filter.Parse(expression)
+	var syntaxErr *filter.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return nil, false, web.WithParams(err, web.Params{"position": syntaxErr.Position, "reason": syntaxErr.Message})
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if narrowExpr != nil {
+		expr = filter.And{Left: narrowExpr, Right: expr}
+	}
+	return expr, true, nil
+}
+
+/*
+Auxiliary function that parses the excludeAllergens and diet parameters of the request, comma
+separated lists of the dietary tags, into an expression matching the products with nutrition facts
+free of every excluded allergen and suitable for every diet. It returns nil without them.
+*/
+func parseDietary(c *gin.Context) (filter.Expr, error) {
+	var allergens, diets []string
+	var err error
+	if list, ok := c.GetQuery("excludeAllergens"); ok {
+		if allergens, err = domain.ParseAllergens(list); err != nil {
+			return nil, err
+		}
+	}
+	if list, ok := c.GetQuery("diet"); ok {
+		if diets, err = domain.ParseDiets(list); err != nil {
+			return nil, err
+		}
+	}
+	return filter.Dietary(allergens, diets), nil
+}
+
+/*
+Auxiliary method that returns the products matching a full-text query, in the relevance order of
+the search engine. The products removed since they were indexed are skipped.
+*/
+func (h *ProductHandler) searchProducts(c *gin.Context, query string) ([]domain.Product, error) {
+	if h.engine == nil || web.Tenant(c) != "" {
+		return nil, search.ErrUnavailable
+	}
+	ids, err := h.engine.Search(c.Request.Context(), query, maxSearchResults)
+	if err != nil {
+		return nil, err
+	}
+
+	products := make([]domain.Product, 0, len(ids))
+	for _, id := range ids {
+		p, err := h.service.GetById(id)
+		if errors.Is(err, domain.ErrProductNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, p)
+	}
+	return products, nil
 }
 
 /*
@@ -325,26 +1102,226 @@ date string is a valid date and occurs after the current date. Otherwise, it ret
 an error.
 */
 func validateDate(date string) (bool, error) {
-	parsedDate, err := time.Parse("02/01/2006", date)
+	if err := domain.ValidateExpiration(date); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+/*
+Auxiliary method that attaches the invalid fields of a product validation error to the error, so
+they are listed in the response with their names in the API version of the handler.
+*/
+func (h *ProductHandler) invalidFields(err error) error {
+	return withInvalidFields(err, h.version.FieldName)
+}
+
+/*
+Auxiliary function that attaches the invalid fields of a validation error to the error, named by the
+given function, so they are listed in the response.
+*/
+func withInvalidFields(err error, fieldName func(field string) string) error {
+	var validationErr *domain.ValidationError
+	if !errors.As(err, &validationErr) {
+		return err
+	}
+
+	fields := make([]web.FieldError, len(validationErr.Fields))
+	for i, field := range validationErr.Fields {
+		fields[i] = web.FieldError{
+			Field:   fieldName(field.Field),
+			Rule:    field.Rule,
+			Message: field.Message,
+		}
+	}
+	return web.WithFields(err, fields)
+}
+
+// Auxiliary method that returns the upcoming prices of a product converted like convertPrices.
+func (h *ProductHandler) convertUpcomingPrices(c *gin.Context, p domain.Product, prices []domain.ScheduledPrice) ([]domain.ScheduledPrice, error) {
+	code, ok, err := requestedCurrency(c)
+	if err != nil || !ok {
+		return prices, err
+	}
+
+	converted := make([]domain.ScheduledPrice, len(prices))
+	for i, scheduled := range prices {
+		from := scheduled.Currency
+		if from == "" {
+			from = p.PriceCurrency()
+		}
+		price, err := h.converter.Convert(c.Request.Context(), scheduled.Price, from, code)
+		if err != nil {
+			return nil, web.WithParams(err, web.Params{"currency": code})
+		}
+		scheduled.Price, scheduled.Currency = price, code
+		converted[i] = scheduled
+	}
+	return converted, nil
+}
+
+/*
+Auxiliary function that returns the ISO 4217 code of the currency query parameter, and whether the
+request has one. Lowercase codes are accepted.
+*/
+func requestedCurrency(c *gin.Context) (string, bool, error) {
+	code, ok := c.GetQuery("currency")
+	if !ok {
+		return "", false, nil
+	}
+	code = strings.ToUpper(code)
+	if !domain.ValidCurrency(code) {
+		return "", false, domain.ErrInvalidCurrency
+	}
+	return code, true, nil
+}
+
+/*
+Auxiliary function that returns the path of a product created through the given request. The
+creations made through the deprecated /products/new alias, or by cloning a product, point to the
+canonical path too.
+*/
+func productLocation(c *gin.Context, id int) string {
+	collection := strings.TrimSuffix(strings.TrimSuffix(c.Request.URL.Path, "/"), "/new")
+	if c.Param("id") != "" {
+		// Strip the "/:id/clone" suffix of the clone requests
+		collection = path.Dir(path.Dir(collection))
+	}
+	return fmt.Sprintf("%s/%d", collection, id)
+}
+
+/*
+Auxiliary method that emits the result of a filter. Matching no product is not an error for a
+filter, so an empty list is emitted unless the handler keeps the legacy 404, which has an
+X-Total-Count header of 0 as well. Every filter endpoint must emit its result through this method.
+*/
+func (h *ProductHandler) successFilter(c *gin.Context, products []domain.Product, err error) {
+	if errors.Is(err, product.ErrNoProducts) && !h.emptyFilterNotFound {
+		products, err = []domain.Product{}, nil
+	}
+	if errors.Is(err, product.ErrNoProducts) {
+		c.Header(web.HeaderTotalCount, "0")
+	}
 	if err != nil {
-		return false, errors.New("invalid expiration date format")
+		web.Error(c, err)
+		return
 	}
+	h.successList(c, products)
+}
 
-	if err == nil && parsedDate.Before(time.Now()) {
-		return false, errors.New("expiration date must be after current date")
+/*
+Auxiliary method that emits a list of products. If the client asked for a page (page and page_size
+query parameters), only that page is returned along with the pagination metadata. The prices are
+converted to the requested currency, if any, and the number of products of the whole list is sent
+in the X-Total-Count header.
+*/
+func (h *ProductHandler) successList(c *gin.Context, products []domain.Product) {
+	page, paginated, err := web.ParsePage(c)
+	if err != nil {
+		web.Error(c, err)
+		return
+	}
+	total := len(products)
+	c.Header(web.HeaderTotalCount, strconv.Itoa(total))
+	if paginated {
+		start, end := page.Bounds(total)
+		products = products[start:end]
+	}
+	products = h.rateProducts(c, localizeProducts(c, products))
+	if products, err = formatDescriptions(c, products); err != nil {
+		web.Error(c, err)
+		return
+	}
+	if products, err = h.convertPrices(c, products); err != nil {
+		web.Error(c, err)
+		return
 	}
 
-	return true, nil
+	if !paginated {
+		web.Success(c, 200, h.version.ResponseList(products))
+		return
+	}
+	web.Success(c, 200, h.version.ResponseList(products), web.WithPagination(page, total))
+}
+
+/*
+Auxiliary function that returns the products with the name and description of the locale the
+client prefers among their translations, by the Accept-Language header, or their own ones. The
+given products are never modified, since they may be shared with the repository.
+*/
+func localizeProducts(c *gin.Context, products []domain.Product) []domain.Product {
+	c.Writer.Header().Add("Vary", "Accept-Language")
+	preferred := web.AcceptedLanguages(c)
+	if len(preferred) == 0 {
+		return products
+	}
+
+	localized := make([]domain.Product, len(products))
+	for i, p := range products {
+		localized[i], _ = p.Localize(preferred)
+	}
+	return localized
 }
 
-// Auxiliary function that checks if the given token is valid.
-func isAuthorized(c *gin.Context) error {
-	// Get the token from the header
-	token := c.GetHeader("token")
+/*
+Auxiliary method that returns the products of the main catalog with the rating of their reviews, if
+the products can be reviewed. The given products are never modified.
+*/
+func (h *ProductHandler) rateProducts(c *gin.Context, products []domain.Product) []domain.Product {
+	if h.reviews == nil || web.Tenant(c) != "" {
+		return products
+	}
+
+	rated := make([]domain.Product, len(products))
+	for i, p := range products {
+		p.Rating = h.reviews.Rating(p.Id)
+		rated[i] = p
+	}
+	return rated
+}
 
-	// Authentication
-	if token != os.Getenv("TOKEN") {
-		return errors.New("invalid token")
+/*
+Auxiliary function that returns the products with their Markdown descriptions rendered to sanitized
+HTML if the description_format query parameter is html. Without the parameter, or with markdown,
+the products are returned unchanged. The given products are never modified.
+*/
+func formatDescriptions(c *gin.Context, products []domain.Product) ([]domain.Product, error) {
+	switch c.Query("description_format") {
+	case "", "markdown":
+		return products, nil
+	case "html":
+	default:
+		return nil, ErrInvalidDescriptionFormat
+	}
+
+	formatted := make([]domain.Product, len(products))
+	for i, p := range products {
+		p.Description = markdown.ToHTML(p.Description)
+		formatted[i] = p
+	}
+	return formatted, nil
+}
+
+/*
+Auxiliary method that returns the products with their prices converted to the currency of the
+currency query parameter. Without the parameter, the products are returned unchanged. The given
+products are never modified, since they may be shared with the repository.
+*/
+func (h *ProductHandler) convertPrices(c *gin.Context, products []domain.Product) ([]domain.Product, error) {
+	code, ok, err := requestedCurrency(c)
+	if err != nil || !ok {
+		return products, err
+	}
+
+	converted := make([]domain.Product, len(products))
+	for i, p := range products {
+		price, err := h.converter.Convert(c.Request.Context(), p.Price, p.PriceCurrency(), code)
+		if err != nil {
+			return nil, web.WithParams(err, web.Params{"currency": code})
+		}
+		p.Price, p.Currency = price, code
+		converted[i] = p
 	}
-	return nil
+	return converted, nil
 }
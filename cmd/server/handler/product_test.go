@@ -1,19 +1,34 @@
 package handler
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/JoseObreque/go-web/cmd/server/middleware"
 	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/popularity"
 	"github.com/JoseObreque/go-web/internal/product"
-	"github.com/JoseObreque/go-web/pkg/store"
+	"github.com/JoseObreque/go-web/internal/product/mocks"
+	"github.com/JoseObreque/go-web/internal/review"
+	"github.com/JoseObreque/go-web/internal/search"
+	"github.com/JoseObreque/go-web/internal/tenant"
+	"github.com/JoseObreque/go-web/pkg/currency"
+	"github.com/JoseObreque/go-web/pkg/events"
+	"github.com/JoseObreque/go-web/pkg/testutil"
 	"github.com/JoseObreque/go-web/pkg/web"
+	"github.com/JoseObreque/go-web/pkg/webtest"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/ugorji/go/codec"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func createServerForTestProducts(token string) *gin.Engine {
@@ -25,164 +40,150 @@ func createServerForTestProducts(token string) *gin.Engine {
 		}
 	}
 
-	// Create a JSON store
-	jsonStore := store.NewJsonStore("products_copy.json")
-
 	// Obtains a slice of products
-	products, err := jsonStore.GetAll()
-	if err != nil {
-		panic(err)
-	}
+	products := testProducts()
 
-	// Create a new product handler
+	// Create a new product service
+	bus := events.NewBus()
 	repository := product.NewRepository(products)
-	service := product.NewService(repository)
-	productHandler := NewProductHandler(service)
+	service := product.NewService(repository, bus)
 
 	// Define a new router
 	router := gin.New()
 	router.Use(middleware.PanicLogger())
 
-	// Add the product handler to the router
-	generalGroup := router.Group("/api/v1")
-
-	productGroup := generalGroup.Group("/products")
-	{
-		productGroup.GET("/all", productHandler.GetAll())
-		productGroup.GET("/:id", productHandler.GetById())
-		productGroup.GET("/search", productHandler.GetByPriceGt())
+	// Add a product handler per API version to the router
+	versions := map[string]APIVersion{
+		"/api/v1": V1,
+		"/api/v2": V2,
 	}
+	for path, version := range versions {
+		productHandler := NewVersionedProductHandler(service, version)
+		eventHandler := NewEventHandler(bus, version)
+		generalGroup := router.Group(path)
+
+		productGroup := generalGroup.Group("/products")
+		{
+			productGroup.GET("", productHandler.GetAll())
+			productGroup.GET("/all", middleware.Deprecated(time.Now().AddDate(0, 6, 0), path+"/products"), productHandler.GetAll())
+			productGroup.GET("/:id", productHandler.GetById())
+			productGroup.GET("/search", productHandler.GetByPriceGt())
+			productGroup.GET("/export", productHandler.Export())
+			productGroup.GET("/stream", productHandler.Stream())
+			productGroup.GET("/events", eventHandler.Stream())
+			productGroup.POST("/labels", productHandler.Labels())
+		}
 
-	protectedProductGroup := generalGroup.Group("/products")
-	protectedProductGroup.Use(middleware.TokenValidator())
-	{
-		protectedProductGroup.POST("/new", productHandler.Create())
-		protectedProductGroup.PUT("/:id", productHandler.FullUpdate())
-		protectedProductGroup.PATCH("/:id", productHandler.PartialUpdate())
-		protectedProductGroup.DELETE("/:id", productHandler.Delete())
+		protectedProductGroup := generalGroup.Group("/products")
+		protectedProductGroup.Use(middleware.TokenValidator())
+		{
+			protectedProductGroup.POST("", productHandler.Create())
+			protectedProductGroup.POST("/new", middleware.Deprecated(time.Now().AddDate(0, 6, 0), path+"/products"), productHandler.Create())
+			protectedProductGroup.POST("/:id/clone", productHandler.Clone())
+			protectedProductGroup.PATCH("", productHandler.BatchPatch())
+			protectedProductGroup.PUT("/:id", productHandler.FullUpdate())
+			protectedProductGroup.PATCH("/:id", productHandler.PartialUpdate())
+			protectedProductGroup.DELETE("/:id", productHandler.Delete())
+		}
 	}
 
 	return router
 }
 
-func createRequestTest(method string, url string, body string) (*http.Request, *httptest.ResponseRecorder) {
-	// Create a new request
-	request := httptest.NewRequest(method, url, bytes.NewBuffer([]byte(body)))
-
-	// Add elements to the header
-	request.Header.Add("Content-Type", "application/json")
-
-	return request, httptest.NewRecorder()
+// The testProducts function returns the products of the handler tests, the same on every call.
+func testProducts() []domain.Product {
+	return testutil.Fixtures(30)
 }
 
 func TestProductHandler_GetAll_OK(t *testing.T) {
-	router := createServerForTestProducts("")
-	request, responseRecorder := createRequestTest(http.MethodGet, "https://localhost:8080/api/v1/products/all", "")
-
-	// Expected response
-	jsonStore := store.NewJsonStore("products_copy.json")
-	expectedResponse := web.Response{
-		Data: []domain.Product{},
-	}
-	expectedProductsData, err := jsonStore.GetAll()
-	if err != nil {
-		panic(err)
-	}
-	expectedResponse.Data = expectedProductsData
+	client := webtest.NewClient(t, createServerForTestProducts(""))
 
 	// Actual response
-	router.ServeHTTP(responseRecorder, request)
-	actualResponse := map[string][]domain.Product{}
-	err = json.Unmarshal(responseRecorder.Body.Bytes(), &actualResponse)
+	response := client.Get("https://localhost:8080/api/v1/products/all")
 
 	// Assertions
-	assert.Equal(t, http.StatusOK, responseRecorder.Code)
-	assert.Equal(t, expectedResponse.Data, actualResponse["data"])
-
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, testProducts(), webtest.Data[[]domain.Product](response))
 }
 
 func TestProductHandler_GetById_OK(t *testing.T) {
-	router := createServerForTestProducts("")
-	request, responseRecorder := createRequestTest(http.MethodGet, "https://localhost:8080/api/v1/products/1", "")
-
-	// Expected response
-	jsonStore := store.NewJsonStore("products_copy.json")
-	expectedResponse := web.Response{
-		Data: domain.Product{},
-	}
-	expectedProductsData, err := jsonStore.GetOne(1)
-	if err != nil {
-		panic(err)
-	}
-	expectedResponse.Data = expectedProductsData
+	client := webtest.NewClient(t, createServerForTestProducts(""))
 
 	// Actual response
-	router.ServeHTTP(responseRecorder, request)
-	actualResponse := map[string]domain.Product{}
-	err = json.Unmarshal(responseRecorder.Body.Bytes(), &actualResponse)
+	response := client.Get("https://localhost:8080/api/v1/products/1")
 
 	// Assertions
-	assert.Equal(t, http.StatusOK, responseRecorder.Code)
-	assert.Equal(t, expectedResponse.Data, actualResponse["data"])
-
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, testProducts()[0], webtest.Data[domain.Product](response))
 }
 
 func TestProductHandler_Create_OK(t *testing.T) {
-	// Expected response
-	expectedResponse := web.Response{
-		Data: domain.Product{
-			Id:          501,
-			Name:        "New Product",
-			Quantity:    100,
-			CodeValue:   "NewCode123",
-			IsPublished: true,
-			Expiration:  "25/10/2030",
-			Price:       900,
-		},
-	}
-	expectedProductData, err := json.Marshal(expectedResponse.Data)
-	if err != nil {
-		panic(err)
+	// Expected product
+	expectedProduct := domain.Product{
+		Id:         31,
+		Name:       "New Product",
+		Quantity:   100,
+		CodeValue:  "NewCode123",
+		Status:     domain.StatusPublished,
+		Expiration: "25/10/2030",
+		Price:      900,
 	}
 
-	router := createServerForTestProducts("12345")
-	request, responseRecorder := createRequestTest(
-		http.MethodPost,
-		"https://localhost:8080/api/v1/products/new",
-		string(expectedProductData),
-	)
-	request.Header.Add("token", "12345")
+	client := webtest.NewClient(t, createServerForTestProducts("12345")).WithToken("12345")
 
 	// Actual response
-	router.ServeHTTP(responseRecorder, request)
-	actualResponse := map[string]domain.Product{}
-	err = json.Unmarshal(responseRecorder.Body.Bytes(), &actualResponse)
-	if err != nil {
-		panic(err)
-	}
+	response := client.Post("https://localhost:8080/api/v1/products/new", expectedProduct)
 
 	// Assertions
-	assert.Equal(t, http.StatusCreated, responseRecorder.Code)
-	assert.Equal(t, expectedResponse.Data, actualResponse["data"])
+	assert.Equal(t, http.StatusCreated, response.Code)
+	assert.Equal(t, expectedProduct, webtest.Data[domain.Product](response))
 }
 
 func TestProductHandler_Delete_OK(t *testing.T) {
-	router := createServerForTestProducts("12345")
-	request, responseRecorder := createRequestTest(
-		http.MethodDelete,
-		"https://localhost:8080/api/v1/products/1",
-		"",
-	)
-	request.Header.Add("token", "12345")
+	client := webtest.NewClient(t, createServerForTestProducts("12345")).WithToken("12345")
 
 	// Actual response
-	router.ServeHTTP(responseRecorder, request)
-	actualResponse := responseRecorder.Body.Bytes()
+	response := client.Delete("https://localhost:8080/api/v1/products/1")
 
 	// Assertions
-	assert.Equal(t, 204, responseRecorder.Code)
-	assert.Nil(t, actualResponse)
+	assert.Equal(t, http.StatusNoContent, response.Code)
+	assert.Empty(t, response.Body.Bytes())
+}
+
+func TestProductHandler_PartialUpdate_OK(t *testing.T) {
+	client := webtest.NewClient(t, createServerForTestProducts("12345")).WithToken("12345")
+
+	// Actual responses
+	response := client.Patch("https://localhost:8080/api/v1/products/1", `{"price": 80}`)
+	patched := webtest.Data[domain.Product](response)
+	unpublishResponse := client.Patch("https://localhost:8080/api/v1/products/1", `{"is_published": false}`)
+	unpublished := webtest.Data[domain.Product](unpublishResponse)
+
+	// Assertions: the omitted fields keep their value, and false is applied when present
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, 80.0, patched.Price)
+	assert.True(t, patched.Published())
+	assert.Equal(t, testProducts()[0].Name, patched.Name)
+	assert.Equal(t, http.StatusOK, unpublishResponse.Code)
+	assert.False(t, unpublished.Published())
+	assert.Equal(t, 80.0, unpublished.Price)
+}
 
+func TestProductHandler_PricePerUnit(t *testing.T) {
+	client := webtest.NewClient(t, createServerForTestProducts("12345")).WithToken("12345")
+
+	// Actual responses
+	response := client.Patch("https://localhost:8080/api/v1/products/1", `{"price": 3, "unit": "kg", "pack_size": 0.4}`)
+	v2 := client.Get("https://localhost:8080/api/v2/products?filter=" + url.QueryEscape("unit = 'kg'"))
+	removed := client.Patch("https://localhost:8080/api/v1/products/1", `{"unit": "", "pack_size": 0}`)
+	invalid := client.Patch("https://localhost:8080/api/v1/products/1", `{"unit": "lb", "pack_size": 1}`)
+
+	// Assertions: the price per unit follows the price, and it is never stored
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, 7.5, *webtest.Data[domain.Product](response).PricePerUnit)
+	assert.Equal(t, 7.5, *webtest.Data[[]domain.ProductV2](v2)[0].PricePerUnit)
+	assert.Nil(t, webtest.Data[domain.Product](removed).PricePerUnit)
+	assert.Equal(t, http.StatusUnprocessableEntity, invalid.Code)
 }
 
 func TestProductHandler_BadRequest(t *testing.T) {
@@ -193,32 +194,27 @@ func TestProductHandler_BadRequest(t *testing.T) {
 		http.MethodPatch,
 		http.MethodDelete,
 	}
-	// Create a new router
-	router := createServerForTestProducts("12345")
+	client := webtest.NewClient(t, createServerForTestProducts("12345")).WithToken("12345")
 
 	// Iterate through the http methods slice
 	for _, method := range httpMethods {
-		// Create a request for every http method
-		request, responseRecorder := createRequestTest(
-			method,
-			"https://localhost:8080/api/v1/products/badId",
-			"",
-		)
-
-		// Attach the token to the header and serve the request
-		request.Header.Add("token", "12345")
-		router.ServeHTTP(responseRecorder, request)
-
-		// Unmarshal the actual response
-		actualResponse := map[string]interface{}{}
-		err := json.Unmarshal(responseRecorder.Body.Bytes(), &actualResponse)
-		if err != nil {
-			panic(err)
-		}
+		response := client.Do(method, "https://localhost:8080/api/v1/products/badId", nil)
 
 		// Assertions
-		assert.Equal(t, http.StatusBadRequest, responseRecorder.Code)
-		assert.Equal(t, http.StatusText(http.StatusBadRequest), actualResponse["code"])
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.Equal(t, http.StatusText(http.StatusBadRequest), response.Error().Code)
+	}
+}
+
+// The newTestProduct function returns the product sent in the bodies of the handler tests.
+func newTestProduct() domain.Product {
+	return domain.Product{
+		Name:       "New Product",
+		Quantity:   100,
+		CodeValue:  "NewCode123",
+		Status:     domain.StatusPublished,
+		Expiration: "25/10/2030",
+		Price:      900,
 	}
 }
 
@@ -230,46 +226,15 @@ func TestProductHandler_NotFound(t *testing.T) {
 		http.MethodPatch,
 		http.MethodDelete,
 	}
-	// Create a new router
-	router := createServerForTestProducts("12345")
-
-	// Create a body for the http methods that requires one
-	newProduct := domain.Product{
-		Name:        "New Product",
-		Quantity:    100,
-		CodeValue:   "NewCode123",
-		IsPublished: true,
-		Expiration:  "25/10/2030",
-		Price:       900,
-	}
-	bodyProduct, err := json.Marshal(newProduct)
-	if err != nil {
-		panic(err)
-	}
+	client := webtest.NewClient(t, createServerForTestProducts("12345")).WithToken("12345")
 
 	// Iterate through the http methods slice
 	for _, method := range httpMethods {
-		// Create a request for every http method
-		request, responseRecorder := createRequestTest(
-			method,
-			"https://localhost:8080/api/v1/products/9999",
-			string(bodyProduct),
-		)
-
-		// Attach the token to the header and serve the request
-		request.Header.Add("token", "12345")
-		router.ServeHTTP(responseRecorder, request)
-
-		// Unmarshal the actual response
-		actualResponse := map[string]interface{}{}
-		err := json.Unmarshal(responseRecorder.Body.Bytes(), &actualResponse)
-		if err != nil {
-			panic(err)
-		}
+		response := client.Do(method, "https://localhost:8080/api/v1/products/9999", newTestProduct())
 
 		// Assertions
-		assert.Equal(t, http.StatusNotFound, responseRecorder.Code)
-		assert.Equal(t, http.StatusText(http.StatusNotFound), actualResponse["code"])
+		assert.Equal(t, http.StatusNotFound, response.Code)
+		assert.Equal(t, http.StatusText(http.StatusNotFound), response.Error().Code)
 	}
 }
 
@@ -281,84 +246,694 @@ func TestProductHandler_Unauthorized(t *testing.T) {
 			http.MethodPatch,
 			http.MethodDelete,
 		}
-		// Create a new router
-		router := createServerForTestProducts("12345")
-
-		// Create a body for the http methods that requires one
-		newProduct := domain.Product{
-			Name:        "New Product",
-			Quantity:    100,
-			CodeValue:   "NewCode123",
-			IsPublished: true,
-			Expiration:  "25/10/2030",
-			Price:       900,
-		}
-		bodyProduct, err := json.Marshal(newProduct)
-		if err != nil {
-			panic(err)
-		}
+		client := webtest.NewClient(t, createServerForTestProducts("12345"))
 
 		// Iterate through the http methods slice
 		for _, method := range httpMethods {
-			// Create a request for every http method
-			request, responseRecorder := createRequestTest(
-				method,
-				"https://localhost:8080/api/v1/products/1",
-				string(bodyProduct),
-			)
-
-			// Serve the request
-			router.ServeHTTP(responseRecorder, request)
-
-			// Unmarshal the actual response
-			actualResponse := map[string]interface{}{}
-			err := json.Unmarshal(responseRecorder.Body.Bytes(), &actualResponse)
-			if err != nil {
-				panic(err)
-			}
+			response := client.Do(method, "https://localhost:8080/api/v1/products/1", newTestProduct())
 
 			// Assertions
-			assert.Equal(t, http.StatusUnauthorized, responseRecorder.Code)
-			assert.Equal(t, http.StatusText(http.StatusUnauthorized), actualResponse["code"])
+			assert.Equal(t, http.StatusUnauthorized, response.Code)
+			assert.Equal(t, http.StatusText(http.StatusUnauthorized), response.Error().Code)
 		}
 	})
 	t.Run("Unauthorized POST", func(t *testing.T) {
-		// Create a new router
-		router := createServerForTestProducts("12345")
-
-		// Create a body for the POST request
-		newProduct := domain.Product{
-			Name:        "New Product",
-			Quantity:    100,
-			CodeValue:   "NewCode123",
-			IsPublished: true,
-			Expiration:  "25/10/2030",
-			Price:       900,
-		}
-		bodyProduct, err := json.Marshal(newProduct)
-		if err != nil {
-			panic(err)
-		}
+		client := webtest.NewClient(t, createServerForTestProducts("12345"))
+
+		// Actual response
+		response := client.Post("https://localhost:8080/api/v1/products/new", newTestProduct())
 
-		// Create the POST request
-		request, responseRecorder := createRequestTest(
-			http.MethodPost,
-			"https://localhost:8080/api/v1/products/new",
-			string(bodyProduct),
-		)
+		// Assertions
+		assert.Equal(t, http.StatusUnauthorized, response.Code)
+		assert.Equal(t, http.StatusText(http.StatusUnauthorized), response.Error().Code)
+	})
+}
 
-		// Serve the request
-		router.ServeHTTP(responseRecorder, request)
+func TestProductHandler_Export_OK(t *testing.T) {
+	client := webtest.NewClient(t, createServerForTestProducts(""))
 
-		// Unmarshal the actual response
-		actualResponse := map[string]interface{}{}
-		err = json.Unmarshal(responseRecorder.Body.Bytes(), &actualResponse)
-		if err != nil {
+	t.Run("Export CSV", func(t *testing.T) {
+		response := client.Get("https://localhost:8080/api/v1/products/export?format=csv&priceGt=999")
+
+		// Assertions
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Equal(t, "text/csv", response.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="products.csv"`, response.Header().Get("Content-Disposition"))
+		assert.Contains(t, response.Body.String(), "id,name,quantity,code_value,status,expiration,price")
+	})
+	t.Run("Export XLSX", func(t *testing.T) {
+		response := client.Get("https://localhost:8080/api/v1/products/export?format=xlsx")
+
+		// Assertions (an XLSX file is a zip archive)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Equal(t, []byte("PK"), response.Body.Bytes()[:2])
+	})
+	t.Run("Invalid format", func(t *testing.T) {
+		response := client.Get("https://localhost:8080/api/v1/products/export?format=pdf")
+
+		// Assertions
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+	})
+}
+
+func TestProductHandler_Labels(t *testing.T) {
+	client := webtest.NewClient(t, createServerForTestProducts(""))
+
+	t.Run("Printable PDF", func(t *testing.T) {
+		response := client.Post("https://localhost:8080/api/v1/products/labels", LabelsRequest{Ids: []int{1, 3, 1}})
+
+		// Assertions
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Equal(t, "application/pdf", response.Header().Get("Content-Type"))
+		assert.Equal(t, `inline; filename="labels.pdf"`, response.Header().Get("Content-Disposition"))
+		assert.True(t, strings.HasPrefix(response.Body.String(), "%PDF-1.4"))
+	})
+	t.Run("Invalid requests", func(t *testing.T) {
+		empty := client.Post("https://localhost:8080/api/v1/products/labels", LabelsRequest{})
+		tooMany := client.Post("https://localhost:8080/api/v1/products/labels", LabelsRequest{Ids: make([]int, maxLabels+1)})
+		missing := client.Post("https://localhost:8080/api/v1/products/labels", LabelsRequest{Ids: []int{1, 9999}})
+
+		// Assertions
+		assert.Equal(t, "invalid_labels", empty.Error().ErrorCode)
+		assert.Equal(t, "invalid_labels", tooMany.Error().ErrorCode)
+		assert.Equal(t, http.StatusNotFound, missing.Code)
+	})
+}
+
+func TestProductHandler_Stream_OK(t *testing.T) {
+	client := webtest.NewClient(t, createServerForTestProducts(""))
+
+	// Actual response (one JSON document per line)
+	response := client.Get("https://localhost:8080/api/v1/products/stream")
+	var actualProductsData []domain.Product
+	decoder := json.NewDecoder(response.Body)
+	for decoder.More() {
+		var p domain.Product
+		if err := decoder.Decode(&p); err != nil {
 			panic(err)
 		}
+		actualProductsData = append(actualProductsData, p)
+	}
+
+	// Assertions
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "application/x-ndjson", response.Header().Get("Content-Type"))
+	assert.Equal(t, testProducts(), actualProductsData)
+}
+
+func TestProductHandler_StreamAndExport_V2(t *testing.T) {
+	client := webtest.NewClient(t, createServerForTestProducts(""))
+
+	// Actual responses
+	stream := client.Get("https://localhost:8080/api/v2/products/stream")
+	csv := client.Get("https://localhost:8080/api/v2/products/export?format=csv")
+	var first domain.ProductV2
+	if err := json.NewDecoder(stream.Body).Decode(&first); err != nil {
+		panic(err)
+	}
+
+	// Assertions: the fields are named like in the second version
+	assert.Equal(t, testProducts()[0].CodeValue, first.Code)
+	assert.NotContains(t, stream.Body.String(), "code_value")
+	assert.Contains(t, csv.Body.String(), "id,name,quantity,code,status,expiration,price")
+}
+
+func TestProductHandler_GetById_MsgPack(t *testing.T) {
+	client := webtest.NewClient(t, createServerForTestProducts("")).WithHeader("Accept", "application/msgpack")
+
+	// Actual response
+	response := client.Get("https://localhost:8080/api/v1/products/1")
+	actualResponse := map[string]domain.Product{}
+	var handle codec.MsgpackHandle
+	err := codec.NewDecoderBytes(response.Body.Bytes(), &handle).Decode(&actualResponse)
+	if err != nil {
+		panic(err)
+	}
+
+	// Assertions
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Header().Get("Content-Type"), "application/msgpack")
+	assert.Equal(t, testProducts()[0], actualResponse["data"])
+}
+
+func TestProductHandler_JSONAPI(t *testing.T) {
+	client := webtest.NewClient(t, createServerForTestProducts("")).WithHeader("Accept", web.MIMEJSONAPI)
+
+	t.Run("Resource document", func(t *testing.T) {
+		response := client.Get("https://localhost:8080/api/v1/products/1")
+		resource := webtest.Decode[web.Document](response).Data.(map[string]interface{})
 
 		// Assertions
-		assert.Equal(t, http.StatusUnauthorized, responseRecorder.Code)
-		assert.Equal(t, http.StatusText(http.StatusUnauthorized), actualResponse["code"])
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Equal(t, web.MIMEJSONAPI, response.Header().Get("Content-Type"))
+		assert.Equal(t, "products", resource["type"])
+		assert.Equal(t, "1", resource["id"])
+		assert.NotContains(t, resource["attributes"], "id")
 	})
+	t.Run("Errors document", func(t *testing.T) {
+		response := client.Get("https://localhost:8080/api/v1/products/9999")
+		document := webtest.Decode[web.Document](response)
+
+		// Assertions
+		assert.Equal(t, http.StatusNotFound, response.Code)
+		assert.Len(t, document.Errors, 1)
+		assert.Equal(t, "404", document.Errors[0].Status)
+	})
+}
+
+func TestProductHandler_V2_GetById_OK(t *testing.T) {
+	client := webtest.NewClient(t, createServerForTestProducts(""))
+
+	// Actual response
+	response := client.Get("https://localhost:8080/api/v2/products/1")
+	actualProductData := webtest.Data[map[string]interface{}](response)
+
+	// Assertions
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, testProducts()[0].CodeValue, actualProductData["code"])
+	assert.NotContains(t, actualProductData, "code_value")
+}
+
+func TestProductHandler_V1_IsPublished(t *testing.T) {
+	client := webtest.NewClient(t, createServerForTestProducts(""))
+
+	// Actual responses
+	v1 := webtest.Data[[]map[string]interface{}](client.Get("https://localhost:8080/api/v1/products"))
+	v2 := webtest.Data[[]map[string]interface{}](client.Get("https://localhost:8080/api/v2/products"))
+
+	// Assertions
+	for i, product := range testProducts() {
+		assert.Equal(t, product.Published(), v1[i]["is_published"])
+		assert.Equal(t, string(product.Status), v1[i]["status"])
+		assert.NotContains(t, v2[i], "is_published")
+	}
+}
+
+func TestProductHandler_DeprecatedAliases(t *testing.T) {
+	client := webtest.NewClient(t, createServerForTestProducts(""))
+
+	t.Run("Canonical route", func(t *testing.T) {
+		response := client.Get("https://localhost:8080/api/v1/products")
+
+		// Assertions
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Empty(t, response.Header().Get("Deprecation"))
+	})
+	t.Run("Deprecated alias", func(t *testing.T) {
+		response := client.Get("https://localhost:8080/api/v1/products/all")
+
+		// Assertions
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Equal(t, "true", response.Header().Get("Deprecation"))
+		assert.NotEmpty(t, response.Header().Get("Sunset"))
+		assert.Equal(t, `</api/v1/products>; rel="successor-version"`, response.Header().Get("Link"))
+	})
+}
+
+// The flushNotifier struct is a response recorder that signals when the response is first flushed.
+type flushNotifier struct {
+	*httptest.ResponseRecorder
+	flushed chan struct{}
+	once    sync.Once
+}
+
+func (f *flushNotifier) Flush() {
+	f.ResponseRecorder.Flush()
+	f.once.Do(func() { close(f.flushed) })
+}
+
+func TestEventHandler_Stream(t *testing.T) {
+	router := createServerForTestProducts("12345")
+	client := webtest.NewClient(t, router).WithToken("12345")
+
+	// Open the event stream in the background
+	ctx, cancel := context.WithCancel(context.Background())
+	streamRequest := httptest.NewRequest(http.MethodGet, "https://localhost:8080/api/v1/products/events", nil).WithContext(ctx)
+	streamRecorder := httptest.NewRecorder()
+	streamWriter := &flushNotifier{ResponseRecorder: streamRecorder, flushed: make(chan struct{})}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		router.ServeHTTP(streamWriter, streamRequest)
+	}()
+	<-streamWriter.flushed
+
+	// Create a product while the stream is open
+	response := client.Post("https://localhost:8080/api/v1/products", newTestProduct())
+
+	// Close the stream
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	wg.Wait()
+
+	// Assertions
+	assert.Equal(t, http.StatusCreated, response.Code)
+	assert.Equal(t, "text/event-stream", streamRecorder.Header().Get("Content-Type"))
+	assert.Contains(t, streamRecorder.Body.String(), "event:product.created")
+	assert.Contains(t, streamRecorder.Body.String(), "NewCode123")
+}
+
+func TestProductHandler_Conflict(t *testing.T) {
+	client := webtest.NewClient(t, createServerForTestProducts("12345")).WithToken("12345")
+
+	// The code value of the first product is already taken
+	products := testProducts()
+	body := fmt.Sprintf(`{"name":"Copy","quantity":1,"code_value":%q,"expiration":"25/10/2030","price":10}`, products[0].CodeValue)
+	conflict := client.Post("https://localhost:8080/api/v1/products", body)
+
+	// An expired date is well formed but invalid
+	expired := client.Post("https://localhost:8080/api/v1/products",
+		`{"name":"Expired","quantity":1,"code_value":"EXP1","expiration":"25/10/2020","price":10}`)
+
+	// Assertions
+	assert.Equal(t, http.StatusConflict, conflict.Code)
+	assert.Equal(t, "duplicate_code_value", conflict.Error().ErrorCode)
+	assert.Equal(t, http.StatusUnprocessableEntity, expired.Code)
+	assert.Equal(t, "expired_date", expired.Error().ErrorCode)
+}
+
+func TestProductHandler_InvalidFields(t *testing.T) {
+	client := webtest.NewClient(t, createServerForTestProducts("12345")).WithToken("12345")
+	invalid := newTestProduct()
+	invalid.Quantity = -50
+
+	put := client.Put("https://localhost:8080/api/v1/products/1", invalid)
+	patch := client.Patch("https://localhost:8080/api/v2/products/1", `{"code":"not valid","price":-1}`)
+	jsonAPI := client.WithHeader("Accept", web.MIMEJSONAPI).Patch("https://localhost:8080/api/v1/products/1", `{"price":-1}`)
+	document := webtest.Decode[web.Document](jsonAPI)
+
+	// Assertions: nothing changed, and every invalid field is named as in the API version
+	assert.Equal(t, http.StatusUnprocessableEntity, put.Code)
+	assert.Equal(t, "invalid_product", put.Error().ErrorCode)
+	assert.Equal(t, []web.FieldError{{Field: "quantity", Rule: "min", Message: "must not be negative"}}, put.Error().Fields)
+	assert.Equal(t, http.StatusUnprocessableEntity, patch.Code)
+	assert.Equal(t, "code", patch.Error().Fields[0].Field)
+	assert.Equal(t, "price", patch.Error().Fields[1].Field)
+	assert.Equal(t, "/data/attributes/price", document.Errors[0].Source.Pointer)
+	assert.Equal(t, testProducts()[0], webtest.Data[domain.Product](client.Get("https://localhost:8080/api/v1/products/1")))
+}
+
+func TestProductHandler_Clone(t *testing.T) {
+	client := webtest.NewClient(t, createServerForTestProducts("12345")).WithToken("12345")
+	source := testProducts()[0]
+
+	cloned := client.Post("https://localhost:8080/api/v1/products/1/clone", `{"code_value":"CLONE1","quantity":3}`)
+	clonedV2 := client.Post("https://localhost:8080/api/v2/products/1/clone", `{"code":"CLONE2"}`)
+	withoutCode := client.Post("https://localhost:8080/api/v1/products/1/clone", `{"name":"Copy"}`)
+	duplicate := client.Post("https://localhost:8080/api/v1/products/1/clone", `{"code_value":"CLONE1"}`)
+	missing := client.Post("https://localhost:8080/api/v1/products/999/clone", `{"code_value":"CLONE3"}`)
+	copied := webtest.Data[domain.Product](cloned)
+
+	// Assertions: every other field is copied
+	expected := source
+	expected.Id, expected.CodeValue, expected.Quantity = copied.Id, "CLONE1", 3
+	assert.Equal(t, http.StatusCreated, cloned.Code)
+	assert.NotEqual(t, source.Id, copied.Id)
+	assert.Equal(t, expected, copied)
+	assert.Equal(t, fmt.Sprintf("/api/v1/products/%d", copied.Id), cloned.Header().Get("Location"))
+	assert.Equal(t, http.StatusCreated, clonedV2.Code)
+	assert.Equal(t, "CLONE2", webtest.Data[domain.ProductV2](clonedV2).Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, withoutCode.Code)
+	assert.Equal(t, "code_value", withoutCode.Error().Fields[0].Field)
+	assert.Equal(t, http.StatusConflict, duplicate.Code)
+	assert.Equal(t, http.StatusNotFound, missing.Code)
+}
+
+func TestProductHandler_Merge(t *testing.T) {
+	service := product.NewService(product.NewRepository(testProducts()), nil)
+	repository, _ := review.NewRepository("")
+	reviews := review.NewService(repository, service)
+	views, err := popularity.NewCounter("", 16)
+	if err != nil {
+		panic(err)
+	}
+	service.(*product.ServiceImpl).WithReferrers(reviews, views)
+	router := gin.New()
+	router.POST("/products/:id/merge/:otherId", NewProductHandler(service).Merge())
+	client := webtest.NewClient(t, router)
+	target, duplicate := testProducts()[0], testProducts()[1]
+
+	if _, err = reviews.Post(2, domain.ReviewRequest{Rating: 4, Author: "Jane"}); err != nil {
+		panic(err)
+	}
+	views.View(2)
+	dryRun := client.Post("/products/1/merge/2?dryRun=true", nil)
+	merged := client.Post("/products/1/merge/2", nil)
+	again := client.Post("/products/1/merge/2", nil)
+	missingTarget := client.Post("/products/999/merge/3", nil)
+	itself := client.Post("/products/1/merge/1", nil)
+
+	// Assertions: the target keeps its ID and code value, with the stock of both
+	product := webtest.Data[domain.Product](merged)
+	assert.Equal(t, http.StatusOK, dryRun.Code)
+	assert.Equal(t, http.StatusOK, merged.Code)
+	assert.Equal(t, target.Id, product.Id)
+	assert.Equal(t, target.CodeValue, product.CodeValue)
+	assert.Equal(t, target.Quantity+duplicate.Quantity, product.Quantity)
+	assert.Equal(t, 4.0, reviews.Rating(1).Average)
+	assert.Nil(t, reviews.Rating(2))
+	assert.Equal(t, []popularity.Count{{ProductId: 1, Views: 1}}, views.Top(-1))
+	assert.Equal(t, http.StatusNotFound, again.Code)
+	assert.Equal(t, "product not found: id 2", again.Error().Message)
+	assert.Equal(t, "product not found: id 999", missingTarget.Error().Message)
+	assert.Equal(t, http.StatusBadRequest, itself.Code)
+	assert.Equal(t, "self_merge", itself.Error().ErrorCode)
+}
+
+func TestProductHandler_Create_Location(t *testing.T) {
+	client := webtest.NewClient(t, createServerForTestProducts("12345")).WithToken("12345")
+	body := `{"name":"Located","quantity":1,"code_value":"LOC1","expiration":"25/10/2030","price":10}`
+
+	full := client.Post("https://localhost:8080/api/v1/products", body)
+	id := webtest.Data[domain.Product](full).Id
+
+	// The minimal representation only carries the ID of the product
+	minimal := client.WithHeader("Prefer", "return=minimal").Post("https://localhost:8080/api/v1/products",
+		strings.Replace(body, "LOC1", "LOC2", 1))
+
+	// Assertions
+	assert.Equal(t, http.StatusCreated, full.Code)
+	assert.Equal(t, fmt.Sprintf("/api/v1/products/%d", id), full.Header().Get("Location"))
+	assert.Equal(t, http.StatusCreated, minimal.Code)
+	assert.Equal(t, fmt.Sprintf("/api/v1/products/%d", id+1), minimal.Header().Get("Location"))
+	assert.Equal(t, "return=minimal", minimal.Header().Get("Preference-Applied"))
+	assert.JSONEq(t, fmt.Sprintf(`{"data":{"id":%d}}`, id+1), minimal.Body.String())
+}
+
+func TestProductHandler_GetAll_Paginated(t *testing.T) {
+	client := webtest.NewClient(t, createServerForTestProducts(""))
+	products := testProducts()
+
+	// Actual responses
+	response := client.Get("https://localhost:8080/api/v1/products?page=2&page_size=10")
+	data, meta := webtest.Envelope[[]domain.Product](response)
+	invalid := client.Get("https://localhost:8080/api/v1/products?page=0")
+
+	// Assertions
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, products[10:20], data)
+	assert.Equal(t, len(products), meta.Total)
+	assert.Equal(t, 2, meta.Page)
+	assert.Equal(t, "/api/v1/products?page=1&page_size=10", meta.Prev)
+	assert.Equal(t, "/api/v1/products?page=3&page_size=10", meta.Next)
+	assert.Equal(t, http.StatusBadRequest, invalid.Code)
+}
+
+func TestProductHandler_GetByPriceGt_Empty(t *testing.T) {
+	client := webtest.NewClient(t, createServerForTestProducts(""))
+
+	// The legacy handler answers 404 instead
+	legacyRouter := gin.New()
+	legacyRouter.GET("/search", NewProductHandler(product.NewService(product.NewRepository(nil), nil)).WithEmptyFilterNotFound(true).GetByPriceGt())
+
+	response := client.Get("https://localhost:8080/api/v1/products/search?priceGt=99999999")
+	legacy := webtest.NewClient(t, legacyRouter).Get("/search?priceGt=1")
+
+	// Assertions
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.JSONEq(t, `{"data":[]}`, response.Body.String())
+	assert.Equal(t, http.StatusNotFound, legacy.Code)
+}
+
+func TestProductHandler_GetByPriceGt_FullText(t *testing.T) {
+	products := []domain.Product{
+		{Id: 1, Name: "Pineapple juice", CodeValue: "J1", Expiration: "15/12/2030", Price: 5},
+		{Id: 2, Name: "Pineapple", CodeValue: "F1", Expiration: "15/12/2030", Price: 20},
+		{Id: 3, Name: "Apple", CodeValue: "F2", Expiration: "15/12/2030", Price: 10},
+	}
+	engine := search.NewMemoryEngine()
+	if err := engine.Rebuild(context.Background(), products); err != nil {
+		panic(err)
+	}
+	// The third product is deleted after being indexed
+	service := product.NewService(product.NewRepository(products[:2]), nil)
+	router := gin.New()
+	router.GET("/search", NewProductHandler(service).WithSearchEngine(engine).GetByPriceGt())
+	router.GET("/unavailable", NewProductHandler(service).GetByPriceGt())
+	client := webtest.NewClient(t, router)
+
+	found := client.Get("/search?q=pineaple")
+	filtered := client.Get("/search?q=pineapple&priceGt=10")
+	deleted := client.Get("/search?q=apple")
+	unavailable := client.Get("/unavailable?q=pineapple")
+
+	// Assertions: most relevant first, and a typo is tolerated
+	ids := func(response *webtest.Response) []int {
+		var result []int
+		for _, p := range webtest.Data[[]domain.Product](response) {
+			result = append(result, p.Id)
+		}
+		return result
+	}
+	assert.Equal(t, http.StatusOK, found.Code)
+	assert.Equal(t, []int{2, 1}, ids(found))
+	assert.Equal(t, []int{2}, ids(filtered))
+	assert.NotContains(t, ids(deleted), 3)
+	assert.Equal(t, http.StatusServiceUnavailable, unavailable.Code)
+	assert.Equal(t, "search_unavailable", unavailable.Error().ErrorCode)
+}
+
+func TestProductHandler_GetAll_Filter(t *testing.T) {
+	client := webtest.NewClient(t, createServerForTestProducts(""))
+	expected := []domain.Product{}
+	for _, p := range testProducts() {
+		if p.Price > 500 && p.Quantity < 400 && p.Published() {
+			expected = append(expected, p)
+		}
+	}
+
+	filtered := client.Get("https://localhost:8080/api/v1/products?filter=" +
+		url.QueryEscape("price > 500 AND quantity < 400 AND is_published = true"))
+	none := client.Get("https://localhost:8080/api/v1/products?filter=" + url.QueryEscape("price < 0"))
+	invalid := client.Get("https://localhost:8080/api/v1/products?filter=" + url.QueryEscape("price > 'cheap'"))
+	exported := client.Get("https://localhost:8080/api/v1/products/export?filter=" + url.QueryEscape("id = 1"))
+
+	// Assertions
+	assert.NotEmpty(t, expected)
+	assert.Equal(t, http.StatusOK, filtered.Code)
+	assert.Equal(t, expected, webtest.Data[[]domain.Product](filtered))
+	assert.Equal(t, http.StatusOK, none.Code)
+	assert.Empty(t, webtest.Data[[]domain.Product](none))
+	assert.Equal(t, http.StatusBadRequest, invalid.Code)
+	assert.Equal(t, "invalid_filter", invalid.Error().ErrorCode)
+	assert.Contains(t, invalid.Error().Message, "position 9")
+	assert.Equal(t, http.StatusOK, exported.Code)
+	assert.Equal(t, 2, strings.Count(exported.Body.String(), "\n"))
+}
+
+func TestProductHandler_GetAll_Status(t *testing.T) {
+	client := webtest.NewClient(t, createServerForTestProducts(""))
+	expected := []domain.Product{}
+	for _, p := range testProducts() {
+		if !p.Published() && p.Price > 500 {
+			expected = append(expected, p)
+		}
+	}
+
+	drafts := client.Get("https://localhost:8080/api/v1/products?status=draft,Archived&filter=" + url.QueryEscape("price > 500"))
+	invalid := client.Get("https://localhost:8080/api/v1/products?status=draft,sold")
+
+	// Assertions
+	assert.NotEmpty(t, expected)
+	assert.Equal(t, http.StatusOK, drafts.Code)
+	assert.Equal(t, expected, webtest.Data[[]domain.Product](drafts))
+	assert.Equal(t, http.StatusBadRequest, invalid.Code)
+	assert.Equal(t, "invalid_status", invalid.Error().ErrorCode)
+}
+
+func TestProductHandler_GetAll_Storage(t *testing.T) {
+	client := webtest.NewClient(t, createServerForTestProducts("12345")).WithToken("12345")
+	patched := client.Patch("https://localhost:8080/api/v1/products/1", `{"storage":"frozen","max_temperature":-18}`)
+	reversed := client.Patch("https://localhost:8080/api/v1/products/3", `{"storage":"chilled","min_temperature":8,"max_temperature":2}`)
+
+	frozen := client.Get("https://localhost:8080/api/v1/products?storage=Chilled,frozen&filter=" + url.QueryEscape("max_temperature <= -15"))
+	invalid := client.Get("https://localhost:8080/api/v1/products?storage=cold")
+
+	// Assertions
+	assert.Equal(t, http.StatusOK, patched.Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, reversed.Code)
+	assert.Equal(t, http.StatusOK, frozen.Code)
+	products := webtest.Data[[]domain.Product](frozen)
+	if assert.Len(t, products, 1) {
+		assert.Equal(t, 1, products[0].Id)
+		assert.Equal(t, domain.StorageFrozen, products[0].Storage)
+	}
+	assert.Equal(t, http.StatusBadRequest, invalid.Code)
+	assert.Equal(t, "invalid_storage", invalid.Error().ErrorCode)
+}
+
+func TestProductHandler_BatchPatch(t *testing.T) {
+	client := webtest.NewClient(t, createServerForTestProducts("12345")).WithToken("12345")
+	scope := "/api/v1/products?filter=" + url.QueryEscape("id <= 3")
+
+	dryRun := client.Patch("https://localhost:8080"+scope+"&dryRun=true", `{"is_published":false}`)
+	unchanged := webtest.Data[domain.Product](client.Get("https://localhost:8080/api/v1/products/1"))
+	applied := client.Patch("https://localhost:8080"+scope, `{"is_published":false}`)
+	changed := webtest.Data[domain.Product](client.Get("https://localhost:8080/api/v1/products/1"))
+	withoutFilter := client.Patch("https://localhost:8080/api/v1/products", `{"is_published":false}`)
+	code := client.Patch("https://localhost:8080/api/v2/products?filter=id%3D1", `{"code":"NEW"}`)
+
+	// Assertions
+	assert.Equal(t, http.StatusOK, dryRun.Code)
+	assert.Equal(t, product.BatchResult{Count: 3, Ids: []int{1, 2, 3}, DryRun: true}, webtest.Data[product.BatchResult](dryRun))
+	assert.Equal(t, testProducts()[0].Status, unchanged.Status)
+	assert.Equal(t, http.StatusOK, applied.Code)
+	assert.Equal(t, 3, webtest.Data[product.BatchResult](applied).Count)
+	assert.False(t, changed.Published())
+	assert.Equal(t, http.StatusBadRequest, withoutFilter.Code)
+	assert.Equal(t, "missing_filter", withoutFilter.Error().ErrorCode)
+	assert.Equal(t, http.StatusUnprocessableEntity, code.Code)
+	assert.Equal(t, "code", code.Error().Fields[0].Field)
+}
+
+func TestProductHandler_DryRun(t *testing.T) {
+	client := webtest.NewClient(t, createServerForTestProducts("12345")).WithToken("12345")
+	before := client.Get("https://localhost:8080/api/v1/products").Body.String()
+	body := `{"name":"Dry","quantity":1,"code_value":"DRY1","expiration":"25/10/2030","price":10}`
+	duplicate := fmt.Sprintf(`{"name":"Dry","quantity":1,"code_value":%q,"expiration":"25/10/2030","price":10}`, testProducts()[1].CodeValue)
+
+	created := client.Post("https://localhost:8080/api/v1/products?dryRun=true", body)
+	preferred := client.WithHeader("Prefer", "handling=dry-run").Post("https://localhost:8080/api/v1/products", body)
+	conflict := client.Post("https://localhost:8080/api/v1/products?dryRun=true", duplicate)
+	updated := client.Put("https://localhost:8080/api/v1/products/1?dryRun=true", body)
+	patched := client.Patch("https://localhost:8080/api/v1/products/1?dryRun=1", `{"price":-1}`)
+	cloned := client.Post("https://localhost:8080/api/v1/products/1/clone?dryRun=true", `{"code_value":"DRY2"}`)
+	deleted := client.Delete("https://localhost:8080/api/v1/products/2?dryRun=true")
+	missing := client.Delete("https://localhost:8080/api/v1/products/999?dryRun=true")
+	invalid := client.Delete("https://localhost:8080/api/v1/products/2?dryRun=maybe")
+	after := client.Get("https://localhost:8080/api/v1/products").Body.String()
+
+	// Assertions: every answer is the real one, but nothing changes
+	assert.Equal(t, http.StatusOK, created.Code)
+	assert.Equal(t, "DRY1", webtest.Data[domain.Product](created).CodeValue)
+	assert.Zero(t, webtest.Data[domain.Product](created).Id)
+	assert.Empty(t, created.Header().Get("Location"))
+	assert.Equal(t, http.StatusOK, preferred.Code)
+	assert.Equal(t, "handling=dry-run", preferred.Header().Get("Preference-Applied"))
+	assert.Equal(t, http.StatusConflict, conflict.Code)
+	assert.Equal(t, http.StatusOK, updated.Code)
+	assert.Equal(t, 1, webtest.Data[domain.Product](updated).Id)
+	assert.Equal(t, http.StatusUnprocessableEntity, patched.Code)
+	assert.Equal(t, http.StatusOK, cloned.Code)
+	assert.Equal(t, http.StatusOK, deleted.Code)
+	assert.Equal(t, testProducts()[1], webtest.Data[domain.Product](deleted))
+	assert.Equal(t, http.StatusNotFound, missing.Code)
+	assert.Equal(t, http.StatusBadRequest, invalid.Code)
+	assert.Equal(t, "invalid_dry_run", invalid.Error().ErrorCode)
+	assert.Equal(t, before, after)
+}
+
+func TestProductHandler_Tenants(t *testing.T) {
+	if err := os.Setenv("TOKEN", "12345"); err != nil {
+		panic(err)
+	}
+	products := testProducts()
+	tenants, err := tenant.NewRegistry([]string{"globex"}, map[string]string{"acme-key": "acme"}, func(id string) (tenant.Catalog, error) {
+		return tenant.Catalog{Service: product.NewService(product.NewRepository(nil), nil)}, nil
+	})
+	if err != nil {
+		panic(err)
+	}
+	router := gin.New()
+	router.Use(middleware.Tenancy(tenants))
+	productHandler := NewProductHandler(product.NewService(product.NewRepository(products), nil)).WithTenants(tenants)
+	router.GET("/products", productHandler.GetAll())
+	router.POST("/products", middleware.TokenValidator(), productHandler.Create())
+	client := webtest.NewClient(t, router)
+	acme := client.WithToken("acme-key")
+	body := `{"name":"Anvil","quantity":1,"code_value":"ACME1","expiration":"25/10/2030","price":10}`
+
+	// Actual responses
+	created := acme.Post("/products", body)
+	acmeList := acme.Get("/products")
+	mainList := client.Get("/products")
+	globexList := client.WithToken("12345").WithHeader(web.HeaderTenant, "globex").Get("/products")
+	untrusted := client.WithHeader(web.HeaderTenant, "globex").Get("/products")
+	crossed := acme.WithHeader(web.HeaderTenant, "globex").Get("/products")
+	unknown := client.WithToken("12345").WithHeader(web.HeaderTenant, "initech").Get("/products")
+
+	// Assertions
+	assert.Equal(t, http.StatusCreated, created.Code)
+	assert.Equal(t, 1, webtest.Data[domain.Product](created).Id)
+	assert.Len(t, webtest.Data[[]domain.Product](acmeList), 1)
+	assert.Equal(t, "ACME1", webtest.Data[[]domain.Product](acmeList)[0].CodeValue)
+	assert.Len(t, webtest.Data[[]domain.Product](mainList), len(products))
+	assert.Empty(t, webtest.Data[[]domain.Product](globexList))
+	assert.Equal(t, http.StatusForbidden, untrusted.Code)
+	assert.Equal(t, "tenant_forbidden", untrusted.Error().ErrorCode)
+	assert.Equal(t, http.StatusForbidden, crossed.Code)
+	assert.Equal(t, http.StatusNotFound, unknown.Code)
+	assert.Equal(t, "tenant_not_found", unknown.Error().ErrorCode)
+}
+
+func TestProductHandler_Currency(t *testing.T) {
+	products := testProducts()
+	converter := currency.NewConverter(currency.NewStaticProvider(domain.BaseCurrency(), map[string]float64{"EUR": 0.5}))
+	router := gin.New()
+	productHandler := NewProductHandler(product.NewService(product.NewRepository(products), nil)).WithCurrencyConverter(converter)
+	router.GET("/products", productHandler.GetAll())
+	router.GET("/products/:id", productHandler.GetById())
+	client := webtest.NewClient(t, router)
+
+	// Actual responses
+	single := client.Get("/products/1?currency=EUR")
+	page := client.Get("/products?currency=eur&page=1&page_size=2")
+	unsupported := client.Get("/products/1?currency=JPY")
+	invalid := client.Get("/products?currency=EURO")
+
+	// Assertions
+	assert.Equal(t, http.StatusOK, single.Code)
+	assert.Equal(t, "EUR", webtest.Data[domain.Product](single).Currency)
+	assert.InDelta(t, products[0].Price/2, webtest.Data[domain.Product](single).Price, 0.01)
+	assert.Equal(t, http.StatusOK, page.Code)
+	assert.Len(t, webtest.Data[[]domain.Product](page), 2)
+	assert.Equal(t, "EUR", webtest.Data[[]domain.Product](page)[1].Currency)
+	assert.Equal(t, http.StatusBadRequest, unsupported.Code)
+	assert.Equal(t, "unsupported_currency", unsupported.Error().ErrorCode)
+	assert.Equal(t, http.StatusBadRequest, invalid.Code)
+	assert.Equal(t, "invalid_currency", invalid.Error().ErrorCode)
+	assert.Equal(t, products[0], webtest.Data[domain.Product](client.Get("/products/1")))
+}
+
+func TestProductHandler_ServiceMock(t *testing.T) {
+	service := mocks.NewService(t)
+	router := gin.New()
+	productHandler := NewProductHandler(service)
+	router.GET("/products/:id", productHandler.GetById())
+	router.PATCH("/products/:id", productHandler.PartialUpdate())
+	client := webtest.NewClient(t, router)
+
+	// Only the fields present in the body reach the service
+	draft := domain.StatusDraft
+	service.On("Patch", 7, domain.ProductRequest{Status: &draft}).Return(domain.Product{Id: 7, Name: "Oil"}, nil).Once()
+	service.On("GetById", 8).Return(domain.Product{}, errors.New("disk failure")).Once()
+
+	patch := client.Patch("/products/7", `{"is_published":false}`)
+	failing := client.Get("/products/8")
+
+	// Assertions
+	assert.Equal(t, http.StatusOK, patch.Code)
+	assert.Equal(t, http.StatusInternalServerError, failing.Code)
+}
+
+func TestProductHandler_GetById_DescriptionFormat(t *testing.T) {
+	products := []domain.Product{
+		{Id: 1, Name: "Milk", CodeValue: "A1", Expiration: "15/12/2030", Price: 10, Description: "*Whole* milk <img src=x onerror=alert(1)>"},
+	}
+	router := gin.New()
+	router.GET("/products/:id", NewProductHandler(product.NewService(product.NewRepository(products), nil)).GetById())
+	client := webtest.NewClient(t, router)
+
+	// Actual responses
+	raw := client.Get("/products/1")
+	rendered := client.Get("/products/1?description_format=html")
+	invalid := client.Get("/products/1?description_format=rtf")
+
+	// Assertions
+	assert.Equal(t, products[0].Description, webtest.Data[domain.Product](raw).Description)
+	assert.Equal(t, "<p><em>Whole</em> milk </p>\n", webtest.Data[domain.Product](rendered).Description)
+	assert.Equal(t, http.StatusBadRequest, invalid.Code)
+	assert.Equal(t, "invalid_description_format", invalid.Error().ErrorCode)
 }
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"errors"
+	"github.com/JoseObreque/go-web/internal/related"
+	"github.com/JoseObreque/go-web/pkg/web"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Number of related products returned by default, and at most.
+const (
+	defaultRelated = 5
+	maxRelated     = 20
+)
+
+var ErrInvalidRelatedLimit = errors.New("invalid related products limit")
+
+// GetRelated godoc
+// @Summary List the related products
+// @Tags Products
+// @Description List the published products related to a product, most similar first, for a "you may also like"
+// @Description section. The products are scored by their shared category, the shared words of their names
+// @Description and a price within 25% in the same currency; a similar price alone does not relate them. The
+// @Description products outside their publish window are left out.
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param limit query int false "Maximum number of products (5 by default, 20 at most)"
+// @Param currency query string false "ISO 4217 code of the currency the prices are converted to"
+// @Param description_format query string false "Format of the descriptions: markdown, as written (default), or html, rendered and sanitized" Enums(markdown, html)
+// @Success 200 {object} web.Response
+// @Failure 400 {object} web.ErrorResponse
+// @Failure 404 {object} web.ErrorResponse
+// @Failure 503 {object} web.ErrorResponse
+// @Router /products/{id}/related [get]
+func (h *ProductHandler) GetRelated() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			web.Failure(c, http.StatusBadRequest, ErrInvalidId)
+			return
+		}
+
+		limit := defaultRelated
+		if stringLimit, ok := c.GetQuery("limit"); ok {
+			if limit, err = strconv.Atoi(stringLimit); err != nil || limit < 1 || limit > maxRelated {
+				web.Failure(c, http.StatusBadRequest, ErrInvalidRelatedLimit)
+				return
+			}
+		}
+
+		service, err := h.catalog(c)
+		if err != nil {
+			web.Error(c, err)
+			return
+		}
+		targetProduct, err := service.GetById(id)
+		if err != nil {
+			web.Error(c, web.WithParams(err, web.Params{"id": id}))
+			return
+		}
+		h.successList(c, related.Find(targetProduct, service.GetAll(), limit, time.Now()))
+	}
+}
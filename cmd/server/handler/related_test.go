@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/JoseObreque/go-web/pkg/webtest"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestProductHandler_GetRelated(t *testing.T) {
+	products := []domain.Product{
+		{Id: 1, Name: "Whole milk", CodeValue: "A1", Category: "dairy", Status: domain.StatusPublished, Expiration: "15/12/2030", Price: 10},
+		{Id: 2, Name: "Skim milk", CodeValue: "B2", Category: "dairy", Status: domain.StatusPublished, Expiration: "15/12/2030", Price: 10},
+		{Id: 3, Name: "Butter", CodeValue: "C3", Category: "dairy", Status: domain.StatusPublished, Expiration: "15/12/2030", Price: 50},
+		{Id: 4, Name: "Bread", CodeValue: "D4", Category: "bakery", Status: domain.StatusPublished, Expiration: "15/12/2030", Price: 10},
+	}
+	router := gin.New()
+	router.GET("/products/:id/related", NewProductHandler(product.NewService(product.NewRepository(products), nil)).GetRelated())
+	client := webtest.NewClient(t, router)
+
+	// Actual responses
+	response := client.Get("/products/1/related")
+	limited := client.Get("/products/1/related?limit=1")
+	invalidLimit := client.Get("/products/1/related?limit=100")
+	missing := client.Get("/products/99/related")
+
+	// Assertions
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, []domain.Product{products[1], products[2]}, webtest.Data[[]domain.Product](response))
+	assert.Equal(t, []domain.Product{products[1]}, webtest.Data[[]domain.Product](limited))
+	assert.Equal(t, http.StatusBadRequest, invalidLimit.Code)
+	assert.Equal(t, "invalid_related_limit", invalidLimit.Error().ErrorCode)
+	assert.Equal(t, http.StatusNotFound, missing.Code)
+}
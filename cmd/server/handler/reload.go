@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/JoseObreque/go-web/pkg/web"
+	"github.com/gin-gonic/gin"
+)
+
+// The ReloadResult struct describes the outcome of a reload of the products.
+type ReloadResult struct {
+	Reloaded bool `json:"reloaded"`
+	Count    int  `json:"count"`
+}
+
+// ReloadHandler is a handler for the endpoint that reloads the products from the store file.
+type ReloadHandler struct {
+	service product.Service
+	reload  func() (bool, error)
+}
+
+/*
+The NewReloadHandler function returns a new ReloadHandler that uses the given reload function, which
+returns whether the products of the service changed.
+*/
+func NewReloadHandler(service product.Service, reload func() (bool, error)) *ReloadHandler {
+	return &ReloadHandler{
+		service: service,
+		reload:  reload,
+	}
+}
+
+// Reload godoc
+// @Summary Reload the products
+// @Tags Tasks
+// @Description Reload the products from the store file, keeping the changes not yet saved in it. Nothing changes if the
+// @Description file holds invalid products, and reloaded is false if it holds the current products.
+// @Produce json
+// @Param token header string true "Token"
+// @Success 200 {object} web.Response
+// @Failure 401 {object} web.ErrorResponse
+// @Failure 422 {object} web.ErrorResponse
+// @Failure 500 {object} web.ErrorResponse
+// @Router /admin/reload [post]
+func (h *ReloadHandler) Reload() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reloaded, err := h.reload()
+		if err != nil {
+			web.Error(c, err)
+			return
+		}
+		web.Success(c, 200, ReloadResult{
+			Reloaded: reloaded,
+			Count:    len(h.service.GetAll()),
+		})
+	}
+}
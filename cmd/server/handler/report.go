@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"errors"
+	"github.com/JoseObreque/go-web/internal/analytics"
+	"github.com/JoseObreque/go-web/internal/dedupe"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/popularity"
+	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/JoseObreque/go-web/pkg/web"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Number of products, queries or pairs of the reports by default, and at most, and longest period of the reports in days.
+const (
+	defaultReportLimit = 10
+	maxReportLimit     = 100
+	maxReportDays      = 365
+)
+
+var (
+	ErrInvalidReportLimit     = errors.New("invalid report limit")
+	ErrInvalidReportPeriod    = errors.New("invalid report period")
+	ErrInvalidReportThreshold = errors.New("invalid report threshold")
+)
+
+// The TopProduct struct is a product along with its number of views.
+type TopProduct struct {
+	domain.Product
+	Views int `json:"views" example:"42"`
+}
+
+// ReportHandler is a handler for the reports endpoints.
+type ReportHandler struct {
+	products product.Service
+	views    *popularity.Counter
+	// Log of the searches of the customers, nil if they are not recorded
+	searches *analytics.SearchLog
+}
+
+// The NewReportHandler function returns a new ReportHandler reporting the views of the given counter.
+func NewReportHandler(products product.Service, views *popularity.Counter) *ReportHandler {
+	return &ReportHandler{
+		products: products,
+		views:    views,
+	}
+}
+
+// The WithSearchLog method makes the handler report the searches recorded in the given log.
+func (h *ReportHandler) WithSearchLog(searches *analytics.SearchLog) *ReportHandler {
+	h.searches = searches
+	return h
+}
+
+// TopProducts godoc
+// @Summary List the most viewed products
+// @Tags Reports
+// @Description List the most viewed products of the main catalog, most viewed first, with their number of views.
+// @Description The views of the clients sending "DNT: 1" or "Sec-GPC: 1" are not counted, and only the number of
+// @Description views of every product is kept. The deleted products are left out.
+// @Produce json
+// @Param token header string true "Token"
+// @Param limit query int false "Maximum number of products (10 by default, 100 at most)"
+// @Success 200 {object} web.Response{data=[]TopProduct}
+// @Failure 400 {object} web.ErrorResponse
+// @Failure 401 {object} web.ErrorResponse
+// @Router /reports/top-products [get]
+func (h *ReportHandler) TopProducts() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit, err := reportLimit(c)
+		if err != nil {
+			web.Failure(c, http.StatusBadRequest, err)
+			return
+		}
+
+		top := []TopProduct{}
+		for _, count := range h.views.Top(-1) {
+			if len(top) == limit {
+				break
+			}
+			viewed, err := h.products.GetById(count.ProductId)
+			if err != nil {
+				continue
+			}
+			top = append(top, TopProduct{Product: viewed, Views: count.Views})
+		}
+		web.Success(c, http.StatusOK, top)
+	}
+}
+
+// Searches godoc
+// @Summary Summarize the searches
+// @Tags Reports
+// @Description Summarize the text searches of the customers: the most searched queries and the queries whose searches
+// @Description found no product, which merchandising should look into. Queries differing only in case, accents or
+// @Description spacing are the same. Only the most recent searches are kept, in memory, and nothing about the
+// @Description customers; the searches of the clients sending "DNT: 1" or "Sec-GPC: 1" are not recorded.
+// @Produce json
+// @Param token header string true "Token"
+// @Param limit query int false "Maximum number of queries of each list (10 by default, 100 at most)"
+// @Param days query int false "Only the searches of the last days (1 to 365); every search kept by default"
+// @Success 200 {object} web.Response{data=analytics.Summary}
+// @Failure 400 {object} web.ErrorResponse
+// @Failure 401 {object} web.ErrorResponse
+// @Router /reports/searches [get]
+func (h *ReportHandler) Searches() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit, err := reportLimit(c)
+		if err != nil {
+			web.Failure(c, http.StatusBadRequest, err)
+			return
+		}
+		var since time.Time
+		if stringDays, ok := c.GetQuery("days"); ok {
+			days, err := strconv.Atoi(stringDays)
+			if err != nil || days < 1 || days > maxReportDays {
+				web.Failure(c, http.StatusBadRequest, ErrInvalidReportPeriod)
+				return
+			}
+			since = time.Now().AddDate(0, 0, -days)
+		}
+
+		web.Success(c, http.StatusOK, h.searches.Summarize(since, limit))
+	}
+}
+
+// PossibleDuplicates godoc
+// @Summary List the possible duplicate products
+// @Tags Reports
+// @Description List the pairs of products of the main catalog that are likely the same SKU entered twice: products
+// @Description with the same price and currency and similar names, like names with a typo or with the words in another
+// @Description order. The most similar pairs come first, and the product of every pair is the oldest one, usually the
+// @Description one to keep when merging them.
+// @Produce json
+// @Param token header string true "Token"
+// @Param limit query int false "Maximum number of pairs (10 by default, 100 at most)"
+// @Param threshold query number false "Minimum similarity of the names, above 0 and up to 1 (0.8 by default)"
+// @Success 200 {object} web.Response{data=[]dedupe.Pair}
+// @Failure 400 {object} web.ErrorResponse
+// @Failure 401 {object} web.ErrorResponse
+// @Router /reports/possible-duplicates [get]
+func (h *ReportHandler) PossibleDuplicates() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit, err := reportLimit(c)
+		if err != nil {
+			web.Failure(c, http.StatusBadRequest, err)
+			return
+		}
+		threshold := dedupe.DefaultThreshold
+		if stringThreshold, ok := c.GetQuery("threshold"); ok {
+			threshold, err = strconv.ParseFloat(stringThreshold, 64)
+			if err != nil || threshold <= 0 || threshold > 1 {
+				web.Failure(c, http.StatusBadRequest, ErrInvalidReportThreshold)
+				return
+			}
+		}
+
+		pairs := dedupe.Find(h.products.GetAll(), threshold)
+		if len(pairs) > limit {
+			pairs = pairs[:limit]
+		}
+		web.Success(c, http.StatusOK, pairs)
+	}
+}
+
+// Auxiliary function that returns the limit query parameter of a report, or its default.
+func reportLimit(c *gin.Context) (int, error) {
+	stringLimit, ok := c.GetQuery("limit")
+	if !ok {
+		return defaultReportLimit, nil
+	}
+	limit, err := strconv.Atoi(stringLimit)
+	if err != nil || limit < 1 || limit > maxReportLimit {
+		return 0, ErrInvalidReportLimit
+	}
+	return limit, nil
+}
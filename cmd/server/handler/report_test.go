@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"github.com/JoseObreque/go-web/internal/analytics"
+	"github.com/JoseObreque/go-web/internal/dedupe"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/popularity"
+	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/JoseObreque/go-web/pkg/webtest"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestReportHandler_TopProducts(t *testing.T) {
+	service := product.NewService(product.NewRepository(testProducts()), nil)
+	views, err := popularity.NewCounter("", 16)
+	if err != nil {
+		panic(err)
+	}
+	router := gin.New()
+	router.GET("/reports/top-products", NewReportHandler(service, views).TopProducts())
+	client := webtest.NewClient(t, router)
+
+	// The views of a deleted product are left out
+	for _, id := range []int{2, 1, 2, 999} {
+		views.View(id)
+	}
+	response := client.Get("/reports/top-products")
+	limited := webtest.Data[[]domain.Product](client.Get("/reports/top-products?limit=1"))
+	invalidLimit := client.Get("/reports/top-products?limit=0")
+
+	// Assertions
+	// The products decode themselves, so their views are decoded apart
+	top := webtest.Data[[]domain.Product](response)
+	counts := webtest.Data[[]struct{ Views int }](response)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Len(t, top, 2)
+	assert.Equal(t, testProducts()[1], top[0])
+	assert.Equal(t, 2, counts[0].Views)
+	assert.Equal(t, 1, counts[1].Views)
+	assert.Len(t, limited, 1)
+	assert.Equal(t, http.StatusBadRequest, invalidLimit.Code)
+	assert.Equal(t, "invalid_report_limit", invalidLimit.Error().ErrorCode)
+}
+
+func TestReportHandler_Searches(t *testing.T) {
+	service := product.NewService(product.NewRepository(testProducts()), nil)
+	searches := analytics.NewSearchLog(10)
+	router := gin.New()
+	router.GET("/reports/searches", NewReportHandler(service, nil).WithSearchLog(searches).Searches())
+	client := webtest.NewClient(t, router)
+
+	searches.Record(analytics.Search{Term: "milk", Results: 2})
+	searches.Record(analytics.Search{Term: "kombucha", Results: 0})
+	response := client.Get("/reports/searches?days=7")
+	invalidDays := client.Get("/reports/searches?days=0")
+
+	// Assertions
+	summary := webtest.Data[analytics.Summary](response)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, 2, summary.Searches)
+	assert.Equal(t, "kombucha", summary.ZeroResultQueries[0].Term)
+	assert.Equal(t, http.StatusBadRequest, invalidDays.Code)
+	assert.Equal(t, "invalid_report_period", invalidDays.Error().ErrorCode)
+}
+
+func TestReportHandler_PossibleDuplicates(t *testing.T) {
+	products := testProducts()
+	duplicate := products[0]
+	duplicate.Id, duplicate.CodeValue = 0, "DUP1"
+	service := product.NewService(product.NewRepository(products), nil)
+	created, err := service.Create(duplicate)
+	if err != nil {
+		panic(err)
+	}
+	router := gin.New()
+	router.GET("/reports/possible-duplicates", NewReportHandler(service, nil).PossibleDuplicates())
+	client := webtest.NewClient(t, router)
+
+	response := client.Get("/reports/possible-duplicates?threshold=1")
+	invalidThreshold := client.Get("/reports/possible-duplicates?threshold=0")
+
+	// Assertions
+	pairs := webtest.Data[[]dedupe.Pair](response)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Len(t, pairs, 1)
+	assert.Equal(t, products[0].Id, pairs[0].Product.Id)
+	assert.Equal(t, created.Id, pairs[0].Duplicate.Id)
+	assert.Equal(t, 1.0, pairs[0].Similarity)
+	assert.Equal(t, http.StatusBadRequest, invalidThreshold.Code)
+	assert.Equal(t, "invalid_report_threshold", invalidThreshold.Error().ErrorCode)
+}
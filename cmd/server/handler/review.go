@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"errors"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/review"
+	"github.com/JoseObreque/go-web/pkg/web"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+var (
+	ErrInvalidReviewId     = errors.New("invalid review id")
+	ErrInvalidReviewData   = errors.New("invalid review data")
+	ErrInvalidReviewFilter = errors.New("invalid review filter")
+)
+
+// ReviewHandler is a handler for the product reviews endpoints.
+type ReviewHandler struct {
+	service review.Service
+}
+
+// The NewReviewHandler function returns a new ReviewHandler that uses the provided service.
+func NewReviewHandler(service review.Service) *ReviewHandler {
+	return &ReviewHandler{
+		service: service,
+	}
+}
+
+// GetAll godoc
+// @Summary List the reviews of a product
+// @Tags Reviews
+// @Description List the reviews of a product, newest first. The reviews hidden by the administrators are left out.
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param page query int false "Page number, starting at 1"
+// @Param page_size query int false "Number of reviews per page"
+// @Success 200 {object} web.Response{data=[]domain.Review}
+// @Failure 400 {object} web.ErrorResponse
+// @Failure 404 {object} web.ErrorResponse
+// @Router /products/{id}/reviews [get]
+func (h *ReviewHandler) GetAll() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		productId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			web.Failure(c, http.StatusBadRequest, ErrInvalidId)
+			return
+		}
+		page, paginated, err := web.ParsePage(c)
+		if err != nil {
+			web.Error(c, err)
+			return
+		}
+
+		reviews, err := h.service.List(productId)
+		if err != nil {
+			web.Error(c, web.WithParams(err, web.Params{"id": productId}))
+			return
+		}
+		if !paginated {
+			web.Success(c, http.StatusOK, reviews)
+			return
+		}
+		start, end := page.Bounds(len(reviews))
+		web.Success(c, http.StatusOK, reviews[start:end], web.WithPagination(page, len(reviews)))
+	}
+}
+
+// Create godoc
+// @Summary Review a product
+// @Tags Reviews
+// @Description Post the review of a product, with a rating from 1 to 5. The review counts in the rating of the
+// @Description product at once, unless an administrator hides it.
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param review body domain.ReviewRequest true "review"
+// @Success 201 {object} web.Response{data=domain.Review}
+// @Failure 400 {object} web.ErrorResponse
+// @Failure 404 {object} web.ErrorResponse
+// @Failure 422 {object} web.ErrorResponse
+// @Router /products/{id}/reviews [post]
+func (h *ReviewHandler) Create() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		productId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			web.Failure(c, http.StatusBadRequest, ErrInvalidId)
+			return
+		}
+		var request domain.ReviewRequest
+		if err = c.ShouldBindJSON(&request); err != nil {
+			web.Failure(c, http.StatusBadRequest, ErrInvalidReviewData)
+			return
+		}
+
+		created, err := h.service.Post(productId, request)
+		if err != nil {
+			web.Error(c, withInvalidFields(web.WithParams(err, web.Params{"id": productId}), V1.FieldName))
+			return
+		}
+		web.Created(c, created.Public())
+	}
+}
+
+// Search godoc
+// @Summary List the reviews to moderate
+// @Tags Reviews
+// @Description List every review, hidden ones included and with their moderation fields, newest first.
+// @Produce json
+// @Param token header string true "Token"
+// @Param product_id query int false "Only the reviews of this product"
+// @Param flagged query bool false "Only the flagged (true) or unflagged (false) reviews"
+// @Param hidden query bool false "Only the hidden (true) or visible (false) reviews"
+// @Success 200 {object} web.Response{data=[]domain.Review}
+// @Failure 400 {object} web.ErrorResponse
+// @Failure 401 {object} web.ErrorResponse
+// @Router /admin/reviews [get]
+func (h *ReviewHandler) Search() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var filter review.Filter
+		if stringId, ok := c.GetQuery("product_id"); ok {
+			id, err := strconv.Atoi(stringId)
+			if err != nil {
+				web.Failure(c, http.StatusBadRequest, ErrInvalidReviewFilter)
+				return
+			}
+			filter.ProductId = id
+		}
+		for name, target := range map[string]**bool{"flagged": &filter.Flagged, "hidden": &filter.Hidden} {
+			stringValue, ok := c.GetQuery(name)
+			if !ok {
+				continue
+			}
+			value, err := strconv.ParseBool(stringValue)
+			if err != nil {
+				web.Failure(c, http.StatusBadRequest, ErrInvalidReviewFilter)
+				return
+			}
+			*target = &value
+		}
+
+		web.Success(c, http.StatusOK, h.service.Search(filter))
+	}
+}
+
+// Moderate godoc
+// @Summary Moderate a review
+// @Tags Reviews
+// @Description Flag a review for a closer look, hide it or show it again, and note why. A hidden review is left out
+// @Description of the reviews listed to the customers and of the rating of its product.
+// @Accept json
+// @Produce json
+// @Param token header string true "Token"
+// @Param id path int true "Review ID"
+// @Param moderation body domain.ReviewModeration true "moderation fields to change"
+// @Success 200 {object} web.Response{data=domain.Review}
+// @Failure 400 {object} web.ErrorResponse
+// @Failure 401 {object} web.ErrorResponse
+// @Failure 404 {object} web.ErrorResponse
+// @Router /admin/reviews/{id} [patch]
+func (h *ReviewHandler) Moderate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			web.Failure(c, http.StatusBadRequest, ErrInvalidReviewId)
+			return
+		}
+		var moderation domain.ReviewModeration
+		if err = c.ShouldBindJSON(&moderation); err != nil {
+			web.Failure(c, http.StatusBadRequest, ErrInvalidReviewData)
+			return
+		}
+
+		moderated, err := h.service.Moderate(id, moderation)
+		if err != nil {
+			web.Error(c, web.WithParams(err, web.Params{"id": id}))
+			return
+		}
+		web.Success(c, http.StatusOK, moderated)
+	}
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/JoseObreque/go-web/internal/review"
+	"github.com/JoseObreque/go-web/pkg/webtest"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestReviewHandler(t *testing.T) {
+	service := product.NewService(product.NewRepository(testProducts()), nil)
+	repository, _ := review.NewRepository("")
+	reviews := review.NewService(repository, service)
+
+	router := gin.New()
+	productHandler := NewProductHandler(service).WithReviews(reviews)
+	reviewHandler := NewReviewHandler(reviews)
+	router.GET("/products", productHandler.GetAll())
+	router.GET("/products/:id", productHandler.GetById())
+	router.GET("/products/:id/reviews", reviewHandler.GetAll())
+	router.POST("/products/:id/reviews", reviewHandler.Create())
+	router.GET("/admin/reviews", reviewHandler.Search())
+	router.PATCH("/admin/reviews/:id", reviewHandler.Moderate())
+	client := webtest.NewClient(t, router)
+
+	// Actual responses
+	created := client.Post("/products/1/reviews", domain.ReviewRequest{Rating: 5, Comment: "Great", Author: "Jane"})
+	client.Post("/products/1/reviews", domain.ReviewRequest{Rating: 2, Author: "John"})
+	invalid := client.Post("/products/1/reviews", domain.ReviewRequest{Rating: 9, Author: "Jane"})
+	missing := client.Post("/products/999/reviews", domain.ReviewRequest{Rating: 5, Author: "Jane"})
+	rated := webtest.Data[domain.Product](client.Get("/products/1"))
+	unrated := webtest.Data[domain.Product](client.Get("/products/2"))
+	listed := webtest.Data[[]domain.Product](client.Get("/products"))
+
+	flagged := true
+	moderated := client.Patch(fmt.Sprintf("/admin/reviews/%d", webtest.Data[domain.Review](created).Id),
+		domain.ReviewModeration{Flagged: &flagged, Hidden: &flagged})
+	moderatedMissing := client.Patch("/admin/reviews/999", domain.ReviewModeration{Hidden: &flagged})
+	flaggedReviews := webtest.Data[[]domain.Review](client.Get("/admin/reviews?flagged=true"))
+	invalidFilter := client.Get("/admin/reviews?hidden=maybe")
+	visible := webtest.Data[[]domain.Review](client.Get("/products/1/reviews"))
+	rerated := webtest.Data[domain.Product](client.Get("/products/1"))
+
+	// Assertions
+	assert.Equal(t, http.StatusCreated, created.Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, invalid.Code)
+	assert.Equal(t, "rating", invalid.Error().Fields[0].Field)
+	assert.Equal(t, http.StatusNotFound, missing.Code)
+	assert.Equal(t, &domain.Rating{Average: 3.5, Count: 2}, rated.Rating)
+	assert.Nil(t, unrated.Rating)
+	assert.Equal(t, rated.Rating, listed[0].Rating)
+	assert.Equal(t, http.StatusOK, moderated.Code)
+	assert.Equal(t, http.StatusNotFound, moderatedMissing.Code)
+	assert.Equal(t, "review_not_found", moderatedMissing.Error().ErrorCode)
+	assert.Len(t, flaggedReviews, 1)
+	assert.Equal(t, "Jane", flaggedReviews[0].Author)
+	assert.Equal(t, http.StatusBadRequest, invalidFilter.Code)
+	assert.Len(t, visible, 1)
+	assert.Equal(t, "John", visible[0].Author)
+	assert.Equal(t, &domain.Rating{Average: 2, Count: 1}, rerated.Rating)
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"errors"
+	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/JoseObreque/go-web/internal/seed"
+	"github.com/JoseObreque/go-web/pkg/web"
+	"github.com/gin-gonic/gin"
+	"strconv"
+	"time"
+)
+
+// Number of products generated when no count is given, and the most generated by a single request.
+const (
+	defaultSeedCount = 100
+	maxSeedCount     = 10000
+)
+
+var (
+	ErrInvalidSeedCount = errors.New("count must be between 1 and 10000")
+	ErrInvalidSeed      = errors.New("seed must be an integer")
+)
+
+// The SeedResult struct describes the fake products generated by a seed request.
+type SeedResult struct {
+	Seed    int64 `json:"seed"`
+	Created int   `json:"created"`
+}
+
+// SeedHandler is a handler for the development endpoint that generates fake products.
+type SeedHandler struct {
+	service product.Service
+}
+
+// The NewSeedHandler function returns a new SeedHandler that uses the provided service.
+func NewSeedHandler(service product.Service) *SeedHandler {
+	return &SeedHandler{
+		service: service,
+	}
+}
+
+// Seed godoc
+// @Summary Generate fake products
+// @Tags Development
+// @Description Create the given number of realistic fake products, for load testing and local development. The same seed
+// @Description generates the same products on the same day; a random seed is used if none is given, and returned.
+// @Description Only available in development mode.
+// @Produce json
+// @Param token header string true "Token"
+// @Param count query int false "Number of products, 100 by default and 10000 at most"
+// @Param seed query int false "Seed of the generator"
+// @Success 201 {object} web.Response
+// @Failure 400 {object} web.ErrorResponse
+// @Failure 401 {object} web.ErrorResponse
+// @Router /admin/seed [post]
+func (h *SeedHandler) Seed() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		count := defaultSeedCount
+		if raw := c.Query("count"); raw != "" {
+			var err error
+			count, err = strconv.Atoi(raw)
+			if err != nil || count < 1 || count > maxSeedCount {
+				web.Error(c, ErrInvalidSeedCount)
+				return
+			}
+		}
+		seedValue := time.Now().UnixNano()
+		if raw := c.Query("seed"); raw != "" {
+			var err error
+			if seedValue, err = strconv.ParseInt(raw, 10, 64); err != nil {
+				web.Error(c, ErrInvalidSeed)
+				return
+			}
+		}
+
+		// Code values already taken by other products are skipped
+		today := time.Now().Truncate(24 * time.Hour)
+		generator := seed.NewGenerator(seedValue, today)
+		created := 0
+		for _, fake := range generator.Products(count) {
+			_, err := h.service.Create(fake)
+			if errors.Is(err, product.ErrInvalidCode) {
+				continue
+			}
+			if err != nil {
+				web.Error(c, err)
+				return
+			}
+			created++
+		}
+
+		web.Created(c, SeedResult{
+			Seed:    seedValue,
+			Created: created,
+		})
+	}
+}
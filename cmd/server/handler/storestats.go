@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/JoseObreque/go-web/pkg/adapter"
+	"github.com/JoseObreque/go-web/pkg/cache"
+	"github.com/JoseObreque/go-web/pkg/store"
+	"net/http"
+)
+
+/*
+The StoreStats struct describes the health of the data layer.
+
+	Records (int): Number of products served.
+	File (store.FileStats): Size and last write of the store file and its journal, if there is one.
+	Indexes (map[string]int): Number of entries of every index, like the suggestions.
+	Caches (map[string]cache.Stats): Hits, misses and hit ratio of every cache, like the responses one.
+*/
+type StoreStats struct {
+	Records int                    `json:"records"`
+	File    *store.FileStats       `json:"file,omitempty"`
+	Indexes map[string]int         `json:"indexes"`
+	Caches  map[string]cache.Stats `json:"caches"`
+}
+
+// StoreStatsHandler is a handler for the endpoint reporting the health of the data layer.
+type StoreStatsHandler struct {
+	service product.Service
+	file    func() (store.FileStats, error)
+	indexes map[string]func() int
+	caches  map[string]func() cache.Stats
+}
+
+/*
+The NewStoreStatsHandler function returns a new StoreStatsHandler reporting the products of the
+given service and the stats of the store file returned by file, which can be nil if there is none.
+*/
+func NewStoreStatsHandler(service product.Service, file func() (store.FileStats, error)) *StoreStatsHandler {
+	return &StoreStatsHandler{
+		service: service,
+		file:    file,
+		indexes: make(map[string]func() int),
+		caches:  make(map[string]func() cache.Stats),
+	}
+}
+
+// The WithIndex method makes the stats include the size of an index, returned by size.
+func (h *StoreStatsHandler) WithIndex(name string, size func() int) *StoreStatsHandler {
+	h.indexes[name] = size
+	return h
+}
+
+// The WithCache method makes the stats include the hits and misses of a cache, returned by stats.
+func (h *StoreStatsHandler) WithCache(name string, stats func() cache.Stats) *StoreStatsHandler {
+	h.caches[name] = stats
+	return h
+}
+
+// Stats godoc
+// @Summary Get the store statistics
+// @Tags Tasks
+// @Description Report the health of the data layer: the number of products, the size and last write of the store file
+// @Description and its journal, the number of entries of the indexes, and the hit ratio of the caches since the start.
+// @Produce json
+// @Param token header string true "Token"
+// @Success 200 {object} web.Response{data=StoreStats}
+// @Failure 401 {object} web.ErrorResponse
+// @Failure 500 {object} web.ErrorResponse
+// @Router /admin/store/stats [get]
+func (h *StoreStatsHandler) Stats() adapter.HandlerFunc {
+	return func(c adapter.Context) {
+		stats := StoreStats{
+			Records: len(h.service.GetAll()),
+			Indexes: make(map[string]int, len(h.indexes)),
+			Caches:  make(map[string]cache.Stats, len(h.caches)),
+		}
+		if h.file != nil {
+			file, err := h.file()
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			stats.File = &file
+		}
+		for name, size := range h.indexes {
+			stats.Indexes[name] = size()
+		}
+		for name, cacheStats := range h.caches {
+			stats.Caches[name] = cacheStats()
+		}
+		c.Success(http.StatusOK, stats)
+	}
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/JoseObreque/go-web/internal/suggest"
+	"github.com/JoseObreque/go-web/pkg/adapter"
+	"github.com/JoseObreque/go-web/pkg/cache"
+	"github.com/JoseObreque/go-web/pkg/store"
+	"github.com/JoseObreque/go-web/pkg/webtest"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"path/filepath"
+	"testing"
+)
+
+func TestStoreStatsHandler(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "products.json")
+	journal, err := store.OpenJournal(path + ".journal")
+	if err != nil {
+		panic(err)
+	}
+	defer journal.Close()
+	if err = store.NewJsonStore(path).Save(testProducts()); err != nil {
+		panic(err)
+	}
+	_ = journal.Append(store.Entry{Op: store.OpDelete, Id: 1})
+
+	counter := &cache.Counter{}
+	counter.Hit()
+	counter.Hit()
+	counter.Hit()
+	counter.Miss()
+	storeStatsHandler := NewStoreStatsHandler(product.NewService(product.NewRepository(testProducts()), nil), func() (store.FileStats, error) {
+		return journal.FileStats(path)
+	}).
+		WithIndex("suggestions", suggest.NewIndex(testProducts()).Size).
+		WithCache("responses", counter.Stats)
+	router := gin.New()
+	router.GET("/admin/store/stats", adapter.Gin(storeStatsHandler.Stats()))
+
+	response := webtest.NewClient(t, router).Get("/admin/store/stats")
+	stats := webtest.Data[StoreStats](response)
+
+	// Assertions
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, len(testProducts()), stats.Records)
+	assert.Equal(t, path, stats.File.Path)
+	assert.Positive(t, stats.File.Bytes)
+	assert.Equal(t, 1, stats.File.JournalEntries)
+	assert.NotNil(t, stats.File.LastWrite)
+	assert.Positive(t, stats.Indexes["suggestions"])
+	assert.Equal(t, cache.Stats{Hits: 3, Misses: 1, HitRatio: 0.75}, stats.Caches["responses"])
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"errors"
+	"github.com/JoseObreque/go-web/internal/suggest"
+	"github.com/JoseObreque/go-web/pkg/web"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Number of suggestions returned by default, and at most.
+const (
+	defaultSuggestions = 10
+	maxSuggestions     = 50
+)
+
+var (
+	ErrInvalidQuery = errors.New("invalid suggestion query")
+	ErrInvalidLimit = errors.New("invalid suggestion limit")
+)
+
+// SuggestHandler is a handler for the product name suggestions of the storefront search box.
+type SuggestHandler struct {
+	index *suggest.Index
+}
+
+// The NewSuggestHandler function returns a new SuggestHandler that suggests the names of the given index.
+func NewSuggestHandler(index *suggest.Index) *SuggestHandler {
+	return &SuggestHandler{
+		index: index,
+	}
+}
+
+// Suggest godoc
+// @Summary Suggest product names
+// @Tags Products
+// @Description Suggest the names of the published products with a word starting with the typed text, ignoring case
+// @Description and accents. The names starting with the text come first, then the most viewed ones.
+// @Produce json
+// @Param q query string true "Typed text"
+// @Param limit query int false "Maximum number of suggestions (10 by default, 50 at most)"
+// @Success 200 {object} web.Response{data=[]suggest.Suggestion}
+// @Failure 400 {object} web.ErrorResponse
+// @Router /products/suggest [get]
+func (h *SuggestHandler) Suggest() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := strings.TrimSpace(c.Query("q"))
+		if query == "" {
+			web.Failure(c, http.StatusBadRequest, ErrInvalidQuery)
+			return
+		}
+
+		limit := defaultSuggestions
+		if stringLimit, ok := c.GetQuery("limit"); ok {
+			var err error
+			if limit, err = strconv.Atoi(stringLimit); err != nil || limit < 1 || limit > maxSuggestions {
+				web.Failure(c, http.StatusBadRequest, ErrInvalidLimit)
+				return
+			}
+		}
+
+		web.Success(c, http.StatusOK, h.index.Suggest(query, limit))
+	}
+}
@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/JoseObreque/go-web/internal/suggest"
+	"github.com/JoseObreque/go-web/pkg/webtest"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestSuggestHandler(t *testing.T) {
+	products := []domain.Product{
+		{Id: 1, Name: "Milk", CodeValue: "A1", Status: domain.StatusPublished, Expiration: "15/12/2030", Price: 10},
+		{Id: 2, Name: "Soy milk", CodeValue: "B2", Status: domain.StatusPublished, Expiration: "15/12/2030", Price: 20},
+	}
+	index := suggest.NewIndex(products)
+	router := gin.New()
+	router.GET("/products/suggest", NewSuggestHandler(index).Suggest())
+	router.GET("/products/:id", NewProductHandler(product.NewService(product.NewRepository(products), nil)).WithViewRecorder(index).GetById())
+	client := webtest.NewClient(t, router)
+
+	// Viewing the second product makes it the most popular after the prefix matches
+	client.Get("/products/2")
+	response := client.Get("/products/suggest?q=mil&limit=2")
+	missing := client.Get("/products/suggest")
+	invalidLimit := client.Get("/products/suggest?q=mil&limit=500")
+
+	// Assertions
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, []suggest.Suggestion{
+		{Name: "Milk", Products: 1},
+		{Name: "Soy milk", Products: 1, Views: 1},
+	}, webtest.Data[[]suggest.Suggestion](response))
+	assert.Equal(t, http.StatusBadRequest, missing.Code)
+	assert.Equal(t, "invalid_query", missing.Error().ErrorCode)
+	assert.Equal(t, http.StatusBadRequest, invalidLimit.Code)
+}
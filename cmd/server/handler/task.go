@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/JoseObreque/go-web/pkg/web"
+	"github.com/gin-gonic/gin"
+	"time"
+)
+
+// TaskHandler is a handler for the endpoints that trigger maintenance tasks on demand.
+type TaskHandler struct {
+	service product.Service
+}
+
+// The NewTaskHandler function returns a new TaskHandler that uses the provided service.
+func NewTaskHandler(service product.Service) *TaskHandler {
+	return &TaskHandler{
+		service: service,
+	}
+}
+
+// UnpublishExpired godoc
+// @Summary Unpublish expired products
+// @Tags Tasks
+// @Description Unpublish every published product whose expiration date has passed, without waiting for the nightly job. Returns the unpublished products.
+// @Produce json
+// @Param token header string true "Token"
+// @Success 200 {object} web.Response
+// @Failure 401 {object} web.ErrorResponse
+// @Failure 500 {object} web.ErrorResponse
+// @Router /admin/tasks/unpublish-expired [post]
+func (h *TaskHandler) UnpublishExpired() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		unpublished, err := h.service.UnpublishExpired()
+		if err != nil {
+			web.Failure(c, 500, err)
+			return
+		}
+		web.Success(c, 200, unpublished)
+	}
+}
+
+// ApplyPublishWindows godoc
+// @Summary Apply the publish windows
+// @Tags Tasks
+// @Description Publish the drafts whose publish window opened and archive the published products whose publish window closed, without waiting for the background job. Returns the changed products.
+// @Produce json
+// @Param token header string true "Token"
+// @Success 200 {object} web.Response
+// @Failure 401 {object} web.ErrorResponse
+// @Failure 500 {object} web.ErrorResponse
+// @Router /admin/tasks/apply-publish-windows [post]
+func (h *TaskHandler) ApplyPublishWindows() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scheduled, err := h.service.ApplyPublishWindows(time.Now())
+		if err != nil {
+			web.Failure(c, 500, err)
+			return
+		}
+		web.Success(c, 200, scheduled)
+	}
+}
@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/JoseObreque/go-web/pkg/events"
+	"github.com/JoseObreque/go-web/pkg/webtest"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestTaskHandler_UnpublishExpired(t *testing.T) {
+	// Create a service with an expired, a valid and an already unpublished expired product
+	bus := events.NewBus()
+	subscription, unsubscribe := bus.Subscribe(product.EventExpired)
+	defer unsubscribe()
+	repository := product.NewRepository([]domain.Product{
+		{Id: 1, Name: "Milk", Quantity: 10, CodeValue: "A1", Status: domain.StatusPublished, Expiration: "15/12/2020", Price: 10},
+		{Id: 2, Name: "Oil", Quantity: 10, CodeValue: "B2", Status: domain.StatusPublished, Expiration: "15/12/2030", Price: 20},
+		{Id: 3, Name: "Rice", Quantity: 10, CodeValue: "C3", Status: domain.StatusDraft, Expiration: "15/12/2020", Price: 30},
+	})
+	service := product.NewService(repository, bus)
+
+	router := gin.New()
+	router.POST("/admin/tasks/unpublish-expired", NewTaskHandler(service).UnpublishExpired())
+	response := webtest.NewClient(t, router).Post("/admin/tasks/unpublish-expired", nil)
+	unpublished := webtest.Data[[]domain.Product](response)
+	expired, _ := service.GetById(1)
+	event := <-subscription
+
+	// Assertions
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Len(t, unpublished, 1)
+	assert.Equal(t, 1, unpublished[0].Id)
+	assert.False(t, expired.Published())
+	assert.Equal(t, 1, event.Id)
+	assert.True(t, event.Previous.(domain.Product).Published())
+}
@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/pkg/web"
+	"github.com/gin-gonic/gin"
+	"strconv"
+)
+
+// GetTranslations godoc
+// @Summary List the translations of a product
+// @Tags Products
+// @Description Get the name and description of a product in every locale other than the base one, by locale.
+// @Produce json
+// @Param id path int true "Product ID"
+// @Success 200 {object} web.Response{data=map[string]domain.Translation}
+// @Failure 400 {object} web.ErrorResponse
+// @Failure 404 {object} web.ErrorResponse
+// @Router /products/{id}/translations [get]
+func (h *ProductHandler) GetTranslations() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			web.Failure(c, 400, ErrInvalidId)
+			return
+		}
+
+		service, err := h.catalog(c)
+		if err != nil {
+			web.Error(c, err)
+			return
+		}
+		targetProduct, err := service.GetById(id)
+		if err != nil {
+			web.Error(c, web.WithParams(err, web.Params{"id": id}))
+			return
+		}
+
+		translations := targetProduct.Translations
+		if translations == nil {
+			translations = map[string]domain.Translation{}
+		}
+		web.Success(c, 200, translations)
+	}
+}
+
+// PutTranslation godoc
+// @Summary Set a translation of a product
+// @Tags Products
+// @Description Create or replace the name and description of a product in a locale other than the base one.
+// @Accept json
+// @Produce json
+// @Param token header string true "Token"
+// @Param id path int true "Product ID"
+// @Param locale path string true "Language tag of the locale, like es or pt-BR"
+// @Param translation body domain.Translation true "Translation"
+// @Param dryRun query bool false "Only validate the change and answer the result, without applying it"
+// @Success 200 {object} web.Response{data=domain.Translation}
+// @Failure 400 {object} web.ErrorResponse
+// @Failure 404 {object} web.ErrorResponse
+// @Failure 422 {object} web.ErrorResponse
+// @Router /products/{id}/translations/{locale} [put]
+func (h *ProductHandler) PutTranslation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		service, dryRun, err := h.mutations(c)
+		if err != nil {
+			web.Error(c, err)
+			return
+		}
+
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			web.Failure(c, 400, ErrInvalidId)
+			return
+		}
+		locale, err := domain.NormalizeLocale(c.Param("locale"))
+		if err != nil {
+			web.Error(c, err)
+			return
+		}
+
+		var translation domain.Translation
+		if err = c.ShouldBindJSON(&translation); err != nil {
+			web.Failure(c, 400, ErrInvalidData)
+			return
+		}
+
+		// Only the translation of the locale changes, so concurrent changes of other locales are kept
+		updatedProduct, err := service.Patch(id, domain.ProductRequest{
+			Translations: map[string]*domain.Translation{locale: &translation},
+		})
+		if err != nil {
+			web.Error(c, h.invalidFields(web.WithParams(err, web.Params{"id": id})))
+			return
+		}
+		web.Success(c, 200, updatedProduct.Translations[locale], web.WithDryRun(dryRun))
+	}
+}
+
+// DeleteTranslation godoc
+// @Summary Delete a translation of a product
+// @Tags Products
+// @Description Delete the name and description of a product in a locale, which falls back to the base one.
+// @Param token header string true "Token"
+// @Param id path int true "Product ID"
+// @Param locale path string true "Language tag of the locale, like es or pt-BR"
+// @Param dryRun query bool false "Only validate the change and answer the result, without applying it"
+// @Success 204 {object} web.Response
+// @Success 200 {object} web.Response{data=domain.Translation} "Result of a dry run"
+// @Failure 400 {object} web.ErrorResponse
+// @Failure 404 {object} web.ErrorResponse
+// @Router /products/{id}/translations/{locale} [delete]
+func (h *ProductHandler) DeleteTranslation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		service, dryRun, err := h.mutations(c)
+		if err != nil {
+			web.Error(c, err)
+			return
+		}
+
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			web.Failure(c, 400, ErrInvalidId)
+			return
+		}
+		locale, err := domain.NormalizeLocale(c.Param("locale"))
+		if err != nil {
+			web.Error(c, err)
+			return
+		}
+
+		targetProduct, err := service.GetById(id)
+		if err != nil {
+			web.Error(c, web.WithParams(err, web.Params{"id": id}))
+			return
+		}
+		translation, ok := targetProduct.Translations[locale]
+		if !ok {
+			web.Error(c, web.WithParams(domain.ErrTranslationNotFound, web.Params{"id": id, "locale": locale}))
+			return
+		}
+
+		if _, err = service.Patch(id, domain.ProductRequest{
+			Translations: map[string]*domain.Translation{locale: nil},
+		}); err != nil {
+			web.Error(c, web.WithParams(err, web.Params{"id": id}))
+			return
+		}
+
+		// A dry run answers the translation that would be deleted
+		if dryRun {
+			web.Success(c, 200, translation, web.WithDryRun(true))
+			return
+		}
+		web.NoContent(c)
+	}
+}
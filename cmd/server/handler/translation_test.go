@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"github.com/JoseObreque/go-web/cmd/server/middleware"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/JoseObreque/go-web/pkg/webtest"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestProductHandler_Translations(t *testing.T) {
+	t.Setenv("TOKEN", "secret")
+	products := []domain.Product{
+		{Id: 1, Name: "Pineapple", Description: "Sweet pineapple", CodeValue: "A1", Status: domain.StatusPublished, Expiration: "15/12/2030", Price: 10},
+	}
+	productHandler := NewProductHandler(product.NewService(product.NewRepository(products), nil))
+	router := gin.New()
+	router.GET("/products", productHandler.GetAll())
+	router.GET("/products/:id", productHandler.GetById())
+	router.GET("/products/:id/translations", productHandler.GetTranslations())
+	router.PUT("/products/:id/translations/:locale", middleware.TokenValidator(), productHandler.PutTranslation())
+	router.DELETE("/products/:id/translations/:locale", middleware.TokenValidator(), productHandler.DeleteTranslation())
+	client := webtest.NewClient(t, router).WithToken("secret")
+
+	// Actual responses
+	created := client.Put("/products/1/translations/ES", domain.Translation{Name: "Piña", Description: "Piña dulce"})
+	invalidLocale := client.Put("/products/1/translations/spanish!", domain.Translation{Name: "Piña"})
+	baseLocale := client.Put("/products/1/translations/en", domain.Translation{Name: "Pineapple"})
+	missingName := client.Put("/products/1/translations/fr", domain.Translation{})
+	translations := client.Get("/products/1/translations")
+	spanish := client.WithHeader("Accept-Language", "es-CL,es;q=0.9,en;q=0.8").Get("/products/1")
+	spanishList := client.WithHeader("Accept-Language", "es").Get("/products")
+	deleted := client.Delete("/products/1/translations/es")
+	deletedAgain := client.Delete("/products/1/translations/es")
+	fallback := client.WithHeader("Accept-Language", "es").Get("/products/1")
+
+	// Assertions
+	assert.Equal(t, http.StatusOK, created.Code)
+	assert.Equal(t, domain.Translation{Name: "Piña", Description: "Piña dulce"}, webtest.Data[domain.Translation](created))
+	assert.Equal(t, http.StatusBadRequest, invalidLocale.Code)
+	assert.Equal(t, "invalid_locale", invalidLocale.Error().ErrorCode)
+	assert.Equal(t, http.StatusUnprocessableEntity, baseLocale.Code)
+	assert.Equal(t, http.StatusBadRequest, missingName.Code)
+	assert.Equal(t, map[string]domain.Translation{"es": {Name: "Piña", Description: "Piña dulce"}},
+		webtest.Data[map[string]domain.Translation](translations))
+	assert.Equal(t, "Piña", webtest.Data[domain.Product](spanish).Name)
+	assert.Equal(t, "es", spanish.Header().Get("Content-Language"))
+	assert.Equal(t, "Piña dulce", webtest.Data[[]domain.Product](spanishList)[0].Description)
+	assert.Equal(t, http.StatusNoContent, deleted.Code)
+	assert.Equal(t, http.StatusNotFound, deletedAgain.Code)
+	assert.Equal(t, "translation_not_found", deletedAgain.Error().ErrorCode)
+	assert.Equal(t, "Pineapple", webtest.Data[domain.Product](fallback).Name)
+	assert.Equal(t, "en", fallback.Header().Get("Content-Language"))
+}
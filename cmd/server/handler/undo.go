@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"github.com/JoseObreque/go-web/internal/undo"
+	"github.com/JoseObreque/go-web/pkg/web"
+	"github.com/gin-gonic/gin"
+)
+
+// UndoHandler is a handler for the endpoint reverting the last product change of an actor.
+type UndoHandler struct {
+	log *undo.Log
+}
+
+// The NewUndoHandler function returns a new UndoHandler that reverts the operations of the given log.
+func NewUndoHandler(log *undo.Log) *UndoHandler {
+	return &UndoHandler{
+		log: log,
+	}
+}
+
+// Undo godoc
+// @Summary Undo the last product change
+// @Tags Tasks
+// @Description Revert the most recent creation, update or deletion of a product made with the credential of the
+// @Description request. The X-Actor header is not trusted, so it only names who made the change, and any actor with
+// @Description the same credential can revert it. A deleted product is created again with a new ID. A product changed
+// @Description again since then cannot be reverted.
+// @Produce json
+// @Param token header string true "Token"
+// @Param X-Actor header string false "Name of the person or system behind the credential, only informative"
+// @Success 200 {object} web.Response{data=undo.Result}
+// @Failure 401 {object} web.ErrorResponse
+// @Failure 404 {object} web.ErrorResponse
+// @Failure 409 {object} web.ErrorResponse
+// @Router /admin/undo [post]
+func (h *UndoHandler) Undo() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		result, err := h.log.Undo(web.Actor(c))
+		if err != nil {
+			web.Error(c, err)
+			return
+		}
+		web.Success(c, 200, result)
+	}
+}
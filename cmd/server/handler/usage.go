@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"github.com/JoseObreque/go-web/internal/usage"
+	"github.com/JoseObreque/go-web/pkg/web"
+	"github.com/gin-gonic/gin"
+)
+
+// UsageHandler is a handler for the API keys usage administration endpoints.
+type UsageHandler struct {
+	tracker *usage.Tracker
+}
+
+// The NewUsageHandler function returns a new UsageHandler that reports the given tracker.
+func NewUsageHandler(tracker *usage.Tracker) *UsageHandler {
+	return &UsageHandler{
+		tracker: tracker,
+	}
+}
+
+// GetAll godoc
+// @Summary List API keys usage
+// @Tags Usage
+// @Description List the requests made today with every API key and its daily quota. Keys are masked.
+// @Produce json
+// @Param token header string true "Token"
+// @Success 200 {object} web.Response
+// @Failure 401 {object} web.ErrorResponse
+// @Router /admin/usage [get]
+func (h *UsageHandler) GetAll() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		web.Success(c, 200, h.tracker.Usage())
+	}
+}
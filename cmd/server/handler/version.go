@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+/*
+The APIVersion interface converts products between the domain model and the DTOs exposed by a
+given version of the API. Breaking changes to the products representation are shipped as a new
+implementation of this interface, mounted under its own router group.
+*/
+type APIVersion interface {
+	// Response converts a product into the representation returned to the client.
+	Response(product domain.Product) interface{}
+	// ResponseList converts a list of products into the representation returned to the client.
+	ResponseList(products []domain.Product) interface{}
+	// Detail converts a product and its upcoming prices into the representation returned to the client.
+	Detail(product domain.Product, upcoming []domain.ScheduledPrice) interface{}
+	// BindProduct extracts a complete product from the request body.
+	BindProduct(c *gin.Context) (domain.Product, error)
+	// BindPartial extracts a partial product update from the request body.
+	BindPartial(c *gin.Context) (domain.ProductRequest, error)
+	// FieldName converts the JSON name of a domain product field into its name in the representation.
+	FieldName(field string) string
+}
+
+var (
+	// V1 exposes the domain products as they are, along with the deprecated is_published flag.
+	V1 APIVersion = v1{}
+	// V2 renames the "code_value" field to "code".
+	V2 APIVersion = v2{}
+)
+
+// The v1 struct implements the first version of the API.
+type v1 struct{}
+
+func (v1) Response(product domain.Product) interface{} {
+	return domain.NewProductV1(product.WithPricePerUnit())
+}
+
+func (v1) ResponseList(products []domain.Product) interface{} {
+	response := make([]domain.ProductV1, len(products))
+	for i, product := range products {
+		response[i] = domain.NewProductV1(product.WithPricePerUnit())
+	}
+	return response
+}
+
+func (v1) Detail(product domain.Product, upcoming []domain.ScheduledPrice) interface{} {
+	return pricedProduct{ProductV1: domain.NewProductV1(product.WithPricePerUnit()), UpcomingPrices: upcoming}
+}
+
+func (v1) BindProduct(c *gin.Context) (domain.Product, error) {
+	var product domain.Product
+	err := c.ShouldBindJSON(&product)
+	// The rating and the price per unit are computed, never sent by the clients
+	product.Rating, product.PricePerUnit = nil, nil
+	return product, err
+}
+
+func (v1) BindPartial(c *gin.Context) (domain.ProductRequest, error) {
+	var request domain.ProductRequest
+	err := c.ShouldBindJSON(&request)
+	return request, err
+}
+
+func (v1) FieldName(field string) string {
+	return field
+}
+
+// The v2 struct implements the second version of the API.
+type v2 struct{}
+
+func (v2) Response(product domain.Product) interface{} {
+	return domain.NewProductV2(product.WithPricePerUnit())
+}
+
+func (v2) ResponseList(products []domain.Product) interface{} {
+	response := make([]domain.ProductV2, len(products))
+	for i, product := range products {
+		response[i] = domain.NewProductV2(product.WithPricePerUnit())
+	}
+	return response
+}
+
+func (v2) Detail(product domain.Product, upcoming []domain.ScheduledPrice) interface{} {
+	return pricedProductV2{ProductV2: domain.NewProductV2(product.WithPricePerUnit()), UpcomingPrices: upcoming}
+}
+
+func (v2) BindProduct(c *gin.Context) (domain.Product, error) {
+	var product domain.ProductV2
+	err := c.ShouldBindJSON(&product)
+	return product.ToProduct(), err
+}
+
+func (v2) BindPartial(c *gin.Context) (domain.ProductRequest, error) {
+	var request domain.ProductRequestV2
+	err := c.ShouldBindJSON(&request)
+	return request.ToProductRequest(), err
+}
+
+func (v2) FieldName(field string) string {
+	if field == "code_value" {
+		return "code"
+	}
+	return field
+}
+
+// The pricedProduct struct is a first version product along with its upcoming prices.
+type pricedProduct struct {
+	domain.ProductV1
+	UpcomingPrices []domain.ScheduledPrice `json:"upcoming_prices"`
+}
+
+// The pricedProductV2 struct is a second version product along with its upcoming prices.
+type pricedProductV2 struct {
+	domain.ProductV2
+	UpcomingPrices []domain.ScheduledPrice `json:"upcoming_prices"`
+}
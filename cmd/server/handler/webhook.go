@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"errors"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/webhook"
+	"github.com/JoseObreque/go-web/pkg/web"
+	"github.com/gin-gonic/gin"
+	"strconv"
+)
+
+var (
+	ErrInvalidWebhookId   = errors.New("invalid webhook id")
+	ErrInvalidWebhookData = errors.New("invalid webhook data")
+)
+
+// WebhookHandler is a handler for the webhooks administration endpoints.
+type WebhookHandler struct {
+	service webhook.Service
+}
+
+// The NewWebhookHandler function returns a new WebhookHandler that uses the provided service.
+func NewWebhookHandler(service webhook.Service) *WebhookHandler {
+	return &WebhookHandler{
+		service: service,
+	}
+}
+
+// GetAll godoc
+// @Summary List webhooks
+// @Tags Webhooks
+// @Description List all registered webhooks
+// @Produce json
+// @Param token header string true "Token"
+// @Success 200 {object} web.Response
+// @Failure 401 {object} web.ErrorResponse
+// @Router /admin/webhooks [get]
+func (h *WebhookHandler) GetAll() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		web.Success(c, 200, h.service.GetAll())
+	}
+}
+
+// Create godoc
+// @Summary Register a webhook
+// @Tags Webhooks
+// @Description Register a URL that receives a signed POST notification every time a product is created, updated or deleted. Leave events empty to receive every event type.
+// @Accept json
+// @Produce json
+// @Param token header string true "Token"
+// @Param webhook body domain.WebhookRequest true "new webhook"
+// @Success 201 {object} web.Response
+// @Failure 400 {object} web.ErrorResponse
+// @Failure 401 {object} web.ErrorResponse
+// @Router /admin/webhooks [post]
+func (h *WebhookHandler) Create() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request domain.WebhookRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			web.Failure(c, 400, ErrInvalidWebhookData)
+			return
+		}
+
+		web.Created(c, h.service.Create(request))
+	}
+}
+
+// Delete godoc
+// @Summary Delete a webhook
+// @Tags Webhooks
+// @Description Stop notifying a webhook
+// @Param token header string true "Token"
+// @Param id path int true "Webhook ID"
+// @Success 204 {object} web.Response
+// @Failure 400 {object} web.ErrorResponse
+// @Failure 404 {object} web.ErrorResponse
+// @Router /admin/webhooks/{id} [delete]
+func (h *WebhookHandler) Delete() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			web.Failure(c, 400, ErrInvalidWebhookId)
+			return
+		}
+
+		if err = h.service.Delete(id); err != nil {
+			web.Failure(c, 404, err)
+			return
+		}
+
+		web.NoContent(c)
+	}
+}
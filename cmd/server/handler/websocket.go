@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/pkg/events"
+	"github.com/JoseObreque/go-web/pkg/web"
+	"github.com/JoseObreque/go-web/pkg/ws"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// WebSocketHandler is a handler for the live catalog updates websocket.
+type WebSocketHandler struct {
+	hub *ws.Hub
+}
+
+// The NewWebSocketHandler function returns a new WebSocketHandler that registers clients in the given hub.
+func NewWebSocketHandler(hub *ws.Hub) *WebSocketHandler {
+	return &WebSocketHandler{
+		hub: hub,
+	}
+}
+
+// Connect godoc
+// @Summary Live catalog updates
+// @Tags Products
+// @Description Open a websocket that receives the product change events. Clients choose what they receive by sending {"action": "subscribe", "topic": "..."} messages, where the topic is "products" (every change), "product:{id}" or "category:{category}".
+// @Success 101
+// @Failure 503 {object} web.ErrorResponse
+// @Router /ws [get]
+func (h *WebSocketHandler) Connect() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		err := h.hub.Serve(c.Writer, c.Request)
+		if errors.Is(err, ws.ErrTooManyConnections) {
+			web.Failure(c, http.StatusServiceUnavailable, err)
+			return
+		}
+		// Any other error has already been answered by the upgrader
+	}
+}
+
+/*
+The ProductTopics function returns the websocket topics of a product event: "products", the
+product id topic and, if the product has one, its category topic.
+*/
+func ProductTopics(event events.Event) []string {
+	topics := []string{"products", fmt.Sprintf("product:%d", event.Id)}
+
+	if p, ok := event.Data.(domain.Product); ok && p.Category != "" {
+		topics = append(topics, "category:"+p.Category)
+	}
+
+	return topics
+}
@@ -0,0 +1,40 @@
+/*
+Package locales contains the catalogs of the error messages returned by the API, one JSON file per
+language named after it (en.json, es.json). The messages are keyed by error code and registered in
+pkg/web when the package is imported.
+*/
+package locales
+
+import (
+	"embed"
+	"encoding/json"
+	"github.com/JoseObreque/go-web/pkg/web"
+	"path"
+	"strings"
+)
+
+//go:embed *.json
+var files embed.FS
+
+func init() {
+	entries, err := files.ReadDir(".")
+	if err != nil {
+		panic(err)
+	}
+
+	for _, entry := range entries {
+		raw, err := files.ReadFile(entry.Name())
+		if err != nil {
+			panic(err)
+		}
+
+		var messages map[string]string
+		if err = json.Unmarshal(raw, &messages); err != nil {
+			panic(err)
+		}
+		language := strings.TrimSuffix(entry.Name(), path.Ext(entry.Name()))
+		if err = web.RegisterMessages(language, messages); err != nil {
+			panic(err)
+		}
+	}
+}
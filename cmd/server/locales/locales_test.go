@@ -0,0 +1,33 @@
+package locales
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCatalogs_SameCodes(t *testing.T) {
+	codes := map[string][]string{}
+	entries, err := files.ReadDir(".")
+	if err != nil {
+		panic(err)
+	}
+	for _, entry := range entries {
+		raw, err := files.ReadFile(entry.Name())
+		if err != nil {
+			panic(err)
+		}
+		var messages map[string]string
+		if err = json.Unmarshal(raw, &messages); err != nil {
+			panic(err)
+		}
+		for code := range messages {
+			codes[code] = append(codes[code], entry.Name())
+		}
+	}
+
+	// Assertions
+	for code, catalogs := range codes {
+		assert.Len(t, catalogs, len(entries), "code %s is missing from some catalogs", code)
+	}
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"github.com/JoseObreque/go-web/pkg/logging"
+	"github.com/JoseObreque/go-web/pkg/metrics"
+	"github.com/gin-gonic/gin"
+	"time"
+)
+
+// Route name of the requests that do not match any endpoint.
+const unmatchedRoute = "unmatched"
+
+/*
+The AccessLog function returns a middleware that logs every request with its status and latency,
+and records the latency per route in the given recorder. Requests slower than the threshold are
+logged again with their full context. A zero threshold disables the slow request log.
+*/
+func AccessLog(latency *metrics.Latency, slowThreshold time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		elapsed := time.Since(start)
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		latency.Observe(c.Request.Method+" "+route, elapsed)
+
+		logging.Infof("%s %s %d %s %dB\n", c.Request.Method, c.Request.URL.Path, c.Writer.Status(), elapsed, c.Writer.Size())
+		if slowThreshold > 0 && elapsed > slowThreshold {
+			logging.Warnf("slow request: %s %s (route %s, query %q) answered %d in %s (threshold %s), client %s, "+
+				"user agent %q, request body %dB, response body %dB, errors: %v\n",
+				c.Request.Method, c.Request.URL.Path, route, c.Request.URL.RawQuery, c.Writer.Status(), elapsed,
+				slowThreshold, c.ClientIP(), c.Request.UserAgent(), c.Request.ContentLength, c.Writer.Size(),
+				c.Errors.Errors())
+		}
+	}
+}
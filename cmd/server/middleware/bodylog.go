@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"bytes"
+	"github.com/JoseObreque/go-web/pkg/logging"
+	"github.com/gin-gonic/gin"
+	"io"
+	"log"
+)
+
+// The bodyWriter struct is a gin.ResponseWriter that keeps a copy of the first bytes of the body.
+type bodyWriter struct {
+	gin.ResponseWriter
+	body  bytes.Buffer
+	limit int
+}
+
+// The Write method writes the data to the client and keeps a copy of it, up to the limit.
+func (w *bodyWriter) Write(data []byte) (int, error) {
+	if room := w.limit - w.body.Len(); room > 0 {
+		if len(data) < room {
+			room = len(data)
+		}
+		w.body.Write(data[:room])
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// The WriteString method writes the string to the client and keeps a copy of it, up to the limit.
+func (w *bodyWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+/*
+The BodyLog function returns a middleware that logs the headers and the bodies of the requests and
+their responses, for debugging, while the bodies are enabled in the logging settings. The
+credential headers and the sensitive JSON fields are redacted, and only the first limit bytes of
+every body are logged.
+*/
+func BodyLog(settings *logging.Settings, redactor *logging.Redactor, limit int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !settings.Bodies() {
+			c.Next()
+			return
+		}
+
+		// Only the logged part of the body is read up front, the handler reads the rest
+		var requestBody []byte
+		if c.Request.Body != nil {
+			read, err := io.ReadAll(io.LimitReader(c.Request.Body, int64(limit)))
+			if err != nil {
+				log.Printf("body log: reading the request body: %s\n", err)
+			}
+			requestBody = read
+			c.Request.Body = readCloser{io.MultiReader(bytes.NewReader(read), c.Request.Body), c.Request.Body}
+		}
+		writer := &bodyWriter{ResponseWriter: c.Writer, limit: limit}
+		c.Writer = writer
+
+		c.Next()
+
+		log.Printf("body log: %s %s\n  request headers: %v\n  request body: %s\n  response %d headers: %v\n"+
+			"  response body: %s\n", c.Request.Method, c.Request.URL.RequestURI(), redactor.Headers(c.Request.Header),
+			redactor.Body(requestBody), writer.Status(), redactor.Headers(writer.Header()), redactor.Body(writer.body.Bytes()))
+	}
+}
+
+// The readCloser struct reads from a reader and closes the original body of a request.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
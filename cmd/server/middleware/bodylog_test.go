@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"bytes"
+	"github.com/JoseObreque/go-web/pkg/logging"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestBodyLog(t *testing.T) {
+	var output bytes.Buffer
+	log.SetOutput(&output)
+	defer log.SetOutput(os.Stderr)
+
+	settings := logging.NewSettings(logging.Options{})
+	router := gin.New()
+	router.Use(BodyLog(settings, logging.NewRedactor("password"), 1024))
+	router.POST("/users", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.Data(http.StatusCreated, "application/json", body)
+	})
+	send := func() *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{"name":"ana","password":"1234"}`))
+		request.Header.Set("Token", "secret-token")
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, request)
+		return response
+	}
+
+	disabled := send()
+	disabledOutput := output.String()
+	settings.Apply(logging.Options{Bodies: true})
+	enabled := send()
+
+	// Assertions
+	assert.Empty(t, disabledOutput)
+	assert.JSONEq(t, `{"name":"ana","password":"1234"}`, disabled.Body.String())
+	assert.JSONEq(t, `{"name":"ana","password":"1234"}`, enabled.Body.String())
+	assert.Contains(t, output.String(), `{"name":"ana","password":"[REDACTED]"}`)
+	assert.Contains(t, output.String(), "response 201")
+	assert.NotContains(t, output.String(), "1234")
+	assert.NotContains(t, output.String(), "secret-token")
+}
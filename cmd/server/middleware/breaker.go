@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"github.com/JoseObreque/go-web/pkg/resilience"
+	"github.com/JoseObreque/go-web/pkg/web"
+	"github.com/gin-gonic/gin"
+	"math"
+	"strconv"
+)
+
+/*
+The CircuitBreaker function returns a middleware that fails fast with 503 and a Retry-After header
+while the circuit of any of the given backends is open, instead of holding the request until the
+call to a backend that is down times out.
+*/
+func CircuitBreaker(breakers ...*resilience.Breaker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, breaker := range breakers {
+			if wait := breaker.RetryAfter(); wait > 0 {
+				c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				c.Abort()
+				web.Error(c, web.WithParams(resilience.ErrOpen, web.Params{"backend": breaker.Name()}))
+				return
+			}
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,158 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/JoseObreque/go-web/pkg/cache"
+	"github.com/JoseObreque/go-web/pkg/web"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"time"
+)
+
+// Header telling whether a response was served from the response cache.
+const HeaderCache = "X-Cache"
+
+// Maximum size of a response body stored in the response cache.
+const maxCachedBody = 16 << 20
+
+// Maximum size of the responses stored in the response cache, the least recently used being evicted beyond.
+const maxCacheSize = 64 << 20
+
+/*
+Query parameters read by the handlers behind the response cache. The others are left out of the
+keys of the cache, so arbitrary parameters cannot multiply its entries, and the order of the
+parameters does not matter.
+*/
+var cachedQueryParams = []string{
+	"currency", "days", "description_format", "diet", "excludeAllergens", "filter", "format", "limit", "page",
+	"page_size", "priceGt", "q", "quantity", "status", "storage", "threshold",
+}
+
+// The cachedResponse struct is a response stored in the response cache.
+type cachedResponse struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+/*
+The recordingWriter struct is a gin.ResponseWriter that keeps a copy of the written body. A
+response that is flushed while being written is a stream and is never cached.
+*/
+type recordingWriter struct {
+	gin.ResponseWriter
+	body     bytes.Buffer
+	streamed bool
+}
+
+// The Write method writes the data to the client and keeps a copy of it.
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	if w.body.Len()+len(data) <= maxCachedBody {
+		w.body.Write(data)
+	} else {
+		w.streamed = true
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// The WriteString method writes the string to the client and keeps a copy of it.
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// The Flush method flags the response as a stream and flushes it to the client.
+func (w *recordingWriter) Flush() {
+	w.streamed = true
+	w.ResponseWriter.Flush()
+}
+
+/*
+The ResponseCache function returns a middleware that caches successful GET responses for ttl,
+keyed by the version of the catalog read by the request, like the one of its tenant, and by
+tenant, path, query parameters read by the handlers (see cachedQueryParams) and Accept and
+Accept-Language headers, so repeated reads do not reach the handlers. A change of the version,
+whatever made it, and every successful mutation that goes through the middleware bust the cache.
+Requests with "Cache-Control: no-cache" skip the cache, and streamed responses are never cached.
+The cache holds up to maxCacheSize bytes, evicting the least recently used responses beyond.
+*/
+func ResponseCache(ttl time.Duration, version func(c *gin.Context) string) gin.HandlerFunc {
+	return CountedResponseCache(ttl, version, &cache.Counter{})
+}
+
+// The CountedResponseCache function returns a ResponseCache counting its hits and misses in counter.
+func CountedResponseCache(ttl time.Duration, version func(c *gin.Context) string, counter *cache.Counter) gin.HandlerFunc {
+	store := cache.NewBoundedMemory(maxCacheSize)
+	var generation int64
+
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet {
+			c.Next()
+			if c.Writer.Status() < http.StatusBadRequest {
+				atomic.AddInt64(&generation, 1)
+			}
+			return
+		}
+
+		if c.GetHeader("Cache-Control") == "no-cache" {
+			c.Next()
+			return
+		}
+
+		ctx := context.Background()
+		key := fmt.Sprintf("%d:%s:%s:%s?%s:%s:%s", atomic.LoadInt64(&generation), version(c), web.Tenant(c), c.Request.URL.Path,
+			cacheQuery(c), c.GetHeader("Accept"), c.GetHeader("Accept-Language"))
+
+		// Serve the response from the cache if possible
+		if data, err := store.Get(ctx, key); err == nil {
+			var response cachedResponse
+			if json.Unmarshal(data, &response) == nil {
+				for name, values := range response.Header {
+					c.Writer.Header()[name] = values
+				}
+				counter.Hit()
+				c.Header(HeaderCache, "HIT")
+				c.Data(response.Status, response.Header.Get("Content-Type"), response.Body)
+				c.Abort()
+				return
+			}
+		}
+
+		counter.Miss()
+		writer := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Header(HeaderCache, "MISS")
+		c.Next()
+
+		if writer.streamed || writer.Status() != http.StatusOK {
+			return
+		}
+
+		header := writer.Header().Clone()
+		header.Del(HeaderCache)
+		data, err := json.Marshal(cachedResponse{
+			Status: writer.Status(),
+			Header: header,
+			Body:   writer.body.Bytes(),
+		})
+		if err == nil {
+			_ = store.Set(ctx, key, data, ttl)
+		}
+	}
+}
+
+// Auxiliary function that returns the query parameters of the request read by the cached handlers, encoded in a fixed order.
+func cacheQuery(c *gin.Context) string {
+	query := c.Request.URL.Query()
+	params := make(url.Values, len(cachedQueryParams))
+	for _, name := range cachedQueryParams {
+		if values, ok := query[name]; ok {
+			params[name] = values
+		}
+	}
+	return params.Encode()
+}
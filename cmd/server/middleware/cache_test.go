@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestResponseCache(t *testing.T) {
+	calls := 0
+	version := "1"
+
+	router := gin.New()
+	router.Use(ResponseCache(time.Minute, func(c *gin.Context) string { return version }))
+	router.GET("/products", func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusOK, gin.H{"calls": calls})
+	})
+	router.POST("/products", func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+
+	get := func(headers map[string]string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, "/products?price=1", nil)
+		for name, value := range headers {
+			request.Header.Set(name, value)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	first := get(nil)
+	second := get(nil)
+	bypass := get(map[string]string{"Cache-Control": "no-cache"})
+
+	// Assertions
+	assert.Equal(t, "MISS", first.Header().Get(HeaderCache))
+	assert.Equal(t, "HIT", second.Header().Get(HeaderCache))
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, "application/json; charset=utf-8", second.Header().Get("Content-Type"))
+	assert.Equal(t, 2, calls)
+	assert.Empty(t, bypass.Header().Get(HeaderCache))
+
+	// A mutation busts the cache
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/products", nil))
+	afterMutation := get(nil)
+
+	// Assertions
+	assert.Equal(t, "MISS", afterMutation.Header().Get(HeaderCache))
+	assert.Equal(t, 3, calls)
+
+	// So does a change of the version made elsewhere
+	version = "2"
+	afterChange := get(nil)
+	cached := get(nil)
+
+	// Assertions
+	assert.Equal(t, "MISS", afterChange.Header().Get(HeaderCache))
+	assert.Equal(t, "HIT", cached.Header().Get(HeaderCache))
+	assert.Equal(t, 4, calls)
+}
+
+func TestResponseCache_Query(t *testing.T) {
+	calls := 0
+	router := gin.New()
+	router.Use(ResponseCache(time.Minute, func(c *gin.Context) string { return "1" }))
+	router.GET("/products", func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusOK, gin.H{"calls": calls})
+	})
+	get := func(url string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, url, nil))
+		return recorder
+	}
+
+	first := get("/products?priceGt=1&status=draft")
+	reordered := get("/products?status=draft&priceGt=1&utm_source=mail")
+	other := get("/products?status=draft&priceGt=2")
+
+	// Assertions
+	assert.Equal(t, "MISS", first.Header().Get(HeaderCache))
+	assert.Equal(t, "HIT", reordered.Header().Get(HeaderCache))
+	assert.Equal(t, first.Body.String(), reordered.Body.String())
+	assert.Equal(t, "MISS", other.Header().Get(HeaderCache))
+	assert.Equal(t, 2, calls)
+}
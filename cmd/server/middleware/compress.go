@@ -0,0 +1,197 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Content encodings supported by the compression middleware, in order of preference.
+const (
+	encodingBrotli = "br"
+	encodingGzip   = "gzip"
+)
+
+/*
+The compressWriter struct is a gin.ResponseWriter that compresses the body with the negotiated
+encoding. The first bytes are buffered until minSize is reached, so small bodies are sent as is;
+bodies already encoded or of a non compressible content type are never compressed.
+*/
+type compressWriter struct {
+	gin.ResponseWriter
+	encoding string
+	minSize  int
+	buffer   bytes.Buffer
+	decided  bool
+	encoder  io.WriteCloser
+}
+
+// The Write method buffers or compresses the data, depending on the body size.
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if w.decided {
+		return w.write(data)
+	}
+
+	w.buffer.Write(data)
+	if w.buffer.Len() >= w.minSize {
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(data), nil
+}
+
+// The WriteString method writes a string as a byte slice.
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// The Written method reports whether a body has been written, even if it is still buffered.
+func (w *compressWriter) Written() bool {
+	return w.buffer.Len() > 0 || w.ResponseWriter.Written()
+}
+
+// The WriteHeaderNow method sends the headers of a response without body, uncompressed.
+func (w *compressWriter) WriteHeaderNow() {
+	if !w.decided {
+		_ = w.decide(false)
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+// The Flush method sends the buffered data, compressed if possible, and flushes the response.
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		_ = w.decide(w.buffer.Len() > 0)
+	}
+	if flusher, ok := w.encoder.(interface{ Flush() error }); ok {
+		_ = flusher.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// The close method sends the buffered data and finishes the compressed stream.
+func (w *compressWriter) close() {
+	if !w.decided {
+		_ = w.decide(false)
+	}
+	if w.encoder != nil {
+		_ = w.encoder.Close()
+	}
+}
+
+/*
+Auxiliary method that decides whether the body is compressed, sets the headers accordingly and
+sends the buffered data.
+*/
+func (w *compressWriter) decide(large bool) error {
+	w.decided = true
+
+	header := w.Header()
+	if large && header.Get("Content-Encoding") == "" && compressible(header.Get("Content-Type")) {
+		header.Set("Content-Encoding", w.encoding)
+		header.Add("Vary", "Accept-Encoding")
+		header.Del("Content-Length")
+
+		if w.encoding == encodingBrotli {
+			w.encoder = brotli.NewWriterLevel(w.ResponseWriter, brotli.DefaultCompression)
+		} else {
+			w.encoder, _ = gzip.NewWriterLevel(w.ResponseWriter, gzip.DefaultCompression)
+		}
+	}
+
+	if w.buffer.Len() == 0 {
+		return nil
+	}
+	_, err := w.write(w.buffer.Bytes())
+	w.buffer.Reset()
+	return err
+}
+
+// Auxiliary method that writes data through the encoder, if the body is compressed.
+func (w *compressWriter) write(data []byte) (int, error) {
+	if w.encoder != nil {
+		return w.encoder.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+/*
+The Compression function returns a middleware that compresses the responses with Brotli or gzip,
+as negotiated with the Accept-Encoding header. Bodies smaller than minSize bytes, already encoded
+bodies, binary content types and server-sent event streams are sent uncompressed.
+*/
+func Compression(minSize int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"))
+		if encoding == "" || c.GetHeader("Upgrade") != "" || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+
+		writer := &compressWriter{
+			ResponseWriter: c.Writer,
+			encoding:       encoding,
+			minSize:        minSize,
+		}
+		c.Writer = writer
+		defer writer.close()
+
+		c.Next()
+	}
+}
+
+/*
+Auxiliary function that returns the supported encoding with the highest quality value in an
+Accept-Encoding header, preferring Brotli on ties, or an empty string if none is acceptable.
+*/
+func negotiateEncoding(acceptEncoding string) string {
+	best, bestQuality := "", 0.0
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		name := strings.ToLower(strings.TrimSpace(fields[0]))
+		if name != encodingBrotli && name != encodingGzip {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if parsed, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					quality = parsed
+				}
+			}
+		}
+
+		if quality > bestQuality || (quality == bestQuality && name == encodingBrotli) {
+			best, bestQuality = name, quality
+		}
+	}
+	return best
+}
+
+// Auxiliary function that checks if a content type is worth compressing.
+func compressible(contentType string) bool {
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	switch {
+	case mediaType == "text/event-stream":
+		return false
+	case strings.HasPrefix(mediaType, "text/"):
+		return true
+	case strings.HasSuffix(mediaType, "+json"), strings.HasSuffix(mediaType, "+xml"):
+		return true
+	}
+
+	switch mediaType {
+	case "application/json", "application/x-ndjson", "application/javascript", "application/xml",
+		"application/msgpack", "application/x-msgpack":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCompression(t *testing.T) {
+	large := strings.Repeat("pineapple ", 500)
+	router := gin.New()
+	router.Use(Compression(1024))
+	router.GET("/large", func(c *gin.Context) {
+		c.String(http.StatusOK, large)
+	})
+	router.GET("/small", func(c *gin.Context) {
+		c.String(http.StatusOK, "pong")
+	})
+	router.GET("/xlsx", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", []byte(large))
+	})
+
+	get := func(path string, acceptEncoding string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, path, nil)
+		request.Header.Set("Accept-Encoding", acceptEncoding)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	t.Run("Gzip", func(t *testing.T) {
+		response := get("/large", "gzip, deflate")
+		reader, err := gzip.NewReader(response.Body)
+		if err != nil {
+			panic(err)
+		}
+		body, _ := io.ReadAll(reader)
+
+		// Assertions
+		assert.Equal(t, "gzip", response.Header().Get("Content-Encoding"))
+		assert.Equal(t, large, string(body))
+	})
+
+	t.Run("Brotli", func(t *testing.T) {
+		response := get("/large", "gzip;q=0.8, br")
+		body, _ := io.ReadAll(brotli.NewReader(response.Body))
+
+		// Assertions
+		assert.Equal(t, "br", response.Header().Get("Content-Encoding"))
+		assert.Equal(t, large, string(body))
+	})
+
+	t.Run("Skipped", func(t *testing.T) {
+		small := get("/small", "gzip")
+		binary := get("/xlsx", "gzip")
+		identity := get("/large", "identity")
+
+		// Assertions
+		assert.Empty(t, small.Header().Get("Content-Encoding"))
+		assert.Equal(t, "pong", small.Body.String())
+		assert.Empty(t, binary.Header().Get("Content-Encoding"))
+		assert.Equal(t, large, binary.Body.String())
+		assert.Empty(t, identity.Header().Get("Content-Encoding"))
+	})
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"github.com/JoseObreque/go-web/pkg/config"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+)
+
+// Methods and headers the cross-origin requests are allowed to use.
+const (
+	corsMethods = "GET, POST, PUT, PATCH, DELETE, OPTIONS"
+	corsHeaders = "Content-Type, Token, X-Signature, X-Timestamp, X-Tenant, Idempotency-Key, Prefer, Accept-Language"
+)
+
+/*
+The CORS function returns a middleware that allows the browsers to call the API from the given
+origins, or from any origin if they include "*". The preflight requests of an allowed origin are
+answered with 204, and the requests of the other origins are served without the CORS headers, so
+the browsers block their responses. The origins can change while the server runs.
+*/
+func CORS(origins *config.Value[[]string]) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+		c.Writer.Header().Add("Vary", "Origin")
+		if !allowedOrigin(origins.Get(), origin) {
+			c.Next()
+			return
+		}
+
+		c.Header("Access-Control-Allow-Origin", origin)
+		if c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != "" {
+			c.Header("Access-Control-Allow-Methods", corsMethods)
+			c.Header("Access-Control-Allow-Headers", corsHeaders)
+			c.Header("Access-Control-Max-Age", "600")
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+		c.Next()
+	}
+}
+
+// Auxiliary function that checks whether an origin is in the allowed ones, case insensitive.
+func allowedOrigin(allowed []string, origin string) bool {
+	for _, candidate := range allowed {
+		if candidate == "*" || strings.EqualFold(candidate, origin) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"github.com/JoseObreque/go-web/pkg/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCORS(t *testing.T) {
+	origins := config.NewValue([]string{"https://shop.example.com"})
+	router := gin.New()
+	router.Use(CORS(origins))
+	router.GET("/products", func(c *gin.Context) {
+		c.String(http.StatusOK, "products")
+	})
+	send := func(method, origin string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, "/products", nil)
+		request.Header.Set("Origin", origin)
+		if method == http.MethodOptions {
+			request.Header.Set("Access-Control-Request-Method", http.MethodGet)
+		}
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, request)
+		return response
+	}
+
+	allowed := send(http.MethodGet, "https://shop.example.com")
+	preflight := send(http.MethodOptions, "https://shop.example.com")
+	other := send(http.MethodGet, "https://evil.example.com")
+	origins.Set([]string{"*"})
+	reloaded := send(http.MethodGet, "https://evil.example.com")
+
+	// Assertions
+	assert.Equal(t, http.StatusOK, allowed.Code)
+	assert.Equal(t, "https://shop.example.com", allowed.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, http.StatusNoContent, preflight.Code)
+	assert.Contains(t, preflight.Header().Get("Access-Control-Allow-Headers"), "Token")
+	assert.Equal(t, http.StatusOK, other.Code)
+	assert.Empty(t, other.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "https://evil.example.com", reloaded.Header().Get("Access-Control-Allow-Origin"))
+}
@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"github.com/JoseObreque/go-web/pkg/web"
+	"net/http"
+)
+
+// Mapping of the errors returned by the middlewares to HTTP statuses and error codes.
+func init() {
+	web.RegisterError(ErrInvalidToken, http.StatusUnauthorized, "invalid_token")
+	web.RegisterError(ErrInvalidSignature, http.StatusUnauthorized, "invalid_signature")
+	web.RegisterError(ErrStaleTimestamp, http.StatusUnauthorized, "stale_timestamp")
+	web.RegisterError(ErrForbiddenAddress, http.StatusForbidden, "forbidden_address")
+	web.RegisterError(ErrRequestTimeout, http.StatusRequestTimeout, "request_timeout")
+	web.RegisterError(ErrBodyTooLarge, http.StatusRequestEntityTooLarge, "body_too_large")
+	web.RegisterError(ErrIdempotencyInFlight, http.StatusConflict, "idempotency_key_in_use")
+	web.RegisterError(ErrIdempotencyMismatch, http.StatusUnprocessableEntity, "idempotency_key_mismatch")
+	web.RegisterError(ErrQuotaExceeded, http.StatusTooManyRequests, "quota_exceeded")
+}
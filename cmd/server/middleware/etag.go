@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/JoseObreque/go-web/pkg/web"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+)
+
+/*
+The ETag function returns a middleware that tags the GET responses with a weak ETag derived from
+the catalog version, the requested URI and the Accept and Accept-Language headers. Requests whose If-None-Match header
+matches the current tag are answered with 304 Not Modified without reaching the handler.
+*/
+func ETag(version func() string) gin.HandlerFunc {
+	return CatalogETag(func(c *gin.Context) string {
+		return version()
+	})
+}
+
+/*
+The CatalogETag function returns an ETag middleware whose version depends on the request, for the
+deployments serving the catalogs of several tenants. The tenant is part of the tag as well.
+*/
+func CatalogETag(version func(c *gin.Context) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			c.Next()
+			return
+		}
+
+		sum := sha256.Sum256([]byte(version(c) + "|" + web.Tenant(c) + "|" + c.Request.URL.RequestURI() + "|" + c.GetHeader("Accept") + "|" + c.GetHeader("Accept-Language")))
+		etag := `W/"` + hex.EncodeToString(sum[:8]) + `"`
+		c.Header("ETag", etag)
+		c.Header("Vary", "Accept, Accept-Language, "+web.HeaderTenant)
+
+		if matchesETag(c.GetHeader("If-None-Match"), etag) {
+			c.AbortWithStatus(http.StatusNotModified)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// Auxiliary function that checks if an If-None-Match header matches an ETag, using weak comparison.
+func matchesETag(ifNoneMatch string, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
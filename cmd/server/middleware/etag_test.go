@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestETag(t *testing.T) {
+	version := "1"
+	router := gin.New()
+	router.Use(ETag(func() string { return version }))
+	router.GET("/products", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"data": []string{}})
+	})
+
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, "/products", nil)
+		if ifNoneMatch != "" {
+			request.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	first := get("")
+	etag := first.Header().Get("ETag")
+	notModified := get(etag)
+
+	// A mutation changes the catalog version
+	version = "2"
+	modified := get(etag)
+
+	// Assertions
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.NotEmpty(t, etag)
+	assert.Equal(t, http.StatusNotModified, notModified.Code)
+	assert.Empty(t, notModified.Body.String())
+	assert.Equal(t, http.StatusOK, modified.Code)
+	assert.NotEqual(t, etag, modified.Header().Get("ETag"))
+	assert.Equal(t, http.StatusNotModified, get(`"other", `+modified.Header().Get("ETag")).Code)
+}
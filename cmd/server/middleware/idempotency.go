@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"github.com/JoseObreque/go-web/pkg/web"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Headers of the idempotent requests.
+const (
+	HeaderIdempotencyKey = "Idempotency-Key"
+	HeaderReplayed       = "Idempotent-Replayed"
+)
+
+var (
+	ErrIdempotencyInFlight = errors.New("a request with the same idempotency key is in progress")
+	ErrIdempotencyMismatch = errors.New("idempotency key already used with a different request body")
+)
+
+// The idempotentResult struct is the stored outcome of an idempotent request.
+type idempotentResult struct {
+	fingerprint [sha256.Size]byte
+	done        bool
+	status      int
+	header      http.Header
+	body        []byte
+	expiresAt   time.Time
+}
+
+/*
+The Idempotency function returns a middleware that makes the requests carrying an
+Idempotency-Key header safe to retry. The response of the first request is stored for retention
+and returned as is to every retry with the same key, flagged with the Idempotent-Replayed header,
+so network retries do not repeat the operation. Keys are scoped by token, tenant and path;
+reusing a key with a different body is rejected with 422, and a retry sent while the first request
+is still in progress is rejected with 409. Server errors are not stored, so they can be retried.
+*/
+func Idempotency(retention time.Duration) gin.HandlerFunc {
+	var mu sync.Mutex
+	results := make(map[string]*idempotentResult)
+	lastSweep := time.Now()
+
+	return func(c *gin.Context) {
+		// Dry runs change nothing, so they are never replayed instead of the real request
+		idempotencyKey := c.GetHeader(HeaderIdempotencyKey)
+		if dryRun, _ := web.DryRun(c); idempotencyKey == "" || dryRun {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.Abort()
+			web.Failure(c, http.StatusBadRequest, err)
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		key := c.GetHeader("token") + "|" + web.Tenant(c) + "|" + c.Request.URL.Path + "|" + idempotencyKey
+		fingerprint := sha256.Sum256(body)
+		now := time.Now()
+
+		mu.Lock()
+		if now.Sub(lastSweep) > time.Minute {
+			for k, result := range results {
+				if result.done && now.After(result.expiresAt) {
+					delete(results, k)
+				}
+			}
+			lastSweep = now
+		}
+
+		result, exists := results[key]
+		if exists && result.done && now.After(result.expiresAt) {
+			exists = false
+		}
+		if exists {
+			// The first request completes the result under the lock, so it is copied before releasing it.
+			// Its header and body are not modified once it is done.
+			stored := *result
+			mu.Unlock()
+			switch {
+			case stored.fingerprint != fingerprint:
+				c.Abort()
+				web.Failure(c, http.StatusUnprocessableEntity, ErrIdempotencyMismatch)
+			case !stored.done:
+				c.Abort()
+				web.Failure(c, http.StatusConflict, ErrIdempotencyInFlight)
+			default:
+				for name, values := range stored.header {
+					c.Writer.Header()[name] = append([]string(nil), values...)
+				}
+				c.Header(HeaderReplayed, "true")
+				c.Data(stored.status, stored.header.Get("Content-Type"), stored.body)
+				c.Abort()
+			}
+			return
+		}
+
+		// Reserve the key while the request is processed
+		result = &idempotentResult{fingerprint: fingerprint}
+		results[key] = result
+		mu.Unlock()
+
+		// Release the key if the request fails, even by a panic
+		defer func() {
+			mu.Lock()
+			defer mu.Unlock()
+			if !result.done {
+				delete(results, key)
+			}
+		}()
+
+		writer := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+
+		if writer.Status() >= http.StatusInternalServerError || writer.streamed {
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		result.done = true
+		result.status = writer.Status()
+		result.header = writer.Header().Clone()
+		result.body = writer.body.Bytes()
+		result.expiresAt = time.Now().Add(retention)
+	}
+}
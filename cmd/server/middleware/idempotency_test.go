@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestIdempotency(t *testing.T) {
+	created := 0
+	router := gin.New()
+	router.Use(Idempotency(time.Hour))
+	router.POST("/products", func(c *gin.Context) {
+		created++
+		c.JSON(http.StatusCreated, gin.H{"id": created})
+	})
+
+	post := func(key string, body string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, "/products", strings.NewReader(body))
+		if key != "" {
+			request.Header.Set(HeaderIdempotencyKey, key)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	first := post("abc", `{"name":"Oil"}`)
+	retry := post("abc", `{"name":"Oil"}`)
+	mismatch := post("abc", `{"name":"Rice"}`)
+	other := post("def", `{"name":"Oil"}`)
+	withoutKey := post("", `{"name":"Oil"}`)
+
+	// A dry run with the key of a later request is not replayed
+	request := httptest.NewRequest(http.MethodPost, "/products?dryRun=true", strings.NewReader(`{"name":"Salt"}`))
+	request.Header.Set(HeaderIdempotencyKey, "ghi")
+	router.ServeHTTP(httptest.NewRecorder(), request)
+	afterDryRun := post("ghi", `{"name":"Salt"}`)
+
+	// Assertions
+	assert.Equal(t, http.StatusCreated, first.Code)
+	assert.Equal(t, http.StatusCreated, retry.Code)
+	assert.Equal(t, first.Body.String(), retry.Body.String())
+	assert.Equal(t, "true", retry.Header().Get(HeaderReplayed))
+	assert.Equal(t, http.StatusUnprocessableEntity, mismatch.Code)
+	assert.Equal(t, `{"id":2}`, other.Body.String())
+	assert.Equal(t, `{"id":3}`, withoutKey.Body.String())
+	assert.Equal(t, `{"id":5}`, afterDryRun.Body.String())
+	assert.Empty(t, afterDryRun.Header().Get(HeaderReplayed))
+	assert.Equal(t, 5, created)
+}
+
+func TestIdempotency_Concurrent(t *testing.T) {
+	var created int32
+	router := gin.New()
+	router.Use(Idempotency(time.Hour))
+	router.POST("/products", func(c *gin.Context) {
+		c.JSON(http.StatusCreated, gin.H{"id": atomic.AddInt32(&created, 1)})
+	})
+
+	// The retries run while the first request completes its result, under the race detector
+	var wg sync.WaitGroup
+	codes := make([]int, 20)
+	for i := range codes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			request := httptest.NewRequest(http.MethodPost, "/products", strings.NewReader(`{"name":"Oil"}`))
+			request.Header.Set(HeaderIdempotencyKey, "abc")
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+			codes[i] = recorder.Code
+		}(i)
+	}
+	wg.Wait()
+
+	// Assertions
+	assert.Equal(t, int32(1), atomic.LoadInt32(&created))
+	for _, code := range codes {
+		assert.Contains(t, []int{http.StatusCreated, http.StatusConflict}, code)
+	}
+}
@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"github.com/JoseObreque/go-web/pkg/web"
+	"github.com/gin-gonic/gin"
+	"net"
+	"net/http"
+	"strings"
+)
+
+var ErrForbiddenAddress = errors.New("client address not allowed")
+
+/*
+The IPRules struct holds the networks allowed and denied by the IPFilter middleware. A denied
+network always wins; when Allow is empty, every address that is not denied is allowed.
+*/
+type IPRules struct {
+	Allow []*net.IPNet
+	Deny  []*net.IPNet
+}
+
+/*
+The ParseIPRules function builds the IP rules from two comma separated lists of CIDR ranges.
+Single addresses are accepted as well and match only themselves.
+*/
+func ParseIPRules(allow string, deny string) (IPRules, error) {
+	allowed, err := parseNetworks(allow)
+	if err != nil {
+		return IPRules{}, err
+	}
+	denied, err := parseNetworks(deny)
+	if err != nil {
+		return IPRules{}, err
+	}
+
+	return IPRules{Allow: allowed, Deny: denied}, nil
+}
+
+// The Empty method reports whether the rules do not restrict any address.
+func (r IPRules) Empty() bool {
+	return len(r.Allow) == 0 && len(r.Deny) == 0
+}
+
+// The Allowed method reports whether the rules allow the given address.
+func (r IPRules) Allowed(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	if containsIP(r.Deny, ip) {
+		return false
+	}
+	return len(r.Allow) == 0 || containsIP(r.Allow, ip)
+}
+
+/*
+The IPFilter function returns a middleware that rejects with 403 the requests whose client
+address is not allowed by the given rules. The client address is resolved by gin, so the
+X-Forwarded-For header is only honored when the request comes from a trusted proxy.
+*/
+func IPFilter(rules IPRules) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !rules.Allowed(net.ParseIP(c.ClientIP())) {
+			c.Abort()
+			web.Failure(c, http.StatusForbidden, ErrForbiddenAddress)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// Auxiliary function that parses a comma separated list of CIDR ranges or single addresses.
+func parseNetworks(list string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		if !strings.Contains(item, "/") {
+			ip := net.ParseIP(item)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid address %q", item)
+			}
+			bits := 8 * len(ip.To16())
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(item)
+		if err != nil {
+			return nil, err
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// Auxiliary function that checks if any of the networks contains the address.
+func containsIP(networks []*net.IPNet, ip net.IP) bool {
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIPFilter(t *testing.T) {
+	rules, err := ParseIPRules("10.8.0.0/16, 192.168.1.10", "10.8.5.0/24")
+	if err != nil {
+		panic(err)
+	}
+
+	router := gin.New()
+	router.Use(IPFilter(rules))
+	router.POST("/products", func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+
+	post := func(remoteAddr string) int {
+		request := httptest.NewRequest(http.MethodPost, "/products", nil)
+		request.RemoteAddr = remoteAddr
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+
+	// Assertions
+	assert.Equal(t, http.StatusCreated, post("10.8.1.2:5000"))
+	assert.Equal(t, http.StatusCreated, post("192.168.1.10:5000"))
+	assert.Equal(t, http.StatusForbidden, post("10.8.5.7:5000"))
+	assert.Equal(t, http.StatusForbidden, post("192.168.1.11:5000"))
+
+	_, err = ParseIPRules("10.0.0.0/33", "")
+	assert.Error(t, err)
+}
+
+func TestIPFilter_ForwardedFor(t *testing.T) {
+	rules, err := ParseIPRules("10.8.0.0/16", "")
+	if err != nil {
+		panic(err)
+	}
+
+	post := func(trustedProxies []string, remoteAddr string, forwardedFor string) int {
+		router := gin.New()
+		if err := router.SetTrustedProxies(trustedProxies); err != nil {
+			panic(err)
+		}
+		router.Use(IPFilter(rules))
+		router.POST("/products", func(c *gin.Context) {
+			c.Status(http.StatusCreated)
+		})
+
+		request := httptest.NewRequest(http.MethodPost, "/products", nil)
+		request.RemoteAddr = remoteAddr
+		request.Header.Set("X-Forwarded-For", forwardedFor)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+
+	// Assertions: a spoofed header is ignored unless the request comes from a trusted proxy
+	assert.Equal(t, http.StatusForbidden, post(nil, "203.0.113.7:5000", "10.8.1.2"))
+	assert.Equal(t, http.StatusForbidden, post([]string{"192.168.0.1"}, "203.0.113.7:5000", "10.8.1.2"))
+	assert.Equal(t, http.StatusCreated, post([]string{"192.168.0.1"}, "192.168.0.1:5000", "10.8.1.2"))
+	assert.Equal(t, http.StatusForbidden, post([]string{"192.168.0.1"}, "192.168.0.1:5000", "203.0.113.7"))
+}
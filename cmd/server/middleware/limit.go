@@ -0,0 +1,248 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/JoseObreque/go-web/pkg/web"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	ErrBodyTooLarge   = errors.New("request body too large")
+	ErrRequestTimeout = errors.New("request timeout")
+)
+
+/*
+The BodyLimit function returns a middleware that rejects with 413 the POST, PUT and PATCH requests
+whose body is larger than maxBytes. The body is read up front, never beyond the limit, so an
+oversized upload cannot exhaust the server memory.
+*/
+func BodyLimit(maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch:
+		default:
+			c.Next()
+			return
+		}
+
+		if c.Request.ContentLength > maxBytes {
+			c.Abort()
+			web.Failure(c, http.StatusRequestEntityTooLarge, ErrBodyTooLarge)
+			return
+		}
+
+		// The declared length may be missing or false, so the limit is enforced while reading
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxBytes+1))
+		if err != nil {
+			c.Abort()
+			web.Failure(c, http.StatusBadRequest, err)
+			return
+		}
+		if int64(len(body)) > maxBytes {
+			c.Abort()
+			web.Failure(c, http.StatusRequestEntityTooLarge, ErrBodyTooLarge)
+			return
+		}
+
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Next()
+	}
+}
+
+/*
+The Timeout function returns a middleware that answers with a 408 the requests not handled within
+the timeout, like http.TimeoutHandler: the rest of the chain runs in its own goroutine with a
+deadline in the request context, and its response is buffered until it ends. When the deadline
+passes first, the client receives the 408 right away, whether the handlers and services honor the
+context or not, and their late writes are discarded. The middleware still waits for them before
+returning, since the gin context is reused afterwards. A handler that flushes its response streams
+it from then on, without the deadline.
+*/
+func Timeout(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		request, writer := c.Request, c.Writer
+		buffer := newTimeoutWriter(writer)
+		c.Writer = buffer
+
+		done := make(chan struct{})
+		var panicked interface{}
+		go func() {
+			defer close(done)
+			defer func() { panicked = recover() }()
+			c.Next()
+		}()
+
+		select {
+		case <-done:
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) && buffer.timeOut() {
+				failure := newTimeoutWriter(writer)
+				web.WriteError(failure, request, ErrRequestTimeout)
+				failure.Header().Set("Content-Length", strconv.Itoa(failure.body.Len()))
+				failure.flushTo(writer)
+				writer.Flush()
+			}
+			<-done
+		}
+
+		c.Writer = writer
+		if panicked != nil {
+			panic(panicked)
+		}
+		buffer.flushTo(writer)
+	}
+}
+
+/*
+The timeoutWriter struct is the response writer of the handlers run by the Timeout middleware. It
+buffers the response until the handlers end, or until they flush it, and discards it once the
+request times out.
+*/
+type timeoutWriter struct {
+	gin.ResponseWriter
+	mu        sync.Mutex
+	header    http.Header
+	body      bytes.Buffer
+	status    int
+	written   bool
+	size      int
+	timedOut  bool
+	streaming bool
+}
+
+// Auxiliary function that returns a timeoutWriter of the response of writer, starting with its headers.
+func newTimeoutWriter(writer gin.ResponseWriter) *timeoutWriter {
+	return &timeoutWriter{
+		ResponseWriter: writer,
+		header:         writer.Header().Clone(),
+		status:         http.StatusOK,
+	}
+}
+
+// The Header method returns the headers of the buffered response.
+func (w *timeoutWriter) Header() http.Header {
+	return w.header
+}
+
+// The WriteHeader method sets the status of the response, until the body is written.
+func (w *timeoutWriter) WriteHeader(code int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if code > 0 && !w.written {
+		w.status = code
+	}
+}
+
+// The WriteHeaderNow method marks the status and the headers of the response as written.
+func (w *timeoutWriter) WriteHeaderNow() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.written = true
+}
+
+// The Write method buffers the body, writes it once the response is streamed, or fails after the timeout.
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	w.written = true
+	w.size += len(data)
+	if w.streaming {
+		return w.ResponseWriter.Write(data)
+	}
+	return w.body.Write(data)
+}
+
+// The WriteString method writes the body like Write.
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// The Status method returns the status of the response.
+func (w *timeoutWriter) Status() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.status
+}
+
+// The Size method returns the bytes of the body written, or -1 if the response is not written yet.
+func (w *timeoutWriter) Size() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.written {
+		return -1
+	}
+	return w.size
+}
+
+// The Written method reports whether the response was written.
+func (w *timeoutWriter) Written() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.written
+}
+
+/*
+The Flush method writes the buffered response and streams the rest of it, which is no longer
+subject to the timeout. It does nothing after the timeout.
+*/
+func (w *timeoutWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timedOut {
+		return
+	}
+	if !w.streaming {
+		w.streaming = true
+		w.written = true
+		w.copyTo(w.ResponseWriter)
+	}
+	w.ResponseWriter.Flush()
+}
+
+// Auxiliary method that discards the response once the request times out, unless it is already streamed.
+func (w *timeoutWriter) timeOut() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.streaming {
+		return false
+	}
+	w.timedOut = true
+	return true
+}
+
+// Auxiliary method that writes the buffered response to the given writer, unless it timed out or was streamed.
+func (w *timeoutWriter) flushTo(writer gin.ResponseWriter) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timedOut || w.streaming {
+		return
+	}
+	w.copyTo(writer)
+}
+
+// Auxiliary method that writes the headers, the status and the body buffered to the given writer, with the writer held.
+func (w *timeoutWriter) copyTo(writer gin.ResponseWriter) {
+	for name, values := range w.header {
+		writer.Header()[name] = values
+	}
+	writer.WriteHeader(w.status)
+	if w.written {
+		writer.WriteHeaderNow()
+		_, _ = writer.Write(w.body.Bytes())
+		w.body.Reset()
+	}
+}
@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBodyLimit(t *testing.T) {
+	router := gin.New()
+	router.Use(BodyLimit(16))
+	router.POST("/products", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusCreated, string(body))
+	})
+
+	small := httptest.NewRecorder()
+	router.ServeHTTP(small, httptest.NewRequest(http.MethodPost, "/products", strings.NewReader(`{"name":"Oil"}`)))
+
+	// A body without declared length is also limited
+	request := httptest.NewRequest(http.MethodPost, "/products", strings.NewReader(strings.Repeat("a", 17)))
+	request.ContentLength = -1
+	large := httptest.NewRecorder()
+	router.ServeHTTP(large, request)
+
+	// Assertions
+	assert.Equal(t, http.StatusCreated, small.Code)
+	assert.Equal(t, `{"name":"Oil"}`, small.Body.String())
+	assert.Equal(t, http.StatusRequestEntityTooLarge, large.Code)
+}
+
+func TestTimeout(t *testing.T) {
+	router := gin.New()
+	router.Use(Timeout(10 * time.Millisecond))
+	router.GET("/slow", func(c *gin.Context) {
+		<-c.Request.Context().Done()
+	})
+	router.GET("/fast", func(c *gin.Context) {
+		c.String(http.StatusOK, "pong")
+	})
+
+	slow := httptest.NewRecorder()
+	router.ServeHTTP(slow, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	fast := httptest.NewRecorder()
+	router.ServeHTTP(fast, httptest.NewRequest(http.MethodGet, "/fast", nil))
+
+	// Assertions
+	assert.Equal(t, http.StatusRequestTimeout, slow.Code)
+	assert.Equal(t, http.StatusOK, fast.Code)
+}
+
+func TestTimeout_IgnoredContext(t *testing.T) {
+	router := gin.New()
+	router.Use(Timeout(10 * time.Millisecond))
+	router.GET("/slow", func(c *gin.Context) {
+		// The handler never looks at the context, like the services
+		time.Sleep(200 * time.Millisecond)
+		c.String(http.StatusOK, "late")
+	})
+	router.GET("/streamed", func(c *gin.Context) {
+		c.String(http.StatusOK, "first")
+		c.Writer.Flush()
+		time.Sleep(30 * time.Millisecond)
+		c.String(http.StatusOK, " second")
+	})
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	start := time.Now()
+	slow, err := http.Get(server.URL + "/slow")
+	if err != nil {
+		panic(err)
+	}
+	elapsed := time.Since(start)
+	slowBody, _ := io.ReadAll(slow.Body)
+	_ = slow.Body.Close()
+	streamed, err := http.Get(server.URL + "/streamed")
+	if err != nil {
+		panic(err)
+	}
+	streamedBody, _ := io.ReadAll(streamed.Body)
+	_ = streamed.Body.Close()
+
+	// Assertions
+	assert.Equal(t, http.StatusRequestTimeout, slow.StatusCode)
+	assert.Less(t, elapsed, 150*time.Millisecond)
+	assert.Contains(t, string(slowBody), "request_timeout")
+	assert.NotContains(t, string(slowBody), "late")
+	assert.Equal(t, http.StatusOK, streamed.StatusCode)
+	assert.Equal(t, "first second", string(streamedBody))
+}
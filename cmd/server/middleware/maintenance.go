@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"github.com/JoseObreque/go-web/internal/maintenance"
+	"github.com/JoseObreque/go-web/pkg/web"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+/*
+The ReadOnly function returns a middleware that rejects the requests changing data with 503 and a
+Retry-After header during a maintenance, while the reads and the dry runs keep being served.
+*/
+func ReadOnly(mode *maintenance.Mode) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+		if dryRun, _ := web.DryRun(c); dryRun {
+			c.Next()
+			return
+		}
+
+		if err := mode.Check(); err != nil {
+			status := mode.Status()
+			c.Header("Retry-After", strconv.Itoa(status.RetryAfter))
+			c.Abort()
+			web.Error(c, web.WithParams(err, web.Params{"reason": status.Reason}))
+			return
+		}
+		c.Next()
+	}
+}
@@ -1,11 +1,17 @@
 package middleware
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"github.com/JoseObreque/go-web/pkg/logging"
+	"github.com/JoseObreque/go-web/pkg/secrets"
 	"github.com/JoseObreque/go-web/pkg/web"
 	"github.com/gin-gonic/gin"
-	"log"
-	"os"
+	"net/http"
+	"runtime/debug"
+	"strconv"
 	"time"
 )
 
@@ -23,29 +29,138 @@ func TokenValidator() gin.HandlerFunc {
 			return
 		}
 
-		// Check if the token is valid
-		if token != os.Getenv("TOKEN") {
+		// Check if the token is valid, the main token or the API key of the tenant of the request
+		if !secrets.ValidToken(token) && !web.TenantKey(c) {
 			c.Abort()
 			web.Failure(c, 401, ErrInvalidToken)
 			return
 		}
 
+		web.SetActor(c, "token")
 		c.Next()
 	}
 }
 
-func PanicLogger() gin.HandlerFunc {
+// HeaderErrorId is the response header that carries the ID of a server error.
+const HeaderErrorId = "X-Error-Id"
+
+/*
+The Incident struct describes a request that failed on the server side: either a panic recovered by
+the PanicLogger, along with its stack trace, or a response with a 5xx status and its error.
+*/
+type Incident struct {
+	ErrorId string
+	Method  string
+	Path    string
+	Time    time.Time
+	Status  int
+	Panic   interface{}
+	Stack   []byte
+	Err     error
+}
+
+// The Reporter interface is implemented by the services notified of the incidents, like Sentry.
+type Reporter interface {
+	Report(c *gin.Context, incident Incident)
+}
+
+/*
+The PanicLogger function returns a middleware that recovers the panics of the handlers. The panic
+and its stack trace are logged under a generated error ID, which is returned to the client in a 500
+problem details body, and the incident is forwarded to the given reporters.
+*/
+func PanicLogger(reporters ...Reporter) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		defer func() {
 			if err := recover(); err != nil {
-				now := time.Now()
-				log.Printf("HTTP Verb: %s\n", c.Request.Method)
-				log.Printf("URL: %s\n", c.Request.URL.Path)
-				log.Printf("Datetime: %s\n", now.Format("2006-01-02 15:04:05"))
-				log.Printf("Bytes: %b\n", c.Request.ContentLength)
+				incident := Incident{
+					ErrorId: newErrorId(),
+					Method:  c.Request.Method,
+					Path:    c.Request.URL.Path,
+					Time:    time.Now(),
+					Status:  http.StatusInternalServerError,
+					Panic:   err,
+					Stack:   debug.Stack(),
+				}
+				logging.Errorf("panic %s: %s %s at %s (%d bytes): %v\n%s", incident.ErrorId, incident.Method,
+					incident.Path, incident.Time.Format("2006-01-02 15:04:05"), c.Request.ContentLength, err, incident.Stack)
+
+				for _, reporter := range reporters {
+					reporter.Report(c, incident)
+				}
+
+				// The response cannot be replaced once it has been sent, the client is just disconnected
+				if c.Writer.Written() {
+					c.Abort()
+					return
+				}
+				c.Header(HeaderErrorId, incident.ErrorId)
+				web.Problem(c, web.ProblemDetails{
+					Status:   http.StatusInternalServerError,
+					Detail:   "the server failed to process the request, report the error ID if the problem persists",
+					Instance: c.Request.URL.Path,
+					ErrorId:  incident.ErrorId,
+				})
 			}
 		}()
 
 		c.Next()
 	}
 }
+
+/*
+The ErrorReporter function returns a middleware that forwards the responses with a 5xx status to
+the given reporters, along with the last error attached to the request. Panics are reported by the
+PanicLogger instead.
+*/
+func ErrorReporter(reporters ...Reporter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if c.Writer.Status() < http.StatusInternalServerError || len(reporters) == 0 {
+			return
+		}
+
+		incident := Incident{
+			ErrorId: newErrorId(),
+			Method:  c.Request.Method,
+			Path:    c.Request.URL.Path,
+			Time:    time.Now(),
+			Status:  c.Writer.Status(),
+			Err:     errors.New(http.StatusText(c.Writer.Status())),
+		}
+		if last := c.Errors.Last(); last != nil {
+			incident.Err = last.Err
+		}
+		logging.Errorf("error %s: %s %s answered %d: %s\n", incident.ErrorId, incident.Method, incident.Path,
+			incident.Status, incident.Err)
+
+		for _, reporter := range reporters {
+			reporter.Report(c, incident)
+		}
+	}
+}
+
+// Auxiliary function that returns a random ID for a server error.
+func newErrorId() string {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(id)
+}
+
+/*
+The Deprecated function returns a middleware that flags a route as deprecated. It adds the
+Deprecation and Sunset headers to every response, and a Link header pointing to the route that
+replaces it.
+*/
+func Deprecated(sunset time.Time, successor string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Deprecation", "true")
+		c.Header("Sunset", sunset.UTC().Format(http.TimeFormat))
+		c.Header("Link", fmt.Sprintf(`<%s>; rel="successor-version"`, successor))
+
+		c.Next()
+	}
+}
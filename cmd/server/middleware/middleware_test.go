@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/JoseObreque/go-web/pkg/metrics"
+	"github.com/JoseObreque/go-web/pkg/web"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// The recordingReporter struct keeps the reported incidents.
+type recordingReporter struct {
+	incidents []Incident
+}
+
+func (r *recordingReporter) Report(c *gin.Context, incident Incident) {
+	r.incidents = append(r.incidents, incident)
+}
+
+func TestPanicLogger(t *testing.T) {
+	reporter := &recordingReporter{}
+	router := gin.New()
+	router.Use(PanicLogger(reporter))
+	router.GET("/panic", func(c *gin.Context) {
+		panic("oh no!")
+	})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	var problem web.ProblemDetails
+	err := json.Unmarshal(recorder.Body.Bytes(), &problem)
+
+	// Assertions
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.Equal(t, web.MIMEProblemJSON, recorder.Header().Get("Content-Type"))
+	assert.NotEmpty(t, problem.ErrorId)
+	assert.Equal(t, problem.ErrorId, recorder.Header().Get(HeaderErrorId))
+	assert.Len(t, reporter.incidents, 1)
+	assert.Equal(t, problem.ErrorId, reporter.incidents[0].ErrorId)
+	assert.Equal(t, "oh no!", reporter.incidents[0].Panic)
+	assert.Contains(t, string(reporter.incidents[0].Stack), "middleware_test.go")
+}
+
+func TestErrorReporter(t *testing.T) {
+	errStorage := errors.New("storage unavailable")
+	reporter := &recordingReporter{}
+	router := gin.New()
+	router.Use(ErrorReporter(reporter))
+	router.GET("/failing", func(c *gin.Context) {
+		web.Failure(c, http.StatusServiceUnavailable, errStorage)
+	})
+	router.GET("/missing", func(c *gin.Context) {
+		web.Failure(c, http.StatusNotFound, errors.New("not found"))
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/failing", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil))
+
+	// Assertions
+	assert.Len(t, reporter.incidents, 1)
+	assert.Equal(t, http.StatusServiceUnavailable, reporter.incidents[0].Status)
+	assert.ErrorIs(t, reporter.incidents[0].Err, errStorage)
+	assert.Equal(t, "/failing", reporter.incidents[0].Path)
+}
+
+func TestAccessLog(t *testing.T) {
+	latency := metrics.NewLatency(10)
+	router := gin.New()
+	router.Use(AccessLog(latency, time.Millisecond))
+	router.GET("/products/:id", func(c *gin.Context) {
+		time.Sleep(2 * time.Millisecond)
+		c.Status(http.StatusOK)
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/products/1", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/products/2", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil))
+
+	summaries := latency.Summaries()
+
+	// Assertions
+	assert.Equal(t, int64(2), summaries["GET /products/:id"].Count)
+	assert.GreaterOrEqual(t, summaries["GET /products/:id"].P50, 2.0)
+	assert.Equal(t, int64(1), summaries["GET unmatched"].Count)
+}
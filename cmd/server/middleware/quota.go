@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"errors"
+	"github.com/JoseObreque/go-web/internal/usage"
+	"github.com/JoseObreque/go-web/pkg/web"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var ErrQuotaExceeded = errors.New("daily quota exceeded")
+
+/*
+The Quota function returns a middleware that counts the requests made with every credential and
+rejects with 429 the requests beyond its daily quota. The credential is the one the authentication
+accepts: the signature, checked with verify, for the signed requests, and otherwise the known API
+key sent in the token header. The requests with neither are anonymous and are metered per client
+address with the usage.Anonymous quota, or not at all without one. The responses carry the
+X-RateLimit-* headers so clients can pace themselves.
+*/
+func Quota(tracker *usage.Tracker, verify func(c *gin.Context) error) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var current usage.Usage
+		var allowed bool
+		switch {
+		case c.GetHeader(HeaderSignature) != "" && verify(c) == nil:
+			current, allowed = tracker.Allow(usage.Signature)
+		case tracker.Known(c.GetHeader("token")):
+			current, allowed = tracker.Allow(c.GetHeader("token"))
+		default:
+			current, allowed = tracker.AllowAnonymous(c.ClientIP())
+		}
+
+		if current.Quota > 0 {
+			resetAt := tracker.ResetAt()
+			c.Header("X-RateLimit-Limit", strconv.Itoa(current.Quota))
+			c.Header("X-RateLimit-Remaining", strconv.Itoa(*current.Remaining))
+			c.Header("X-RateLimit-Reset", strconv.FormatInt(resetAt.Unix(), 10))
+
+			if !allowed {
+				c.Header("Retry-After", strconv.Itoa(int(time.Until(resetAt).Seconds())+1))
+				c.Abort()
+				web.Failure(c, http.StatusTooManyRequests, ErrQuotaExceeded)
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"github.com/JoseObreque/go-web/internal/usage"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestQuota(t *testing.T) {
+	quotas, err := usage.ParseQuotas("partner-one:1, signature:1, anonymous:1")
+	if err != nil {
+		panic(err)
+	}
+	router := gin.New()
+	router.Use(Quota(usage.NewTracker(quotas), func(c *gin.Context) error {
+		return VerifySignature(c, "secret", time.Minute)
+	}))
+	router.GET("/products", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	get := func(client string, headers map[string]string) int {
+		request := httptest.NewRequest(http.MethodGet, "/products", nil)
+		request.RemoteAddr = client + ":1234"
+		for name, value := range headers {
+			request.Header.Set(name, value)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	signed := map[string]string{
+		HeaderTimestamp: ts,
+		HeaderSignature: "sha256=" + SignRequest("secret", ts, http.MethodGet, "/products", nil),
+	}
+	forged := map[string]string{HeaderTimestamp: ts, HeaderSignature: "sha256=00"}
+
+	// Assertions
+	assert.Equal(t, http.StatusOK, get("10.0.0.1", map[string]string{"token": "partner-one"}))
+	assert.Equal(t, http.StatusTooManyRequests, get("10.0.0.1", map[string]string{"token": "partner-one"}))
+
+	// The signed requests are metered together, wherever they come from
+	assert.Equal(t, http.StatusOK, get("10.0.0.2", signed))
+	assert.Equal(t, http.StatusTooManyRequests, get("10.0.0.3", signed))
+
+	// Unknown tokens and forged signatures are anonymous, metered per client address
+	assert.Equal(t, http.StatusOK, get("10.0.0.4", map[string]string{"token": "random"}))
+	assert.Equal(t, http.StatusTooManyRequests, get("10.0.0.4", forged))
+	assert.Equal(t, http.StatusOK, get("10.0.0.5", nil))
+}
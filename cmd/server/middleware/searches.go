@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"github.com/JoseObreque/go-web/internal/analytics"
+	"github.com/JoseObreque/go-web/pkg/web"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+/*
+The SearchLog function returns a middleware that records the successful text searches (q query
+parameter) of the given route of the main catalog in the log, with the given query parameters as
+their filters and the X-Total-Count header of the response as their number of results; the
+responses without it are not searches. Placed before
+the response cache, it also records the searches answered from the cache; the ones answered with
+304 have no count and are not recorded. Like the views, the searches of the clients sending "DNT: 1"
+or "Sec-GPC: 1" are not recorded.
+*/
+func SearchLog(route string, filters []string, log *analytics.SearchLog) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if c.Request.Method != http.MethodGet || c.FullPath() != route || web.Tenant(c) != "" {
+			return
+		}
+		// The filters matching no product may answer 404, with no results
+		if status := c.Writer.Status(); status != http.StatusOK && status != http.StatusNotFound {
+			return
+		}
+		if c.GetHeader("DNT") == "1" || c.GetHeader("Sec-GPC") == "1" {
+			return
+		}
+		term := strings.TrimSpace(c.Query("q"))
+		results, err := strconv.Atoi(c.Writer.Header().Get(web.HeaderTotalCount))
+		if term == "" || err != nil {
+			return
+		}
+
+		search := analytics.Search{Term: term, Results: results}
+		for _, filter := range filters {
+			if value, ok := c.GetQuery(filter); ok {
+				if search.Filters == nil {
+					search.Filters = make(map[string]string)
+				}
+				search.Filters[filter] = value
+			}
+		}
+		log.Record(search)
+	}
+}
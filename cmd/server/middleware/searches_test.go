@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"github.com/JoseObreque/go-web/internal/analytics"
+	"github.com/JoseObreque/go-web/pkg/web"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSearchLog(t *testing.T) {
+	log := analytics.NewSearchLog(10)
+	router := gin.New()
+	router.Use(SearchLog("/products/search", []string{"priceGt"}, log))
+	router.Use(ResponseCache(time.Minute, func(c *gin.Context) string { return "" }))
+	router.GET("/products/search", func(c *gin.Context) {
+		if c.Query("q") == "bad" {
+			c.JSON(http.StatusBadRequest, gin.H{})
+			return
+		}
+		total := "3"
+		if c.Query("q") == "kombucha" {
+			total = "0"
+		}
+		c.Header(web.HeaderTotalCount, total)
+		c.JSON(http.StatusOK, gin.H{"data": []string{}})
+	})
+
+	get := func(url string, header ...string) {
+		request := httptest.NewRequest(http.MethodGet, url, nil)
+		for i := 0; i+1 < len(header); i += 2 {
+			request.Header.Set(header[i], header[i+1])
+		}
+		router.ServeHTTP(httptest.NewRecorder(), request)
+	}
+
+	// The second search of milk is answered from the cache, and the failed and opted-out ones are not recorded
+	get("/products/search?q=milk&priceGt=10")
+	get("/products/search?q=milk&priceGt=10")
+	get("/products/search?q=kombucha")
+	get("/products/search?priceGt=10")
+	get("/products/search?q=bad")
+	get("/products/search?q=secret", "DNT", "1")
+	summary := log.Summarize(time.Time{}, 10)
+
+	// Assertions
+	assert.Equal(t, 3, summary.Searches)
+	assert.Equal(t, 1, summary.ZeroResultSearches)
+	assert.Len(t, summary.TopQueries, 2)
+	assert.Equal(t, "milk", summary.TopQueries[0].Term)
+	assert.Equal(t, 2, summary.TopQueries[0].Searches)
+	assert.Equal(t, "kombucha", summary.ZeroResultQueries[0].Term)
+}
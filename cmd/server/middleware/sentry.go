@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"github.com/JoseObreque/go-web/pkg/logging"
+	"github.com/getsentry/sentry-go"
+	"github.com/gin-gonic/gin"
+	"strconv"
+	"time"
+)
+
+// The SentryReporter struct forwards the incidents to Sentry.
+type SentryReporter struct {
+	hub *sentry.Hub
+}
+
+/*
+The NewSentryReporter function returns a Reporter that sends the incidents to the Sentry project of
+the given DSN, tagged with the environment (for example "production").
+*/
+func NewSentryReporter(dsn, environment string) (*SentryReporter, error) {
+	client, err := sentry.NewClient(sentry.ClientOptions{
+		Dsn:         dsn,
+		Environment: environment,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &SentryReporter{
+		hub: sentry.NewHub(client, sentry.NewScope()),
+	}, nil
+}
+
+/*
+The Report method sends an incident to Sentry along with the request that caused it, without the
+headers carrying credentials, like the token and the signature. The error ID is attached as a tag,
+so the events can be found from the ID returned to the client.
+*/
+func (r *SentryReporter) Report(c *gin.Context, incident Incident) {
+	request := c.Request.Clone(c.Request.Context())
+	request.Header = logging.WithoutCredentials(c.Request.Header)
+
+	hub := r.hub.Clone()
+	hub.WithScope(func(scope *sentry.Scope) {
+		scope.SetRequest(request)
+		scope.SetTag("error_id", incident.ErrorId)
+		scope.SetTag("status", strconv.Itoa(incident.Status))
+		scope.SetExtra("route", c.FullPath())
+
+		if incident.Panic != nil {
+			scope.SetLevel(sentry.LevelFatal)
+			hub.RecoverWithContext(c.Request.Context(), incident.Panic)
+			return
+		}
+		hub.CaptureException(incident.Err)
+	})
+}
+
+// The Flush method waits up to the given timeout for the pending events to be sent.
+func (r *SentryReporter) Flush(timeout time.Duration) bool {
+	return r.hub.Flush(timeout)
+}
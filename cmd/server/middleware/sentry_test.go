@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"errors"
+	"github.com/getsentry/sentry-go"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// The recordingTransport struct is a Sentry transport keeping the events instead of sending them.
+type recordingTransport struct {
+	events []*sentry.Event
+}
+
+func (t *recordingTransport) Flush(time.Duration) bool       { return true }
+func (t *recordingTransport) Configure(sentry.ClientOptions) {}
+func (t *recordingTransport) SendEvent(event *sentry.Event)  { t.events = append(t.events, event) }
+
+func TestSentryReporter_Report(t *testing.T) {
+	transport := &recordingTransport{}
+	client, err := sentry.NewClient(sentry.ClientOptions{Transport: transport})
+	if err != nil {
+		panic(err)
+	}
+	reporter := &SentryReporter{hub: sentry.NewHub(client, sentry.NewScope())}
+
+	router := gin.New()
+	router.Use(ErrorReporter(reporter))
+	router.POST("/products", func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+		_ = c.Error(errors.New("storage unavailable"))
+		c.AbortWithStatus(http.StatusInternalServerError)
+	})
+	request := httptest.NewRequest(http.MethodPost, "/products", nil)
+	request.Header.Set("token", "12345")
+	request.Header.Set(HeaderSignature, "sha256=abc")
+	request.Header.Set("User-Agent", "tests")
+	router.ServeHTTP(httptest.NewRecorder(), request)
+
+	// Assertions: the credentials are left out of the event, and the request keeps them
+	assert.Len(t, transport.events, 1)
+	assert.NotContains(t, transport.events[0].Request.Headers, "Token")
+	assert.NotContains(t, transport.events[0].Request.Headers, HeaderSignature)
+	assert.Equal(t, "tests", transport.events[0].Request.Headers["User-Agent"])
+	assert.Equal(t, "12345", request.Header.Get("token"))
+}
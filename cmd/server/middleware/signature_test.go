@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"github.com/JoseObreque/go-web/pkg/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTokenOrSignature(t *testing.T) {
+	if err := os.Setenv("TOKEN", "12345"); err != nil {
+		panic(err)
+	}
+
+	router := gin.New()
+	router.Use(TokenOrSignature(config.NewValue("secret"), time.Minute))
+	router.POST("/products", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusCreated, string(body))
+	})
+
+	post := func(body string, headers map[string]string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, "/products", strings.NewReader(body))
+		for name, value := range headers {
+			request.Header.Set(name, value)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+	signed := func(body string, timestamp time.Time) map[string]string {
+		ts := strconv.FormatInt(timestamp.Unix(), 10)
+		return map[string]string{
+			HeaderTimestamp: ts,
+			HeaderSignature: "sha256=" + SignRequest("secret", ts, http.MethodPost, "/products", []byte(body)),
+		}
+	}
+
+	valid := post(`{"name":"Oil"}`, signed(`{"name":"Oil"}`, time.Now()))
+	tampered := post(`{"name":"Rice"}`, signed(`{"name":"Oil"}`, time.Now()))
+	stale := post(`{"name":"Oil"}`, signed(`{"name":"Oil"}`, time.Now().Add(-time.Hour)))
+	token := post(`{"name":"Oil"}`, map[string]string{"token": "12345"})
+
+	// Assertions
+	assert.Equal(t, http.StatusCreated, valid.Code)
+	assert.Equal(t, `{"name":"Oil"}`, valid.Body.String())
+	assert.Equal(t, http.StatusUnauthorized, tampered.Code)
+	assert.Equal(t, http.StatusUnauthorized, stale.Code)
+	assert.Equal(t, http.StatusCreated, token.Code)
+}
+
+func TestSignatureValidator_WithoutSecret(t *testing.T) {
+	router := gin.New()
+	router.Use(SignatureValidator(config.NewValue(""), time.Minute))
+	router.POST("/products", func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+
+	// A request signed with the empty secret, which anyone could compute
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	request := httptest.NewRequest(http.MethodPost, "/products", strings.NewReader(`{}`))
+	request.Header.Set(HeaderTimestamp, ts)
+	request.Header.Set(HeaderSignature, "sha256="+SignRequest("", ts, http.MethodPost, "/products", []byte(`{}`)))
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	// Assertions
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"github.com/JoseObreque/go-web/internal/tenant"
+	"github.com/JoseObreque/go-web/pkg/secrets"
+	"github.com/JoseObreque/go-web/pkg/web"
+	"github.com/gin-gonic/gin"
+)
+
+/*
+The Tenancy function returns a middleware that resolves the tenant of every request, from the tenant
+API key in the token header or the X-Tenant header, for the handlers to serve its catalog. The
+requests naming a tenant they cannot access are rejected.
+*/
+func Tenancy(registry *tenant.Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.GetHeader("token")
+		id, byKey, err := registry.Resolve(token, c.GetHeader(web.HeaderTenant), secrets.ValidToken(token))
+		if err != nil {
+			c.Abort()
+			web.Error(c, err)
+			return
+		}
+
+		web.SetTenant(c, id, byKey)
+		c.Next()
+	}
+}
+
+// The MainCatalog function returns a middleware that rejects the requests of a tenant, for the endpoints only serving the main catalog.
+func MainCatalog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if web.Tenant(c) != "" {
+			c.Abort()
+			web.Error(c, tenant.ErrUnsupported)
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"github.com/JoseObreque/go-web/pkg/web"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+/*
+The ProductViews function returns a middleware that records a view of the product of every
+successful GET request to the given route of the main catalog, whose :id parameter is the product
+ID. Placed before the ETag and the response cache, it also records the views answered with 304 or
+from the cache. The clients asking not to be tracked, with "DNT: 1" or "Sec-GPC: 1", are not
+recorded, and nothing about the client is passed to record.
+*/
+func ProductViews(route string, record func(productId int)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if c.Request.Method != http.MethodGet || c.FullPath() != route || web.Tenant(c) != "" {
+			return
+		}
+		if status := c.Writer.Status(); status != http.StatusOK && status != http.StatusNotModified {
+			return
+		}
+		if c.GetHeader("DNT") == "1" || c.GetHeader("Sec-GPC") == "1" {
+			return
+		}
+		if id, err := strconv.Atoi(c.Param("id")); err == nil {
+			record(id)
+		}
+	}
+}
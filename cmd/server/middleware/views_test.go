@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProductViews(t *testing.T) {
+	var viewed []int
+	router := gin.New()
+	router.Use(ProductViews("/products/:id", func(productId int) { viewed = append(viewed, productId) }))
+	router.Use(ETag(func() string { return "1" }))
+	router.GET("/products", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"data": []string{}})
+	})
+	router.GET("/products/:id", func(c *gin.Context) {
+		if c.Param("id") == "9" {
+			c.JSON(http.StatusNotFound, gin.H{})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": gin.H{}})
+	})
+
+	get := func(url string, header ...string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, url, nil)
+		for i := 0; i+1 < len(header); i += 2 {
+			request.Header.Set(header[i], header[i+1])
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	// The 304 answers are views as well, unlike the listings, the missing products and the opt-outs
+	first := get("/products/1")
+	get("/products/1", "If-None-Match", first.Header().Get("ETag"))
+	get("/products/2")
+	get("/products")
+	get("/products/9")
+	get("/products/2", "DNT", "1")
+	get("/products/2", "Sec-GPC", "1")
+
+	// Assertions
+	assert.Equal(t, []int{1, 1, 2}, viewed)
+}
@@ -0,0 +1,583 @@
+package router
+
+import (
+	docs "github.com/JoseObreque/go-web/cmd/docs"
+	"github.com/JoseObreque/go-web/cmd/server/adminui"
+	"github.com/JoseObreque/go-web/cmd/server/graph"
+	"github.com/JoseObreque/go-web/cmd/server/handler"
+	_ "github.com/JoseObreque/go-web/cmd/server/locales"
+	"github.com/JoseObreque/go-web/cmd/server/middleware"
+	"github.com/JoseObreque/go-web/internal/analytics"
+	"github.com/JoseObreque/go-web/internal/audit"
+	"github.com/JoseObreque/go-web/internal/expiry"
+	"github.com/JoseObreque/go-web/internal/maintenance"
+	"github.com/JoseObreque/go-web/internal/popularity"
+	"github.com/JoseObreque/go-web/internal/pricing"
+	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/JoseObreque/go-web/internal/review"
+	"github.com/JoseObreque/go-web/internal/search"
+	"github.com/JoseObreque/go-web/internal/suggest"
+	"github.com/JoseObreque/go-web/internal/tenant"
+	"github.com/JoseObreque/go-web/internal/undo"
+	"github.com/JoseObreque/go-web/internal/usage"
+	"github.com/JoseObreque/go-web/internal/webhook"
+	"github.com/JoseObreque/go-web/pkg/adapter"
+	"github.com/JoseObreque/go-web/pkg/cache"
+	"github.com/JoseObreque/go-web/pkg/config"
+	"github.com/JoseObreque/go-web/pkg/currency"
+	"github.com/JoseObreque/go-web/pkg/events"
+	"github.com/JoseObreque/go-web/pkg/logging"
+	"github.com/JoseObreque/go-web/pkg/metrics"
+	"github.com/JoseObreque/go-web/pkg/resilience"
+	"github.com/JoseObreque/go-web/pkg/scheduler"
+	"github.com/JoseObreque/go-web/pkg/store"
+	"github.com/JoseObreque/go-web/pkg/web"
+	"github.com/JoseObreque/go-web/pkg/ws"
+	"github.com/gin-gonic/gin"
+	swaggerfiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Maximum difference between the timestamp of a signed request and the server time.
+const signatureTolerance = 5 * time.Minute
+
+// Date after which the deprecated products aliases (/products/all and /products/new) are removed.
+var aliasesSunset = time.Date(2027, time.April, 30, 0, 0, 0, 0, time.UTC)
+
+// Router is the interface that maps every endpoint of the API into a gin engine.
+type Router interface {
+	MapRoutes()
+}
+
+// The Dependencies struct groups the services and components served by the router.
+type Dependencies struct {
+	Products product.Service
+	Webhooks webhook.Service
+	Bus      events.Bus
+	Hub      *ws.Hub
+	Audit    *audit.Log
+	// Recent product changes of every credential. Nil disables the undo endpoint.
+	Undo      *undo.Log
+	Usage     *usage.Tracker
+	Scheduler *scheduler.Scheduler
+	// Time to live of the cached product responses. Zero disables the response cache.
+	CacheTTL time.Duration
+	// Maximum size in bytes of the request bodies. Zero disables the limit.
+	MaxBodySize int64
+	// Time the responses of the idempotent requests are kept. Zero disables the idempotency keys.
+	IdempotencyRetention time.Duration
+	// Deadline of the non streaming requests. Zero disables the timeout.
+	RequestTimeout time.Duration
+	// Networks allowed to reach the mutation and administration endpoints.
+	WriteNetworks middleware.IPRules
+	// Shared secret of the signed requests, which can be rotated. Nil or empty disables the request signing.
+	SigningSecret *config.Value[string]
+	// Whether the protected endpoints only accept signed requests, and no longer the token.
+	RequireSignature bool
+	// Whether the filters matching no product answer 404 (legacy) instead of an empty list.
+	EmptyFilterNotFound bool
+	// Services notified of the panics and the server errors, like Sentry.
+	Reporters []middleware.Reporter
+	// Directory of the backup snapshots. Empty means the snapshots are only downloaded and uploaded.
+	BackupDir string
+	// Reload of the products from the store file, returning whether they changed. Nil disables the reload endpoint.
+	Reload func() (bool, error)
+	// Stats of the store file and its journal. Nil leaves them out of the store stats.
+	StoreFile func() (store.FileStats, error)
+	// Hit and miss counters of the caches of the data layer, by name, reported with the store stats.
+	Caches map[string]func() cache.Stats
+	// Save of the products in the store file, returning its stats. Nil disables the flush endpoint.
+	Flush func() (store.FlushStats, error)
+	// Whether the development endpoints, like the generation of fake products, are available.
+	DevMode bool
+	// Converter of the prices requested in another currency. Nil only allows the own currency of the prices.
+	Currency *currency.Converter
+	// Scheduled prices of the products. Nil disables the scheduled prices endpoints.
+	Prices pricing.Service
+	// Reviews of the products, whose rating is included in the products. Nil disables the reviews endpoints.
+	Reviews review.Service
+	// Subscriptions to the stock about to expire, and their notifier. Nil disables the expiry endpoints.
+	Expiry         expiry.Service
+	ExpiryNotifier *expiry.Notifier
+	// Views of the single products of the main catalog, flushed periodically. Nil disables the view
+	// counts and their report.
+	Views *popularity.Counter
+	// Recent text searches of the main catalog. Nil disables the search log and its report.
+	Searches *analytics.SearchLog
+	// Index of the product names suggested to the search box. Nil disables the suggestions endpoint.
+	Suggestions *suggest.Index
+	// Full-text search engine of the products. Nil disables the text searches (q parameter).
+	Search search.Engine
+	// Read-only maintenance mode of the catalog. A disabled mode is used if nil.
+	Maintenance *maintenance.Mode
+	// Catalogs of the tenants sharing the deployment. Nil serves the main catalog only.
+	Tenants *tenant.Registry
+	// Sandbox serving the load test datasets instead of the catalog. Nil disables the load test endpoints.
+	Sandbox *product.SandboxService
+	// Circuit breakers of the backends the products cannot be served without. While any of them is
+	// open, the products endpoints fail fast with 503.
+	Breakers []*resilience.Breaker
+	// Latency of the requests per route. A new recorder is used if nil.
+	Latency *metrics.Latency
+	// Latency above which the requests are logged with their full context. Zero disables the log.
+	SlowRequestThreshold time.Duration
+	// Logging settings changed at runtime. The settings of the package level log functions are used if nil.
+	Logging *logging.Settings
+	// Redaction of the logged bodies. The credential headers and the default fields are redacted if nil.
+	Redactor *logging.Redactor
+	// Maximum size in bytes of every logged body. Zero logs the first 4KB.
+	LogBodyLimit int
+	// Origins the browsers can call the API from, which can change. Nil disables the CORS headers.
+	CORSOrigins *config.Value[[]string]
+	// Reload of the configuration without restarting the server. Nil disables the reload endpoint.
+	Reloader *config.Reloader
+	// Absolute URL of the public catalog pages, used in the sitemap and the product feed. Empty uses the host of each request.
+	PublicURL string
+}
+
+// The router struct is the implementation of the Router interface.
+type router struct {
+	engine *gin.Engine
+	deps   Dependencies
+	cache  gin.HandlerFunc
+	// Hits and misses of the response cache
+	cacheCounter *cache.Counter
+	timeout      gin.HandlerFunc
+	ipFilter     gin.HandlerFunc
+	auth         gin.HandlerFunc
+	// Network restriction and authentication of the GraphQL mutations, following the ones of the
+	// protected endpoints
+	graphAuth gin.HandlerFunc
+	// Rejection of the changes of the catalog during a maintenance
+	readOnly gin.HandlerFunc
+	// Rejection of the products requests while a backend is down
+	breakers gin.HandlerFunc
+	// Resolution of the tenant of the products requests
+	tenancy gin.HandlerFunc
+	// Rejection of the tenant requests to the endpoints only serving the main catalog
+	mainCatalog gin.HandlerFunc
+	// Idempotency keys of the creations, shared by every API version
+	idempotency gin.HandlerFunc
+}
+
+// The NewRouter function returns a new Router that maps the endpoints of the given dependencies.
+func NewRouter(engine *gin.Engine, deps Dependencies) Router {
+	return &router{
+		engine: engine,
+		deps:   deps,
+	}
+}
+
+/*
+The MapRoutes method registers the general endpoints and mounts the products endpoints once per
+API version, each one under its own /api/vN group and with its own product representation.
+*/
+func (r *router) MapRoutes() {
+	if r.deps.Latency == nil {
+		r.deps.Latency = metrics.NewLatency(metrics.DefaultWindow)
+	}
+	r.engine.Use(middleware.AccessLog(r.deps.Latency, r.deps.SlowRequestThreshold))
+	if r.deps.CORSOrigins != nil {
+		r.engine.Use(middleware.CORS(r.deps.CORSOrigins))
+	}
+	r.engine.Use(middleware.PanicLogger(r.deps.Reporters...), middleware.ErrorReporter(r.deps.Reporters...), middleware.Compression(1024))
+	if r.deps.MaxBodySize > 0 {
+		r.engine.Use(middleware.BodyLimit(r.deps.MaxBodySize))
+	}
+	if r.deps.Logging == nil {
+		r.deps.Logging = logging.Default()
+	}
+	if r.deps.Redactor == nil {
+		r.deps.Redactor = logging.NewRedactor(logging.DefaultFields...)
+	}
+	if r.deps.LogBodyLimit <= 0 {
+		r.deps.LogBodyLimit = 4 << 10
+	}
+	r.engine.Use(middleware.BodyLog(r.deps.Logging, r.deps.Redactor, r.deps.LogBodyLimit))
+	if r.deps.Usage == nil {
+		r.deps.Usage = usage.NewTracker(nil)
+	}
+	// Quotas of the credentials, known the same way the authentication does
+	if r.deps.SigningSecret == nil {
+		r.deps.SigningSecret = config.NewValue("")
+	}
+	r.engine.Use(middleware.Quota(r.deps.Usage, func(c *gin.Context) error {
+		return middleware.VerifySignature(c, r.deps.SigningSecret.Get(), signatureTolerance)
+	}))
+	docs.SwaggerInfo.BasePath = "/api/v1"
+
+	// Response cache shared by every API version
+	r.cache = func(c *gin.Context) { c.Next() }
+	if r.deps.CacheTTL > 0 {
+		r.cacheCounter = &cache.Counter{}
+		r.cache = middleware.CountedResponseCache(r.deps.CacheTTL, r.catalogVersion, r.cacheCounter)
+	}
+
+	// Deadline of the regular requests; streams and websockets are long-lived and have none
+	r.timeout = func(c *gin.Context) { c.Next() }
+	if r.deps.RequestTimeout > 0 {
+		r.timeout = middleware.Timeout(r.deps.RequestTimeout)
+	}
+
+	r.idempotency = func(c *gin.Context) { c.Next() }
+	if r.deps.IdempotencyRetention > 0 {
+		r.idempotency = middleware.Idempotency(r.deps.IdempotencyRetention)
+	}
+
+	// Network restriction of the write access
+	r.ipFilter = func(c *gin.Context) { c.Next() }
+	if !r.deps.WriteNetworks.Empty() {
+		r.ipFilter = middleware.IPFilter(r.deps.WriteNetworks)
+	}
+
+	// Changes of the catalog rejected during a maintenance, the administration endpoints excepted but
+	// for the ones changing the products
+	if r.deps.Maintenance == nil {
+		r.deps.Maintenance = maintenance.NewMode(false, time.Minute)
+	}
+	r.readOnly = middleware.ReadOnly(r.deps.Maintenance)
+
+	// Products requests failing fast while a backend is down, the cached responses excepted
+	r.breakers = middleware.CircuitBreaker(r.deps.Breakers...)
+
+	// Tenant of the products requests, which selects the catalog served
+	r.tenancy = func(c *gin.Context) { c.Next() }
+	r.mainCatalog = r.tenancy
+	if r.deps.Tenants != nil {
+		r.tenancy = middleware.Tenancy(r.deps.Tenants)
+		r.mainCatalog = middleware.MainCatalog()
+	}
+
+	// Authentication of the protected endpoints: signed requests only, or along with the API token.
+	// The secret is read on every request, so the signatures are accepted once it is set, and
+	// requiring them without a secret rejects every request rather than falling back to the token.
+	checkSignature := func(c *gin.Context) error {
+		return middleware.VerifySignature(c, r.deps.SigningSecret.Get(), signatureTolerance)
+	}
+	var checkGraph func(c *gin.Context) error
+	if r.deps.RequireSignature {
+		r.auth = middleware.SignatureValidator(r.deps.SigningSecret, signatureTolerance)
+		checkGraph = checkSignature
+	} else {
+		r.auth = middleware.TokenOrSignature(r.deps.SigningSecret, signatureTolerance)
+		checkGraph = func(c *gin.Context) error {
+			if c.GetHeader(middleware.HeaderSignature) != "" {
+				return checkSignature(c)
+			}
+			return graph.CheckToken(c)
+		}
+	}
+
+	// The GraphQL queries are public, so the network and the credentials only restrict its mutations
+	r.graphAuth = graph.WithAuth(func(c *gin.Context) error {
+		if !r.deps.WriteNetworks.Empty() && !r.deps.WriteNetworks.Allowed(net.ParseIP(c.ClientIP())) {
+			return middleware.ErrForbiddenAddress
+		}
+		return checkGraph(c)
+	})
+
+	// Endpoints independent of the web framework
+	MapPortableRoutes(adapter.NewGinRouter(r.engine))
+
+	// Panic endpoint
+	r.engine.GET("/panic", func(c *gin.Context) {
+		panic("oh no!")
+	})
+
+	// GraphQL endpoint
+	r.engine.POST("/graphql", r.timeout, r.graphAuth, gin.WrapH(graph.NewServer(r.deps.Products, graph.WithReadOnly(r.deps.Maintenance.Check))))
+
+	// Live updates websocket
+	r.engine.GET("/ws", handler.NewWebSocketHandler(r.deps.Hub).Connect())
+
+	// Public catalog pages rendered by the server, with their sitemap and product feed, tagged with
+	// the catalog version like the API reads
+	catalogHandler := handler.NewCatalogHandler(r.deps.Products).WithPublicURL(r.deps.PublicURL)
+	catalogETag := middleware.ETag(r.deps.Products.Version)
+	catalogGroup := r.engine.Group("/catalog")
+	catalogGroup.Use(r.timeout, catalogETag)
+	{
+		catalogGroup.GET("", catalogHandler.List())
+		catalogGroup.GET("/:id", catalogHandler.Get())
+	}
+	r.engine.GET("/sitemap.xml", r.timeout, catalogETag, catalogHandler.Sitemap())
+	r.engine.GET("/feed.xml", r.timeout, catalogETag, catalogHandler.Feed())
+
+	// Administration endpoints
+	adminGroup := r.engine.Group("/admin")
+	adminGroup.Use(r.ipFilter, r.timeout, r.auth)
+	r.mapAdminRoutes(adminGroup)
+
+	// Reports of the activity of the catalog
+	reportHandler := handler.NewReportHandler(r.deps.Products, r.deps.Views).WithSearchLog(r.deps.Searches)
+	reportGroup := r.engine.Group("/reports")
+	reportGroup.Use(r.ipFilter, r.timeout, r.auth)
+	if r.deps.Views != nil {
+		reportGroup.GET("/top-products", reportHandler.TopProducts())
+	}
+	if r.deps.Searches != nil {
+		reportGroup.GET("/searches", reportHandler.Searches())
+	}
+	reportGroup.GET("/possible-duplicates", reportHandler.PossibleDuplicates())
+
+	// Admin web UI, whose pages are public and send the token typed in them to the API
+	uiGroup := r.engine.Group("/admin/ui")
+	uiGroup.Use(r.ipFilter)
+	uiGroup.StaticFS("", adminui.FS())
+
+	// Runtime debugging endpoints, without deadline since the profiles take a while
+	debugGroup := r.engine.Group("/debug")
+	debugGroup.Use(r.ipFilter, r.auth)
+	r.mapDebugRoutes(debugGroup)
+
+	// Version 1 endpoints
+	v1Group := r.engine.Group("/api/v1")
+	v1Group.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
+	r.mapProductRoutes(v1Group, handler.V1)
+
+	// Version 2 endpoints
+	v2Group := r.engine.Group("/api/v2")
+	r.mapProductRoutes(v2Group, handler.V2)
+}
+
+/*
+The MapPortableRoutes function registers the portable endpoints in the given router. They are served
+by gin along with the rest of the endpoints, and natively by a net/http router placed in front of
+gin. Only the public endpoints needing none of the middlewares of gin belong here, which for now is
+just the ping one; the other handlers independent of the framework are mounted on gin, behind its
+middlewares.
+*/
+func MapPortableRoutes(router adapter.Router) {
+	router.Handle(http.MethodGet, "/ping", handler.Ping())
+}
+
+/*
+The catalogVersion method returns the version of the catalog read by a request, so unchanged
+listings are answered with 304 and from the response cache: the one of the products, along with
+the scheduled prices and reviews ones since they are returned too, or the one of its tenant, which
+has a catalog of its own.
+*/
+func (r *router) catalogVersion(c *gin.Context) string {
+	if id := web.Tenant(c); id != "" {
+		service, err := r.deps.Tenants.Service(id)
+		if err != nil {
+			return ""
+		}
+		return service.Version()
+	}
+
+	version := r.deps.Products.Version()
+	if r.deps.Prices != nil {
+		version += "." + r.deps.Prices.Version()
+	}
+	if r.deps.Reviews != nil {
+		version += "." + r.deps.Reviews.Version()
+	}
+	return version
+}
+
+// The mapProductRoutes method registers the products endpoints of an API version in the given group.
+func (r *router) mapProductRoutes(group *gin.RouterGroup, version handler.APIVersion) {
+	productHandler := handler.NewVersionedProductHandler(r.deps.Products, version).
+		WithEmptyFilterNotFound(r.deps.EmptyFilterNotFound).
+		WithCurrencyConverter(r.deps.Currency).
+		WithSearchEngine(r.deps.Search).
+		WithTenants(r.deps.Tenants)
+	eventHandler := handler.NewEventHandler(r.deps.Bus, version)
+
+	if r.deps.Suggestions != nil {
+		productHandler.WithViewRecorder(r.deps.Suggestions)
+	}
+	if r.deps.Prices != nil {
+		productHandler.WithPriceSchedule(r.deps.Prices)
+	}
+	if r.deps.Reviews != nil {
+		productHandler.WithReviews(r.deps.Reviews)
+	}
+
+	// The views of the single products and the searches are recorded before the ETag and the response
+	// cache, which answer most of them
+	productGroup := group.Group("/products")
+	views := func(c *gin.Context) { c.Next() }
+	if r.deps.Views != nil {
+		views = middleware.ProductViews(productGroup.BasePath()+"/:id", r.deps.Views.View)
+	}
+	searches := func(c *gin.Context) { c.Next() }
+	if r.deps.Searches != nil {
+		searches = middleware.SearchLog(productGroup.BasePath()+"/search", []string{"priceGt"}, r.deps.Searches)
+	}
+	productGroup.Use(r.tenancy, views, searches, r.timeout, middleware.CatalogETag(r.catalogVersion), r.cache, r.breakers)
+	{
+		productGroup.GET("", productHandler.GetAll())
+		productGroup.GET("/all", middleware.Deprecated(aliasesSunset, group.BasePath()+"/products"), productHandler.GetAll())
+		productGroup.GET("/:id", productHandler.GetById())
+		productGroup.GET("/:id/translations", productHandler.GetTranslations())
+		productGroup.GET("/:id/nutrition", productHandler.GetNutrition())
+		productGroup.GET("/:id/lots", productHandler.GetLots())
+		productGroup.GET("/:id/related", productHandler.GetRelated())
+		productGroup.GET("/search", productHandler.GetByPriceGt())
+		productGroup.GET("/export", productHandler.Export())
+		productGroup.GET("/dietary-tags", productHandler.GetDietaryTags())
+		if r.deps.Suggestions != nil {
+			productGroup.GET("/suggest", r.mainCatalog, handler.NewSuggestHandler(r.deps.Suggestions).Suggest())
+		}
+	}
+
+	streamGroup := group.Group("/products")
+	streamGroup.Use(r.tenancy)
+	{
+		streamGroup.GET("/stream", productHandler.Stream())
+		streamGroup.GET("/events", r.mainCatalog, eventHandler.Stream())
+		streamGroup.POST("/labels", r.timeout, productHandler.Labels())
+	}
+
+	protectedProductGroup := group.Group("/products")
+	protectedProductGroup.Use(r.ipFilter, r.tenancy, r.timeout, r.auth, r.readOnly, r.cache, r.breakers)
+	{
+		protectedProductGroup.POST("", r.idempotency, productHandler.Create())
+		protectedProductGroup.POST("/new", middleware.Deprecated(aliasesSunset, group.BasePath()+"/products"), r.idempotency, productHandler.Create())
+		protectedProductGroup.POST("/:id/clone", r.idempotency, productHandler.Clone())
+		protectedProductGroup.POST("/:id/merge/:otherId", r.idempotency, productHandler.Merge())
+		protectedProductGroup.PATCH("", productHandler.BatchPatch())
+		protectedProductGroup.PUT("/:id", productHandler.FullUpdate())
+		protectedProductGroup.PATCH("/:id", productHandler.PartialUpdate())
+		protectedProductGroup.DELETE("/:id", productHandler.Delete())
+		protectedProductGroup.PUT("/:id/translations/:locale", productHandler.PutTranslation())
+		protectedProductGroup.DELETE("/:id/translations/:locale", productHandler.DeleteTranslation())
+		protectedProductGroup.PUT("/:id/nutrition", productHandler.PutNutrition())
+		protectedProductGroup.PUT("/:id/lots", productHandler.PutLots())
+	}
+
+	if r.deps.Prices != nil {
+		priceHandler := handler.NewPriceHandler(r.deps.Prices)
+		productGroup.GET("/:id/prices", r.mainCatalog, priceHandler.GetAll())
+		protectedProductGroup.POST("/:id/prices", r.mainCatalog, priceHandler.Create())
+		protectedProductGroup.DELETE("/:id/prices/:priceId", r.mainCatalog, priceHandler.Delete())
+	}
+
+	// The reviews are posted by the customers, so they need no token, but not during a maintenance
+	if r.deps.Reviews != nil {
+		reviewHandler := handler.NewReviewHandler(r.deps.Reviews)
+		productGroup.GET("/:id/reviews", r.mainCatalog, reviewHandler.GetAll())
+		reviewGroup := group.Group("/products")
+		reviewGroup.Use(r.tenancy, r.timeout, r.readOnly, r.cache, r.breakers)
+		reviewGroup.POST("/:id/reviews", r.mainCatalog, r.idempotency, reviewHandler.Create())
+	}
+}
+
+// The mapAdminRoutes method registers the administration endpoints in the given group.
+func (r *router) mapAdminRoutes(group *gin.RouterGroup) {
+	webhookHandler := handler.NewWebhookHandler(r.deps.Webhooks)
+	webhookGroup := group.Group("/webhooks")
+	{
+		webhookGroup.GET("", webhookHandler.GetAll())
+		webhookGroup.POST("", webhookHandler.Create())
+		webhookGroup.DELETE("/:id", webhookHandler.Delete())
+	}
+
+	if r.deps.Expiry != nil {
+		expiryHandler := handler.NewExpiryHandler(r.deps.Expiry, r.deps.ExpiryNotifier)
+		expiryGroup := group.Group("/expiry-subscriptions")
+		{
+			expiryGroup.GET("", expiryHandler.GetAll())
+			expiryGroup.POST("", expiryHandler.Create())
+			expiryGroup.PUT("/:id", expiryHandler.Update())
+			expiryGroup.DELETE("/:id", expiryHandler.Delete())
+		}
+		group.POST("/tasks/notify-expiring", expiryHandler.Check())
+	}
+
+	auditHandler := handler.NewAuditHandler(r.deps.Audit)
+	group.GET("/audit", auditHandler.GetAll())
+
+	if r.deps.Undo != nil {
+		undoHandler := handler.NewUndoHandler(r.deps.Undo)
+		group.POST("/undo", r.readOnly, undoHandler.Undo())
+	}
+
+	// The moderations go through the response cache, which busts the cached ratings
+	if r.deps.Reviews != nil {
+		reviewHandler := handler.NewReviewHandler(r.deps.Reviews)
+		group.GET("/reviews", reviewHandler.Search())
+		group.PATCH("/reviews/:id", r.cache, reviewHandler.Moderate())
+	}
+
+	maintenanceHandler := handler.NewMaintenanceHandler(r.deps.Maintenance)
+	group.GET("/maintenance", maintenanceHandler.Status())
+	group.POST("/maintenance", maintenanceHandler.Update())
+
+	logLevelHandler := handler.NewLogLevelHandler(r.deps.Logging)
+	group.GET("/log-level", logLevelHandler.Get())
+	group.PUT("/log-level", logLevelHandler.Update())
+
+	usageHandler := handler.NewUsageHandler(r.deps.Usage)
+	group.GET("/usage", usageHandler.GetAll())
+
+	jobHandler := handler.NewJobHandler(r.deps.Scheduler)
+	group.GET("/jobs", jobHandler.GetAll())
+
+	backupHandler := handler.NewBackupHandler(r.deps.Products, r.deps.BackupDir)
+	group.POST("/backup", backupHandler.Backup())
+	group.POST("/restore", backupHandler.Restore())
+
+	if r.deps.Reload != nil {
+		reloadHandler := handler.NewReloadHandler(r.deps.Products, r.deps.Reload)
+		group.POST("/reload", reloadHandler.Reload())
+	}
+
+	if r.deps.Reloader != nil {
+		configHandler := handler.NewConfigHandler(r.deps.Reloader)
+		group.POST("/reload-config", configHandler.Reload())
+	}
+
+	if r.deps.Flush != nil {
+		flushHandler := handler.NewFlushHandler(r.deps.Flush)
+		group.POST("/flush", adapter.Gin(flushHandler.Flush()))
+	}
+
+	storeStatsHandler := handler.NewStoreStatsHandler(r.deps.Products, r.deps.StoreFile)
+	if r.deps.Suggestions != nil {
+		storeStatsHandler.WithIndex("suggestions", r.deps.Suggestions.Size)
+	}
+	if sizer, ok := r.deps.Search.(search.Sizer); ok {
+		storeStatsHandler.WithIndex("search", sizer.Size)
+	}
+	if r.cacheCounter != nil {
+		storeStatsHandler.WithCache("responses", r.cacheCounter.Stats)
+	}
+	for name, stats := range r.deps.Caches {
+		storeStatsHandler.WithCache(name, stats)
+	}
+	group.GET("/store/stats", adapter.Gin(storeStatsHandler.Stats()))
+
+	codeFormatHandler := handler.NewCodeFormatHandler()
+	group.GET("/settings/code-format", codeFormatHandler.Get())
+	group.PUT("/settings/code-format", codeFormatHandler.Update())
+
+	taskHandler := handler.NewTaskHandler(r.deps.Products)
+	group.POST("/tasks/unpublish-expired", r.readOnly, taskHandler.UnpublishExpired())
+	group.POST("/tasks/apply-publish-windows", r.readOnly, taskHandler.ApplyPublishWindows())
+
+	if r.deps.DevMode {
+		seedHandler := handler.NewSeedHandler(r.deps.Products)
+		group.POST("/seed", r.readOnly, seedHandler.Seed())
+	}
+
+	if r.deps.Sandbox != nil {
+		loadTestHandler := handler.NewLoadTestHandler(r.deps.Sandbox)
+		group.GET("/loadtest", loadTestHandler.Status())
+		group.POST("/loadtest", loadTestHandler.Enable())
+		group.DELETE("/loadtest", loadTestHandler.Disable())
+	}
+}
+
+// The mapDebugRoutes method registers the pprof profiles and the expvar variables in the given group.
+func (r *router) mapDebugRoutes(group *gin.RouterGroup) {
+	debugHandler := handler.NewDebugHandler(r.deps.Products, r.deps.Latency)
+	group.GET("/vars", debugHandler.Vars())
+	group.GET("/pprof/*name", debugHandler.Profile())
+	group.POST("/pprof/*name", debugHandler.Profile())
+}
@@ -0,0 +1,275 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/JoseObreque/go-web/pkg/pb"
+	"github.com/JoseObreque/go-web/pkg/resilience"
+	"github.com/JoseObreque/go-web/pkg/secrets"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"net"
+)
+
+// Full method names of the RPCs that modify products and therefore require a valid token.
+var protectedMethods = map[string]bool{
+	pb.ProductService_Create_FullMethodName: true,
+	pb.ProductService_Update_FullMethodName: true,
+	pb.ProductService_Delete_FullMethodName: true,
+}
+
+/*
+The ProductServer struct implements the ProductService gRPC service. It reuses the same
+product.Service used by the HTTP handlers, so both transports share the business rules.
+*/
+type ProductServer struct {
+	pb.UnimplementedProductServiceServer
+	service product.Service
+}
+
+// The NewProductServer function returns a new ProductServer backed by the given service.
+func NewProductServer(service product.Service) *ProductServer {
+	return &ProductServer{
+		service: service,
+	}
+}
+
+/*
+The NewServer function returns a gRPC server with the ProductService registered. Mutations
+must carry the API token in the "token" metadata key, just like the HTTP endpoints. The given
+interceptors run after the token check.
+*/
+func NewServer(service product.Service, interceptors ...grpc.UnaryServerInterceptor) *grpc.Server {
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(append([]grpc.UnaryServerInterceptor{TokenInterceptor}, interceptors...)...))
+	pb.RegisterProductServiceServer(server, NewProductServer(service))
+	return server
+}
+
+// The Get method returns a single product by its ID.
+func (s *ProductServer) Get(ctx context.Context, request *pb.GetRequest) (*pb.Product, error) {
+	targetProduct, err := s.service.GetById(int(request.GetId()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProto(targetProduct), nil
+}
+
+// The List method returns every product, or only those with a price greater than price_gt.
+func (s *ProductServer) List(ctx context.Context, request *pb.ListRequest) (*pb.ListResponse, error) {
+	var products []domain.Product
+	var err error
+	if request.GetPriceGt() > 0 {
+		products, err = s.service.GetByPriceGt(request.GetPriceGt())
+	} else {
+		products, err = s.service.List()
+	}
+	// An empty result is not an error for a list RPC
+	if err != nil && !errors.Is(err, product.ErrNoProducts) {
+		return nil, toStatus(err)
+	}
+
+	response := &pb.ListResponse{
+		Products: make([]*pb.Product, len(products)),
+	}
+	for i, p := range products {
+		response.Products[i] = toProto(p)
+	}
+	return response, nil
+}
+
+// The Create method validates and stores a new product.
+func (s *ProductServer) Create(ctx context.Context, request *pb.CreateRequest) (*pb.Product, error) {
+	if request.GetProduct() == nil {
+		return nil, status.Error(codes.InvalidArgument, "missing product")
+	}
+
+	newProduct := fromProto(request.GetProduct())
+	if err := domain.ValidateExpiration(newProduct.Expiration); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	createdProduct, err := s.service.Create(newProduct)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProto(createdProduct), nil
+}
+
+/*
+The Update method validates and replaces the fields of the message of an existing product. The
+message only carries some of the fields of the products, so the update is a partial one and the
+other fields, like the description or the lots, are kept. The status only changes if the
+is_published flag does, so an archived product stays archived unless it is published.
+*/
+func (s *ProductServer) Update(ctx context.Context, request *pb.UpdateRequest) (*pb.Product, error) {
+	if request.GetProduct() == nil {
+		return nil, status.Error(codes.InvalidArgument, "missing product")
+	}
+
+	id := int(request.GetId())
+	changes := request.GetProduct()
+	if err := domain.ValidateExpiration(changes.GetExpiration()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	previous, err := s.service.GetById(id)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	partialUpdate := patchFromProto(changes)
+	if changes.GetIsPublished() != previous.Published() {
+		productStatus := domain.StatusOf(changes.GetIsPublished())
+		partialUpdate.Status = &productStatus
+	}
+	updatedProduct, err := s.service.Patch(id, partialUpdate)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProto(updatedProduct), nil
+}
+
+// The Delete method permanently removes a product.
+func (s *ProductServer) Delete(ctx context.Context, request *pb.DeleteRequest) (*pb.DeleteResponse, error) {
+	if err := s.service.Delete(int(request.GetId())); err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.DeleteResponse{}, nil
+}
+
+/*
+The TokenInterceptor function is a unary interceptor that rejects calls to protected methods
+whose "token" metadata does not match the TOKEN environment variable.
+*/
+func TokenInterceptor(ctx context.Context, request interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if !protectedMethods[info.FullMethod] {
+		return handler(ctx, request)
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	tokens := md.Get("token")
+	if len(tokens) == 0 || !secrets.ValidToken(tokens[0]) {
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+
+	return handler(ctx, request)
+}
+
+/*
+The NetworkInterceptor function returns a unary interceptor that rejects with PermissionDenied the
+calls to the protected methods from the addresses that are not allowed, like the networks allowed
+to reach the HTTP mutations. The address is the one of the peer, since no proxy is trusted in front
+of the gRPC server.
+*/
+func NetworkInterceptor(allowed func(ip net.IP) bool) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, request interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !protectedMethods[info.FullMethod] {
+			return handler(ctx, request)
+		}
+
+		var ip net.IP
+		if client, ok := peer.FromContext(ctx); ok && client.Addr != nil {
+			host, _, err := net.SplitHostPort(client.Addr.String())
+			if err != nil {
+				host = client.Addr.String()
+			}
+			ip = net.ParseIP(host)
+		}
+		if !allowed(ip) {
+			return nil, status.Error(codes.PermissionDenied, "client address not allowed")
+		}
+		return handler(ctx, request)
+	}
+}
+
+/*
+The SignedOnlyInterceptor function is a unary interceptor that rejects the calls to the protected
+methods with Unauthenticated. The gRPC calls cannot be signed like the HTTP requests, so it is used
+when the signed requests are mandatory, and the token alone must not authorize any mutation.
+*/
+func SignedOnlyInterceptor(ctx context.Context, request interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if protectedMethods[info.FullMethod] {
+		return nil, status.Error(codes.Unauthenticated, "the mutations require signed requests, only available over HTTP")
+	}
+	return handler(ctx, request)
+}
+
+/*
+The ReadOnlyInterceptor function returns a unary interceptor that rejects the calls to the protected
+methods, the mutations, with Unavailable while readOnly returns an error, like during a maintenance.
+*/
+func ReadOnlyInterceptor(readOnly func() error) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, request interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if protectedMethods[info.FullMethod] {
+			if err := readOnly(); err != nil {
+				return nil, status.Error(codes.Unavailable, err.Error())
+			}
+		}
+		return handler(ctx, request)
+	}
+}
+
+// Auxiliary function that maps the service errors into gRPC status errors.
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, product.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, product.ErrInvalidCode):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, domain.ErrInvalidProduct):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, product.ErrUnavailable), errors.Is(err, resilience.ErrOpen):
+		return status.Error(codes.Unavailable, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+/*
+Auxiliary function that converts a domain product into its protobuf message. The message keeps the
+is_published flag the status replaced, so only the published products are published in it.
+*/
+func toProto(p domain.Product) *pb.Product {
+	return &pb.Product{
+		Id:          int64(p.Id),
+		Name:        p.Name,
+		Quantity:    int64(p.Quantity),
+		CodeValue:   p.CodeValue,
+		IsPublished: p.Published(),
+		Expiration:  p.Expiration,
+		Price:       p.Price,
+		Category:    p.Category,
+	}
+}
+
+// Auxiliary function that converts a protobuf message into a partial update of its fields but the is_published flag.
+func patchFromProto(p *pb.Product) domain.ProductRequest {
+	name, codeValue, expiration, category := p.GetName(), p.GetCodeValue(), p.GetExpiration(), p.GetCategory()
+	quantity, price := int(p.GetQuantity()), p.GetPrice()
+	return domain.ProductRequest{
+		Name:       &name,
+		Quantity:   &quantity,
+		CodeValue:  &codeValue,
+		Expiration: &expiration,
+		Price:      &price,
+		Category:   &category,
+	}
+}
+
+// Auxiliary function that converts a protobuf message into a domain product, published or draft.
+func fromProto(p *pb.Product) domain.Product {
+	return domain.Product{
+		Id:         int(p.GetId()),
+		Name:       p.GetName(),
+		Quantity:   int(p.GetQuantity()),
+		CodeValue:  p.GetCodeValue(),
+		Status:     domain.StatusOf(p.GetIsPublished()),
+		Expiration: p.GetExpiration(),
+		Price:      p.GetPrice(),
+		Category:   p.GetCategory(),
+	}
+}
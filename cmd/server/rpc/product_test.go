@@ -0,0 +1,171 @@
+package rpc
+
+import (
+	"context"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/JoseObreque/go-web/pkg/pb"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"net"
+	"os"
+	"testing"
+	"time"
+)
+
+func createClientForTestProducts(t *testing.T, interceptors ...grpc.UnaryServerInterceptor) pb.ProductServiceClient {
+	// Token settings
+	if err := os.Setenv("TOKEN", "12345"); err != nil {
+		panic(err)
+	}
+
+	// Create a service with a couple of products
+	repository := product.NewRepository([]domain.Product{
+		{Id: 1, Name: "Oil", Quantity: 10, CodeValue: "A1", Status: domain.StatusPublished, Expiration: "15/12/2030", Price: 71.42},
+		{Id: 2, Name: "Pineapple", Quantity: 20, CodeValue: "B2", Status: domain.StatusPublished, Expiration: "09/08/2030", Price: 352.79},
+	})
+	return createClientForTestService(t, product.NewService(repository, nil), interceptors...)
+}
+
+func createClientForTestService(t *testing.T, service product.Service, interceptors ...grpc.UnaryServerInterceptor) pb.ProductServiceClient {
+	// Serve the gRPC server over an in-memory listener
+	listener := bufconn.Listen(1024 * 1024)
+	server := NewServer(service, interceptors...)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial(
+		"bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		panic(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return pb.NewProductServiceClient(conn)
+}
+
+func TestProductServer_Get(t *testing.T) {
+	client := createClientForTestProducts(t)
+
+	t.Run("OK", func(t *testing.T) {
+		response, err := client.Get(context.Background(), &pb.GetRequest{Id: 1})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Oil", response.GetName())
+	})
+	t.Run("Not found", func(t *testing.T) {
+		_, err := client.Get(context.Background(), &pb.GetRequest{Id: 9999})
+
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+}
+
+func TestProductServer_List(t *testing.T) {
+	client := createClientForTestProducts(t)
+
+	response, err := client.List(context.Background(), &pb.ListRequest{PriceGt: 100})
+
+	assert.NoError(t, err)
+	assert.Len(t, response.GetProducts(), 1)
+	assert.Equal(t, int64(2), response.GetProducts()[0].GetId())
+}
+
+func TestProductServer_Create(t *testing.T) {
+	client := createClientForTestProducts(t)
+	request := &pb.CreateRequest{
+		Product: &pb.Product{Name: "New Product", Quantity: 100, CodeValue: "NewCode123", Expiration: "25/10/2030", Price: 900},
+	}
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		_, err := client.Create(context.Background(), request)
+
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+	t.Run("OK", func(t *testing.T) {
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "token", "12345")
+		response, err := client.Create(ctx, request)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "NewCode123", response.GetCodeValue())
+	})
+}
+
+func TestSignedOnlyInterceptor(t *testing.T) {
+	client := createClientForTestProducts(t, SignedOnlyInterceptor)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "token", "12345")
+
+	_, errDelete := client.Delete(ctx, &pb.DeleteRequest{Id: 1})
+	response, errGet := client.Get(ctx, &pb.GetRequest{Id: 1})
+
+	// Assertions: the token no longer authorizes the mutations, and the queries are still answered
+	assert.Equal(t, codes.Unauthenticated, status.Code(errDelete))
+	assert.NoError(t, errGet)
+	assert.Equal(t, "Oil", response.GetName())
+}
+
+func TestProductServer_Update(t *testing.T) {
+	if err := os.Setenv("TOKEN", "12345"); err != nil {
+		panic(err)
+	}
+	minTemperature, maxTemperature := 2.0, 6.0
+	publishFrom := time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)
+	service := product.NewService(product.NewRepository([]domain.Product{
+		{Id: 1, Name: "Cheese", Quantity: 10, CodeValue: "A1", Status: domain.StatusArchived, Expiration: "15/12/2030",
+			Price: 10, Currency: "EUR", Description: "Aged cheese", Unit: domain.UnitKilogram, PackSize: 2,
+			Storage: domain.StorageChilled, MinTemperature: &minTemperature, MaxTemperature: &maxTemperature,
+			PublishFrom: &publishFrom, Lots: []domain.Lot{{Number: "L1", Quantity: 10, Expiration: "01/06/2030"}}},
+	}), nil)
+	client := createClientForTestService(t, service)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "token", "12345")
+
+	response, err := client.Update(ctx, &pb.UpdateRequest{Id: 1, Product: &pb.Product{
+		Name: "Old cheese", Quantity: 10, CodeValue: "A1", Expiration: "15/12/2030", Price: 12, Category: "dairy",
+	}})
+	stored, _ := service.GetById(1)
+
+	// Assertions: the fields of the message change, and the ones it does not carry are kept
+	assert.NoError(t, err)
+	assert.Equal(t, "Old cheese", response.GetName())
+	assert.Equal(t, "Old cheese", stored.Name)
+	assert.Equal(t, 12.0, stored.Price)
+	assert.Equal(t, "dairy", stored.Category)
+	assert.Equal(t, domain.StatusArchived, stored.Status)
+	assert.Equal(t, "EUR", stored.Currency)
+	assert.Equal(t, "Aged cheese", stored.Description)
+	assert.Equal(t, domain.UnitKilogram, stored.Unit)
+	assert.Equal(t, 2.0, stored.PackSize)
+	assert.Equal(t, domain.StorageChilled, stored.Storage)
+	assert.Equal(t, &minTemperature, stored.MinTemperature)
+	assert.Equal(t, &maxTemperature, stored.MaxTemperature)
+	assert.Equal(t, &publishFrom, stored.PublishFrom)
+	assert.Len(t, stored.Lots, 1)
+}
+
+func TestNetworkInterceptor(t *testing.T) {
+	var seen net.IP
+	denied := createClientForTestProducts(t, NetworkInterceptor(func(ip net.IP) bool {
+		seen = ip
+		return false
+	}))
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "token", "12345")
+
+	_, errDelete := denied.Delete(ctx, &pb.DeleteRequest{Id: 1})
+	_, errGet := denied.Get(ctx, &pb.GetRequest{Id: 1})
+
+	// Assertions: only the mutations are restricted
+	assert.Equal(t, codes.PermissionDenied, status.Code(errDelete))
+	assert.NoError(t, errGet)
+	assert.Nil(t, seen)
+}
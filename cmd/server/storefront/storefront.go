@@ -0,0 +1,22 @@
+/*
+Package storefront contains the html/template templates of the public catalog pages, embedded in
+the binary: the paginated list of the published products, the page of a single product and the
+error page.
+*/
+package storefront
+
+import (
+	"embed"
+	"html/template"
+)
+
+//go:embed templates
+var files embed.FS
+
+/*
+The Templates function returns the templates of the catalog pages, named after their files:
+catalog.html, product.html and error.html. They share the layout of layout.html.
+*/
+func Templates() *template.Template {
+	return template.Must(template.ParseFS(files, "templates/*.html"))
+}
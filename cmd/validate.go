@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/JoseObreque/go-web/pkg/store"
+	"github.com/spf13/cobra"
+)
+
+/*
+The newValidateCommand function returns the command that checks the products of a JSON file with
+the validation run by the server on startup, and fails if any product has issues.
+*/
+func newValidateCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate [products.json]",
+		Short: "Validate a products file",
+		Long: `Check every product of a JSON file, by default the store file with the changes not saved yet, and
+print the issues found. The command fails if any product has issues.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var products []domain.Product
+			var err error
+			if len(args) == 1 {
+				products, err = store.NewJsonStore(args[0], storeOptions()...).Load()
+			} else {
+				products, err = loadStoreProducts()
+			}
+			if err != nil {
+				return err
+			}
+
+			report := product.ValidateCatalog(products)
+			if err = report.Err(); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%d products are valid\n", report.Total)
+			return nil
+		},
+	}
+}
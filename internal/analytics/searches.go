@@ -0,0 +1,151 @@
+package analytics
+
+import (
+	"github.com/JoseObreque/go-web/pkg/fold"
+	"sort"
+	"sync"
+	"time"
+)
+
+/*
+The Search struct is a search made by a customer. Nothing about the customer is recorded.
+
+	Term (string): Searched text, as typed. Example: "pineapple".
+	Filters (map[string]string): Other criteria of the search, by query parameter. Example: {"priceGt": "100"}.
+	Results (int): Number of products found. Example: 3.
+	Timestamp (time.Time): Moment of the search.
+*/
+type Search struct {
+	Term      string            `json:"term" example:"pineapple"`
+	Filters   map[string]string `json:"filters,omitempty"`
+	Results   int               `json:"results" example:"3"`
+	Timestamp time.Time         `json:"timestamp"`
+}
+
+/*
+The QueryStats struct summarizes the searches of a term. Terms differing only in case, accents or
+spacing are the same query.
+
+	Term (string): Comparable form of the term: lowercase, without accents and single spaced. Example: "pineapple".
+	Searches (int): Number of searches of the term. Example: 12.
+	ZeroResults (int): Number of those searches that found no product. Example: 2.
+	LastSearched (time.Time): Moment of the last search of the term.
+*/
+type QueryStats struct {
+	Term         string    `json:"term" example:"pineapple"`
+	Searches     int       `json:"searches" example:"12"`
+	ZeroResults  int       `json:"zero_results" example:"2"`
+	LastSearched time.Time `json:"last_searched"`
+}
+
+/*
+The Summary struct summarizes the searches of a period: the most searched queries, and the queries
+whose searches found no product, most searched first, which are the products the customers cannot
+find.
+*/
+type Summary struct {
+	Since              time.Time    `json:"since"`
+	Searches           int          `json:"searches" example:"120"`
+	ZeroResultSearches int          `json:"zero_result_searches" example:"9"`
+	TopQueries         []QueryStats `json:"top_queries"`
+	ZeroResultQueries  []QueryStats `json:"zero_result_queries"`
+}
+
+/*
+The SearchLog struct records the searches of the customers. The most recent ones are kept in
+memory only, so the log never grows without bound and the searched texts are gone on a restart.
+*/
+type SearchLog struct {
+	mu          sync.RWMutex
+	searches    []Search
+	maxSearches int
+	nowFunc     func() time.Time
+}
+
+// The NewSearchLog function returns a new search log that keeps up to maxSearches searches in memory.
+func NewSearchLog(maxSearches int) *SearchLog {
+	return &SearchLog{
+		maxSearches: maxSearches,
+		nowFunc:     time.Now,
+	}
+}
+
+// The Record method stores a search, made now if it has no timestamp. The searches of blank terms are ignored.
+func (l *SearchLog) Record(search Search) {
+	if fold.String(search.Term) == "" {
+		return
+	}
+	if search.Timestamp.IsZero() {
+		search.Timestamp = l.nowFunc()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.searches = append(l.searches, search)
+	if l.maxSearches > 0 && len(l.searches) > l.maxSearches {
+		l.searches = l.searches[len(l.searches)-l.maxSearches:]
+	}
+}
+
+/*
+The Summarize method summarizes the searches kept that were made since the given moment, with up to
+limit queries in each list. A zero since summarizes every search kept.
+*/
+func (l *SearchLog) Summarize(since time.Time, limit int) Summary {
+	l.mu.RLock()
+	stats := make(map[string]*QueryStats)
+	summary := Summary{Since: since}
+	for _, search := range l.searches {
+		if search.Timestamp.Before(since) {
+			continue
+		}
+		if summary.Since.IsZero() {
+			summary.Since = search.Timestamp
+		}
+
+		term := fold.String(search.Term)
+		query, ok := stats[term]
+		if !ok {
+			query = &QueryStats{Term: term}
+			stats[term] = query
+		}
+		query.Searches++
+		summary.Searches++
+		if search.Results == 0 {
+			query.ZeroResults++
+			summary.ZeroResultSearches++
+		}
+		if search.Timestamp.After(query.LastSearched) {
+			query.LastSearched = search.Timestamp
+		}
+	}
+	l.mu.RUnlock()
+
+	summary.TopQueries = top(stats, limit, func(query *QueryStats) int { return query.Searches })
+	summary.ZeroResultQueries = top(stats, limit, func(query *QueryStats) int { return query.ZeroResults })
+	return summary
+}
+
+/*
+Auxiliary function that returns up to limit queries with a positive count, highest count first and
+by term on a tie.
+*/
+func top(stats map[string]*QueryStats, limit int, count func(query *QueryStats) int) []QueryStats {
+	queries := []QueryStats{}
+	for _, query := range stats {
+		if count(query) > 0 {
+			queries = append(queries, *query)
+		}
+	}
+	sort.Slice(queries, func(i, j int) bool {
+		if count(&queries[i]) != count(&queries[j]) {
+			return count(&queries[i]) > count(&queries[j])
+		}
+		return queries[i].Term < queries[j].Term
+	})
+	if len(queries) > limit {
+		queries = queries[:limit]
+	}
+	return queries
+}
@@ -0,0 +1,41 @@
+package analytics
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestSearchLog_Summarize(t *testing.T) {
+	log := NewSearchLog(5)
+	now := time.Date(2030, time.March, 10, 12, 0, 0, 0, time.UTC)
+	log.nowFunc = func() time.Time { return now }
+
+	// The oldest search is discarded once the log is full
+	log.Record(Search{Term: "forgotten", Results: 0, Timestamp: now.Add(-time.Hour)})
+	log.Record(Search{Term: "Piña", Results: 2, Timestamp: now.Add(-48 * time.Hour)})
+	log.Record(Search{Term: "pina ", Results: 0})
+	log.Record(Search{Term: "kombucha", Results: 0})
+	log.Record(Search{Term: "PIÑA", Results: 1, Filters: map[string]string{"priceGt": "10"}})
+	log.Record(Search{Term: "milk", Results: 4})
+	log.Record(Search{Term: "   ", Results: 0})
+
+	all := log.Summarize(time.Time{}, 10)
+	recent := log.Summarize(now.Add(-time.Hour), 1)
+
+	// Assertions
+	assert.Equal(t, 5, all.Searches)
+	assert.Equal(t, 2, all.ZeroResultSearches)
+	assert.Equal(t, now.Add(-48*time.Hour), all.Since)
+	assert.Equal(t, []QueryStats{
+		{Term: "pina", Searches: 3, ZeroResults: 1, LastSearched: now},
+		{Term: "kombucha", Searches: 1, ZeroResults: 1, LastSearched: now},
+		{Term: "milk", Searches: 1, LastSearched: now},
+	}, all.TopQueries)
+	assert.Equal(t, []QueryStats{
+		{Term: "kombucha", Searches: 1, ZeroResults: 1, LastSearched: now},
+		{Term: "pina", Searches: 3, ZeroResults: 1, LastSearched: now},
+	}, all.ZeroResultQueries)
+	assert.Equal(t, 4, recent.Searches)
+	assert.Equal(t, []QueryStats{{Term: "pina", Searches: 2, ZeroResults: 1, LastSearched: now}}, recent.TopQueries)
+}
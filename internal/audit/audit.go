@@ -0,0 +1,109 @@
+package audit
+
+import (
+	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/JoseObreque/go-web/pkg/events"
+	"log"
+	"sync"
+	"time"
+)
+
+/*
+The Entry struct represents an audited change: what happened, to which entity and when. Data is the
+entity state attached to the event (the deleted entity for deletions) and Previous its state
+before the change, when available. Actor is who made the change, if it was not the system.
+*/
+type Entry struct {
+	Type      string      `json:"type"`
+	EntityId  int         `json:"entity_id"`
+	Data      interface{} `json:"data,omitempty"`
+	Previous  interface{} `json:"previous,omitempty"`
+	Actor     string      `json:"actor,omitempty"`
+	Timestamp time.Time   `json:"timestamp"`
+}
+
+/*
+The Log struct records the events published in the bus. The entries are written to the standard
+logger and the most recent ones are kept in memory. The bus drops the events of a listener that
+does not keep up, so under a burst of changes some of them may be missing from the log.
+*/
+type Log struct {
+	mu         sync.RWMutex
+	entries    []Entry
+	maxEntries int
+}
+
+// The NewLog function returns a new audit log that keeps up to maxEntries entries in memory.
+func NewLog(maxEntries int) *Log {
+	return &Log{
+		maxEntries: maxEntries,
+	}
+}
+
+// The Listen method starts recording the events published in the given bus.
+func (l *Log) Listen(bus events.Bus) {
+	events.Listen(bus, "audit", l.Record)
+}
+
+// The Record method stores an event as an audit entry.
+func (l *Log) Record(event events.Event) {
+	entry := Entry{
+		Type:      event.Type,
+		EntityId:  event.Id,
+		Data:      event.Data,
+		Previous:  event.Previous,
+		Actor:     event.Actor,
+		Timestamp: event.Timestamp,
+	}
+	if entry.Actor != "" {
+		log.Printf("audit: %s id=%d by %s at %s\n", entry.Type, entry.EntityId, entry.Actor, entry.Timestamp.Format(time.RFC3339))
+	} else {
+		log.Printf("audit: %s id=%d at %s\n", entry.Type, entry.EntityId, entry.Timestamp.Format(time.RFC3339))
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.entries = append(l.entries, entry)
+	if l.maxEntries > 0 && len(l.entries) > l.maxEntries {
+		l.entries = l.entries[len(l.entries)-l.maxEntries:]
+	}
+}
+
+// The Entries method returns the recorded entries, most recent last.
+func (l *Log) Entries() []Entry {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	entries := make([]Entry, len(l.entries))
+	copy(entries, l.entries)
+	return entries
+}
+
+/*
+The History method returns the recorded entries of a product, most recent last. The history of a
+product includes the one of the duplicates merged into it, since they are the same product.
+*/
+func (l *Log) History(productId int) []Entry {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	// The entries are walked from the most recent one, so a merge is found before the entries of its duplicate
+	ids := map[int]bool{productId: true}
+	history := []Entry{}
+	for i := len(l.entries) - 1; i >= 0; i-- {
+		entry := l.entries[i]
+		if !ids[entry.EntityId] {
+			continue
+		}
+		if merged, ok := entry.Previous.(product.MergedProducts); ok && entry.Type == product.EventMerged {
+			ids[merged.Duplicate.Id] = true
+		}
+		history = append(history, entry)
+	}
+
+	for i, j := 0, len(history)-1; i < j; i, j = i+1, j-1 {
+		history[i], history[j] = history[j], history[i]
+	}
+	return history
+}
@@ -0,0 +1,40 @@
+package audit
+
+import (
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/JoseObreque/go-web/pkg/events"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLog_History(t *testing.T) {
+	log := NewLog(10)
+	for _, event := range []events.Event{
+		{Type: product.EventCreated, Id: 1},
+		{Type: product.EventCreated, Id: 2},
+		{Type: product.EventUpdated, Id: 2},
+		{Type: product.EventCreated, Id: 3},
+		{Type: product.EventDeleted, Id: 2},
+		{Type: product.EventUpdated, Id: 1},
+		{Type: product.EventMerged, Id: 1, Previous: product.MergedProducts{
+			Product: domain.Product{Id: 1}, Duplicate: domain.Product{Id: 2},
+		}},
+		{Type: product.EventUpdated, Id: 3},
+	} {
+		log.Record(event)
+	}
+
+	// Assertions: the history of the duplicate is the one of the product it was merged into
+	history := log.History(1)
+	types := make([]string, len(history))
+	for i, entry := range history {
+		types[i] = entry.Type
+	}
+	assert.Equal(t, []string{
+		product.EventCreated, product.EventCreated, product.EventUpdated, product.EventDeleted,
+		product.EventUpdated, product.EventMerged,
+	}, types)
+	assert.Len(t, log.History(3), 2)
+	assert.Empty(t, log.History(9))
+}
@@ -0,0 +1,125 @@
+/*
+Package dedupe flags the products that are likely duplicates of each other, like the same SKU
+entered twice with a typo or the words of the name in another order. Two products are possible
+duplicates when they have the same price, in the same currency, and similar names.
+*/
+package dedupe
+
+import (
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/pkg/fold"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultThreshold is the similarity of the names from which two products are possible duplicates by default.
+const DefaultThreshold = 0.8
+
+/*
+The Pair struct is two products that are possibly the same one. Product is the oldest of them, the
+one with the lowest ID, which is usually the one to keep.
+
+	Product (domain.Product): Product with the lowest ID.
+	Duplicate (domain.Product): Product that is possibly a duplicate of the first one.
+	Similarity (float64): Similarity of their names, from 0 (nothing alike) to 1 (the same words). Example: 0.92.
+*/
+type Pair struct {
+	Product    domain.Product `json:"product"`
+	Duplicate  domain.Product `json:"duplicate"`
+	Similarity float64        `json:"similarity" example:"0.92"`
+}
+
+/*
+The Similarity function returns how similar two names are, from 0 to 1: one minus their edit
+distance relative to the longest one. The names are compared ignoring case, accents and spacing,
+and also with their words sorted, so the same words in another order are the same name.
+*/
+func Similarity(a, b string) float64 {
+	x, y := fold.Words(a), fold.Words(b)
+	similarity := ratio(strings.Join(x, " "), strings.Join(y, " "))
+	sort.Strings(x)
+	sort.Strings(y)
+	if sorted := ratio(strings.Join(x, " "), strings.Join(y, " ")); sorted > similarity {
+		similarity = sorted
+	}
+	return similarity
+}
+
+/*
+The Find function returns the pairs of products with the same price and currency whose names are
+at least as similar as the threshold, most similar first and by the IDs of their products on a tie.
+A product similar to several others is in a pair with each of them.
+*/
+func Find(products []domain.Product, threshold float64) []Pair {
+	// Only the products with the same price are compared, which are usually few
+	byPrice := make(map[string][]domain.Product)
+	for _, product := range products {
+		key := strconv.FormatFloat(product.Price, 'f', -1, 64) + " " + product.PriceCurrency()
+		byPrice[key] = append(byPrice[key], product)
+	}
+
+	pairs := []Pair{}
+	for _, group := range byPrice {
+		sort.Slice(group, func(i, j int) bool { return group[i].Id < group[j].Id })
+		for i := range group {
+			for j := i + 1; j < len(group); j++ {
+				similarity := Similarity(group[i].Name, group[j].Name)
+				if similarity >= threshold {
+					pairs = append(pairs, Pair{Product: group[i], Duplicate: group[j], Similarity: similarity})
+				}
+			}
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].Similarity != pairs[j].Similarity {
+			return pairs[i].Similarity > pairs[j].Similarity
+		}
+		if pairs[i].Product.Id != pairs[j].Product.Id {
+			return pairs[i].Product.Id < pairs[j].Product.Id
+		}
+		return pairs[i].Duplicate.Id < pairs[j].Duplicate.Id
+	})
+	return pairs
+}
+
+// Auxiliary function that returns the similarity of two texts, rounded to 2 decimals. Two empty texts are not similar.
+func ratio(a, b string) float64 {
+	x, y := []rune(a), []rune(b)
+	longest := len(x)
+	if len(y) > longest {
+		longest = len(y)
+	}
+	if longest == 0 {
+		return 0
+	}
+	similarity := 1 - float64(distance(x, y))/float64(longest)
+	return float64(int(similarity*100+0.5)) / 100
+}
+
+// Auxiliary function that returns the Levenshtein distance between two texts.
+func distance(x, y []rune) int {
+	previous := make([]int, len(y)+1)
+	current := make([]int, len(y)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(x); i++ {
+		current[0] = i
+		for j := 1; j <= len(y); j++ {
+			cost := 1
+			if x[i-1] == y[j-1] {
+				cost = 0
+			}
+			current[j] = previous[j-1] + cost
+			if previous[j]+1 < current[j] {
+				current[j] = previous[j] + 1
+			}
+			if current[j-1]+1 < current[j] {
+				current[j] = current[j-1] + 1
+			}
+		}
+		previous, current = current, previous
+	}
+	return previous[len(y)]
+}
@@ -0,0 +1,39 @@
+package dedupe
+
+import (
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFind(t *testing.T) {
+	products := []domain.Product{
+		{Id: 1, Name: "Pineapple juice", Price: 10},
+		{Id: 2, Name: "Orange juice", Price: 10},
+		{Id: 3, Name: "Pineaple Juice", Price: 10},
+		{Id: 4, Name: "Juice  pineapple", Price: 10},
+		{Id: 5, Name: "Pineapple juice", Price: 12},
+		{Id: 6, Name: "Pineapple juice", Price: 10, Currency: "CLP"},
+		{Id: 7, Name: "Piña", Price: 5},
+		{Id: 8, Name: "pina", Price: 5},
+	}
+
+	pairs := Find(products, DefaultThreshold)
+
+	// Assertions
+	assert.Equal(t, [][2]int{{1, 4}, {7, 8}, {1, 3}, {3, 4}}, ids(pairs))
+	assert.Equal(t, 1.0, pairs[0].Similarity)
+	assert.Equal(t, 0.93, pairs[2].Similarity)
+	assert.Equal(t, [][2]int{{1, 4}, {7, 8}}, ids(Find(products, 1)))
+	assert.Less(t, Similarity("Pineapple juice", "Orange juice"), DefaultThreshold)
+	assert.Equal(t, 0.0, Similarity("", ""))
+}
+
+// Auxiliary function that returns the IDs of the products of every pair.
+func ids(pairs []Pair) [][2]int {
+	result := make([][2]int, len(pairs))
+	for i, pair := range pairs {
+		result[i] = [2]int{pair.Product.Id, pair.Duplicate.Id}
+	}
+	return result
+}
@@ -0,0 +1,151 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+)
+
+// Names of the formats of the code values that can be configured.
+const (
+	// CodeFormatPattern accepts the code values matching a regular expression, CodeValuePattern by default.
+	CodeFormatPattern = "pattern"
+	// CodeFormatEAN13 accepts the EAN-13 barcodes: 13 digits, the last one being the check digit.
+	CodeFormatEAN13 = "ean13"
+	// CodeFormatUPC accepts the UPC-A barcodes: 12 digits, the last one being the check digit.
+	CodeFormatUPC = "upc"
+	// CodeFormatGTIN accepts both the EAN-13 and the UPC-A barcodes.
+	CodeFormatGTIN = "gtin"
+)
+
+var (
+	ErrInvalidCodeFormat  = errors.New("invalid code value format, expected pattern, ean13, upc or gtin")
+	ErrInvalidCodePattern = errors.New("invalid code value pattern")
+)
+
+/*
+The CodeFormat struct describes the format the code values must follow.
+
+	Format (string): Name of the format. Example: "ean13".
+	Pattern (string): Regular expression of the pattern format. Example: "^[A-Z]{3}[0-9]{4}$".
+*/
+type CodeFormat struct {
+	Format  string `json:"format" example:"pattern"`
+	Pattern string `json:"pattern,omitempty" example:"^[A-Za-z0-9][A-Za-z0-9_-]{0,31}$"`
+}
+
+// Code value settings: the configured format and the compiled expression of the pattern format.
+var codes = struct {
+	sync.RWMutex
+	format CodeFormat
+	regexp *regexp.Regexp
+}{
+	format: CodeFormat{Format: CodeFormatPattern, Pattern: CodeValuePattern},
+	regexp: regexp.MustCompile(CodeValuePattern),
+}
+
+/*
+The ConfigureCodeValues function sets the format the code values of the created and updated
+products must follow. The pattern format (the default if empty) uses the given regular expression,
+or CodeValuePattern if empty; the barcode formats ignore it. The code values already stored are
+only checked again when they change.
+*/
+func ConfigureCodeValues(format CodeFormat) error {
+	var compiled *regexp.Regexp
+	switch format.Format {
+	case "", CodeFormatPattern:
+		format.Format = CodeFormatPattern
+		if format.Pattern == "" {
+			format.Pattern = CodeValuePattern
+		}
+		var err error
+		if compiled, err = regexp.Compile(format.Pattern); err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidCodePattern, err)
+		}
+	case CodeFormatEAN13, CodeFormatUPC, CodeFormatGTIN:
+		format.Pattern = ""
+	default:
+		return ErrInvalidCodeFormat
+	}
+
+	codes.Lock()
+	defer codes.Unlock()
+	codes.format = format
+	codes.regexp = compiled
+	return nil
+}
+
+// The CodeValueFormat function returns the configured format of the code values.
+func CodeValueFormat() CodeFormat {
+	codes.RLock()
+	defer codes.RUnlock()
+	return codes.format
+}
+
+/*
+The validateCodeValue function checks a code value against the configured format. It returns the
+error of the code_value field, or nil if the code value is valid.
+*/
+func validateCodeValue(code string) *FieldError {
+	codes.RLock()
+	format, compiled := codes.format, codes.regexp
+	codes.RUnlock()
+
+	invalid := func(rule, message string) *FieldError {
+		return &FieldError{Field: "code_value", Rule: rule, Message: message}
+	}
+	switch format.Format {
+	case CodeFormatEAN13:
+		if !validGTIN(code, 13) {
+			return invalid("ean13", "must be an EAN-13 barcode: 13 digits with a valid check digit")
+		}
+	case CodeFormatUPC:
+		if !validGTIN(code, 12) {
+			return invalid("upc", "must be a UPC-A barcode: 12 digits with a valid check digit")
+		}
+	case CodeFormatGTIN:
+		if !validGTIN(code, 13) && !validGTIN(code, 12) {
+			return invalid("gtin", "must be an EAN-13 or UPC-A barcode with a valid check digit")
+		}
+	default:
+		if !compiled.MatchString(code) {
+			message := fmt.Sprintf("must match %s", format.Pattern)
+			if format.Pattern == CodeValuePattern {
+				message = "must have up to 32 letters, digits, dashes or underscores"
+			}
+			return invalid("pattern", message)
+		}
+	}
+	return nil
+}
+
+/*
+Auxiliary function that checks if a code is a GTIN barcode of the given length: only digits, the
+last one being the check digit. From the right, the digits before the check digit are weighted 3
+and 1 alternately, and the check digit rounds their sum up to a multiple of 10.
+*/
+func validGTIN(code string, length int) bool {
+	if len(code) != length {
+		return false
+	}
+
+	sum := 0
+	for i := length - 2; i >= 0; i-- {
+		digit := code[i]
+		if digit < '0' || digit > '9' {
+			return false
+		}
+		weight := 1
+		if (length-2-i)%2 == 0 {
+			weight = 3
+		}
+		sum += int(digit-'0') * weight
+	}
+
+	check := code[length-1]
+	if check < '0' || check > '9' {
+		return false
+	}
+	return int(check-'0') == (10-sum%10)%10
+}
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestConfigureCodeValues(t *testing.T) {
+	defer func() {
+		_ = ConfigureCodeValues(CodeFormat{})
+	}()
+	product := Product{Name: "Chocolate", CodeValue: "4006381333931", Expiration: "15/12/2030", Price: 2.5}
+	upc := product
+	upc.CodeValue = "036000291452"
+	wrongCheck := product
+	wrongCheck.CodeValue = "4006381333932"
+
+	// Assertions
+	assert.ErrorIs(t, ConfigureCodeValues(CodeFormat{Format: "isbn"}), ErrInvalidCodeFormat)
+	assert.ErrorIs(t, ConfigureCodeValues(CodeFormat{Pattern: "[a-"}), ErrInvalidCodePattern)
+	assert.Equal(t, CodeFormat{Format: CodeFormatPattern, Pattern: CodeValuePattern}, CodeValueFormat())
+
+	assert.NoError(t, ConfigureCodeValues(CodeFormat{Format: CodeFormatEAN13, Pattern: "ignored"}))
+	assert.Equal(t, CodeFormat{Format: CodeFormatEAN13}, CodeValueFormat())
+	assert.NoError(t, product.Validate())
+	assert.ErrorIs(t, upc.Validate(), ErrInvalidProduct)
+	assert.ErrorIs(t, wrongCheck.Validate(), ErrInvalidProduct)
+
+	assert.NoError(t, ConfigureCodeValues(CodeFormat{Format: CodeFormatUPC}))
+	assert.NoError(t, upc.Validate())
+	assert.ErrorIs(t, product.Validate(), ErrInvalidProduct)
+
+	assert.NoError(t, ConfigureCodeValues(CodeFormat{Format: CodeFormatGTIN}))
+	assert.NoError(t, product.Validate())
+	assert.NoError(t, upc.Validate())
+
+	assert.NoError(t, ConfigureCodeValues(CodeFormat{Pattern: "^[A-Z]{3}[0-9]{4}$"}))
+	err := product.Validate()
+	var validationErr *ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, []FieldError{
+		{Field: "code_value", Rule: "pattern", Message: "must match ^[A-Z]{3}[0-9]{4}$"},
+	}, validationErr.Fields)
+	// Stored code values are only checked again when they change
+	renamed := product
+	renamed.Name = "Dark chocolate"
+	assert.NoError(t, renamed.ValidateChanges(product))
+	assert.ErrorIs(t, wrongCheck.ValidateChanges(product), ErrInvalidProduct)
+}
@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"errors"
+	"sync"
+)
+
+// DefaultCurrency is the currency of the prices without a currency, unless another one is configured.
+const DefaultCurrency = "USD"
+
+var ErrInvalidCurrency = errors.New("invalid currency, expected an ISO 4217 code")
+
+// Currency settings: the currency of the prices of the products without a currency.
+var currencies = struct {
+	sync.RWMutex
+	base string
+}{
+	base: DefaultCurrency,
+}
+
+/*
+The ConfigureCurrency function sets the currency of the prices of the products that have no
+currency of their own (DefaultCurrency if empty). It must be called before serving any request.
+*/
+func ConfigureCurrency(code string) error {
+	if code == "" {
+		code = DefaultCurrency
+	}
+	if !ValidCurrency(code) {
+		return ErrInvalidCurrency
+	}
+
+	currencies.Lock()
+	defer currencies.Unlock()
+	currencies.base = code
+	return nil
+}
+
+// The BaseCurrency function returns the currency of the prices of the products without a currency.
+func BaseCurrency() string {
+	currencies.RLock()
+	defer currencies.RUnlock()
+	return currencies.base
+}
+
+// The ValidCurrency function checks if a code looks like an ISO 4217 currency code: three uppercase letters.
+func ValidCurrency(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for i := 0; i < len(code); i++ {
+		if code[i] < 'A' || code[i] > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// The PriceCurrency method returns the currency of the product price, the base currency if it has none.
+func (p Product) PriceCurrency() string {
+	if p.Currency == "" {
+		return BaseCurrency()
+	}
+	return p.Currency
+}
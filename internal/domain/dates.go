@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// Layouts of the expiration dates accepted on input. Both are always accepted.
+const (
+	// ExpirationLayout is the layout of the products expiration date (DD/MM/YYYY).
+	ExpirationLayout = "02/01/2006"
+	// ISOExpirationLayout is the ISO 8601 layout of the products expiration date (YYYY-MM-DD).
+	ISOExpirationLayout = "2006-01-02"
+)
+
+// Names of the formats of the expiration dates that can be configured for the output.
+const (
+	DateFormatDMY = "dmy"
+	DateFormatISO = "iso"
+)
+
+var ErrInvalidDateFormat = errors.New("invalid date format, expected dmy or iso")
+
+// Date settings: the layout of the stored and returned dates, and the timezone they refer to.
+var dates = struct {
+	sync.RWMutex
+	layout   string
+	location *time.Location
+}{
+	layout:   ExpirationLayout,
+	location: time.Local,
+}
+
+/*
+The ConfigureDates function sets the format the expiration dates are normalized to (DateFormatDMY
+or DateFormatISO, DMY if empty) and the timezone in which they are compared with the current time
+(the local one if nil). It must be called before serving any request.
+*/
+func ConfigureDates(format string, location *time.Location) error {
+	layout := ExpirationLayout
+	switch format {
+	case "", DateFormatDMY:
+	case DateFormatISO:
+		layout = ISOExpirationLayout
+	default:
+		return ErrInvalidDateFormat
+	}
+	if location == nil {
+		location = time.Local
+	}
+
+	dates.Lock()
+	defer dates.Unlock()
+	dates.layout = layout
+	dates.location = location
+	return nil
+}
+
+/*
+The ParseExpiration function parses an expiration date written in any of the accepted layouts. The
+date refers to the start of that day in the configured timezone.
+*/
+func ParseExpiration(date string) (time.Time, error) {
+	dates.RLock()
+	location := dates.location
+	dates.RUnlock()
+
+	for _, layout := range []string{ExpirationLayout, ISOExpirationLayout} {
+		if parsedDate, err := time.ParseInLocation(layout, date, location); err == nil {
+			return parsedDate, nil
+		}
+	}
+	return time.Time{}, ErrInvalidExpirationFormat
+}
+
+/*
+The NormalizeExpiration function rewrites an expiration date in the configured format. Dates that
+cannot be parsed are returned with an error.
+*/
+func NormalizeExpiration(date string) (string, error) {
+	parsedDate, err := ParseExpiration(date)
+	if err != nil {
+		return date, err
+	}
+
+	dates.RLock()
+	defer dates.RUnlock()
+	return parsedDate.Format(dates.layout), nil
+}
+
+/*
+The NormalizeExpirations function rewrites the expiration dates of the given products and their
+lots in the configured format. Invalid expiration dates are left untouched.
+*/
+func NormalizeExpirations(products []Product) {
+	for i := range products {
+		products[i].Expiration, _ = NormalizeExpiration(products[i].Expiration)
+		for j := range products[i].Lots {
+			products[i].Lots[j].Expiration, _ = NormalizeExpiration(products[i].Lots[j].Expiration)
+		}
+	}
+}
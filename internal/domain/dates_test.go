@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestNormalizeExpiration(t *testing.T) {
+	defer func() { _ = ConfigureDates(DateFormatDMY, nil) }()
+
+	err := ConfigureDates(DateFormatISO, time.UTC)
+	iso, errISO := NormalizeExpiration("25/10/2030")
+	same, errSame := NormalizeExpiration("2030-10-25")
+	_, errInvalid := NormalizeExpiration("10-25-2030")
+
+	// Assertions
+	assert.NoError(t, err)
+	assert.NoError(t, errISO)
+	assert.NoError(t, errSame)
+	assert.Equal(t, "2030-10-25", iso)
+	assert.Equal(t, "2030-10-25", same)
+	assert.ErrorIs(t, errInvalid, ErrInvalidExpirationFormat)
+	assert.ErrorIs(t, ConfigureDates("mdy", nil), ErrInvalidDateFormat)
+	assert.NoError(t, ValidateExpiration("2030-10-25"))
+	assert.ErrorIs(t, ValidateExpiration("2020-10-25"), ErrExpiredDate)
+}
@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrInvalidAllergen = errors.New("invalid allergen, expected one of the dietary tags")
+	ErrInvalidDiet     = errors.New("invalid diet, expected one of the dietary tags")
+)
+
+/*
+Allergens are the controlled vocabulary of the allergens of the nutrition facts: the 14 allergens
+the food labels must declare.
+*/
+var Allergens = []string{
+	"celery", "crustaceans", "eggs", "fish", "gluten", "lupin", "milk", "molluscs", "mustard", "peanuts", "sesame", "soy",
+	"sulphites", "tree-nuts",
+}
+
+// Diets are the controlled vocabulary of the diets the products are suitable for, listed in their nutrition facts.
+var Diets = []string{"gluten-free", "halal", "keto", "kosher", "lactose-free", "vegan", "vegetarian"}
+
+/*
+The DietaryTags struct is the controlled vocabulary of the allergens and diets of the nutrition
+facts, which are the only ones accepted in the products and in their filters.
+
+	Allergens ([]string): Allergens of the nutrition facts. Example: ["milk", "tree-nuts"].
+	Diets ([]string): Diets of the nutrition facts. Example: ["vegan", "gluten-free"].
+*/
+type DietaryTags struct {
+	Allergens []string `json:"allergens"`
+	Diets     []string `json:"diets"`
+}
+
+// The NewDietaryTags function returns the controlled vocabulary of the Allergens and Diets.
+func NewDietaryTags() DietaryTags {
+	return DietaryTags{Allergens: Allergens, Diets: Diets}
+}
+
+// The ParseAllergens function parses a comma separated list of allergens, like "milk,peanuts", ignoring the case.
+func ParseAllergens(list string) ([]string, error) {
+	return parseTags(list, Allergens, ErrInvalidAllergen)
+}
+
+// The ParseDiets function parses a comma separated list of diets, like "vegan,gluten-free", ignoring the case.
+func ParseDiets(list string) ([]string, error) {
+	return parseTags(list, Diets, ErrInvalidDiet)
+}
+
+// Auxiliary function that parses a comma separated list of tags of the vocabulary, returning the given error for the others.
+func parseTags(list string, vocabulary []string, invalid error) ([]string, error) {
+	var tags []string
+	for _, name := range strings.Split(list, ",") {
+		tag := strings.ToLower(strings.TrimSpace(name))
+		if !inVocabulary(tag, vocabulary) {
+			return nil, fmt.Errorf("%w: %q", invalid, tag)
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// Auxiliary function that checks if a tag is one of the given vocabulary.
+func inVocabulary(tag string, vocabulary []string) bool {
+	for _, known := range vocabulary {
+		if tag == known {
+			return true
+		}
+	}
+	return false
+}
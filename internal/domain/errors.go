@@ -0,0 +1,15 @@
+package domain
+
+import "errors"
+
+/*
+Sentinel errors shared by every layer that handles products. The repositories and stores wrap them
+with the details of the failure, so callers must match them with errors.Is instead of comparing the
+error messages.
+*/
+var (
+	ErrProductNotFound = errors.New("product not found")
+	ErrDuplicateCode   = errors.New("invalid product code value")
+	ErrNoProducts      = errors.New("no products found")
+	ErrUnavailable     = errors.New("the products are unavailable")
+)
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrInvalidSubscription = errors.New("invalid expiry subscription, expected a webhook url with its secret or an email")
+
+/*
+The ExpirySubscription struct is a recipient of the notifications of the products and lots about to
+expire, by webhook, by email or both. The secret is used to sign the webhook notifications and is
+never included in the responses.
+*/
+type ExpirySubscription struct {
+	Id        int    `json:"id" example:"1"`
+	Recipient string `json:"recipient" example:"Madrid warehouse"`
+	URL       string `json:"url,omitempty" example:"https://example.com/hooks/expiry"`
+	Secret    string `json:"-"`
+	Email     string `json:"email,omitempty" example:"madrid@example.com"`
+	// Days ahead the expirations are notified, the default window of the server if 0
+	WindowDays int       `json:"window_days,omitempty" example:"7"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// The ExpirySubscriptionRequest struct is the body used to create or replace an expiry subscription.
+type ExpirySubscriptionRequest struct {
+	Recipient  string `json:"recipient" example:"Madrid warehouse" binding:"required"`
+	URL        string `json:"url,omitempty" example:"https://example.com/hooks/expiry" binding:"omitempty,url"`
+	Secret     string `json:"secret,omitempty" example:"s3cr3t"`
+	Email      string `json:"email,omitempty" example:"madrid@example.com" binding:"omitempty,email"`
+	WindowDays int    `json:"window_days,omitempty" example:"7" binding:"min=0,max=365"`
+}
+
+/*
+The Validate method checks that the request has somewhere to notify: a webhook URL along with the
+secret to sign its notifications, an email, or both. It returns ErrInvalidSubscription otherwise.
+*/
+func (r ExpirySubscriptionRequest) Validate() error {
+	if (r.URL == "" && r.Email == "") || (r.URL != "") != (r.Secret != "") {
+		return ErrInvalidSubscription
+	}
+	return nil
+}
+
+// The EntityId method returns the ID of an expiry subscription, which identifies it in the in-memory repositories.
+func (s ExpirySubscription) EntityId() int {
+	return s.Id
+}
+
+// The WithId method returns a copy of the expiry subscription with the given ID.
+func (s ExpirySubscription) WithId(id int) ExpirySubscription {
+	s.Id = id
+	return s
+}
@@ -0,0 +1,111 @@
+package domain
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultLocale is the locale of the names and descriptions of the products, unless another one is configured.
+const DefaultLocale = "en"
+
+var (
+	ErrInvalidLocale       = errors.New("invalid locale, expected a language tag like es or pt-br")
+	ErrTranslationNotFound = errors.New("translation not found")
+)
+
+// Format of the locales of the translations: a language code, optionally followed by subtags.
+var localePattern = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})*$`)
+
+// Locale settings: the locale of the own names and descriptions of the products.
+var locales = struct {
+	sync.RWMutex
+	base string
+}{
+	base: DefaultLocale,
+}
+
+/*
+The Translation struct is the name and description of a product in another locale.
+
+	Name (string): Name of the product in the locale. Example: "Piña".
+	Description (string): Description of the product in the locale. Example: "Piña dulce de Costa Rica".
+*/
+type Translation struct {
+	Name        string `json:"name" example:"Piña" binding:"required"`
+	Description string `json:"description,omitempty" example:"Piña dulce de Costa Rica"`
+}
+
+/*
+The ConfigureLocale function sets the locale of the own names and descriptions of the products
+(DefaultLocale if empty), the one used when the client accepts none of their translations. It must
+be called before serving any request.
+*/
+func ConfigureLocale(locale string) error {
+	if locale == "" {
+		locale = DefaultLocale
+	}
+	locale, err := NormalizeLocale(locale)
+	if err != nil {
+		return err
+	}
+
+	locales.Lock()
+	defer locales.Unlock()
+	locales.base = locale
+	return nil
+}
+
+// The BaseLocale function returns the locale of the own names and descriptions of the products.
+func BaseLocale() string {
+	locales.RLock()
+	defer locales.RUnlock()
+	return locales.base
+}
+
+// The NormalizeLocale function returns a locale in lower case, with dashes, or ErrInvalidLocale if it is not a language tag.
+func NormalizeLocale(locale string) (string, error) {
+	locale = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+	if !localePattern.MatchString(locale) {
+		return "", ErrInvalidLocale
+	}
+	return locale, nil
+}
+
+/*
+The Localize method returns the product with the name and description of the first of the given
+locales it has, in order of preference, along with that locale. A regional locale falls back to its
+language, so "es-cl" uses the "es" translation. If the product has none of them, or the base locale
+comes first, the product is returned unchanged with the base locale.
+*/
+func (p Product) Localize(preferred []string) (Product, string) {
+	base := BaseLocale()
+	for _, locale := range preferred {
+		language, _, _ := strings.Cut(locale, "-")
+		for _, candidate := range []string{locale, language} {
+			if candidate == base {
+				return p, base
+			}
+			if translation, ok := p.Translations[candidate]; ok {
+				p.Name = translation.Name
+				if translation.Description != "" {
+					p.Description = translation.Description
+				}
+				return p, candidate
+			}
+		}
+	}
+	return p, base
+}
+
+// Auxiliary function that returns the locales of the translations in alphabetical order.
+func sortedLocales(translations map[string]Translation) []string {
+	sorted := make([]string, 0, len(translations))
+	for locale := range translations {
+		sorted = append(sorted, locale)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
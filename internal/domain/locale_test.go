@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestProduct_Localize(t *testing.T) {
+	product := Product{Name: "Pineapple", Description: "Sweet pineapple", Translations: map[string]Translation{
+		"es":    {Name: "Piña", Description: "Piña dulce"},
+		"pt-br": {Name: "Abacaxi"},
+	}}
+
+	spanish, spanishLocale := product.Localize([]string{"es-cl", "en"})
+	portuguese, portugueseLocale := product.Localize([]string{"fr", "pt-br"})
+	english, englishLocale := product.Localize([]string{"en", "es"})
+	fallback, fallbackLocale := product.Localize([]string{"de"})
+
+	// Assertions
+	assert.Equal(t, "es", spanishLocale)
+	assert.Equal(t, "Piña", spanish.Name)
+	assert.Equal(t, "Piña dulce", spanish.Description)
+	assert.Equal(t, "pt-br", portugueseLocale)
+	assert.Equal(t, "Abacaxi", portuguese.Name)
+	assert.Equal(t, "Sweet pineapple", portuguese.Description)
+	assert.Equal(t, "en", englishLocale)
+	assert.Equal(t, product, english)
+	assert.Equal(t, "en", fallbackLocale)
+	assert.Equal(t, "Pineapple", fallback.Name)
+}
+
+func TestProductRequest_Apply_Translations(t *testing.T) {
+	product := Product{Name: "Pineapple", Translations: map[string]Translation{"es": {Name: "Piña"}, "fr": {Name: "Ananas"}}}
+
+	changed := ProductRequest{Translations: map[string]*Translation{"fr": nil, "it": {Name: "Ananas"}}}.Apply(product)
+	cleared := ProductRequest{Translations: map[string]*Translation{"es": nil, "fr": nil}}.Apply(product)
+
+	// Assertions
+	assert.Equal(t, map[string]Translation{"es": {Name: "Piña"}, "it": {Name: "Ananas"}}, changed.Translations)
+	assert.Nil(t, cleared.Translations)
+	assert.Equal(t, map[string]Translation{"es": {Name: "Piña"}, "fr": {Name: "Ananas"}}, product.Translations)
+}
+
+func TestProduct_Validate_Translations(t *testing.T) {
+	product := Product{Name: "Milk", CodeValue: "MILK-01", Expiration: "15/12/2030", Price: 0.5, Translations: map[string]Translation{
+		"ES": {Name: "Leche"},
+		"en": {Name: "Milk"},
+		"fr": {Name: " "},
+		"it": {Name: "Latte"},
+	}}
+
+	err := product.Validate()
+	var validationErr *ValidationError
+
+	// Assertions
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, []FieldError{
+		{Field: "translations.ES", Rule: "locale", Message: "must be a lowercase language tag, like es or pt-br"},
+		{Field: "translations.en", Rule: "base_locale", Message: "must not be the base locale, which is the own name and description"},
+		{Field: "translations.fr.name", Rule: "required", Message: "must not be empty"},
+	}, validationErr.Fields)
+}
@@ -0,0 +1,174 @@
+package domain
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+The Lot struct is a batch of the stock of a product, received together and expiring on the same
+date. The quantity of a product with lots is the sum of theirs.
+
+	Number (string): Lot number printed on the packages, unique in the product. Example: "L2301".
+	Quantity (int): Units of the product left in the lot. Example: 40.
+	Expiration (string): Expiration date of the lot, in the format of the products. Example: "01/06/2030".
+*/
+type Lot struct {
+	Number     string `json:"number" example:"L2301"`
+	Quantity   int    `json:"quantity" example:"40"`
+	Expiration string `json:"expiration" example:"01/06/2030"`
+}
+
+// The LotQuantity method returns the sum of the quantities of the lots of the product, and false if it has none.
+func (p Product) LotQuantity() (int, bool) {
+	quantity := 0
+	for _, lot := range p.Lots {
+		quantity += lot.Quantity
+	}
+	return quantity, len(p.Lots) > 0
+}
+
+/*
+The NormalizeLots method returns a copy of the product with the expiration dates of its lots in the
+configured format, its lots in first-expire-first-out order and its quantity set to their sum. A
+product without lots is returned as it is, with the quantity of its own.
+*/
+func (p Product) NormalizeLots() Product {
+	if len(p.Lots) == 0 {
+		return p
+	}
+	// The lots of the given product may be shared with the repository, so they are copied
+	lots := make([]Lot, len(p.Lots))
+	for i, lot := range p.Lots {
+		lot.Expiration, _ = NormalizeExpiration(lot.Expiration)
+		lots[i] = lot
+	}
+	p.Lots = sortLots(lots)
+	p.Quantity, _ = p.LotQuantity()
+	return p
+}
+
+/*
+The PickLots function suggests the lots to pick the given quantity from, first-expire-first-out: the
+units are taken from the lots expiring first, skipping the empty ones and the ones already expired
+at the given moment. It returns the picks, as lots with the quantity to take from them, and the
+units the lots cannot cover.
+*/
+func PickLots(lots []Lot, quantity int, now time.Time) ([]Lot, int) {
+	picks := []Lot{}
+	for _, lot := range sortLots(append([]Lot{}, lots...)) {
+		if quantity <= 0 {
+			break
+		}
+		expiration, err := ParseExpiration(lot.Expiration)
+		if lot.Quantity <= 0 || err != nil || expiration.Before(now) {
+			continue
+		}
+		if lot.Quantity > quantity {
+			lot.Quantity = quantity
+		}
+		quantity -= lot.Quantity
+		picks = append(picks, lot)
+	}
+	if quantity < 0 {
+		quantity = 0
+	}
+	return picks, quantity
+}
+
+/*
+Auxiliary method that returns the lots of the stock of the product. The stock of a product without
+lots is a single lot numbered after its code value and expiring with the product, or none if the
+product has no stock.
+*/
+func (p Product) stockLots() []Lot {
+	if len(p.Lots) > 0 || p.Quantity <= 0 {
+		return p.Lots
+	}
+	return []Lot{{Number: p.CodeValue, Quantity: p.Quantity, Expiration: p.Expiration}}
+}
+
+/*
+Auxiliary function that joins the lots of two products in a new list. The lots sharing a number are
+the same batch, so they are combined in one with the sum of their quantities and the earliest of
+their expiration dates.
+*/
+func mergeLots(lots []Lot, others []Lot) []Lot {
+	merged := make([]Lot, 0, len(lots)+len(others))
+	index := make(map[string]int, len(lots)+len(others))
+	for _, lot := range append(append([]Lot{}, lots...), others...) {
+		number := strings.TrimSpace(lot.Number)
+		i, ok := index[number]
+		if !ok {
+			index[number] = len(merged)
+			merged = append(merged, lot)
+			continue
+		}
+		merged[i].Quantity += lot.Quantity
+		if expiration, err := ParseExpiration(lot.Expiration); err == nil {
+			current, errCurrent := ParseExpiration(merged[i].Expiration)
+			if errCurrent != nil || expiration.Before(current) {
+				merged[i].Expiration = lot.Expiration
+			}
+		}
+	}
+	return merged
+}
+
+/*
+Auxiliary function that sorts the lots by their expiration date, and their number for the same date.
+The lots with an invalid expiration date go last.
+*/
+func sortLots(lots []Lot) []Lot {
+	expirations := make(map[string]time.Time, len(lots))
+	for _, lot := range lots {
+		if expiration, err := ParseExpiration(lot.Expiration); err == nil {
+			expirations[lot.Expiration] = expiration
+		}
+	}
+	sort.SliceStable(lots, func(i, j int) bool {
+		a, okA := expirations[lots[i].Expiration]
+		b, okB := expirations[lots[j].Expiration]
+		switch {
+		case okA != okB:
+			return okA
+		case !a.Equal(b):
+			return a.Before(b)
+		}
+		return lots[i].Number < lots[j].Number
+	})
+	return lots
+}
+
+/*
+Auxiliary function that returns the lots breaking a rule, by their field under lots and their
+index: the numbers are given and unique, the quantities are not negative and the expiration dates
+can be parsed. They can be already expired, since the stock is still there.
+*/
+func validateLots(lots []Lot) []FieldError {
+	var fields []FieldError
+	invalid := func(i int, name, rule, message string) {
+		fields = append(fields, FieldError{Field: "lots." + strconv.Itoa(i) + "." + name, Rule: rule, Message: message})
+	}
+
+	seen := make(map[string]bool, len(lots))
+	for i, lot := range lots {
+		number := strings.TrimSpace(lot.Number)
+		switch {
+		case number == "":
+			invalid(i, "number", "required", "must not be empty")
+		case seen[number]:
+			invalid(i, "number", "unique", "must not list lot "+number+" twice")
+		}
+		seen[number] = true
+		if lot.Quantity < 0 {
+			invalid(i, "quantity", "min", "must not be negative")
+		}
+		if _, err := ParseExpiration(lot.Expiration); err != nil {
+			invalid(i, "expiration", "date", "must be a date like 25/08/2030")
+		}
+	}
+	return fields
+}
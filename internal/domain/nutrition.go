@@ -0,0 +1,86 @@
+package domain
+
+import "errors"
+
+var ErrNutritionNotFound = errors.New("nutrition facts not found")
+
+/*
+The Nutrition struct is the nutrition facts of a product, per 100 g or 100 mL of it. The nutrients
+are in grams, except for the calories.
+
+	Calories (float64): Energy, in kcal. Example: 50.
+	Fat (float64): Total fat. Example: 0.1.
+	SaturatedFat (float64): Saturated fat, part of the total fat. Example: 0.
+	Carbohydrates (float64): Total carbohydrates. Example: 13.1.
+	Sugars (float64): Sugars, part of the carbohydrates. Example: 9.9.
+	Fiber (float64): Dietary fiber. Example: 1.4.
+	Protein (float64): Protein. Example: 0.5.
+	Salt (float64): Salt. Example: 0.01.
+	Allergens ([]string): Allergens the product contains, of the Allergens. Example: ["sulphites"].
+	Diets ([]string): Diets the product is suitable for, of the Diets. Example: ["vegan"].
+*/
+type Nutrition struct {
+	Calories      float64  `json:"calories" example:"50" format:"float64"`
+	Fat           float64  `json:"fat" example:"0.1" format:"float64"`
+	SaturatedFat  float64  `json:"saturated_fat" example:"0" format:"float64"`
+	Carbohydrates float64  `json:"carbohydrates" example:"13.1" format:"float64"`
+	Sugars        float64  `json:"sugars" example:"9.9" format:"float64"`
+	Fiber         float64  `json:"fiber" example:"1.4" format:"float64"`
+	Protein       float64  `json:"protein" example:"0.5" format:"float64"`
+	Salt          float64  `json:"salt" example:"0.01" format:"float64"`
+	Allergens     []string `json:"allergens" example:"sulphites"`
+	Diets         []string `json:"diets" example:"vegan"`
+}
+
+/*
+Auxiliary method that returns the nutrition facts breaking a rule, by their field under the given
+one: the amounts are not negative, the saturated fat and the sugars are not more than the fat and
+the carbohydrates they are part of, and the allergens and diets are of the controlled vocabulary,
+listed once.
+*/
+func (n Nutrition) validate(field string) []FieldError {
+	var fields []FieldError
+	invalid := func(name, rule, message string) {
+		fields = append(fields, FieldError{Field: field + "." + name, Rule: rule, Message: message})
+	}
+
+	amounts := []struct {
+		name   string
+		amount float64
+	}{
+		{"calories", n.Calories}, {"fat", n.Fat}, {"saturated_fat", n.SaturatedFat}, {"carbohydrates", n.Carbohydrates},
+		{"sugars", n.Sugars}, {"fiber", n.Fiber}, {"protein", n.Protein}, {"salt", n.Salt},
+	}
+	for _, amount := range amounts {
+		if amount.amount < 0 {
+			invalid(amount.name, "min", "must not be negative")
+		}
+	}
+	if n.SaturatedFat > n.Fat {
+		invalid("saturated_fat", "max", "must not be more than the fat")
+	}
+	if n.Sugars > n.Carbohydrates {
+		invalid("sugars", "max", "must not be more than the carbohydrates")
+	}
+
+	tags := []struct {
+		name       string
+		tags       []string
+		vocabulary []string
+	}{
+		{"allergens", n.Allergens, Allergens}, {"diets", n.Diets, Diets},
+	}
+	for _, list := range tags {
+		seen := make(map[string]bool, len(list.tags))
+		for _, tag := range list.tags {
+			switch {
+			case !inVocabulary(tag, list.vocabulary):
+				invalid(list.name, "oneof", "must be one of the dietary tags, not "+tag)
+			case seen[tag]:
+				invalid(list.name, "unique", "must not list "+tag+" twice")
+			}
+			seen[tag] = true
+		}
+	}
+	return fields
+}
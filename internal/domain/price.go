@@ -0,0 +1,53 @@
+package domain
+
+import "time"
+
+/*
+The ScheduledPrice struct is a future price of a product, which becomes its current price at the
+effective date.
+
+	Id (int): Identifier of the scheduled price. Example: 1.
+	ProductId (int): Identifier of the product. Example: 1.
+	Price (float64): Price the product will have. Example: 349.
+	Currency (string): Currency of the price. Empty keeps the currency of the product. Example: "USD".
+	EffectiveFrom (time.Time): Moment the price becomes the current one.
+	CreatedAt (time.Time): Moment the price was scheduled.
+*/
+type ScheduledPrice struct {
+	Id            int       `json:"id" example:"1"`
+	ProductId     int       `json:"product_id" example:"1"`
+	Price         float64   `json:"price" example:"349" format:"float64"`
+	Currency      string    `json:"currency,omitempty" example:"USD"`
+	EffectiveFrom time.Time `json:"effective_from" example:"2030-01-01T00:00:00Z"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// The ScheduledPriceRequest struct is the body used to schedule a future price of a product.
+type ScheduledPriceRequest struct {
+	Price         float64   `json:"price" example:"349" binding:"required" format:"float64"`
+	Currency      string    `json:"currency,omitempty" example:"USD"`
+	EffectiveFrom time.Time `json:"effective_from" example:"2030-01-01T00:00:00Z" binding:"required"`
+}
+
+/*
+The Validate method checks the scheduled price: the price is positive, the currency (if any) is an
+ISO 4217 code and the effective date is after the given current time. It returns a
+*ValidationError listing every invalid field, or nil.
+*/
+func (r ScheduledPriceRequest) Validate(now time.Time) error {
+	var fields []FieldError
+	if r.Price <= 0 {
+		fields = append(fields, FieldError{Field: "price", Rule: "positive", Message: "must be greater than 0"})
+	}
+	if r.Currency != "" && !ValidCurrency(r.Currency) {
+		fields = append(fields, FieldError{Field: "currency", Rule: "iso4217", Message: "must be a 3-letter ISO 4217 code, like USD"})
+	}
+	if !r.EffectiveFrom.After(now) {
+		fields = append(fields, FieldError{Field: "effective_from", Rule: "future", Message: "must be in the future"})
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+	return &ValidationError{Fields: fields}
+}
@@ -1,20 +1,299 @@
 package domain
 
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrInvalidExpirationFormat = errors.New("invalid expiration date format")
+	ErrExpiredDate             = errors.New("expiration date must be after current date")
+)
+
 type Product struct {
 	Id          int     `json:"id" example:"1"`
 	Name        string  `json:"name" example:"Pineapple" binding:"required"`
 	Quantity    int     `json:"quantity" example:"100" binding:"required"`
 	CodeValue   string  `json:"code_value" example:"COD123" binding:"required"`
-	IsPublished bool    `json:"is_published" example:"true"`
+	Status      Status  `json:"status" example:"published" enums:"draft,published,archived,discontinued"`
 	Expiration  string  `json:"expiration" example:"25/08/2030" binding:"required"`
 	Price       float64 `json:"price" example:"299" binding:"required" format:"float64"`
+	Currency    string  `json:"currency,omitempty" example:"USD"`
+	Category    string  `json:"category,omitempty" example:"fruits"`
+	Description string  `json:"description,omitempty" example:"Sweet pineapple from Costa Rica"`
+	// Content of the package: PackSize of the Unit of measure, like 0.5 kg
+	Unit     Unit    `json:"unit,omitempty" example:"kg" enums:"kg,L,unit"`
+	PackSize float64 `json:"pack_size,omitempty" example:"1.5" format:"float64"`
+	// Storage requirements: the zone the product is kept in, and the range of temperatures in °C it needs
+	Storage        StorageZone `json:"storage,omitempty" example:"chilled" enums:"ambient,chilled,frozen"`
+	MinTemperature *float64    `json:"min_temperature,omitempty" example:"2" format:"float64"`
+	MaxTemperature *float64    `json:"max_temperature,omitempty" example:"6" format:"float64"`
+	// Publish window: the product is sold from PublishFrom until PublishUntil, if they are set
+	PublishFrom  *time.Time `json:"publish_from,omitempty" example:"2030-01-01T09:00:00Z"`
+	PublishUntil *time.Time `json:"publish_until,omitempty" example:"2030-03-01T00:00:00Z"`
+	// Name and description in other locales than the base one, by locale
+	Translations map[string]Translation `json:"translations,omitempty"`
+	// Nutrition facts, nil if they are unknown
+	Nutrition *Nutrition `json:"nutrition,omitempty"`
+	// Lots of the stock, first-expire-first-out, which the quantity is the sum of if there are any
+	Lots []Lot `json:"lots,omitempty"`
+	// Summary of the visible reviews, set on the responses only and never stored
+	Rating *Rating `json:"rating,omitempty"`
+	// Price of one unit of measure, like the price per kg, set on the responses only and never stored
+	PricePerUnit *float64 `json:"price_per_unit,omitempty" example:"199.33" format:"float64"`
 }
 
+/*
+The ProductRequest struct is a partial update of a product. Only the fields present in the request
+are changed, so a field can also be set to its zero value, like the quantity to 0. The
+translations are merged: the locales present are set, or removed if they are null. A bound of the
+publish window set to the zero time, 0001-01-01T00:00:00Z, is removed, and so is the unit of
+measure set to an empty string along with a pack size of 0. An empty storage zone removes the
+storage requirements, temperatures included, and the nutrition facts are replaced as a whole. So
+are the lots, which set the quantity to their sum; an empty list of lots stops tracking them and
+keeps the quantity.
+*/
 type ProductRequest struct {
-	Name        string  `json:"name,omitempty" example:"Pineapple"`
-	Quantity    int     `json:"quantity,omitempty" example:"100"`
-	CodeValue   string  `json:"code_value,omitempty" example:"COD123"`
-	IsPublished bool    `json:"is_published,omitempty" example:"true"`
-	Expiration  string  `json:"expiration,omitempty" example:"25/08/2030"`
-	Price       float64 `json:"price,omitempty" example:"299" format:"float64"`
+	Name        *string  `json:"name,omitempty" example:"Pineapple"`
+	Quantity    *int     `json:"quantity,omitempty" example:"100"`
+	CodeValue   *string  `json:"code_value,omitempty" example:"COD123"`
+	Status      *Status  `json:"status,omitempty" example:"published" enums:"draft,published,archived,discontinued"`
+	Expiration  *string  `json:"expiration,omitempty" example:"25/08/2030"`
+	Price       *float64 `json:"price,omitempty" example:"299" format:"float64"`
+	Currency    *string  `json:"currency,omitempty" example:"USD"`
+	Category    *string  `json:"category,omitempty" example:"fruits"`
+	Description *string  `json:"description,omitempty" example:"Sweet pineapple from Costa Rica"`
+	// Content of the package, removed if they are empty
+	Unit     *Unit    `json:"unit,omitempty" example:"kg" enums:"kg,L,unit"`
+	PackSize *float64 `json:"pack_size,omitempty" example:"1.5" format:"float64"`
+	// Storage requirements, removed along with the temperatures if the zone is empty
+	Storage        *StorageZone `json:"storage,omitempty" example:"chilled" enums:"ambient,chilled,frozen"`
+	MinTemperature *float64     `json:"min_temperature,omitempty" example:"2" format:"float64"`
+	MaxTemperature *float64     `json:"max_temperature,omitempty" example:"6" format:"float64"`
+	// Bounds of the publish window, removed if they are the zero time
+	PublishFrom  *time.Time `json:"publish_from,omitempty" example:"2030-01-01T09:00:00Z"`
+	PublishUntil *time.Time `json:"publish_until,omitempty" example:"2030-03-01T00:00:00Z"`
+	// Translations set, or removed if null, by locale
+	Translations map[string]*Translation `json:"translations,omitempty"`
+	// Nutrition facts, replaced as a whole
+	Nutrition *Nutrition `json:"nutrition,omitempty"`
+	// Lots of the stock, replaced as a whole, or removed if the list is empty
+	Lots []Lot `json:"lots,omitempty"`
+}
+
+/*
+The UnmarshalJSON method decodes a product, accepting the is_published flag that the status
+replaced, so the products stored and sent before it keep their state: a published product is
+published, and any other one a draft. The status wins over the flag if both are present. Since the
+method is promoted, a struct embedding a product must decode its other fields on its own.
+*/
+func (p *Product) UnmarshalJSON(data []byte) error {
+	type product Product
+	legacy := struct {
+		*product
+		IsPublished *bool `json:"is_published"`
+	}{product: (*product)(p)}
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return err
+	}
+	if p.Status == "" && legacy.IsPublished != nil {
+		p.Status = StatusOf(*legacy.IsPublished)
+	}
+	return nil
+}
+
+// The UnmarshalJSON method decodes a partial update, accepting the is_published flag that the status replaced.
+func (r *ProductRequest) UnmarshalJSON(data []byte) error {
+	type request ProductRequest
+	legacy := struct {
+		*request
+		IsPublished *bool `json:"is_published"`
+	}{request: (*request)(r)}
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return err
+	}
+	if r.Status == nil && legacy.IsPublished != nil {
+		status := StatusOf(*legacy.IsPublished)
+		r.Status = &status
+	}
+	return nil
+}
+
+// The Apply method returns the given product with the fields present in the request changed.
+func (r ProductRequest) Apply(product Product) Product {
+	if r.Name != nil {
+		product.Name = *r.Name
+	}
+	if r.Quantity != nil {
+		product.Quantity = *r.Quantity
+	}
+	if r.CodeValue != nil {
+		product.CodeValue = *r.CodeValue
+	}
+	if r.Status != nil {
+		product.Status = *r.Status
+	}
+	if r.Expiration != nil {
+		product.Expiration = *r.Expiration
+	}
+	if r.Price != nil {
+		product.Price = *r.Price
+	}
+	if r.Currency != nil {
+		product.Currency = *r.Currency
+	}
+	if r.Category != nil {
+		product.Category = *r.Category
+	}
+	if r.Description != nil {
+		product.Description = *r.Description
+	}
+	if r.Unit != nil {
+		product.Unit = *r.Unit
+	}
+	if r.PackSize != nil {
+		product.PackSize = *r.PackSize
+	}
+	if r.Storage != nil {
+		product.Storage = *r.Storage
+		if product.Storage == "" {
+			product.MinTemperature, product.MaxTemperature = nil, nil
+		}
+	}
+	if r.MinTemperature != nil {
+		product.MinTemperature = r.MinTemperature
+	}
+	if r.MaxTemperature != nil {
+		product.MaxTemperature = r.MaxTemperature
+	}
+	if r.PublishFrom != nil {
+		product.PublishFrom = windowBound(*r.PublishFrom)
+	}
+	if r.PublishUntil != nil {
+		product.PublishUntil = windowBound(*r.PublishUntil)
+	}
+	if r.Nutrition != nil {
+		product.Nutrition = r.Nutrition
+	}
+	if r.Lots != nil {
+		product.Lots = r.Lots
+		if len(product.Lots) == 0 {
+			product.Lots = nil
+		}
+		product = product.NormalizeLots()
+	}
+	if len(r.Translations) > 0 {
+		// The translations of the given product may be shared with the repository, so they are copied
+		translations := make(map[string]Translation, len(product.Translations)+len(r.Translations))
+		for locale, translation := range product.Translations {
+			translations[locale] = translation
+		}
+		for locale, translation := range r.Translations {
+			if translation == nil {
+				delete(translations, locale)
+			} else {
+				translations[locale] = *translation
+			}
+		}
+		product.Translations = translations
+		if len(translations) == 0 {
+			product.Translations = nil
+		}
+	}
+	return product
+}
+
+// Auxiliary function that returns a bound of a publish window, nil if it is the zero time.
+func windowBound(bound time.Time) *time.Time {
+	if bound.IsZero() {
+		return nil
+	}
+	return &bound
+}
+
+// The Equal method checks if two products have the same fields, their translations included.
+func (p Product) Equal(other Product) bool {
+	if len(p.Translations) == 0 && len(other.Translations) == 0 {
+		p.Translations, other.Translations = nil, nil
+	}
+	return reflect.DeepEqual(p, other)
+}
+
+/*
+The Merge method returns the product consolidated with a duplicate of it: the fields of the product
+are kept, its empty category, description and nutrition facts are filled with the ones of the
+duplicate, the translations of the duplicate are added for the locales the product has none of, and
+the stock of both is summed. If any of them has lots, the lots of both are joined as explained in
+mergeLots and the quantity is their sum.
+*/
+func (p Product) Merge(duplicate Product) Product {
+	if len(p.Lots) > 0 || len(duplicate.Lots) > 0 {
+		p.Lots = mergeLots(p.stockLots(), duplicate.stockLots())
+	}
+	p.Quantity += duplicate.Quantity
+	p = p.NormalizeLots()
+	if p.Category == "" {
+		p.Category = duplicate.Category
+	}
+	if p.Description == "" {
+		p.Description = duplicate.Description
+	}
+	if p.Nutrition == nil {
+		p.Nutrition = duplicate.Nutrition
+	}
+
+	translations := make(map[string]Translation, len(p.Translations)+len(duplicate.Translations))
+	for locale, translation := range duplicate.Translations {
+		translations[locale] = translation
+	}
+	for locale, translation := range p.Translations {
+		translations[locale] = translation
+	}
+	p.Translations = nil
+	if len(translations) > 0 {
+		p.Translations = translations
+	}
+	return p
+}
+
+// The EntityId method returns the ID of a product, which identifies it in the in-memory repositories.
+func (p Product) EntityId() int {
+	return p.Id
+}
+
+// The WithId method returns a copy of the product with the given ID.
+func (p Product) WithId(id int) Product {
+	p.Id = id
+	return p
+}
+
+// The ResourceType method returns the JSON:API resource type of a product.
+func (p Product) ResourceType() string {
+	return "products"
+}
+
+// The ResourceID method returns the JSON:API resource identifier of a product.
+func (p Product) ResourceID() string {
+	return strconv.Itoa(p.Id)
+}
+
+/*
+The ValidateExpiration function checks if a given date string is a valid expiration date. It
+returns an error if the date follows neither the ExpirationLayout nor the ISOExpirationLayout, or
+if it does not occur after the current date.
+*/
+func ValidateExpiration(date string) error {
+	parsedDate, err := ParseExpiration(date)
+	if err != nil {
+		return err
+	}
+
+	if parsedDate.Before(time.Now()) {
+		return ErrExpiredDate
+	}
+
+	return nil
 }
@@ -0,0 +1,21 @@
+package domain
+
+/*
+The ProductV1 struct is the representation of a product in the first version of the API: the
+product as it is, along with the is_published flag that the status replaced, so the clients of the
+first version reading it keep working until it is removed. Since the UnmarshalJSON method of the
+product is promoted, the representation is only meant to be encoded.
+*/
+type ProductV1 struct {
+	Product
+	// Deprecated: use the status instead. True only for the published products
+	IsPublished bool `json:"is_published" example:"true" extensions:"x-deprecated=true"`
+}
+
+// The NewProductV1 function converts a product into its first version representation.
+func NewProductV1(p Product) ProductV1 {
+	return ProductV1{
+		Product:     p,
+		IsPublished: p.Published(),
+	}
+}
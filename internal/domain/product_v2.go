@@ -0,0 +1,164 @@
+package domain
+
+import (
+	"strconv"
+	"time"
+)
+
+/*
+The ProductV2 struct is the representation of a product in the second version of the API. It
+renames the "code_value" field to "code".
+*/
+type ProductV2 struct {
+	Id          int     `json:"id" example:"1"`
+	Name        string  `json:"name" example:"Pineapple" binding:"required"`
+	Quantity    int     `json:"quantity" example:"100" binding:"required"`
+	Code        string  `json:"code" example:"COD123" binding:"required"`
+	Status      Status  `json:"status" example:"published" enums:"draft,published,archived,discontinued"`
+	Expiration  string  `json:"expiration" example:"25/08/2030" binding:"required"`
+	Price       float64 `json:"price" example:"299" binding:"required" format:"float64"`
+	Currency    string  `json:"currency,omitempty" example:"USD"`
+	Category    string  `json:"category,omitempty" example:"fruits"`
+	Description string  `json:"description,omitempty" example:"Sweet pineapple from Costa Rica"`
+	// Content of the package: PackSize of the Unit of measure, like 0.5 kg
+	Unit     Unit    `json:"unit,omitempty" example:"kg" enums:"kg,L,unit"`
+	PackSize float64 `json:"pack_size,omitempty" example:"1.5" format:"float64"`
+	// Storage requirements: the zone the product is kept in, and the range of temperatures in °C it needs
+	Storage        StorageZone `json:"storage,omitempty" example:"chilled" enums:"ambient,chilled,frozen"`
+	MinTemperature *float64    `json:"min_temperature,omitempty" example:"2" format:"float64"`
+	MaxTemperature *float64    `json:"max_temperature,omitempty" example:"6" format:"float64"`
+	// Publish window: the product is sold from PublishFrom until PublishUntil, if they are set
+	PublishFrom  *time.Time `json:"publish_from,omitempty" example:"2030-01-01T09:00:00Z"`
+	PublishUntil *time.Time `json:"publish_until,omitempty" example:"2030-03-01T00:00:00Z"`
+	// Name and description in other locales than the base one, by locale
+	Translations map[string]Translation `json:"translations,omitempty"`
+	// Nutrition facts, nil if they are unknown
+	Nutrition *Nutrition `json:"nutrition,omitempty"`
+	// Lots of the stock, first-expire-first-out, which the quantity is the sum of if there are any
+	Lots []Lot `json:"lots,omitempty"`
+	// Summary of the visible reviews, set on the responses only and never stored
+	Rating *Rating `json:"rating,omitempty"`
+	// Price of one unit of measure, like the price per kg, set on the responses only and never stored
+	PricePerUnit *float64 `json:"price_per_unit,omitempty" example:"199.33" format:"float64"`
+}
+
+// The ProductRequestV2 struct is the partial update body of the second version of the API.
+type ProductRequestV2 struct {
+	Name        *string  `json:"name,omitempty" example:"Pineapple"`
+	Quantity    *int     `json:"quantity,omitempty" example:"100"`
+	Code        *string  `json:"code,omitempty" example:"COD123"`
+	Status      *Status  `json:"status,omitempty" example:"published" enums:"draft,published,archived,discontinued"`
+	Expiration  *string  `json:"expiration,omitempty" example:"25/08/2030"`
+	Price       *float64 `json:"price,omitempty" example:"299" format:"float64"`
+	Currency    *string  `json:"currency,omitempty" example:"USD"`
+	Category    *string  `json:"category,omitempty" example:"fruits"`
+	Description *string  `json:"description,omitempty" example:"Sweet pineapple from Costa Rica"`
+	// Content of the package, removed if they are empty
+	Unit     *Unit    `json:"unit,omitempty" example:"kg" enums:"kg,L,unit"`
+	PackSize *float64 `json:"pack_size,omitempty" example:"1.5" format:"float64"`
+	// Storage requirements, removed along with the temperatures if the zone is empty
+	Storage        *StorageZone `json:"storage,omitempty" example:"chilled" enums:"ambient,chilled,frozen"`
+	MinTemperature *float64     `json:"min_temperature,omitempty" example:"2" format:"float64"`
+	MaxTemperature *float64     `json:"max_temperature,omitempty" example:"6" format:"float64"`
+	// Bounds of the publish window, removed if they are the zero time
+	PublishFrom  *time.Time `json:"publish_from,omitempty" example:"2030-01-01T09:00:00Z"`
+	PublishUntil *time.Time `json:"publish_until,omitempty" example:"2030-03-01T00:00:00Z"`
+	// Translations set, or removed if null, by locale
+	Translations map[string]*Translation `json:"translations,omitempty"`
+	// Nutrition facts, replaced as a whole
+	Nutrition *Nutrition `json:"nutrition,omitempty"`
+	// Lots of the stock, replaced as a whole, or removed if the list is empty
+	Lots []Lot `json:"lots,omitempty"`
+}
+
+// The NewProductV2 function converts a product into its second version representation.
+func NewProductV2(p Product) ProductV2 {
+	return ProductV2{
+		Id:             p.Id,
+		Name:           p.Name,
+		Quantity:       p.Quantity,
+		Code:           p.CodeValue,
+		Status:         p.Status,
+		Expiration:     p.Expiration,
+		Price:          p.Price,
+		Currency:       p.Currency,
+		Category:       p.Category,
+		Description:    p.Description,
+		Unit:           p.Unit,
+		PackSize:       p.PackSize,
+		Storage:        p.Storage,
+		MinTemperature: p.MinTemperature,
+		MaxTemperature: p.MaxTemperature,
+		PublishFrom:    p.PublishFrom,
+		PublishUntil:   p.PublishUntil,
+		Translations:   p.Translations,
+		Nutrition:      p.Nutrition,
+		Lots:           p.Lots,
+		Rating:         p.Rating,
+		PricePerUnit:   p.PricePerUnit,
+	}
+}
+
+/*
+The ToProduct method converts a second version product into the domain product. The rating and the
+price per unit are computed, so they are left out.
+*/
+func (p ProductV2) ToProduct() Product {
+	return Product{
+		Id:             p.Id,
+		Name:           p.Name,
+		Quantity:       p.Quantity,
+		CodeValue:      p.Code,
+		Status:         p.Status,
+		Expiration:     p.Expiration,
+		Price:          p.Price,
+		Currency:       p.Currency,
+		Category:       p.Category,
+		Description:    p.Description,
+		Unit:           p.Unit,
+		PackSize:       p.PackSize,
+		Storage:        p.Storage,
+		MinTemperature: p.MinTemperature,
+		MaxTemperature: p.MaxTemperature,
+		PublishFrom:    p.PublishFrom,
+		PublishUntil:   p.PublishUntil,
+		Translations:   p.Translations,
+		Nutrition:      p.Nutrition,
+		Lots:           p.Lots,
+	}
+}
+
+// The ToProductRequest method converts a second version partial update into the domain one.
+func (p ProductRequestV2) ToProductRequest() ProductRequest {
+	return ProductRequest{
+		Name:           p.Name,
+		Quantity:       p.Quantity,
+		CodeValue:      p.Code,
+		Status:         p.Status,
+		Expiration:     p.Expiration,
+		Price:          p.Price,
+		Currency:       p.Currency,
+		Category:       p.Category,
+		Description:    p.Description,
+		Unit:           p.Unit,
+		PackSize:       p.PackSize,
+		Storage:        p.Storage,
+		MinTemperature: p.MinTemperature,
+		MaxTemperature: p.MaxTemperature,
+		PublishFrom:    p.PublishFrom,
+		PublishUntil:   p.PublishUntil,
+		Translations:   p.Translations,
+		Nutrition:      p.Nutrition,
+		Lots:           p.Lots,
+	}
+}
+
+// The ResourceType method returns the JSON:API resource type of a product.
+func (p ProductV2) ResourceType() string {
+	return "products"
+}
+
+// The ResourceID method returns the JSON:API resource identifier of a product.
+func (p ProductV2) ResourceID() string {
+	return strconv.Itoa(p.Id)
+}
@@ -0,0 +1,133 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Limits of the review fields checked by the Validate method.
+const (
+	MinRating              = 1
+	MaxRating              = 5
+	ReviewAuthorMaxLength  = 100
+	ReviewCommentMaxLength = 2000
+)
+
+/*
+The Review struct is the opinion of a customer about a product. The moderation fields are only set
+by the administrators and are left out of the public responses.
+
+	Id (int): Identifier of the review. Example: 1.
+	ProductId (int): Identifier of the reviewed product. Example: 1.
+	Rating (int): Stars given to the product, from 1 to 5. Example: 4.
+	Comment (string): Opinion of the customer, optional. Example: "Sweet and juicy".
+	Author (string): Name the customer signs the review with. Example: "Jane".
+	Flagged (bool): Whether an administrator flagged the review for a closer look.
+	Hidden (bool): Whether an administrator hid the review, which leaves it out of the listings and the rating.
+	ModerationNote (string): Note of the administrator who moderated the review.
+	CreatedAt (time.Time): Moment the review was posted.
+*/
+type Review struct {
+	Id             int       `json:"id" example:"1"`
+	ProductId      int       `json:"product_id" example:"1"`
+	Rating         int       `json:"rating" example:"4"`
+	Comment        string    `json:"comment,omitempty" example:"Sweet and juicy"`
+	Author         string    `json:"author" example:"Jane"`
+	Flagged        bool      `json:"flagged,omitempty" example:"false"`
+	Hidden         bool      `json:"hidden,omitempty" example:"false"`
+	ModerationNote string    `json:"moderation_note,omitempty" example:"Off-topic"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// The Public method returns the review without its moderation fields, as shown to the customers.
+func (r Review) Public() Review {
+	r.Flagged, r.Hidden, r.ModerationNote = false, false, ""
+	return r
+}
+
+// The ReviewRequest struct is the body used to post a review of a product.
+type ReviewRequest struct {
+	Rating  int    `json:"rating" example:"4" binding:"required"`
+	Comment string `json:"comment,omitempty" example:"Sweet and juicy"`
+	Author  string `json:"author" example:"Jane" binding:"required"`
+}
+
+/*
+The Validate method checks the review: the rating is between MinRating and MaxRating, the author is
+not blank and has at most ReviewAuthorMaxLength characters, and the comment has at most
+ReviewCommentMaxLength characters. It returns a *ValidationError listing every invalid field, or nil.
+*/
+func (r ReviewRequest) Validate() error {
+	var fields []FieldError
+	invalid := func(field, rule, message string) {
+		fields = append(fields, FieldError{Field: field, Rule: rule, Message: message})
+	}
+
+	if r.Rating < MinRating || r.Rating > MaxRating {
+		invalid("rating", "range", fmt.Sprintf("must be between %d and %d", MinRating, MaxRating))
+	}
+	author := strings.TrimSpace(r.Author)
+	switch {
+	case author == "":
+		invalid("author", "required", "must not be empty")
+	case utf8.RuneCountInString(author) > ReviewAuthorMaxLength:
+		invalid("author", "max_length", fmt.Sprintf("must have at most %d characters", ReviewAuthorMaxLength))
+	}
+	if utf8.RuneCountInString(r.Comment) > ReviewCommentMaxLength {
+		invalid("comment", "max_length", fmt.Sprintf("must have at most %d characters", ReviewCommentMaxLength))
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+	return &ValidationError{Fields: fields}
+}
+
+/*
+The ReviewModeration struct is a change of the moderation fields of a review. Only the fields
+present in the request are changed.
+*/
+type ReviewModeration struct {
+	Flagged *bool   `json:"flagged,omitempty" example:"true"`
+	Hidden  *bool   `json:"hidden,omitempty" example:"true"`
+	Note    *string `json:"moderation_note,omitempty" example:"Off-topic"`
+}
+
+// The Apply method returns the given review with the moderation fields present in the request changed.
+func (m ReviewModeration) Apply(review Review) Review {
+	if m.Flagged != nil {
+		review.Flagged = *m.Flagged
+	}
+	if m.Hidden != nil {
+		review.Hidden = *m.Hidden
+	}
+	if m.Note != nil {
+		review.ModerationNote = strings.TrimSpace(*m.Note)
+	}
+	return review
+}
+
+/*
+The Rating struct summarizes the visible reviews of a product.
+
+	Average (float64): Average of the ratings, rounded to two decimals. Example: 4.33.
+	Count (int): Number of visible reviews. Example: 3.
+*/
+type Rating struct {
+	Average float64 `json:"average" example:"4.33" format:"float64"`
+	Count   int     `json:"count" example:"3"`
+}
+
+// The NewRating function returns the summary of count ratings adding up to total, or nil if there are none.
+func NewRating(total, count int) *Rating {
+	if count == 0 {
+		return nil
+	}
+	return &Rating{
+		Average: math.Round(float64(total)/float64(count)*100) / 100,
+		Count:   count,
+	}
+}
@@ -0,0 +1,125 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// The Status type is the stage of the lifecycle of a product. Only the published products are sold.
+type Status string
+
+const (
+	// StatusDraft is a product being prepared, not sold yet. An empty status is a draft.
+	StatusDraft Status = "draft"
+	// StatusPublished is a product sold in the catalog.
+	StatusPublished Status = "published"
+	// StatusArchived is a product no longer sold for now, like an expired or seasonal one.
+	StatusArchived Status = "archived"
+	// StatusDiscontinued is a product that will never be sold again, kept for its history.
+	StatusDiscontinued Status = "discontinued"
+)
+
+var ErrInvalidStatus = errors.New("invalid status, expected draft, published, archived or discontinued")
+
+// Statuses are the valid statuses of the products, in the order of their lifecycle.
+var Statuses = []Status{StatusDraft, StatusPublished, StatusArchived, StatusDiscontinued}
+
+/*
+Statuses a product can change to from every status. A discontinued product can only be archived,
+so it is never sold again by mistake.
+*/
+var transitions = map[Status][]Status{
+	StatusDraft:        {StatusPublished, StatusArchived},
+	StatusPublished:    {StatusDraft, StatusArchived, StatusDiscontinued},
+	StatusArchived:     {StatusDraft, StatusPublished, StatusDiscontinued},
+	StatusDiscontinued: {StatusArchived},
+}
+
+// The StatusOf function returns the status of the legacy published flag: published or draft.
+func StatusOf(published bool) Status {
+	if published {
+		return StatusPublished
+	}
+	return StatusDraft
+}
+
+// The ParseStatuses function parses a comma separated list of statuses, like "draft,archived".
+func ParseStatuses(list string) ([]Status, error) {
+	var statuses []Status
+	for _, name := range strings.Split(list, ",") {
+		status := Status(strings.ToLower(strings.TrimSpace(name)))
+		if !status.Valid() {
+			return nil, ErrInvalidStatus
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// The Valid method checks if the status is one of the Statuses.
+func (s Status) Valid() bool {
+	_, ok := transitions[s]
+	return ok
+}
+
+// The OrDraft method returns the status, or StatusDraft if it is empty.
+func (s Status) OrDraft() Status {
+	if s == "" {
+		return StatusDraft
+	}
+	return s
+}
+
+// The CanChangeTo method checks if a product with the status can change to the given one. Keeping the same status is always allowed.
+func (s Status) CanChangeTo(next Status) bool {
+	if s.OrDraft() == next.OrDraft() {
+		return true
+	}
+	for _, allowed := range transitions[s.OrDraft()] {
+		if allowed == next.OrDraft() {
+			return true
+		}
+	}
+	return false
+}
+
+// The Published method checks if the product is sold in the catalog.
+func (p Product) Published() bool {
+	return p.Status == StatusPublished
+}
+
+/*
+The Live method checks if the product is sold at the given moment: it is published and the moment
+is within its publish window, from PublishFrom (included) until PublishUntil (excluded). A bound
+that is not set leaves the window open on its side.
+*/
+func (p Product) Live(now time.Time) bool {
+	return p.Published() && p.inWindow(now)
+}
+
+/*
+The ScheduledStatus method returns the status the publish window of the product calls for at the
+given moment, and whether it differs from the current one: a draft with a PublishFrom is published
+once the window opens, and a published product is archived once the window closes. The other
+statuses are set by hand only, so an archived product is never published again on its own. The
+PublishFrom is cleared once applied, so the opening of a window publishes the draft only once.
+*/
+func (p Product) ScheduledStatus(now time.Time) (Status, bool) {
+	switch {
+	case p.Status.OrDraft() == StatusDraft && p.PublishFrom != nil && p.inWindow(now):
+		return StatusPublished, true
+	case p.Published() && p.PublishUntil != nil && !now.Before(*p.PublishUntil):
+		return StatusArchived, true
+	default:
+		return p.Status, false
+	}
+}
+
+// Auxiliary method that checks if the given moment is within the publish window of the product.
+func (p Product) inWindow(now time.Time) bool {
+	if p.PublishFrom != nil && now.Before(*p.PublishFrom) {
+		return false
+	}
+	return p.PublishUntil == nil || now.Before(*p.PublishUntil)
+}
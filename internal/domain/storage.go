@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+)
+
+// The StorageZone type is the storage zone of the warehouses a product must be kept in.
+type StorageZone string
+
+const (
+	// StorageAmbient is the zone of the products kept at room temperature.
+	StorageAmbient StorageZone = "ambient"
+	// StorageChilled is the zone of the refrigerated products.
+	StorageChilled StorageZone = "chilled"
+	// StorageFrozen is the zone of the frozen products.
+	StorageFrozen StorageZone = "frozen"
+)
+
+var ErrInvalidStorage = errors.New("invalid storage zone, expected ambient, chilled or frozen")
+
+// StorageZones are the valid storage zones of the products, from the warmest to the coldest.
+var StorageZones = []StorageZone{StorageAmbient, StorageChilled, StorageFrozen}
+
+// The ParseStorageZones function parses a comma separated list of storage zones, like "chilled,frozen".
+func ParseStorageZones(list string) ([]StorageZone, error) {
+	var zones []StorageZone
+	for _, name := range strings.Split(list, ",") {
+		zone := StorageZone(strings.ToLower(strings.TrimSpace(name)))
+		if !zone.Valid() {
+			return nil, ErrInvalidStorage
+		}
+		zones = append(zones, zone)
+	}
+	return zones, nil
+}
+
+// The Valid method checks if the storage zone is one of the StorageZones.
+func (z StorageZone) Valid() bool {
+	for _, zone := range StorageZones {
+		if z == zone {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package domain
+
+import "math"
+
+// The Unit type is the unit of measure of the content of a product, which its pack size is counted in.
+type Unit string
+
+const (
+	// UnitKilogram is the unit of the products sold by weight.
+	UnitKilogram Unit = "kg"
+	// UnitLiter is the unit of the products sold by volume.
+	UnitLiter Unit = "L"
+	// UnitPiece is the unit of the products sold by count, like a pack of 6 eggs.
+	UnitPiece Unit = "unit"
+)
+
+// Units are the valid units of measure of the products.
+var Units = []Unit{UnitKilogram, UnitLiter, UnitPiece}
+
+// The Valid method checks if the unit is one of the Units.
+func (u Unit) Valid() bool {
+	for _, unit := range Units {
+		if u == unit {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+The UnitPrice method returns the price of one unit of measure of the product, like its price per
+kilogram, which the consumer protection rules require next to the price. It is the price divided by
+the pack size, rounded to cents, and false if the product has no unit of measure.
+*/
+func (p Product) UnitPrice() (float64, bool) {
+	if p.Unit == "" || p.PackSize <= 0 {
+		return 0, false
+	}
+	return math.Round(p.Price/p.PackSize*100) / 100, true
+}
+
+// The WithPricePerUnit method returns a copy of the product with its PricePerUnit set from its UnitPrice.
+func (p Product) WithPricePerUnit() Product {
+	p.PricePerUnit = nil
+	if price, ok := p.UnitPrice(); ok {
+		p.PricePerUnit = &price
+	}
+	return p
+}
@@ -0,0 +1,174 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+// Limits of the product fields checked by the Validate method.
+const (
+	NameMaxLength        = 100
+	DescriptionMaxLength = 5000
+	// CodeValuePattern is the default format of the code values: up to 32 letters, digits, dashes and underscores.
+	CodeValuePattern = `^[A-Za-z0-9][A-Za-z0-9_-]{0,31}$`
+)
+
+var ErrInvalidProduct = errors.New("invalid product fields")
+
+/*
+The FieldError struct describes a field of a product that breaks a validation rule.
+
+	Field (string): JSON name of the field. Example: "quantity".
+	Rule (string): Machine readable name of the broken rule. Example: "min".
+	Message (string): Description of the problem. Example: "must not be negative".
+*/
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// The String method returns the field error as "field: message".
+func (e FieldError) String() string {
+	return e.Field + ": " + e.Message
+}
+
+// The ValidationError struct is the error of a product with invalid fields. It matches ErrInvalidProduct.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		messages[i] = field.String()
+	}
+	return fmt.Sprintf("%s: %s", ErrInvalidProduct, strings.Join(messages, "; "))
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidProduct
+}
+
+/*
+The Validate method checks the business rules of the product fields: the name is required and has at
+most NameMaxLength characters, the quantity is not negative, the price is positive, the currency (if
+any) is an ISO 4217 code and the code value follows the configured format (see ConfigureCodeValues).
+The status (if any) is one of the Statuses, the unit of measure (if any) is one of the Units with a
+positive pack size, whole for UnitPiece, the storage zone (if any) is one of the StorageZones with a
+range of temperatures that is not reversed, the publish window closes after it opens, the
+description has at most DescriptionMaxLength characters, the nutrition facts (if any) add up, the
+lots (if any) have unique numbers and valid dates and add up to the quantity, and every translation
+has a name and a normalized locale other than the base one. It returns a *ValidationError listing
+every invalid field, or nil. The expiration date has rules of its own, see ValidateExpiration.
+*/
+func (p Product) Validate() error {
+	return p.validate(nil)
+}
+
+/*
+The ValidateChanges method checks the product like Validate, as the new state of the given previous
+product. The code value is only checked if it changed, so the products stored before a change of
+the code values format can still be updated, and the status can only change to the ones allowed
+from the previous one.
+*/
+func (p Product) ValidateChanges(previous Product) error {
+	return p.validate(&previous)
+}
+
+// Auxiliary method that checks the product fields as the new state of the previous product, if any.
+func (p Product) validate(previous *Product) error {
+	var fields []FieldError
+	invalid := func(field, rule, message string) {
+		fields = append(fields, FieldError{Field: field, Rule: rule, Message: message})
+	}
+
+	name := strings.TrimSpace(p.Name)
+	switch {
+	case name == "":
+		invalid("name", "required", "must not be empty")
+	case utf8.RuneCountInString(name) > NameMaxLength:
+		invalid("name", "max_length", fmt.Sprintf("must have at most %d characters", NameMaxLength))
+	}
+	if p.Quantity < 0 {
+		invalid("quantity", "min", "must not be negative")
+	}
+	if previous == nil || p.CodeValue != previous.CodeValue {
+		if fieldErr := validateCodeValue(p.CodeValue); fieldErr != nil {
+			fields = append(fields, *fieldErr)
+		}
+	}
+	if p.Price <= 0 {
+		invalid("price", "positive", "must be greater than 0")
+	}
+	switch {
+	case p.Status != "" && !p.Status.Valid():
+		invalid("status", "oneof", "must be draft, published, archived or discontinued")
+	case previous != nil && !previous.Status.CanChangeTo(p.Status):
+		invalid("status", "transition", fmt.Sprintf("cannot change from %s to %s", previous.Status.OrDraft(), p.Status.OrDraft()))
+	}
+	switch {
+	case p.Unit != "" && !p.Unit.Valid():
+		invalid("unit", "oneof", "must be kg, L or unit")
+	case p.Unit == "" && p.PackSize != 0:
+		invalid("unit", "required", "must be set along with pack_size")
+	case p.Unit != "" && p.PackSize <= 0:
+		invalid("pack_size", "positive", "must be greater than 0")
+	case p.Unit == UnitPiece && p.PackSize != math.Trunc(p.PackSize):
+		invalid("pack_size", "integer", "must be a whole number of units")
+	}
+	switch {
+	case p.Storage != "" && !p.Storage.Valid():
+		invalid("storage", "oneof", "must be ambient, chilled or frozen")
+	case p.Storage == "" && (p.MinTemperature != nil || p.MaxTemperature != nil):
+		invalid("storage", "required", "must be set along with the temperatures")
+	case p.MinTemperature != nil && p.MaxTemperature != nil && *p.MinTemperature > *p.MaxTemperature:
+		invalid("max_temperature", "min", "must not be below min_temperature")
+	}
+	if p.PublishFrom != nil && p.PublishUntil != nil && !p.PublishUntil.After(*p.PublishFrom) {
+		invalid("publish_until", "after", "must be after publish_from")
+	}
+	if p.Currency != "" && !ValidCurrency(p.Currency) {
+		invalid("currency", "iso4217", "must be a 3-letter ISO 4217 code, like USD")
+	}
+	if utf8.RuneCountInString(p.Description) > DescriptionMaxLength {
+		invalid("description", "max_length", fmt.Sprintf("must have at most %d characters", DescriptionMaxLength))
+	}
+	if p.Nutrition != nil {
+		fields = append(fields, p.Nutrition.validate("nutrition")...)
+	}
+	fields = append(fields, validateLots(p.Lots)...)
+	if quantity, ok := p.LotQuantity(); ok && p.Quantity != quantity {
+		invalid("quantity", "lots", fmt.Sprintf("must be the sum of the quantities of the lots, %d", quantity))
+	}
+	for _, locale := range sortedLocales(p.Translations) {
+		field := "translations." + locale
+		translation := p.Translations[locale]
+		if normalized, err := NormalizeLocale(locale); err != nil || normalized != locale {
+			invalid(field, "locale", "must be a lowercase language tag, like es or pt-br")
+			continue
+		}
+		if locale == BaseLocale() {
+			invalid(field, "base_locale", "must not be the base locale, which is the own name and description")
+			continue
+		}
+		name := strings.TrimSpace(translation.Name)
+		switch {
+		case name == "":
+			invalid(field+".name", "required", "must not be empty")
+		case utf8.RuneCountInString(name) > NameMaxLength:
+			invalid(field+".name", "max_length", fmt.Sprintf("must have at most %d characters", NameMaxLength))
+		}
+		if utf8.RuneCountInString(translation.Description) > DescriptionMaxLength {
+			invalid(field+".description", "max_length", fmt.Sprintf("must have at most %d characters", DescriptionMaxLength))
+		}
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+	return &ValidationError{Fields: fields}
+}
@@ -0,0 +1,155 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProduct_Validate(t *testing.T) {
+	valid := Product{Name: "Milk", Quantity: 0, CodeValue: "MILK-01", Expiration: "15/12/2030", Price: 0.5}
+	invalid := Product{Name: strings.Repeat("a", NameMaxLength+1), Quantity: -50, CodeValue: "bad code!", Price: 0, Currency: "usd"}
+	blank := valid
+	blank.Name = "   "
+
+	err := invalid.Validate()
+	var validationErr *ValidationError
+
+	// Assertions
+	assert.NoError(t, valid.Validate())
+	assert.ErrorIs(t, err, ErrInvalidProduct)
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, []FieldError{
+		{Field: "name", Rule: "max_length", Message: "must have at most 100 characters"},
+		{Field: "quantity", Rule: "min", Message: "must not be negative"},
+		{Field: "code_value", Rule: "pattern", Message: "must have up to 32 letters, digits, dashes or underscores"},
+		{Field: "price", Rule: "positive", Message: "must be greater than 0"},
+		{Field: "currency", Rule: "iso4217", Message: "must be a 3-letter ISO 4217 code, like USD"},
+	}, validationErr.Fields)
+	assert.ErrorIs(t, blank.Validate(), ErrInvalidProduct)
+}
+
+func TestProduct_ValidateChanges_Status(t *testing.T) {
+	draft := Product{Name: "Milk", CodeValue: "MILK-01", Expiration: "15/12/2030", Price: 0.5}
+	published, discontinued, unknown := draft, draft, draft
+	published.Status = StatusPublished
+	discontinued.Status = StatusDiscontinued
+	unknown.Status = "sold"
+
+	err := published.ValidateChanges(discontinued)
+	var validationErr *ValidationError
+
+	// Assertions
+	assert.NoError(t, published.ValidateChanges(draft))
+	assert.NoError(t, discontinued.ValidateChanges(published))
+	assert.NoError(t, discontinued.ValidateChanges(discontinued))
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, []FieldError{
+		{Field: "status", Rule: "transition", Message: "cannot change from discontinued to published"},
+	}, validationErr.Fields)
+	assert.ErrorIs(t, discontinued.ValidateChanges(draft), ErrInvalidProduct)
+	assert.ErrorIs(t, unknown.Validate(), ErrInvalidProduct)
+}
+
+func TestParseStatuses(t *testing.T) {
+	statuses, err := ParseStatuses(" Draft,archived")
+	_, errInvalid := ParseStatuses("draft,")
+
+	// Assertions
+	assert.NoError(t, err)
+	assert.Equal(t, []Status{StatusDraft, StatusArchived}, statuses)
+	assert.ErrorIs(t, errInvalid, ErrInvalidStatus)
+}
+
+func TestParseAllergens_Diets(t *testing.T) {
+	allergens, err := ParseAllergens("Milk, tree-nuts")
+	_, errAllergen := ParseAllergens("milk,nuts")
+	_, errDiet := ParseDiets("vegan,")
+
+	// Assertions
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"milk", "tree-nuts"}, allergens)
+	assert.ErrorIs(t, errAllergen, ErrInvalidAllergen)
+	assert.ErrorIs(t, errDiet, ErrInvalidDiet)
+}
+
+func TestProduct_ScheduledStatus(t *testing.T) {
+	now := time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+	launch := Product{Status: StatusDraft, PublishFrom: &past, PublishUntil: &future}
+	staged := Product{Status: StatusPublished, PublishFrom: &future}
+	ended := Product{Status: StatusPublished, PublishUntil: &now}
+	archived := Product{Status: StatusArchived, PublishFrom: &past}
+	invalid := Product{Name: "Milk", CodeValue: "MILK-01", Price: 0.5, PublishFrom: &future, PublishUntil: &past}
+
+	launchStatus, launchChanged := launch.ScheduledStatus(now)
+	_, stagedChanged := staged.ScheduledStatus(now)
+	endedStatus, endedChanged := ended.ScheduledStatus(now)
+	_, archivedChanged := archived.ScheduledStatus(now)
+	var validationErr *ValidationError
+
+	// Assertions
+	assert.Equal(t, StatusPublished, launchStatus)
+	assert.True(t, launchChanged)
+	assert.False(t, stagedChanged)
+	assert.False(t, staged.Live(now))
+	assert.True(t, staged.Live(future))
+	assert.Equal(t, StatusArchived, endedStatus)
+	assert.True(t, endedChanged)
+	assert.False(t, ended.Live(now))
+	assert.False(t, archivedChanged)
+	assert.ErrorAs(t, invalid.Validate(), &validationErr)
+	assert.Equal(t, []FieldError{{Field: "publish_until", Rule: "after", Message: "must be after publish_from"}}, validationErr.Fields)
+}
+
+func TestProduct_Validate_Unit(t *testing.T) {
+	milk := Product{Name: "Milk", CodeValue: "MILK-01", Expiration: "15/12/2030", Price: 1.5, Unit: UnitLiter, PackSize: 1.5}
+	eggs := Product{Name: "Eggs", CodeValue: "EGGS-12", Expiration: "15/12/2030", Price: 3, Unit: UnitPiece, PackSize: 12}
+	cases := map[string]Product{
+		"unit":      {Name: "Rice", CodeValue: "RICE", Price: 2, Unit: "lb", PackSize: 1},
+		"pack_size": {Name: "Rice", CodeValue: "RICE", Price: 2, Unit: UnitPiece, PackSize: 1.5},
+	}
+	withoutUnit := milk
+	withoutUnit.Unit = ""
+
+	pricePerLiter, ok := milk.UnitPrice()
+	_, okWithoutUnit := withoutUnit.UnitPrice()
+
+	// Assertions
+	assert.NoError(t, milk.Validate())
+	assert.NoError(t, eggs.Validate())
+	for field, product := range cases {
+		var validationErr *ValidationError
+		assert.ErrorAs(t, product.Validate(), &validationErr)
+		assert.Equal(t, field, validationErr.Fields[0].Field)
+	}
+	assert.ErrorIs(t, withoutUnit.Validate(), ErrInvalidProduct)
+	assert.True(t, ok)
+	assert.Equal(t, 1.0, pricePerLiter)
+	assert.False(t, okWithoutUnit)
+	assert.Equal(t, 0.25, *eggs.WithPricePerUnit().PricePerUnit)
+	assert.Nil(t, withoutUnit.WithPricePerUnit().PricePerUnit)
+}
+
+func TestProduct_Validate_Storage(t *testing.T) {
+	cold, warm := -18.0, 4.0
+	frozen := Product{Name: "Peas", CodeValue: "PEAS", Price: 2, Storage: StorageFrozen, MaxTemperature: &cold}
+	reversed := Product{Name: "Milk", CodeValue: "MILK", Price: 1, Storage: StorageChilled, MinTemperature: &warm, MaxTemperature: &cold}
+	withoutZone := Product{Name: "Milk", CodeValue: "MILK", Price: 1, MinTemperature: &warm}
+	storage := StorageZone("")
+	removed := ProductRequest{Storage: &storage}.Apply(frozen)
+
+	zones, err := ParseStorageZones("Chilled, frozen")
+	_, errInvalid := ParseStorageZones("cold")
+
+	// Assertions
+	assert.NoError(t, frozen.Validate())
+	assert.ErrorIs(t, reversed.Validate(), ErrInvalidProduct)
+	assert.ErrorIs(t, withoutZone.Validate(), ErrInvalidProduct)
+	assert.NoError(t, removed.Validate())
+	assert.Nil(t, removed.MaxTemperature)
+	assert.NoError(t, err)
+	assert.Equal(t, []StorageZone{StorageChilled, StorageFrozen}, zones)
+	assert.ErrorIs(t, errInvalid, ErrInvalidStorage)
+}
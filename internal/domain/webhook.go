@@ -0,0 +1,47 @@
+package domain
+
+import "time"
+
+/*
+The Webhook struct represents an external URL that is notified when products change. The secret
+is used to sign the notifications and is never included in the responses.
+*/
+type Webhook struct {
+	Id        int       `json:"id" example:"1"`
+	URL       string    `json:"url" example:"https://example.com/hooks/products"`
+	Secret    string    `json:"-"`
+	Events    []string  `json:"events,omitempty" example:"product.created"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// The WebhookRequest struct is the body used to register a new webhook.
+type WebhookRequest struct {
+	URL    string   `json:"url" example:"https://example.com/hooks/products" binding:"required,url"`
+	Secret string   `json:"secret" example:"s3cr3t" binding:"required"`
+	Events []string `json:"events,omitempty" example:"product.created"`
+}
+
+// The EntityId method returns the ID of a webhook, which identifies it in the in-memory repositories.
+func (w Webhook) EntityId() int {
+	return w.Id
+}
+
+// The WithId method returns a copy of the webhook with the given ID.
+func (w Webhook) WithId(id int) Webhook {
+	w.Id = id
+	return w
+}
+
+// The Subscribed method checks if the webhook must be notified of the given event type.
+func (w Webhook) Subscribed(eventType string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+
+	for _, e := range w.Events {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,197 @@
+package expiry
+
+import (
+	"errors"
+	"fmt"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/pkg/events"
+	"github.com/JoseObreque/go-web/pkg/mail"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// EventExpiring is the type of the webhook notifications of the stock about to expire.
+const EventExpiring = "products.expiring"
+
+/*
+The Item struct is stock about to expire: a lot of a product, or the whole stock of a product
+without lots.
+
+	ProductId (int): ID of the product. Example: 1.
+	Name (string): Name of the product. Example: "Milk".
+	CodeValue (string): Code value of the product. Example: "MILK1".
+	Lot (string): Number of the lot, empty for a product without lots. Example: "L2301".
+	Quantity (int): Units expiring. Example: 40.
+	Expiration (string): Expiration date. Example: "01/06/2030".
+	DaysLeft (int): Days until the expiration date, 0 if it is today. Example: 3.
+*/
+type Item struct {
+	ProductId  int    `json:"product_id"`
+	Name       string `json:"name"`
+	CodeValue  string `json:"code_value"`
+	Lot        string `json:"lot,omitempty"`
+	Quantity   int    `json:"quantity"`
+	Expiration string `json:"expiration"`
+	DaysLeft   int    `json:"days_left"`
+}
+
+// The Notification struct is the data of the EventExpiring events: the items expiring within the window of a recipient.
+type Notification struct {
+	Recipient  string `json:"recipient"`
+	WindowDays int    `json:"window_days"`
+	Items      []Item `json:"items"`
+}
+
+// The Report struct is the outcome of a check: the subscriptions notified and the notifications sent to them.
+type Report struct {
+	Notified int `json:"notified"`
+	Webhooks int `json:"webhooks"`
+	Emails   int `json:"emails"`
+}
+
+// The Webhooks interface delivers signed notifications to a URL, like the dispatcher of the webhooks does.
+type Webhooks interface {
+	Deliver(url, secret string, event events.Event) error
+}
+
+/*
+The Notifier struct checks the stock about to expire and notifies it to every expiry subscription,
+by webhook and by email. The checks are meant to be scheduled, so every recipient gets a daily
+digest of the stock expiring within its window, until the stock is removed or expires.
+*/
+type Notifier struct {
+	service    Service
+	products   func() []domain.Product
+	windowDays int
+	webhooks   Webhooks
+	mail       mail.Sender
+}
+
+/*
+The NewNotifier function returns a Notifier of the products returned by the given function, for the
+subscriptions of the service. The window of the subscriptions without one of their own is
+windowDays. The emails are not sent until a sender is set with WithMail.
+*/
+func NewNotifier(service Service, products func() []domain.Product, windowDays int, webhooks Webhooks) *Notifier {
+	return &Notifier{
+		service:    service,
+		products:   products,
+		windowDays: windowDays,
+		webhooks:   webhooks,
+	}
+}
+
+// The WithMail method sets the sender of the emails of the subscriptions and returns the notifier.
+func (n *Notifier) WithMail(sender mail.Sender) *Notifier {
+	n.mail = sender
+	return n
+}
+
+/*
+The Check method notifies the stock expiring within the window of every subscription at the given
+moment, skipping the subscriptions with nothing to notify. The webhook notifications are delivered
+in the background, with retries, and the emails right away. It returns the report of the check,
+along with the errors of the emails that could not be sent; the emails are skipped if no sender is
+set.
+*/
+func (n *Notifier) Check(now time.Time) (Report, error) {
+	var report Report
+	var errs []error
+	products := n.products()
+
+	for _, subscription := range n.service.GetAll("") {
+		window := subscription.WindowDays
+		if window == 0 {
+			window = n.windowDays
+		}
+		items := Expiring(products, now, window)
+		if len(items) == 0 {
+			continue
+		}
+		notification := Notification{Recipient: subscription.Recipient, WindowDays: window, Items: items}
+		report.Notified++
+
+		if subscription.URL != "" {
+			event := events.Event{Type: EventExpiring, Id: subscription.Id, Data: notification, Timestamp: now}
+			if err := n.webhooks.Deliver(subscription.URL, subscription.Secret, event); err != nil {
+				errs = append(errs, fmt.Errorf("subscription %d: %w", subscription.Id, err))
+			} else {
+				report.Webhooks++
+			}
+		}
+		if subscription.Email != "" && n.mail != nil {
+			subject, body := notification.email()
+			if err := n.mail.Send([]string{subscription.Email}, subject, body); err != nil {
+				errs = append(errs, fmt.Errorf("subscription %d: %w", subscription.Id, err))
+			} else {
+				report.Emails++
+			}
+		}
+	}
+	return report, errors.Join(errs...)
+}
+
+/*
+The Expiring function returns the stock of the products expiring within the given number of days
+from the given moment, today included, the first to expire before: the lots of the products with
+lots, and the whole stock of the other ones. The empty lots and products, and the ones already
+expired or with an invalid date, are left out.
+*/
+func Expiring(products []domain.Product, now time.Time, days int) []Item {
+	items := []Item{}
+	add := func(product domain.Product, lot string, quantity int, expiration string) {
+		date, err := domain.ParseExpiration(expiration)
+		if quantity <= 0 || err != nil {
+			return
+		}
+		// The dates are the start of their day, so today is 0 days left until the day ends
+		daysLeft := int(math.Ceil(date.Sub(now).Hours() / 24))
+		if daysLeft < 0 || daysLeft > days {
+			return
+		}
+		items = append(items, Item{ProductId: product.Id, Name: product.Name, CodeValue: product.CodeValue, Lot: lot,
+			Quantity: quantity, Expiration: expiration, DaysLeft: daysLeft})
+	}
+
+	for _, product := range products {
+		if len(product.Lots) == 0 {
+			add(product, "", product.Quantity, product.Expiration)
+		}
+		for _, lot := range product.Lots {
+			add(product, lot.Number, lot.Quantity, lot.Expiration)
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].DaysLeft != items[j].DaysLeft {
+			return items[i].DaysLeft < items[j].DaysLeft
+		}
+		return items[i].ProductId < items[j].ProductId
+	})
+	return items
+}
+
+// Auxiliary method that writes the notification as the subject and the plain text body of an email.
+func (n Notification) email() (string, string) {
+	subject := fmt.Sprintf("Stock expiring in the next %d days: %d items", n.WindowDays, len(n.Items))
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "Hello %s,\n\nThis stock expires in the next %d days:\n\n", n.Recipient, n.WindowDays)
+	for _, item := range n.Items {
+		fmt.Fprintf(&body, "- %s (%s)", item.Name, item.CodeValue)
+		if item.Lot != "" {
+			fmt.Fprintf(&body, ", lot %s", item.Lot)
+		}
+		fmt.Fprintf(&body, ": %d units on %s", item.Quantity, item.Expiration)
+		switch item.DaysLeft {
+		case 0:
+			body.WriteString(", today\n")
+		case 1:
+			body.WriteString(", tomorrow\n")
+		default:
+			fmt.Fprintf(&body, ", in %d days\n", item.DaysLeft)
+		}
+	}
+	return subject, body.String()
+}
@@ -0,0 +1,92 @@
+package expiry
+
+import (
+	"errors"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/pkg/events"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The webhooksStub struct records the delivered events instead of sending them.
+type webhooksStub struct {
+	urls   []string
+	events []events.Event
+}
+
+func (w *webhooksStub) Deliver(url, secret string, event events.Event) error {
+	w.urls = append(w.urls, url)
+	w.events = append(w.events, event)
+	return nil
+}
+
+// The mailStub struct records the sent emails, failing for the given recipient.
+type mailStub struct {
+	failing string
+	to      []string
+	bodies  []string
+}
+
+func (m *mailStub) Send(to []string, subject, body string) error {
+	if to[0] == m.failing {
+		return errors.New("mailbox unavailable")
+	}
+	m.to = append(m.to, to...)
+	m.bodies = append(m.bodies, body)
+	return nil
+}
+
+func testProducts() []domain.Product {
+	return []domain.Product{
+		{Id: 1, Name: "Milk", Quantity: 30, CodeValue: "MILK", Expiration: "01/01/2031", Lots: []domain.Lot{
+			{Number: "L1", Quantity: 10, Expiration: "10/03/2030"},
+			{Number: "L2", Quantity: 0, Expiration: "11/03/2030"},
+			{Number: "L3", Quantity: 20, Expiration: "01/05/2030"},
+		}},
+		{Id: 2, Name: "Bread", Quantity: 5, CodeValue: "BREAD", Expiration: "14/03/2030"},
+		{Id: 3, Name: "Cheese", Quantity: 2, CodeValue: "CHEESE", Expiration: "09/03/2030"},
+		{Id: 4, Name: "Butter", Quantity: 0, CodeValue: "BUTTER", Expiration: "10/03/2030"},
+	}
+}
+
+func TestExpiring(t *testing.T) {
+	now := time.Date(2030, time.March, 10, 9, 0, 0, 0, time.UTC)
+
+	items := Expiring(testProducts(), now, 4)
+
+	// Assertions: the empty and expired stock is left out, and today has 0 days left
+	assert.Equal(t, []Item{
+		{ProductId: 1, Name: "Milk", CodeValue: "MILK", Lot: "L1", Quantity: 10, Expiration: "10/03/2030", DaysLeft: 0},
+		{ProductId: 2, Name: "Bread", CodeValue: "BREAD", Quantity: 5, Expiration: "14/03/2030", DaysLeft: 4},
+	}, items)
+	assert.Empty(t, Expiring(testProducts(), now, -1))
+}
+
+func TestNotifier_Check(t *testing.T) {
+	service := NewService(NewRepository())
+	_, _ = service.Create(domain.ExpirySubscriptionRequest{Recipient: "Store", URL: "https://example.com/hook", Secret: "s3cr3t", Email: "store@example.com"})
+	_, _ = service.Create(domain.ExpirySubscriptionRequest{Recipient: "Warehouse", Email: "warehouse@example.com", WindowDays: 60})
+	_, _ = service.Create(domain.ExpirySubscriptionRequest{Recipient: "Nobody", URL: "https://example.com/other", Secret: "s3cr3t", WindowDays: 1})
+	webhooks := &webhooksStub{}
+	mailer := &mailStub{failing: "warehouse@example.com"}
+	notifier := NewNotifier(service, testProducts, 7, webhooks)
+	now := time.Date(2030, time.March, 11, 9, 0, 0, 0, time.UTC)
+
+	withoutMail, errWithoutMail := notifier.Check(now)
+	report, err := notifier.WithMail(mailer).Check(now)
+
+	// Assertions: the last subscription has nothing expiring within its window
+	assert.NoError(t, errWithoutMail)
+	assert.Equal(t, Report{Notified: 2, Webhooks: 1}, withoutMail)
+	assert.ErrorContains(t, err, "subscription 2: mailbox unavailable")
+	assert.Equal(t, Report{Notified: 2, Webhooks: 1, Emails: 1}, report)
+	assert.Equal(t, []string{"https://example.com/hook", "https://example.com/hook"}, webhooks.urls)
+	assert.Equal(t, EventExpiring, webhooks.events[0].Type)
+	notification := webhooks.events[0].Data.(Notification)
+	assert.Equal(t, 7, notification.WindowDays)
+	assert.Equal(t, []string{"BREAD"}, []string{notification.Items[0].CodeValue})
+	assert.Equal(t, []string{"store@example.com"}, mailer.to)
+	assert.True(t, strings.Contains(mailer.bodies[0], "- Bread (BREAD): 5 units on 14/03/2030, in 3 days\n"))
+}
@@ -0,0 +1,57 @@
+package expiry
+
+import (
+	"errors"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/pkg/memory"
+)
+
+var ErrNotFound = errors.New("expiry subscription not found")
+
+// Repository is the interface definition for the expiry subscriptions storage
+type Repository interface {
+	GetAll() []domain.ExpirySubscription
+	GetById(id int) (domain.ExpirySubscription, error)
+	Create(subscription domain.ExpirySubscription) domain.ExpirySubscription
+	Update(id int, subscription domain.ExpirySubscription) (domain.ExpirySubscription, error)
+	Delete(id int) error
+}
+
+// RepositoryImpl is the in-memory implementation of the repository interface
+type RepositoryImpl struct {
+	subscriptions *memory.Repository[domain.ExpirySubscription]
+}
+
+// The NewRepository function returns a new, empty instance of the repository.
+func NewRepository() Repository {
+	return &RepositoryImpl{
+		subscriptions: memory.NewRepository[domain.ExpirySubscription](nil, ErrNotFound),
+	}
+}
+
+// The GetAll method returns all the expiry subscriptions
+func (r *RepositoryImpl) GetAll() []domain.ExpirySubscription {
+	return r.subscriptions.GetAll()
+}
+
+// The GetById method returns an expiry subscription by its ID
+func (r *RepositoryImpl) GetById(id int) (domain.ExpirySubscription, error) {
+	return r.subscriptions.GetById(id)
+}
+
+// The Create method stores a new expiry subscription, assigning it a new ID.
+func (r *RepositoryImpl) Create(subscription domain.ExpirySubscription) domain.ExpirySubscription {
+	// Subscriptions have no unique keys, so the creation never fails
+	subscription, _ = r.subscriptions.Create(subscription)
+	return subscription
+}
+
+// The Update method replaces an expiry subscription. It returns an error if the subscription does not exist.
+func (r *RepositoryImpl) Update(id int, subscription domain.ExpirySubscription) (domain.ExpirySubscription, error) {
+	return r.subscriptions.Update(id, subscription)
+}
+
+// The Delete method deletes an expiry subscription. It returns an error if the subscription does not exist.
+func (r *RepositoryImpl) Delete(id int) error {
+	return r.subscriptions.Delete(id)
+}
@@ -0,0 +1,82 @@
+package expiry
+
+import (
+	"github.com/JoseObreque/go-web/internal/domain"
+	"strings"
+	"time"
+)
+
+type Service interface {
+	GetAll(recipient string) []domain.ExpirySubscription
+	Create(request domain.ExpirySubscriptionRequest) (domain.ExpirySubscription, error)
+	Update(id int, request domain.ExpirySubscriptionRequest) (domain.ExpirySubscription, error)
+	Delete(id int) error
+}
+
+type ServiceImpl struct {
+	repository Repository
+}
+
+// The NewService function returns a new instance of the service.
+func NewService(repository Repository) Service {
+	return &ServiceImpl{
+		repository: repository,
+	}
+}
+
+// The GetAll method returns the expiry subscriptions of the given recipient, ignoring the case, or all of them if it is empty.
+func (s *ServiceImpl) GetAll(recipient string) []domain.ExpirySubscription {
+	subscriptions := s.repository.GetAll()
+	if recipient == "" {
+		return subscriptions
+	}
+
+	matching := []domain.ExpirySubscription{}
+	for _, subscription := range subscriptions {
+		if strings.EqualFold(subscription.Recipient, strings.TrimSpace(recipient)) {
+			matching = append(matching, subscription)
+		}
+	}
+	return matching
+}
+
+// The Create method registers a new expiry subscription and returns it, or ErrInvalidSubscription if it notifies nowhere.
+func (s *ServiceImpl) Create(request domain.ExpirySubscriptionRequest) (domain.ExpirySubscription, error) {
+	if err := request.Validate(); err != nil {
+		return domain.ExpirySubscription{}, err
+	}
+	return s.repository.Create(newSubscription(request, time.Now())), nil
+}
+
+/*
+The Update method replaces the recipient, the destinations and the window of an expiry
+subscription, keeping its creation date. It returns an error if the subscription does not exist or
+the request notifies nowhere.
+*/
+func (s *ServiceImpl) Update(id int, request domain.ExpirySubscriptionRequest) (domain.ExpirySubscription, error) {
+	if err := request.Validate(); err != nil {
+		return domain.ExpirySubscription{}, err
+	}
+	previous, err := s.repository.GetById(id)
+	if err != nil {
+		return domain.ExpirySubscription{}, err
+	}
+	return s.repository.Update(id, newSubscription(request, previous.CreatedAt))
+}
+
+// The Delete method removes an expiry subscription. If the subscription does not exist, it returns an error.
+func (s *ServiceImpl) Delete(id int) error {
+	return s.repository.Delete(id)
+}
+
+// Auxiliary function that returns the subscription of a request, created at the given time.
+func newSubscription(request domain.ExpirySubscriptionRequest, createdAt time.Time) domain.ExpirySubscription {
+	return domain.ExpirySubscription{
+		Recipient:  strings.TrimSpace(request.Recipient),
+		URL:        request.URL,
+		Secret:     request.Secret,
+		Email:      request.Email,
+		WindowDays: request.WindowDays,
+		CreatedAt:  createdAt,
+	}
+}
@@ -0,0 +1,338 @@
+/*
+Package filter implements the expression language of the filter query parameter, which selects the
+products matching comparisons of their fields combined with AND, OR, NOT and parentheses, like
+`price > 100 AND quantity < 5 AND status = "published"`. The expressions are parsed into an AST that
+the repositories evaluate, in memory with the Match method or translated to their own queries.
+*/
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidFilter = errors.New("invalid filter")
+
+// The Kind type is the type of the values of a field.
+type Kind int
+
+const (
+	Number Kind = iota
+	Text
+	Bool
+	Date
+	// List fields hold several texts, and are only compared with contains
+	List
+)
+
+// The Operator type is a comparison operator.
+type Operator string
+
+const (
+	Equal          Operator = "="
+	NotEqual       Operator = "!="
+	Greater        Operator = ">"
+	GreaterOrEqual Operator = ">="
+	Less           Operator = "<"
+	LessOrEqual    Operator = "<="
+	// Contains matches the text fields holding the value, ignoring the case.
+	Contains Operator = "contains"
+)
+
+/*
+Kinds of the product fields that can be filtered, by their name in the expressions. The
+is_published flag, which the status replaced, is whether the status is published, and the
+nutrition flag is whether the product has nutrition facts. The allergens and diets of the
+products without nutrition facts are unknown, so an allergen-free product is matched with both,
+like `nutrition = true AND NOT allergens contains "milk"`. The products without a temperature match
+no comparison of it.
+*/
+var fields = map[string]Kind{
+	"id":              Number,
+	"name":            Text,
+	"quantity":        Number,
+	"code_value":      Text,
+	"status":          Text,
+	"is_published":    Bool,
+	"expiration":      Date,
+	"price":           Number,
+	"category":        Text,
+	"currency":        Text,
+	"unit":            Text,
+	"pack_size":       Number,
+	"nutrition":       Bool,
+	"allergens":       List,
+	"diets":           List,
+	"storage":         Text,
+	"min_temperature": Number,
+	"max_temperature": Number,
+}
+
+// Alternative names of the fields, like the ones of the second version of the API.
+var aliases = map[string]string{
+	"code": "code_value",
+}
+
+/*
+The Expr interface is a node of the AST of a filter expression: an And, an Or, a Not or a
+Comparison. The Match method reports whether a product matches the expression, and the String
+method returns the expression in its canonical form, suitable as a cache key.
+*/
+type Expr interface {
+	Match(product domain.Product) bool
+	String() string
+}
+
+// The And struct is an expression matching the products matched by both sides.
+type And struct {
+	Left, Right Expr
+}
+
+// The Or struct is an expression matching the products matched by any of the sides.
+type Or struct {
+	Left, Right Expr
+}
+
+// The Not struct is an expression matching the products not matched by the given one.
+type Not struct {
+	Expr Expr
+}
+
+// The Comparison struct is an expression comparing a field of the products with a value.
+type Comparison struct {
+	Field    string
+	Kind     Kind
+	Operator Operator
+	Value    Value
+}
+
+/*
+The Value struct is a literal of an expression. Only the field of its Kind is set: the dates are
+parsed in any of the accepted expiration layouts.
+*/
+type Value struct {
+	Number float64
+	Text   string
+	Bool   bool
+	Date   time.Time
+}
+
+func (e And) Match(product domain.Product) bool {
+	return e.Left.Match(product) && e.Right.Match(product)
+}
+
+func (e And) String() string {
+	return "(" + e.Left.String() + " AND " + e.Right.String() + ")"
+}
+
+func (e Or) Match(product domain.Product) bool {
+	return e.Left.Match(product) || e.Right.Match(product)
+}
+
+func (e Or) String() string {
+	return "(" + e.Left.String() + " OR " + e.Right.String() + ")"
+}
+
+func (e Not) Match(product domain.Product) bool {
+	return !e.Expr.Match(product)
+}
+
+func (e Not) String() string {
+	return "NOT " + e.Expr.String()
+}
+
+/*
+The Match method compares the field of the product with the value. Products whose expiration date
+cannot be parsed match no date comparison, the ones without a temperature no comparison of it, and the texts are compared ignoring the case. A list
+field contains the value if any of its texts is equal to it.
+*/
+func (e Comparison) Match(product domain.Product) bool {
+	switch e.Kind {
+	case Number:
+		number, ok := numberField(product, e.Field)
+		return ok && compare(number, e.Value.Number, e.Operator)
+	case Bool:
+		return (boolField(product, e.Field) == e.Value.Bool) == (e.Operator == Equal)
+	case List:
+		for _, text := range listField(product, e.Field) {
+			if strings.EqualFold(text, e.Value.Text) {
+				return true
+			}
+		}
+		return false
+	case Date:
+		expiration, err := domain.ParseExpiration(product.Expiration)
+		if err != nil {
+			return false
+		}
+		return compare(float64(expiration.Unix()), float64(e.Value.Date.Unix()), e.Operator)
+	default:
+		text := strings.ToLower(textField(product, e.Field))
+		value := strings.ToLower(e.Value.Text)
+		switch e.Operator {
+		case Contains:
+			return strings.Contains(text, value)
+		case NotEqual:
+			return text != value
+		default:
+			return text == value
+		}
+	}
+}
+
+func (e Comparison) String() string {
+	var value string
+	switch e.Kind {
+	case Number:
+		value = strconv.FormatFloat(e.Value.Number, 'f', -1, 64)
+	case Bool:
+		value = strconv.FormatBool(e.Value.Bool)
+	case Date:
+		value = strconv.Quote(e.Value.Date.Format(domain.ISOExpirationLayout))
+	default:
+		value = strconv.Quote(e.Value.Text)
+	}
+	return fmt.Sprintf("%s %s %s", e.Field, e.Operator, value)
+}
+
+/*
+The Dietary function returns an expression matching the products with nutrition facts free of all
+the excluded allergens and suitable for all the given diets, like nutrition = true AND NOT
+allergens contains "milk" AND diets contains "vegan". It returns nil without allergens or diets.
+*/
+func Dietary(excludedAllergens, diets []string) Expr {
+	if len(excludedAllergens) == 0 && len(diets) == 0 {
+		return nil
+	}
+	var expr Expr = Comparison{Field: "nutrition", Kind: Bool, Operator: Equal, Value: Value{Bool: true}}
+	for _, allergen := range excludedAllergens {
+		expr = And{Left: expr, Right: Not{Expr: Comparison{Field: "allergens", Kind: List, Operator: Contains, Value: Value{Text: allergen}}}}
+	}
+	for _, diet := range diets {
+		expr = And{Left: expr, Right: Comparison{Field: "diets", Kind: List, Operator: Contains, Value: Value{Text: diet}}}
+	}
+	return expr
+}
+
+/*
+The Statuses function returns an expression matching the products with any of the given statuses,
+like status = "draft" OR status = "archived". It returns nil without statuses.
+*/
+func Statuses(statuses []domain.Status) Expr {
+	values := make([]string, len(statuses))
+	for i, status := range statuses {
+		values[i] = string(status)
+	}
+	return anyOf("status", values)
+}
+
+/*
+The StorageZones function returns an expression matching the products kept in any of the given
+storage zones, like storage = "chilled" OR storage = "frozen". It returns nil without zones.
+*/
+func StorageZones(zones []domain.StorageZone) Expr {
+	values := make([]string, len(zones))
+	for i, zone := range zones {
+		values[i] = string(zone)
+	}
+	return anyOf("storage", values)
+}
+
+// Auxiliary function that returns an expression matching the products whose text field is any of the values.
+func anyOf(field string, values []string) Expr {
+	var expr Expr
+	for _, value := range values {
+		comparison := Comparison{Field: field, Kind: Text, Operator: Equal, Value: Value{Text: value}}
+		if expr == nil {
+			expr = comparison
+		} else {
+			expr = Or{Left: expr, Right: comparison}
+		}
+	}
+	return expr
+}
+
+// Auxiliary function that returns the value of a numeric field of a product, and whether it has one.
+func numberField(product domain.Product, field string) (float64, bool) {
+	switch field {
+	case "id":
+		return float64(product.Id), true
+	case "quantity":
+		return float64(product.Quantity), true
+	case "pack_size":
+		return product.PackSize, true
+	case "min_temperature", "max_temperature":
+		temperature := product.MinTemperature
+		if field == "max_temperature" {
+			temperature = product.MaxTemperature
+		}
+		if temperature == nil {
+			return 0, false
+		}
+		return *temperature, true
+	default:
+		return product.Price, true
+	}
+}
+
+// Auxiliary function that returns the value of a text field of a product.
+func textField(product domain.Product, field string) string {
+	switch field {
+	case "name":
+		return product.Name
+	case "code_value":
+		return product.CodeValue
+	case "category":
+		return product.Category
+	case "status":
+		return string(product.Status.OrDraft())
+	case "unit":
+		return string(product.Unit)
+	case "storage":
+		return string(product.Storage)
+	default:
+		return product.PriceCurrency()
+	}
+}
+
+// Auxiliary function that returns the value of a flag of a product.
+func boolField(product domain.Product, field string) bool {
+	if field == "nutrition" {
+		return product.Nutrition != nil
+	}
+	return product.Published()
+}
+
+// Auxiliary function that returns the texts of a list field of a product.
+func listField(product domain.Product, field string) []string {
+	switch {
+	case product.Nutrition == nil:
+		return nil
+	case field == "diets":
+		return product.Nutrition.Diets
+	default:
+		return product.Nutrition.Allergens
+	}
+}
+
+// Auxiliary function that compares two numbers with an ordering operator.
+func compare(a, b float64, operator Operator) bool {
+	switch operator {
+	case NotEqual:
+		return a != b
+	case Greater:
+		return a > b
+	case GreaterOrEqual:
+		return a >= b
+	case Less:
+		return a < b
+	case LessOrEqual:
+		return a <= b
+	default:
+		return a == b
+	}
+}
@@ -0,0 +1,92 @@
+package filter
+
+import (
+	"errors"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParse_Match(t *testing.T) {
+	chilledMin, chilledMax := 2.0, 6.0
+	products := []domain.Product{
+		{Id: 1, Name: "Pineapple juice", Quantity: 2, CodeValue: "J1", Status: domain.StatusPublished, Expiration: "15/12/2030", Price: 150, Category: "Drinks",
+			Nutrition: &domain.Nutrition{Allergens: []string{"sulphites"}}},
+		{Id: 2, Name: "Apple", Quantity: 10, CodeValue: "F1", Status: domain.StatusPublished, Expiration: "2031-01-10", Price: 200, Category: "Fruits",
+			Nutrition: &domain.Nutrition{}},
+		{Id: 3, Name: "Orange", Quantity: 1, CodeValue: "F2", Status: domain.StatusDraft, Expiration: "01/06/2030", Price: 120, Currency: "EUR",
+			Storage: domain.StorageChilled, MinTemperature: &chilledMin, MaxTemperature: &chilledMax},
+		{Id: 4, Name: "Banana", Quantity: 3, CodeValue: "F3", Status: domain.StatusPublished, Expiration: "invalid", Price: 50},
+	}
+	cases := []struct {
+		expression string
+		expected   []int
+	}{
+		{"price > 100 AND quantity < 5 AND is_published = true", []int{1}},
+		{"price >= 150 or NOT (is_published = TRUE)", []int{1, 2, 3}},
+		{"price > 100 AND quantity < 5 OR name = 'banana'", []int{1, 3, 4}},
+		{`name contains "APPLE" AND category != 'Drinks'`, []int{2}},
+		{`code = "f2"`, []int{3}},
+		{`currency = 'USD'`, []int{1, 2, 4}},
+		{`expiration < "2031-01-01"`, []int{1, 3}},
+		{`expiration = '10/01/2031'`, []int{2}},
+		{"id != 2 AND price <= -1.5", nil},
+		{`name = 'It''s'`, nil},
+		{`allergens contains 'Sulphites'`, []int{1}},
+		{`nutrition = true AND NOT allergens contains "sulphites"`, []int{2}},
+		{`storage = "Chilled" AND max_temperature <= 8`, []int{3}},
+		{"min_temperature < 10", []int{3}},
+		{"NOT min_temperature < 10", []int{1, 2, 4}},
+	}
+
+	for _, testCase := range cases {
+		expr, err := Parse(testCase.expression)
+		var matched []int
+		for _, p := range products {
+			if err == nil && expr.Match(p) {
+				matched = append(matched, p.Id)
+			}
+		}
+
+		// Assertions
+		assert.NoError(t, err, testCase.expression)
+		assert.Equal(t, testCase.expected, matched, testCase.expression)
+	}
+}
+
+func TestParse_String(t *testing.T) {
+	expr, err := Parse(`NOT price>1 and (Name CONTAINS 'a' OR code = "x")`)
+
+	// Assertions: AND binds tighter than OR, and the fields and keywords are normalized
+	assert.NoError(t, err)
+	assert.Equal(t, `(NOT price > 1 AND (name contains "a" OR code_value = "x"))`, expr.String())
+}
+
+func TestParse_Errors(t *testing.T) {
+	cases := map[string]int{
+		"":                        1,
+		"price >":                 8,
+		"price > 'cheap'":         9,
+		"weight > 1":              1,
+		"name > 'a'":              6,
+		"is_published = yes":      16,
+		"(price > 1":              11,
+		"price > 1 price":         11,
+		"name = 'unterminated":    8,
+		"price ! 1":               7,
+		"price > 1 # comment":     11,
+		`expiration < "tomorrow"`: 14,
+		`allergens = "milk"`:      11,
+	}
+
+	for expression, position := range cases {
+		_, err := Parse(expression)
+		var syntaxErr *SyntaxError
+
+		// Assertions
+		assert.True(t, errors.Is(err, ErrInvalidFilter), expression)
+		if assert.True(t, errors.As(err, &syntaxErr), expression) {
+			assert.Equal(t, position, syntaxErr.Position, expression)
+		}
+	}
+}
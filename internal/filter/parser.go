@@ -0,0 +1,318 @@
+package filter
+
+import (
+	"fmt"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Limits of the expressions, so a request cannot make the parser or the queries too expensive.
+const (
+	MaxLength = 1000
+	maxDepth  = 32
+)
+
+/*
+The SyntaxError struct is the error of an expression that cannot be parsed, with the position (1
+based, in characters) where the problem was found. It matches ErrInvalidFilter.
+*/
+type SyntaxError struct {
+	Position int
+	Message  string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s at position %d: %s", ErrInvalidFilter, e.Position, e.Message)
+}
+
+func (e *SyntaxError) Unwrap() error {
+	return ErrInvalidFilter
+}
+
+/*
+The Parse function parses a filter expression. The comparisons have a field on the left and a
+literal on the right: a number, true or false, or a quoted text ('...' or "..."). The dates are
+quoted texts too. AND binds tighter than OR, and the keywords are case insensitive:
+
+	price > 100 AND (category = "Fruits" OR name contains 'juice') AND NOT is_published = false
+*/
+func Parse(expression string) (Expr, error) {
+	if len(expression) > MaxLength {
+		return nil, &SyntaxError{Position: MaxLength + 1, Message: fmt.Sprintf("longer than %d characters", MaxLength)}
+	}
+	tokens, err := tokenize(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	expr, err := p.or(0)
+	if err != nil {
+		return nil, err
+	}
+	if next := p.peek(); next.kind != tokenEnd {
+		return nil, p.errorf(next, "unexpected %s", next)
+	}
+	return expr, nil
+}
+
+// Kinds of the tokens of an expression.
+const (
+	tokenEnd = iota
+	tokenWord
+	tokenNumber
+	tokenString
+	tokenOperator
+	tokenOpen
+	tokenClose
+)
+
+// The token struct is a lexical unit of an expression, with its position in characters.
+type token struct {
+	kind     int
+	text     string
+	position int
+}
+
+func (t token) String() string {
+	if t.kind == tokenEnd {
+		return "end of filter"
+	}
+	return strconv.Quote(t.text)
+}
+
+// Auxiliary function that splits an expression into its tokens.
+func tokenize(expression string) ([]token, error) {
+	runes := []rune(expression)
+	var tokens []token
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		start := i
+		switch {
+		case unicode.IsSpace(r):
+			i++
+			continue
+		case r == '(' || r == ')':
+			kind := tokenOpen
+			if r == ')' {
+				kind = tokenClose
+			}
+			tokens = append(tokens, token{kind: kind, text: string(r), position: start + 1})
+			i++
+		case r == '\'' || r == '"':
+			// Quoted texts end at the same quote, which is escaped by doubling it
+			var text strings.Builder
+			for i++; ; i++ {
+				if i >= len(runes) {
+					return nil, &SyntaxError{Position: start + 1, Message: "unterminated text"}
+				}
+				if runes[i] == r {
+					if i+1 < len(runes) && runes[i+1] == r {
+						i++
+					} else {
+						break
+					}
+				}
+				text.WriteRune(runes[i])
+			}
+			i++
+			tokens = append(tokens, token{kind: tokenString, text: text.String(), position: start + 1})
+		case strings.ContainsRune("=!<>", r):
+			i++
+			if i < len(runes) && runes[i] == '=' && r != '=' {
+				i++
+			}
+			text := string(runes[start:i])
+			if text == "!" {
+				return nil, &SyntaxError{Position: start + 1, Message: `expected "!="`}
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: text, position: start + 1})
+		case unicode.IsDigit(r) || r == '-' || r == '.':
+			for i++; i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.'); i++ {
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: string(runes[start:i]), position: start + 1})
+		case unicode.IsLetter(r) || r == '_':
+			for i++; i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_'); i++ {
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: string(runes[start:i]), position: start + 1})
+		default:
+			return nil, &SyntaxError{Position: start + 1, Message: fmt.Sprintf("unexpected character %q", r)}
+		}
+	}
+	return append(tokens, token{kind: tokenEnd, position: len(runes) + 1}), nil
+}
+
+// The parser struct is a recursive descent parser over the tokens of an expression.
+type parser struct {
+	tokens  []token
+	current int
+}
+
+// Auxiliary method that returns the next token without consuming it.
+func (p *parser) peek() token {
+	return p.tokens[p.current]
+}
+
+// Auxiliary method that consumes the next token.
+func (p *parser) next() token {
+	t := p.tokens[p.current]
+	if t.kind != tokenEnd {
+		p.current++
+	}
+	return t
+}
+
+// Auxiliary method that consumes the next token if it is the given keyword.
+func (p *parser) keyword(word string) bool {
+	if t := p.peek(); t.kind == tokenWord && strings.EqualFold(t.text, word) {
+		p.current++
+		return true
+	}
+	return false
+}
+
+// Auxiliary method that returns a syntax error at the position of a token.
+func (p *parser) errorf(t token, format string, args ...interface{}) error {
+	return &SyntaxError{Position: t.position, Message: fmt.Sprintf(format, args...)}
+}
+
+// or := and (OR and)*
+func (p *parser) or(depth int) (Expr, error) {
+	left, err := p.and(depth)
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		right, err := p.and(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = Or{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+// and := unary (AND unary)*
+func (p *parser) and(depth int) (Expr, error) {
+	left, err := p.unary(depth)
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		right, err := p.unary(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = And{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+// unary := NOT unary | "(" or ")" | comparison
+func (p *parser) unary(depth int) (Expr, error) {
+	if depth > maxDepth {
+		return nil, p.errorf(p.peek(), "nested more than %d levels", maxDepth)
+	}
+	if p.keyword("not") {
+		expr, err := p.unary(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return Not{Expr: expr}, nil
+	}
+	if p.peek().kind == tokenOpen {
+		p.next()
+		expr, err := p.or(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if t := p.next(); t.kind != tokenClose {
+			return nil, p.errorf(t, `expected ")", found %s`, t)
+		}
+		return expr, nil
+	}
+	return p.comparison()
+}
+
+// comparison := field operator literal
+func (p *parser) comparison() (Expr, error) {
+	fieldToken := p.next()
+	if fieldToken.kind != tokenWord {
+		return nil, p.errorf(fieldToken, "expected a field, found %s", fieldToken)
+	}
+	field := strings.ToLower(fieldToken.text)
+	if alias, ok := aliases[field]; ok {
+		field = alias
+	}
+	kind, ok := fields[field]
+	if !ok {
+		return nil, p.errorf(fieldToken, "unknown field %s", fieldToken)
+	}
+
+	operatorToken := p.next()
+	var operator Operator
+	switch {
+	case operatorToken.kind == tokenOperator:
+		operator = Operator(operatorToken.text)
+	case operatorToken.kind == tokenWord && strings.EqualFold(operatorToken.text, string(Contains)):
+		operator = Contains
+	default:
+		return nil, p.errorf(operatorToken, "expected an operator, found %s", operatorToken)
+	}
+	if !allowed(kind, operator) {
+		return nil, p.errorf(operatorToken, "operator %s cannot be used with %s", operator, field)
+	}
+
+	valueToken := p.next()
+	value, err := p.value(kind, valueToken)
+	if err != nil {
+		return nil, err
+	}
+	return Comparison{Field: field, Kind: kind, Operator: operator, Value: value}, nil
+}
+
+// Auxiliary method that parses the literal compared with a field of the given kind.
+func (p *parser) value(kind Kind, t token) (Value, error) {
+	switch kind {
+	case Number:
+		if t.kind == tokenNumber {
+			if number, err := strconv.ParseFloat(t.text, 64); err == nil {
+				return Value{Number: number}, nil
+			}
+		}
+		return Value{}, p.errorf(t, "expected a number, found %s", t)
+	case Bool:
+		if t.kind == tokenWord && (strings.EqualFold(t.text, "true") || strings.EqualFold(t.text, "false")) {
+			return Value{Bool: strings.EqualFold(t.text, "true")}, nil
+		}
+		return Value{}, p.errorf(t, "expected true or false, found %s", t)
+	case Date:
+		if t.kind == tokenString {
+			if date, err := domain.ParseExpiration(t.text); err == nil {
+				return Value{Date: date}, nil
+			}
+		}
+		return Value{}, p.errorf(t, "expected a quoted date, found %s", t)
+	default:
+		if t.kind == tokenString {
+			return Value{Text: t.text}, nil
+		}
+		return Value{}, p.errorf(t, "expected a quoted text, found %s", t)
+	}
+}
+
+// Auxiliary function that checks if an operator can compare the fields of a kind.
+func allowed(kind Kind, operator Operator) bool {
+	switch {
+	case kind == List:
+		return operator == Contains
+	case operator == Equal || operator == NotEqual:
+		return true
+	case operator == Contains:
+		return kind == Text
+	default:
+		return kind == Number || kind == Date
+	}
+}
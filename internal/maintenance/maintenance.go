@@ -0,0 +1,100 @@
+/*
+Package maintenance holds the read-only maintenance mode of the catalog, turned on during the store
+migrations and restores so the catalog keeps being read but cannot be changed.
+*/
+package maintenance
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrReadOnly = errors.New("the catalog is read-only during a maintenance")
+
+/*
+The Status struct describes the maintenance mode.
+
+	Enabled (bool): Whether the changes of the catalog are rejected.
+	Reason (string): Why the catalog is under maintenance, shown to the clients.
+	Since (time.Time): When the maintenance started.
+	RetryAfter (int): Seconds the clients are asked to wait before retrying their changes.
+*/
+type Status struct {
+	Enabled    bool       `json:"enabled"`
+	Reason     string     `json:"reason,omitempty"`
+	Since      *time.Time `json:"since,omitempty"`
+	RetryAfter int        `json:"retry_after"`
+}
+
+// The Mode struct is the runtime toggle of the maintenance mode, safe for concurrent use.
+type Mode struct {
+	mu         sync.RWMutex
+	enabled    bool
+	reason     string
+	since      time.Time
+	retryAfter time.Duration
+}
+
+// The NewMode function returns a maintenance mode, enabled or not, asking the clients to retry after retryAfter.
+func NewMode(enabled bool, retryAfter time.Duration) *Mode {
+	m := &Mode{
+		retryAfter: retryAfter,
+	}
+	if enabled {
+		m.Enable("", 0)
+	}
+	return m
+}
+
+// The Enable method starts a maintenance. A zero retryAfter keeps the current one.
+func (m *Mode) Enable(reason string, retryAfter time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.enabled {
+		m.since = time.Now()
+	}
+	m.enabled = true
+	m.reason = reason
+	if retryAfter > 0 {
+		m.retryAfter = retryAfter
+	}
+}
+
+// The Disable method ends the maintenance.
+func (m *Mode) Disable() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.enabled = false
+	m.reason = ""
+}
+
+// The Status method returns the current state of the maintenance mode.
+func (m *Mode) Status() Status {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	status := Status{
+		Enabled:    m.enabled,
+		Reason:     m.reason,
+		RetryAfter: int(m.retryAfter.Seconds()),
+	}
+	if m.enabled {
+		since := m.since
+		status.Since = &since
+	}
+	return status
+}
+
+// The Check method returns ErrReadOnly during a maintenance, and nil otherwise.
+func (m *Mode) Check() error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.enabled {
+		return ErrReadOnly
+	}
+	return nil
+}
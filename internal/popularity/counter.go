@@ -0,0 +1,198 @@
+package popularity
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// Number of views waiting to be counted before the new ones are dropped.
+const DefaultBuffer = 4096
+
+/*
+The Count struct is the number of views of a product.
+
+	ProductId (int): Identifier of the product. Example: 1.
+	Views (int): Number of views of the product. Example: 42.
+*/
+type Count struct {
+	ProductId int `json:"product_id" example:"1"`
+	Views     int `json:"views" example:"42"`
+}
+
+/*
+The event struct is a view of a product waiting to be counted, or a barrier closing done once every
+view queued before it has been counted.
+*/
+type event struct {
+	productId int
+	done      chan struct{}
+}
+
+/*
+The Counter struct counts the views of the products. The views are queued and counted in the
+background, so recording them never slows down the requests, and a view is dropped if the queue is
+full. Only the number of views of every product is kept, nothing about who viewed them. The counts
+are kept in memory and written to a JSON file on every Flush, so they survive a restart; an empty
+filepath keeps them in memory only.
+*/
+type Counter struct {
+	filepath string
+	events   chan event
+	// Views dropped because the queue was full
+	dropped uint64
+
+	mu sync.Mutex
+	// Views counted since the last flush, by product ID
+	pending map[int]int
+	// Views written to the file, by product ID
+	totals map[int]int
+}
+
+/*
+The NewCounter function returns a counter persisted in the given file, loading the counts of a
+previous run if the file exists, and starts counting the views queued, up to buffer of them.
+*/
+func NewCounter(filepath string, buffer int) (*Counter, error) {
+	c := &Counter{
+		filepath: filepath,
+		events:   make(chan event, buffer),
+		pending:  make(map[int]int),
+		totals:   make(map[int]int),
+	}
+	if filepath != "" {
+		data, err := os.ReadFile(filepath)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+		case err != nil:
+			return nil, err
+		default:
+			if err = json.Unmarshal(data, &c.totals); err != nil {
+				return nil, err
+			}
+			if c.totals == nil {
+				c.totals = make(map[int]int)
+			}
+		}
+	}
+
+	go c.run()
+	return c, nil
+}
+
+// The View method queues a view of the product with the given ID, without waiting for it to be counted.
+func (c *Counter) View(productId int) {
+	select {
+	case c.events <- event{productId: productId}:
+	default:
+		atomic.AddUint64(&c.dropped, 1)
+	}
+}
+
+/*
+The Top method returns the counts of the limit most viewed products, most viewed first and by ID
+on a tie, including the views not flushed yet. The products without views are left out.
+*/
+func (c *Counter) Top(limit int) []Count {
+	c.sync()
+	c.mu.Lock()
+	counts := make([]Count, 0, len(c.totals)+len(c.pending))
+	for id, views := range c.totals {
+		counts = append(counts, Count{ProductId: id, Views: views + c.pending[id]})
+	}
+	for id, views := range c.pending {
+		if _, ok := c.totals[id]; !ok {
+			counts = append(counts, Count{ProductId: id, Views: views})
+		}
+	}
+	c.mu.Unlock()
+
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Views != counts[j].Views {
+			return counts[i].Views > counts[j].Views
+		}
+		return counts[i].ProductId < counts[j].ProductId
+	})
+	if limit >= 0 && len(counts) > limit {
+		counts = counts[:limit]
+	}
+	return counts
+}
+
+/*
+The Flush method adds the views counted since the last flush to the totals and writes them to the
+file, if any. It returns the number of views flushed and dropped since the last flush.
+*/
+func (c *Counter) Flush() (flushed int, dropped int, err error) {
+	c.sync()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for id, views := range c.pending {
+		c.totals[id] += views
+		flushed += views
+	}
+	c.pending = make(map[int]int)
+	dropped = int(atomic.SwapUint64(&c.dropped, 0))
+	return flushed, dropped, c.save()
+}
+
+/*
+The Repoint method moves the views of a product to another one, like the product a duplicate is
+merged into, and writes the totals to the file, if any.
+*/
+func (c *Counter) Repoint(fromId int, toId int) error {
+	c.sync()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, counts := range []map[int]int{c.pending, c.totals} {
+		if views, ok := counts[fromId]; ok {
+			counts[toId] += views
+			delete(counts, fromId)
+		}
+	}
+	return c.save()
+}
+
+// Auxiliary method that counts the queued views, in the order they were queued, for the life of the process.
+func (c *Counter) run() {
+	for e := range c.events {
+		if e.done != nil {
+			close(e.done)
+			continue
+		}
+		c.mu.Lock()
+		c.pending[e.productId]++
+		c.mu.Unlock()
+	}
+}
+
+// Auxiliary method that waits until every view queued so far has been counted.
+func (c *Counter) sync() {
+	done := make(chan struct{})
+	c.events <- event{done: done}
+	<-done
+}
+
+// Auxiliary method that writes the totals to the file, if any. It must hold the lock.
+func (c *Counter) save() error {
+	if c.filepath == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(c.totals)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so a crash never leaves a truncated file
+	tmp := c.filepath + ".tmp"
+	if err = os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.filepath)
+}
@@ -0,0 +1,46 @@
+package popularity
+
+import (
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+)
+
+func TestCounter(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "views.json")
+	counter, err := NewCounter(file, 16)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, id := range []int{3, 1, 3, 2, 3, 1} {
+		counter.View(id)
+	}
+	top := counter.Top(2)
+	flushed, dropped, errFlush := counter.Flush()
+	counter.View(2)
+	reloaded, errReload := NewCounter(file, 16)
+
+	// Assertions
+	assert.Equal(t, []Count{{ProductId: 3, Views: 3}, {ProductId: 1, Views: 2}}, top)
+	assert.Equal(t, 6, flushed)
+	assert.Zero(t, dropped)
+	assert.NoError(t, errFlush)
+	assert.Equal(t, []Count{{ProductId: 3, Views: 3}, {ProductId: 1, Views: 2}, {ProductId: 2, Views: 2}}, counter.Top(10))
+	assert.NoError(t, errReload)
+	assert.Equal(t, []Count{{ProductId: 3, Views: 3}, {ProductId: 1, Views: 2}, {ProductId: 2, Views: 1}}, reloaded.Top(10))
+}
+
+func TestCounter_Dropped(t *testing.T) {
+	// Without room in the queue, nor anyone counting yet, every view is dropped
+	counter := &Counter{events: make(chan event), pending: map[int]int{}, totals: map[int]int{}}
+	counter.View(1)
+	counter.View(1)
+	go counter.run()
+	flushed, dropped, err := counter.Flush()
+
+	// Assertions
+	assert.Zero(t, flushed)
+	assert.Equal(t, 2, dropped)
+	assert.NoError(t, err)
+}
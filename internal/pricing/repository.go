@@ -0,0 +1,163 @@
+package pricing
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"os"
+	"sort"
+	"sync"
+)
+
+var ErrNotFound = errors.New("scheduled price not found")
+
+// Repository is the interface definition for the scheduled prices storage
+type Repository interface {
+	GetAll() []domain.ScheduledPrice
+	GetByProduct(productId int) []domain.ScheduledPrice
+	Create(price domain.ScheduledPrice) (domain.ScheduledPrice, error)
+	Repoint(fromId int, toId int) (int, error)
+	Delete(id int) error
+}
+
+/*
+RepositoryImpl is the implementation of the repository interface. Every change is written to a
+JSON file before returning, so the prices scheduled before a restart are still activated. An empty
+filepath keeps the prices in memory only.
+*/
+type RepositoryImpl struct {
+	filepath string
+
+	mu     sync.RWMutex
+	prices []domain.ScheduledPrice
+	lastId int
+}
+
+/*
+The NewRepository function returns a repository persisted in the given file, loading the prices
+scheduled by a previous run if the file exists.
+*/
+func NewRepository(filepath string) (Repository, error) {
+	r := &RepositoryImpl{
+		filepath: filepath,
+	}
+	if filepath == "" {
+		return r, nil
+	}
+
+	data, err := os.ReadFile(filepath)
+	if errors.Is(err, os.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, &r.prices); err != nil {
+		return nil, err
+	}
+
+	for _, price := range r.prices {
+		if price.Id > r.lastId {
+			r.lastId = price.Id
+		}
+	}
+	return r, nil
+}
+
+// The GetAll method returns every scheduled price, sorted by effective date.
+func (r *RepositoryImpl) GetAll() []domain.ScheduledPrice {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return sorted(r.prices, func(domain.ScheduledPrice) bool { return true })
+}
+
+// The GetByProduct method returns the scheduled prices of a product, sorted by effective date.
+func (r *RepositoryImpl) GetByProduct(productId int) []domain.ScheduledPrice {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return sorted(r.prices, func(price domain.ScheduledPrice) bool { return price.ProductId == productId })
+}
+
+// The Create method stores a new scheduled price, assigning it a new ID.
+func (r *RepositoryImpl) Create(price domain.ScheduledPrice) (domain.ScheduledPrice, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastId++
+	price.Id = r.lastId
+	r.prices = append(r.prices, price)
+	return price, r.save()
+}
+
+/*
+The Repoint method moves every scheduled price of a product to another one and returns how many
+were moved. The file is written once, only if any price moved.
+*/
+func (r *RepositoryImpl) Repoint(fromId int, toId int) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	moved := 0
+	for i, price := range r.prices {
+		if price.ProductId == fromId {
+			r.prices[i].ProductId = toId
+			moved++
+		}
+	}
+	if moved == 0 {
+		return 0, nil
+	}
+	return moved, r.save()
+}
+
+// The Delete method deletes a scheduled price. It returns an error if the price does not exist.
+func (r *RepositoryImpl) Delete(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, price := range r.prices {
+		if price.Id == id {
+			r.prices = append(r.prices[:i], r.prices[i+1:]...)
+			return r.save()
+		}
+	}
+	return ErrNotFound
+}
+
+// Auxiliary method that writes the scheduled prices to the repository file, if any.
+func (r *RepositoryImpl) save() error {
+	if r.filepath == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(r.prices)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so a crash never leaves a truncated file
+	tmp := r.filepath + ".tmp"
+	if err = os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, r.filepath)
+}
+
+// Auxiliary function that returns a copy of the prices matching the filter, sorted by effective date and ID.
+func sorted(prices []domain.ScheduledPrice, match func(price domain.ScheduledPrice) bool) []domain.ScheduledPrice {
+	result := []domain.ScheduledPrice{}
+	for _, price := range prices {
+		if match(price) {
+			result = append(result, price)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if !result[i].EffectiveFrom.Equal(result[j].EffectiveFrom) {
+			return result[i].EffectiveFrom.Before(result[j].EffectiveFrom)
+		}
+		return result[i].Id < result[j].Id
+	})
+	return result
+}
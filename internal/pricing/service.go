@@ -0,0 +1,134 @@
+package pricing
+
+import (
+	"errors"
+	"fmt"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/product"
+	"log"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+type Service interface {
+	Upcoming(productId int) []domain.ScheduledPrice
+	Schedule(productId int, request domain.ScheduledPriceRequest) (domain.ScheduledPrice, error)
+	Cancel(productId int, id int) error
+	ActivateDue(now time.Time) ([]domain.Product, error)
+	Repoint(fromId int, toId int) error
+	Version() string
+}
+
+type ServiceImpl struct {
+	repository Repository
+	products   product.Service
+	version    uint64
+}
+
+/*
+The NewService function returns a new instance of the service. The scheduled prices are activated
+by patching the products of the given product service, so every activation is a regular update.
+*/
+func NewService(repository Repository, products product.Service) Service {
+	return &ServiceImpl{
+		repository: repository,
+		products:   products,
+	}
+}
+
+// The Upcoming method returns the prices scheduled for a product, sorted by effective date.
+func (s *ServiceImpl) Upcoming(productId int) []domain.ScheduledPrice {
+	return s.repository.GetByProduct(productId)
+}
+
+/*
+The Schedule method schedules a future price of a product. It returns an error if the product does
+not exist or the scheduled price has invalid fields.
+*/
+func (s *ServiceImpl) Schedule(productId int, request domain.ScheduledPriceRequest) (domain.ScheduledPrice, error) {
+	if _, err := s.products.GetById(productId); err != nil {
+		return domain.ScheduledPrice{}, err
+	}
+	now := time.Now()
+	if err := request.Validate(now); err != nil {
+		return domain.ScheduledPrice{}, err
+	}
+
+	price, err := s.repository.Create(domain.ScheduledPrice{
+		ProductId:     productId,
+		Price:         request.Price,
+		Currency:      request.Currency,
+		EffectiveFrom: request.EffectiveFrom,
+		CreatedAt:     now,
+	})
+	atomic.AddUint64(&s.version, 1)
+	return price, err
+}
+
+// The Cancel method removes a scheduled price of a product. If the price does not exist, it returns an error.
+func (s *ServiceImpl) Cancel(productId int, id int) error {
+	for _, price := range s.repository.GetByProduct(productId) {
+		if price.Id == id {
+			atomic.AddUint64(&s.version, 1)
+			return s.repository.Delete(id)
+		}
+	}
+	return fmt.Errorf("%w: id %d", ErrNotFound, id)
+}
+
+/*
+The ActivateDue method makes current every scheduled price whose effective date is not after now,
+in effective date order, and returns the updated products. Prices of deleted products, or that the
+product rules no longer accept, are discarded. Other failures stop the activation, and the pending
+prices are retried on the next call.
+*/
+func (s *ServiceImpl) ActivateDue(now time.Time) ([]domain.Product, error) {
+	updated := []domain.Product{}
+	for _, price := range s.repository.GetAll() {
+		if price.EffectiveFrom.After(now) {
+			break
+		}
+
+		partial := domain.ProductRequest{Price: &price.Price}
+		if price.Currency != "" {
+			partial.Currency = &price.Currency
+		}
+		updatedProduct, err := s.products.Patch(price.ProductId, partial)
+		switch {
+		case errors.Is(err, product.ErrNotFound), errors.Is(err, domain.ErrInvalidProduct):
+			log.Printf("pricing: scheduled price %d discarded: %s\n", price.Id, err)
+		case err != nil:
+			return updated, err
+		default:
+			updated = append(updated, updatedProduct)
+		}
+
+		atomic.AddUint64(&s.version, 1)
+		if err = s.repository.Delete(price.Id); err != nil {
+			return updated, err
+		}
+	}
+	return updated, nil
+}
+
+/*
+The Repoint method moves the prices scheduled for a product to another one, like the product a
+duplicate is merged into, so they are still activated.
+*/
+func (s *ServiceImpl) Repoint(fromId int, toId int) error {
+	moved, err := s.repository.Repoint(fromId, toId)
+	if moved > 0 {
+		atomic.AddUint64(&s.version, 1)
+	}
+	return err
+}
+
+/*
+The Version method returns a number identifying the current state of the scheduled prices, which
+changes after every change of the prices. Along with the catalog version, it validates the cached
+reads of the products.
+*/
+func (s *ServiceImpl) Version() string {
+	return strconv.FormatUint(atomic.LoadUint64(&s.version), 10)
+}
@@ -0,0 +1,103 @@
+package pricing
+
+import (
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/JoseObreque/go-web/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestService_ActivateDue(t *testing.T) {
+	products := product.NewService(product.NewRepository(testutil.Fixtures(3)), nil)
+	file := filepath.Join(t.TempDir(), "prices.json")
+	repository, err := NewRepository(file)
+	if err != nil {
+		panic(err)
+	}
+	service := NewService(repository, products)
+	now := time.Now()
+
+	later, errLater := service.Schedule(1, domain.ScheduledPriceRequest{Price: 30, EffectiveFrom: now.Add(2 * time.Hour)})
+	_, errSooner := service.Schedule(1, domain.ScheduledPriceRequest{Price: 20, Currency: "EUR", EffectiveFrom: now.Add(time.Hour)})
+	_, errOther := service.Schedule(2, domain.ScheduledPriceRequest{Price: 99, EffectiveFrom: now.Add(3 * time.Hour)})
+	_, errMissing := service.Schedule(7, domain.ScheduledPriceRequest{Price: 10, EffectiveFrom: now.Add(time.Hour)})
+	_, errInvalid := service.Schedule(1, domain.ScheduledPriceRequest{Price: -1, EffectiveFrom: now.Add(-time.Hour)})
+	upcoming := service.Upcoming(1)
+	version := service.Version()
+
+	// Both prices of the first product are due, the second product one is not
+	activated, errActivate := service.ActivateDue(now.Add(150 * time.Minute))
+	current, _ := products.GetById(1)
+	reloaded, _ := NewRepository(file)
+
+	// Assertions
+	assert.NoError(t, errLater)
+	assert.NoError(t, errSooner)
+	assert.NoError(t, errOther)
+	assert.ErrorIs(t, errMissing, product.ErrNotFound)
+	assert.ErrorIs(t, errInvalid, domain.ErrInvalidProduct)
+	assert.Len(t, upcoming, 2)
+	assert.Equal(t, 20.0, upcoming[0].Price)
+	assert.Equal(t, later, upcoming[1])
+	assert.NoError(t, errActivate)
+	assert.Len(t, activated, 2)
+	assert.Equal(t, 30.0, current.Price)
+	assert.Equal(t, "EUR", current.Currency)
+	assert.Empty(t, service.Upcoming(1))
+	assert.Len(t, reloaded.GetAll(), 1)
+	assert.NotEqual(t, version, service.Version())
+	assert.ErrorIs(t, service.Cancel(1, later.Id), ErrNotFound)
+}
+
+func TestService_ActivateDue_DeletedProduct(t *testing.T) {
+	products := product.NewService(product.NewRepository(testutil.Fixtures(1)), nil)
+	repository, _ := NewRepository("")
+	service := NewService(repository, products)
+	if _, err := service.Schedule(1, domain.ScheduledPriceRequest{Price: 30, EffectiveFrom: time.Now().Add(time.Minute)}); err != nil {
+		panic(err)
+	}
+	if err := products.Delete(1); err != nil {
+		panic(err)
+	}
+
+	activated, err := service.ActivateDue(time.Now().Add(time.Hour))
+
+	// Assertions: the price is discarded instead of retried forever
+	assert.NoError(t, err)
+	assert.Empty(t, activated)
+	assert.Empty(t, repository.GetAll())
+}
+
+func TestService_Repoint(t *testing.T) {
+	products := product.NewService(product.NewRepository(testutil.Fixtures(2)), nil)
+	file := filepath.Join(t.TempDir(), "prices.json")
+	repository, err := NewRepository(file)
+	if err != nil {
+		panic(err)
+	}
+	service := NewService(repository, products)
+	scheduled, err := service.Schedule(2, domain.ScheduledPriceRequest{Price: 30, EffectiveFrom: time.Now().Add(time.Hour)})
+	if err != nil {
+		panic(err)
+	}
+	version := service.Version()
+
+	errRepoint := service.Repoint(2, 1)
+	movedVersion := service.Version()
+	errNone := service.Repoint(2, 1)
+	reloaded, _ := NewRepository(file)
+
+	// Assertions: the price follows the product, and nothing changes without prices to move
+	assert.NoError(t, errRepoint)
+	assert.NoError(t, errNone)
+	assert.Empty(t, service.Upcoming(2))
+	if assert.Len(t, service.Upcoming(1), 1) {
+		assert.Equal(t, scheduled.Id, service.Upcoming(1)[0].Id)
+	}
+	assert.Equal(t, 1, reloaded.GetAll()[0].ProductId)
+	assert.NotEqual(t, version, movedVersion)
+	assert.Equal(t, movedVersion, service.Version())
+}
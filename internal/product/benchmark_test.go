@@ -0,0 +1,101 @@
+package product
+
+import (
+	"fmt"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/pkg/testutil"
+	"sync"
+	"testing"
+)
+
+// Number of products of the benchmark catalog, the largest catalog a release has to serve.
+const benchmarkSize = 1000000
+
+var (
+	benchmarkOnce     sync.Once
+	benchmarkProducts []domain.Product
+)
+
+/*
+The benchmarkCatalog function returns a copy of the benchmark catalog, generated once per run
+since it takes a while. The prices are uniformly spread between 0.5 and 1000.
+*/
+func benchmarkCatalog(b *testing.B) []domain.Product {
+	b.Helper()
+	benchmarkOnce.Do(func() {
+		benchmarkProducts = testutil.Fixtures(benchmarkSize)
+	})
+
+	products := make([]domain.Product, len(benchmarkProducts))
+	copy(products, benchmarkProducts)
+	return products
+}
+
+func BenchmarkRepository_GetAll(b *testing.B) {
+	repository := NewRepository(benchmarkCatalog(b))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = repository.GetAll()
+	}
+}
+
+func BenchmarkRepository_GetById(b *testing.B) {
+	repository := NewRepository(benchmarkCatalog(b))
+
+	// The last product is the worst case of the lookup
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := repository.GetById(benchmarkSize); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRepository_GetByPriceGt(b *testing.B) {
+	repository := NewRepository(benchmarkCatalog(b))
+
+	// The filters match about 90%, 50% and 1% of the products
+	for _, price := range []float64{100, 500, 990} {
+		b.Run(fmt.Sprintf("price>%g", price), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = repository.GetByPriceGt(price)
+			}
+		})
+	}
+}
+
+func BenchmarkService_GetByPriceGt(b *testing.B) {
+	service := NewService(NewRepository(benchmarkCatalog(b)), nil)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := service.GetByPriceGt(500); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkService_Create(b *testing.B) {
+	service := NewService(NewRepository(benchmarkCatalog(b)), nil)
+
+	// Every creation checks the code value against the whole catalog
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := service.Create(domain.Product{
+			Name:       "Benchmark",
+			Quantity:   1,
+			CodeValue:  fmt.Sprintf("BENCH%d", i),
+			Expiration: "25/10/2030",
+			Price:      10,
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
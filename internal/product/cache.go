@@ -0,0 +1,305 @@
+package product
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/filter"
+	"github.com/JoseObreque/go-web/pkg/cache"
+	"github.com/JoseObreque/go-web/pkg/logging"
+	"strconv"
+	"time"
+)
+
+// Key of the cache entry holding the current generation of the product listings.
+const cacheGenerationKey = "products:generation"
+
+/*
+The CachedRepository struct is a Repository decorator that caches the reads of another
+repository. Single products are cached under their id and invalidated when they change; listings
+are cached under the current generation, which is incremented on every write so that every cached
+listing becomes unreachable at once. If the cache fails, reads fall back to the repository.
+*/
+type CachedRepository struct {
+	repository Repository
+	cache      cache.Cache
+	ttl        time.Duration
+	// Hits and misses of the cached reads
+	counter cache.Counter
+	// Products changed within a transaction, invalidated when it ends; nil outside a transaction
+	pending *[]int
+}
+
+// The NewCachedRepository function returns a repository that caches the reads of the given one for ttl.
+func NewCachedRepository(repository Repository, cache cache.Cache, ttl time.Duration) Repository {
+	return &CachedRepository{
+		repository: repository,
+		cache:      cache,
+		ttl:        ttl,
+	}
+}
+
+// The GetAll method returns all available products
+func (r *CachedRepository) GetAll() []domain.Product {
+	key := r.listKey("all")
+
+	var products []domain.Product
+	if r.load(key, &products) {
+		return products
+	}
+
+	products = r.repository.GetAll()
+	r.store(key, products)
+	return products
+}
+
+// The GetById method returns a product by its ID
+func (r *CachedRepository) GetById(id int) (domain.Product, error) {
+	key := productKey(id)
+
+	var product domain.Product
+	if r.load(key, &product) {
+		return product, nil
+	}
+
+	product, err := r.repository.GetById(id)
+	if err != nil {
+		return domain.Product{}, err
+	}
+	r.store(key, product)
+	return product, nil
+}
+
+// The GetByPriceGt method returns a list of products with a price greater than the given price.
+func (r *CachedRepository) GetByPriceGt(price float64) []domain.Product {
+	key := r.listKey("price_gt:" + strconv.FormatFloat(price, 'f', -1, 64))
+
+	var products []domain.Product
+	if r.load(key, &products) {
+		return products
+	}
+
+	products = r.repository.GetByPriceGt(price)
+	r.store(key, products)
+	return products
+}
+
+// The Filter method returns a list of the products matching the given filter expression.
+func (r *CachedRepository) Filter(expr filter.Expr) []domain.Product {
+	key := r.listKey("filter:" + expr.String())
+
+	var products []domain.Product
+	if r.load(key, &products) {
+		return products
+	}
+
+	products = r.repository.Filter(expr)
+	r.store(key, products)
+	return products
+}
+
+/*
+The List method returns the products matching the filter expression, every product if it is nil,
+cached like the other listings. The failed reads of the repository are returned and not cached.
+*/
+func (r *CachedRepository) List(expr filter.Expr) ([]domain.Product, error) {
+	key := r.listKey("all")
+	if expr != nil {
+		key = r.listKey("filter:" + expr.String())
+	}
+
+	var products []domain.Product
+	if r.load(key, &products) {
+		return products, nil
+	}
+
+	products, err := list(r.repository, expr)
+	if err != nil {
+		return nil, err
+	}
+	r.store(key, products)
+	return products, nil
+}
+
+// The Create method creates a new product and invalidates the cached listings.
+func (r *CachedRepository) Create(product domain.Product) (domain.Product, error) {
+	newProduct, err := r.repository.Create(product)
+	if err != nil {
+		return domain.Product{}, err
+	}
+
+	r.invalidate(newProduct.Id)
+	return newProduct, nil
+}
+
+// The Update method updates a product and invalidates it and the cached listings.
+func (r *CachedRepository) Update(id int, updatedProduct domain.Product) (domain.Product, error) {
+	product, err := r.repository.Update(id, updatedProduct)
+	if err != nil {
+		return domain.Product{}, err
+	}
+
+	r.invalidate(id)
+	return product, nil
+}
+
+// The Patch method partially updates a product and invalidates it and the cached listings.
+func (r *CachedRepository) Patch(id int, partial domain.ProductRequest) (domain.Product, error) {
+	product, err := r.repository.Patch(id, partial)
+	if err != nil {
+		return domain.Product{}, err
+	}
+
+	r.invalidate(id)
+	return product, nil
+}
+
+// The Delete method deletes a product and invalidates it and the cached listings.
+func (r *CachedRepository) Delete(id int) error {
+	if err := r.repository.Delete(id); err != nil {
+		return err
+	}
+
+	r.invalidate(id)
+	return nil
+}
+
+// The Replace method replaces every product and invalidates the old and the new products and the cached listings.
+func (r *CachedRepository) Replace(products []domain.Product) {
+	r.replace(products, r.repository.Replace)
+}
+
+// The Reset method resets every product of the decorated repository and invalidates them, like Replace.
+func (r *CachedRepository) Reset(products []domain.Product) {
+	r.replace(products, func(products []domain.Product) {
+		reset(r.repository, products)
+	})
+}
+
+// The LastId method returns the highest ID ever assigned by the decorated repository.
+func (r *CachedRepository) LastId() int {
+	return lastId(r.repository)
+}
+
+// The ReserveIds method reserves the IDs up to lastId in the decorated repository.
+func (r *CachedRepository) ReserveIds(lastId int) {
+	reserveIds(r.repository, lastId)
+}
+
+// Auxiliary method that replaces every product with the given function and invalidates the previous and the new ones.
+func (r *CachedRepository) replace(products []domain.Product, replace func([]domain.Product)) {
+	previous := r.repository.GetAll()
+	replace(products)
+
+	var ids []int
+	for _, list := range [][]domain.Product{previous, products} {
+		for _, product := range list {
+			ids = append(ids, product.Id)
+		}
+	}
+	r.invalidate(ids...)
+}
+
+/*
+The WithTx method runs fn in a transaction of the cached repository. The reads within the
+transaction bypass the cache, so the changes that could be rolled back are never cached, and the
+changed products are invalidated once it ends, whether it was committed or not.
+*/
+func (r *CachedRepository) WithTx(fn func(tx Repository) error) error {
+	if r.pending != nil {
+		return fn(r)
+	}
+
+	var changed []int
+	err := r.repository.WithTx(func(tx Repository) error {
+		return fn(&CachedRepository{
+			repository: tx,
+			cache:      r.cache,
+			ttl:        r.ttl,
+			pending:    &changed,
+		})
+	})
+	if len(changed) > 0 {
+		r.invalidate(changed...)
+	}
+	return err
+}
+
+// The CacheStats method returns the hits and misses of the reads of the products and listings.
+func (r *CachedRepository) CacheStats() cache.Stats {
+	return r.counter.Stats()
+}
+
+// Auxiliary method that returns the key of a listing under the current generation.
+func (r *CachedRepository) listKey(name string) string {
+	generation, err := r.cache.Get(context.Background(), cacheGenerationKey)
+	if err != nil {
+		generation = []byte("0")
+	}
+	return fmt.Sprintf("products:list:%s:%s", generation, name)
+}
+
+// Auxiliary function that returns the key of a single product.
+func productKey(id int) string {
+	return "products:id:" + strconv.Itoa(id)
+}
+
+// Auxiliary method that decodes a cached value into target, reporting whether it was found.
+func (r *CachedRepository) load(key string, target interface{}) bool {
+	if r.pending != nil {
+		return false
+	}
+	data, err := r.cache.Get(context.Background(), key)
+	if err != nil {
+		if errors.Is(err, cache.ErrMiss) {
+			logging.Debugf("cache: miss of %s\n", key)
+		} else {
+			logging.Warnf("cache: could not read %s: %s\n", key, err)
+		}
+		r.counter.Miss()
+		return false
+	}
+	if json.Unmarshal(data, target) != nil {
+		r.counter.Miss()
+		return false
+	}
+	r.counter.Hit()
+	return true
+}
+
+// Auxiliary method that caches a value under the given key.
+func (r *CachedRepository) store(key string, value interface{}) {
+	if r.pending != nil {
+		return
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+	if err = r.cache.Set(context.Background(), key, data, r.ttl); err != nil {
+		logging.Warnf("cache: could not write %s: %s\n", key, err)
+	}
+}
+
+/*
+Auxiliary method that removes the products from the cache and starts a new listings generation.
+Within a transaction, the products are only noted to be invalidated when it ends.
+*/
+func (r *CachedRepository) invalidate(ids ...int) {
+	if r.pending != nil {
+		*r.pending = append(*r.pending, ids...)
+		return
+	}
+
+	ctx := context.Background()
+	for _, id := range ids {
+		if err := r.cache.Delete(ctx, productKey(id)); err != nil {
+			logging.Warnf("cache: could not invalidate product %d: %s\n", id, err)
+		}
+	}
+	if _, err := r.cache.Incr(ctx, cacheGenerationKey); err != nil {
+		logging.Warnf("cache: could not invalidate listings: %s\n", err)
+	}
+}
@@ -0,0 +1,58 @@
+package product
+
+import (
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/pkg/cache"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// The countingRepository struct counts the reads that reach the decorated repository.
+type countingRepository struct {
+	Repository
+	reads int
+}
+
+func (r *countingRepository) GetAll() []domain.Product {
+	r.reads++
+	return r.Repository.GetAll()
+}
+
+func (r *countingRepository) GetById(id int) (domain.Product, error) {
+	r.reads++
+	return r.Repository.GetById(id)
+}
+
+func TestCachedRepository(t *testing.T) {
+	backend := &countingRepository{Repository: NewRepository([]domain.Product{
+		{Id: 1, Name: "Oil", Quantity: 10, CodeValue: "A1", Status: domain.StatusPublished, Expiration: "15/12/2030", Price: 71.42},
+	})}
+	repository := NewCachedRepository(backend, cache.NewMemory(), time.Minute)
+
+	// Repeated reads are served from the cache
+	repository.GetAll()
+	repository.GetAll()
+	_, _ = repository.GetById(1)
+	product, err := repository.GetById(1)
+
+	// Assertions
+	assert.NoError(t, err)
+	assert.Equal(t, "Oil", product.Name)
+	assert.Equal(t, 2, backend.reads)
+
+	// Writes invalidate the product and the listings
+	product.Name = "Olive oil"
+	_, err = repository.Update(1, product)
+	assert.NoError(t, err)
+	_, err = repository.Create(domain.Product{Name: "Rice", CodeValue: "B2"})
+	assert.NoError(t, err)
+
+	products := repository.GetAll()
+	updated, _ := repository.GetById(1)
+
+	// Assertions
+	assert.Len(t, products, 2)
+	assert.Equal(t, "Olive oil", updated.Name)
+	assert.Equal(t, 4, backend.reads)
+}
@@ -0,0 +1,80 @@
+package product
+
+import (
+	"fmt"
+	"github.com/JoseObreque/go-web/internal/domain"
+)
+
+/*
+The DryRunRepository struct is a Repository decorator whose writes check the same rules as the
+ones of another repository, the existence of the product and the uniqueness of its code value, but
+change nothing. The reads are answered by the decorated repository, and the created products have
+no ID yet.
+*/
+type DryRunRepository struct {
+	Repository
+}
+
+// The NewDryRunRepository function returns a repository that validates the writes of the given one without applying them.
+func NewDryRunRepository(repository Repository) Repository {
+	return &DryRunRepository{
+		Repository: repository,
+	}
+}
+
+// The Create method returns the product that would be created, or an error if its code value is taken.
+func (r *DryRunRepository) Create(product domain.Product) (domain.Product, error) {
+	if err := r.validateCodeValue(product.CodeValue, 0); err != nil {
+		return domain.Product{}, err
+	}
+	product.Id = 0
+	return product, nil
+}
+
+/*
+The Update method returns the product as it would be updated. It returns an error if the product
+does not exist or if the new code value is already used by another product.
+*/
+func (r *DryRunRepository) Update(id int, updatedProduct domain.Product) (domain.Product, error) {
+	if _, err := r.Repository.GetById(id); err != nil {
+		return domain.Product{}, err
+	}
+	if err := r.validateCodeValue(updatedProduct.CodeValue, id); err != nil {
+		return domain.Product{}, err
+	}
+	updatedProduct.Id = id
+	return updatedProduct, nil
+}
+
+// The Patch method returns the product as it would be partially updated.
+func (r *DryRunRepository) Patch(id int, partial domain.ProductRequest) (domain.Product, error) {
+	product, err := r.Repository.GetById(id)
+	if err != nil {
+		return domain.Product{}, err
+	}
+	return r.Update(id, partial.Apply(product))
+}
+
+// The Delete method returns an error if the product does not exist.
+func (r *DryRunRepository) Delete(id int) error {
+	_, err := r.Repository.GetById(id)
+	return err
+}
+
+// The Replace method does nothing.
+func (r *DryRunRepository) Replace(products []domain.Product) {}
+
+// The WithTx method runs fn over the dry run repository itself, since it has nothing to roll back.
+func (r *DryRunRepository) WithTx(fn func(tx Repository) error) error {
+	return fn(r)
+}
+
+// Auxiliary method that checks if a code value is free, ignoring the product with the given ID.
+func (r *DryRunRepository) validateCodeValue(codeValue string, id int) error {
+	for _, product := range r.Repository.GetAll() {
+		if product.CodeValue == codeValue && product.Id != id {
+			return fmt.Errorf("%w: %s", ErrInvalidCode, codeValue)
+		}
+	}
+	return nil
+}
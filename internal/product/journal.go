@@ -0,0 +1,172 @@
+package product
+
+import (
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/pkg/store"
+	"log"
+)
+
+/*
+The JournaledRepository struct is a Repository decorator that records every write of another
+repository in a journal before committing it, so the changes survive a crash without rewriting the
+whole store file on each of them. Every entry records the highest ID ever assigned, so it is never
+given again after a restart. If the journal is shared with other instances of the server, the
+writes are made holding its lock, like the ones of a service with WithStoreLock, which reads the
+products after acquiring it.
+*/
+type JournaledRepository struct {
+	Repository
+	journal *store.Journal
+	// Entries of the writes within a transaction, recorded before it commits; nil outside a transaction
+	pending *[]store.Entry
+}
+
+// The NewJournaledRepository function returns a repository that records the writes of the given one in the journal.
+func NewJournaledRepository(repository Repository, journal *store.Journal) Repository {
+	return &JournaledRepository{
+		Repository: repository,
+		journal:    journal,
+	}
+}
+
+// The Create method creates a product, committed once it is recorded in the journal.
+func (r *JournaledRepository) Create(product domain.Product) (domain.Product, error) {
+	var newProduct domain.Product
+	err := r.write(func(tx Repository) (store.Entry, error) {
+		var err error
+		newProduct, err = tx.Create(product)
+		return store.Entry{Op: store.OpPut, Id: newProduct.Id, Product: &newProduct}, err
+	})
+	if err != nil {
+		return domain.Product{}, err
+	}
+	return newProduct, nil
+}
+
+// The Update method updates a product, committed once its new state is recorded in the journal.
+func (r *JournaledRepository) Update(id int, newProductData domain.Product) (domain.Product, error) {
+	var updatedProduct domain.Product
+	err := r.write(func(tx Repository) (store.Entry, error) {
+		var err error
+		updatedProduct, err = tx.Update(id, newProductData)
+		return store.Entry{Op: store.OpPut, Id: updatedProduct.Id, Product: &updatedProduct}, err
+	})
+	if err != nil {
+		return domain.Product{}, err
+	}
+	return updatedProduct, nil
+}
+
+// The Patch method partially updates a product, committed once its new state is recorded in the journal.
+func (r *JournaledRepository) Patch(id int, partial domain.ProductRequest) (domain.Product, error) {
+	var updatedProduct domain.Product
+	err := r.write(func(tx Repository) (store.Entry, error) {
+		var err error
+		updatedProduct, err = tx.Patch(id, partial)
+		return store.Entry{Op: store.OpPut, Id: updatedProduct.Id, Product: &updatedProduct}, err
+	})
+	if err != nil {
+		return domain.Product{}, err
+	}
+	return updatedProduct, nil
+}
+
+// The Delete method deletes a product, committed once its removal is recorded in the journal.
+func (r *JournaledRepository) Delete(id int) error {
+	return r.write(func(tx Repository) (store.Entry, error) {
+		return store.Entry{Op: store.OpDelete, Id: id}, tx.Delete(id)
+	})
+}
+
+/*
+The Reset method replaces every product with the ones of the journal and its store file, like the
+ones refreshed from the changes of another instance, without recording them again.
+*/
+func (r *JournaledRepository) Reset(products []domain.Product) {
+	reset(r.Repository, products)
+}
+
+// The LastId method returns the highest ID ever assigned by the decorated repository.
+func (r *JournaledRepository) LastId() int {
+	return lastId(r.Repository)
+}
+
+/*
+The ReserveIds method reserves the IDs up to lastId in the decorated repository, like the ones of
+the journal and its store file, without recording them again.
+*/
+func (r *JournaledRepository) ReserveIds(lastId int) {
+	reserveIds(r.Repository, lastId)
+}
+
+/*
+The Replace method replaces every product, committed once the new products are recorded in the
+journal. Replace returns no error, so a failure is logged and the products are left as they were.
+*/
+func (r *JournaledRepository) Replace(products []domain.Product) {
+	err := r.write(func(tx Repository) (store.Entry, error) {
+		tx.Replace(products)
+		return store.Entry{Op: store.OpReplace, Products: products}, nil
+	})
+	if err != nil {
+		log.Printf("journal: replacing the products: %s\n", err)
+	}
+}
+
+/*
+The WithTx method runs fn in a transaction of the decorated repository, and records the writes of
+the transaction in a single batch entry before it commits, so a crash never leaves part of them in
+the journal. The transaction is rolled back if the entry cannot be recorded.
+*/
+func (r *JournaledRepository) WithTx(fn func(tx Repository) error) error {
+	if r.pending != nil {
+		return fn(r)
+	}
+
+	return r.Repository.WithTx(func(tx Repository) error {
+		var entries []store.Entry
+		err := fn(&JournaledRepository{
+			Repository: tx,
+			journal:    r.journal,
+			pending:    &entries,
+		})
+		if err != nil {
+			return err
+		}
+
+		switch len(entries) {
+		case 0:
+			return nil
+		case 1:
+			entries[0].LastId = lastId(tx)
+			return r.journal.Append(entries[0])
+		default:
+			return r.journal.Append(store.Entry{Op: store.OpBatch, Entries: entries, LastId: lastId(tx)})
+		}
+	})
+}
+
+/*
+Auxiliary method that runs a write in a transaction of the decorated repository, and records the
+entry it returns before committing it, so no change is acknowledged without being in the journal:
+the write is rolled back and the error returned if the entry cannot be recorded. Within a
+transaction, the entry is noted until the transaction commits.
+*/
+func (r *JournaledRepository) write(fn func(tx Repository) (store.Entry, error)) error {
+	if r.pending != nil {
+		entry, err := fn(r.Repository)
+		if err != nil {
+			return err
+		}
+		*r.pending = append(*r.pending, entry)
+		return nil
+	}
+	return r.Repository.WithTx(func(tx Repository) error {
+		entry, err := fn(tx)
+		if err != nil {
+			return err
+		}
+		entry.LastId = lastId(tx)
+		return r.journal.Append(entry)
+	})
+}
@@ -0,0 +1,12 @@
+CREATE TABLE products (
+    id           BIGSERIAL PRIMARY KEY,
+    name         TEXT             NOT NULL,
+    quantity     INTEGER          NOT NULL,
+    code_value   TEXT             NOT NULL UNIQUE,
+    is_published BOOLEAN          NOT NULL DEFAULT FALSE,
+    expiration   TEXT             NOT NULL,
+    price        DOUBLE PRECISION NOT NULL,
+    category     TEXT             NOT NULL DEFAULT ''
+);
+
+CREATE INDEX products_price_idx ON products (price);
@@ -0,0 +1,2 @@
+-- An empty currency is the base currency of the server
+ALTER TABLE products ADD COLUMN currency TEXT NOT NULL DEFAULT '';
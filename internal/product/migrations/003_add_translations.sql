@@ -0,0 +1,3 @@
+-- Translations hold the name and description of the product in other locales, by locale
+ALTER TABLE products ADD COLUMN description TEXT NOT NULL DEFAULT '';
+ALTER TABLE products ADD COLUMN translations JSONB NOT NULL DEFAULT '{}';
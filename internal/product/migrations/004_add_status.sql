@@ -0,0 +1,4 @@
+-- The status replaces is_published: the published products stay published and the others are drafts
+ALTER TABLE products ADD COLUMN status TEXT NOT NULL DEFAULT 'draft';
+UPDATE products SET status = 'published' WHERE is_published;
+ALTER TABLE products DROP COLUMN is_published;
@@ -0,0 +1,3 @@
+-- The publish window of a product, open on the sides without a bound
+ALTER TABLE products ADD COLUMN publish_from TIMESTAMPTZ;
+ALTER TABLE products ADD COLUMN publish_until TIMESTAMPTZ;
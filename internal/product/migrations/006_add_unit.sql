@@ -0,0 +1,3 @@
+-- The content of the package of a product, as a pack size of a unit of measure (kg, L or unit)
+ALTER TABLE products ADD COLUMN unit TEXT NOT NULL DEFAULT '';
+ALTER TABLE products ADD COLUMN pack_size DOUBLE PRECISION NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+-- The nutrition facts of a product, NULL if they are unknown
+ALTER TABLE products ADD COLUMN nutrition JSONB;
@@ -0,0 +1,4 @@
+-- The storage requirements of a product: its zone and the range of temperatures in °C it needs, if any
+ALTER TABLE products ADD COLUMN storage TEXT NOT NULL DEFAULT '';
+ALTER TABLE products ADD COLUMN min_temperature DOUBLE PRECISION;
+ALTER TABLE products ADD COLUMN max_temperature DOUBLE PRECISION;
@@ -0,0 +1,2 @@
+-- The lots of the stock of a product, whose quantities add up to its own, empty if they are not tracked
+ALTER TABLE products ADD COLUMN lots JSONB NOT NULL DEFAULT '[]';
@@ -0,0 +1,83 @@
+/*
+Package mocks contains testify mocks of the product Repository and Service, so the tests of the
+layers above them can set the expected calls and answers without any data file.
+*/
+package mocks
+
+import (
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/filter"
+	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ product.Repository = (*Repository)(nil)
+
+// The Repository struct is a mock of the product.Repository interface.
+type Repository struct {
+	mock.Mock
+}
+
+// The NewRepository function returns a mock repository whose expectations are asserted when the test ends.
+func NewRepository(t mock.TestingT) *Repository {
+	m := &Repository{}
+	m.Test(t)
+	if cleaner, ok := t.(interface{ Cleanup(func()) }); ok {
+		cleaner.Cleanup(func() { m.AssertExpectations(t) })
+	}
+	return m
+}
+
+func (m *Repository) GetAll() []domain.Product {
+	args := m.Called()
+	return products(args, 0)
+}
+
+func (m *Repository) GetById(id int) (domain.Product, error) {
+	args := m.Called(id)
+	return args.Get(0).(domain.Product), args.Error(1)
+}
+
+func (m *Repository) GetByPriceGt(price float64) []domain.Product {
+	args := m.Called(price)
+	return products(args, 0)
+}
+
+func (m *Repository) Filter(expr filter.Expr) []domain.Product {
+	args := m.Called(expr)
+	return products(args, 0)
+}
+
+func (m *Repository) Create(p domain.Product) (domain.Product, error) {
+	args := m.Called(p)
+	return args.Get(0).(domain.Product), args.Error(1)
+}
+
+func (m *Repository) Update(id int, newProductData domain.Product) (domain.Product, error) {
+	args := m.Called(id, newProductData)
+	return args.Get(0).(domain.Product), args.Error(1)
+}
+
+func (m *Repository) Patch(id int, partial domain.ProductRequest) (domain.Product, error) {
+	args := m.Called(id, partial)
+	return args.Get(0).(domain.Product), args.Error(1)
+}
+
+func (m *Repository) Delete(id int) error {
+	return m.Called(id).Error(0)
+}
+
+func (m *Repository) Replace(products []domain.Product) {
+	m.Called(products)
+}
+
+// The WithTx method runs fn over the mock itself, so the tests expect the calls made within the transaction.
+func (m *Repository) WithTx(fn func(tx product.Repository) error) error {
+	return fn(m)
+}
+
+// Auxiliary function that returns the products answered at the given position, which may be nil.
+func products(args mock.Arguments, index int) []domain.Product {
+	products, _ := args.Get(index).([]domain.Product)
+	return products
+}
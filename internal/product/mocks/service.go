@@ -0,0 +1,119 @@
+package mocks
+
+import (
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/filter"
+	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/stretchr/testify/mock"
+	"time"
+)
+
+var _ product.Service = (*Service)(nil)
+
+// The Service struct is a mock of the product.Service interface.
+type Service struct {
+	mock.Mock
+}
+
+// The NewService function returns a mock service whose expectations are asserted when the test ends.
+func NewService(t mock.TestingT) *Service {
+	m := &Service{}
+	m.Test(t)
+	if cleaner, ok := t.(interface{ Cleanup(func()) }); ok {
+		cleaner.Cleanup(func() { m.AssertExpectations(t) })
+	}
+	return m
+}
+
+func (m *Service) GetAll() []domain.Product {
+	args := m.Called()
+	return products(args, 0)
+}
+
+func (m *Service) List() ([]domain.Product, error) {
+	args := m.Called()
+	return products(args, 0), args.Error(1)
+}
+
+func (m *Service) GetById(id int) (domain.Product, error) {
+	args := m.Called(id)
+	return args.Get(0).(domain.Product), args.Error(1)
+}
+
+func (m *Service) GetByPriceGt(price float64) ([]domain.Product, error) {
+	args := m.Called(price)
+	return products(args, 0), args.Error(1)
+}
+
+func (m *Service) Filter(expr filter.Expr) ([]domain.Product, error) {
+	args := m.Called(expr)
+	return products(args, 0), args.Error(1)
+}
+
+func (m *Service) Create(p domain.Product) (domain.Product, error) {
+	args := m.Called(p)
+	return args.Get(0).(domain.Product), args.Error(1)
+}
+
+func (m *Service) Update(id int, updatedProduct domain.Product) (domain.Product, error) {
+	args := m.Called(id, updatedProduct)
+	return args.Get(0).(domain.Product), args.Error(1)
+}
+
+func (m *Service) Patch(id int, partial domain.ProductRequest) (domain.Product, error) {
+	args := m.Called(id, partial)
+	return args.Get(0).(domain.Product), args.Error(1)
+}
+
+func (m *Service) PatchMatching(expr filter.Expr, partial domain.ProductRequest, dryRun bool) (product.BatchResult, error) {
+	args := m.Called(expr, partial, dryRun)
+	return args.Get(0).(product.BatchResult), args.Error(1)
+}
+
+func (m *Service) Clone(id int, changes domain.ProductRequest) (domain.Product, error) {
+	args := m.Called(id, changes)
+	return args.Get(0).(domain.Product), args.Error(1)
+}
+
+func (m *Service) Delete(id int) error {
+	return m.Called(id).Error(0)
+}
+
+func (m *Service) Merge(id int, duplicateId int) (domain.Product, error) {
+	args := m.Called(id, duplicateId)
+	return args.Get(0).(domain.Product), args.Error(1)
+}
+
+func (m *Service) UnpublishExpired() ([]domain.Product, error) {
+	args := m.Called()
+	return products(args, 0), args.Error(1)
+}
+
+func (m *Service) ApplyPublishWindows(now time.Time) ([]domain.Product, error) {
+	args := m.Called(now)
+	return products(args, 0), args.Error(1)
+}
+
+func (m *Service) Restore(products []domain.Product) {
+	m.Called(products)
+}
+
+// The Reload method expects mock.Anything as argument, since functions cannot be compared.
+func (m *Service) Reload(load func() ([]domain.Product, error)) (bool, error) {
+	args := m.Called(load)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *Service) Version() string {
+	return m.Called().String(0)
+}
+
+func (m *Service) As(actor string) product.Service {
+	args := m.Called(actor)
+	return args.Get(0).(product.Service)
+}
+
+func (m *Service) DryRun() product.Service {
+	args := m.Called()
+	return args.Get(0).(product.Service)
+}
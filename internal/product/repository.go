@@ -1,63 +1,145 @@
 package product
 
 import (
-	"errors"
 	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/filter"
+	"github.com/JoseObreque/go-web/pkg/memory"
 )
 
+// Errors of the product layer, aliases of the shared domain errors.
 var (
-	ErrNotFound    = errors.New("product not found")
-	ErrInvalidCode = errors.New("invalid product code value")
+	ErrNotFound    = domain.ErrProductNotFound
+	ErrInvalidCode = domain.ErrDuplicateCode
+	ErrNoProducts  = domain.ErrNoProducts
+	ErrUnavailable = domain.ErrUnavailable
 )
 
-// Repository is the interface definition for the product service
+/*
+Repository is the interface definition for the product service. The WithTx method runs a unit of
+work: the writes made through the repository given to fn are applied all or none, committed if fn
+returns nil and rolled back otherwise.
+*/
 type Repository interface {
 	GetAll() []domain.Product
 	GetById(id int) (domain.Product, error)
 	GetByPriceGt(price float64) []domain.Product
+	Filter(expr filter.Expr) []domain.Product
 	Create(product domain.Product) (domain.Product, error)
 	Update(id int, newProductData domain.Product) (domain.Product, error)
+	Patch(id int, partial domain.ProductRequest) (domain.Product, error)
 	Delete(id int) error
+	Replace(products []domain.Product)
+	WithTx(fn func(tx Repository) error) error
+}
+
+/*
+The Resetter interface is implemented by the repositories whose Replace also records the products
+somewhere, like the journaled one. Reset replaces them with products already recorded, like the
+ones refreshed from a store shared with other instances, without recording them again.
+*/
+type Resetter interface {
+	Reset(products []domain.Product)
+}
+
+/*
+The Lister interface is implemented by the repositories whose reads of many products can fail, like
+the SQL one, whose Repository methods can only log a failed read and return no products. List
+returns the products matching the filter expression, every product if it is nil, along with the
+error of a failed read.
+*/
+type Lister interface {
+	List(expr filter.Expr) ([]domain.Product, error)
+}
+
+/*
+Auxiliary function that returns the products of a repository matching the filter expression, every
+product if it is nil, along with the error of a failed read if it is a Lister.
+*/
+func list(repository Repository, expr filter.Expr) ([]domain.Product, error) {
+	if lister, ok := repository.(Lister); ok {
+		return lister.List(expr)
+	}
+	if expr == nil {
+		return repository.GetAll(), nil
+	}
+	return repository.Filter(expr), nil
+}
+
+/*
+The IdReserver interface is implemented by the repositories that assign the IDs of the new products
+themselves. LastId returns the highest ID ever assigned, and ReserveIds makes the new products get
+IDs above the given one, like the IDs assigned before a restart, so the IDs of the deleted products
+are never reused.
+*/
+type IdReserver interface {
+	LastId() int
+	ReserveIds(lastId int)
+}
+
+// Auxiliary function that returns the highest ID ever assigned by a repository, 0 if it is not an IdReserver.
+func lastId(repository Repository) int {
+	if reserver, ok := repository.(IdReserver); ok {
+		return reserver.LastId()
+	}
+	return 0
+}
+
+// Auxiliary function that reserves the IDs up to lastId in a repository, if it is an IdReserver.
+func reserveIds(repository Repository, lastId int) {
+	if reserver, ok := repository.(IdReserver); ok {
+		reserver.ReserveIds(lastId)
+	}
+}
+
+// Auxiliary function that resets the products of a repository if it is a Resetter, or replaces them otherwise.
+func reset(repository Repository, products []domain.Product) {
+	if resetter, ok := repository.(Resetter); ok {
+		resetter.Reset(products)
+		return
+	}
+	repository.Replace(products)
 }
 
-// RepositoryImpl is the implementation of the repository interface
+/*
+RepositoryImpl is the implementation of the repository interface, backed by an in-memory repository
+of products whose code values are unique. The IDs of deleted products are never reused.
+*/
 type RepositoryImpl struct {
-	productList []domain.Product
+	products *memory.Repository[domain.Product]
 }
 
-// The NewRepository function returns a new instance of the repository.
+/*
+The NewRepository function returns a new instance of the repository. The IDs of the new products
+follow the highest ID of the given ones.
+*/
 func NewRepository(productList []domain.Product) Repository {
+	products := memory.NewRepository(productList, ErrNotFound).
+		WithUniqueKey(func(p domain.Product) string { return p.CodeValue }, ErrInvalidCode)
 	return &RepositoryImpl{
-		productList: productList,
+		products: products,
 	}
 }
 
 // The GetAll method returns all available products
 func (r *RepositoryImpl) GetAll() []domain.Product {
-	return r.productList
+	return r.products.GetAll()
 }
 
 // The GetById method returns a product by its ID
 func (r *RepositoryImpl) GetById(id int) (domain.Product, error) {
-	for _, product := range r.productList {
-		if product.Id == id {
-			return product, nil
-		}
-	}
-
-	return domain.Product{}, ErrNotFound
+	return r.products.GetById(id)
 }
 
 // The GetByPriceGt method returns a list of products with a price greater than the given price.
 func (r *RepositoryImpl) GetByPriceGt(price float64) []domain.Product {
-	var filteredProducts []domain.Product
+	return r.products.Filter(func(product domain.Product) bool {
+		return product.Price > price
+	})
+}
 
-	for _, product := range r.productList {
-		if product.Price > price {
-			filteredProducts = append(filteredProducts, product)
-		}
-	}
-	return filteredProducts
+// The Filter method returns a list of the products matching the given filter expression.
+func (r *RepositoryImpl) Filter(expr filter.Expr) []domain.Product {
+	return r.products.Filter(expr.Match)
 }
 
 /*
@@ -65,14 +147,7 @@ The Create method creates a new product. If the product code already exists, it
 Otherwise, it creates a new product.
 */
 func (r *RepositoryImpl) Create(product domain.Product) (domain.Product, error) {
-	if !r.validateCodeValue(product.CodeValue) {
-		return domain.Product{}, ErrInvalidCode
-	}
-
-	product.Id = len(r.productList) + 1
-	r.productList = append(r.productList, product)
-
-	return product, nil
+	return r.products.Create(product)
 }
 
 /*
@@ -81,20 +156,20 @@ data as parameters and returns the updated product if the process was successful
 returns an error.
 */
 func (r *RepositoryImpl) Update(id int, updatedProduct domain.Product) (domain.Product, error) {
-	// Search for the product with the given ID
-	for i, product := range r.productList {
-		if product.Id == id {
-			// Validate the updated code value
-			if !r.validateCodeValue(updatedProduct.CodeValue) && product.CodeValue != updatedProduct.CodeValue {
-				return domain.Product{}, ErrInvalidCode
-			}
-			// Store the updated product and return it
-			updatedProduct.Id = id
-			r.productList[i] = updatedProduct
-			return updatedProduct, nil
-		}
+	return r.products.Update(id, updatedProduct)
+}
+
+/*
+The Patch method changes the fields of a product present in the partial update and returns the
+updated product. It returns an error if the product does not exist or if the new code value is
+already used by another product.
+*/
+func (r *RepositoryImpl) Patch(id int, partial domain.ProductRequest) (domain.Product, error) {
+	product, err := r.GetById(id)
+	if err != nil {
+		return domain.Product{}, err
 	}
-	return domain.Product{}, ErrNotFound
+	return r.Update(id, partial.Apply(product))
 }
 
 /*
@@ -102,24 +177,34 @@ The Delete method deletes a product. It receives the ID of the product and retur
 product does not exist.
 */
 func (r *RepositoryImpl) Delete(id int) error {
-	for i, product := range r.productList {
-		if product.Id == id {
-			r.productList = append(r.productList[:i], r.productList[i+1:]...)
-			return nil
-		}
-	}
-	return ErrNotFound
+	return r.products.Delete(id)
 }
 
 /*
-A function that check if a given code value already exists. If it does, the code value
-is invalid and returns false. Otherwise, it returns true.
+The Replace method replaces every product with the given ones. The IDs of the new products keep
+following the highest ID ever assigned, even if the given products only have lower ones.
 */
-func (r *RepositoryImpl) validateCodeValue(codeValue string) bool {
-	for _, product := range r.productList {
-		if product.CodeValue == codeValue {
-			return false
-		}
-	}
-	return true
+func (r *RepositoryImpl) Replace(products []domain.Product) {
+	r.products.Replace(products)
+}
+
+// The LastId method returns the highest ID ever assigned to a product.
+func (r *RepositoryImpl) LastId() int {
+	return r.products.LastId()
+}
+
+// The ReserveIds method makes the new products get IDs above lastId, never lowering the highest ID.
+func (r *RepositoryImpl) ReserveIds(lastId int) {
+	r.products.ReserveIds(lastId)
+}
+
+/*
+The WithTx method runs fn over the repository itself and, if fn fails, restores the products as
+they were before. The writes are serialized by the service, so no other write is lost by the
+rollback.
+*/
+func (r *RepositoryImpl) WithTx(fn func(tx Repository) error) error {
+	return r.products.WithTx(func() error {
+		return fn(r)
+	})
 }
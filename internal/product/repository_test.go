@@ -0,0 +1,202 @@
+package product
+
+import (
+	"errors"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/pkg/store"
+	"github.com/JoseObreque/go-web/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRepository_Errors(t *testing.T) {
+	repository := NewRepository([]domain.Product{
+		{Id: 1, Name: "Oil", Quantity: 10, CodeValue: "A1", Status: domain.StatusPublished, Expiration: "15/12/2030", Price: 71.42},
+	})
+
+	_, errGet := repository.GetById(7)
+	_, errCreate := repository.Create(domain.Product{Name: "Rice", CodeValue: "A1"})
+	errDelete := repository.Delete(7)
+
+	// Assertions
+	assert.ErrorIs(t, errGet, ErrNotFound)
+	assert.ErrorIs(t, errGet, domain.ErrProductNotFound)
+	assert.EqualError(t, errGet, "product not found: id 7")
+	assert.ErrorIs(t, errCreate, ErrInvalidCode)
+	assert.ErrorIs(t, errDelete, ErrNotFound)
+}
+
+func TestRepository_CreateAfterDelete(t *testing.T) {
+	repository := NewRepository([]domain.Product{
+		{Id: 1, Name: "Oil", Quantity: 10, CodeValue: "A1", Status: domain.StatusPublished, Expiration: "15/12/2030", Price: 71.42},
+		{Id: 2, Name: "Rice", Quantity: 5, CodeValue: "B2", Expiration: "15/12/2030", Price: 10},
+		{Id: 7, Name: "Salt", Quantity: 1, CodeValue: "C3", Expiration: "15/12/2030", Price: 2},
+	})
+
+	// The highest ID is deleted, and still not reused
+	if err := repository.Delete(7); err != nil {
+		panic(err)
+	}
+	first, errFirst := repository.Create(domain.Product{Name: "Sugar", CodeValue: "D4"})
+	if err := repository.Delete(1); err != nil {
+		panic(err)
+	}
+	second, errSecond := repository.Create(domain.Product{Name: "Flour", CodeValue: "E5"})
+
+	// A restore with lower IDs does not lower the next ones either
+	repository.Replace([]domain.Product{{Id: 1, Name: "Oil", CodeValue: "A1"}})
+	third, errThird := repository.Create(domain.Product{Name: "Pasta", CodeValue: "F6"})
+
+	// Assertions
+	assert.NoError(t, errFirst)
+	assert.NoError(t, errSecond)
+	assert.NoError(t, errThird)
+	assert.Equal(t, 8, first.Id)
+	assert.Equal(t, 9, second.Id)
+	assert.Equal(t, 10, third.Id)
+}
+
+func TestRepository_UpdatePatchDelete(t *testing.T) {
+	repository := NewRepository([]domain.Product{
+		{Id: 1, Name: "Oil", Quantity: 10, CodeValue: "A1", Status: domain.StatusPublished, Expiration: "15/12/2030", Price: 71.42},
+		{Id: 2, Name: "Rice", Quantity: 5, CodeValue: "B2", Expiration: "15/12/2030", Price: 10},
+	})
+	name, published := "Olive oil", domain.StatusDraft
+	taken := "B2"
+
+	updated, errUpdate := repository.Update(2, domain.Product{Name: "Brown rice", Quantity: 3, CodeValue: "B2", Expiration: "01/01/2031", Price: 12})
+	patched, errPatch := repository.Patch(1, domain.ProductRequest{Name: &name, Status: &published})
+	_, errTaken := repository.Patch(1, domain.ProductRequest{CodeValue: &taken})
+	_, errMissing := repository.Patch(7, domain.ProductRequest{Name: &name})
+	errDelete := repository.Delete(2)
+	_, errDeleted := repository.GetById(2)
+
+	// Assertions
+	assert.NoError(t, errUpdate)
+	assert.Equal(t, domain.Product{Id: 2, Name: "Brown rice", Quantity: 3, CodeValue: "B2", Expiration: "01/01/2031", Price: 12}, updated)
+	assert.NoError(t, errPatch)
+	assert.Equal(t, domain.Product{Id: 1, Name: "Olive oil", Quantity: 10, CodeValue: "A1", Status: domain.StatusDraft, Expiration: "15/12/2030", Price: 71.42}, patched)
+	assert.ErrorIs(t, errTaken, ErrInvalidCode)
+	assert.ErrorIs(t, errMissing, ErrNotFound)
+	assert.NoError(t, errDelete)
+	assert.ErrorIs(t, errDeleted, ErrNotFound)
+	assert.Len(t, repository.GetAll(), 1)
+}
+
+func TestRepository_WithTx(t *testing.T) {
+	products := []domain.Product{
+		{Id: 1, Name: "Oil", Quantity: 10, CodeValue: "A1", Status: domain.StatusPublished, Expiration: "15/12/2030", Price: 71.42},
+		{Id: 2, Name: "Rice", Quantity: 5, CodeValue: "B2", Expiration: "15/12/2030", Price: 10},
+	}
+	testStore := testutil.NewStore(t, products)
+	repository := NewJournaledRepository(NewRepository(append([]domain.Product(nil), products...)), testStore.Journal)
+	errOutOfStock := errors.New("out of stock")
+
+	// A failed unit of work leaves the products and the journal untouched
+	errRolledBack := repository.WithTx(func(tx Repository) error {
+		if _, err := tx.Create(domain.Product{Name: "Salt", CodeValue: "C3", Expiration: "15/12/2030", Price: 2}); err != nil {
+			return err
+		}
+		if err := tx.Delete(1); err != nil {
+			return err
+		}
+		return errOutOfStock
+	})
+	rolledBack := append([]domain.Product(nil), repository.GetAll()...)
+	rolledBackEntries, _ := testStore.Journal.Entries()
+
+	// A committed one is recorded in a single entry of the journal
+	quantity := 4
+	errCommitted := repository.WithTx(func(tx Repository) error {
+		for _, id := range []int{1, 2} {
+			if _, err := tx.Patch(id, domain.ProductRequest{Quantity: &quantity}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	entries, _ := testStore.Journal.Entries()
+
+	// Assertions
+	assert.ErrorIs(t, errRolledBack, errOutOfStock)
+	assert.Equal(t, products, rolledBack)
+	assert.Empty(t, rolledBackEntries)
+	assert.NoError(t, errCommitted)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, store.OpBatch, entries[0].Op)
+	assert.Equal(t, repository.GetAll(), store.Replay(products, entries))
+	assert.Equal(t, 4, repository.GetAll()[1].Quantity)
+}
+
+func TestRepository_JournalFailure(t *testing.T) {
+	products := []domain.Product{
+		{Id: 1, Name: "Oil", Quantity: 10, CodeValue: "A1", Status: domain.StatusPublished, Expiration: "15/12/2030", Price: 71.42},
+	}
+	testStore := testutil.NewStore(t, products)
+	repository := NewJournaledRepository(NewRepository(append([]domain.Product(nil), products...)), testStore.Journal)
+	if err := testStore.Journal.Close(); err != nil {
+		panic(err)
+	}
+
+	// The changes that cannot be recorded are not applied either
+	quantity := 4
+	_, errCreate := repository.Create(domain.Product{Name: "Salt", CodeValue: "C3", Expiration: "15/12/2030", Price: 2})
+	_, errPatch := repository.Patch(1, domain.ProductRequest{Quantity: &quantity})
+	errDelete := repository.Delete(1)
+	repository.Replace(nil)
+
+	// Assertions
+	assert.ErrorIs(t, errCreate, store.ErrJournalClosed)
+	assert.ErrorIs(t, errPatch, store.ErrJournalClosed)
+	assert.ErrorIs(t, errDelete, store.ErrJournalClosed)
+	assert.Equal(t, products, repository.GetAll())
+}
+
+func TestRepository_LastIdPersisted(t *testing.T) {
+	products := []domain.Product{
+		{Id: 1, Name: "Oil", Quantity: 10, CodeValue: "A1", Status: domain.StatusPublished, Expiration: "15/12/2030", Price: 71.42},
+	}
+	testStore := testutil.NewStore(t, products)
+	repository := NewJournaledRepository(NewRepository(append([]domain.Product(nil), products...)), testStore.Journal)
+	restart := func() Repository {
+		state, err := testStore.LoadState()
+		if err != nil {
+			panic(err)
+		}
+		entries, err := testStore.Journal.Entries()
+		if err != nil {
+			panic(err)
+		}
+		state = store.ReplayState(state, entries)
+		restarted := NewRepository(state.Products)
+		reserveIds(restarted, state.LastId)
+		return restarted
+	}
+
+	// The highest ID is deleted and its creation rolled back, so only the journal remembers them
+	salt, err := repository.Create(domain.Product{Name: "Salt", CodeValue: "C3", Expiration: "15/12/2030", Price: 2})
+	if err != nil {
+		panic(err)
+	}
+	_ = repository.WithTx(func(tx Repository) error {
+		_, _ = tx.Create(domain.Product{Name: "Rice", CodeValue: "B2", Expiration: "15/12/2030", Price: 10})
+		return errors.New("out of stock")
+	})
+	if err = repository.Delete(salt.Id); err != nil {
+		panic(err)
+	}
+	fromJournal, errJournal := restart().Create(domain.Product{Name: "Rice", CodeValue: "B2", Expiration: "15/12/2030", Price: 10})
+
+	// And then only the store file, once the journal is compacted
+	err = testStore.Journal.Compact(func() error {
+		return testStore.SaveState(store.State{Products: repository.GetAll(), LastId: lastId(repository)})
+	})
+	fromFile, errFile := restart().Create(domain.Product{Name: "Rice", CodeValue: "B2", Expiration: "15/12/2030", Price: 10})
+
+	// Assertions
+	assert.NoError(t, errJournal)
+	assert.Equal(t, 4, fromJournal.Id)
+	assert.NoError(t, err)
+	assert.NoError(t, errFile)
+	assert.Equal(t, 4, fromFile.Id)
+}
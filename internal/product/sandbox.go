@@ -0,0 +1,170 @@
+package product
+
+import (
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/filter"
+	"sync"
+	"time"
+)
+
+/*
+The SandboxService struct is a Service decorator that swaps the catalog for a synthetic dataset,
+for example to load test a running server with millions of products. While the sandbox is enabled
+every call is served by an in-memory service over the dataset, with no event bus, cache or journal,
+so nothing reaches the store file or the event consumers; disabling it brings back the real
+catalog untouched.
+*/
+type SandboxService struct {
+	mu      sync.RWMutex
+	service Service
+	sandbox Service
+	size    int
+}
+
+// The NewSandboxService function returns a service that serves the given one until a sandbox is enabled.
+func NewSandboxService(service Service) *SandboxService {
+	return &SandboxService{
+		service: service,
+	}
+}
+
+/*
+The Enable method serves the given products instead of the catalog, replacing the dataset of a
+sandbox already enabled. The products are served as given, keeping their IDs.
+*/
+func (s *SandboxService) Enable(products []domain.Product) {
+	domain.NormalizeExpirations(products)
+	sandbox := NewService(NewRepository(products), nil)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sandbox = sandbox
+	s.size = len(products)
+}
+
+// The Disable method serves the catalog again and discards the dataset. It reports whether a sandbox was enabled.
+func (s *SandboxService) Disable() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	enabled := s.sandbox != nil
+	s.sandbox = nil
+	s.size = 0
+	return enabled
+}
+
+// The Enabled method reports whether a sandbox is enabled, and the number of products it was enabled with.
+func (s *SandboxService) Enabled() (bool, int) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.sandbox != nil, s.size
+}
+
+// The GetAll method returns all available products
+func (s *SandboxService) GetAll() []domain.Product {
+	return s.current().GetAll()
+}
+
+// The List method returns all available products, along with the error of a failed read.
+func (s *SandboxService) List() ([]domain.Product, error) {
+	return s.current().List()
+}
+
+// The GetById method returns a product by its ID
+func (s *SandboxService) GetById(id int) (domain.Product, error) {
+	return s.current().GetById(id)
+}
+
+// The GetByPriceGt method returns all product that has a price greater than the given price.
+func (s *SandboxService) GetByPriceGt(price float64) ([]domain.Product, error) {
+	return s.current().GetByPriceGt(price)
+}
+
+// The Filter method returns the products matching a filter expression.
+func (s *SandboxService) Filter(expr filter.Expr) ([]domain.Product, error) {
+	return s.current().Filter(expr)
+}
+
+// The Create method creates a new product.
+func (s *SandboxService) Create(product domain.Product) (domain.Product, error) {
+	return s.current().Create(product)
+}
+
+// The Update method replaces every field of a product, except its ID, with the given data.
+func (s *SandboxService) Update(id int, updatedProduct domain.Product) (domain.Product, error) {
+	return s.current().Update(id, updatedProduct)
+}
+
+// The Patch method changes only the fields of a product present in the partial update.
+func (s *SandboxService) Patch(id int, partial domain.ProductRequest) (domain.Product, error) {
+	return s.current().Patch(id, partial)
+}
+
+// The PatchMatching method changes the fields present in the partial update of every product matching the filter.
+func (s *SandboxService) PatchMatching(expr filter.Expr, partial domain.ProductRequest, dryRun bool) (BatchResult, error) {
+	return s.current().PatchMatching(expr, partial, dryRun)
+}
+
+// The Clone method creates a copy of a product with a new ID and code value.
+func (s *SandboxService) Clone(id int, changes domain.ProductRequest) (domain.Product, error) {
+	return s.current().Clone(id, changes)
+}
+
+// The Delete method deletes a product.
+func (s *SandboxService) Delete(id int) error {
+	return s.current().Delete(id)
+}
+
+// The Merge method consolidates a duplicate into a product.
+func (s *SandboxService) Merge(id int, duplicateId int) (domain.Product, error) {
+	return s.current().Merge(id, duplicateId)
+}
+
+// The UnpublishExpired method unpublishes every published product whose expiration date has already passed.
+func (s *SandboxService) UnpublishExpired() ([]domain.Product, error) {
+	return s.current().UnpublishExpired()
+}
+
+// The ApplyPublishWindows method publishes or archives the products whose publish window opened or closed.
+func (s *SandboxService) ApplyPublishWindows(now time.Time) ([]domain.Product, error) {
+	return s.current().ApplyPublishWindows(now)
+}
+
+// The Restore method replaces every product with the given ones.
+func (s *SandboxService) Restore(products []domain.Product) {
+	s.current().Restore(products)
+}
+
+// The Reload method replaces every product with the ones returned by the load function.
+func (s *SandboxService) Reload(load func() ([]domain.Product, error)) (bool, error) {
+	return s.current().Reload(load)
+}
+
+/*
+The Version method returns a hash identifying the current state of the served products. The
+sandbox has versions of its own, so the responses cached by version never mix both catalogs.
+*/
+func (s *SandboxService) Version() string {
+	return s.current().Version()
+}
+
+// The As method returns a service changing the served products on behalf of the given actor.
+func (s *SandboxService) As(actor string) Service {
+	return s.current().As(actor)
+}
+
+// The DryRun method returns a service validating the mutations of the served products without applying them.
+func (s *SandboxService) DryRun() Service {
+	return s.current().DryRun()
+}
+
+// Auxiliary method that returns the service of the served products.
+func (s *SandboxService) current() Service {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.sandbox != nil {
+		return s.sandbox
+	}
+	return s.service
+}
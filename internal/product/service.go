@@ -1,35 +1,161 @@
 package product
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/filter"
+	"github.com/JoseObreque/go-web/pkg/events"
+	"log"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
+// Types of the events published by the service after every successful mutation.
+const (
+	EventCreated = "product.created"
+	EventUpdated = "product.updated"
+	EventDeleted = "product.deleted"
+	EventExpired = "product.expired"
+	// EventScheduled is published when the publish window of a product publishes or archives it.
+	EventScheduled = "product.scheduled"
+	// EventMerged is published after the events of a merge, with the merged products as previous state.
+	EventMerged = "product.merged"
+	// EventRestored is published once after every product is replaced by a restore.
+	EventRestored = "products.restored"
+	// EventReloaded is published once after the products are reloaded from the store file.
+	EventReloaded = "products.reloaded"
+)
+
+var ErrSelfMerge = errors.New("a product cannot be merged into itself")
+
 type Service interface {
 	GetAll() []domain.Product
+	List() ([]domain.Product, error)
 	GetById(id int) (domain.Product, error)
 	GetByPriceGt(price float64) ([]domain.Product, error)
+	Filter(expr filter.Expr) ([]domain.Product, error)
 	Create(product domain.Product) (domain.Product, error)
 	Update(id int, updatedProduct domain.Product) (domain.Product, error)
+	Patch(id int, partial domain.ProductRequest) (domain.Product, error)
+	Clone(id int, changes domain.ProductRequest) (domain.Product, error)
+	PatchMatching(expr filter.Expr, partial domain.ProductRequest, dryRun bool) (BatchResult, error)
 	Delete(id int) error
+	Merge(id int, duplicateId int) (domain.Product, error)
+	UnpublishExpired() ([]domain.Product, error)
+	ApplyPublishWindows(now time.Time) ([]domain.Product, error)
+	Restore(products []domain.Product)
+	Reload(load func() ([]domain.Product, error)) (bool, error)
+	Version() string
+	DryRun() Service
+	As(actor string) Service
+}
+
+/*
+The MergedProducts struct is the previous state of the EventMerged events: the product kept and the
+duplicate merged into it, as they were before the merge.
+*/
+type MergedProducts struct {
+	Product   domain.Product `json:"product"`
+	Duplicate domain.Product `json:"duplicate"`
+}
+
+// The BatchResult struct is the outcome of a change applied to every product matching a filter.
+type BatchResult struct {
+	// Number and IDs of the changed products, or of the ones that would change in a dry run
+	Count  int   `json:"count" example:"2"`
+	Ids    []int `json:"ids"`
+	DryRun bool  `json:"dry_run"`
 }
 
 type ServiceImpl struct {
+	// Serializes the writes, so a reload never misses a change made while it runs. The mutex and the
+	// version are shared by the services of every actor
+	mu         *sync.Mutex
 	repository Repository
+	bus        events.Bus
+	seed       string
+	version    *uint64
+	// Who makes the changes published by this service, empty for the system
+	actor string
+	// Acquires the lock of a store shared with other instances, nil if the store is not shared
+	storeLock func() (func(), error)
+	// Record every event durably before it is published, with the writes held
+	recorders []func(event events.Event) error
+	// Data referring to the products by ID, moved to the product a duplicate is merged into
+	referrers []Referrer
 }
 
-// The NewService function returns a new instance of the service.
-func NewService(repository Repository) Service {
+/*
+The Referrer interface is implemented by the data referring to the products by ID, like their
+reviews. Repoint is called with the writes of the catalog held, so it must not change the catalog.
+*/
+type Referrer interface {
+	Repoint(fromId int, toId int) error
+}
+
+/*
+The NewService function returns a new instance of the service. Every successful mutation is
+published to the given event bus; if the bus is nil, no events are published.
+*/
+func NewService(repository Repository, bus events.Bus) Service {
 	return &ServiceImpl{
+		mu:         &sync.Mutex{},
 		repository: repository,
+		bus:        bus,
+		seed:       strconv.FormatInt(time.Now().UnixNano(), 36),
+		version:    new(uint64),
 	}
 }
 
+/*
+The WithStoreLock method makes the writes of the service hold the lock of a store shared with other
+instances of the server, acquired with the given function before the products are read, so a write
+never starts from products another instance changed meanwhile: the function is expected to refresh
+them first with Refresh, like the Lock method of a journal does with OnStale. It returns the service.
+*/
+func (s *ServiceImpl) WithStoreLock(lock func() (func(), error)) *ServiceImpl {
+	s.storeLock = lock
+	return s
+}
+
+/*
+The WithRecorder method makes the service record every event with the given function, like an
+outbox, as part of the mutation: right after the change is written, before the writes are released
+and the event is published to the bus, which drops the events of the subscribers not keeping up.
+A failure to record an event is logged, since the change is already made. It returns the service.
+*/
+func (s *ServiceImpl) WithRecorder(record func(event events.Event) error) *ServiceImpl {
+	s.recorders = append(s.recorders, record)
+	return s
+}
+
+/*
+The WithReferrers method makes the merges move the data of the given referrers from the duplicate
+to the product it is merged into, whatever the API the merge comes from. It returns the service.
+*/
+func (s *ServiceImpl) WithReferrers(referrers ...Referrer) *ServiceImpl {
+	s.referrers = append(s.referrers, referrers...)
+	return s
+}
+
 // The GetAll method returns all available products
 func (s *ServiceImpl) GetAll() []domain.Product {
 	return s.repository.GetAll()
 }
 
+/*
+The List method returns all available products, like GetAll, along with the error of a failed read
+of the repository, like ErrUnavailable while its database is down.
+*/
+func (s *ServiceImpl) List() ([]domain.Product, error) {
+	return list(s.repository, nil)
+}
+
 // The GetById method returns a product by its ID
 func (s *ServiceImpl) GetById(id int) (domain.Product, error) {
 	product, err := s.repository.GetById(id)
@@ -45,69 +171,583 @@ If no product has a price greater than the given price, it returns an error.
 Otherwise, it returns all product that has a price greater than the given price.
 */
 func (s *ServiceImpl) GetByPriceGt(price float64) ([]domain.Product, error) {
-	products := s.repository.GetByPriceGt(price)
+	// The repositories whose reads can fail list the products matching the same comparison instead
+	var products []domain.Product
+	if lister, ok := s.repository.(Lister); ok {
+		var err error
+		products, err = lister.List(filter.Comparison{Field: "price", Kind: filter.Number, Operator: filter.Greater,
+			Value: filter.Value{Number: price}})
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		products = s.repository.GetByPriceGt(price)
+	}
 	if len(products) == 0 {
-		return []domain.Product{}, errors.New("no products found")
+		return []domain.Product{}, ErrNoProducts
 	}
 	return products, nil
 }
 
 /*
-The Create method try to create a new product. If the product already exists, it returns an error.
-Otherwise, it creates a new product and returns it.
+The Filter method returns the products matching a filter expression, evaluated by the repository.
+If no product matches, it returns an error.
+*/
+func (s *ServiceImpl) Filter(expr filter.Expr) ([]domain.Product, error) {
+	products, err := list(s.repository, expr)
+	if err != nil {
+		return nil, err
+	}
+	if len(products) == 0 {
+		return []domain.Product{}, ErrNoProducts
+	}
+	return products, nil
+}
+
+/*
+The Create method try to create a new product. If the product has invalid fields or already exists,
+it returns an error. Otherwise, it creates a new product, a draft if it has no status, and returns it.
+The quantity of a product with lots is their sum, whatever the given one.
 */
 func (s *ServiceImpl) Create(product domain.Product) (domain.Product, error) {
+	product = product.NormalizeLots()
+	if err := product.Validate(); err != nil {
+		return domain.Product{}, err
+	}
+	product.Status = product.Status.OrDraft()
+
+	unlock, err := s.lock()
+	if err != nil {
+		return domain.Product{}, err
+	}
+	defer unlock()
+
+	// Dates are stored in the configured format, whatever the accepted format they arrived in
+	product.Expiration, _ = domain.NormalizeExpiration(product.Expiration)
+
 	newProduct, err := s.repository.Create(product)
 	if err != nil {
 		return domain.Product{}, err
 	}
+
+	s.publish(EventCreated, newProduct, nil)
 	return newProduct, nil
 }
 
 /*
-The Update method try to update a product. If the product does not exist or any updated fields
-data is invalid then returns an error. Otherwise, it updates the product and returns it.
+The Update method replaces every field of a product, except its ID, with the given data. The
+translations, the nutrition facts, the lots and the status are kept if the data has none, since
+they are usually managed on their own, and the quantity of a product with lots is their sum. If the
+new data has invalid fields or a status not allowed from the current one, the product does not
+exist or the new code value is already taken, it returns an error.
 */
 func (s *ServiceImpl) Update(id int, newProductData domain.Product) (domain.Product, error) {
-	// Search the old product data
-	product, err := s.repository.GetById(id)
+	unlock, err := s.lock()
 	if err != nil {
 		return domain.Product{}, err
 	}
+	defer unlock()
 
-	// Update the product data
-	if newProductData.Name != "" {
-		product.Name = newProductData.Name
+	// Keep the old product data for the published event
+	previous, err := s.repository.GetById(id)
+	if err != nil {
+		return domain.Product{}, err
+	}
+	if newProductData.Translations == nil {
+		newProductData.Translations = previous.Translations
 	}
-	if newProductData.Quantity > 0 {
-		product.Quantity = newProductData.Quantity
+	if newProductData.Nutrition == nil {
+		newProductData.Nutrition = previous.Nutrition
 	}
-	if newProductData.CodeValue != "" {
-		product.CodeValue = newProductData.CodeValue
+	if newProductData.Lots == nil {
+		newProductData.Lots = previous.Lots
 	}
-	if newProductData.Expiration != "" {
-		product.Expiration = newProductData.Expiration
+	newProductData = newProductData.NormalizeLots()
+	if newProductData.Status == "" {
+		newProductData.Status = previous.Status
 	}
-	if newProductData.Price > 0 {
-		product.Price = newProductData.Price
+	if err = newProductData.ValidateChanges(previous); err != nil {
+		return domain.Product{}, err
 	}
-	product.IsPublished = newProductData.IsPublished
 
-	// Store the updated product data
-	updatedProduct, err := s.repository.Update(id, product)
+	newProductData.Expiration, _ = domain.NormalizeExpiration(newProductData.Expiration)
+	updatedProduct, err := s.repository.Update(id, newProductData)
 	if err != nil {
 		return domain.Product{}, err
 	}
+
+	s.publish(EventUpdated, updatedProduct, previous)
 	return updatedProduct, nil
 }
 
+/*
+The Patch method changes only the fields of a product present in the partial update. If the
+product does not exist, the patched product has invalid fields or the new code value is already
+taken, it returns an error.
+*/
+func (s *ServiceImpl) Patch(id int, partial domain.ProductRequest) (domain.Product, error) {
+	unlock, err := s.lock()
+	if err != nil {
+		return domain.Product{}, err
+	}
+	defer unlock()
+
+	// Keep the old product data for the published event
+	previous, err := s.repository.GetById(id)
+	if err != nil {
+		return domain.Product{}, err
+	}
+
+	if partial.Expiration != nil {
+		expiration, _ := domain.NormalizeExpiration(*partial.Expiration)
+		partial.Expiration = &expiration
+	}
+	if err = partial.Apply(previous).ValidateChanges(previous); err != nil {
+		return domain.Product{}, err
+	}
+	updatedProduct, err := s.repository.Patch(id, partial)
+	if err != nil {
+		return domain.Product{}, err
+	}
+
+	s.publish(EventUpdated, updatedProduct, previous)
+	return updatedProduct, nil
+}
+
+/*
+The Clone method creates a copy of a product with a new ID. The changes must have a new code value,
+and their other fields replace the copied ones. If the product does not exist, the copy has invalid
+fields or its code value is already taken, it returns an error.
+*/
+func (s *ServiceImpl) Clone(id int, changes domain.ProductRequest) (domain.Product, error) {
+	if changes.CodeValue == nil {
+		return domain.Product{}, &domain.ValidationError{Fields: []domain.FieldError{
+			{Field: "code_value", Rule: "required", Message: "must be given for the copy"},
+		}}
+	}
+
+	source, err := s.repository.GetById(id)
+	if err != nil {
+		return domain.Product{}, err
+	}
+	copied := changes.Apply(source)
+	copied.Id = 0
+	return s.Create(copied)
+}
+
+/*
+The PatchMatching method changes the fields present in the partial update of every product matching
+the filter expression, all or none of them: the changed products are validated before any change,
+and the changes are made in a transaction of the repository, rolled back if any of them fails. The code values are unique, so they
+cannot be changed in batch. In a dry run, the products are only validated.
+*/
+func (s *ServiceImpl) PatchMatching(expr filter.Expr, partial domain.ProductRequest, dryRun bool) (BatchResult, error) {
+	if partial.CodeValue != nil {
+		return BatchResult{}, &domain.ValidationError{Fields: []domain.FieldError{
+			{Field: "code_value", Rule: "excluded", Message: "cannot be changed in batch"},
+		}}
+	}
+
+	unlock, err := s.lock()
+	if err != nil {
+		return BatchResult{}, err
+	}
+	defer unlock()
+
+	if partial.Expiration != nil {
+		expiration, _ := domain.NormalizeExpiration(*partial.Expiration)
+		partial.Expiration = &expiration
+	}
+	matches, err := list(s.repository, expr)
+	if err != nil {
+		return BatchResult{}, err
+	}
+	result := BatchResult{Count: len(matches), Ids: make([]int, len(matches)), DryRun: dryRun}
+	for i, p := range matches {
+		if err := partial.Apply(p).ValidateChanges(p); err != nil {
+			return BatchResult{}, fmt.Errorf("product %d: %w", p.Id, err)
+		}
+		result.Ids[i] = p.Id
+	}
+	if dryRun {
+		return result, nil
+	}
+
+	updated := make([]domain.Product, 0, len(matches))
+	err = s.repository.WithTx(func(tx Repository) error {
+		for _, p := range matches {
+			updatedProduct, err := tx.Patch(p.Id, partial)
+			if err != nil {
+				return fmt.Errorf("product %d: %w", p.Id, err)
+			}
+			updated = append(updated, updatedProduct)
+		}
+		return nil
+	})
+	if err != nil {
+		return BatchResult{}, err
+	}
+
+	for i, updatedProduct := range updated {
+		s.publish(EventUpdated, updatedProduct, matches[i])
+	}
+	return result, nil
+}
+
 /*
 The Delete method try to delete a product. If the product does not exist, it returns an error.
 */
 func (s *ServiceImpl) Delete(id int) error {
-	err := s.repository.Delete(id)
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// Keep the product data for the published event
+	product, err := s.repository.GetById(id)
+	if err != nil {
+		return err
+	}
+
+	err = s.repository.Delete(id)
 	if err != nil {
 		return err
 	}
+
+	s.publish(EventDeleted, product, nil)
 	return nil
 }
+
+/*
+The Merge method consolidates a duplicate into a product, in a transaction of the repository: the
+product keeps its ID, code value and fields, gets the stock of the duplicate, lots included, and its
+category, description and translations it has none of, and the duplicate is deleted. It returns the
+merged product, or an error if any of them does not exist, they are the same product or the merged
+product has invalid fields.
+An EventMerged event follows the deletion and update events.
+*/
+func (s *ServiceImpl) Merge(id int, duplicateId int) (domain.Product, error) {
+	if id == duplicateId {
+		return domain.Product{}, ErrSelfMerge
+	}
+
+	unlock, err := s.lock()
+	if err != nil {
+		return domain.Product{}, err
+	}
+	defer unlock()
+
+	// Keep the old products data for the published events
+	previous, err := s.repository.GetById(id)
+	if err != nil {
+		return domain.Product{}, err
+	}
+	duplicate, err := s.repository.GetById(duplicateId)
+	if err != nil {
+		return domain.Product{}, err
+	}
+	merged := previous.Merge(duplicate)
+	if err = merged.ValidateChanges(previous); err != nil {
+		return domain.Product{}, err
+	}
+
+	var mergedProduct domain.Product
+	err = s.repository.WithTx(func(tx Repository) error {
+		if err := tx.Delete(duplicateId); err != nil {
+			return err
+		}
+		mergedProduct, err = tx.Update(id, merged)
+		return err
+	})
+	if err != nil {
+		return domain.Product{}, err
+	}
+
+	// The data referring to the duplicate follows it before the merge is published. The merge is
+	// already committed, so a failure is logged and the other referrers still move theirs
+	for _, referrer := range s.referrers {
+		if err = referrer.Repoint(duplicateId, id); err != nil {
+			log.Printf("merge: moving the data of product %d to product %d: %s\n", duplicateId, id, err)
+		}
+	}
+
+	// The consumers keeping a copy of the products only need the deletion and the update, the audit
+	// also gets the merge itself
+	s.publish(EventDeleted, duplicate, nil)
+	s.publish(EventUpdated, mergedProduct, previous)
+	s.publish(EventMerged, mergedProduct, MergedProducts{Product: previous, Duplicate: duplicate})
+	return mergedProduct, nil
+}
+
+/*
+The UnpublishExpired method archives every published product whose expiration date has already
+passed and returns the archived products. Products with an unparseable expiration date are left
+untouched. An EventExpired event is published for every archived product.
+*/
+func (s *ServiceImpl) UnpublishExpired() ([]domain.Product, error) {
+	unlock, err := s.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	now := time.Now()
+	unpublished := []domain.Product{}
+
+	for _, p := range s.repository.GetAll() {
+		if !p.Published() {
+			continue
+		}
+		expiration, err := domain.ParseExpiration(p.Expiration)
+		if err != nil || !expiration.Before(now) {
+			continue
+		}
+
+		previous := p
+		p.Status = domain.StatusArchived
+		updatedProduct, err := s.repository.Update(p.Id, p)
+		if err != nil {
+			return unpublished, err
+		}
+
+		s.publish(EventExpired, updatedProduct, previous)
+		unpublished = append(unpublished, updatedProduct)
+	}
+
+	return unpublished, nil
+}
+
+/*
+The ApplyPublishWindows method changes the status of every product whose publish window calls for
+another one at the given moment (see domain.Product.ScheduledStatus): the drafts whose window
+opened are published, and the published products whose window closed are archived. The opening of
+a window is applied once: the PublishFrom of the published drafts is cleared, so a product
+unpublished by hand within its window stays a draft. It returns the changed products, and an
+EventScheduled event is published for every one of them.
+*/
+func (s *ServiceImpl) ApplyPublishWindows(now time.Time) ([]domain.Product, error) {
+	unlock, err := s.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	scheduled := []domain.Product{}
+	for _, p := range s.repository.GetAll() {
+		status, changed := p.ScheduledStatus(now)
+		if !changed {
+			continue
+		}
+
+		previous := p
+		p.Status = status
+		if status == domain.StatusPublished {
+			p.PublishFrom = nil
+		}
+		updatedProduct, err := s.repository.Update(p.Id, p)
+		if err != nil {
+			return scheduled, err
+		}
+
+		s.publish(EventScheduled, updatedProduct, previous)
+		scheduled = append(scheduled, updatedProduct)
+	}
+
+	return scheduled, nil
+}
+
+/*
+The Restore method replaces every product with the given ones, for example from a backup. A single
+EventRestored event is published with the number of restored products.
+*/
+func (s *ServiceImpl) Restore(products []domain.Product) {
+	unlock, err := s.lock()
+	if err != nil {
+		log.Printf("restore: %s\n", err)
+		return
+	}
+	defer unlock()
+
+	s.replace(EventRestored, products)
+}
+
+/*
+The Reload method replaces every product with the ones returned by the load function, typically
+the store file with the pending journal changes replayed over it. No write runs while the products
+are loaded, so the changes made in memory are never lost. Invalid products are rejected with the
+validation report, and products equal to the current ones are left untouched. It returns whether
+the products changed, in which case a single EventReloaded event is published. With a shared store,
+the replacement is recorded holding its lock, like the writes.
+*/
+func (s *ServiceImpl) Reload(load func() ([]domain.Product, error)) (bool, error) {
+	unlock, err := s.lock()
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	return s.reload(load, s.repository.Replace)
+}
+
+/*
+The Refresh method reloads the products like Reload, with the ones of a store shared with other
+instances, after another instance changed them. They are already in the store, so they are not
+recorded again by the repository. It must be called holding the lock of the store, but not within
+a write of the service.
+*/
+func (s *ServiceImpl) Refresh(load func() ([]domain.Product, error)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.reload(load, func(products []domain.Product) {
+		reset(s.repository, products)
+	})
+	return err
+}
+
+/*
+The Version method returns a hash identifying the current state of the catalog. It changes after
+every mutation, and also when the service restarts, so it can be used to validate cached reads.
+*/
+func (s *ServiceImpl) Version() string {
+	version := strconv.FormatUint(atomic.LoadUint64(s.version), 10)
+	sum := sha256.Sum256([]byte(s.seed + ":" + version))
+	return hex.EncodeToString(sum[:8])
+}
+
+/*
+The LastId method returns the highest ID ever assigned to a product, saved along with the products
+so it is never given again after a restart. It is 0 if the repository does not assign the IDs.
+*/
+func (s *ServiceImpl) LastId() int {
+	return lastId(s.repository)
+}
+
+/*
+The ReserveIds method makes the new products get IDs above lastId, like the highest ID of a store
+file, and never lowers the highest ID.
+*/
+func (s *ServiceImpl) ReserveIds(lastId int) {
+	reserveIds(s.repository, lastId)
+}
+
+/*
+The DryRun method returns a service running the same validations as this one, over the same
+products, without applying the mutations nor publishing any event. Its mutations return the
+products as they would be, but the created ones have no ID yet.
+*/
+func (s *ServiceImpl) DryRun() Service {
+	return NewService(NewDryRunRepository(s.repository), nil)
+}
+
+/*
+The As method returns a service making the same changes as this one on behalf of the given actor,
+who is attached to the published events.
+*/
+func (s *ServiceImpl) As(actor string) Service {
+	service := *s
+	service.actor = actor
+	return &service
+}
+
+// Auxiliary method that loads the products and replaces the current ones with the given function if they changed.
+func (s *ServiceImpl) reload(load func() ([]domain.Product, error), replace func([]domain.Product)) (bool, error) {
+	products, err := load()
+	if err != nil {
+		return false, err
+	}
+	domain.NormalizeExpirations(products)
+	if err = ValidateCatalog(products).Err(); err != nil {
+		return false, err
+	}
+	if sameProducts(products, s.repository.GetAll()) {
+		return false, nil
+	}
+
+	replace(products)
+	s.replaced(EventReloaded, len(products))
+	return true, nil
+}
+
+// Auxiliary method that replaces every product and publishes a single event with their number.
+func (s *ServiceImpl) replace(eventType string, products []domain.Product) {
+	s.repository.Replace(products)
+	s.replaced(eventType, len(products))
+}
+
+// Auxiliary method that records the replacement of every product, publishing a single event with their number.
+func (s *ServiceImpl) replaced(eventType string, count int) {
+	atomic.AddUint64(s.version, 1)
+	s.emit(events.Event{
+		Type:  eventType,
+		Data:  map[string]int{"products": count},
+		Actor: s.actor,
+	})
+}
+
+/*
+Auxiliary method that acquires the lock of the shared store, if any, and then the one serializing
+the writes, and returns the function releasing both. The store lock goes first, since refreshing
+the products while acquiring it takes the other one.
+*/
+func (s *ServiceImpl) lock() (func(), error) {
+	unlockStore := func() {}
+	if s.storeLock != nil {
+		var err error
+		if unlockStore, err = s.storeLock(); err != nil {
+			return nil, err
+		}
+	}
+	s.mu.Lock()
+	return func() {
+		s.mu.Unlock()
+		unlockStore()
+	}, nil
+}
+
+/*
+Auxiliary method that records a mutation: it changes the catalog version and publishes a product
+event, if the service has an event bus. The previous state of the product is attached to the
+event when it is not nil.
+*/
+func (s *ServiceImpl) publish(eventType string, product domain.Product, previous interface{}) {
+	atomic.AddUint64(s.version, 1)
+
+	event := events.Event{
+		Type:  eventType,
+		Id:    product.Id,
+		Data:  product,
+		Actor: s.actor,
+	}
+	if previous != nil {
+		event.Previous = previous
+	}
+	s.emit(event)
+}
+
+// Auxiliary method that records an event with every recorder of the service and publishes it, if the service has an event bus.
+func (s *ServiceImpl) emit(event events.Event) {
+	event.Timestamp = time.Now()
+	for _, record := range s.recorders {
+		if err := record(event); err != nil {
+			log.Printf("events: recording %s event of product %d: %s\n", event.Type, event.Id, err)
+		}
+	}
+	if s.bus != nil {
+		s.bus.Publish(event)
+	}
+}
+
+// Auxiliary function that checks if two lists hold the same products in the same order.
+func sameProducts(a, b []domain.Product) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+	return true
+}
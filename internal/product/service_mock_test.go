@@ -0,0 +1,60 @@
+package product_test
+
+import (
+	"errors"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/filter"
+	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/JoseObreque/go-web/internal/product/mocks"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestService_RepositoryMock(t *testing.T) {
+	repository := mocks.NewRepository(t)
+	service := product.NewService(repository, nil)
+	version := service.Version()
+	errDisk := errors.New("disk failure")
+
+	// The expiration dates reach the repository normalized, and the products without status as drafts
+	repository.On("Create", domain.Product{Name: "Oil", CodeValue: "A1", Status: domain.StatusDraft, Expiration: "20/12/2030", Price: 10}).
+		Return(domain.Product{Id: 1, Name: "Oil", CodeValue: "A1", Expiration: "20/12/2030", Price: 10}, nil).Once()
+	repository.On("GetById", 1).Return(domain.Product{Id: 1, Name: "Oil", CodeValue: "A1"}, nil).Once()
+	repository.On("Delete", 1).Return(errDisk).Once()
+
+	created, errCreate := service.Create(domain.Product{Name: "Oil", CodeValue: "A1", Expiration: "2030-12-20", Price: 10})
+	createdVersion := service.Version()
+	errDelete := service.Delete(1)
+
+	// Assertions
+	assert.NoError(t, errCreate)
+	assert.Equal(t, 1, created.Id)
+	assert.NotEqual(t, version, createdVersion)
+	assert.ErrorIs(t, errDelete, errDisk)
+	assert.Equal(t, createdVersion, service.Version())
+}
+
+func TestService_PatchMatching_Rollback(t *testing.T) {
+	repository := mocks.NewRepository(t)
+	service := product.NewService(repository, nil)
+	errDisk := errors.New("disk failure")
+	expr, err := filter.Parse("price > 1")
+	if err != nil {
+		panic(err)
+	}
+	first := domain.Product{Id: 1, Name: "Oil", CodeValue: "A1", Expiration: "20/12/2030", Price: 10}
+	second := domain.Product{Id: 2, Name: "Rice", CodeValue: "A2", Expiration: "20/12/2030", Price: 20}
+	quantity := 3
+	changes := domain.ProductRequest{Quantity: &quantity}
+
+	// The changes are made in a transaction, so the first product is restored by the repository
+	// when the second one fails
+	repository.On("Filter", expr).Return([]domain.Product{first, second}).Once()
+	repository.On("Patch", 1, changes).Return(domain.Product{}, nil).Once()
+	repository.On("Patch", 2, changes).Return(domain.Product{}, errDisk).Once()
+
+	_, err = service.PatchMatching(expr, changes, false)
+
+	// Assertions
+	assert.ErrorIs(t, err, errDisk)
+}
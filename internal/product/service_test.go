@@ -0,0 +1,428 @@
+package product
+
+import (
+	"errors"
+	"fmt"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/filter"
+	"github.com/JoseObreque/go-web/pkg/events"
+	"github.com/JoseObreque/go-web/pkg/lock"
+	"github.com/JoseObreque/go-web/pkg/store"
+	"github.com/JoseObreque/go-web/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestService_Mutations(t *testing.T) {
+	bus := events.NewBus()
+	received, unsubscribe := bus.Subscribe()
+	defer unsubscribe()
+	service := NewService(NewRepository([]domain.Product{
+		{Id: 1, Name: "Oil", Quantity: 10, CodeValue: "A1", Status: domain.StatusPublished, Expiration: "15/12/2030", Price: 71.42},
+	}), bus)
+	version := service.Version()
+	expiration := "2031-01-01"
+
+	updated, errUpdate := service.Update(1, domain.Product{Name: "Olive oil", Quantity: 4, CodeValue: "A1", Expiration: "2030-12-20", Price: 90})
+	patched, errPatch := service.Patch(1, domain.ProductRequest{Expiration: &expiration})
+	_, errPatchMissing := service.Patch(7, domain.ProductRequest{Expiration: &expiration})
+	errDelete := service.Delete(1)
+	errDeleteMissing := service.Delete(1)
+
+	// Assertions
+	assert.NoError(t, errUpdate)
+	assert.Equal(t, "Olive oil", updated.Name)
+	assert.Equal(t, domain.StatusPublished, updated.Status)
+	assert.Equal(t, "20/12/2030", updated.Expiration)
+	assert.NoError(t, errPatch)
+	assert.Equal(t, "01/01/2031", patched.Expiration)
+	assert.Equal(t, "Olive oil", patched.Name)
+	assert.ErrorIs(t, errPatchMissing, ErrNotFound)
+	assert.NoError(t, errDelete)
+	assert.ErrorIs(t, errDeleteMissing, ErrNotFound)
+	assert.Empty(t, service.GetAll())
+	assert.NotEqual(t, version, service.Version())
+	for _, eventType := range []string{EventUpdated, EventUpdated, EventDeleted} {
+		event := <-received
+		assert.Equal(t, eventType, event.Type)
+		assert.Equal(t, 1, event.Id)
+	}
+}
+
+func TestService_Merge(t *testing.T) {
+	bus := events.NewBus()
+	received, unsubscribe := bus.Subscribe()
+	defer unsubscribe()
+	products := []domain.Product{
+		{Id: 1, Name: "Oil", Quantity: 10, CodeValue: "A1", Expiration: "15/12/2030", Price: 71.42,
+			Translations: map[string]domain.Translation{"es": {Name: "Aceite"}}},
+		{Id: 2, Name: "oil", Quantity: 5, CodeValue: "A2", Expiration: "01/01/2031", Price: 71.42, Category: "Pantry",
+			Translations: map[string]domain.Translation{"es": {Name: "Aceite de oliva"}, "pt": {Name: "Óleo"}}},
+	}
+	service := NewService(NewRepository(products), bus)
+
+	merged, err := service.Merge(1, 2)
+	_, errItself := service.Merge(1, 1)
+	_, errMissing := service.Merge(1, 2)
+
+	// Assertions: the fields of the product win over the ones of the duplicate
+	assert.NoError(t, err)
+	assert.Equal(t, "A1", merged.CodeValue)
+	assert.Equal(t, 15, merged.Quantity)
+	assert.Equal(t, "15/12/2030", merged.Expiration)
+	assert.Equal(t, "Pantry", merged.Category)
+	assert.Equal(t, map[string]domain.Translation{"es": {Name: "Aceite"}, "pt": {Name: "Óleo"}}, merged.Translations)
+	assert.Equal(t, []domain.Product{merged}, service.GetAll())
+	assert.ErrorIs(t, errItself, ErrSelfMerge)
+	assert.ErrorIs(t, errMissing, ErrNotFound)
+	for _, expected := range []struct {
+		eventType string
+		id        int
+	}{{EventDeleted, 2}, {EventUpdated, 1}, {EventMerged, 1}} {
+		event := <-received
+		assert.Equal(t, expected.eventType, event.Type)
+		assert.Equal(t, expected.id, event.Id)
+		if event.Type == EventMerged {
+			assert.Equal(t, MergedProducts{Product: products[0], Duplicate: products[1]}, event.Previous)
+		}
+	}
+}
+
+func TestService_Merge_Referrers(t *testing.T) {
+	var moved []string
+	referrer := func(name string, err error) referrerFunc {
+		return func(fromId int, toId int) error {
+			moved = append(moved, fmt.Sprintf("%s %d->%d", name, fromId, toId))
+			return err
+		}
+	}
+	service := NewService(NewRepository(testutil.Fixtures(3)), nil).(*ServiceImpl).
+		WithReferrers(referrer("reviews", errors.New("disk full")), referrer("views", nil))
+
+	_, errDryRun := service.DryRun().Merge(1, 3)
+	merged, err := service.Merge(1, 2)
+	_, errMissing := service.Merge(1, 2)
+
+	// Assertions: a failing referrer does not undo the merge or stop the other ones
+	assert.NoError(t, errDryRun)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, merged.Id)
+	assert.ErrorIs(t, errMissing, ErrNotFound)
+	assert.Equal(t, []string{"reviews 2->1", "views 2->1"}, moved)
+}
+
+// Auxiliary type that implements the Referrer interface with a function.
+type referrerFunc func(fromId int, toId int) error
+
+func (f referrerFunc) Repoint(fromId int, toId int) error {
+	return f(fromId, toId)
+}
+
+func TestService_Lots(t *testing.T) {
+	service := NewService(NewRepository([]domain.Product{
+		{Id: 1, Name: "Milk", Quantity: 12, CodeValue: "A1", Expiration: "15/12/2030", Price: 1,
+			Lots: []domain.Lot{{Number: "L1", Quantity: 12, Expiration: "01/02/2030"}}},
+		{Id: 2, Name: "milk", Quantity: 4, CodeValue: "A2", Expiration: "15/12/2030", Price: 1,
+			Lots: []domain.Lot{{Number: "L2", Quantity: 3, Expiration: "01/01/2030"}, {Number: "L1", Quantity: 1, Expiration: "15/01/2030"}}},
+		{Id: 3, Name: "Milk", Quantity: 4, CodeValue: "A3", Expiration: "15/12/2030", Price: 1},
+	}), nil)
+
+	created, errCreate := service.Create(domain.Product{Name: "Cream", Quantity: 1, CodeValue: "A4", Expiration: "15/12/2030", Price: 2,
+		Lots: []domain.Lot{{Number: "C1", Quantity: 6, Expiration: "2030-05-01"}}})
+	updated, errUpdate := service.Update(4, domain.Product{Name: "Cream", Quantity: 1, CodeValue: "A4", Expiration: "15/12/2030", Price: 3})
+	merged, errMerge := service.Merge(1, 2)
+	untracked, errUntracked := service.Merge(3, 1)
+
+	// Assertions: the quantity of the products with lots is their sum
+	assert.NoError(t, errCreate)
+	assert.Equal(t, 6, created.Quantity)
+	assert.Equal(t, "01/05/2030", created.Lots[0].Expiration)
+	assert.NoError(t, errUpdate)
+	assert.Equal(t, created.Lots, updated.Lots)
+	assert.Equal(t, 6, updated.Quantity)
+	assert.NoError(t, errMerge)
+	assert.Equal(t, 16, merged.Quantity)
+	assert.Equal(t, []domain.Lot{
+		{Number: "L2", Quantity: 3, Expiration: "01/01/2030"},
+		{Number: "L1", Quantity: 13, Expiration: "15/01/2030"},
+	}, merged.Lots)
+	assert.NoError(t, errUntracked)
+	assert.Equal(t, 20, untracked.Quantity)
+	assert.Equal(t, append(merged.Lots, domain.Lot{Number: "A3", Quantity: 4, Expiration: "15/12/2030"}), untracked.Lots)
+}
+
+func TestService_ApplyPublishWindows(t *testing.T) {
+	bus := events.NewBus()
+	received, unsubscribe := bus.Subscribe()
+	defer unsubscribe()
+	now := time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)
+	opened, closed := now.Add(-time.Minute), now
+	service := NewService(NewRepository([]domain.Product{
+		{Id: 1, Name: "Oil", Quantity: 10, CodeValue: "A1", Expiration: "15/12/2030", Price: 71.42, PublishFrom: &opened},
+		{Id: 2, Name: "Rice", Quantity: 5, CodeValue: "B2", Status: domain.StatusPublished, Expiration: "15/12/2030", Price: 10,
+			PublishUntil: &closed},
+		{Id: 3, Name: "Salt", Quantity: 5, CodeValue: "C3", Expiration: "15/12/2030", Price: 2},
+	}), bus)
+
+	scheduled, err := service.ApplyPublishWindows(now)
+	again, errAgain := service.ApplyPublishWindows(now)
+
+	// Unpublished by hand within its window, it is not published again
+	draft := domain.StatusDraft
+	if _, err = service.Patch(1, domain.ProductRequest{Status: &draft}); err != nil {
+		panic(err)
+	}
+	unpublished, errUnpublished := service.ApplyPublishWindows(now.Add(time.Hour))
+	oil, _ := service.GetById(1)
+
+	// Assertions
+	assert.NoError(t, err)
+	assert.Len(t, scheduled, 2)
+	assert.Equal(t, domain.StatusPublished, scheduled[0].Status)
+	assert.Nil(t, scheduled[0].PublishFrom)
+	assert.Equal(t, domain.StatusArchived, scheduled[1].Status)
+	assert.NoError(t, errAgain)
+	assert.Empty(t, again)
+	assert.NoError(t, errUnpublished)
+	assert.Empty(t, unpublished)
+	assert.Equal(t, domain.StatusDraft, oil.Status)
+	for _, id := range []int{1, 2} {
+		event := <-received
+		assert.Equal(t, EventScheduled, event.Type)
+		assert.Equal(t, id, event.Id)
+	}
+}
+
+func TestService_Reload(t *testing.T) {
+	products := []domain.Product{
+		{Id: 1, Name: "Oil", Quantity: 10, CodeValue: "A1", Status: domain.StatusPublished, Expiration: "15/12/2030", Price: 71.42},
+	}
+	testStore := testutil.NewStore(t, products)
+	service := NewService(NewJournaledRepository(NewRepository(products), testStore.Journal), nil)
+	load := func() ([]domain.Product, error) {
+		return testStore.Products(t), nil
+	}
+
+	// A product created in memory survives the edit of the file
+	_, err := service.Create(domain.Product{Name: "Rice", Quantity: 5, CodeValue: "B2", Expiration: "15/12/2030", Price: 10})
+	if err != nil {
+		panic(err)
+	}
+	products[0].Price = 80
+	if err = testStore.Save(products); err != nil {
+		panic(err)
+	}
+	reloaded, err := service.Reload(load)
+	if err != nil {
+		panic(err)
+	}
+	err = testStore.Journal.Compact(func() error { return testStore.Save(service.GetAll()) })
+	if err != nil {
+		panic(err)
+	}
+	unchanged, errUnchanged := service.Reload(load)
+
+	// A file with invalid products is rejected
+	if err = testStore.Save(append(products, domain.Product{Id: 3, CodeValue: "A1"})); err != nil {
+		panic(err)
+	}
+	invalid, errInvalid := service.Reload(load)
+
+	// Assertions
+	assert.True(t, reloaded)
+	assert.False(t, unchanged)
+	assert.NoError(t, errUnchanged)
+	assert.False(t, invalid)
+	assert.ErrorIs(t, errInvalid, ErrInvalidCatalog)
+	assert.Len(t, service.GetAll(), 2)
+	assert.Equal(t, 80.0, service.GetAll()[0].Price)
+	assert.Equal(t, "Rice", service.GetAll()[1].Name)
+}
+
+func TestService_PatchMatching(t *testing.T) {
+	service := NewService(NewRepository([]domain.Product{
+		{Id: 1, Name: "Oil", Quantity: 10, CodeValue: "A1", Status: domain.StatusPublished, Expiration: "15/12/2030", Price: 70, Category: "Acme"},
+		{Id: 2, Name: "Rice", Quantity: 5, CodeValue: "A2", Status: domain.StatusPublished, Expiration: "15/12/2030", Price: 20, Category: "Acme"},
+		{Id: 3, Name: "Salt", Quantity: 8, CodeValue: "B1", Status: domain.StatusPublished, Expiration: "15/12/2030", Price: 5, Category: "Other"},
+	}), nil)
+	acme, err := filter.Parse("category = 'acme'")
+	if err != nil {
+		panic(err)
+	}
+	unpublished, negative, code := domain.StatusDraft, -1.0, "C1"
+
+	dryRun, errDryRun := service.PatchMatching(acme, domain.ProductRequest{Status: &unpublished}, true)
+	// The repository returns its own list, so the states are copied
+	afterDryRun := append([]domain.Product(nil), service.GetAll()...)
+	_, errInvalid := service.PatchMatching(acme, domain.ProductRequest{Price: &negative}, false)
+	_, errCode := service.PatchMatching(acme, domain.ProductRequest{CodeValue: &code}, false)
+	afterInvalid := append([]domain.Product(nil), service.GetAll()...)
+	applied, errApplied := service.PatchMatching(acme, domain.ProductRequest{Status: &unpublished}, false)
+	published, _ := filter.Parse("is_published = true")
+
+	// Assertions: nothing changes but with the valid change applied
+	assert.NoError(t, errDryRun)
+	assert.Equal(t, BatchResult{Count: 2, Ids: []int{1, 2}, DryRun: true}, dryRun)
+	assert.True(t, afterDryRun[0].Published())
+	assert.ErrorIs(t, errInvalid, domain.ErrInvalidProduct)
+	assert.ErrorIs(t, errCode, domain.ErrInvalidProduct)
+	assert.Equal(t, afterDryRun, afterInvalid)
+	assert.NoError(t, errApplied)
+	assert.Equal(t, BatchResult{Count: 2, Ids: []int{1, 2}}, applied)
+	assert.Equal(t, []domain.Product{afterDryRun[2]}, service.GetAll()[2:])
+	assert.Len(t, NewRepository(service.GetAll()).Filter(published), 1)
+}
+
+func TestService_DryRun(t *testing.T) {
+	bus := events.NewBus()
+	received, unsubscribe := bus.Subscribe()
+	defer unsubscribe()
+	oil := domain.Product{Id: 1, Name: "Oil", Quantity: 10, CodeValue: "A1", Status: domain.StatusPublished, Expiration: "15/12/2030", Price: 71.42}
+	service := NewService(NewRepository([]domain.Product{oil}), bus)
+	version := service.Version()
+	dryRun := service.DryRun()
+	rice := domain.Product{Name: "Rice", Quantity: 4, CodeValue: "A2", Expiration: "2030-12-20", Price: 9}
+	taken := rice
+	taken.CodeValue = "A1"
+
+	created, errCreate := dryRun.Create(rice)
+	_, errTaken := dryRun.Create(taken)
+	updated, errUpdate := dryRun.Update(1, rice)
+	_, errMissing := dryRun.Update(7, rice)
+	errDelete := dryRun.Delete(1)
+
+	// Assertions
+	assert.NoError(t, errCreate)
+	assert.Equal(t, 0, created.Id)
+	assert.Equal(t, "20/12/2030", created.Expiration)
+	assert.ErrorIs(t, errTaken, ErrInvalidCode)
+	assert.NoError(t, errUpdate)
+	assert.Equal(t, 1, updated.Id)
+	assert.ErrorIs(t, errMissing, ErrNotFound)
+	assert.NoError(t, errDelete)
+	assert.Equal(t, []domain.Product{oil}, service.GetAll())
+	assert.Equal(t, version, service.Version())
+	assert.Empty(t, received)
+}
+
+func TestService_WithStoreLock(t *testing.T) {
+	products := []domain.Product{
+		{Id: 1, Name: "Oil", Quantity: 10, CodeValue: "A1", Status: domain.StatusPublished, Expiration: "15/12/2030", Price: 71.42},
+	}
+	testStore := testutil.NewStore(t, products)
+	locker := lock.NewFileLock(testStore.Path+".lock", time.Minute)
+
+	// Two instances sharing the store, each with its own journal file handle
+	secondJournal, err := store.OpenJournal(testStore.Path + ".journal")
+	if err != nil {
+		panic(err)
+	}
+	defer secondJournal.Close()
+	bus := events.NewBus()
+	received, unsubscribe := bus.Subscribe()
+	defer unsubscribe()
+	first := shareTestStore(t, testStore, products, locker, testStore.Journal, nil)
+	second := shareTestStore(t, testStore, products, locker, secondJournal, bus)
+
+	// The second instance refreshes the lots set by the first one, kept by its update of the name
+	lots := []domain.Lot{{Number: "L1", Quantity: 10, Expiration: "15/12/2030"}}
+	if _, err = first.Patch(1, domain.ProductRequest{Lots: lots}); err != nil {
+		panic(err)
+	}
+	updated, err := second.Update(1, domain.Product{Name: "Olive oil", Quantity: 10, CodeValue: "A1", Expiration: "15/12/2030", Price: 71.42})
+	entries, _ := testStore.Journal.Entries()
+
+	// Assertions
+	assert.NoError(t, err)
+	assert.Equal(t, "Olive oil", updated.Name)
+	assert.Equal(t, lots, updated.Lots)
+	assert.Equal(t, EventReloaded, (<-received).Type)
+	assert.Equal(t, EventUpdated, (<-received).Type)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, testStore.Products(t), second.GetAll())
+}
+
+func TestService_WithStoreLock_Reload(t *testing.T) {
+	products := []domain.Product{
+		{Id: 1, Name: "Oil", Quantity: 10, CodeValue: "A1", Status: domain.StatusPublished, Expiration: "15/12/2030", Price: 71.42},
+	}
+	testStore := testutil.NewStore(t, products)
+	locker := lock.NewFileLock(testStore.Path+".lock", time.Minute)
+	secondJournal, err := store.OpenJournal(testStore.Path + ".journal")
+	if err != nil {
+		panic(err)
+	}
+	defer secondJournal.Close()
+	first := shareTestStore(t, testStore, products, locker, testStore.Journal, nil)
+	second := shareTestStore(t, testStore, products, locker, secondJournal, nil)
+	edited := append([]domain.Product(nil), products...)
+	edited[0].Price = 80
+	load := func() ([]domain.Product, error) {
+		return edited, nil
+	}
+
+	// The reload waits for the lock held by a write of the other instance
+	unlock, err := testStore.Journal.Lock()
+	if err != nil {
+		panic(err)
+	}
+	locked, errLocked := second.Reload(load)
+	unlock()
+	reloaded, errReloaded := second.Reload(load)
+
+	// The other instance refreshes the replacement recorded in the journal before its next write
+	if err = testStore.Save(edited); err != nil {
+		panic(err)
+	}
+	_, errPatch := first.Patch(1, domain.ProductRequest{Lots: []domain.Lot{{Number: "L1", Quantity: 10, Expiration: "15/12/2030"}}})
+	entries, _ := testStore.Journal.Entries()
+
+	// Assertions
+	assert.False(t, locked)
+	assert.Error(t, errLocked)
+	assert.True(t, reloaded)
+	assert.NoError(t, errReloaded)
+	assert.NoError(t, errPatch)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, 80.0, first.GetAll()[0].Price)
+}
+
+func TestService_WithRecorder(t *testing.T) {
+	bus := events.NewBus()
+	_, unsubscribe := bus.Subscribe()
+	defer unsubscribe()
+	var recorded []events.Event
+	service := NewService(NewRepository(nil), bus).(*ServiceImpl).WithRecorder(func(event events.Event) error {
+		recorded = append(recorded, event)
+		return nil
+	})
+
+	// The events are recorded even when a subscriber of the bus does not keep up
+	for i := 0; i < 100; i++ {
+		_, err := service.Create(domain.Product{Name: "Oil", Quantity: 1, CodeValue: fmt.Sprintf("A%d", i), Expiration: "15/12/2030", Price: 1})
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	// Assertions
+	assert.Len(t, recorded, 100)
+	assert.Equal(t, EventCreated, recorded[99].Type)
+	assert.Equal(t, 100, recorded[99].Id)
+	assert.False(t, recorded[99].Timestamp.IsZero())
+}
+
+/*
+Auxiliary function that returns a service sharing the test store with other instances through the
+given journal, refreshed with the products of the store file when they are stale.
+*/
+func shareTestStore(t *testing.T, testStore *testutil.Store, products []domain.Product, locker lock.Locker, journal *store.Journal, bus events.Bus) Service {
+	service := NewService(NewJournaledRepository(NewRepository(products), journal), bus).(*ServiceImpl)
+	journal.WithLock(locker, time.Second).OnStale(func() error {
+		return service.Refresh(func() ([]domain.Product, error) {
+			return testStore.Products(t), nil
+		})
+	})
+	return service.WithStoreLock(journal.Lock)
+}
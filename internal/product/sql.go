@@ -0,0 +1,587 @@
+package product
+
+import (
+	"database/sql"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/filter"
+	"github.com/JoseObreque/go-web/pkg/resilience"
+	"io/fs"
+	"log"
+	"sync/atomic"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// The Migrations function returns the SQL migrations creating the tables of the SQLRepository.
+func Migrations() fs.FS {
+	sub, err := fs.Sub(migrations, "migrations")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
+
+// Columns of the products table, in the order scanned by scanProduct.
+const productColumns = "id, name, quantity, code_value, status, expiration, price, category, currency, description, translations, " +
+	"publish_from, publish_until, unit, pack_size, nutrition, storage, min_temperature, max_temperature, lots"
+
+/*
+The SQLRepository struct is a Repository backed by the products table of a PostgreSQL database,
+created by the Migrations. The IDs come from the sequence of the table, so the IDs of deleted
+products are never reused. The Repository reads of many products return no errors, so failed
+queries are logged and return no products; List returns their error instead, ErrUnavailable or
+resilience.ErrOpen. With read replicas, the reads are balanced across them while the writes,
+and the reads they depend on, go to the primary database.
+*/
+type SQLRepository struct {
+	db *sql.DB
+	// Transaction of the writes and reads of the repository, nil outside a transaction
+	tx *sql.Tx
+	// Read replicas of the database, and the count of the reads balanced across them
+	replicas []*sql.DB
+	reads    uint64
+	// Circuit breaker of the database, nil if the calls are not guarded, and retry policy of the reads
+	breaker *resilience.Breaker
+	retry   resilience.Retry
+}
+
+// The SQLOption type configures a SQLRepository.
+type SQLOption func(r *SQLRepository)
+
+/*
+The WithBreaker function returns a SQLOption that calls the database through the given circuit
+breaker, so the calls fail fast with resilience.ErrOpen while the database is down instead of
+piling up waiting for it. The reads, which are safe to repeat, are retried with the given policy;
+the writes are never retried.
+*/
+func WithBreaker(breaker *resilience.Breaker, retry resilience.Retry) SQLOption {
+	return func(r *SQLRepository) {
+		r.breaker = breaker
+		r.retry = retry
+	}
+}
+
+/*
+The WithReplicas function returns a SQLOption that balances the reads across the given replicas of
+the database, in turns, reading from the primary when a replica fails. A replica may lag behind the
+primary, so a product may be read as it was shortly after it changed.
+*/
+func WithReplicas(replicas ...*sql.DB) SQLOption {
+	return func(r *SQLRepository) {
+		r.replicas = replicas
+	}
+}
+
+// The NewSQLRepository function returns a repository storing the products in the given database.
+func NewSQLRepository(db *sql.DB, options ...SQLOption) Repository {
+	r := &SQLRepository{
+		db: db,
+	}
+	for _, option := range options {
+		option(r)
+	}
+	return r
+}
+
+// The GetAll method returns all available products
+func (r *SQLRepository) GetAll() []domain.Product {
+	return r.query("SELECT " + productColumns + " FROM products ORDER BY id")
+}
+
+// The GetById method returns a product by its ID
+func (r *SQLRepository) GetById(id int) (domain.Product, error) {
+	return r.getById(false, id)
+}
+
+/*
+Auxiliary method that returns a product by its ID, read from the primary database if primary is
+true, or from the next replica in turn otherwise.
+*/
+func (r *SQLRepository) getById(primary bool, id int) (domain.Product, error) {
+	var product domain.Product
+	found := true
+	err := r.read(primary, func(db querier) error {
+		var err error
+		product, err = scanProduct(db.QueryRow("SELECT "+productColumns+" FROM products WHERE id = $1", id))
+		if errors.Is(err, sql.ErrNoRows) {
+			found = false
+			return nil
+		}
+		return err
+	})
+	if err == nil && !found {
+		return domain.Product{}, fmt.Errorf("%w: id %d", ErrNotFound, id)
+	}
+	return product, err
+}
+
+// The GetByPriceGt method returns a list of products with a price greater than the given price.
+func (r *SQLRepository) GetByPriceGt(price float64) []domain.Product {
+	return r.query("SELECT "+productColumns+" FROM products WHERE price > $1 ORDER BY id", price)
+}
+
+/*
+The Filter method returns a list of the products matching the given filter expression, translated
+to the WHERE clause of the query.
+*/
+func (r *SQLRepository) Filter(expr filter.Expr) []domain.Product {
+	var args []interface{}
+	where := filterSQL(expr, &args)
+	return r.query("SELECT "+productColumns+" FROM products WHERE "+where+" ORDER BY id", args...)
+}
+
+/*
+The List method returns the products matching the given filter expression, every product if it is
+nil, along with the error of a failed read.
+*/
+func (r *SQLRepository) List(expr filter.Expr) ([]domain.Product, error) {
+	if expr == nil {
+		return r.list("SELECT " + productColumns + " FROM products ORDER BY id")
+	}
+	var args []interface{}
+	where := filterSQL(expr, &args)
+	return r.list("SELECT "+productColumns+" FROM products WHERE "+where+" ORDER BY id", args...)
+}
+
+/*
+The Create method creates a new product. If the product code already exists, it will return an error.
+Otherwise, it creates a new product.
+*/
+func (r *SQLRepository) Create(product domain.Product) (domain.Product, error) {
+	if err := r.validateCodeValue(product.CodeValue, 0); err != nil {
+		return domain.Product{}, err
+	}
+
+	translations, err := encodeTranslations(product.Translations)
+	if err != nil {
+		return domain.Product{}, err
+	}
+	nutrition, err := encodeNutrition(product.Nutrition)
+	if err != nil {
+		return domain.Product{}, err
+	}
+	lots, err := encodeLots(product.Lots)
+	if err != nil {
+		return domain.Product{}, err
+	}
+	err = r.write(func() error {
+		return r.primary().QueryRow(
+			`INSERT INTO products (name, quantity, code_value, status, expiration, price, category, currency, description,
+			translations, publish_from, publish_until, unit, pack_size, nutrition, storage, min_temperature, max_temperature, lots)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19) RETURNING id`,
+			product.Name, product.Quantity, product.CodeValue, product.Status.OrDraft(), product.Expiration, product.Price, product.Category,
+			product.Currency, product.Description, translations, product.PublishFrom, product.PublishUntil, product.Unit, product.PackSize,
+			nutrition, product.Storage, product.MinTemperature, product.MaxTemperature, lots,
+		).Scan(&product.Id)
+	})
+	if err != nil {
+		return domain.Product{}, err
+	}
+	return product, nil
+}
+
+/*
+The Update method replaces every field of a product, except its ID. It returns an error if the
+product does not exist or if the new code value is already used by another product.
+*/
+func (r *SQLRepository) Update(id int, updatedProduct domain.Product) (domain.Product, error) {
+	if err := r.validateCodeValue(updatedProduct.CodeValue, id); err != nil {
+		return domain.Product{}, err
+	}
+
+	translations, err := encodeTranslations(updatedProduct.Translations)
+	if err != nil {
+		return domain.Product{}, err
+	}
+	nutrition, err := encodeNutrition(updatedProduct.Nutrition)
+	if err != nil {
+		return domain.Product{}, err
+	}
+	lots, err := encodeLots(updatedProduct.Lots)
+	if err != nil {
+		return domain.Product{}, err
+	}
+	var result sql.Result
+	err = r.write(func() error {
+		var err error
+		result, err = r.primary().Exec(
+			`UPDATE products SET name = $2, quantity = $3, code_value = $4, status = $5, expiration = $6, price = $7, category = $8,
+			currency = $9, description = $10, translations = $11, publish_from = $12, publish_until = $13, unit = $14, pack_size = $15,
+			nutrition = $16, storage = $17, min_temperature = $18, max_temperature = $19, lots = $20 WHERE id = $1`,
+			id, updatedProduct.Name, updatedProduct.Quantity, updatedProduct.CodeValue, updatedProduct.Status.OrDraft(),
+			updatedProduct.Expiration, updatedProduct.Price, updatedProduct.Category, updatedProduct.Currency,
+			updatedProduct.Description, translations, updatedProduct.PublishFrom, updatedProduct.PublishUntil, updatedProduct.Unit,
+			updatedProduct.PackSize, nutrition, updatedProduct.Storage, updatedProduct.MinTemperature, updatedProduct.MaxTemperature, lots,
+		)
+		return err
+	})
+	if err != nil {
+		return domain.Product{}, err
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return domain.Product{}, fmt.Errorf("%w: id %d", ErrNotFound, id)
+	}
+
+	updatedProduct.Id = id
+	return updatedProduct, nil
+}
+
+/*
+The Patch method changes the fields of a product present in the partial update and returns the
+updated product. It returns an error if the product does not exist or if the new code value is
+already used by another product.
+*/
+func (r *SQLRepository) Patch(id int, partial domain.ProductRequest) (domain.Product, error) {
+	// The product is read from the primary, as a lagging replica could undo the latest changes
+	product, err := r.getById(true, id)
+	if err != nil {
+		return domain.Product{}, err
+	}
+	return r.Update(id, partial.Apply(product))
+}
+
+/*
+The Delete method deletes a product. It receives the ID of the product and returns an error if the
+product does not exist.
+*/
+func (r *SQLRepository) Delete(id int) error {
+	var result sql.Result
+	err := r.write(func() error {
+		var err error
+		result, err = r.primary().Exec("DELETE FROM products WHERE id = $1", id)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return fmt.Errorf("%w: id %d", ErrNotFound, id)
+	}
+	return nil
+}
+
+/*
+The Replace method replaces every product with the given ones in a single transaction. The IDs of
+the new products keep following the highest ID ever assigned, even if the given products only have
+lower ones. If the replacement fails, the products are left untouched and the error is logged.
+*/
+func (r *SQLRepository) Replace(products []domain.Product) {
+	err := r.WithTx(func(tx Repository) error {
+		return tx.(*SQLRepository).replace(products)
+	})
+	if err != nil {
+		log.Printf("sql: could not replace the products: %s\n", err)
+	}
+}
+
+/*
+The WithTx method runs fn in a database transaction, committed if fn returns nil and rolled back
+otherwise. The reads within the transaction go to the primary database, and are not retried. A
+transaction started within another one joins it.
+*/
+func (r *SQLRepository) WithTx(fn func(tx Repository) error) error {
+	if r.tx != nil {
+		return fn(r)
+	}
+
+	var tx *sql.Tx
+	err := r.write(func() error {
+		var err error
+		tx, err = r.db.Begin()
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = fn(&SQLRepository{db: r.db, tx: tx, breaker: r.breaker}); err != nil {
+		return err
+	}
+	return r.write(tx.Commit)
+}
+
+// Auxiliary method that replaces every product and raises the ID sequence, within a transaction.
+func (r *SQLRepository) replace(products []domain.Product) error {
+	if _, err := r.tx.Exec("DELETE FROM products"); err != nil {
+		return err
+	}
+	for _, p := range products {
+		translations, err := encodeTranslations(p.Translations)
+		var nutrition interface{}
+		var lots []byte
+		if err == nil {
+			nutrition, err = encodeNutrition(p.Nutrition)
+		}
+		if err == nil {
+			lots, err = encodeLots(p.Lots)
+		}
+		if err == nil {
+			_, err = r.tx.Exec(
+				`INSERT INTO products (id, name, quantity, code_value, status, expiration, price, category, currency, description,
+				translations, publish_from, publish_until, unit, pack_size, nutrition, storage, min_temperature, max_temperature, lots)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)`,
+				p.Id, p.Name, p.Quantity, p.CodeValue, p.Status.OrDraft(), p.Expiration, p.Price, p.Category, p.Currency, p.Description,
+				translations, p.PublishFrom, p.PublishUntil, p.Unit, p.PackSize, nutrition, p.Storage, p.MinTemperature, p.MaxTemperature,
+				lots,
+			)
+		}
+		if err != nil {
+			return fmt.Errorf("product %d: %w", p.Id, err)
+		}
+	}
+
+	// The sequence is only raised, never lowered, so the IDs of deleted products are not reused
+	_, err := r.tx.Exec(`SELECT setval(pg_get_serial_sequence('products', 'id'),
+		GREATEST((SELECT COALESCE(MAX(id), 0) FROM products), (SELECT last_value FROM products_id_seq)))`)
+	return err
+}
+
+// Auxiliary method that runs a products query, logging the errors.
+func (r *SQLRepository) query(query string, args ...interface{}) []domain.Product {
+	products, err := r.list(query, args...)
+	if err != nil {
+		log.Printf("sql: could not read the products: %s\n", err)
+		return nil
+	}
+	return products
+}
+
+// Auxiliary method that runs a products query, returning the error of a failed read.
+func (r *SQLRepository) list(query string, args ...interface{}) ([]domain.Product, error) {
+	var products []domain.Product
+	err := r.read(false, func(db querier) error {
+		var err error
+		products, err = scanQuery(db, query, args...)
+		return err
+	})
+	return products, err
+}
+
+// Auxiliary function that runs a products query on the given database and scans every row.
+func scanQuery(db querier, query string, args ...interface{}) ([]domain.Product, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var products []domain.Product
+	for rows.Next() {
+		product, err := scanProduct(rows)
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, product)
+	}
+	return products, rows.Err()
+}
+
+// Auxiliary method that returns the database of the writes: the transaction, or the primary outside one.
+func (r *SQLRepository) primary() querier {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db
+}
+
+/*
+Auxiliary method that returns the database of the next read: the next replica in turn, or the
+primary without replicas or within a transaction.
+*/
+func (r *SQLRepository) reader() querier {
+	if r.tx != nil || len(r.replicas) == 0 {
+		return r.primary()
+	}
+	return r.replicas[(atomic.AddUint64(&r.reads, 1)-1)%uint64(len(r.replicas))]
+}
+
+/*
+Auxiliary method that runs a read on the primary database if primary is true, or on the next
+database of reader otherwise, through the circuit breaker with retries, if the repository has one.
+A read failing on a replica is run again on the primary, so a replica down does not fail the reads.
+The failed reads return ErrUnavailable, or resilience.ErrOpen while the circuit is open.
+*/
+func (r *SQLRepository) read(primary bool, fn func(db querier) error) error {
+	call := func() error {
+		db := r.primary()
+		if !primary {
+			db = r.reader()
+		}
+		err := fn(db)
+		if err != nil && db != r.primary() {
+			log.Printf("sql: read failed on a replica, reading from the primary: %s\n", err)
+			err = fn(r.primary())
+		}
+		return err
+	}
+	var err error
+	if r.breaker == nil {
+		err = call()
+	} else {
+		err = resilience.Call(r.breaker, r.retry, call)
+	}
+	if err != nil && !errors.Is(err, resilience.ErrOpen) {
+		return fmt.Errorf("%w: %s", ErrUnavailable, err)
+	}
+	return err
+}
+
+// Auxiliary method that runs a write through the circuit breaker, if the repository has one, without retries.
+func (r *SQLRepository) write(fn func() error) error {
+	if r.breaker == nil {
+		return fn()
+	}
+	return r.breaker.Do(fn)
+}
+
+/*
+Auxiliary method that checks if a code value is free, ignoring the product with the given ID. The
+unique constraint of the table is the final guard, this check returns the repository error instead.
+It is part of a write, so it reads from the primary.
+*/
+func (r *SQLRepository) validateCodeValue(codeValue string, id int) error {
+	var taken bool
+	err := r.read(true, func(db querier) error {
+		return db.QueryRow("SELECT EXISTS (SELECT 1 FROM products WHERE code_value = $1 AND id <> $2)", codeValue, id).Scan(&taken)
+	})
+	if err != nil {
+		return err
+	}
+	if taken {
+		return fmt.Errorf("%w: %s", ErrInvalidCode, codeValue)
+	}
+	return nil
+}
+
+// The querier interface is the queries shared by sql.DB and sql.Tx.
+type querier interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// The rowScanner interface is the Scan method shared by sql.Row and sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// Auxiliary function that scans a row holding the productColumns.
+func scanProduct(row rowScanner) (domain.Product, error) {
+	var product domain.Product
+	var translations, nutrition, lots []byte
+	err := row.Scan(&product.Id, &product.Name, &product.Quantity, &product.CodeValue, &product.Status,
+		&product.Expiration, &product.Price, &product.Category, &product.Currency, &product.Description, &translations,
+		&product.PublishFrom, &product.PublishUntil, &product.Unit, &product.PackSize, &nutrition,
+		&product.Storage, &product.MinTemperature, &product.MaxTemperature, &lots)
+	if err != nil {
+		return product, err
+	}
+	if err = json.Unmarshal(translations, &product.Translations); err != nil {
+		return product, err
+	}
+	if len(product.Translations) == 0 {
+		product.Translations = nil
+	}
+	if nutrition != nil {
+		if err = json.Unmarshal(nutrition, &product.Nutrition); err != nil {
+			return product, err
+		}
+	}
+	if err = json.Unmarshal(lots, &product.Lots); err != nil {
+		return product, err
+	}
+	if len(product.Lots) == 0 {
+		product.Lots = nil
+	}
+	return product, nil
+}
+
+// Auxiliary function that encodes the translations of a product as the JSON object of their column.
+func encodeTranslations(translations map[string]domain.Translation) ([]byte, error) {
+	if len(translations) == 0 {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(translations)
+}
+
+// Auxiliary function that encodes the lots of a product as the JSON array of their column.
+func encodeLots(lots []domain.Lot) ([]byte, error) {
+	if len(lots) == 0 {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(lots)
+}
+
+/*
+Auxiliary function that encodes the nutrition facts of a product as the JSON of their column, or
+nil, a NULL, if they are unknown. A nil slice of bytes would be sent as an empty value instead.
+*/
+func encodeNutrition(nutrition *domain.Nutrition) (interface{}, error) {
+	if nutrition == nil {
+		return nil, nil
+	}
+	return json.Marshal(nutrition)
+}
+
+/*
+Auxiliary function that translates a filter expression to a SQL condition, appending its values to
+the query arguments. The conditions follow the Match method of the expressions: the texts are
+compared ignoring the case, the products without a currency have the base one, the expiration
+dates are parsed in any of their layouts, the products without nutrition facts have no allergens or
+diets, and the ones without a temperature match no comparison of it.
+*/
+func filterSQL(expr filter.Expr, args *[]interface{}) string {
+	switch e := expr.(type) {
+	case filter.And:
+		return "(" + filterSQL(e.Left, args) + " AND " + filterSQL(e.Right, args) + ")"
+	case filter.Or:
+		return "(" + filterSQL(e.Left, args) + " OR " + filterSQL(e.Right, args) + ")"
+	case filter.Not:
+		return "NOT " + filterSQL(e.Expr, args)
+	case filter.Comparison:
+		column, placeholder := e.Field, "$%d"
+		var value interface{}
+		switch e.Kind {
+		case filter.Number:
+			value = e.Value.Number
+		case filter.Bool:
+			column, value = "(status = 'published')", e.Value.Bool
+			if e.Field == "nutrition" {
+				column = "(nutrition IS NOT NULL)"
+			}
+		case filter.List:
+			// The lists are the allergens and diets of the nutrition facts, which are stored in lowercase
+			*args = append(*args, e.Field, e.Value.Text)
+			return fmt.Sprintf("COALESCE(nutrition->$%d::text, '[]') ? lower($%d)", len(*args)-1, len(*args))
+		case filter.Date:
+			column = `(CASE WHEN expiration LIKE '____-__-__' THEN to_date(expiration, 'YYYY-MM-DD')
+				ELSE to_date(expiration, 'DD/MM/YYYY') END)`
+			placeholder = "$%d::date"
+			value = e.Value.Date.Format(domain.ISOExpirationLayout)
+		default:
+			if column == "currency" {
+				*args = append(*args, domain.BaseCurrency())
+				column = fmt.Sprintf("COALESCE(NULLIF(currency, ''), $%d)", len(*args))
+			}
+			column, placeholder, value = "lower("+column+")", "lower($%d)", e.Value.Text
+		}
+		*args = append(*args, value)
+		placeholder = fmt.Sprintf(placeholder, len(*args))
+		if e.Operator == filter.Contains {
+			return fmt.Sprintf("strpos(%s, %s) > 0", column, placeholder)
+		}
+		if column == "min_temperature" || column == "max_temperature" {
+			// A missing temperature is a mismatch rather than NULL, so its negation matches like in memory
+			return fmt.Sprintf("COALESCE(%s %s %s, FALSE)", column, e.Operator, placeholder)
+		}
+		return fmt.Sprintf("%s %s %s", column, e.Operator, placeholder)
+	default:
+		return "FALSE"
+	}
+}
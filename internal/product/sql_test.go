@@ -0,0 +1,219 @@
+package product
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSQLRepository_WithReplicas(t *testing.T) {
+	primary, replicas := openFakeDatabase("primary"), []*fakeDatabase{openFakeDatabase("replica-1"), openFakeDatabase("replica-2")}
+	repository := NewSQLRepository(primary.db, WithReplicas(replicas[0].db, replicas[1].db))
+
+	// The reads go to the replicas in turns
+	all := repository.GetAll()
+	found, errFound := repository.GetById(1)
+	repository.GetAll()
+
+	// Assertions
+	assert.Len(t, all, 1)
+	assert.NoError(t, errFound)
+	assert.Equal(t, "Oil", found.Name)
+	assert.Equal(t, []string{"SELECT", "SELECT"}, replicas[0].statements())
+	assert.Equal(t, []string{"SELECT"}, replicas[1].statements())
+	assert.Empty(t, primary.statements())
+}
+
+func TestSQLRepository_WithReplicas_Writes(t *testing.T) {
+	primary, replica := openFakeDatabase("primary-writes"), openFakeDatabase("replica-writes")
+	repository := NewSQLRepository(primary.db, WithReplicas(replica.db))
+	name := "Olive oil"
+
+	// The writes, and the reads they depend on, go to the primary, including the ones of a transaction
+	_, errCreate := repository.Create(domain.Product{Name: "Rice", Quantity: 1, CodeValue: "R1", Expiration: "01/01/2031", Price: 2})
+	patched, errPatch := repository.Patch(1, domain.ProductRequest{Name: &name})
+	errTx := repository.WithTx(func(tx Repository) error {
+		_, err := tx.GetById(1)
+		return err
+	})
+
+	// Assertions
+	assert.NoError(t, errCreate)
+	assert.NoError(t, errPatch)
+	assert.Equal(t, "Olive oil", patched.Name)
+	assert.NoError(t, errTx)
+	assert.Equal(t, []string{"SELECT", "INSERT", "SELECT", "SELECT", "UPDATE", "BEGIN", "SELECT", "COMMIT"}, primary.statements())
+	assert.Empty(t, replica.statements())
+}
+
+func TestSQLRepository_WithReplicas_Fallback(t *testing.T) {
+	primary, replica := openFakeDatabase("primary-fallback"), openFakeDatabase("replica-fallback")
+	replica.fail = true
+	repository := NewSQLRepository(primary.db, WithReplicas(replica.db))
+
+	all := repository.GetAll()
+	found, err := repository.GetById(1)
+
+	// Assertions: the failed reads of the replica are read from the primary
+	assert.Len(t, all, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, found.Id)
+	assert.Equal(t, []string{"SELECT", "SELECT"}, replica.statements())
+	assert.Equal(t, []string{"SELECT", "SELECT"}, primary.statements())
+}
+
+/*
+Auxiliary type that is a database of the fakesql driver holding a single product, which records the
+kind of every statement run on it, like SELECT, and fails them all if fail is true.
+*/
+type fakeDatabase struct {
+	db   *sql.DB
+	mu   sync.Mutex
+	log  []string
+	fail bool
+}
+
+// Fake databases of the fakesql driver, by name.
+var (
+	fakeDatabasesMu sync.Mutex
+	fakeDatabases   = make(map[string]*fakeDatabase)
+)
+
+func init() {
+	sql.Register("fakesql", fakeDriver{})
+}
+
+// Auxiliary function that opens a new fake database with the given name.
+func openFakeDatabase(name string) *fakeDatabase {
+	database := &fakeDatabase{}
+	fakeDatabasesMu.Lock()
+	fakeDatabases[name] = database
+	fakeDatabasesMu.Unlock()
+
+	db, err := sql.Open("fakesql", name)
+	if err != nil {
+		panic(err)
+	}
+	database.db = db
+	return database
+}
+
+// Auxiliary method that records a statement and returns the error of a failing database.
+func (d *fakeDatabase) run(statement string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.log = append(d.log, strings.Fields(statement)[0])
+	if d.fail {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+// Auxiliary method that returns the kinds of the statements run so far.
+func (d *fakeDatabase) statements() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.log
+}
+
+// Auxiliary types implementing the database/sql/driver interfaces over the fake databases.
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	fakeDatabasesMu.Lock()
+	defer fakeDatabasesMu.Unlock()
+	return &fakeConn{database: fakeDatabases[name]}, nil
+}
+
+type fakeConn struct {
+	database *fakeDatabase
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{database: c.database, query: query}, nil
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return c, c.database.run("BEGIN")
+}
+
+func (c *fakeConn) Commit() error {
+	return c.database.run("COMMIT")
+}
+
+func (c *fakeConn) Rollback() error {
+	return nil
+}
+
+type fakeStmt struct {
+	database *fakeDatabase
+	query    string
+}
+
+func (s *fakeStmt) Close() error {
+	return nil
+}
+
+func (s *fakeStmt) NumInput() int {
+	return -1
+}
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if err := s.database.run(s.query); err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(1), nil
+}
+
+/*
+The Query method answers the single product of the database, whose code value is free, and the ID
+of the created products.
+*/
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	if err := s.database.run(s.query); err != nil {
+		return nil, err
+	}
+	switch {
+	case strings.Contains(s.query, "SELECT EXISTS"):
+		return &fakeRows{columns: []string{"exists"}, values: [][]driver.Value{{false}}}, nil
+	case strings.HasPrefix(s.query, "INSERT"):
+		return &fakeRows{columns: []string{"id"}, values: [][]driver.Value{{int64(2)}}}, nil
+	default:
+		return &fakeRows{columns: strings.Split(productColumns, ", "), values: [][]driver.Value{{
+			int64(1), "Oil", int64(10), "A1", "published", "15/12/2030", 71.42, "", "", "", []byte("{}"),
+			nil, nil, "", 0.0, nil, "", nil, nil, []byte("[]"),
+		}}}, nil
+	}
+}
+
+type fakeRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string {
+	return r.columns
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
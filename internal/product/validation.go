@@ -0,0 +1,100 @@
+package product
+
+import (
+	"errors"
+	"fmt"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"strings"
+)
+
+var ErrInvalidCatalog = errors.New("invalid products data")
+
+/*
+The Issue struct describes a problem found in a record of the products data. Index is the position
+of the record in the data, starting at 1.
+*/
+type Issue struct {
+	Index   int
+	Id      int
+	Problem string
+}
+
+// The String method returns the issue as a line of the validation report.
+func (i Issue) String() string {
+	return fmt.Sprintf("record %d (id %d): %s", i.Index, i.Id, i.Problem)
+}
+
+/*
+The Report struct contains the result of the validation of the products data: the issues found,
+the valid products and the products with at least one issue, which are quarantined.
+*/
+type Report struct {
+	Total       int
+	Issues      []Issue
+	Valid       []domain.Product
+	Quarantined []domain.Product
+}
+
+/*
+The ValidateCatalog function checks every product of the data: ids and code values must be unique,
+expiration dates must be valid and prices cannot be negative. When an id or a code value is
+repeated, the first record keeps it and the following ones are reported.
+*/
+func ValidateCatalog(products []domain.Product) Report {
+	report := Report{
+		Total: len(products),
+		Valid: make([]domain.Product, 0, len(products)),
+	}
+	ids := make(map[int]int, len(products))
+	codes := make(map[string]int, len(products))
+
+	for i, p := range products {
+		var problems []string
+		if first, ok := ids[p.Id]; ok {
+			problems = append(problems, fmt.Sprintf("duplicate id, already used by record %d", first))
+		} else {
+			ids[p.Id] = i + 1
+		}
+		if first, ok := codes[p.CodeValue]; ok {
+			problems = append(problems, fmt.Sprintf("duplicate code value %q, already used by record %d", p.CodeValue, first))
+		} else {
+			codes[p.CodeValue] = i + 1
+		}
+		if _, err := domain.ParseExpiration(p.Expiration); err != nil {
+			problems = append(problems, fmt.Sprintf("invalid expiration date %q", p.Expiration))
+		}
+		if p.Price < 0 {
+			problems = append(problems, fmt.Sprintf("negative price %g", p.Price))
+		}
+
+		if len(problems) == 0 {
+			report.Valid = append(report.Valid, p)
+			continue
+		}
+		for _, problem := range problems {
+			report.Issues = append(report.Issues, Issue{Index: i + 1, Id: p.Id, Problem: problem})
+		}
+		report.Quarantined = append(report.Quarantined, p)
+	}
+
+	return report
+}
+
+// The String method returns the report with one line per issue.
+func (r Report) String() string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "%d of %d products have issues", len(r.Quarantined), r.Total)
+	for _, issue := range r.Issues {
+		builder.WriteString("\n  ")
+		builder.WriteString(issue.String())
+	}
+	return builder.String()
+}
+
+// The Err method returns an error wrapping ErrInvalidCatalog with the report, or nil if there are no issues.
+func (r Report) Err() error {
+	if len(r.Issues) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrInvalidCatalog, r)
+}
@@ -0,0 +1,29 @@
+package product
+
+import (
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestValidateCatalog(t *testing.T) {
+	report := ValidateCatalog([]domain.Product{
+		{Id: 1, Name: "Oil", CodeValue: "A1", Expiration: "15/12/2030", Price: 71.42},
+		{Id: 1, Name: "Rice", CodeValue: "B2", Expiration: "15/12/2030", Price: 10},
+		{Id: 3, Name: "Milk", CodeValue: "A1", Expiration: "31/02/2030", Price: -1},
+		{Id: 4, Name: "Salt", CodeValue: "D4", Expiration: "2030-12-15", Price: 2},
+	})
+
+	// Assertions
+	assert.Equal(t, 4, report.Total)
+	assert.Len(t, report.Valid, 2)
+	assert.Len(t, report.Quarantined, 2)
+	assert.Equal(t, []Issue{
+		{Index: 2, Id: 1, Problem: "duplicate id, already used by record 1"},
+		{Index: 3, Id: 3, Problem: `duplicate code value "A1", already used by record 1`},
+		{Index: 3, Id: 3, Problem: `invalid expiration date "31/02/2030"`},
+		{Index: 3, Id: 3, Problem: "negative price -1"},
+	}, report.Issues)
+	assert.ErrorIs(t, report.Err(), ErrInvalidCatalog)
+	assert.NoError(t, ValidateCatalog(report.Valid).Err())
+}
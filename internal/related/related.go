@@ -0,0 +1,106 @@
+/*
+Package related recommends the products similar to a given one, for the "you may also like" section
+of the storefront. The similarity is a simple score of what two products share: their category, the
+words of their names and a price band.
+*/
+package related
+
+import (
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/pkg/fold"
+	"math"
+	"sort"
+	"time"
+)
+
+// Weights of the similarities between two products in their score.
+const (
+	categoryWeight = 3.0
+	wordWeight     = 1.0
+	priceWeight    = 2.0
+)
+
+// PriceBand is the relative difference of price, from the lower one, within which two prices are similar.
+const PriceBand = 0.25
+
+// Minimum length of the words of the names compared, so articles and units are not shared words.
+const minWordLength = 3
+
+/*
+The Score function returns how similar a candidate product is to the target one, zero if they share
+nothing. The same category adds 3, every word of the name they share adds 1, and a price in the
+same currency within the PriceBand adds up to 2, the closer the prices the more. The category and
+the words are compared ignoring case and accents.
+*/
+func Score(target, candidate domain.Product) float64 {
+	shared, price := similarity(target, candidate)
+	return shared + price
+}
+
+/*
+The Find function returns up to limit products of the candidates related to the target, live at the
+given moment (see domain.Product.Live), most similar first, and by ID among the equally similar
+ones. The target and the candidates sharing neither the category nor a word of the name with it are
+never returned, whatever their price.
+*/
+func Find(target domain.Product, candidates []domain.Product, limit int, now time.Time) []domain.Product {
+	type scored struct {
+		product domain.Product
+		score   float64
+	}
+
+	var matches []scored
+	for _, candidate := range candidates {
+		if candidate.Id == target.Id || !candidate.Live(now) {
+			continue
+		}
+		if shared, price := similarity(target, candidate); shared > 0 {
+			matches = append(matches, scored{product: candidate, score: shared + price})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return matches[i].product.Id < matches[j].product.Id
+	})
+
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	related := make([]domain.Product, len(matches))
+	for i, match := range matches {
+		related[i] = match.product
+	}
+	return related
+}
+
+// Auxiliary function that returns the score of the category and words shared by two products, and the one of their prices.
+func similarity(target, candidate domain.Product) (float64, float64) {
+	var shared, price float64
+	if target.Category != "" && fold.String(target.Category) == fold.String(candidate.Category) {
+		shared += categoryWeight
+	}
+
+	words := make(map[string]bool)
+	for _, word := range fold.Words(target.Name) {
+		if len(word) >= minWordLength {
+			words[word] = true
+		}
+	}
+	for _, word := range fold.Words(candidate.Name) {
+		if words[word] {
+			shared += wordWeight
+			// Repeated words of the candidate count once
+			delete(words, word)
+		}
+	}
+
+	if target.PriceCurrency() == candidate.PriceCurrency() && target.Price > 0 && candidate.Price > 0 {
+		difference := math.Abs(target.Price-candidate.Price) / math.Min(target.Price, candidate.Price)
+		if difference <= PriceBand {
+			price = priceWeight * (1 - difference/PriceBand)
+		}
+	}
+	return shared, price
+}
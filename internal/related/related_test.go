@@ -0,0 +1,42 @@
+package related
+
+import (
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestFind(t *testing.T) {
+	now := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	launch := now.Add(time.Hour)
+	target := domain.Product{Id: 1, Name: "Whole milk", Category: "Dairy", Status: domain.StatusPublished, Price: 10}
+	candidates := []domain.Product{
+		target,
+		{Id: 2, Name: "Greek yogurt", Category: "dairy", Status: domain.StatusPublished, Price: 30},
+		{Id: 3, Name: "Skim milk", Category: "Dairy", Status: domain.StatusPublished, Price: 11},
+		{Id: 4, Name: "Milk chocolate", Category: "Sweets", Status: domain.StatusPublished, Price: 10},
+		{Id: 5, Name: "Soy milk", Category: "Dairy", Price: 10},
+		{Id: 6, Name: "Bread", Category: "Bakery", Status: domain.StatusPublished, Price: 10},
+		{Id: 7, Name: "Almond milk", Category: "Lácteos", Status: domain.StatusPublished, Price: 10, Currency: "CLP"},
+		{Id: 8, Name: "Oat milk", Category: "Dairy", Status: domain.StatusPublished, Price: 10, PublishFrom: &launch},
+	}
+
+	related := Find(target, candidates, 3, now)
+
+	// Assertions
+	assert.Equal(t, []int{3, 2, 4}, ids(related))
+	assert.Equal(t, 3+1+2*(1-0.1/PriceBand), Score(target, candidates[2]))
+	assert.Equal(t, 3.0, Score(target, candidates[1]))
+	assert.Equal(t, 2.0, Score(target, candidates[5]))
+	assert.Equal(t, []int{3, 2, 4, 7}, ids(Find(target, candidates, 10, now)))
+}
+
+// Auxiliary function that returns the IDs of the products.
+func ids(products []domain.Product) []int {
+	result := make([]int, len(products))
+	for i, p := range products {
+		result[i] = p.Id
+	}
+	return result
+}
@@ -0,0 +1,155 @@
+package review
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"os"
+	"sort"
+	"sync"
+)
+
+var ErrNotFound = errors.New("review not found")
+
+// Repository is the interface definition for the reviews storage
+type Repository interface {
+	GetAll() []domain.Review
+	GetById(id int) (domain.Review, error)
+	GetByProduct(productId int) []domain.Review
+	Create(review domain.Review) (domain.Review, error)
+	Update(review domain.Review) error
+}
+
+/*
+RepositoryImpl is the implementation of the repository interface. Every change is written to a
+JSON file before returning, so the reviews survive a restart. An empty filepath keeps the reviews
+in memory only.
+*/
+type RepositoryImpl struct {
+	filepath string
+
+	mu      sync.RWMutex
+	reviews []domain.Review
+	lastId  int
+}
+
+/*
+The NewRepository function returns a repository persisted in the given file, loading the reviews
+posted during a previous run if the file exists.
+*/
+func NewRepository(filepath string) (Repository, error) {
+	r := &RepositoryImpl{
+		filepath: filepath,
+	}
+	if filepath == "" {
+		return r, nil
+	}
+
+	data, err := os.ReadFile(filepath)
+	if errors.Is(err, os.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, &r.reviews); err != nil {
+		return nil, err
+	}
+
+	for _, review := range r.reviews {
+		if review.Id > r.lastId {
+			r.lastId = review.Id
+		}
+	}
+	return r, nil
+}
+
+// The GetAll method returns every review, newest first.
+func (r *RepositoryImpl) GetAll() []domain.Review {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return sorted(r.reviews, func(domain.Review) bool { return true })
+}
+
+// The GetById method returns a review by its ID. It returns an error if the review does not exist.
+func (r *RepositoryImpl) GetById(id int) (domain.Review, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, review := range r.reviews {
+		if review.Id == id {
+			return review, nil
+		}
+	}
+	return domain.Review{}, ErrNotFound
+}
+
+// The GetByProduct method returns the reviews of a product, newest first.
+func (r *RepositoryImpl) GetByProduct(productId int) []domain.Review {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return sorted(r.reviews, func(review domain.Review) bool { return review.ProductId == productId })
+}
+
+// The Create method stores a new review, assigning it a new ID.
+func (r *RepositoryImpl) Create(review domain.Review) (domain.Review, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastId++
+	review.Id = r.lastId
+	r.reviews = append(r.reviews, review)
+	return review, r.save()
+}
+
+// The Update method replaces a stored review. It returns an error if the review does not exist.
+func (r *RepositoryImpl) Update(review domain.Review) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.reviews {
+		if r.reviews[i].Id == review.Id {
+			r.reviews[i] = review
+			return r.save()
+		}
+	}
+	return ErrNotFound
+}
+
+// Auxiliary method that writes the reviews to the repository file, if any.
+func (r *RepositoryImpl) save() error {
+	if r.filepath == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(r.reviews)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so a crash never leaves a truncated file
+	tmp := r.filepath + ".tmp"
+	if err = os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, r.filepath)
+}
+
+// Auxiliary function that returns a copy of the reviews matching the filter, newest first.
+func sorted(reviews []domain.Review, match func(review domain.Review) bool) []domain.Review {
+	result := []domain.Review{}
+	for _, review := range reviews {
+		if match(review) {
+			result = append(result, review)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.After(result[j].CreatedAt)
+		}
+		return result[i].Id > result[j].Id
+	})
+	return result
+}
@@ -0,0 +1,215 @@
+package review
+
+import (
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/product"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+/*
+The Filter struct selects the reviews listed to the administrators. Every field left nil or zero
+matches any review.
+*/
+type Filter struct {
+	ProductId int
+	Flagged   *bool
+	Hidden    *bool
+}
+
+// The Match method checks if the review matches every field of the filter.
+func (f Filter) Match(review domain.Review) bool {
+	return (f.ProductId == 0 || review.ProductId == f.ProductId) &&
+		(f.Flagged == nil || review.Flagged == *f.Flagged) &&
+		(f.Hidden == nil || review.Hidden == *f.Hidden)
+}
+
+type Service interface {
+	List(productId int) ([]domain.Review, error)
+	Search(filter Filter) []domain.Review
+	Post(productId int, request domain.ReviewRequest) (domain.Review, error)
+	Moderate(id int, moderation domain.ReviewModeration) (domain.Review, error)
+	Rating(productId int) *domain.Rating
+	Repoint(fromId int, toId int) error
+	Version() string
+}
+
+// The totals struct is the sum and number of the visible ratings of a product.
+type totals struct {
+	sum   int
+	count int
+}
+
+/*
+ServiceImpl is the implementation of the Service interface. The rating of every product is kept
+denormalized in memory and updated on every change of its reviews, so the product responses never
+go through the reviews to include it.
+*/
+type ServiceImpl struct {
+	repository Repository
+	products   product.Service
+	version    uint64
+
+	mu      sync.RWMutex
+	ratings map[int]totals
+}
+
+/*
+The NewService function returns a new instance of the service, with the ratings of the reviews
+already in the repository. The reviewed products are looked up in the given product service.
+*/
+func NewService(repository Repository, products product.Service) Service {
+	s := &ServiceImpl{
+		repository: repository,
+		products:   products,
+		ratings:    make(map[int]totals),
+	}
+	for _, review := range repository.GetAll() {
+		s.count(review, 1)
+	}
+	return s
+}
+
+/*
+The List method returns the visible reviews of a product, newest first, without their moderation
+fields. It returns an error if the product does not exist.
+*/
+func (s *ServiceImpl) List(productId int) ([]domain.Review, error) {
+	if _, err := s.products.GetById(productId); err != nil {
+		return nil, err
+	}
+
+	visible := []domain.Review{}
+	for _, review := range s.repository.GetByProduct(productId) {
+		if !review.Hidden {
+			visible = append(visible, review.Public())
+		}
+	}
+	return visible, nil
+}
+
+// The Search method returns every review matching the filter, hidden ones included, newest first.
+func (s *ServiceImpl) Search(filter Filter) []domain.Review {
+	matches := []domain.Review{}
+	for _, review := range s.repository.GetAll() {
+		if filter.Match(review) {
+			matches = append(matches, review)
+		}
+	}
+	return matches
+}
+
+/*
+The Post method stores a new review of a product and updates its rating. It returns an error if
+the product does not exist or the review has invalid fields.
+*/
+func (s *ServiceImpl) Post(productId int, request domain.ReviewRequest) (domain.Review, error) {
+	if _, err := s.products.GetById(productId); err != nil {
+		return domain.Review{}, err
+	}
+	if err := request.Validate(); err != nil {
+		return domain.Review{}, err
+	}
+
+	// The review is counted along its creation, so a concurrent moderation or repoint never misses it
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	review, err := s.repository.Create(domain.Review{
+		ProductId: productId,
+		Rating:    request.Rating,
+		Comment:   request.Comment,
+		Author:    request.Author,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return domain.Review{}, err
+	}
+	s.count(review, 1)
+	atomic.AddUint64(&s.version, 1)
+	return review, nil
+}
+
+/*
+The Moderate method changes the moderation fields of a review and updates the rating of its
+product, which leaves the hidden reviews out. If the review does not exist, it returns an error.
+*/
+func (s *ServiceImpl) Moderate(id int, moderation domain.ReviewModeration) (domain.Review, error) {
+	// The ratings are locked along the change, so concurrent moderations never count a review twice
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, err := s.repository.GetById(id)
+	if err != nil {
+		return domain.Review{}, err
+	}
+
+	review := moderation.Apply(previous)
+	if err = s.repository.Update(review); err != nil {
+		return domain.Review{}, err
+	}
+	s.count(previous, -1)
+	s.count(review, 1)
+	atomic.AddUint64(&s.version, 1)
+	return review, nil
+}
+
+// The Rating method returns the summary of the visible reviews of a product, or nil if it has none.
+func (s *ServiceImpl) Rating(productId int) *domain.Rating {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	productTotals := s.ratings[productId]
+	return domain.NewRating(productTotals.sum, productTotals.count)
+}
+
+/*
+The Repoint method moves every review of a product to another one, like the product a duplicate is
+merged into, along with its rating.
+*/
+func (s *ServiceImpl) Repoint(fromId int, toId int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, review := range s.repository.GetByProduct(fromId) {
+		moved := review
+		moved.ProductId = toId
+		if err := s.repository.Update(moved); err != nil {
+			return err
+		}
+		s.count(review, -1)
+		s.count(moved, 1)
+		atomic.AddUint64(&s.version, 1)
+	}
+	return nil
+}
+
+/*
+The Version method returns a number identifying the current state of the reviews, which changes
+after every change of the reviews. Along with the catalog version, it validates the cached reads of
+the products, which include their rating.
+*/
+func (s *ServiceImpl) Version() string {
+	return strconv.FormatUint(atomic.LoadUint64(&s.version), 10)
+}
+
+/*
+Auxiliary method that adds (delta 1) or removes (delta -1) a visible review from the rating of its
+product. The caller must hold the lock of the ratings.
+*/
+func (s *ServiceImpl) count(review domain.Review, delta int) {
+	if review.Hidden {
+		return
+	}
+
+	productTotals := s.ratings[review.ProductId]
+	productTotals.sum += delta * review.Rating
+	productTotals.count += delta
+	if productTotals.count == 0 {
+		delete(s.ratings, review.ProductId)
+		return
+	}
+	s.ratings[review.ProductId] = productTotals
+}
@@ -0,0 +1,55 @@
+package review
+
+import (
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/JoseObreque/go-web/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+)
+
+func TestService_Rating(t *testing.T) {
+	products := product.NewService(product.NewRepository(testutil.Fixtures(2)), nil)
+	file := filepath.Join(t.TempDir(), "reviews.json")
+	repository, err := NewRepository(file)
+	if err != nil {
+		panic(err)
+	}
+	service := NewService(repository, products)
+	version := service.Version()
+
+	first, errFirst := service.Post(1, domain.ReviewRequest{Rating: 5, Author: "Jane", Comment: "Sweet and juicy"})
+	_, errSecond := service.Post(1, domain.ReviewRequest{Rating: 4, Author: "John"})
+	_, errThird := service.Post(1, domain.ReviewRequest{Rating: 4, Author: "Ann"})
+	_, errMissing := service.Post(7, domain.ReviewRequest{Rating: 5, Author: "Jane"})
+	_, errInvalid := service.Post(1, domain.ReviewRequest{Rating: 6, Author: " "})
+	rating := service.Rating(1)
+
+	// Hiding a review leaves it out of the listing and the rating, which survive a restart
+	hidden := true
+	moderated, errModerate := service.Moderate(first.Id, domain.ReviewModeration{Hidden: &hidden})
+	listed, errList := service.List(1)
+	reloadedRepository, _ := NewRepository(file)
+	reloaded := NewService(reloadedRepository, products)
+	_, errModerateMissing := service.Moderate(99, domain.ReviewModeration{Hidden: &hidden})
+
+	// Assertions
+	assert.NoError(t, errFirst)
+	assert.NoError(t, errSecond)
+	assert.NoError(t, errThird)
+	assert.ErrorIs(t, errMissing, product.ErrNotFound)
+	assert.ErrorIs(t, errInvalid, domain.ErrInvalidProduct)
+	assert.Equal(t, &domain.Rating{Average: 4.33, Count: 3}, rating)
+	assert.NoError(t, errModerate)
+	assert.True(t, moderated.Hidden)
+	assert.NoError(t, errList)
+	assert.Len(t, listed, 2)
+	assert.Equal(t, "Ann", listed[0].Author)
+	assert.Equal(t, &domain.Rating{Average: 4, Count: 2}, service.Rating(1))
+	assert.Equal(t, &domain.Rating{Average: 4, Count: 2}, reloaded.Rating(1))
+	assert.Nil(t, service.Rating(2))
+	assert.Len(t, service.Search(Filter{Hidden: &hidden}), 1)
+	assert.ErrorIs(t, errModerateMissing, ErrNotFound)
+	assert.NotEqual(t, version, service.Version())
+}
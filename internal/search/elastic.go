@@ -0,0 +1,205 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Mapping of the products index: the text fields are analyzed, the code value is matched as a whole.
+const elasticMapping = `{
+	"mappings": {
+		"properties": {
+			"name": {"type": "text"},
+			"category": {"type": "text"},
+			"code_value": {"type": "keyword"},
+			"price": {"type": "double"},
+			"status": {"type": "keyword"}
+		}
+	}
+}`
+
+/*
+The elasticEngine struct is an Engine backed by an external Elasticsearch cluster, through its REST
+API. The queries match the name, the category and the code value with fuzziness, so the typos are
+tolerated, and the results are ranked by the relevance computed by Elasticsearch.
+*/
+type elasticEngine struct {
+	url    string
+	index  string
+	client *http.Client
+}
+
+// The NewElasticEngine function returns an engine storing the products in the given index of the cluster at url.
+func NewElasticEngine(url string, index string, client *http.Client) Engine {
+	return &elasticEngine{
+		url:    strings.TrimSuffix(url, "/"),
+		index:  index,
+		client: client,
+	}
+}
+
+// The elasticDocument struct is the indexed representation of a product.
+type elasticDocument struct {
+	Name      string        `json:"name"`
+	Category  string        `json:"category,omitempty"`
+	CodeValue string        `json:"code_value"`
+	Price     float64       `json:"price"`
+	Status    domain.Status `json:"status"`
+}
+
+// The Index method indexes a product, replacing its previous version.
+func (e *elasticEngine) Index(ctx context.Context, product domain.Product) error {
+	body, err := json.Marshal(document(product))
+	if err != nil {
+		return err
+	}
+	return e.do(ctx, http.MethodPut, "/"+e.index+"/_doc/"+strconv.Itoa(product.Id), "application/json", body, nil)
+}
+
+// The Delete method removes a product from the index. Deleting a product that is not indexed is not an error.
+func (e *elasticEngine) Delete(ctx context.Context, id int) error {
+	err := e.do(ctx, http.MethodDelete, "/"+e.index+"/_doc/"+strconv.Itoa(id), "", nil, nil)
+	if statusErr, ok := err.(*statusError); ok && statusErr.status == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+/*
+The Rebuild method recreates the index with the given products, sent in a single bulk request. The
+searches made while the index is recreated may miss products.
+*/
+func (e *elasticEngine) Rebuild(ctx context.Context, products []domain.Product) error {
+	err := e.do(ctx, http.MethodDelete, "/"+e.index, "", nil, nil)
+	if statusErr, ok := err.(*statusError); err != nil && (!ok || statusErr.status != http.StatusNotFound) {
+		return err
+	}
+	if err = e.do(ctx, http.MethodPut, "/"+e.index, "application/json", []byte(elasticMapping), nil); err != nil {
+		return err
+	}
+	if len(products) == 0 {
+		return nil
+	}
+
+	// The bulk body is an action line followed by the document, for every product
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, p := range products {
+		action := map[string]map[string]string{"index": {"_id": strconv.Itoa(p.Id)}}
+		if err = encoder.Encode(action); err != nil {
+			return err
+		}
+		if err = encoder.Encode(document(p)); err != nil {
+			return err
+		}
+	}
+
+	var result struct {
+		Errors bool `json:"errors"`
+	}
+	if err = e.do(ctx, http.MethodPost, "/"+e.index+"/_bulk?refresh=true", "application/x-ndjson", body.Bytes(), &result); err != nil {
+		return err
+	}
+	if result.Errors {
+		return fmt.Errorf("%w: some products could not be indexed", ErrUnavailable)
+	}
+	return nil
+}
+
+// The Search method returns up to limit IDs of the products matching the query, most relevant first.
+func (e *elasticEngine) Search(ctx context.Context, query string, limit int) ([]int, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"size":    limit,
+		"_source": false,
+		"query": map[string]interface{}{
+			"multi_match": map[string]interface{}{
+				"query":     query,
+				"fields":    []string{"name^3", "category^2", "code_value"},
+				"fuzziness": "AUTO",
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Hits struct {
+			Hits []struct {
+				Id string `json:"_id"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err = e.do(ctx, http.MethodPost, "/"+e.index+"/_search", "application/json", body, &result); err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, 0, len(result.Hits.Hits))
+	for _, hit := range result.Hits.Hits {
+		if id, err := strconv.Atoi(hit.Id); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// The statusError struct is the error of a request answered with an unexpected status.
+type statusError struct {
+	status int
+	body   string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("%s: elasticsearch answered %d: %s", ErrUnavailable, e.status, e.body)
+}
+
+func (e *statusError) Unwrap() error {
+	return ErrUnavailable
+}
+
+/*
+Auxiliary method that sends a request to the cluster and decodes the answer into result, if not
+nil. The failed requests and the statuses other than 2xx return an error matching ErrUnavailable.
+*/
+func (e *elasticEngine) do(ctx context.Context, method, path, contentType string, body []byte, result interface{}) error {
+	request, err := http.NewRequestWithContext(ctx, method, e.url+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		request.Header.Set("Content-Type", contentType)
+	}
+
+	response, err := e.client.Do(request)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrUnavailable, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, 512))
+		return &statusError{status: response.StatusCode, body: string(message)}
+	}
+	if result == nil {
+		return nil
+	}
+	return json.NewDecoder(response.Body).Decode(result)
+}
+
+// Auxiliary function that converts a product into its indexed representation.
+func document(p domain.Product) elasticDocument {
+	return elasticDocument{
+		Name:      p.Name,
+		Category:  p.Category,
+		CodeValue: p.CodeValue,
+		Price:     p.Price,
+		Status:    p.Status.OrDraft(),
+	}
+}
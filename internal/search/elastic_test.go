@@ -0,0 +1,55 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestElasticEngine(t *testing.T) {
+	var requests []string
+	var searchBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch {
+		case r.Method == http.MethodDelete && r.URL.Path == "/products/_doc/9":
+			w.WriteHeader(http.StatusNotFound)
+		case r.URL.Path == "/products/_search":
+			body, _ := io.ReadAll(r.Body)
+			searchBody = string(body)
+			_, _ = io.WriteString(w, `{"hits":{"hits":[{"_id":"2"},{"_id":"1"}]}}`)
+		case r.URL.Path == "/products/_bulk":
+			_, _ = io.WriteString(w, `{"errors":false}`)
+		default:
+			_, _ = io.WriteString(w, `{}`)
+		}
+	}))
+	defer server.Close()
+	ctx := context.Background()
+	engine := NewElasticEngine(server.URL+"/", "products", server.Client())
+
+	rebuildErr := engine.Rebuild(ctx, []domain.Product{{Id: 1, Name: "Pineapple"}})
+	indexErr := engine.Index(ctx, domain.Product{Id: 2, Name: "Pineapple juice"})
+	deleteErr := engine.Delete(ctx, 9)
+	ids, searchErr := engine.Search(ctx, "pineaple", 5)
+	server.Close()
+	_, unavailableErr := engine.Search(ctx, "pineapple", 5)
+
+	// Assertions
+	assert.NoError(t, rebuildErr)
+	assert.NoError(t, indexErr)
+	assert.NoError(t, deleteErr)
+	assert.NoError(t, searchErr)
+	assert.Equal(t, []int{2, 1}, ids)
+	assert.Equal(t, []string{
+		"DELETE /products", "PUT /products", "POST /products/_bulk",
+		"PUT /products/_doc/2", "DELETE /products/_doc/9", "POST /products/_search",
+	}, requests)
+	assert.Contains(t, searchBody, `"fuzziness":"AUTO"`)
+	assert.True(t, errors.Is(unavailableErr, ErrUnavailable))
+}
@@ -0,0 +1,225 @@
+package search
+
+import (
+	"context"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/pkg/fold"
+	"math"
+	"sort"
+	"sync"
+)
+
+// Weights of the matches of every product field in the relevance of the results.
+const (
+	nameWeight     = 3.0
+	categoryWeight = 2.0
+	codeWeight     = 1.0
+)
+
+/*
+The memoryEngine struct is an embedded Engine keeping an inverted index of the product words in
+memory. Matches are ranked with TF-IDF, normalized by the number of words of the product, and the
+query words also match the indexed words at a small edit distance, at a lower relevance.
+*/
+type memoryEngine struct {
+	mu sync.RWMutex
+	// Weighted frequency of every word in every product, and the words of every product
+	postings map[string]map[int]float64
+	words    map[int][]string
+}
+
+// The NewMemoryEngine function returns an empty embedded search engine.
+func NewMemoryEngine() Engine {
+	return &memoryEngine{
+		postings: make(map[string]map[int]float64),
+		words:    make(map[int][]string),
+	}
+}
+
+// The Size method returns the number of indexed products.
+func (e *memoryEngine) Size() int {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return len(e.words)
+}
+
+// The Index method indexes a product, replacing its previous version.
+func (e *memoryEngine) Index(ctx context.Context, product domain.Product) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.delete(product.Id)
+	e.index(product)
+	return nil
+}
+
+// The Delete method removes a product from the index.
+func (e *memoryEngine) Delete(ctx context.Context, id int) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.delete(id)
+	return nil
+}
+
+// The Rebuild method replaces the whole index with the given products.
+func (e *memoryEngine) Rebuild(ctx context.Context, products []domain.Product) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.postings = make(map[string]map[int]float64)
+	e.words = make(map[int][]string)
+	for _, p := range products {
+		e.index(p)
+	}
+	return nil
+}
+
+/*
+The Search method returns up to limit IDs of the products with the words of the query, most relevant
+first. A product matches if it has any of the words, or a word close enough to it.
+*/
+func (e *memoryEngine) Search(ctx context.Context, query string, limit int) ([]int, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	scores := make(map[int]float64)
+	total := float64(len(e.words))
+	for _, queryWord := range fold.Words(query) {
+		// Every product scores the best match of the query word among its words
+		best := make(map[int]float64)
+		for word, postings := range e.postings {
+			distance := editDistance(queryWord, word, maxEdits(queryWord))
+			if distance < 0 {
+				continue
+			}
+			idf := math.Log(1 + total/float64(len(postings)))
+			for id, frequency := range postings {
+				score := frequency * idf / float64(1+distance)
+				if score > best[id] {
+					best[id] = score
+				}
+			}
+		}
+		for id, score := range best {
+			scores[id] += score
+		}
+	}
+
+	ids := make([]int, 0, len(scores))
+	for id := range scores {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(a, b int) bool {
+		if scores[ids[a]] != scores[ids[b]] {
+			return scores[ids[a]] > scores[ids[b]]
+		}
+		return ids[a] < ids[b]
+	})
+	if limit > 0 && len(ids) > limit {
+		ids = ids[:limit]
+	}
+	return ids, nil
+}
+
+// Auxiliary method that adds the words of a product to the index.
+func (e *memoryEngine) index(product domain.Product) {
+	frequencies := make(map[string]float64)
+	for _, field := range []struct {
+		text   string
+		weight float64
+	}{
+		{product.Name, nameWeight},
+		{product.Category, categoryWeight},
+		{product.CodeValue, codeWeight},
+	} {
+		for _, word := range fold.Words(field.text) {
+			frequencies[word] += field.weight
+		}
+	}
+
+	// Matches in products with fewer words are more relevant
+	norm := math.Sqrt(float64(len(frequencies)))
+	words := make([]string, 0, len(frequencies))
+	for word, frequency := range frequencies {
+		if e.postings[word] == nil {
+			e.postings[word] = make(map[int]float64)
+		}
+		e.postings[word][product.Id] = frequency / norm
+		words = append(words, word)
+	}
+	e.words[product.Id] = words
+}
+
+// Auxiliary method that removes the words of a product from the index.
+func (e *memoryEngine) delete(id int) {
+	for _, word := range e.words[id] {
+		delete(e.postings[word], id)
+		if len(e.postings[word]) == 0 {
+			delete(e.postings, word)
+		}
+	}
+	delete(e.words, id)
+}
+
+// Auxiliary function that returns the typos tolerated in a word: none for short words, two for long ones.
+func maxEdits(word string) int {
+	switch length := len([]rune(word)); {
+	case length < 4:
+		return 0
+	case length < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+/*
+Auxiliary function that returns the Levenshtein distance between two words, or -1 if it is greater
+than maxDistance. Rows whose every value exceeds it stop the computation early.
+*/
+func editDistance(a, b string, maxDistance int) int {
+	x, y := []rune(a), []rune(b)
+	if diff := len(x) - len(y); diff > maxDistance || -diff > maxDistance {
+		return -1
+	}
+
+	previous := make([]int, len(y)+1)
+	current := make([]int, len(y)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(x); i++ {
+		current[0] = i
+		rowMin := current[0]
+		for j := 1; j <= len(y); j++ {
+			cost := 1
+			if x[i-1] == y[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+			if current[j] < rowMin {
+				rowMin = current[j]
+			}
+		}
+		if rowMin > maxDistance {
+			return -1
+		}
+		previous, current = current, previous
+	}
+	if previous[len(y)] > maxDistance {
+		return -1
+	}
+	return previous[len(y)]
+}
+
+// Auxiliary function that returns the smallest of the given numbers.
+func minInt(first int, others ...int) int {
+	for _, n := range others {
+		if n < first {
+			first = n
+		}
+	}
+	return first
+}
@@ -0,0 +1,48 @@
+package search
+
+import (
+	"context"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMemoryEngine_Search(t *testing.T) {
+	ctx := context.Background()
+	engine := NewMemoryEngine()
+	err := engine.Rebuild(ctx, []domain.Product{
+		{Id: 1, Name: "Pineapple juice", Category: "Drinks", CodeValue: "J1"},
+		{Id: 2, Name: "Pineapple", Category: "Fruits", CodeValue: "F1"},
+		{Id: 3, Name: "Apple", Category: "Fruits", CodeValue: "F2"},
+		{Id: 4, Name: "Orange juice", Category: "Drinks", CodeValue: "J2"},
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	exact, _ := engine.Search(ctx, "pineapple", 10)
+	typo, _ := engine.Search(ctx, "pineaple", 10)
+	category, _ := engine.Search(ctx, "fruits", 10)
+	limited, _ := engine.Search(ctx, "juice", 1)
+	_ = engine.Delete(ctx, 2)
+	_ = engine.Index(ctx, domain.Product{Id: 4, Name: "Mango juice", CodeValue: "J2"})
+	afterDelete, _ := engine.Search(ctx, "pineapple", 10)
+	renamed, _ := engine.Search(ctx, "orange", 10)
+
+	// Assertions: the shortest name matching the whole query is the most relevant
+	assert.Equal(t, []int{2, 1}, exact)
+	assert.Equal(t, []int{2, 1}, typo)
+	assert.ElementsMatch(t, []int{2, 3}, category)
+	assert.Len(t, limited, 1)
+	assert.Equal(t, []int{1}, afterDelete)
+	assert.Empty(t, renamed)
+}
+
+func TestEditDistance(t *testing.T) {
+	// Assertions
+	assert.Equal(t, 0, editDistance("apple", "apple", 2))
+	assert.Equal(t, 1, editDistance("pineaple", "pineapple", 2))
+	assert.Equal(t, 3, editDistance("kitten", "sitting", 3))
+	assert.Equal(t, -1, editDistance("kitten", "sitting", 2))
+	assert.Equal(t, -1, editDistance("apple", "orange", 2))
+}
@@ -0,0 +1,54 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/JoseObreque/go-web/pkg/events"
+	"log"
+)
+
+var ErrUnavailable = errors.New("full-text search is not available")
+
+/*
+The Engine interface defines a full-text search backend of the products. The Search method returns
+the IDs of the products matching the query, most relevant first, tolerating typos in the query.
+Implementations must be safe for concurrent use.
+*/
+type Engine interface {
+	Index(ctx context.Context, product domain.Product) error
+	Delete(ctx context.Context, id int) error
+	Rebuild(ctx context.Context, products []domain.Product) error
+	Search(ctx context.Context, query string, limit int) ([]int, error)
+}
+
+// The Sizer interface is implemented by the engines that can tell the number of products they index.
+type Sizer interface {
+	Size() int
+}
+
+/*
+The Listen function keeps the engine updated with the product events published in the given bus.
+The restores and reloads rebuild the whole index with the products returned by the given function.
+Failed updates are logged, the index catches up on the next change of the product.
+*/
+func Listen(bus events.Bus, engine Engine, products func() []domain.Product) {
+	events.Listen(bus, "search", func(event events.Event) {
+		ctx := context.Background()
+		var err error
+		switch event.Type {
+		case product.EventRestored, product.EventReloaded:
+			err = engine.Rebuild(ctx, products())
+		case product.EventDeleted:
+			err = engine.Delete(ctx, event.Id)
+		default:
+			if p, ok := event.Data.(domain.Product); ok {
+				err = engine.Index(ctx, p)
+			}
+		}
+		if err != nil {
+			log.Printf("search: could not index the %s event of product %d: %s\n", event.Type, event.Id, err)
+		}
+	})
+}
@@ -0,0 +1,94 @@
+/*
+Package seed generates realistic fake products for load testing and local development. A generator
+built with the same seed and start date always generates the same products.
+*/
+package seed
+
+import (
+	"github.com/JoseObreque/go-web/internal/domain"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Word lists the product names are made of.
+var (
+	foods = map[string][]string{
+		"bakery":     {"Bread", "Bagels", "Croissant", "Muffin", "Baguette", "Tortillas"},
+		"beverages":  {"Coffee", "Tea", "Juice", "Water", "Wine", "Beer", "Soda"},
+		"dairy":      {"Milk", "Cheese", "Yogurt", "Butter", "Cream"},
+		"fruits":     {"Apple", "Pineapple", "Banana", "Mango", "Pear", "Grapes", "Lemon"},
+		"grocery":    {"Rice", "Pasta", "Flour", "Sugar", "Oil", "Salt", "Beans", "Lentils"},
+		"meat":       {"Beef", "Chicken", "Pork", "Lamb", "Turkey"},
+		"vegetables": {"Carrot", "Potato", "Tomato", "Onion", "Lettuce", "Pepper", "Spinach"},
+	}
+	varieties = []string{"Organic", "Canned", "Frozen", "Fresh", "Dried", "Sliced", "Whole", "Premium", "Light", "Extra"}
+	// Sorted categories, so the generated products do not depend on the map order
+	categories = []string{"bakery", "beverages", "dairy", "fruits", "grocery", "meat", "vegetables"}
+)
+
+// Characters of the generated code values.
+const codeAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// The Generator struct generates fake products. It is not safe for concurrent use.
+type Generator struct {
+	random *rand.Rand
+	from   time.Time
+	codes  map[string]bool
+}
+
+/*
+The NewGenerator function returns a generator of products with the given seed, whose expiration
+dates fall between 30 days and 2 years after the given date.
+*/
+func NewGenerator(seed int64, from time.Time) *Generator {
+	return &Generator{
+		random: rand.New(rand.NewSource(seed)),
+		from:   from,
+		codes:  map[string]bool{},
+	}
+}
+
+/*
+The Product method returns a new fake product, without an ID. Its code value is never repeated by
+the same generator.
+*/
+func (g *Generator) Product() domain.Product {
+	category := categories[g.random.Intn(len(categories))]
+	names := foods[category]
+	expiration := g.from.AddDate(0, 0, 30+g.random.Intn(700))
+
+	return domain.Product{
+		Name:       names[g.random.Intn(len(names))] + " - " + varieties[g.random.Intn(len(varieties))],
+		Quantity:   1 + g.random.Intn(500),
+		CodeValue:  g.code(),
+		Status:     domain.StatusOf(g.random.Intn(10) > 0),
+		Expiration: expiration.Format(domain.ExpirationLayout),
+		Price:      math.Round((0.5+g.random.Float64()*999.5)*100) / 100,
+		Category:   category,
+	}
+}
+
+// The Products method returns the given number of new fake products.
+func (g *Generator) Products(n int) []domain.Product {
+	products := make([]domain.Product, n)
+	for i := range products {
+		products[i] = g.Product()
+	}
+	return products
+}
+
+// Auxiliary method that returns a code value not generated before, like "K7320QX".
+func (g *Generator) code() string {
+	for {
+		code := make([]byte, 5+g.random.Intn(3))
+		code[0] = codeAlphabet[g.random.Intn(26)]
+		for i := 1; i < len(code); i++ {
+			code[i] = codeAlphabet[g.random.Intn(len(codeAlphabet))]
+		}
+		if !g.codes[string(code)] {
+			g.codes[string(code)] = true
+			return string(code)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package seed
+
+import (
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestGenerator_Products(t *testing.T) {
+	from := time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	products := NewGenerator(42, from).Products(200)
+	again := NewGenerator(42, from).Products(200)
+	other := NewGenerator(7, from).Products(200)
+
+	// The generated products are valid once they get an ID
+	for i := range products {
+		products[i].Id = i + 1
+	}
+	for i := range again {
+		again[i].Id = i + 1
+	}
+
+	// Assertions
+	assert.Len(t, products, 200)
+	assert.NoError(t, product.ValidateCatalog(products).Err())
+	assert.Equal(t, products, again)
+	assert.NotEqual(t, products[0], other[0])
+	for _, p := range products {
+		expiration, err := domain.ParseExpiration(p.Expiration)
+		assert.NoError(t, err)
+		assert.True(t, expiration.After(from))
+	}
+}
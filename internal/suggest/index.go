@@ -0,0 +1,251 @@
+package suggest
+
+import (
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/JoseObreque/go-web/pkg/events"
+	"github.com/JoseObreque/go-web/pkg/fold"
+	"sort"
+	"strings"
+	"sync"
+)
+
+/*
+The Suggestion struct is a product name suggested for a typed prefix.
+
+	Name (string): Product name. Example: "Milk".
+	Products (int): Number of products with this name. Example: 2.
+	Views (int): Number of times the products with this name were viewed. Example: 40.
+*/
+type Suggestion struct {
+	Name     string `json:"name" example:"Milk"`
+	Products int    `json:"products" example:"2"`
+	Views    int    `json:"views" example:"40"`
+}
+
+// The name struct is an indexed product name, with the number of products it has.
+type name struct {
+	display  string
+	products int
+}
+
+// The key struct is an entry of the sorted index: a word of a name and the rest of the name after it.
+type key struct {
+	text string
+	name string
+	// Whether the key is the whole name, so the matches are prefixes of the name
+	start bool
+}
+
+/*
+The Index struct keeps the names of the published products in a sorted index of their words, so the
+names having a word that starts with a prefix are found with a binary search. It is updated on every
+product event, and the names are compared ignoring case and accents. It is safe for concurrent use.
+*/
+type Index struct {
+	mu    sync.RWMutex
+	keys  []key
+	names map[string]*name
+	// Views of the names, kept while they are not indexed so they survive unpublishing and edits
+	views map[string]int
+}
+
+// The NewIndex function returns an index of the names of the given published products.
+func NewIndex(products []domain.Product) *Index {
+	index := &Index{
+		views: make(map[string]int),
+	}
+	index.Rebuild(products)
+	return index
+}
+
+// The Rebuild method replaces the indexed names with those of the given published products, keeping their views.
+func (i *Index) Rebuild(products []domain.Product) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.keys = nil
+	i.names = make(map[string]*name)
+	for _, p := range products {
+		if p.Published() {
+			i.add(p.Name)
+		}
+	}
+	sort.Slice(i.keys, func(a, b int) bool { return less(i.keys[a], i.keys[b]) })
+}
+
+/*
+The Listen method keeps the index updated with the product events published in the given bus. The
+restores and reloads rebuild the whole index with the products returned by the given function.
+*/
+func (i *Index) Listen(bus events.Bus, products func() []domain.Product) {
+	events.Listen(bus, "suggest", func(event events.Event) {
+		if event.Type == product.EventRestored || event.Type == product.EventReloaded {
+			i.Rebuild(products())
+			return
+		}
+
+		// The previous state of the product is unindexed, and its new state indexed
+		if previous, ok := event.Previous.(domain.Product); ok && previous.Published() {
+			i.Remove(previous.Name)
+		}
+		p, ok := event.Data.(domain.Product)
+		if !ok || !p.Published() {
+			return
+		}
+		if event.Type == product.EventDeleted {
+			i.Remove(p.Name)
+		} else {
+			i.Add(p.Name)
+		}
+	})
+}
+
+// The Size method returns the number of entries of the index, one per word of every indexed name.
+func (i *Index) Size() int {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	return len(i.keys)
+}
+
+// The Add method indexes one more product with the given name.
+func (i *Index) Add(productName string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	start := len(i.keys)
+	if !i.add(productName) {
+		return
+	}
+	// Only the keys of a new name were appended, merge them into the sorted keys
+	added := i.keys[start:]
+	sort.Slice(added, func(a, b int) bool { return less(added[a], added[b]) })
+	merged := make([]key, 0, len(i.keys))
+	old := i.keys[:start]
+	for len(old) > 0 || len(added) > 0 {
+		if len(added) == 0 || (len(old) > 0 && !less(added[0], old[0])) {
+			merged, old = append(merged, old[0]), old[1:]
+		} else {
+			merged, added = append(merged, added[0]), added[1:]
+		}
+	}
+	i.keys = merged
+}
+
+// The Remove method unindexes a product with the given name, and the name if no other product has it.
+func (i *Index) Remove(productName string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	normalized := fold.String(productName)
+	n, ok := i.names[normalized]
+	if !ok {
+		return
+	}
+	if n.products--; n.products > 0 {
+		return
+	}
+
+	delete(i.names, normalized)
+	keys := i.keys[:0]
+	for _, k := range i.keys {
+		if k.name != normalized {
+			keys = append(keys, k)
+		}
+	}
+	i.keys = keys
+}
+
+// The View method records a view of a product with the given name, which makes the name more popular.
+func (i *Index) View(productName string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	normalized := fold.String(productName)
+	if _, ok := i.names[normalized]; ok {
+		i.views[normalized]++
+	}
+}
+
+/*
+The Suggest method returns up to limit names with a word starting with the given prefix. The names
+starting with the prefix come first, then the most viewed ones and those of more products.
+*/
+func (i *Index) Suggest(prefix string, limit int) []Suggestion {
+	prefix = fold.String(prefix)
+	suggestions := []Suggestion{}
+	if prefix == "" || limit <= 0 {
+		return suggestions
+	}
+
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	// The keys starting with the prefix are contiguous in the sorted index
+	first := sort.Search(len(i.keys), func(k int) bool { return i.keys[k].text >= prefix })
+	starts := make(map[string]bool)
+	for _, k := range i.keys[first:] {
+		if !strings.HasPrefix(k.text, prefix) {
+			break
+		}
+		starts[k.name] = starts[k.name] || k.start
+	}
+
+	matches := make([]string, 0, len(starts))
+	for normalized := range starts {
+		matches = append(matches, normalized)
+	}
+	sort.Slice(matches, func(a, b int) bool {
+		x, y := matches[a], matches[b]
+		switch {
+		case starts[x] != starts[y]:
+			return starts[x]
+		case i.views[x] != i.views[y]:
+			return i.views[x] > i.views[y]
+		case i.names[x].products != i.names[y].products:
+			return i.names[x].products > i.names[y].products
+		default:
+			return matches[a] < matches[b]
+		}
+	})
+
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	for _, normalized := range matches {
+		n := i.names[normalized]
+		suggestions = append(suggestions, Suggestion{Name: n.display, Products: n.products, Views: i.views[normalized]})
+	}
+	return suggestions
+}
+
+/*
+Auxiliary method that counts one more product with the given name, appending the keys of the name
+if it is new. It reports whether keys were appended, which leaves the keys unsorted.
+*/
+func (i *Index) add(productName string) bool {
+	normalized := fold.String(productName)
+	if normalized == "" {
+		return false
+	}
+	if n, ok := i.names[normalized]; ok {
+		n.products++
+		return false
+	}
+
+	i.names[normalized] = &name{display: strings.TrimSpace(productName), products: 1}
+	words := strings.Split(normalized, " ")
+	for w := range words {
+		i.keys = append(i.keys, key{text: strings.Join(words[w:], " "), name: normalized, start: w == 0})
+	}
+	return true
+}
+
+// Auxiliary function that orders the keys by text, then by name.
+func less(a, b key) bool {
+	if a.text != b.text {
+		return a.text < b.text
+	}
+	return a.name < b.name
+}
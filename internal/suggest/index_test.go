@@ -0,0 +1,67 @@
+package suggest
+
+import (
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/JoseObreque/go-web/pkg/events"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestIndex_Suggest(t *testing.T) {
+	index := NewIndex([]domain.Product{
+		{Id: 1, Name: "Milk", Status: domain.StatusPublished},
+		{Id: 2, Name: "Soy milk", Status: domain.StatusPublished},
+		{Id: 3, Name: "Milanesa", Status: domain.StatusPublished},
+		{Id: 4, Name: "milk ", Status: domain.StatusPublished},
+		{Id: 5, Name: "Millet", Status: domain.StatusDraft},
+		{Id: 6, Name: "Café molido", Status: domain.StatusPublished},
+	})
+	index.View("Milanesa")
+
+	suggestions := index.Suggest("MIL", 10)
+	limited := index.Suggest("mil", 1)
+	accented := index.Suggest("cafe", 10)
+	index.Remove("Milk")
+	afterRemove := index.Suggest("milk", 10)
+	index.Remove("Milk")
+	index.Add("Milkshake")
+
+	// Assertions: prefixes of the name first, then the most viewed and those of more products
+	assert.Equal(t, []Suggestion{
+		{Name: "Milanesa", Products: 1, Views: 1},
+		{Name: "Milk", Products: 2},
+		{Name: "Soy milk", Products: 1},
+	}, suggestions)
+	assert.Equal(t, suggestions[:1], limited)
+	assert.Equal(t, []Suggestion{{Name: "Café molido", Products: 1}}, accented)
+	assert.Equal(t, 1, afterRemove[0].Products)
+	assert.Equal(t, []Suggestion{{Name: "Milkshake", Products: 1}, {Name: "Soy milk", Products: 1}}, index.Suggest("milk", 10))
+	assert.Empty(t, index.Suggest(" ", 10))
+}
+
+func TestIndex_Listen(t *testing.T) {
+	bus := events.NewBus()
+	service := product.NewService(product.NewRepository(nil), bus)
+	index := NewIndex(nil)
+	index.Listen(bus, service.GetAll)
+
+	created, err := service.Create(domain.Product{Name: "Pineapple", CodeValue: "P1", Expiration: "15/12/2030", Price: 10, Status: domain.StatusPublished})
+	if err != nil {
+		panic(err)
+	}
+	assert.Eventually(t, func() bool { return len(index.Suggest("pine", 10)) == 1 }, time.Second, 10*time.Millisecond)
+
+	renamed := "Apple"
+	if _, err = service.Patch(created.Id, domain.ProductRequest{Name: &renamed}); err != nil {
+		panic(err)
+	}
+
+	// Assertions
+	assert.Eventually(t, func() bool {
+		return len(index.Suggest("pine", 10)) == 0 && len(index.Suggest("app", 10)) == 1
+	}, time.Second, 10*time.Millisecond)
+	service.Restore(nil)
+	assert.Eventually(t, func() bool { return len(index.Suggest("app", 10)) == 0 }, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,165 @@
+/*
+Package tenant isolates the catalogs of the stores sharing one deployment. Every tenant has its own
+products, opened on its first request, and the requests name their tenant with a tenant API key or
+the X-Tenant header. The requests without a tenant keep using the main catalog.
+*/
+package tenant
+
+import (
+	"errors"
+	"fmt"
+	"github.com/JoseObreque/go-web/internal/product"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrInvalidTenants = errors.New("invalid tenants, expected ids of lowercase letters, digits and dashes")
+	ErrInvalidKeys    = errors.New("invalid tenant keys, expected key:tenant pairs")
+	ErrUnknownTenant  = errors.New("unknown tenant")
+	ErrForbidden      = errors.New("the credential does not give access to the tenant")
+	ErrUnsupported    = errors.New("the endpoint only serves the main catalog")
+)
+
+// Ids of the tenants, which name their store files.
+var idPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+// The Catalog struct is the product service of a tenant and the function saving its changes in its store.
+type Catalog struct {
+	Service product.Service
+	Flush   func() error
+}
+
+// The Opener type opens the catalog of a tenant.
+type Opener func(id string) (Catalog, error)
+
+/*
+The Registry struct keeps the catalogs of the known tenants, opened on their first request, and
+the tenant API keys. The tenants of the keys are known too.
+*/
+type Registry struct {
+	mu       sync.Mutex
+	tenants  map[string]bool
+	keys     map[string]string
+	catalogs map[string]Catalog
+	open     Opener
+}
+
+// The NewRegistry function returns a registry of the given tenants and keys, whose catalogs are opened with open.
+func NewRegistry(tenants []string, keys map[string]string, open Opener) (*Registry, error) {
+	known := make(map[string]bool)
+	for _, id := range tenants {
+		known[id] = true
+	}
+	for _, id := range keys {
+		known[id] = true
+	}
+	for id := range known {
+		if !idPattern.MatchString(id) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidTenants, id)
+		}
+	}
+
+	return &Registry{
+		tenants:  known,
+		keys:     keys,
+		catalogs: make(map[string]Catalog),
+		open:     open,
+	}, nil
+}
+
+/*
+The ParseKeys function parses a comma separated list of "key:tenant" pairs, giving every key access
+to the catalog of its tenant.
+*/
+func ParseKeys(list string) (map[string]string, error) {
+	keys := make(map[string]string)
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		separator := strings.LastIndex(item, ":")
+		if separator <= 0 || separator == len(item)-1 {
+			return nil, ErrInvalidKeys
+		}
+		keys[item[:separator]] = item[separator+1:]
+	}
+	return keys, nil
+}
+
+// The Tenants method returns the ids of the known tenants, sorted.
+func (r *Registry) Tenants() []string {
+	ids := make([]string, 0, len(r.tenants))
+	for id := range r.tenants {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+/*
+The Resolve method returns the tenant of a request, from its API key and its X-Tenant header, and
+whether the key is a tenant key. A tenant key only gives access to its tenant, and the header of the
+requests without one is only trusted if there are no tenant keys, or with the main token. An empty
+tenant is the main catalog.
+*/
+func (r *Registry) Resolve(key string, header string, trusted bool) (string, bool, error) {
+	if id, ok := r.keys[key]; ok && key != "" {
+		if header != "" && header != id {
+			return "", false, ErrForbidden
+		}
+		return id, true, nil
+	}
+
+	if header == "" {
+		return "", false, nil
+	}
+	if len(r.keys) > 0 && !trusted {
+		return "", false, ErrForbidden
+	}
+	if !r.tenants[header] {
+		return "", false, fmt.Errorf("%w: %s", ErrUnknownTenant, header)
+	}
+	return header, false, nil
+}
+
+// The Service method returns the product service of a known tenant, opening its catalog on the first call.
+func (r *Registry) Service(id string) (product.Service, error) {
+	if !r.tenants[id] {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTenant, id)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	catalog, ok := r.catalogs[id]
+	if !ok {
+		var err error
+		if catalog, err = r.open(id); err != nil {
+			return nil, fmt.Errorf("opening the catalog of tenant %s: %w", id, err)
+		}
+		r.catalogs[id] = catalog
+	}
+	return catalog.Service, nil
+}
+
+// The Flush method saves the changes of every opened catalog in its store.
+func (r *Registry) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var errs []error
+	for id, catalog := range r.catalogs {
+		if catalog.Flush == nil {
+			continue
+		}
+		if err := catalog.Flush(); err != nil {
+			errs = append(errs, fmt.Errorf("tenant %s: %w", id, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,49 @@
+package tenant
+
+import (
+	"errors"
+	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseKeys(t *testing.T) {
+	keys, err := ParseKeys(" acme-key:acme, globex:key:globex ,")
+	_, errMissingTenant := ParseKeys("acme-key:")
+	_, errMissingKey := ParseKeys(":acme")
+
+	// Assertions
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"acme-key": "acme", "globex:key": "globex"}, keys)
+	assert.ErrorIs(t, errMissingTenant, ErrInvalidKeys)
+	assert.ErrorIs(t, errMissingKey, ErrInvalidKeys)
+}
+
+func TestRegistry_Service(t *testing.T) {
+	errFlush := errors.New("disk full")
+	opened := 0
+	registry, err := NewRegistry([]string{"globex"}, map[string]string{"acme-key": "acme"}, func(id string) (Catalog, error) {
+		opened++
+		return Catalog{
+			Service: product.NewService(product.NewRepository(nil), nil),
+			Flush:   func() error { return errFlush },
+		}, nil
+	})
+	if err != nil {
+		panic(err)
+	}
+	_, errInvalid := NewRegistry([]string{"../etc"}, nil, nil)
+
+	first, errFirst := registry.Service("acme")
+	second, _ := registry.Service("acme")
+	_, errUnknown := registry.Service("initech")
+
+	// Assertions
+	assert.ErrorIs(t, errInvalid, ErrInvalidTenants)
+	assert.Equal(t, []string{"acme", "globex"}, registry.Tenants())
+	assert.NoError(t, errFirst)
+	assert.Same(t, first, second)
+	assert.Equal(t, 1, opened)
+	assert.ErrorIs(t, errUnknown, ErrUnknownTenant)
+	assert.ErrorIs(t, registry.Flush(), errFlush)
+}
@@ -0,0 +1,222 @@
+/*
+Package undo keeps the most recent product changes of every credential, recorded by the product
+service as part of every change, so the last one can be reverted as a safety net for mistaken changes.
+*/
+package undo
+
+import (
+	"errors"
+	"fmt"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/JoseObreque/go-web/pkg/events"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Prefix of the actor of the reverts, whose changes are not recorded so an undo is never undone.
+const actorPrefix = "undo:"
+
+var (
+	ErrNothingToUndo = errors.New("nothing to undo")
+	ErrChanged       = errors.New("the product changed after the operation")
+)
+
+/*
+The Operation struct is a recorded product change: a creation, an update or a deletion. Product is
+the state of the product after the change, and Previous the state before it; the created products
+have no previous state and the deleted ones no state after the change.
+*/
+type Operation struct {
+	Type      string          `json:"type"`
+	ProductId int             `json:"product_id"`
+	Product   *domain.Product `json:"product,omitempty"`
+	Previous  *domain.Product `json:"previous,omitempty"`
+	Actor     string          `json:"actor"`
+	Timestamp time.Time       `json:"timestamp"`
+}
+
+// The Result struct is a reverted operation and the state of the product after the revert, nil if it was deleted.
+type Result struct {
+	Operation Operation       `json:"operation"`
+	Product   *domain.Product `json:"product,omitempty"`
+}
+
+/*
+The Log struct keeps the most recent operations made by the actors, and reverts them through the
+product service. Only the changes with an actor are recorded, so the changes made by the system
+itself, like the unpublication of the expired products, cannot be undone. A restore or a reload
+of the products forgets every operation.
+The operations are reverted by credential: the name of an actor after its credential, like alice
+in "token/alice", comes from the X-Actor header of the client and is not trusted, so it only tells
+who made an operation and anyone with the same credential can revert it.
+*/
+type Log struct {
+	mu            sync.Mutex
+	operations    []Operation
+	maxOperations int
+	service       product.Service
+}
+
+// The NewLog function returns an empty log keeping up to maxOperations operations of all the actors.
+func NewLog(service product.Service, maxOperations int) *Log {
+	return &Log{
+		maxOperations: maxOperations,
+		service:       service,
+	}
+}
+
+/*
+The Record method stores a product event as an operation, if it is a change made by an actor. It is
+meant to be set with the WithRecorder method of the product service, so no change is missed even if
+the subscribers of the event bus fall behind, and never fails.
+*/
+func (l *Log) Record(event events.Event) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if event.Type == product.EventRestored || event.Type == product.EventReloaded {
+		l.operations = nil
+		return nil
+	}
+	if event.Actor == "" || strings.HasPrefix(event.Actor, actorPrefix) {
+		return nil
+	}
+
+	operation := Operation{Type: event.Type, ProductId: event.Id, Actor: event.Actor, Timestamp: event.Timestamp}
+	current, _ := event.Data.(domain.Product)
+	previous, _ := event.Previous.(domain.Product)
+	switch event.Type {
+	case product.EventCreated:
+		operation.Product = &current
+	case product.EventUpdated:
+		operation.Product, operation.Previous = &current, &previous
+	case product.EventDeleted:
+		operation.Previous = &current
+	default:
+		return nil
+	}
+
+	l.operations = append(l.operations, operation)
+	if l.maxOperations > 0 && len(l.operations) > l.maxOperations {
+		l.operations = l.operations[len(l.operations)-l.maxOperations:]
+	}
+	return nil
+}
+
+/*
+The Undo method reverts the most recent operation made with the credential of the actor, whatever
+its name: the created product is deleted, the updated one gets its previous state back, and the
+deleted one is created again, with a new ID since the IDs are never reused, that the earlier
+operations of the product refer to from then on. If the product changed after the operation, it
+cannot be reverted anymore and the operation is forgotten with ErrChanged.
+*/
+func (l *Log) Undo(actor string) (Result, error) {
+	operation, ok := l.pop(actor)
+	if !ok {
+		return Result{}, ErrNothingToUndo
+	}
+
+	result := Result{Operation: operation}
+	service := l.service.As(actorPrefix + actor)
+	switch operation.Type {
+	case product.EventCreated:
+		if err := l.unchanged(operation); err != nil {
+			return Result{}, err
+		}
+		if err := service.Delete(operation.ProductId); err != nil {
+			return Result{}, l.failed(operation, err)
+		}
+	case product.EventUpdated:
+		if err := l.unchanged(operation); err != nil {
+			return Result{}, err
+		}
+		restored, err := service.Update(operation.ProductId, *operation.Previous)
+		if err != nil {
+			return Result{}, l.failed(operation, err)
+		}
+		result.Product = &restored
+	default:
+		deleted := *operation.Previous
+		deleted.Id = 0
+		created, err := service.Create(deleted)
+		if err != nil {
+			return Result{}, l.failed(operation, err)
+		}
+		result.Product = &created
+		l.move(operation.ProductId, created.Id)
+	}
+	return result, nil
+}
+
+// Auxiliary method that removes and returns the most recent operation made with the credential of the actor.
+func (l *Log) pop(actor string) (Operation, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for i := len(l.operations) - 1; i >= 0; i-- {
+		if credential(l.operations[i].Actor) == credential(actor) {
+			operation := l.operations[i]
+			l.operations = append(l.operations[:i], l.operations[i+1:]...)
+			return operation, true
+		}
+	}
+	return Operation{}, false
+}
+
+// Auxiliary function that returns the credential of an actor, like token for "token/alice".
+func credential(actor string) string {
+	credential, _, _ := strings.Cut(actor, "/")
+	return credential
+}
+
+// Auxiliary method that moves the operations of a deleted product to the product created again in its place.
+func (l *Log) move(id int, newId int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for i := range l.operations {
+		operation := &l.operations[i]
+		if operation.ProductId != id {
+			continue
+		}
+		operation.ProductId = newId
+		for _, state := range []**domain.Product{&operation.Product, &operation.Previous} {
+			if *state != nil {
+				moved := **state
+				moved.Id = newId
+				*state = &moved
+			}
+		}
+	}
+}
+
+// Auxiliary method that checks if the product is still in the state left by the operation.
+func (l *Log) unchanged(operation Operation) error {
+	current, err := l.service.GetById(operation.ProductId)
+	if errors.Is(err, product.ErrNotFound) || (err == nil && !current.Equal(*operation.Product)) {
+		return fmt.Errorf("%w: product %d", ErrChanged, operation.ProductId)
+	}
+	if err != nil {
+		return l.failed(operation, err)
+	}
+	return nil
+}
+
+/*
+Auxiliary method that puts back an operation whose revert failed for a reason other than a change
+of the product, like a code value taken since a deletion, so it can be retried once solved.
+*/
+func (l *Log) failed(operation Operation, err error) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// The operation goes back to its place among the ones of the other actors
+	i := len(l.operations)
+	for i > 0 && l.operations[i-1].Timestamp.After(operation.Timestamp) {
+		i--
+	}
+	l.operations = append(l.operations[:i], append([]Operation{operation}, l.operations[i:]...)...)
+	return err
+}
@@ -0,0 +1,81 @@
+package undo
+
+import (
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// Auxiliary function that returns a log of the changes made to a service with a single product.
+func newTestLog() (*Log, product.Service) {
+	service := product.NewService(product.NewRepository([]domain.Product{
+		{Id: 1, Name: "Oil", Quantity: 10, CodeValue: "A1", Status: domain.StatusPublished, Expiration: "15/12/2030", Price: 71.42},
+	}), nil).(*product.ServiceImpl)
+	log := NewLog(service, 10)
+	service.WithRecorder(log.Record)
+	return log, service
+}
+
+func TestLog_Undo(t *testing.T) {
+	log, service := newTestLog()
+	alice := service.As("token/alice")
+
+	created, err := alice.Create(domain.Product{Name: "Rice", Quantity: 4, CodeValue: "A2", Expiration: "2030-12-20", Price: 9})
+	if err != nil {
+		panic(err)
+	}
+	if _, err = alice.Update(1, domain.Product{Name: "Olive oil", Quantity: 4, CodeValue: "A1", Expiration: "2030-12-20", Price: 90}); err != nil {
+		panic(err)
+	}
+	if err = alice.Delete(1); err != nil {
+		panic(err)
+	}
+
+	undoDelete, errDelete := log.Undo("token/alice")
+	undoUpdate, errUpdate := log.Undo("token/alice")
+	undoCreate, errCreate := log.Undo("token/alice")
+	_, errNothing := log.Undo("token/alice")
+	_, errCreated := service.GetById(created.Id)
+
+	// Assertions
+	assert.NoError(t, errDelete)
+	assert.Equal(t, product.EventDeleted, undoDelete.Operation.Type)
+	assert.Equal(t, "Olive oil", undoDelete.Product.Name)
+	assert.NoError(t, errUpdate)
+	assert.Equal(t, product.EventUpdated, undoUpdate.Operation.Type)
+	assert.Equal(t, "Oil", undoUpdate.Product.Name)
+	assert.Equal(t, 71.42, undoUpdate.Product.Price)
+	assert.NoError(t, errCreate)
+	assert.Equal(t, product.EventCreated, undoCreate.Operation.Type)
+	assert.Nil(t, undoCreate.Product)
+	assert.ErrorIs(t, errCreated, product.ErrNotFound)
+	assert.ErrorIs(t, errNothing, ErrNothingToUndo)
+}
+
+func TestLog_Undo_Credentials(t *testing.T) {
+	log, service := newTestLog()
+
+	if _, err := service.As("token/alice").Update(1, domain.Product{Name: "Olive oil", Quantity: 4, CodeValue: "A1", Expiration: "2030-12-20", Price: 90}); err != nil {
+		panic(err)
+	}
+	if _, err := service.Create(domain.Product{Name: "Rice", Quantity: 4, CodeValue: "A2", Expiration: "2030-12-20", Price: 9}); err != nil {
+		panic(err)
+	}
+	if _, err := service.As("signature").Update(1, domain.Product{Name: "Corn oil", Quantity: 4, CodeValue: "A1", Expiration: "2030-12-20", Price: 90}); err != nil {
+		panic(err)
+	}
+
+	// The operations of a credential are the ones of all its actors, whose names are not trusted
+	_, errSystem := log.Undo("")
+	_, errChanged := log.Undo("token/bob")
+	_, errChangedAgain := log.Undo("token/alice")
+	result, errSignature := log.Undo("signature")
+
+	// Assertions
+	assert.ErrorIs(t, errSystem, ErrNothingToUndo)
+	assert.ErrorIs(t, errChanged, ErrChanged)
+	assert.ErrorIs(t, errChangedAgain, ErrNothingToUndo)
+	assert.NoError(t, errSignature)
+	assert.Equal(t, "Olive oil", result.Product.Name)
+}
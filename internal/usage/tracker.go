@@ -0,0 +1,243 @@
+package usage
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrInvalidQuotas = errors.New("invalid quotas, expected key:limit pairs")
+
+/*
+Reserved keys of the quotas that are not API keys: Signature meters every request signed with the
+shared secret, and Anonymous the requests without a known credential, each client address on its
+own.
+*/
+const (
+	Signature = "signature"
+	Anonymous = "anonymous"
+)
+
+/*
+The Usage struct reports the requests made with an API key during the current day. A zero Quota
+means the key has no daily limit, in which case Remaining is nil.
+*/
+type Usage struct {
+	Key       string `json:"key"`
+	Date      string `json:"date"`
+	Requests  int    `json:"requests"`
+	Quota     int    `json:"quota"`
+	Remaining *int   `json:"remaining,omitempty"`
+}
+
+/*
+The Tracker struct counts the requests made with every known API key and enforces their daily
+quotas. Days are measured in UTC and the counters restart at midnight. Unknown keys are not
+tracked, so random tokens cannot make the counters grow without bound. The anonymous requests are
+only counted with an Anonymous quota, per client address, so at most one counter per address and
+day.
+*/
+type Tracker struct {
+	mu      sync.Mutex
+	quotas  map[string]int
+	counts  map[string]int
+	day     string
+	nowFunc func() time.Time
+}
+
+// The NewTracker function returns a Tracker for the given keys and their daily quotas.
+func NewTracker(quotas map[string]int) *Tracker {
+	return &Tracker{
+		quotas:  quotas,
+		counts:  make(map[string]int),
+		nowFunc: time.Now,
+	}
+}
+
+/*
+The ParseQuotas function parses a comma separated list of "key:limit" pairs, where a zero limit
+means unlimited.
+*/
+func ParseQuotas(list string) (map[string]int, error) {
+	quotas := make(map[string]int)
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		separator := strings.LastIndex(item, ":")
+		if separator <= 0 {
+			return nil, ErrInvalidQuotas
+		}
+		limit, err := strconv.Atoi(item[separator+1:])
+		if err != nil || limit < 0 {
+			return nil, ErrInvalidQuotas
+		}
+		quotas[item[:separator]] = limit
+	}
+	return quotas, nil
+}
+
+/*
+The Allow method records a request made with the given key and reports whether it is within the
+daily quota, together with the usage of the key. Requests with unknown keys are always allowed
+and not recorded.
+*/
+func (t *Tracker) Allow(key string) (Usage, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	quota, known := t.quotas[key]
+	if !known {
+		return Usage{}, true
+	}
+
+	t.rollover()
+	if quota > 0 && t.counts[key] >= quota {
+		return t.usage(key), false
+	}
+
+	t.counts[key]++
+	return t.usage(key), true
+}
+
+// The Known method reports whether the key has a quota, which excludes the reserved keys.
+func (t *Tracker) Known(key string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	_, known := t.quotas[key]
+	return known && key != Signature && key != Anonymous
+}
+
+/*
+The AllowAnonymous method records a request without a known credential made from the given client
+address and reports whether it is within the Anonymous quota, which applies to every address on its
+own. Without an Anonymous quota the anonymous requests are always allowed and not recorded.
+*/
+func (t *Tracker) AllowAnonymous(client string) (Usage, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	quota, known := t.quotas[Anonymous]
+	if !known {
+		return Usage{}, true
+	}
+
+	t.rollover()
+	key := anonymousKey(client)
+	usage := t.usage(key)
+	usage.Quota = quota
+	if quota > 0 && t.counts[key] >= quota {
+		remaining := 0
+		usage.Remaining = &remaining
+		return usage, false
+	}
+
+	t.counts[key]++
+	usage.Requests++
+	if quota > 0 {
+		remaining := quota - usage.Requests
+		usage.Remaining = &remaining
+	}
+	return usage, true
+}
+
+/*
+The SetQuotas method replaces the known keys and their daily quotas, for example after a reload of
+the configuration. The requests already counted today are kept for the keys still known.
+*/
+func (t *Tracker) SetQuotas(quotas map[string]int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.quotas = quotas
+	_, anonymous := quotas[Anonymous]
+	for key := range t.counts {
+		if _, known := quotas[key]; !known && !(anonymous && strings.HasPrefix(key, anonymousKey(""))) {
+			delete(t.counts, key)
+		}
+	}
+}
+
+/*
+The Usage method returns the usage of every known key, sorted by key and with the API keys masked.
+The usage of the Anonymous key adds up the requests of every client address.
+*/
+func (t *Tracker) Usage() []Usage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.rollover()
+	keys := make([]string, 0, len(t.quotas))
+	for key := range t.quotas {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	usages := make([]Usage, len(keys))
+	for i, key := range keys {
+		usages[i] = t.usage(key)
+		switch key {
+		case Anonymous:
+			usages[i].Remaining = nil
+			for counted, requests := range t.counts {
+				if strings.HasPrefix(counted, anonymousKey("")) {
+					usages[i].Requests += requests
+				}
+			}
+		case Signature:
+		default:
+			usages[i].Key = mask(key)
+		}
+	}
+	return usages
+}
+
+// The ResetAt method returns when the current quotas period ends.
+func (t *Tracker) ResetAt() time.Time {
+	now := t.nowFunc().UTC()
+	return time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+}
+
+// Auxiliary method that restarts the counters when the day changes. It must hold the lock.
+func (t *Tracker) rollover() {
+	today := t.nowFunc().UTC().Format("2006-01-02")
+	if today != t.day {
+		t.day = today
+		t.counts = make(map[string]int)
+	}
+}
+
+// Auxiliary method that returns the usage of a key. It must hold the lock.
+func (t *Tracker) usage(key string) Usage {
+	usage := Usage{
+		Key:      key,
+		Date:     t.day,
+		Requests: t.counts[key],
+		Quota:    t.quotas[key],
+	}
+	if usage.Quota > 0 {
+		remaining := usage.Quota - usage.Requests
+		usage.Remaining = &remaining
+	}
+	return usage
+}
+
+// Auxiliary function that returns the key of the counter of the anonymous requests of a client address.
+func anonymousKey(client string) string {
+	return Anonymous + "/" + client
+}
+
+// Auxiliary function that hides all but the last four characters of a key.
+func mask(key string) string {
+	if len(key) <= 4 {
+		return strings.Repeat("*", len(key))
+	}
+	return fmt.Sprintf("%s%s", strings.Repeat("*", len(key)-4), key[len(key)-4:])
+}
@@ -0,0 +1,66 @@
+package usage
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestTracker_Allow(t *testing.T) {
+	quotas, err := ParseQuotas("partner-one:2, admin-token:0")
+	if err != nil {
+		panic(err)
+	}
+	now := time.Date(2030, time.May, 10, 23, 0, 0, 0, time.UTC)
+	tracker := NewTracker(quotas)
+	tracker.nowFunc = func() time.Time { return now }
+
+	_, first := tracker.Allow("partner-one")
+	_, second := tracker.Allow("partner-one")
+	exceeded, third := tracker.Allow("partner-one")
+	_, unlimited := tracker.Allow("admin-token")
+	_, unknown := tracker.Allow("random")
+
+	// Assertions
+	assert.True(t, first)
+	assert.True(t, second)
+	assert.False(t, third)
+	assert.Equal(t, 0, *exceeded.Remaining)
+	assert.True(t, unlimited)
+	assert.True(t, unknown)
+	assert.Len(t, tracker.Usage(), 2)
+	assert.Equal(t, "*******oken", tracker.Usage()[0].Key)
+	assert.Equal(t, time.Date(2030, time.May, 11, 0, 0, 0, 0, time.UTC), tracker.ResetAt())
+
+	// The counters restart the next day
+	now = now.Add(2 * time.Hour)
+	_, nextDay := tracker.Allow("partner-one")
+	assert.True(t, nextDay)
+
+	_, err = ParseQuotas("partner-one")
+	assert.ErrorIs(t, err, ErrInvalidQuotas)
+}
+
+func TestTracker_AllowAnonymous(t *testing.T) {
+	unmetered := NewTracker(map[string]int{"partner-one": 2})
+	tracker := NewTracker(map[string]int{"partner-one": 2, Anonymous: 1})
+
+	_, withoutQuota := unmetered.AllowAnonymous("10.0.0.1")
+	_, first := tracker.AllowAnonymous("10.0.0.1")
+	exceeded, second := tracker.AllowAnonymous("10.0.0.1")
+	_, otherClient := tracker.AllowAnonymous("10.0.0.2")
+
+	// Assertions
+	assert.True(t, withoutQuota)
+	assert.Empty(t, unmetered.Usage()[0].Requests)
+	assert.True(t, first)
+	assert.False(t, second)
+	assert.Equal(t, 0, *exceeded.Remaining)
+	assert.True(t, otherClient)
+	assert.False(t, tracker.Known(Anonymous))
+	assert.Equal(t, Usage{Key: Anonymous, Date: exceeded.Date, Requests: 2, Quota: 1}, tracker.Usage()[0])
+
+	// The counters of the clients are dropped along with the anonymous quota
+	tracker.SetQuotas(map[string]int{"partner-one": 2})
+	assert.Len(t, tracker.counts, 0)
+}
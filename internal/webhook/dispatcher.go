@@ -0,0 +1,242 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/JoseObreque/go-web/pkg/events"
+	"github.com/JoseObreque/go-web/pkg/logging"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Headers sent with every notification.
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// The Delivery struct represents a notification pending to be delivered to a webhook, 0 for the other URLs.
+type Delivery struct {
+	Id          string    `json:"id"`
+	WebhookId   int       `json:"webhook_id"`
+	URL         string    `json:"url"`
+	EventType   string    `json:"event_type"`
+	Attempts    int       `json:"attempts"`
+	NextAttempt time.Time `json:"next_attempt"`
+	LastError   string    `json:"last_error,omitempty"`
+	secret      string
+	payload     []byte
+}
+
+/*
+The Dispatcher struct delivers the events published in the bus to the registered webhooks. Every
+notification is a POST with the JSON event as body, signed with the webhook secret. Failed
+deliveries are retried with exponential backoff until maxAttempts is reached.
+*/
+type Dispatcher struct {
+	service     Service
+	client      *http.Client
+	maxAttempts int
+	baseDelay   time.Duration
+
+	mu      sync.Mutex
+	pending []*Delivery
+	counter int
+}
+
+/*
+The NewDispatcher function returns a new Dispatcher. The delay before the n-th retry of a
+delivery is baseDelay * 2^(n-1).
+*/
+func NewDispatcher(service Service, client *http.Client, maxAttempts int, baseDelay time.Duration) *Dispatcher {
+	return &Dispatcher{
+		service:     service,
+		client:      client,
+		maxAttempts: maxAttempts,
+		baseDelay:   baseDelay,
+	}
+}
+
+/*
+The Run method subscribes the dispatcher to the bus and notifies the webhooks of every received
+event until the subscription is closed. It is meant to be run in its own goroutine.
+*/
+func (d *Dispatcher) Run(bus events.Bus) {
+	subscription, unsubscribe := bus.Subscribe()
+	defer unsubscribe()
+
+	for event := range subscription {
+		d.Dispatch(event)
+	}
+}
+
+/*
+The RunRetries method retries the due deliveries every interval until the stop channel is
+closed. It is meant to be run in its own goroutine.
+*/
+func (d *Dispatcher) RunRetries(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			d.RetryDue()
+		}
+	}
+}
+
+// The Dispatch method notifies every webhook subscribed to the event type.
+func (d *Dispatcher) Dispatch(event events.Event) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("webhook: could not encode event %s: %s\n", event.Type, err)
+		return
+	}
+
+	for _, webhook := range d.service.GetAll() {
+		if webhook.Subscribed(event.Type) {
+			d.deliver(webhook.Id, webhook.URL, webhook.Secret, event.Type, payload)
+		}
+	}
+}
+
+/*
+The Deliver method notifies the event to a URL outside the registered webhooks, like the ones of
+other subscriptions, signed with the given secret and retried like any other delivery. It returns
+an error if the event cannot be encoded.
+*/
+func (d *Dispatcher) Deliver(url, secret string, event events.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	d.deliver(0, url, secret, event.Type, payload)
+	return nil
+}
+
+// Auxiliary method that sends a new delivery of the payload in the background.
+func (d *Dispatcher) deliver(webhookId int, url, secret, eventType string, payload []byte) {
+	d.mu.Lock()
+	d.counter++
+	delivery := &Delivery{
+		Id:        fmt.Sprintf("%d-%d", time.Now().UnixNano(), d.counter),
+		WebhookId: webhookId,
+		URL:       url,
+		EventType: eventType,
+		secret:    secret,
+		payload:   payload,
+	}
+	d.mu.Unlock()
+
+	go d.attempt(delivery)
+}
+
+// The RetryDue method attempts again every pending delivery whose backoff delay has elapsed.
+func (d *Dispatcher) RetryDue() {
+	now := time.Now()
+
+	d.mu.Lock()
+	var due []*Delivery
+	remaining := d.pending[:0]
+	for _, delivery := range d.pending {
+		if delivery.NextAttempt.After(now) {
+			remaining = append(remaining, delivery)
+		} else {
+			due = append(due, delivery)
+		}
+	}
+	d.pending = remaining
+	d.mu.Unlock()
+
+	for _, delivery := range due {
+		d.attempt(delivery)
+	}
+}
+
+// The Pending method returns a copy of the deliveries waiting to be retried.
+func (d *Dispatcher) Pending() []Delivery {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	pending := make([]Delivery, len(d.pending))
+	for i, delivery := range d.pending {
+		pending[i] = *delivery
+	}
+	return pending
+}
+
+/*
+The attempt method sends a delivery. If it fails and the attempts limit has not been reached, the
+delivery is queued again with an exponentially increasing delay.
+*/
+func (d *Dispatcher) attempt(delivery *Delivery) {
+	delivery.Attempts++
+	err := d.send(delivery)
+	if err == nil {
+		return
+	}
+
+	delivery.LastError = err.Error()
+	if delivery.Attempts >= d.maxAttempts {
+		logging.Warnf("webhook: giving up delivery %s to %s after %d attempts: %s\n",
+			delivery.Id, delivery.URL, delivery.Attempts, err)
+		return
+	}
+
+	delivery.NextAttempt = time.Now().Add(d.baseDelay * time.Duration(1<<(delivery.Attempts-1)))
+	logging.Debugf("webhook: delivery %s to %s failed (attempt %d), retrying at %s: %s\n",
+		delivery.Id, delivery.URL, delivery.Attempts, delivery.NextAttempt.Format(time.RFC3339), err)
+	d.mu.Lock()
+	d.pending = append(d.pending, delivery)
+	d.mu.Unlock()
+}
+
+// Auxiliary method that sends a signed notification and checks the response status.
+func (d *Dispatcher) send(delivery *Delivery) error {
+	request, err := http.NewRequest(http.MethodPost, delivery.URL, bytes.NewReader(delivery.payload))
+	if err != nil {
+		return err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(HeaderEvent, delivery.EventType)
+	request.Header.Set(HeaderDelivery, delivery.Id)
+	request.Header.Set(HeaderTimestamp, timestamp)
+	request.Header.Set(HeaderSignature, "sha256="+Sign(delivery.secret, timestamp, delivery.payload))
+
+	response, err := d.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %d", response.StatusCode)
+	}
+	return nil
+}
+
+/*
+The Sign function returns the hex encoded HMAC-SHA256 of "timestamp.payload" using the given
+secret. Receivers must compute the same value to verify a notification.
+*/
+func Sign(secret string, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
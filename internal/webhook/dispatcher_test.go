@@ -0,0 +1,89 @@
+package webhook
+
+import (
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/pkg/events"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDispatcher_SignedDelivery(t *testing.T) {
+	received := make(chan *http.Request, 1)
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		received <- r
+	}))
+	defer server.Close()
+
+	service := NewService(NewRepository())
+	service.Create(domain.WebhookRequest{URL: server.URL, Secret: "s3cr3t", Events: []string{"product.created"}})
+	dispatcher := NewDispatcher(service, server.Client(), 3, time.Millisecond)
+
+	// Only the subscribed event type is delivered
+	dispatcher.Dispatch(events.Event{Type: "product.deleted", Id: 1})
+	dispatcher.Dispatch(events.Event{Type: "product.created", Id: 2})
+
+	select {
+	case request := <-received:
+		expectedSignature := "sha256=" + Sign("s3cr3t", request.Header.Get(HeaderTimestamp), body)
+		assert.Equal(t, "product.created", request.Header.Get(HeaderEvent))
+		assert.Equal(t, expectedSignature, request.Header.Get(HeaderSignature))
+	case <-time.After(time.Second):
+		t.Fatal("webhook was not notified")
+	}
+}
+
+func TestDispatcher_Retries(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Fail the first attempt
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	service := NewService(NewRepository())
+	service.Create(domain.WebhookRequest{URL: server.URL, Secret: "s3cr3t"})
+	dispatcher := NewDispatcher(service, server.Client(), 3, time.Millisecond)
+
+	dispatcher.Dispatch(events.Event{Type: "product.updated", Id: 1})
+	assert.Eventually(t, func() bool { return len(dispatcher.Pending()) == 1 }, time.Second, time.Millisecond)
+
+	// The failed delivery is retried once its backoff delay has elapsed
+	time.Sleep(5 * time.Millisecond)
+	dispatcher.RetryDue()
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	assert.Empty(t, dispatcher.Pending())
+}
+
+func TestDispatcher_Deliver(t *testing.T) {
+	received := make(chan *http.Request, 1)
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		received <- r
+	}))
+	defer server.Close()
+
+	// The URL is not one of the registered webhooks
+	dispatcher := NewDispatcher(NewService(NewRepository()), server.Client(), 3, time.Millisecond)
+	err := dispatcher.Deliver(server.URL, "s3cr3t", events.Event{Type: "products.expiring", Id: 1})
+
+	assert.NoError(t, err)
+	select {
+	case request := <-received:
+		expectedSignature := "sha256=" + Sign("s3cr3t", request.Header.Get(HeaderTimestamp), body)
+		assert.Equal(t, "products.expiring", request.Header.Get(HeaderEvent))
+		assert.Equal(t, expectedSignature, request.Header.Get(HeaderSignature))
+	case <-time.After(time.Second):
+		t.Fatal("the URL was not notified")
+	}
+}
@@ -0,0 +1,51 @@
+package webhook
+
+import (
+	"errors"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/pkg/memory"
+)
+
+var ErrNotFound = errors.New("webhook not found")
+
+// Repository is the interface definition for the webhook storage
+type Repository interface {
+	GetAll() []domain.Webhook
+	GetById(id int) (domain.Webhook, error)
+	Create(webhook domain.Webhook) domain.Webhook
+	Delete(id int) error
+}
+
+// RepositoryImpl is the in-memory implementation of the repository interface
+type RepositoryImpl struct {
+	webhooks *memory.Repository[domain.Webhook]
+}
+
+// The NewRepository function returns a new, empty instance of the repository.
+func NewRepository() Repository {
+	return &RepositoryImpl{
+		webhooks: memory.NewRepository[domain.Webhook](nil, ErrNotFound),
+	}
+}
+
+// The GetAll method returns all registered webhooks
+func (r *RepositoryImpl) GetAll() []domain.Webhook {
+	return r.webhooks.GetAll()
+}
+
+// The GetById method returns a webhook by its ID
+func (r *RepositoryImpl) GetById(id int) (domain.Webhook, error) {
+	return r.webhooks.GetById(id)
+}
+
+// The Create method stores a new webhook, assigning it a new ID.
+func (r *RepositoryImpl) Create(webhook domain.Webhook) domain.Webhook {
+	// Webhooks have no unique keys, so the creation never fails
+	webhook, _ = r.webhooks.Create(webhook)
+	return webhook
+}
+
+// The Delete method deletes a webhook. It returns an error if the webhook does not exist.
+func (r *RepositoryImpl) Delete(id int) error {
+	return r.webhooks.Delete(id)
+}
@@ -0,0 +1,43 @@
+package webhook
+
+import (
+	"github.com/JoseObreque/go-web/internal/domain"
+	"time"
+)
+
+type Service interface {
+	GetAll() []domain.Webhook
+	Create(request domain.WebhookRequest) domain.Webhook
+	Delete(id int) error
+}
+
+type ServiceImpl struct {
+	repository Repository
+}
+
+// The NewService function returns a new instance of the service.
+func NewService(repository Repository) Service {
+	return &ServiceImpl{
+		repository: repository,
+	}
+}
+
+// The GetAll method returns all registered webhooks
+func (s *ServiceImpl) GetAll() []domain.Webhook {
+	return s.repository.GetAll()
+}
+
+// The Create method registers a new webhook and returns it.
+func (s *ServiceImpl) Create(request domain.WebhookRequest) domain.Webhook {
+	return s.repository.Create(domain.Webhook{
+		URL:       request.URL,
+		Secret:    request.Secret,
+		Events:    request.Events,
+		CreatedAt: time.Now(),
+	})
+}
+
+// The Delete method removes a webhook. If the webhook does not exist, it returns an error.
+func (s *ServiceImpl) Delete(id int) error {
+	return s.repository.Delete(id)
+}
@@ -0,0 +1,55 @@
+/*
+Package adapter decouples the HTTP handlers from the web framework serving them. The handlers
+written against the Context interface are mounted on gin or on a net/http router like chi, through
+the Router interface, so the server can move away from gin one handler at a time.
+*/
+package adapter
+
+import (
+	"net/http"
+	"strings"
+)
+
+/*
+The Context interface is the request and the response of a handler, independent of the framework.
+The responses follow the envelopes of the web package, and the errors are answered with the status
+registered for them.
+*/
+type Context interface {
+	// Request returns the HTTP request.
+	Request() *http.Request
+	// Param returns the value of a path parameter, like id in /products/:id.
+	Param(name string) string
+	// Query returns the value of a query parameter, empty if it is missing.
+	Query(name string) string
+	// SetHeader sets a header of the response.
+	SetHeader(name, value string)
+	// Success answers the data in the envelope of the successful responses.
+	Success(status int, data interface{})
+	// Error answers the failed response of the error.
+	Error(err error)
+	// String answers a plain text body.
+	String(status int, body string)
+}
+
+// The HandlerFunc type is a handler written against the Context interface.
+type HandlerFunc func(c Context)
+
+/*
+The Router interface registers handlers for a method and a path. The path parameters are written
+like :id, whatever the syntax of the framework.
+*/
+type Router interface {
+	Handle(method, path string, handler HandlerFunc)
+}
+
+// Auxiliary function that converts the :name path parameters to the {name} syntax of net/http routers.
+func bracedPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
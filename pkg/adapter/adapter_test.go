@@ -0,0 +1,50 @@
+package adapter
+
+import (
+	"errors"
+	"github.com/JoseObreque/go-web/pkg/web"
+	"github.com/gin-gonic/gin"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+var errMissing = errors.New("missing item")
+
+func init() {
+	web.RegisterError(errMissing, http.StatusNotFound, "missing_item")
+}
+
+// Handler answering the id and the name query parameter, or an error for the id 0.
+func itemHandler(c Context) {
+	if c.Param("id") == "0" {
+		c.Error(errMissing)
+		return
+	}
+	c.SetHeader("X-Item", c.Param("id"))
+	c.Success(http.StatusOK, map[string]string{"id": c.Param("id"), "name": c.Query("name")})
+}
+
+func TestRouters_SameResponses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	NewGinRouter(engine).Handle(http.MethodGet, "/items/:id", itemHandler)
+	mux := chi.NewRouter()
+	NewChiRouter(mux).Handle(http.MethodGet, "/items/:id", itemHandler)
+
+	for name, handler := range map[string]http.Handler{"gin": engine, "chi": mux} {
+		found := httptest.NewRecorder()
+		handler.ServeHTTP(found, httptest.NewRequest(http.MethodGet, "/items/7?name=tea", nil))
+		missing := httptest.NewRecorder()
+		handler.ServeHTTP(missing, httptest.NewRequest(http.MethodGet, "/items/0", nil))
+
+		// Assertions
+		assert.Equal(t, http.StatusOK, found.Code, name)
+		assert.Equal(t, "7", found.Header().Get("X-Item"), name)
+		assert.JSONEq(t, `{"data":{"id":"7","name":"tea"}}`, found.Body.String(), name)
+		assert.Equal(t, http.StatusNotFound, missing.Code, name)
+		assert.Contains(t, missing.Body.String(), `"error_code":"missing_item"`, name)
+	}
+}
@@ -0,0 +1,67 @@
+package adapter
+
+import (
+	"github.com/JoseObreque/go-web/pkg/web"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+)
+
+// The httpContext struct is the Context of a request served by net/http, with the path parameters of chi.
+type httpContext struct {
+	w http.ResponseWriter
+	r *http.Request
+}
+
+func (h httpContext) Request() *http.Request {
+	return h.r
+}
+
+func (h httpContext) Param(name string) string {
+	return chi.URLParam(h.r, name)
+}
+
+func (h httpContext) Query(name string) string {
+	return h.r.URL.Query().Get(name)
+}
+
+func (h httpContext) SetHeader(name, value string) {
+	h.w.Header().Set(name, value)
+}
+
+func (h httpContext) Success(status int, data interface{}) {
+	web.WriteSuccess(h.w, status, data)
+}
+
+func (h httpContext) Error(err error) {
+	web.WriteError(h.w, h.r, err)
+}
+
+func (h httpContext) String(status int, body string) {
+	h.w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	h.w.WriteHeader(status)
+	_, _ = h.w.Write([]byte(body))
+}
+
+// The HTTP function returns the net/http handler running the given handler, with the path parameters of chi.
+func HTTP(handler HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handler(httpContext{w: w, r: r})
+	}
+}
+
+// The chiRouter struct is the Router of a chi router.
+type chiRouter struct {
+	router chi.Router
+}
+
+// The NewChiRouter function returns a Router registering the handlers in the given chi router.
+func NewChiRouter(router chi.Router) Router {
+	return &chiRouter{
+		router: router,
+	}
+}
+
+// The Handle method registers the handler in chi.
+func (r *chiRouter) Handle(method, path string, handler HandlerFunc) {
+	r.router.Method(method, bracedPath(path), HTTP(handler))
+}
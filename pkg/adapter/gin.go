@@ -0,0 +1,67 @@
+package adapter
+
+import (
+	"github.com/JoseObreque/go-web/pkg/web"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// The ginContext struct is the Context of a request served by gin.
+type ginContext struct {
+	c *gin.Context
+}
+
+func (g ginContext) Request() *http.Request {
+	return g.c.Request
+}
+
+func (g ginContext) Param(name string) string {
+	return g.c.Param(name)
+}
+
+func (g ginContext) Query(name string) string {
+	return g.c.Query(name)
+}
+
+func (g ginContext) SetHeader(name, value string) {
+	g.c.Header(name, value)
+}
+
+func (g ginContext) Success(status int, data interface{}) {
+	web.Success(g.c, status, data)
+}
+
+func (g ginContext) Error(err error) {
+	web.Error(g.c, err)
+}
+
+func (g ginContext) String(status int, body string) {
+	g.c.String(status, body)
+}
+
+// The Gin function returns the gin handler running the given handler.
+func Gin(handler HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		handler(ginContext{c: c})
+	}
+}
+
+// The ginRouter struct is the Router of a gin engine or group.
+type ginRouter struct {
+	routes gin.IRoutes
+	// Middlewares run before the handlers
+	middlewares []gin.HandlerFunc
+}
+
+// The NewGinRouter function returns a Router registering the handlers in the given gin routes, after the middlewares.
+func NewGinRouter(routes gin.IRoutes, middlewares ...gin.HandlerFunc) Router {
+	return &ginRouter{
+		routes:      routes,
+		middlewares: middlewares,
+	}
+}
+
+// The Handle method registers the handler in gin.
+func (r *ginRouter) Handle(method, path string, handler HandlerFunc) {
+	r.routes.Handle(method, path, append(append([]gin.HandlerFunc(nil), r.middlewares...), Gin(handler))...)
+}
@@ -0,0 +1,162 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// The entry struct is a cached value with its key and expiration time.
+type entry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// Auxiliary method that returns the number of bytes the entry takes in a bounded cache.
+func (e *entry) size() int {
+	return len(e.key) + len(e.value)
+}
+
+// Minimum time between two sweeps of the expired entries.
+const sweepInterval = time.Minute
+
+/*
+The memoryCache struct is an in-process implementation of the Cache interface. Expired entries
+are removed when they are read, and swept periodically while new entries are written. A bounded
+cache evicts the least recently used entries once its keys and values exceed its maximum size.
+*/
+type memoryCache struct {
+	mu        sync.Mutex
+	entries   map[string]*list.Element
+	order     *list.List
+	size      int
+	maxSize   int
+	lastSweep time.Time
+}
+
+// The NewMemory function returns a new, empty in-process cache.
+func NewMemory() Cache {
+	return NewBoundedMemory(0)
+}
+
+/*
+The NewBoundedMemory function returns a new, empty in-process cache holding up to maxBytes of keys
+and values, evicting the least recently used entries beyond. A value larger than maxBytes is not
+stored, and zero means no limit.
+*/
+func NewBoundedMemory(maxBytes int) Cache {
+	return &memoryCache{
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+		maxSize: maxBytes,
+	}
+}
+
+// The Get method returns the value of a key, or ErrMiss if it is missing or expired.
+func (m *memoryCache) Get(ctx context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	element, ok := m.entries[key]
+	if !ok {
+		return nil, ErrMiss
+	}
+	e := element.Value.(*entry)
+	if !e.expiresAt.IsZero() && time.Now().After(e.expiresAt) {
+		m.remove(element)
+		return nil, ErrMiss
+	}
+	m.order.MoveToFront(element)
+	return e.value, nil
+}
+
+// The Set method stores the value of a key. A zero ttl means the key never expires.
+func (m *memoryCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sweep()
+	e := &entry{key: key, value: value}
+	if ttl > 0 {
+		e.expiresAt = time.Now().Add(ttl)
+	}
+	m.store(e)
+	return nil
+}
+
+// The Delete method removes the given keys.
+func (m *memoryCache) Delete(ctx context.Context, keys ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, key := range keys {
+		if element, ok := m.entries[key]; ok {
+			m.remove(element)
+		}
+	}
+	return nil
+}
+
+// The Incr method increments the integer stored at a key, starting from zero, and returns it.
+func (m *memoryCache) Incr(ctx context.Context, key string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var current int64
+	if element, ok := m.entries[key]; ok {
+		parsed, err := strconv.ParseInt(string(element.Value.(*entry).value), 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		current = parsed
+	}
+
+	current++
+	m.store(&entry{key: key, value: []byte(strconv.FormatInt(current, 10))})
+	return current, nil
+}
+
+/*
+Auxiliary method that stores an entry as the most recently used one, replacing the entry of its key
+and evicting the least recently used ones beyond the maximum size. It must hold the lock.
+*/
+func (m *memoryCache) store(e *entry) {
+	if element, ok := m.entries[e.key]; ok {
+		m.remove(element)
+	}
+	if m.maxSize > 0 && e.size() > m.maxSize {
+		return
+	}
+
+	m.entries[e.key] = m.order.PushFront(e)
+	m.size += e.size()
+	for m.maxSize > 0 && m.size > m.maxSize {
+		m.remove(m.order.Back())
+	}
+}
+
+// Auxiliary method that removes an entry. It must hold the lock.
+func (m *memoryCache) remove(element *list.Element) {
+	e := m.order.Remove(element).(*entry)
+	delete(m.entries, e.key)
+	m.size -= e.size()
+}
+
+// Auxiliary method that removes the expired entries, at most once per sweepInterval. It must hold the lock.
+func (m *memoryCache) sweep() {
+	now := time.Now()
+	if now.Sub(m.lastSweep) < sweepInterval {
+		return
+	}
+
+	m.lastSweep = now
+	for _, element := range m.entries {
+		e := element.Value.(*entry)
+		if !e.expiresAt.IsZero() && now.After(e.expiresAt) {
+			m.remove(element)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package cache
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestBoundedMemory(t *testing.T) {
+	ctx := context.Background()
+	// Room for two entries of a one-byte key and a four-byte value
+	memory := NewBoundedMemory(10)
+
+	_ = memory.Set(ctx, "a", []byte("1111"), time.Minute)
+	_ = memory.Set(ctx, "b", []byte("2222"), time.Minute)
+	_, errA := memory.Get(ctx, "a")
+	_ = memory.Set(ctx, "c", []byte("3333"), time.Minute)
+	_, errB := memory.Get(ctx, "b")
+	_ = memory.Set(ctx, "d", []byte("a value too large"), time.Minute)
+	_, errD := memory.Get(ctx, "d")
+	a, _ := memory.Get(ctx, "a")
+	c, _ := memory.Get(ctx, "c")
+
+	// Assertions: the least recently used entry is evicted
+	assert.NoError(t, errA)
+	assert.ErrorIs(t, errB, ErrMiss)
+	assert.ErrorIs(t, errD, ErrMiss)
+	assert.Equal(t, []byte("1111"), a)
+	assert.Equal(t, []byte("3333"), c)
+}
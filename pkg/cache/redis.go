@@ -0,0 +1,52 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"github.com/redis/go-redis/v9"
+	"time"
+)
+
+// The redisCache struct is the implementation of the Cache interface backed by Redis.
+type redisCache struct {
+	client *redis.Client
+}
+
+/*
+The NewRedis function returns a Cache connected to the Redis server of the given URL, with the
+format redis://[user:password@]host:port/db.
+*/
+func NewRedis(url string) (Cache, error) {
+	options, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, err
+	}
+
+	return &redisCache{
+		client: redis.NewClient(options),
+	}, nil
+}
+
+// The Get method returns the value of a key, or ErrMiss if it does not exist.
+func (r *redisCache) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := r.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrMiss
+	}
+	return value, err
+}
+
+// The Set method stores the value of a key. A zero ttl means the key never expires.
+func (r *redisCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return r.client.Set(ctx, key, value, ttl).Err()
+}
+
+// The Delete method removes the given keys.
+func (r *redisCache) Delete(ctx context.Context, keys ...string) error {
+	return r.client.Del(ctx, keys...).Err()
+}
+
+// The Incr method increments the integer stored at a key, starting from zero, and returns it.
+func (r *redisCache) Incr(ctx context.Context, key string) (int64, error) {
+	return r.client.Incr(ctx, key).Result()
+}
@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"github.com/JoseObreque/go-web/pkg/resilience"
+	"time"
+)
+
+/*
+The resilientCache struct is a Cache decorator that calls another cache through a circuit breaker,
+retrying its failures. Misses are not failures. While the circuit is open every call fails with
+resilience.ErrOpen right away, so the callers falling back on cache errors stop waiting for a cache
+that is down. Incr is never retried, as a retry could count twice.
+*/
+type resilientCache struct {
+	cache   Cache
+	breaker *resilience.Breaker
+	retry   resilience.Retry
+}
+
+// The NewResilientCache function returns the given cache called through the breaker with the retry policy.
+func NewResilientCache(cache Cache, breaker *resilience.Breaker, retry resilience.Retry) Cache {
+	return &resilientCache{
+		cache:   cache,
+		breaker: breaker,
+		retry:   retry,
+	}
+}
+
+// The Get method returns the cached value of the key, or ErrMiss.
+func (c *resilientCache) Get(ctx context.Context, key string) ([]byte, error) {
+	var value []byte
+	miss := false
+	err := resilience.Call(c.breaker, c.retry, func() error {
+		var err error
+		value, err = c.cache.Get(ctx, key)
+		if errors.Is(err, ErrMiss) {
+			miss = true
+			return nil
+		}
+		return err
+	})
+	if err == nil && miss {
+		return nil, ErrMiss
+	}
+	return value, err
+}
+
+// The Set method caches the value of the key for ttl.
+func (c *resilientCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return resilience.Call(c.breaker, c.retry, func() error {
+		return c.cache.Set(ctx, key, value, ttl)
+	})
+}
+
+// The Delete method removes the keys from the cache.
+func (c *resilientCache) Delete(ctx context.Context, keys ...string) error {
+	return resilience.Call(c.breaker, c.retry, func() error {
+		return c.cache.Delete(ctx, keys...)
+	})
+}
+
+// The Incr method increments the counter of the key, without retries.
+func (c *resilientCache) Incr(ctx context.Context, key string) (int64, error) {
+	var value int64
+	err := c.breaker.Do(func() error {
+		var err error
+		value, err = c.cache.Incr(ctx, key)
+		return err
+	})
+	return value, err
+}
@@ -0,0 +1,43 @@
+package cache
+
+import (
+	"sync/atomic"
+)
+
+/*
+The Stats struct reports the reads of a cache: the hits, the misses, and the share of the reads
+that were hits, zero if there were no reads.
+*/
+type Stats struct {
+	Hits     int64   `json:"hits"`
+	Misses   int64   `json:"misses"`
+	HitRatio float64 `json:"hit_ratio"`
+}
+
+// The Counter struct counts the hits and misses of a cache. It is safe for concurrent use.
+type Counter struct {
+	hits   int64
+	misses int64
+}
+
+// The Hit method counts a read served from the cache.
+func (c *Counter) Hit() {
+	atomic.AddInt64(&c.hits, 1)
+}
+
+// The Miss method counts a read not served from the cache.
+func (c *Counter) Miss() {
+	atomic.AddInt64(&c.misses, 1)
+}
+
+// The Stats method returns the reads counted so far.
+func (c *Counter) Stats() Stats {
+	stats := Stats{
+		Hits:   atomic.LoadInt64(&c.hits),
+		Misses: atomic.LoadInt64(&c.misses),
+	}
+	if reads := stats.Hits + stats.Misses; reads > 0 {
+		stats.HitRatio = float64(stats.Hits) / float64(reads)
+	}
+	return stats
+}
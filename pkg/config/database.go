@@ -0,0 +1,80 @@
+/*
+Package config loads the settings of the optional backends of the server from the environment
+variables, and reloads the settings that can change while the server runs.
+*/
+package config
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"strings"
+)
+
+var ErrMissingPrimary = errors.New("read replicas configured without a primary database")
+
+/*
+The Database struct holds the data source names of a SQL backend: the primary database, receiving
+the writes, and its read replicas, receiving the reads. Without replicas, the reads go to the
+primary as well.
+*/
+type Database struct {
+	Primary  string
+	Replicas []string
+}
+
+/*
+The LoadDatabase function returns the SQL backend configured by DATABASE_URL, the primary, and
+DATABASE_REPLICA_URLS, the comma separated list of read replicas. It returns an empty Database if
+none is set.
+*/
+func LoadDatabase() (Database, error) {
+	database := Database{
+		Primary:  strings.TrimSpace(os.Getenv("DATABASE_URL")),
+		Replicas: SplitList(os.Getenv("DATABASE_REPLICA_URLS")),
+	}
+	if database.Primary == "" && len(database.Replicas) > 0 {
+		return Database{}, ErrMissingPrimary
+	}
+	return database, nil
+}
+
+// The Enabled method reports whether a SQL backend is configured.
+func (d Database) Enabled() bool {
+	return d.Primary != ""
+}
+
+/*
+The Open method opens the primary database and the replicas with the given driver. If any of them
+cannot be opened, the ones already open are closed.
+*/
+func (d Database) Open(driver string) (*sql.DB, []*sql.DB, error) {
+	primary, err := sql.Open(driver, d.Primary)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	replicas := make([]*sql.DB, 0, len(d.Replicas))
+	for _, dsn := range d.Replicas {
+		replica, err := sql.Open(driver, dsn)
+		if err != nil {
+			for _, db := range append(replicas, primary) {
+				_ = db.Close()
+			}
+			return nil, nil, err
+		}
+		replicas = append(replicas, replica)
+	}
+	return primary, replicas, nil
+}
+
+// The SplitList function splits a comma separated list, dropping the empty items.
+func SplitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
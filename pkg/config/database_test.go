@@ -0,0 +1,42 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLoadDatabase(t *testing.T) {
+	t.Run("primary and replicas", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://primary/products")
+		t.Setenv("DATABASE_REPLICA_URLS", "postgres://replica-1/products, ,postgres://replica-2/products")
+
+		database, err := LoadDatabase()
+
+		// Assertions
+		assert.NoError(t, err)
+		assert.True(t, database.Enabled())
+		assert.Equal(t, "postgres://primary/products", database.Primary)
+		assert.Equal(t, []string{"postgres://replica-1/products", "postgres://replica-2/products"}, database.Replicas)
+	})
+
+	t.Run("not configured", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "")
+		t.Setenv("DATABASE_REPLICA_URLS", "")
+
+		database, err := LoadDatabase()
+
+		// Assertions
+		assert.NoError(t, err)
+		assert.False(t, database.Enabled())
+	})
+
+	t.Run("replicas without a primary", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "")
+		t.Setenv("DATABASE_REPLICA_URLS", "postgres://replica-1/products")
+
+		_, err := LoadDatabase()
+
+		// Assertions
+		assert.ErrorIs(t, err, ErrMissingPrimary)
+	})
+}
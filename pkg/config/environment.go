@@ -0,0 +1,138 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidEnvironment = errors.New("invalid environment")
+
+// Minimum length of a secret, like the token, that is not considered trivial.
+const minSecretLength = 16
+
+// Well-known values of a secret, which any attacker tries first.
+var trivialSecrets = map[string]bool{
+	"12345": true, "123456": true, "changeme": true, "password": true, "secret": true, "test": true, "token": true,
+}
+
+// The Problem struct describes an environment variable with an invalid or risky value.
+type Problem struct {
+	Variable string
+	Message  string
+	// Whether the server can start anyway
+	Warning bool
+}
+
+// The String method returns the problem as a line of the environment report.
+func (p Problem) String() string {
+	if p.Warning {
+		return fmt.Sprintf("%s: %s (warning)", p.Variable, p.Message)
+	}
+	return fmt.Sprintf("%s: %s", p.Variable, p.Message)
+}
+
+/*
+The EnvReport struct collects every problem of the environment found at startup, so they are all
+reported at once instead of stopping at the first one. Only the errors stop the server.
+*/
+type EnvReport struct {
+	Problems []Problem
+}
+
+// The Fail method records an invalid variable, which stops the server.
+func (r *EnvReport) Fail(variable string, format string, args ...interface{}) {
+	r.Problems = append(r.Problems, Problem{Variable: variable, Message: fmt.Sprintf(format, args...)})
+}
+
+// The Warn method records a risky variable, with which the server starts anyway.
+func (r *EnvReport) Warn(variable string, format string, args ...interface{}) {
+	r.Problems = append(r.Problems, Problem{Variable: variable, Message: fmt.Sprintf(format, args...), Warning: true})
+}
+
+/*
+The CheckSecret method checks that a secret, like the token, is set and not trivial: at least 16
+characters and not a well-known value. A trivial secret is only a warning if allowTrivial is true,
+for example in development.
+*/
+func (r *EnvReport) CheckSecret(variable, value string, allowTrivial bool) {
+	if value == "" {
+		r.Fail(variable, "is required")
+		return
+	}
+	if len(value) >= minSecretLength && !trivialSecrets[strings.ToLower(value)] {
+		return
+	}
+	message := fmt.Sprintf("is trivial, use at least %d random characters", minSecretLength)
+	if allowTrivial {
+		r.Warn(variable, message)
+	} else {
+		r.Fail(variable, message)
+	}
+}
+
+// The CheckPort method checks that a port is a number between 1 and 65535.
+func (r *EnvReport) CheckPort(variable, value string) {
+	port, err := strconv.Atoi(value)
+	if err != nil || port < 1 || port > 65535 {
+		r.Fail(variable, "%q is not a valid port, expected a number between 1 and 65535", value)
+	}
+}
+
+/*
+The CheckFile method checks that a data file can be used: if it exists it must be a readable
+file, and otherwise its directory must exist so the file can be created.
+*/
+func (r *EnvReport) CheckFile(variable, path string) {
+	info, err := os.Stat(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		if dir, err := os.Stat(filepath.Dir(path)); err != nil || !dir.IsDir() {
+			r.Fail(variable, "%s does not exist and its directory %s cannot hold it", path, filepath.Dir(path))
+		}
+	case err != nil:
+		r.Fail(variable, "%s cannot be read: %s", path, err)
+	case info.IsDir():
+		r.Fail(variable, "%s is a directory", path)
+	default:
+		file, err := os.Open(path)
+		if err != nil {
+			r.Fail(variable, "%s cannot be read: %s", path, err)
+			return
+		}
+		_ = file.Close()
+	}
+}
+
+// The Warnings method returns the problems with which the server starts anyway.
+func (r EnvReport) Warnings() []Problem {
+	var warnings []Problem
+	for _, problem := range r.Problems {
+		if problem.Warning {
+			warnings = append(warnings, problem)
+		}
+	}
+	return warnings
+}
+
+// The String method returns the report with one line per problem.
+func (r EnvReport) String() string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "%d environment variables have problems", len(r.Problems))
+	for _, problem := range r.Problems {
+		builder.WriteString("\n  ")
+		builder.WriteString(problem.String())
+	}
+	return builder.String()
+}
+
+// The Err method returns an error wrapping ErrInvalidEnvironment with the report, or nil if there are only warnings.
+func (r EnvReport) Err() error {
+	if len(r.Warnings()) == len(r.Problems) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrInvalidEnvironment, r)
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEnvReport(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "products.json")
+	if err := os.WriteFile(existing, []byte("[]"), 0o644); err != nil {
+		panic(err)
+	}
+
+	var valid EnvReport
+	valid.CheckSecret("TOKEN", "8f14e45fceea167a5a36dedd4bea2543", false)
+	valid.CheckSecret("SIGNING_SECRET", "12345", true)
+	valid.CheckPort("PORT", "8080")
+	valid.CheckFile("STORE_FILE", existing)
+	valid.CheckFile("STORE_FILE", filepath.Join(dir, "new.json"))
+
+	var invalid EnvReport
+	invalid.CheckSecret("TOKEN", "", false)
+	invalid.CheckSecret("SIGNING_SECRET", "secret", false)
+	invalid.CheckPort("PORT", "80a")
+	invalid.CheckPort("GRPC_PORT", "70000")
+	invalid.CheckFile("STORE_FILE", filepath.Join(dir, "missing", "products.json"))
+	invalid.CheckFile("BACKUP_FILE", dir)
+
+	// Assertions
+	assert.NoError(t, valid.Err())
+	assert.Len(t, valid.Warnings(), 1)
+	assert.ErrorIs(t, invalid.Err(), ErrInvalidEnvironment)
+	assert.Len(t, invalid.Problems, 6)
+	assert.Contains(t, invalid.String(), "TOKEN: is required")
+	assert.Contains(t, invalid.String(), `PORT: "80a" is not a valid port`)
+}
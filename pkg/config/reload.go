@@ -0,0 +1,128 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var ErrInvalidReload = errors.New("invalid configuration, nothing was reloaded")
+
+// The Env type looks up an environment variable of a reload, empty if it is not set.
+type Env func(key string) string
+
+/*
+The Prepare type reads and validates a setting from the environment of a reload, returning the
+function that applies it. It must not change anything itself, so an invalid setting leaves every
+other one untouched.
+*/
+type Prepare func(env Env) (apply func(), err error)
+
+/*
+The ReloadError struct is the failure of a reload, with the error of every invalid setting by its
+name. It matches ErrInvalidReload through errors.Is.
+*/
+type ReloadError struct {
+	Failures map[string]error
+}
+
+func (e *ReloadError) Error() string {
+	names := make([]string, 0, len(e.Failures))
+	for name := range e.Failures {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	details := make([]string, len(names))
+	for i, name := range names {
+		details[i] = fmt.Sprintf("%s: %s", name, e.Failures[name])
+	}
+	return fmt.Sprintf("%s (%s)", ErrInvalidReload, strings.Join(details, "; "))
+}
+
+func (e *ReloadError) Unwrap() error {
+	return ErrInvalidReload
+}
+
+// The setting struct is a setting registered in a Reloader.
+type setting struct {
+	name    string
+	prepare Prepare
+}
+
+/*
+The Reloader struct applies the changes of the configuration without restarting the server, nor
+dropping its connections. A reload reads the variables of the source, which override the ones of
+the process environment, prepares every registered setting with them and, only if all of them are
+valid, sets the variables in the process environment and applies the settings. The settings read
+on every use, like TOKEN, follow the environment without being registered.
+*/
+type Reloader struct {
+	mu       sync.Mutex
+	source   func() (map[string]string, error)
+	settings []setting
+}
+
+// The NewReloader function returns a Reloader reading the changed variables from the given source, like an env file.
+func NewReloader(source func() (map[string]string, error)) *Reloader {
+	return &Reloader{
+		source: source,
+	}
+}
+
+// The Register method adds a setting applied on every reload. The settings are applied in the order they are registered.
+func (r *Reloader) Register(name string, prepare Prepare) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.settings = append(r.settings, setting{name: name, prepare: prepare})
+}
+
+/*
+The Reload method reloads the configuration from the source and returns the names of the applied
+settings. If the source cannot be read or any setting is invalid, nothing changes and it returns
+a ReloadError.
+*/
+func (r *Reloader) Reload() ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	values, err := r.source()
+	if err != nil {
+		return nil, &ReloadError{Failures: map[string]error{"source": err}}
+	}
+	env := func(key string) string {
+		if value, ok := values[key]; ok {
+			return value
+		}
+		return os.Getenv(key)
+	}
+
+	failures := make(map[string]error)
+	applies := make([]func(), 0, len(r.settings))
+	for _, s := range r.settings {
+		apply, err := s.prepare(env)
+		if err != nil {
+			failures[s.name] = err
+			continue
+		}
+		applies = append(applies, apply)
+	}
+	if len(failures) > 0 {
+		return nil, &ReloadError{Failures: failures}
+	}
+
+	for key, value := range values {
+		if err = os.Setenv(key, value); err != nil {
+			return nil, &ReloadError{Failures: map[string]error{key: err}}
+		}
+	}
+	names := make([]string, len(r.settings))
+	for i, apply := range applies {
+		apply()
+		names[i] = r.settings[i].name
+	}
+	return names, nil
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"strconv"
+	"testing"
+)
+
+func TestReloader(t *testing.T) {
+	t.Setenv("RELOAD_TEST_LIMIT", "10")
+	t.Setenv("RELOAD_TEST_NAME", "before")
+	values := map[string]string{"RELOAD_TEST_LIMIT": "oops", "RELOAD_TEST_NAME": "after"}
+	limit := NewValue(10)
+	name := NewValue("before")
+	reloader := NewReloader(func() (map[string]string, error) { return values, nil })
+	reloader.Register("limit", func(env Env) (func(), error) {
+		value, err := strconv.Atoi(env("RELOAD_TEST_LIMIT"))
+		if err != nil {
+			return nil, err
+		}
+		return func() { limit.Set(value) }, nil
+	})
+	reloader.Register("name", func(env Env) (func(), error) {
+		value := env("RELOAD_TEST_NAME")
+		return func() { name.Set(value) }, nil
+	})
+
+	_, invalidErr := reloader.Reload()
+	var reloadErr *ReloadError
+	errors.As(invalidErr, &reloadErr)
+	nameAfterInvalid := name.Get()
+	values["RELOAD_TEST_LIMIT"] = "20"
+	reloaded, err := reloader.Reload()
+
+	// Assertions
+	assert.ErrorIs(t, invalidErr, ErrInvalidReload)
+	assert.Contains(t, reloadErr.Failures, "limit")
+	assert.Equal(t, "before", nameAfterInvalid)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"limit", "name"}, reloaded)
+	assert.Equal(t, 20, limit.Get())
+	assert.Equal(t, "after", name.Get())
+	assert.Equal(t, "after", os.Getenv("RELOAD_TEST_NAME"))
+}
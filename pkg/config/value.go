@@ -0,0 +1,35 @@
+package config
+
+import (
+	"sync"
+)
+
+/*
+The Value struct holds a setting that can change while the server runs, like a secret rotated or
+reloaded from the environment, safe for concurrent use.
+*/
+type Value[T any] struct {
+	mu    sync.RWMutex
+	value T
+}
+
+// The NewValue function returns a setting holding the given initial value.
+func NewValue[T any](value T) *Value[T] {
+	return &Value[T]{
+		value: value,
+	}
+}
+
+// The Get method returns the current value of the setting.
+func (v *Value[T]) Get() T {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.value
+}
+
+// The Set method replaces the value of the setting.
+func (v *Value[T]) Set(value T) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.value = value
+}
@@ -0,0 +1,84 @@
+package currency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrUnsupportedCurrency = errors.New("unsupported currency")
+	ErrRatesUnavailable    = errors.New("exchange rates unavailable")
+	ErrInvalidRates        = errors.New("invalid exchange rates, expected CODE=rate pairs")
+)
+
+/*
+The Provider interface defines the source of the exchange rates. The Rates method returns, for every
+currency the provider knows, the amount of that currency bought by one unit of the base currency.
+Implementations must be safe for concurrent use.
+*/
+type Provider interface {
+	Rates(ctx context.Context, base string) (map[string]float64, error)
+}
+
+// The Converter struct converts prices between currencies using the rates of a Provider.
+type Converter struct {
+	provider Provider
+}
+
+/*
+The NewConverter function returns a converter using the rates of the given provider. A converter
+without provider only converts a currency to itself.
+*/
+func NewConverter(provider Provider) *Converter {
+	return &Converter{
+		provider: provider,
+	}
+}
+
+/*
+The Convert method converts an amount from a currency to another one, rounded to cents. It returns
+ErrUnsupportedCurrency if the provider has no rate between both currencies.
+*/
+func (c *Converter) Convert(ctx context.Context, amount float64, from, to string) (float64, error) {
+	if from == to {
+		return amount, nil
+	}
+	if c.provider == nil {
+		return 0, fmt.Errorf("%w: %s", ErrUnsupportedCurrency, to)
+	}
+
+	rates, err := c.provider.Rates(ctx, from)
+	if err != nil {
+		return 0, err
+	}
+	rate, ok := rates[to]
+	if !ok || rate <= 0 {
+		return 0, fmt.Errorf("%w: %s", ErrUnsupportedCurrency, to)
+	}
+	return math.Round(amount*rate*100) / 100, nil
+}
+
+/*
+The ParseRates function parses a list of exchange rates written as comma separated CODE=rate pairs,
+like "EUR=0.92,CLP=950".
+*/
+func ParseRates(value string) (map[string]float64, error) {
+	rates := make(map[string]float64)
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		code, stringRate, found := strings.Cut(pair, "=")
+		rate, err := strconv.ParseFloat(strings.TrimSpace(stringRate), 64)
+		if !found || err != nil || rate <= 0 {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidRates, pair)
+		}
+		rates[strings.ToUpper(strings.TrimSpace(code))] = rate
+	}
+	return rates, nil
+}
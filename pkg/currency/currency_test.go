@@ -0,0 +1,72 @@
+package currency
+
+import (
+	"context"
+	"github.com/JoseObreque/go-web/pkg/cache"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestConverter_Convert(t *testing.T) {
+	ctx := context.Background()
+	converter := NewConverter(NewStaticProvider("USD", map[string]float64{"EUR": 0.5, "CLP": 1000}))
+
+	toEUR, errEUR := converter.Convert(ctx, 10, "USD", "EUR")
+	toUSD, errUSD := converter.Convert(ctx, 10, "EUR", "USD")
+	cross, errCross := converter.Convert(ctx, 1, "EUR", "CLP")
+	_, errUnsupported := converter.Convert(ctx, 1, "USD", "JPY")
+	same, errSame := NewConverter(nil).Convert(ctx, 3.14159, "USD", "USD")
+	_, errNoProvider := NewConverter(nil).Convert(ctx, 1, "USD", "EUR")
+
+	// Assertions
+	assert.NoError(t, errEUR)
+	assert.Equal(t, 5.0, toEUR)
+	assert.NoError(t, errUSD)
+	assert.Equal(t, 20.0, toUSD)
+	assert.NoError(t, errCross)
+	assert.Equal(t, 2000.0, cross)
+	assert.ErrorIs(t, errUnsupported, ErrUnsupportedCurrency)
+	assert.NoError(t, errSame)
+	assert.Equal(t, 3.14159, same)
+	assert.ErrorIs(t, errNoProvider, ErrUnsupportedCurrency)
+}
+
+func TestParseRates(t *testing.T) {
+	rates, err := ParseRates("eur=0.92, CLP=950,")
+	_, errInvalid := ParseRates("EUR=zero")
+
+	// Assertions
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]float64{"EUR": 0.92, "CLP": 950}, rates)
+	assert.ErrorIs(t, errInvalid, ErrInvalidRates)
+}
+
+func TestCachedProvider_HTTP(t *testing.T) {
+	var requests int32
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.URL.Path != "/latest/USD" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"base":"USD","rates":{"EUR":0.5}}`))
+	}))
+	defer api.Close()
+	provider := NewCachedProvider(NewHTTPProvider(api.URL+"/latest/{base}", api.Client()), cache.NewMemory(), time.Minute)
+	converter := NewConverter(provider)
+
+	first, err := converter.Convert(context.Background(), 10, "USD", "EUR")
+	second, _ := converter.Convert(context.Background(), 20, "USD", "EUR")
+	_, errUnavailable := converter.Convert(context.Background(), 10, "GBP", "EUR")
+
+	// Assertions: the rates are requested once
+	assert.NoError(t, err)
+	assert.Equal(t, 5.0, first)
+	assert.Equal(t, 10.0, second)
+	assert.ErrorIs(t, errUnavailable, ErrRatesUnavailable)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+}
@@ -0,0 +1,140 @@
+package currency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/JoseObreque/go-web/pkg/cache"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// The staticProvider struct is a Provider with fixed rates from a single base currency.
+type staticProvider struct {
+	base  string
+	rates map[string]float64
+}
+
+/*
+The NewStaticProvider function returns a provider with fixed rates from the given base currency.
+The rates between two other currencies are derived from their rates from the base currency.
+*/
+func NewStaticProvider(base string, rates map[string]float64) Provider {
+	return &staticProvider{
+		base:  base,
+		rates: rates,
+	}
+}
+
+// The Rates method returns the rates from the given currency.
+func (p *staticProvider) Rates(ctx context.Context, base string) (map[string]float64, error) {
+	divisor := 1.0
+	if base != p.base {
+		rate, ok := p.rates[base]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedCurrency, base)
+		}
+		divisor = rate
+	}
+
+	rates := make(map[string]float64, len(p.rates)+1)
+	rates[p.base] = 1 / divisor
+	for code, rate := range p.rates {
+		rates[code] = rate / divisor
+	}
+	return rates, nil
+}
+
+// The httpProvider struct is a Provider that requests the rates to an exchange rates API.
+type httpProvider struct {
+	url    string
+	client *http.Client
+}
+
+/*
+The NewHTTPProvider function returns a provider requesting the rates to the given URL, where {base}
+is replaced by the base currency. The API must answer a JSON object with the rates in its "rates"
+field, like {"rates": {"EUR": 0.92}}, which most exchange rates APIs do.
+*/
+func NewHTTPProvider(url string, client *http.Client) Provider {
+	return &httpProvider{
+		url:    url,
+		client: client,
+	}
+}
+
+// The Rates method requests the rates from the given currency. Any failure returns ErrRatesUnavailable.
+func (p *httpProvider) Rates(ctx context.Context, base string) (map[string]float64, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.ReplaceAll(p.url, "{base}", base), nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrRatesUnavailable, err)
+	}
+	response, err := p.client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrRatesUnavailable, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: status %d", ErrRatesUnavailable, response.StatusCode)
+	}
+	var body struct {
+		Rates map[string]float64 `json:"rates"`
+	}
+	if err = json.NewDecoder(response.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrRatesUnavailable, err)
+	}
+	if len(body.Rates) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCurrency, base)
+	}
+	return body.Rates, nil
+}
+
+// The cachedProvider struct is a Provider decorator that caches the rates of another provider.
+type cachedProvider struct {
+	provider Provider
+	cache    cache.Cache
+	ttl      time.Duration
+}
+
+/*
+The NewCachedProvider function returns a provider that caches the rates of the given one for ttl,
+so the exchange rates API is requested at most once per base currency and ttl. If the cache fails,
+the rates are requested to the provider.
+*/
+func NewCachedProvider(provider Provider, cache cache.Cache, ttl time.Duration) Provider {
+	return &cachedProvider{
+		provider: provider,
+		cache:    cache,
+		ttl:      ttl,
+	}
+}
+
+// The Rates method returns the cached rates from the given currency, requesting them if missing.
+func (p *cachedProvider) Rates(ctx context.Context, base string) (map[string]float64, error) {
+	key := "currency:rates:" + base
+
+	data, err := p.cache.Get(ctx, key)
+	if err == nil {
+		var rates map[string]float64
+		if json.Unmarshal(data, &rates) == nil {
+			return rates, nil
+		}
+	} else if !errors.Is(err, cache.ErrMiss) {
+		log.Printf("currency: could not read the cached rates of %s: %s\n", base, err)
+	}
+
+	rates, err := p.provider.Rates(ctx, base)
+	if err != nil {
+		return nil, err
+	}
+	if data, err = json.Marshal(rates); err == nil {
+		if err = p.cache.Set(ctx, key, data, p.ttl); err != nil {
+			log.Printf("currency: could not cache the rates of %s: %s\n", base, err)
+		}
+	}
+	return rates, nil
+}
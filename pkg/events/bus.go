@@ -0,0 +1,158 @@
+package events
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// Size of the channel buffer of every subscriber.
+const subscriberBuffer = 64
+
+/*
+The Event struct represents something that happened to an entity. Type identifies what happened
+(for example "product.created"), Id is the identifier of the affected entity, Data contains its
+state after the change and Previous its state before the change, when available. Actor is who made
+the change, empty for the changes made by the system itself.
+*/
+type Event struct {
+	Type      string      `json:"type"`
+	Id        int         `json:"id"`
+	Data      interface{} `json:"data,omitempty"`
+	Previous  interface{} `json:"previous,omitempty"`
+	Actor     string      `json:"actor,omitempty"`
+	Timestamp time.Time   `json:"timestamp"`
+}
+
+/*
+The Bus interface defines an in-process publish/subscribe channel. Every subscriber receives a
+copy of every event published after it subscribed, optionally restricted to some event types.
+*/
+type Bus interface {
+	Publish(event Event)
+	Subscribe(types ...string) (<-chan Event, func())
+	Close()
+}
+
+// The subscription struct holds the channel of a subscriber and the event types it accepts.
+type subscription struct {
+	events chan Event
+	types  map[string]bool
+}
+
+// The bus struct is the implementation of the Bus interface.
+type bus struct {
+	mu            sync.RWMutex
+	subscriptions map[*subscription]struct{}
+	closed        bool
+}
+
+// The NewBus function returns a new, empty event bus.
+func NewBus() Bus {
+	return &bus{
+		subscriptions: make(map[*subscription]struct{}),
+	}
+}
+
+/*
+The Publish method delivers the event to every interested subscriber. Publishing never blocks: if
+a subscriber is not keeping up and its buffer is full, the event is dropped for that subscriber.
+*/
+func (b *bus) Publish(event Event) {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for s := range b.subscriptions {
+		if len(s.types) > 0 && !s.types[event.Type] {
+			continue
+		}
+
+		select {
+		case s.events <- event:
+		default:
+			log.Printf("events: subscriber buffer full, dropping %s event\n", event.Type)
+		}
+	}
+}
+
+/*
+The Subscribe method registers a new subscriber for the given event types, or for every event if
+no type is given. It returns the channel where the events are delivered and a function that must
+be called to unsubscribe, which also closes the channel.
+*/
+func (b *bus) Subscribe(types ...string) (<-chan Event, func()) {
+	s := &subscription{
+		events: make(chan Event, subscriberBuffer),
+		types:  make(map[string]bool, len(types)),
+	}
+	for _, t := range types {
+		s.types[t] = true
+	}
+
+	b.mu.Lock()
+	if b.closed {
+		close(s.events)
+	} else {
+		b.subscriptions[s] = struct{}{}
+	}
+	b.mu.Unlock()
+
+	unsubscribe := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		if _, ok := b.subscriptions[s]; ok {
+			delete(b.subscriptions, s)
+			close(s.events)
+		}
+	}
+
+	return s.events, unsubscribe
+}
+
+/*
+The Close method closes the channel of every subscriber, which ends their receiving loops. Events
+published after closing the bus are discarded.
+*/
+func (b *bus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for s := range b.subscriptions {
+		delete(b.subscriptions, s)
+		close(s.events)
+	}
+}
+
+/*
+The Listen function subscribes to the given event types and calls handler with every received
+event, until the bus is closed. A single goroutine handles the events one after the other, in the
+order they were published, so a slow handler fills the buffer of its subscription and the events
+published meanwhile are dropped, as explained in Publish. A panic inside the handler is logged and
+does not stop the listener.
+*/
+func Listen(b Bus, name string, handler func(event Event), types ...string) {
+	subscription, _ := b.Subscribe(types...)
+
+	go func() {
+		for event := range subscription {
+			handle(name, handler, event)
+		}
+	}()
+}
+
+// Auxiliary function that calls an event handler recovering from its panics.
+func handle(name string, handler func(event Event), event Event) {
+	defer func() {
+		if err := recover(); err != nil {
+			log.Printf("events: %s handler panicked on %s event: %v\n", name, event.Type, err)
+		}
+	}()
+
+	handler(event)
+}
@@ -0,0 +1,51 @@
+package events
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestBus_SubscribeFiltersTypes(t *testing.T) {
+	bus := NewBus()
+	all, unsubscribeAll := bus.Subscribe()
+	defer unsubscribeAll()
+	created, unsubscribeCreated := bus.Subscribe("product.created")
+	defer unsubscribeCreated()
+
+	bus.Publish(Event{Type: "product.updated", Id: 1})
+	bus.Publish(Event{Type: "product.created", Id: 2})
+
+	// Assertions
+	assert.Equal(t, "product.updated", (<-all).Type)
+	assert.Equal(t, "product.created", (<-all).Type)
+
+	event := <-created
+	assert.Equal(t, 2, event.Id)
+	assert.False(t, event.Timestamp.IsZero())
+	assert.Len(t, created, 0)
+}
+
+func TestBus_Close(t *testing.T) {
+	bus := NewBus()
+	subscription, _ := bus.Subscribe()
+
+	received := make(chan Event, 1)
+	Listen(bus, "test", func(event Event) {
+		received <- event
+	})
+	bus.Publish(Event{Type: "product.deleted", Id: 3})
+	bus.Close()
+
+	// Assertions
+	select {
+	case event := <-received:
+		assert.Equal(t, 3, event.Id)
+	case <-time.After(time.Second):
+		t.Fatal("listener did not receive the event")
+	}
+
+	<-subscription
+	_, open := <-subscription
+	assert.False(t, open)
+}
@@ -0,0 +1,71 @@
+package export
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrInvalidBarcode = errors.New("text cannot be encoded as a Code 128 barcode")
+
+/*
+Widths of the bars and spaces of the Code 128 symbols, by value: 0 to 102 are the characters, 103
+to 105 the start symbols of the code sets A, B and C.
+*/
+var code128Patterns = []string{
+	"212222", "222122", "222221", "121223", "121322", "131222", "122213", "122312",
+	"132212", "221213", "221312", "231212", "112232", "122132", "122231", "113222",
+	"123122", "123221", "223211", "221132", "221231", "213212", "223112", "312131",
+	"311222", "321122", "321221", "312212", "322112", "322211", "212123", "212321",
+	"232121", "111323", "131123", "131321", "112313", "132113", "132311", "211313",
+	"231113", "231311", "112133", "112331", "132131", "113123", "113321", "133121",
+	"313121", "211331", "231131", "213113", "213311", "213131", "311123", "311321",
+	"331121", "312113", "312311", "332111", "314111", "221411", "431111", "111224",
+	"111422", "121124", "121421", "141122", "141221", "112214", "112412", "122114",
+	"122411", "142112", "142211", "241211", "221114", "413111", "241112", "134111",
+	"111242", "121142", "121241", "114212", "124112", "124211", "411212", "421112",
+	"421211", "212141", "214121", "412121", "111143", "111341", "131141", "114113",
+	"114311", "411113", "411311", "113141", "114131", "311141", "411131", "211412",
+	"211214", "211232",
+}
+
+// Values of the start symbol of the code set B and the width of the stop symbol, which ends every barcode.
+const (
+	code128StartB = 104
+	code128Stop   = "2331112"
+)
+
+/*
+The code128 function returns the widths, in modules, of the bars and spaces of the Code 128 barcode
+of the text, starting with a bar. The text is encoded in the code set B, so it must only have
+printable ASCII characters, like the code values do.
+*/
+func code128(text string) ([]int, error) {
+	if text == "" {
+		return nil, fmt.Errorf("%w: empty text", ErrInvalidBarcode)
+	}
+	values := []int{code128StartB}
+	checksum := code128StartB
+	for i, char := range text {
+		if char < ' ' || char > '~' {
+			return nil, fmt.Errorf("%w: character %q", ErrInvalidBarcode, char)
+		}
+		value := int(char - ' ')
+		values = append(values, value)
+		checksum += (i + 1) * value
+	}
+	values = append(values, checksum%103)
+
+	var widths []int
+	for _, value := range values {
+		widths = appendWidths(widths, code128Patterns[value])
+	}
+	return appendWidths(widths, code128Stop), nil
+}
+
+// Auxiliary function that appends the widths of a pattern, one digit each, to the given ones.
+func appendWidths(widths []int, pattern string) []int {
+	for _, digit := range pattern {
+		widths = append(widths, int(digit-'0'))
+	}
+	return widths
+}
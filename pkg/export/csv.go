@@ -0,0 +1,188 @@
+package export
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Header contains the column names used by every export format.
+var Header = []string{"id", "name", "quantity", "code_value", "status", "expiration", "price", "currency", "category", "description"}
+
+/*
+The RenamedHeader function returns a copy of Header with the columns renamed by the given function,
+like the names of the fields in a version of the API.
+*/
+func RenamedHeader(rename func(name string) string) []string {
+	header := make([]string, len(Header))
+	for i, name := range Header {
+		header[i] = rename(name)
+	}
+	return header
+}
+
+// Columns that every imported CSV file must have.
+var requiredColumns = []string{"name", "quantity", "code_value", "expiration", "price"}
+
+var ErrInvalidRecord = errors.New("invalid CSV record")
+
+/*
+The WriteCSV function writes the given products to w as CSV, one product per row, preceded by
+a header row with the given column names in the order of Header. Rows are flushed as they are
+written, so large catalogs are not buffered in memory.
+*/
+func WriteCSV(w io.Writer, header []string, products []domain.Product) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, product := range products {
+		if err := writer.Write(record(product)); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+/*
+Auxiliary function that converts a product into a slice of strings following the Header order, with
+its text neutralized so a spreadsheet never evaluates it as a formula.
+*/
+func record(product domain.Product) []string {
+	return []string{
+		strconv.Itoa(product.Id),
+		neutralize(product.Name),
+		strconv.Itoa(product.Quantity),
+		neutralize(product.CodeValue),
+		string(product.Status.OrDraft()),
+		neutralize(product.Expiration),
+		strconv.FormatFloat(product.Price, 'f', -1, 64),
+		neutralize(product.Currency),
+		neutralize(product.Category),
+		neutralize(product.Description),
+	}
+}
+
+/*
+Auxiliary function that prefixes with a quote the text a spreadsheet would evaluate as a formula:
+starting with =, +, -, @, a tab or a carriage return. The text already starting with a quote before
+one of them is quoted again, so restore always gives back the original text.
+*/
+func neutralize(text string) string {
+	if formula(text) {
+		return "'" + text
+	}
+	return text
+}
+
+// Auxiliary function that returns the original text of a text neutralized with neutralize.
+func restore(text string) string {
+	if strings.HasPrefix(text, "'") && formula(text[1:]) {
+		return text[1:]
+	}
+	return text
+}
+
+// Auxiliary function that reports whether a text must be neutralized.
+func formula(text string) bool {
+	if text == "" {
+		return false
+	}
+	switch text[0] {
+	case '=', '+', '-', '@', '\t', '\r':
+		return true
+	case '\'':
+		return formula(text[1:])
+	}
+	return false
+}
+
+/*
+The ReadCSV function reads the products of a CSV file with a header row naming its columns, in any
+order, like the files written by WriteCSV. The id column is read if present, and so are the
+optional status, category, description and currency columns. The is_published column of the files
+written before the status is read as the published or draft status. The text neutralized by
+WriteCSV is restored. Errors report the line of the failing record.
+*/
+func ReadCSV(r io.Reader) ([]domain.Product, error) {
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: reading the header: %s", ErrInvalidRecord, err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[name] = i
+	}
+	for _, name := range requiredColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%w: missing column %s", ErrInvalidRecord, name)
+		}
+	}
+
+	var products []domain.Product
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return products, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %s", ErrInvalidRecord, line, err)
+		}
+
+		product, err := parseRecord(row, columns)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %s", ErrInvalidRecord, line, err)
+		}
+		products = append(products, product)
+	}
+}
+
+// Auxiliary function that converts a CSV row into a product, using the column positions of the header.
+func parseRecord(row []string, columns map[string]int) (domain.Product, error) {
+	value := func(name string) string {
+		if i, ok := columns[name]; ok && i < len(row) {
+			return restore(row[i])
+		}
+		return ""
+	}
+
+	var product domain.Product
+	var err error
+	if id := value("id"); id != "" {
+		if product.Id, err = strconv.Atoi(id); err != nil {
+			return product, fmt.Errorf("invalid id %q", id)
+		}
+	}
+	if product.Quantity, err = strconv.Atoi(value("quantity")); err != nil {
+		return product, fmt.Errorf("invalid quantity %q", value("quantity"))
+	}
+	if product.Price, err = strconv.ParseFloat(value("price"), 64); err != nil {
+		return product, fmt.Errorf("invalid price %q", value("price"))
+	}
+	if published := value("is_published"); published != "" {
+		isPublished, err := strconv.ParseBool(published)
+		if err != nil {
+			return product, fmt.Errorf("invalid is_published %q", published)
+		}
+		product.Status = domain.StatusOf(isPublished)
+	}
+	if status := value("status"); status != "" {
+		product.Status = domain.Status(status)
+	}
+	product.Name = value("name")
+	product.CodeValue = value("code_value")
+	product.Expiration = value("expiration")
+	product.Category = value("category")
+	product.Description = value("description")
+	product.Currency = value("currency")
+	return product, nil
+}
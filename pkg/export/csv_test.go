@@ -0,0 +1,50 @@
+package export
+
+import (
+	"bytes"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestReadCSV(t *testing.T) {
+	products := []domain.Product{
+		{Id: 1, Name: "Oil, Margarine", Quantity: 10, CodeValue: "A1", Status: domain.StatusPublished, Expiration: "15/12/2030", Price: 71.42},
+		{Id: 2, Name: "Rice", Quantity: 5, CodeValue: "B2", Status: domain.StatusDraft, Expiration: "15/12/2030", Price: 10},
+	}
+	var buffer bytes.Buffer
+	if err := WriteCSV(&buffer, Header, products); err != nil {
+		panic(err)
+	}
+
+	read, err := ReadCSV(&buffer)
+	_, errMissing := ReadCSV(strings.NewReader("name,quantity\nRice,5\n"))
+	_, errInvalid := ReadCSV(strings.NewReader("name,quantity,code_value,expiration,price\nRice,five,B2,15/12/2030,10\n"))
+
+	// Assertions
+	assert.NoError(t, err)
+	assert.Equal(t, products, read)
+	assert.ErrorIs(t, errMissing, ErrInvalidRecord)
+	assert.EqualError(t, errInvalid, `invalid CSV record: line 2: invalid quantity "five"`)
+}
+
+func TestWriteCSV_Formulas(t *testing.T) {
+	products := []domain.Product{
+		{Id: 1, Name: "=HYPERLINK(\"http://evil\")", Quantity: 10, CodeValue: "@A1", Status: domain.StatusPublished, Expiration: "15/12/2030", Price: 71.42, Category: "+oils", Description: "-1 for\tfrying"},
+		{Id: 2, Name: "'=quoted", Quantity: 5, CodeValue: "\tB2", Status: domain.StatusDraft, Expiration: "15/12/2030", Price: -10, Category: "'rice", Description: "\rLong grain"},
+	}
+	var buffer bytes.Buffer
+	if err := WriteCSV(&buffer, Header, products); err != nil {
+		panic(err)
+	}
+	written := buffer.String()
+
+	read, err := ReadCSV(&buffer)
+
+	// Assertions
+	assert.Contains(t, written, `"'=HYPERLINK(""http://evil"")",10,'@A1,published,15/12/2030,71.42,,'+oils,'-1 for`)
+	assert.Contains(t, written, "''=quoted,5,'\tB2,draft,15/12/2030,-10,,'rice,\"'\rLong grain\"")
+	assert.NoError(t, err)
+	assert.Equal(t, products, read)
+}
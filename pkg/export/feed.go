@@ -0,0 +1,93 @@
+package export
+
+import (
+	"encoding/xml"
+	"fmt"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"io"
+	"strconv"
+)
+
+// Namespace of the product attributes of a Google Merchant Center feed.
+const merchantNamespace = "http://base.google.com/ns/1.0"
+
+// The merchantFeed struct is the root element of a Google Merchant Center feed, an RSS 2.0 document.
+type merchantFeed struct {
+	XMLName   xml.Name        `xml:"rss"`
+	Version   string          `xml:"version,attr"`
+	Namespace string          `xml:"xmlns:g,attr"`
+	Channel   merchantChannel `xml:"channel"`
+}
+
+// The merchantChannel struct describes the store of a Google Merchant Center feed and holds its products.
+type merchantChannel struct {
+	Title       string         `xml:"title"`
+	Link        string         `xml:"link"`
+	Description string         `xml:"description"`
+	Items       []merchantItem `xml:"item"`
+}
+
+// The merchantItem struct is a product of a Google Merchant Center feed.
+type merchantItem struct {
+	Id           string `xml:"g:id"`
+	Title        string `xml:"title"`
+	Description  string `xml:"description"`
+	Link         string `xml:"link"`
+	Price        string `xml:"g:price"`
+	Availability string `xml:"g:availability"`
+	Condition    string `xml:"g:condition"`
+	MPN          string `xml:"g:mpn"`
+	ProductType  string `xml:"g:product_type,omitempty"`
+	// Content of the package and the unit the price per unit is shown for, like 0.5kg and 1kg
+	UnitPricingMeasure     string `xml:"g:unit_pricing_measure,omitempty"`
+	UnitPricingBaseMeasure string `xml:"g:unit_pricing_base_measure,omitempty"`
+}
+
+// Units of measure of the unit pricing attributes of the feeds, by the unit of the products.
+var merchantUnits = map[domain.Unit]string{
+	domain.UnitKilogram: "kg",
+	domain.UnitLiter:    "l",
+	domain.UnitPiece:    "ct",
+}
+
+/*
+The WriteMerchantFeed function writes the given products to w as a Google Merchant Center product
+feed (RSS 2.0 with the g: attributes) of the store with the given title and link. The link of every
+product, to its page in the store, is returned by productLink. The products are new, in stock while
+their quantity is positive, and identified by their code value as manufacturer part number. The
+products with a unit of measure have the unit pricing attributes, for the price per unit.
+*/
+func WriteMerchantFeed(w io.Writer, title, link string, products []domain.Product, productLink func(domain.Product) string) error {
+	feed := merchantFeed{
+		Version:   "2.0",
+		Namespace: merchantNamespace,
+		Channel: merchantChannel{
+			Title:       title,
+			Link:        link,
+			Description: title + " products",
+			Items:       make([]merchantItem, len(products)),
+		},
+	}
+	for i, product := range products {
+		availability := "in_stock"
+		if product.Quantity <= 0 {
+			availability = "out_of_stock"
+		}
+		feed.Channel.Items[i] = merchantItem{
+			Id:           fmt.Sprint(product.Id),
+			Title:        product.Name,
+			Description:  product.Name,
+			Link:         productLink(product),
+			Price:        fmt.Sprintf("%.2f %s", product.Price, product.PriceCurrency()),
+			Availability: availability,
+			Condition:    "new",
+			MPN:          product.CodeValue,
+			ProductType:  product.Category,
+		}
+		if unit, ok := merchantUnits[product.Unit]; ok && product.PackSize > 0 {
+			feed.Channel.Items[i].UnitPricingMeasure = strconv.FormatFloat(product.PackSize, 'f', -1, 64) + unit
+			feed.Channel.Items[i].UnitPricingBaseMeasure = "1" + unit
+		}
+	}
+	return writeXML(w, feed)
+}
@@ -0,0 +1,46 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestWriteMerchantFeed(t *testing.T) {
+	products := []domain.Product{
+		{Id: 1, Name: "Oil & Margarine", Quantity: 10, CodeValue: "A1", Status: domain.StatusPublished, Expiration: "15/12/2030", Price: 71.42, Category: "pantry"},
+		{Id: 2, Name: "Rice", CodeValue: "B2", Status: domain.StatusPublished, Expiration: "15/12/2030", Price: 10, Currency: "CLP", Unit: domain.UnitKilogram, PackSize: 0.5},
+	}
+	var feed, sitemap bytes.Buffer
+
+	err := WriteMerchantFeed(&feed, "Store", "https://shop.test/catalog", products, func(product domain.Product) string {
+		return fmt.Sprintf("https://shop.test/catalog/%d", product.Id)
+	})
+	errSitemap := WriteSitemap(&sitemap, []string{"https://shop.test/catalog", "https://shop.test/catalog/1"})
+
+	// Assertions
+	assert.NoError(t, err)
+	assert.Contains(t, feed.String(), `<rss version="2.0" xmlns:g="http://base.google.com/ns/1.0">`)
+	assert.Contains(t, feed.String(), "<title>Oil &amp; Margarine</title>")
+	assert.Contains(t, feed.String(), "<g:price>71.42 USD</g:price>")
+	assert.Contains(t, feed.String(), "<g:product_type>pantry</g:product_type>")
+	assert.Contains(t, feed.String(), "<g:price>10.00 CLP</g:price>")
+	assert.Contains(t, feed.String(), "<g:availability>out_of_stock</g:availability>")
+	assert.Contains(t, feed.String(), "<g:unit_pricing_measure>0.5kg</g:unit_pricing_measure>")
+	assert.Contains(t, feed.String(), "<g:unit_pricing_base_measure>1kg</g:unit_pricing_base_measure>")
+	assert.Equal(t, 1, strings.Count(feed.String(), "<g:unit_pricing_measure>"))
+	assert.NoError(t, errSitemap)
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url>
+    <loc>https://shop.test/catalog</loc>
+  </url>
+  <url>
+    <loc>https://shop.test/catalog/1</loc>
+  </url>
+</urlset>
+`, sitemap.String())
+}
@@ -0,0 +1,97 @@
+package export
+
+import (
+	"fmt"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"io"
+)
+
+// Layout of the shelf labels: an A4 sheet of 3 by 8 labels of 70 x 37 mm, without margins.
+const (
+	labelColumns = 3
+	labelRows    = 8
+	labelWidth   = a4Width / labelColumns
+	labelHeight  = a4Height / labelRows
+	// Blank space around the content of every label, in points
+	labelPadding = 10
+	// Height of the barcode bars and widest module, in points, which is narrower for the long code values
+	barcodeHeight      = 24
+	barcodeModuleWidth = 1.0
+	// Approximate width of the characters of Helvetica relative to the font size, to fit the names
+	averageCharWidth = 0.5
+)
+
+// The LabelsPerPage constant is the number of labels printed on every page of WriteLabels.
+const LabelsPerPage = labelColumns * labelRows
+
+/*
+The WriteLabels function writes the shelf labels of the given products to w as a printable PDF
+document of A4 pages with LabelsPerPage labels each, in the order of the products. Every label has
+the name of the product, its price and currency, its price per unit if it has a unit of measure,
+and the Code 128 barcode of its code value. It
+returns ErrInvalidBarcode, before writing anything, if a code value cannot be encoded.
+*/
+func WriteLabels(w io.Writer, products []domain.Product) error {
+	barcodes := make([][]int, len(products))
+	for i, product := range products {
+		widths, err := code128(product.CodeValue)
+		if err != nil {
+			return fmt.Errorf("product %d: %w", product.Id, err)
+		}
+		barcodes[i] = widths
+	}
+
+	pages := []*pdfPage{{}}
+	for i, product := range products {
+		if i > 0 && i%LabelsPerPage == 0 {
+			pages = append(pages, &pdfPage{})
+		}
+		position := i % LabelsPerPage
+		x := float64(position%labelColumns) * labelWidth
+		top := a4Height - float64(position/labelColumns)*labelHeight
+		drawLabel(pages[len(pages)-1], x, top, product, barcodes[i])
+	}
+	return writePDF(w, pages)
+}
+
+// Auxiliary function that draws the label of a product with its top left corner at the given point.
+func drawLabel(page *pdfPage, x, top float64, product domain.Product, barcode []int) {
+	page.strokeRect(x, top-labelHeight, labelWidth, labelHeight)
+	left := x + labelPadding
+	contentWidth := labelWidth - 2*labelPadding
+
+	page.text(left, top-labelPadding-10, fontRegular, 10, fitText(product.Name, contentWidth, 10))
+	page.text(left, top-labelPadding-32, fontBold, 18, fmt.Sprintf("%.2f %s", product.Price, product.PriceCurrency()))
+	if unitPrice, ok := product.UnitPrice(); ok {
+		page.text(left, top-labelPadding-42, fontRegular, 7, fmt.Sprintf("%.2f %s/%s", unitPrice, product.PriceCurrency(), product.Unit))
+	}
+
+	modules := 0
+	for _, width := range barcode {
+		modules += width
+	}
+	moduleWidth := contentWidth / float64(modules)
+	if moduleWidth > barcodeModuleWidth {
+		moduleWidth = barcodeModuleWidth
+	}
+	bottom := top - labelPadding - 48 - barcodeHeight
+	position := left
+	for i, width := range barcode {
+		// The widths alternate between bars and spaces, starting with a bar
+		if i%2 == 0 {
+			page.fillRect(position, bottom, float64(width)*moduleWidth, barcodeHeight)
+		}
+		position += float64(width) * moduleWidth
+	}
+	page.text(left, bottom-9, fontRegular, 7, product.CodeValue)
+}
+
+// Auxiliary function that shortens a text with an ellipsis so it fits in the given width at the font size.
+func fitText(text string, width, size float64) string {
+	maxChars := int(width / (size * averageCharWidth))
+	runes := []rune(text)
+	if len(runes) <= maxChars {
+		return text
+	}
+	return string(runes[:maxChars-3]) + "..."
+}
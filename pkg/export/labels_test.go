@@ -0,0 +1,42 @@
+package export
+
+import (
+	"bytes"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestWriteLabels(t *testing.T) {
+	products := make([]domain.Product, LabelsPerPage+1)
+	for i := range products {
+		products[i] = domain.Product{Id: i + 1, Name: "Piña (fresh)", CodeValue: "A1", Price: 71.42}
+	}
+	products[0].Name = strings.Repeat("Long name ", 10)
+	products[1].Unit, products[1].PackSize = domain.UnitKilogram, 0.25
+	var document bytes.Buffer
+
+	err := WriteLabels(&document, products)
+	errBarcode := WriteLabels(&bytes.Buffer{}, []domain.Product{{Id: 7, CodeValue: "Piña"}})
+	barcode, _ := code128("A1")
+	modules := 0
+	for _, width := range barcode {
+		modules += width
+	}
+
+	// Assertions
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(document.String(), "%PDF-1.4"))
+	assert.True(t, strings.HasSuffix(document.String(), "%%EOF\n"))
+	assert.Contains(t, document.String(), "/Count 2")
+	assert.Contains(t, document.String(), `(Pi\361a \(fresh\)) Tj`)
+	assert.Contains(t, document.String(), "(71.42 USD) Tj")
+	assert.Contains(t, document.String(), "(285.68 USD/kg) Tj")
+	assert.Equal(t, 1, strings.Count(document.String(), "USD/kg"))
+	assert.Contains(t, document.String(), "(Long name Long name Long name Lo...) Tj")
+	assert.ErrorIs(t, errBarcode, ErrInvalidBarcode)
+	// Start, 2 characters, checksum (68) and stop
+	assert.Equal(t, 11*4+13, modules)
+	assert.Equal(t, []int{1, 4, 1, 2, 2, 1}, barcode[18:24])
+}
@@ -0,0 +1,37 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// Number of records written between two flushes of the underlying writer.
+const ndjsonFlushEvery = 100
+
+/*
+The WriteNDJSON function writes the given records to w as newline-delimited JSON, one record per
+line, like the products or their representation in a version of the API. If w is an http.Flusher,
+it is flushed periodically so the client receives the records as chunks instead of waiting for the
+whole catalog.
+*/
+func WriteNDJSON[T any](w io.Writer, records []T) error {
+	encoder := json.NewEncoder(w)
+	flusher, canFlush := w.(http.Flusher)
+
+	for i, record := range records {
+		// The encoder terminates every value with a newline
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+
+		if canFlush && (i+1)%ndjsonFlushEvery == 0 {
+			flusher.Flush()
+		}
+	}
+
+	if canFlush {
+		flusher.Flush()
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Size of an A4 page in points, the unit of the PDF coordinates, which start at the bottom left corner.
+const (
+	a4Width  = 595.28
+	a4Height = 841.89
+)
+
+// Fonts of the PDF documents, by resource name. They are standard fonts, so they are never embedded.
+const (
+	fontRegular = "F1"
+	fontBold    = "F2"
+)
+
+// The pdfPage struct is a page of a PDF document being drawn, as the operators of its content stream.
+type pdfPage struct {
+	content bytes.Buffer
+}
+
+// The text method draws a line of text with its baseline starting at the given point.
+func (p *pdfPage) text(x, y float64, font string, size float64, text string) {
+	fmt.Fprintf(&p.content, "BT /%s %.2f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, pdfString(text))
+}
+
+// The fillRect method draws a black rectangle with its bottom left corner at the given point.
+func (p *pdfPage) fillRect(x, y, width, height float64) {
+	fmt.Fprintf(&p.content, "%.3f %.2f %.3f %.2f re f\n", x, y, width, height)
+}
+
+// The strokeRect method draws the light gray outline of a rectangle, like the cut guides of the labels.
+func (p *pdfPage) strokeRect(x, y, width, height float64) {
+	fmt.Fprintf(&p.content, "0.8 G 0.25 w %.2f %.2f %.2f %.2f re S 0 G\n", x, y, width, height)
+}
+
+/*
+The writePDF function writes a PDF document with the given A4 pages to w. The document is built in
+memory, since the cross-reference table at its end needs the offset of every object.
+*/
+func writePDF(w io.Writer, pages []*pdfPage) error {
+	var buffer bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, buffer.Len())
+		fmt.Fprintf(&buffer, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	// The binary comment tells the transfer programs that the file is not text
+	buffer.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	for i, page := range pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] "+
+			"/Resources << /Font << /%s 3 0 R /%s 4 0 R >> >> /Contents %d 0 R >>", a4Width, a4Height, fontRegular, fontBold, 6+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", page.content.Len(), page.content.Bytes()))
+	}
+
+	xref := buffer.Len()
+	fmt.Fprintf(&buffer, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buffer, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buffer, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	_, err := buffer.WriteTo(w)
+	return err
+}
+
+/*
+Auxiliary function that returns a text as the content of a PDF string in the WinAnsi encoding of the
+fonts. The characters out of the encoding are replaced with a question mark.
+*/
+func pdfString(text string) string {
+	var builder strings.Builder
+	for _, char := range text {
+		switch {
+		case char == '(' || char == ')' || char == '\\':
+			builder.WriteByte('\\')
+			builder.WriteRune(char)
+		case char == '€':
+			builder.WriteString(`\200`)
+		case char >= ' ' && char <= '~':
+			builder.WriteRune(char)
+		case char >= 0xa0 && char <= 0xff:
+			// Latin-1 characters have the same code in WinAnsi, written in octal to keep the content ASCII
+			fmt.Fprintf(&builder, `\%03o`, char)
+		default:
+			builder.WriteByte('?')
+		}
+	}
+	return builder.String()
+}
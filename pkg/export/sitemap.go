@@ -0,0 +1,53 @@
+package export
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+// Maximum number of URLs of a sitemap file, set by the sitemaps protocol.
+const MaxSitemapURLs = 50000
+
+// Namespace of the elements of a sitemap file.
+const sitemapNamespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// The sitemap struct is the root element of a sitemap file.
+type sitemap struct {
+	XMLName   xml.Name     `xml:"urlset"`
+	Namespace string       `xml:"xmlns,attr"`
+	URLs      []sitemapURL `xml:"url"`
+}
+
+// The sitemapURL struct is a page listed in a sitemap file.
+type sitemapURL struct {
+	Location string `xml:"loc"`
+}
+
+/*
+The WriteSitemap function writes the given absolute URLs to w as a sitemap file, following the
+sitemaps protocol. Only the first MaxSitemapURLs URLs are written, the limit of a single file.
+*/
+func WriteSitemap(w io.Writer, urls []string) error {
+	if len(urls) > MaxSitemapURLs {
+		urls = urls[:MaxSitemapURLs]
+	}
+	document := sitemap{Namespace: sitemapNamespace, URLs: make([]sitemapURL, len(urls))}
+	for i, url := range urls {
+		document.URLs[i].Location = url
+	}
+	return writeXML(w, document)
+}
+
+// Auxiliary function that writes an XML document to w, preceded by the XML declaration.
+func writeXML(w io.Writer, document interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(document); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,156 @@
+package export
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"io"
+	"strconv"
+)
+
+// Static parts of a minimal Office Open XML workbook with a single worksheet.
+const (
+	contentTypesXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+</Types>`
+
+	rootRelsXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`
+
+	workbookXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Products" sheetId="1" r:id="rId1"/></sheets>
+</workbook>`
+
+	workbookRelsXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+</Relationships>`
+
+	sheetHeaderXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+
+	sheetFooterXML = `</sheetData></worksheet>`
+)
+
+/*
+The WriteXLSX function writes the given products to w as an Excel workbook with a single
+"Products" sheet, whose header row has the given column names in the order of Header. The
+worksheet is streamed row by row into the zip archive, with the text neutralized like in WriteCSV.
+*/
+func WriteXLSX(w io.Writer, header []string, products []domain.Product) error {
+	archive := zip.NewWriter(w)
+
+	// Write the static parts of the workbook
+	staticParts := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", contentTypesXML},
+		{"_rels/.rels", rootRelsXML},
+		{"xl/workbook.xml", workbookXML},
+		{"xl/_rels/workbook.xml.rels", workbookRelsXML},
+	}
+	for _, part := range staticParts {
+		file, err := archive.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err = io.WriteString(file, part.content); err != nil {
+			return err
+		}
+	}
+
+	// Write the worksheet, starting with the header row
+	sheet, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	if _, err = io.WriteString(sheet, sheetHeaderXML); err != nil {
+		return err
+	}
+
+	headerCells := make([]cell, len(header))
+	for i, name := range header {
+		headerCells[i] = cell{kind: "inlineStr", value: name}
+	}
+	if err = writeRow(sheet, 1, headerCells); err != nil {
+		return err
+	}
+
+	for i, product := range products {
+		cells := []cell{
+			{kind: "n", value: strconv.Itoa(product.Id)},
+			{kind: "inlineStr", value: neutralize(product.Name)},
+			{kind: "n", value: strconv.Itoa(product.Quantity)},
+			{kind: "inlineStr", value: neutralize(product.CodeValue)},
+			{kind: "inlineStr", value: string(product.Status.OrDraft())},
+			{kind: "inlineStr", value: neutralize(product.Expiration)},
+			{kind: "n", value: strconv.FormatFloat(product.Price, 'f', -1, 64)},
+			{kind: "inlineStr", value: neutralize(product.Currency)},
+			{kind: "inlineStr", value: neutralize(product.Category)},
+			{kind: "inlineStr", value: neutralize(product.Description)},
+		}
+		if err = writeRow(sheet, i+2, cells); err != nil {
+			return err
+		}
+	}
+
+	if _, err = io.WriteString(sheet, sheetFooterXML); err != nil {
+		return err
+	}
+
+	return archive.Close()
+}
+
+// The cell struct represents a single worksheet cell and its SpreadsheetML type.
+type cell struct {
+	kind  string
+	value string
+}
+
+// Auxiliary function that writes a single worksheet row.
+func writeRow(w io.Writer, row int, cells []cell) error {
+	if _, err := fmt.Fprintf(w, `<row r="%d">`, row); err != nil {
+		return err
+	}
+
+	for i, c := range cells {
+		ref := columnName(i) + strconv.Itoa(row)
+		if c.kind == "inlineStr" {
+			if _, err := fmt.Fprintf(w, `<c r="%s" t="inlineStr"><is><t>`, ref); err != nil {
+				return err
+			}
+			if err := xml.EscapeText(w, []byte(c.value)); err != nil {
+				return err
+			}
+			if _, err := io.WriteString(w, `</t></is></c>`); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if _, err := fmt.Fprintf(w, `<c r="%s" t="%s"><v>%s</v></c>`, ref, c.kind, c.value); err != nil {
+			return err
+		}
+	}
+
+	_, err := io.WriteString(w, `</row>`)
+	return err
+}
+
+// Auxiliary function that returns the name of the column of the given index from 0: A to Z, then AA, AB and so on.
+func columnName(index int) string {
+	name := ""
+	for index++; index > 0; index = (index - 1) / 26 {
+		name = string(rune('A'+(index-1)%26)) + name
+	}
+	return name
+}
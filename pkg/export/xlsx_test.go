@@ -0,0 +1,49 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+)
+
+func TestWriteXLSX_Formulas(t *testing.T) {
+	products := []domain.Product{
+		{Id: 1, Name: "=1+1", Quantity: 10, CodeValue: "A1", Expiration: "15/12/2030", Price: -5, Category: "@oils", Description: "Plain"},
+	}
+	var buffer bytes.Buffer
+	if err := WriteXLSX(&buffer, Header, products); err != nil {
+		panic(err)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+	if err != nil {
+		panic(err)
+	}
+	file, err := archive.Open("xl/worksheets/sheet1.xml")
+	if err != nil {
+		panic(err)
+	}
+	sheet, err := io.ReadAll(file)
+	if err != nil {
+		panic(err)
+	}
+
+	// Assertions
+	assert.Contains(t, string(sheet), `<c r="B2" t="inlineStr"><is><t>&#39;=1+1</t></is></c>`)
+	assert.Contains(t, string(sheet), `<c r="G2" t="n"><v>-5</v></c>`)
+	assert.Contains(t, string(sheet), `<c r="I2" t="inlineStr"><is><t>&#39;@oils</t></is></c>`)
+	assert.Contains(t, string(sheet), `<c r="J2" t="inlineStr"><is><t>Plain</t></is></c>`)
+}
+
+func TestColumnName(t *testing.T) {
+	// Assertions
+	assert.Equal(t, "A", columnName(0))
+	assert.Equal(t, "Z", columnName(25))
+	assert.Equal(t, "AA", columnName(26))
+	assert.Equal(t, "AZ", columnName(51))
+	assert.Equal(t, "BA", columnName(52))
+	assert.Equal(t, "ZZ", columnName(701))
+	assert.Equal(t, "AAA", columnName(702))
+}
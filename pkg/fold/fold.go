@@ -0,0 +1,26 @@
+package fold
+
+import "strings"
+
+// Replacement of the accented letters by their base letter.
+var accents = strings.NewReplacer(
+	"á", "a", "à", "a", "ä", "a", "â", "a", "ã", "a",
+	"é", "e", "è", "e", "ë", "e", "ê", "e",
+	"í", "i", "ì", "i", "ï", "i", "î", "i",
+	"ó", "o", "ò", "o", "ö", "o", "ô", "o", "õ", "o",
+	"ú", "u", "ù", "u", "ü", "u", "û", "u",
+	"ñ", "n", "ç", "c",
+)
+
+/*
+The String function returns the comparable form of a text, so texts differing only in case, accents
+or spacing are equal: lowercase, without accents and single spaced.
+*/
+func String(text string) string {
+	return strings.Join(Words(text), " ")
+}
+
+// The Words function returns the words of the comparable form of a text.
+func Words(text string) []string {
+	return strings.Fields(accents.Replace(strings.ToLower(text)))
+}
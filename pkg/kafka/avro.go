@@ -0,0 +1,68 @@
+package kafka
+
+import (
+	"encoding/json"
+	"github.com/JoseObreque/go-web/pkg/events"
+	"github.com/JoseObreque/go-web/pkg/outbox"
+	"github.com/linkedin/goavro/v2"
+)
+
+/*
+EventSchema is the Avro schema of the published events. The entity states are carried as JSON
+strings so the schema does not change when the products gain new fields.
+*/
+const EventSchema = `{
+	"type": "record",
+	"name": "Event",
+	"namespace": "com.github.joseobreque.goweb",
+	"fields": [
+		{"name": "type", "type": "string"},
+		{"name": "id", "type": "long"},
+		{"name": "timestamp", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+		{"name": "data", "type": ["null", "string"], "default": null},
+		{"name": "previous", "type": ["null", "string"], "default": null}
+	]
+}`
+
+/*
+The NewAvroEncoder function returns an outbox encoder that serializes the events in Avro binary
+format following the EventSchema.
+*/
+func NewAvroEncoder() (outbox.Encoder, error) {
+	codec, err := goavro.NewCodec(EventSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(event events.Event) ([]byte, error) {
+		data, err := jsonUnion(event.Data)
+		if err != nil {
+			return nil, err
+		}
+		previous, err := jsonUnion(event.Previous)
+		if err != nil {
+			return nil, err
+		}
+
+		return codec.BinaryFromNative(nil, map[string]interface{}{
+			"type":      event.Type,
+			"id":        int64(event.Id),
+			"timestamp": event.Timestamp,
+			"data":      data,
+			"previous":  previous,
+		})
+	}, nil
+}
+
+// Auxiliary function that encodes a value as a nullable Avro string holding its JSON representation.
+func jsonUnion(value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return goavro.Union("string", string(encoded)), nil
+}
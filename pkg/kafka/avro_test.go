@@ -0,0 +1,37 @@
+package kafka
+
+import (
+	"github.com/JoseObreque/go-web/pkg/events"
+	"github.com/linkedin/goavro/v2"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestAvroEncoder(t *testing.T) {
+	encode, err := NewAvroEncoder()
+	if err != nil {
+		panic(err)
+	}
+	codec, err := goavro.NewCodec(EventSchema)
+	if err != nil {
+		panic(err)
+	}
+
+	value, err := encode(events.Event{
+		Type:      "product.created",
+		Id:        7,
+		Data:      map[string]string{"name": "Pineapple"},
+		Timestamp: time.Now(),
+	})
+	native, _, decodeErr := codec.NativeFromBinary(value)
+	record := native.(map[string]interface{})
+
+	// Assertions
+	assert.NoError(t, err)
+	assert.NoError(t, decodeErr)
+	assert.Equal(t, "product.created", record["type"])
+	assert.Equal(t, int64(7), record["id"])
+	assert.Equal(t, map[string]interface{}{"string": `{"name":"Pineapple"}`}, record["data"])
+	assert.Nil(t, record["previous"])
+}
@@ -0,0 +1,49 @@
+package kafka
+
+import (
+	"context"
+	"github.com/JoseObreque/go-web/pkg/outbox"
+	kafkago "github.com/segmentio/kafka-go"
+	"time"
+)
+
+// Header with the type of the event carried by every Kafka message.
+const HeaderEventType = "event-type"
+
+/*
+The Publisher struct publishes outbox messages to a Kafka topic. The product id is used as message
+key, so every change of a product lands in the same partition and is consumed in order.
+*/
+type Publisher struct {
+	writer *kafkago.Writer
+}
+
+// The NewPublisher function returns a Publisher writing to the given topic of the given brokers.
+func NewPublisher(brokers []string, topic string) *Publisher {
+	return &Publisher{
+		writer: &kafkago.Writer{
+			Addr:         kafkago.TCP(brokers...),
+			Topic:        topic,
+			Balancer:     &kafkago.Hash{},
+			RequiredAcks: kafkago.RequireAll,
+			WriteTimeout: 10 * time.Second,
+		},
+	}
+}
+
+// The Publish method writes a message to the topic, waiting for the acknowledgement of every replica.
+func (p *Publisher) Publish(ctx context.Context, message outbox.Message) error {
+	return p.writer.WriteMessages(ctx, kafkago.Message{
+		Key:   []byte(message.Key),
+		Value: message.Value,
+		Time:  message.CreatedAt,
+		Headers: []kafkago.Header{
+			{Key: HeaderEventType, Value: []byte(message.Type)},
+		},
+	})
+}
+
+// The Close method flushes and closes the underlying Kafka writer.
+func (p *Publisher) Close() error {
+	return p.writer.Close()
+}
@@ -0,0 +1,113 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"time"
+)
+
+/*
+The FileLock struct is a Locker backed by a lock file, created exclusively by its holder and removed
+on release. The exclusive creation is atomic on local disks and on NFS, unlike the advisory locks
+of most network file systems. The holder touches the lock file while it holds it, so a lock file
+older than the time to live is left by a crashed holder and is taken over.
+*/
+type FileLock struct {
+	path string
+	ttl  time.Duration
+}
+
+// The NewFileLock function returns a lock backed by the lock file at the given path.
+func NewFileLock(path string, ttl time.Duration) *FileLock {
+	return &FileLock{
+		path: path,
+		ttl:  ttl,
+	}
+}
+
+// The Lock method creates the lock file, waiting while another process holds it.
+func (l *FileLock) Lock(ctx context.Context) (func() error, error) {
+	token := newToken()
+	for {
+		file, err := os.OpenFile(l.path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			_, err = file.WriteString(token)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				_ = os.Remove(l.path)
+				return nil, err
+			}
+			stop := renew(l.ttl, func() error { return l.touch(token) })
+			return func() error {
+				stop()
+				return l.unlock(token)
+			}, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, err
+		}
+
+		// A lock file past its time to live is removed, so the next attempt takes it over
+		if l.takeOver() {
+			continue
+		}
+		if err = wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+}
+
+/*
+Auxiliary method that removes the lock file if it is past its time to live, and reports whether
+the lock may be free. The file is first moved aside under a name of its own, an atomic rename, and
+only removed once the moved file is checked to be the expired one: if another process took over
+the lock meanwhile, the two checks see different files, and the lock file of the new holder is put
+back where it was instead.
+*/
+func (l *FileLock) takeOver() bool {
+	info, err := os.Stat(l.path)
+	if err != nil {
+		return errors.Is(err, fs.ErrNotExist)
+	}
+	if time.Since(info.ModTime()) <= l.ttl {
+		return false
+	}
+
+	moved := l.path + "." + newToken()
+	if err = os.Rename(l.path, moved); err != nil {
+		return errors.Is(err, fs.ErrNotExist)
+	}
+	defer os.Remove(moved)
+	if info, err = os.Stat(moved); err == nil && time.Since(info.ModTime()) <= l.ttl {
+		// A link never replaces a lock file created since then
+		_ = os.Link(moved, l.path)
+		return false
+	}
+	return true
+}
+
+// Auxiliary method that updates the modification time of the lock file, while it is still held with the given token.
+func (l *FileLock) touch(token string) error {
+	holder, err := os.ReadFile(l.path)
+	if err != nil || string(holder) != token {
+		return err
+	}
+	now := time.Now()
+	return os.Chtimes(l.path, now, now)
+}
+
+// Auxiliary method that removes the lock file, unless it was taken over by another process after expiring.
+func (l *FileLock) unlock(token string) error {
+	holder, err := os.ReadFile(l.path)
+	if errors.Is(err, fs.ErrNotExist) || (err == nil && string(holder) != token) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return os.Remove(l.path)
+}
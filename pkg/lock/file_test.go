@@ -0,0 +1,112 @@
+package lock
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFileLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "products.json.lock")
+	first, second := NewFileLock(path, time.Minute), NewFileLock(path, time.Minute)
+
+	unlock, err := first.Lock(context.Background())
+	if err != nil {
+		panic(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, heldErr := second.Lock(ctx)
+	unlockErr := unlock()
+	secondUnlock, secondErr := second.Lock(context.Background())
+
+	// Assertions
+	assert.ErrorIs(t, heldErr, ErrTimeout)
+	assert.NoError(t, unlockErr)
+	assert.NoError(t, secondErr)
+	assert.NoError(t, secondUnlock())
+	assert.NoFileExists(t, path)
+}
+
+func TestFileLock_Expired(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "products.json.lock")
+	crashed := NewFileLock(path, time.Minute)
+	if _, err := crashed.Lock(context.Background()); err != nil {
+		panic(err)
+	}
+	expired := time.Now().Add(-2 * time.Minute)
+	if err := os.Chtimes(path, expired, expired); err != nil {
+		panic(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	unlock, err := NewFileLock(path, time.Minute).Lock(ctx)
+
+	// Assertions
+	assert.NoError(t, err)
+	assert.FileExists(t, path)
+	assert.NoError(t, unlock())
+	assert.NoFileExists(t, path)
+}
+
+func TestFileLock_Renewed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "products.json.lock")
+	holder := NewFileLock(path, 150*time.Millisecond)
+
+	// A holder slower than the time to live keeps the lock
+	unlock, err := holder.Lock(context.Background())
+	if err != nil {
+		panic(err)
+	}
+	time.Sleep(400 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, heldErr := NewFileLock(path, 150*time.Millisecond).Lock(ctx)
+
+	// Assertions
+	assert.ErrorIs(t, heldErr, ErrTimeout)
+	assert.NoError(t, unlock())
+	assert.NoFileExists(t, path)
+}
+
+func TestFileLock_TakenOverOnce(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "products.json.lock")
+	if _, err := NewFileLock(path, time.Minute).Lock(context.Background()); err != nil {
+		panic(err)
+	}
+	expired := time.Now().Add(-2 * time.Minute)
+	if err := os.Chtimes(path, expired, expired); err != nil {
+		panic(err)
+	}
+
+	// The waiters taking over the expired lock hold it one at a time
+	var holders, overlaps int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock, err := NewFileLock(path, time.Minute).Lock(context.Background())
+			if err != nil {
+				panic(err)
+			}
+			if atomic.AddInt32(&holders, 1) > 1 {
+				atomic.AddInt32(&overlaps, 1)
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&holders, -1)
+			_ = unlock()
+		}()
+	}
+	wg.Wait()
+
+	// Assertions
+	assert.Zero(t, overlaps)
+	assert.NoFileExists(t, path)
+}
@@ -0,0 +1,74 @@
+/*
+Package lock coordinates the writes of several instances of the server to a shared resource, like
+a store file on a network volume, with a lock file next to it or a Redis key.
+*/
+package lock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+var ErrTimeout = errors.New("lock not acquired in time")
+
+/*
+The Locker interface defines a lock shared by several processes. Lock blocks until the lock is
+acquired or the context is done, and returns the function releasing it. A lock expires after its
+time to live, so a crashed holder never blocks the others for good, and is renewed in the
+background while it is held, so a holder slower than the time to live keeps it.
+*/
+type Locker interface {
+	Lock(ctx context.Context) (unlock func() error, err error)
+}
+
+// Interval between two attempts to acquire a lock held by another process.
+const retryInterval = 50 * time.Millisecond
+
+// Auxiliary function that returns a random token identifying a holder of a lock.
+func newToken() string {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(token)
+}
+
+// Auxiliary function that waits before the next attempt to acquire a lock, or returns ErrTimeout if the context is done.
+func wait(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ErrTimeout
+	case <-time.After(retryInterval):
+		return nil
+	}
+}
+
+/*
+Auxiliary function that renews a lock every third of its time to live with the given function,
+until the returned function is called. The failed renewals are retried on the next tick, and the
+renewal never runs after the returned function returns.
+*/
+func renew(ttl time.Duration, fn func() error) func() {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(ttl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				_ = fn()
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
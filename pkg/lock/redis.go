@@ -0,0 +1,71 @@
+package lock
+
+import (
+	"context"
+	"github.com/redis/go-redis/v9"
+	"time"
+)
+
+// Script releasing a lock only if it is still held with the given token.
+var unlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// Script renewing the time to live of a lock only if it is still held with the given token.
+var renewScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+/*
+The RedisLock struct is a Locker backed by a Redis key, set by its holder with its own token and
+a time to live, renewed while it holds it. The key is only renewed and deleted by the holder that
+set it.
+*/
+type RedisLock struct {
+	client *redis.Client
+	key    string
+	ttl    time.Duration
+}
+
+/*
+The NewRedisLock function returns a lock backed by the given key of the Redis server of the URL,
+with the format redis://[user:password@]host:port/db.
+*/
+func NewRedisLock(url, key string, ttl time.Duration) (*RedisLock, error) {
+	options, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, err
+	}
+	return &RedisLock{
+		client: redis.NewClient(options),
+		key:    key,
+		ttl:    ttl,
+	}, nil
+}
+
+// The Lock method sets the key of the lock, waiting while another process holds it.
+func (l *RedisLock) Lock(ctx context.Context) (func() error, error) {
+	token := newToken()
+	for {
+		acquired, err := l.client.SetNX(ctx, l.key, token, l.ttl).Result()
+		if err != nil && ctx.Err() == nil {
+			return nil, err
+		}
+		if acquired {
+			stop := renew(l.ttl, func() error {
+				return renewScript.Run(context.Background(), l.client, []string{l.key}, token, l.ttl.Milliseconds()).Err()
+			})
+			return func() error {
+				stop()
+				return unlockScript.Run(context.Background(), l.client, []string{l.key}, token).Err()
+			}, nil
+		}
+		if err = wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+)
+
+var ErrInvalidLevel = errors.New("invalid log level, expected debug, info, warn or error")
+
+// The Level type is the severity of a log message. Only the messages of the enabled level and above are logged.
+type Level int
+
+// Log levels, from the most to the least verbose. The zero level is info.
+const (
+	LevelDebug Level = iota - 1
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// Names of the levels, used in the settings and as prefix of the messages.
+var levelNames = map[Level]string{
+	LevelDebug: "debug",
+	LevelInfo:  "info",
+	LevelWarn:  "warn",
+	LevelError: "error",
+}
+
+// The ParseLevel function returns the level of the given name, case insensitive. An empty name is info.
+func ParseLevel(name string) (Level, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return LevelInfo, nil
+	}
+	for level, levelName := range levelNames {
+		if levelName == name {
+			return level, nil
+		}
+	}
+	return LevelInfo, ErrInvalidLevel
+}
+
+// The String method returns the name of the level.
+func (l Level) String() string {
+	if name, ok := levelNames[l]; ok {
+		return name
+	}
+	return fmt.Sprintf("level(%d)", int(l))
+}
+
+// The MarshalText method encodes the level as its name.
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// The UnmarshalText method decodes a level from its name.
+func (l *Level) UnmarshalText(text []byte) error {
+	level, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*l = level
+	return nil
+}
+
+// Settings used by the package level log functions.
+var std = NewSettings(Options{})
+
+// The Default function returns the settings used by the package level log functions, like Debugf.
+func Default() *Settings {
+	return std
+}
+
+// The Debugf function logs a message for debugging, only while the debug level is enabled.
+func Debugf(format string, args ...interface{}) {
+	std.output(LevelDebug, format, args...)
+}
+
+// The Infof function logs a message about the regular work of the server.
+func Infof(format string, args ...interface{}) {
+	std.output(LevelInfo, format, args...)
+}
+
+// The Warnf function logs a message about an unexpected problem the server recovered from.
+func Warnf(format string, args ...interface{}) {
+	std.output(LevelWarn, format, args...)
+}
+
+// The Errorf function logs a message about a failure.
+func Errorf(format string, args ...interface{}) {
+	std.output(LevelError, format, args...)
+}
+
+// Auxiliary method that logs a message with the standard logger, prefixed by its level, if the level is enabled.
+func (s *Settings) output(level Level, format string, args ...interface{}) {
+	if !s.Enabled(level) {
+		return
+	}
+	_ = log.Output(3, strings.ToUpper(level.String())+" "+fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,43 @@
+package logging
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestRedactor(t *testing.T) {
+	redactor := NewRedactor("password", " Secret ")
+	headers := http.Header{"Token": {"abc"}, "Content-Type": {"application/json"}}
+
+	// Assertions
+	assert.Equal(t, http.Header{"Token": {Redacted}, "Content-Type": {"application/json"}}, redactor.Headers(headers))
+	assert.Equal(t, "abc", headers.Get("Token"))
+	assert.JSONEq(t,
+		`{"name":"tea","price":1.50,"PASSWORD":"[REDACTED]","items":[{"secret":"[REDACTED]","id":1}]}`,
+		string(redactor.Body([]byte(`{"name":"tea","price":1.50,"PASSWORD":"x","items":[{"secret":{"a":1},"id":1}]}`))))
+	assert.Equal(t, "[16 bytes of incomplete JSON]", string(redactor.Body([]byte(`{"password":"x",`))))
+	assert.Equal(t, "password=x", string(redactor.Body([]byte("password=x"))))
+}
+
+func TestSettings_Levels(t *testing.T) {
+	settings := NewSettings(Options{})
+	var decoded Options
+	err := json.Unmarshal([]byte(`{"level":"WARN","bodies":true}`), &decoded)
+	if err != nil {
+		panic(err)
+	}
+	settings.Apply(decoded)
+	encoded, err := json.Marshal(settings.Options())
+	if err != nil {
+		panic(err)
+	}
+	_, err = ParseLevel("verbose")
+
+	// Assertions
+	assert.False(t, settings.Enabled(LevelInfo))
+	assert.True(t, settings.Enabled(LevelError))
+	assert.JSONEq(t, `{"level":"warn","bodies":true}`, string(encoded))
+	assert.ErrorIs(t, err, ErrInvalidLevel)
+}
@@ -0,0 +1,114 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Redacted is the value logged in place of the sensitive headers and fields.
+const Redacted = "[REDACTED]"
+
+// DefaultFields are the JSON fields redacted when no other ones are configured.
+var DefaultFields = []string{"password", "secret", "token"}
+
+// Headers carrying credentials, always redacted.
+var credentialHeaders = []string{"Authorization", "Cookie", "Set-Cookie", "Token", "X-Signature"}
+
+/*
+The Redactor struct hides the sensitive data of the logged requests and responses: the headers
+carrying credentials, like the token, and the JSON fields with the configured names, at any depth.
+The names are case insensitive.
+*/
+type Redactor struct {
+	headers map[string]bool
+	fields  map[string]bool
+}
+
+// The NewRedactor function returns a redactor of the credential headers and the given JSON fields.
+func NewRedactor(fields ...string) *Redactor {
+	r := &Redactor{
+		headers: make(map[string]bool),
+		fields:  make(map[string]bool),
+	}
+	for _, header := range credentialHeaders {
+		r.headers[http.CanonicalHeaderKey(header)] = true
+	}
+	for _, field := range fields {
+		if field = strings.TrimSpace(field); field != "" {
+			r.fields[strings.ToLower(field)] = true
+		}
+	}
+	return r
+}
+
+// The Headers method returns a copy of the headers with the values of the credential headers redacted.
+func (r *Redactor) Headers(headers http.Header) http.Header {
+	redacted := headers.Clone()
+	for name := range redacted {
+		if r.headers[http.CanonicalHeaderKey(name)] {
+			redacted[name] = []string{Redacted}
+		}
+	}
+	return redacted
+}
+
+// The WithoutCredentials function returns a copy of the headers without the ones carrying credentials.
+func WithoutCredentials(headers http.Header) http.Header {
+	cleaned := headers.Clone()
+	for _, header := range credentialHeaders {
+		cleaned.Del(header)
+	}
+	return cleaned
+}
+
+/*
+The Body method returns the body with the values of the sensitive fields redacted, if it is a JSON
+document. A JSON document cut short, like a truncated body, is replaced by its size, since its
+fields cannot be redacted reliably. Other bodies are returned as they are.
+*/
+func (r *Redactor) Body(body []byte) []byte {
+	if len(r.fields) == 0 {
+		return body
+	}
+	if !json.Valid(body) {
+		trimmed := bytes.TrimSpace(body)
+		if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+			return []byte(fmt.Sprintf("[%d bytes of incomplete JSON]", len(body)))
+		}
+		return body
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var document interface{}
+	if decoder.Decode(&document) != nil {
+		return body
+	}
+	redacted, err := json.Marshal(r.redact(document))
+	if err != nil {
+		return body
+	}
+	return redacted
+}
+
+// Auxiliary method that replaces the values of the sensitive fields of a decoded JSON value.
+func (r *Redactor) redact(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if r.fields[strings.ToLower(key)] {
+				v[key] = Redacted
+			} else {
+				v[key] = r.redact(field)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = r.redact(item)
+		}
+	}
+	return value
+}
@@ -0,0 +1,57 @@
+/*
+Package logging holds the runtime settings of the server logs, the levels of the logged messages,
+and the redaction of the sensitive data of the logged requests and responses.
+*/
+package logging
+
+import (
+	"sync"
+)
+
+/*
+The Options struct describes the logging settings.
+
+	Level (Level): Lowest level of the logged messages, debug, info, warn or error.
+	Bodies (bool): Whether the request and response bodies are logged, redacted, for debugging.
+*/
+type Options struct {
+	Level  Level `json:"level" swaggertype:"string" example:"info"`
+	Bodies bool  `json:"bodies"`
+}
+
+// The Settings struct is the runtime toggle of the logging settings, safe for concurrent use.
+type Settings struct {
+	mu      sync.RWMutex
+	options Options
+}
+
+// The NewSettings function returns the logging settings with the given initial options.
+func NewSettings(options Options) *Settings {
+	return &Settings{
+		options: options,
+	}
+}
+
+// The Options method returns the current logging settings.
+func (s *Settings) Options() Options {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.options
+}
+
+// The Apply method replaces the logging settings.
+func (s *Settings) Apply(options Options) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.options = options
+}
+
+// The Enabled method tells whether the messages of the given level are logged.
+func (s *Settings) Enabled(level Level) bool {
+	return level >= s.Options().Level
+}
+
+// The Bodies method tells whether the request and response bodies are logged.
+func (s *Settings) Bodies() bool {
+	return s.Options().Bodies
+}
@@ -0,0 +1,87 @@
+/*
+Package mail sends plain text emails through an SMTP server, like the notifications of the products
+about to expire.
+*/
+package mail
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"net"
+	netmail "net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// The Sender interface sends an email with the given subject and plain text body to the recipients.
+type Sender interface {
+	Send(to []string, subject, body string) error
+}
+
+/*
+The SMTP struct is a Sender through an SMTP server. The server address is host:port, and the
+connection is upgraded to TLS whenever the server supports it. Without a username, the emails are
+sent without authenticating.
+*/
+type SMTP struct {
+	addr     string
+	from     string
+	username string
+	password string
+	// Sends the message, net/smtp.SendMail outside the tests
+	send func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// The NewSMTP function returns a Sender through the SMTP server at addr, sending from the given address.
+func NewSMTP(addr, from, username, password string) *SMTP {
+	return &SMTP{
+		addr:     addr,
+		from:     from,
+		username: username,
+		password: password,
+		send:     smtp.SendMail,
+	}
+}
+
+// The Send method sends the email through the SMTP server, returning the error of the server if any.
+func (s *SMTP) Send(to []string, subject, body string) error {
+	var auth smtp.Auth
+	if s.username != "" {
+		host, _, err := net.SplitHostPort(s.addr)
+		if err != nil {
+			return err
+		}
+		auth = smtp.PlainAuth("", s.username, s.password, host)
+	}
+	// The envelope sender is the bare address of a sender with a name, like "Shop <shop@example.com>"
+	envelope := s.from
+	if address, err := netmail.ParseAddress(s.from); err == nil {
+		envelope = address.Address
+	}
+	return s.send(s.addr, auth, envelope, to, message(s.from, to, subject, body, time.Now()))
+}
+
+// The ValidAddress function checks if the text is a single email address, like "Shop <shop@example.com>".
+func ValidAddress(address string) bool {
+	_, err := netmail.ParseAddress(address)
+	return err == nil
+}
+
+/*
+Auxiliary function that writes the email as a message of RFC 5322, with a UTF-8 subject encoded
+for the headers and the lines of the body ended in CRLF.
+*/
+func message(from string, to []string, subject, body string, date time.Time) []byte {
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(strings.ReplaceAll(strings.ReplaceAll(body, "\r\n", "\n"), "\n", "\r\n"))
+	return msg.Bytes()
+}
@@ -0,0 +1,32 @@
+package mail
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/smtp"
+	"strings"
+	"testing"
+)
+
+func TestSMTP_Send(t *testing.T) {
+	var addr, envelope string
+	var auth smtp.Auth
+	var msg []byte
+	sender := NewSMTP("smtp.example.com:587", "Shop <shop@example.com>", "shop", "secret")
+	sender.send = func(a string, sendAuth smtp.Auth, from string, to []string, m []byte) error {
+		addr, auth, envelope, msg = a, sendAuth, from, m
+		return nil
+	}
+
+	err := sender.Send([]string{"store@example.com"}, "Stock expiring: Crème", "Hello,\nbye")
+
+	// Assertions
+	assert.NoError(t, err)
+	assert.Equal(t, "smtp.example.com:587", addr)
+	assert.NotNil(t, auth)
+	assert.Equal(t, "shop@example.com", envelope)
+	assert.Contains(t, string(msg), "From: Shop <shop@example.com>\r\nTo: store@example.com\r\n")
+	assert.Contains(t, string(msg), "Subject: =?utf-8?q?Stock_expiring:_Cr=C3=A8me?=\r\n")
+	assert.True(t, strings.HasSuffix(string(msg), "\r\n\r\nHello,\r\nbye"))
+	assert.True(t, ValidAddress("Shop <shop@example.com>"))
+	assert.False(t, ValidAddress("shop"))
+}
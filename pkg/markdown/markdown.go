@@ -0,0 +1,134 @@
+/*
+Package markdown renders the Markdown texts written by the users, like the descriptions of the
+products, to HTML that is safe to embed in a page: the raw HTML of the text is dropped, and the
+rendered HTML only keeps an allowlist of formatting elements and links to safe URLs.
+*/
+package markdown
+
+import (
+	"bytes"
+	"github.com/russross/blackfriday/v2"
+	"golang.org/x/net/html"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// Elements kept by Sanitize, with the attributes kept for each of them.
+var allowedElements = map[string][]string{
+	"a": {"href"}, "blockquote": nil, "br": nil, "code": nil, "del": nil, "em": nil,
+	"h1": nil, "h2": nil, "h3": nil, "h4": nil, "h5": nil, "h6": nil, "hr": nil, "li": nil,
+	"ol": nil, "p": nil, "pre": nil, "strong": nil, "table": nil, "tbody": nil, "td": nil,
+	"th": nil, "thead": nil, "tr": nil, "tt": nil, "ul": nil,
+}
+
+// Elements dropped by Sanitize along with their content, which is not meant to be shown.
+var droppedElements = map[string]bool{
+	"script": true, "style": true, "iframe": true, "object": true, "template": true, "textarea": true,
+}
+
+// Schemes of the links kept by Sanitize; relative links have none.
+var allowedSchemes = map[string]bool{"": true, "http": true, "https": true, "mailto": true}
+
+// Renderer of the Markdown texts, skipping their raw HTML and images.
+var renderer = blackfriday.NewHTMLRenderer(blackfriday.HTMLRendererParameters{
+	Flags: blackfriday.SkipHTML | blackfriday.SkipImages | blackfriday.Safelink,
+})
+
+/*
+The ToHTML function renders a Markdown text to sanitized HTML. Raw HTML and images in the text are
+dropped, and the links open outside the page without passing it on, as nofollow links.
+*/
+func ToHTML(source string) string {
+	if strings.TrimSpace(source) == "" {
+		return ""
+	}
+	rendered := blackfriday.Run([]byte(source), blackfriday.WithRenderer(renderer),
+		blackfriday.WithExtensions(blackfriday.CommonExtensions))
+	return Sanitize(string(rendered))
+}
+
+/*
+The Sanitize function returns an HTML fragment with only the allowed formatting elements and
+attributes. The text of the other elements is kept, except for the elements like script whose
+content is dropped too, and the links to unsafe URLs, like javascript: ones, lose their target.
+*/
+func Sanitize(fragment string) string {
+	var out strings.Builder
+	tokenizer := html.NewTokenizer(strings.NewReader(fragment))
+	// Depth within dropped elements, whose content is skipped
+	dropping := 0
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			if tokenizer.Err() == io.EOF {
+				return out.String()
+			}
+			return html.EscapeString(fragment)
+		}
+		token := tokenizer.Token()
+
+		switch tokenType {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			if droppedElements[token.Data] {
+				if tokenType == html.StartTagToken {
+					dropping++
+				}
+				continue
+			}
+			if _, ok := allowedElements[token.Data]; ok && dropping == 0 {
+				writeStartTag(&out, token)
+			}
+		case html.EndTagToken:
+			if droppedElements[token.Data] {
+				if dropping > 0 {
+					dropping--
+				}
+				continue
+			}
+			if _, ok := allowedElements[token.Data]; ok && dropping == 0 {
+				out.WriteString("</" + token.Data + ">")
+			}
+		case html.TextToken:
+			if dropping == 0 {
+				out.WriteString(html.EscapeString(token.Data))
+			}
+		}
+	}
+}
+
+// Auxiliary function that writes a start tag with its allowed attributes. The links are nofollow and open in a new tab.
+func writeStartTag(out *strings.Builder, token html.Token) {
+	var tag bytes.Buffer
+	tag.WriteString("<" + token.Data)
+	for _, attribute := range token.Attr {
+		if !allowedAttribute(token.Data, attribute) {
+			continue
+		}
+		tag.WriteString(" " + attribute.Key + `="` + html.EscapeString(attribute.Val) + `"`)
+	}
+	if token.Data == "a" {
+		tag.WriteString(` rel="nofollow noopener noreferrer" target="_blank"`)
+	}
+	tag.WriteString(">")
+	out.Write(tag.Bytes())
+}
+
+// Auxiliary function that checks if an attribute of an element is allowed, with a safe URL if it is a link.
+func allowedAttribute(element string, attribute html.Attribute) bool {
+	if attribute.Namespace != "" {
+		return false
+	}
+	allowed := false
+	for _, key := range allowedElements[element] {
+		allowed = allowed || key == attribute.Key
+	}
+	if !allowed {
+		return false
+	}
+	if attribute.Key == "href" {
+		link, err := url.Parse(strings.TrimSpace(attribute.Val))
+		return err == nil && allowedSchemes[strings.ToLower(link.Scheme)]
+	}
+	return true
+}
@@ -0,0 +1,30 @@
+package markdown
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestToHTML(t *testing.T) {
+	source := "# Fresh <script>alert(1)</script>\n\n**Sweet** and [juicy](https://example.com/a?b=1&c=2).\n\n" +
+		"<img src=x onerror=alert(1)>\n\n[click](javascript:alert(1)) ![pixel](https://tracker.test/p.png)\n"
+
+	rendered := ToHTML(source)
+
+	// Assertions
+	assert.Equal(t, "<h1>Fresh alert(1)</h1>\n\n"+
+		`<p><strong>Sweet</strong> and <a href="https://example.com/a?b=1&amp;c=2" rel="nofollow noopener noreferrer" target="_blank">juicy</a>.</p>`+
+		"\n\n<p></p>\n\n<p><tt>click</tt>) </p>\n", rendered)
+	assert.Empty(t, ToHTML("  "))
+}
+
+func TestSanitize(t *testing.T) {
+	fragment := `<p onclick="steal()">Hi <b>there</b><script>alert(1)</script><style>p{}</style>` +
+		`<a href="JavaScript:alert(1)" title="x">bad</a> <a href="/catalog/1">good</a></p>`
+
+	sanitized := Sanitize(fragment)
+
+	// Assertions
+	assert.Equal(t, `<p>Hi there<a rel="nofollow noopener noreferrer" target="_blank">bad</a> `+
+		`<a href="/catalog/1" rel="nofollow noopener noreferrer" target="_blank">good</a></p>`, sanitized)
+}
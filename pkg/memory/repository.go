@@ -0,0 +1,249 @@
+/*
+Package memory provides the CRUD mechanics shared by the in-memory repositories of the domains: the
+ID assignment and index, the unique keys, the filtering, the pagination and the rollback of a unit
+of work.
+*/
+package memory
+
+import (
+	"fmt"
+	"sync"
+)
+
+/*
+The Entity interface is implemented by the items of a Repository, identified by an integer ID. The
+WithId method returns a copy of the item with the given ID.
+*/
+type Entity[T any] interface {
+	EntityId() int
+	WithId(id int) T
+}
+
+/*
+The Repository struct is an in-memory repository of entities, kept in their creation order. The new
+entities get the ID following the highest one ever assigned, loaded or reserved, so the IDs of the
+deleted entities are never reused. It is safe for concurrent use.
+*/
+type Repository[T Entity[T]] struct {
+	mu    sync.RWMutex
+	items []T
+	// Position of every entity in items, by ID
+	index  map[int]int
+	lastId int
+	// Error wrapped when an ID is not found
+	notFound error
+	// Keys that no two entities may share, along with the error wrapped when a key is taken
+	unique []uniqueKey[T]
+}
+
+// The uniqueKey struct is a key that no two entities of a repository may share.
+type uniqueKey[T any] struct {
+	key   func(item T) string
+	taken error
+}
+
+/*
+The NewRepository function returns a repository holding the given entities. The lookups of unknown
+IDs fail with an error wrapping notFound.
+*/
+func NewRepository[T Entity[T]](items []T, notFound error) *Repository[T] {
+	r := &Repository[T]{
+		notFound: notFound,
+	}
+	r.replace(items)
+	return r
+}
+
+/*
+The WithUniqueKey method makes the given key unique among the entities, so the writes giving an
+entity the key of another one fail with an error wrapping taken, and returns the repository.
+*/
+func (r *Repository[T]) WithUniqueKey(key func(item T) string, taken error) *Repository[T] {
+	r.unique = append(r.unique, uniqueKey[T]{key: key, taken: taken})
+	return r
+}
+
+// The GetAll method returns every entity.
+func (r *Repository[T]) GetAll() []T {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]T(nil), r.items...)
+}
+
+// The GetById method returns an entity by its ID.
+func (r *Repository[T]) GetById(id int) (T, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	i, exists := r.index[id]
+	if !exists {
+		var zero T
+		return zero, fmt.Errorf("%w: id %d", r.notFound, id)
+	}
+	return r.items[i], nil
+}
+
+// The Filter method returns the entities matching the given function.
+func (r *Repository[T]) Filter(match func(item T) bool) []T {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var filtered []T
+	for _, item := range r.items {
+		if match(item) {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered
+}
+
+/*
+The List method returns up to limit of the entities matching the given function, skipping the first
+offset of them, along with the number of matching entities. A nil function matches every entity,
+and a limit of zero or less returns all of them.
+*/
+func (r *Repository[T]) List(match func(item T) bool, offset, limit int) ([]T, int) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var page []T
+	total := 0
+	for _, item := range r.items {
+		if match != nil && !match(item) {
+			continue
+		}
+		if total >= offset && (limit <= 0 || len(page) < limit) {
+			page = append(page, item)
+		}
+		total++
+	}
+	return page, total
+}
+
+// The Create method stores a new entity with the next ID and returns it.
+func (r *Repository[T]) Create(item T) (T, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.checkUnique(item, 0); err != nil {
+		var zero T
+		return zero, err
+	}
+	r.lastId++
+	item = item.WithId(r.lastId)
+	r.index[r.lastId] = len(r.items)
+	r.items = append(r.items, item)
+	return item, nil
+}
+
+// The Update method replaces the entity with the given ID, which the stored entity keeps, and returns it.
+func (r *Repository[T]) Update(id int, item T) (T, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var zero T
+	i, exists := r.index[id]
+	if !exists {
+		return zero, fmt.Errorf("%w: id %d", r.notFound, id)
+	}
+	if err := r.checkUnique(item, id); err != nil {
+		return zero, err
+	}
+	item = item.WithId(id)
+	r.items[i] = item
+	return item, nil
+}
+
+// The Delete method deletes the entity with the given ID.
+func (r *Repository[T]) Delete(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i, exists := r.index[id]
+	if !exists {
+		return fmt.Errorf("%w: id %d", r.notFound, id)
+	}
+	r.items = append(r.items[:i:i], r.items[i+1:]...)
+	delete(r.index, id)
+	for _, item := range r.items[i:] {
+		r.index[item.EntityId()]--
+	}
+	return nil
+}
+
+// The LastId method returns the highest ID ever assigned, loaded or reserved.
+func (r *Repository[T]) LastId() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.lastId
+}
+
+/*
+The ReserveIds method makes the new entities get IDs above lastId, like the IDs assigned before a
+restart, and never lowers the highest ID.
+*/
+func (r *Repository[T]) ReserveIds(lastId int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if lastId > r.lastId {
+		r.lastId = lastId
+	}
+}
+
+/*
+The Replace method replaces every entity with the given ones. The IDs of the new entities keep
+following the highest ID ever assigned, even if the given entities only have lower ones.
+*/
+func (r *Repository[T]) Replace(items []T) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.replace(items)
+}
+
+/*
+The WithTx method runs fn, a unit of work over the repository, and restores the entities as they
+were before if it fails. The writes of other goroutines while fn runs are rolled back as well, so
+the callers serialize their units of work. The IDs assigned by fn are not given again.
+*/
+func (r *Repository[T]) WithTx(fn func() error) error {
+	r.mu.RLock()
+	items := append([]T(nil), r.items...)
+	r.mu.RUnlock()
+
+	if err := fn(); err != nil {
+		r.mu.Lock()
+		r.replace(items)
+		r.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Auxiliary method that replaces every entity and rebuilds the index, with the repository held.
+func (r *Repository[T]) replace(items []T) {
+	r.items = append([]T(nil), items...)
+	r.index = make(map[int]int, len(items))
+	for i, item := range r.items {
+		r.index[item.EntityId()] = i
+		if item.EntityId() > r.lastId {
+			r.lastId = item.EntityId()
+		}
+	}
+}
+
+// Auxiliary method that checks the unique keys of an entity, ignoring the entity with the given ID.
+func (r *Repository[T]) checkUnique(item T, id int) error {
+	for _, unique := range r.unique {
+		key := unique.key(item)
+		for _, other := range r.items {
+			if other.EntityId() != id && unique.key(other) == key {
+				return fmt.Errorf("%w: %s", unique.taken, key)
+			}
+		}
+	}
+	return nil
+}
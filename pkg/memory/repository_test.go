@@ -0,0 +1,90 @@
+package memory
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var (
+	errSupplierNotFound = errors.New("supplier not found")
+	errTaxIdTaken       = errors.New("tax id taken")
+)
+
+// The supplier struct is an entity of the tests.
+type supplier struct {
+	Id    int
+	Name  string
+	TaxId string
+}
+
+func (s supplier) EntityId() int {
+	return s.Id
+}
+
+func (s supplier) WithId(id int) supplier {
+	s.Id = id
+	return s
+}
+
+func TestRepository(t *testing.T) {
+	repository := NewRepository([]supplier{{Id: 1, Name: "Acme", TaxId: "A1"}, {Id: 5, Name: "Globex", TaxId: "G5"}}, errSupplierNotFound).
+		WithUniqueKey(func(s supplier) string { return s.TaxId }, errTaxIdTaken)
+
+	created, errCreate := repository.Create(supplier{Id: 99, Name: "Initech", TaxId: "I6"})
+	_, errTaken := repository.Create(supplier{Name: "Acme copy", TaxId: "A1"})
+	updated, errUpdate := repository.Update(1, supplier{Name: "Acme Corp", TaxId: "A1"})
+	errDelete := repository.Delete(5)
+	_, errGet := repository.GetById(5)
+	next, _ := repository.Create(supplier{Name: "Umbrella", TaxId: "U7"})
+
+	// Assertions
+	assert.NoError(t, errCreate)
+	assert.Equal(t, 6, created.Id)
+	assert.ErrorIs(t, errTaken, errTaxIdTaken)
+	assert.EqualError(t, errTaken, "tax id taken: A1")
+	assert.NoError(t, errUpdate)
+	assert.Equal(t, supplier{Id: 1, Name: "Acme Corp", TaxId: "A1"}, updated)
+	assert.NoError(t, errDelete)
+	assert.ErrorIs(t, errGet, errSupplierNotFound)
+	assert.EqualError(t, errGet, "supplier not found: id 5")
+	assert.Equal(t, 7, next.Id)
+	assert.Equal(t, []supplier{updated, created, next}, repository.GetAll())
+}
+
+func TestRepository_List(t *testing.T) {
+	repository := NewRepository([]supplier{
+		{Id: 1, Name: "Acme"}, {Id: 2, Name: "Globex"}, {Id: 3, Name: "Initech"}, {Id: 4, Name: "Acme Labs"},
+	}, errSupplierNotFound)
+	named := func(s supplier) bool { return s.Name != "Globex" }
+
+	page, total := repository.List(named, 1, 1)
+	all, allTotal := repository.List(nil, 0, 0)
+	beyond, beyondTotal := repository.List(named, 10, 5)
+
+	// Assertions
+	assert.Equal(t, []supplier{{Id: 3, Name: "Initech"}}, page)
+	assert.Equal(t, 3, total)
+	assert.Len(t, all, 4)
+	assert.Equal(t, 4, allTotal)
+	assert.Empty(t, beyond)
+	assert.Equal(t, 3, beyondTotal)
+}
+
+func TestRepository_WithTx(t *testing.T) {
+	repository := NewRepository([]supplier{{Id: 1, Name: "Acme"}}, errSupplierNotFound)
+	errFailed := errors.New("failed")
+
+	err := repository.WithTx(func() error {
+		_, _ = repository.Create(supplier{Name: "Globex"})
+		_ = repository.Delete(1)
+		return errFailed
+	})
+	created, _ := repository.Create(supplier{Name: "Initech"})
+
+	// Assertions
+	assert.ErrorIs(t, err, errFailed)
+	// The ID assigned within the transaction rolled back is never given again
+	assert.Equal(t, 3, created.Id)
+	assert.Equal(t, []supplier{{Id: 1, Name: "Acme"}, created}, repository.GetAll())
+}
@@ -0,0 +1,110 @@
+package metrics
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultWindow is the number of recent samples per route used to compute the percentiles.
+const DefaultWindow = 1024
+
+/*
+The Summary struct contains the latency percentiles of a route over its recent requests, in
+milliseconds.
+*/
+type Summary struct {
+	Count int64   `json:"count"`
+	P50   float64 `json:"p50_ms"`
+	P95   float64 `json:"p95_ms"`
+	P99   float64 `json:"p99_ms"`
+	Max   float64 `json:"max_ms"`
+}
+
+// The window struct is a ring buffer with the most recent latencies of a route.
+type window struct {
+	samples []time.Duration
+	next    int
+	count   int64
+}
+
+/*
+The Latency struct records the latency of the requests per route. The percentiles are computed
+over the last samples of every route, so they follow the recent behavior of the server.
+*/
+type Latency struct {
+	mu      sync.Mutex
+	size    int
+	windows map[string]*window
+}
+
+// The NewLatency function returns a Latency keeping the given number of samples per route.
+func NewLatency(size int) *Latency {
+	if size <= 0 {
+		size = DefaultWindow
+	}
+	return &Latency{
+		size:    size,
+		windows: map[string]*window{},
+	}
+}
+
+// The Observe method records the latency of a request to the given route.
+func (l *Latency) Observe(route string, latency time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	w, ok := l.windows[route]
+	if !ok {
+		w = &window{samples: make([]time.Duration, 0, l.size)}
+		l.windows[route] = w
+	}
+
+	if len(w.samples) < l.size {
+		w.samples = append(w.samples, latency)
+	} else {
+		w.samples[w.next] = latency
+	}
+	w.next = (w.next + 1) % l.size
+	w.count++
+}
+
+// The Summaries method returns the latency percentiles of every route.
+func (l *Latency) Summaries() map[string]Summary {
+	l.mu.Lock()
+	snapshots := make(map[string][]time.Duration, len(l.windows))
+	counts := make(map[string]int64, len(l.windows))
+	for route, w := range l.windows {
+		snapshots[route] = append([]time.Duration(nil), w.samples...)
+		counts[route] = w.count
+	}
+	l.mu.Unlock()
+
+	summaries := make(map[string]Summary, len(snapshots))
+	for route, samples := range snapshots {
+		sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+		summaries[route] = Summary{
+			Count: counts[route],
+			P50:   milliseconds(percentile(samples, 0.50)),
+			P95:   milliseconds(percentile(samples, 0.95)),
+			P99:   milliseconds(percentile(samples, 0.99)),
+			Max:   milliseconds(samples[len(samples)-1]),
+		}
+	}
+	return summaries
+}
+
+// Auxiliary function that returns the nearest-rank percentile of the sorted samples.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
+
+// Auxiliary function that converts a duration into milliseconds.
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,23 @@
+package metrics
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestLatency(t *testing.T) {
+	latency := NewLatency(100)
+	// The first 50 samples fall out of the window
+	for i := 1; i <= 150; i++ {
+		latency.Observe("GET /products", time.Duration(i)*time.Millisecond)
+	}
+	latency.Observe("POST /products", 3*time.Millisecond)
+
+	summaries := latency.Summaries()
+
+	// Assertions
+	assert.Len(t, summaries, 2)
+	assert.Equal(t, Summary{Count: 150, P50: 100, P95: 145, P99: 149, Max: 150}, summaries["GET /products"])
+	assert.Equal(t, Summary{Count: 1, P50: 3, P95: 3, P99: 3, Max: 3}, summaries["POST /products"])
+}
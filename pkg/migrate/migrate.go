@@ -0,0 +1,74 @@
+/*
+Package migrate applies SQL migrations: plain .sql files applied once each, in name order, and
+recorded in the schema_migrations table of the database.
+*/
+package migrate
+
+import (
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+)
+
+/*
+The Up function applies the .sql files of the given file system that were not applied yet. Every
+migration runs in its own transaction, along with its record, so a failed migration leaves no trace
+and is retried by the next call. It returns the names of the applied migrations.
+*/
+func Up(db *sql.DB, migrations fs.FS) ([]string, error) {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		name       TEXT PRIMARY KEY,
+		applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := fs.Glob(migrations, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+
+	var applied []string
+	for _, name := range names {
+		var done bool
+		err = db.QueryRow(`SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE name = $1)`, name).Scan(&done)
+		if err != nil {
+			return applied, err
+		}
+		if done {
+			continue
+		}
+
+		if err = apply(db, migrations, name); err != nil {
+			return applied, fmt.Errorf("migration %s: %w", name, err)
+		}
+		applied = append(applied, strings.TrimSuffix(name, ".sql"))
+	}
+	return applied, nil
+}
+
+// Auxiliary function that runs a migration file and records it in a single transaction.
+func apply(db *sql.DB, migrations fs.FS, name string) error {
+	script, err := fs.ReadFile(migrations, name)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	if _, err = tx.Exec(string(script)); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if _, err = tx.Exec(`INSERT INTO schema_migrations (name) VALUES ($1)`, name); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
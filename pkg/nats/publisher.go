@@ -0,0 +1,43 @@
+package nats
+
+import (
+	"context"
+	"github.com/JoseObreque/go-web/pkg/outbox"
+	natsgo "github.com/nats-io/nats.go"
+)
+
+// Header with the type of the event carried by every NATS message.
+const HeaderEventType = "Event-Type"
+
+/*
+The Publisher struct publishes outbox messages to NATS. Every event is published on the subject
+"<prefix>.<event type>" (for example "catalog.product.created"), so subscribers can use wildcards
+to receive only the events they are interested in.
+*/
+type Publisher struct {
+	conn   *natsgo.Conn
+	prefix string
+}
+
+// The NewPublisher function returns a Publisher using the given connection and subject prefix.
+func NewPublisher(conn *natsgo.Conn, prefix string) *Publisher {
+	return &Publisher{
+		conn:   conn,
+		prefix: prefix,
+	}
+}
+
+/*
+The Publish method publishes a message and waits until the server has processed it, so a message
+is only removed from the outbox when the server received it.
+*/
+func (p *Publisher) Publish(ctx context.Context, message outbox.Message) error {
+	msg := natsgo.NewMsg(p.prefix + "." + message.Type)
+	msg.Data = message.Value
+	msg.Header.Set(HeaderEventType, message.Type)
+
+	if err := p.conn.PublishMsg(msg); err != nil {
+		return err
+	}
+	return p.conn.FlushWithContext(ctx)
+}
@@ -0,0 +1,172 @@
+package outbox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/JoseObreque/go-web/pkg/events"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var ErrMessageNotFound = errors.New("outbox message not found")
+
+/*
+The Message struct represents an event waiting to be published to an external broker. Value holds
+the already encoded event, so the message can be retried as is after a restart.
+*/
+type Message struct {
+	Id        int       `json:"id"`
+	Type      string    `json:"type"`
+	Key       string    `json:"key"`
+	Value     []byte    `json:"value"`
+	CreatedAt time.Time `json:"created_at"`
+	Attempts  int       `json:"attempts"`
+	LastError string    `json:"last_error,omitempty"`
+}
+
+// The Encoder type is a function that encodes an event into the value of an outbox message.
+type Encoder func(event events.Event) ([]byte, error)
+
+// The JSONEncoder function encodes an event as JSON.
+func JSONEncoder(event events.Event) ([]byte, error) {
+	return json.Marshal(event)
+}
+
+/*
+The Outbox struct is a durable table of pending messages. Every change is written to a JSON file
+before returning, so the messages recorded before a crash are published when the server restarts.
+An empty filepath keeps the messages in memory only.
+*/
+type Outbox struct {
+	filepath string
+
+	mu       sync.Mutex
+	messages []Message
+	lastId   int
+}
+
+/*
+The NewOutbox function returns an outbox persisted in the given file, loading the messages left
+pending by a previous run if the file exists.
+*/
+func NewOutbox(filepath string) (*Outbox, error) {
+	o := &Outbox{
+		filepath: filepath,
+	}
+	if filepath == "" {
+		return o, nil
+	}
+
+	data, err := os.ReadFile(filepath)
+	if errors.Is(err, os.ErrNotExist) {
+		return o, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, &o.messages); err != nil {
+		return nil, err
+	}
+
+	for _, message := range o.messages {
+		if message.Id > o.lastId {
+			o.lastId = message.Id
+		}
+	}
+	return o, nil
+}
+
+/*
+The Recorder method returns a function that adds an event, encoded with the given encoder, as a
+new message. It is meant to be called by the producer of the events as part of every change, like
+the product service does with WithRecorder, so no event is lost on the way to the outbox.
+*/
+func (o *Outbox) Recorder(encode Encoder) func(event events.Event) error {
+	return func(event events.Event) error {
+		value, err := encode(event)
+		if err != nil {
+			return fmt.Errorf("encoding event %s: %w", event.Type, err)
+		}
+		_, err = o.Add(event.Type, strconv.Itoa(event.Id), value)
+		return err
+	}
+}
+
+// The Add method stores a new pending message and returns it.
+func (o *Outbox) Add(eventType string, key string, value []byte) (Message, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.lastId++
+	message := Message{
+		Id:        o.lastId,
+		Type:      eventType,
+		Key:       key,
+		Value:     value,
+		CreatedAt: time.Now(),
+	}
+	o.messages = append(o.messages, message)
+
+	return message, o.save()
+}
+
+// The Pending method returns a copy of the pending messages, oldest first.
+func (o *Outbox) Pending() []Message {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	pending := make([]Message, len(o.messages))
+	copy(pending, o.messages)
+	return pending
+}
+
+// The Ack method removes a message once it has been published.
+func (o *Outbox) Ack(id int) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for i, message := range o.messages {
+		if message.Id == id {
+			o.messages = append(o.messages[:i], o.messages[i+1:]...)
+			return o.save()
+		}
+	}
+	return ErrMessageNotFound
+}
+
+// The Fail method records a failed publishing attempt of a message.
+func (o *Outbox) Fail(id int, cause error) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for i := range o.messages {
+		if o.messages[i].Id == id {
+			o.messages[i].Attempts++
+			o.messages[i].LastError = cause.Error()
+			return o.save()
+		}
+	}
+	return ErrMessageNotFound
+}
+
+// Auxiliary method that writes the pending messages to the outbox file. It must hold the lock.
+func (o *Outbox) save() error {
+	if o.filepath == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(o.messages)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so a crash never leaves a truncated outbox
+	tmp := o.filepath + ".tmp"
+	if err = os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, o.filepath)
+}
@@ -0,0 +1,79 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"github.com/JoseObreque/go-web/pkg/events"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+)
+
+type fakePublisher struct {
+	published []Message
+	fail      bool
+}
+
+func (p *fakePublisher) Publish(ctx context.Context, message Message) error {
+	if p.fail {
+		return errors.New("broker unavailable")
+	}
+	p.published = append(p.published, message)
+	return nil
+}
+
+func TestRelay_Flush(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "outbox.json")
+	outbox, err := NewOutbox(file)
+	if err != nil {
+		panic(err)
+	}
+	_, _ = outbox.Add("product.created", "1", []byte(`{"id":1}`))
+	_, _ = outbox.Add("product.updated", "1", []byte(`{"id":1}`))
+
+	// A failed publishing keeps the messages in the outbox
+	publisher := &fakePublisher{fail: true}
+	relay := NewRelay(outbox, publisher)
+	published, err := relay.Flush(context.Background())
+
+	// Assertions
+	assert.Error(t, err)
+	assert.Equal(t, 0, published)
+	assert.Equal(t, 1, outbox.Pending()[0].Attempts)
+
+	// The pending messages survive a restart
+	outbox, err = NewOutbox(file)
+	if err != nil {
+		panic(err)
+	}
+	publisher.fail = false
+	relay = NewRelay(outbox, publisher)
+	published, err = relay.Flush(context.Background())
+
+	// Assertions
+	assert.NoError(t, err)
+	assert.Equal(t, 2, published)
+	assert.Equal(t, "product.created", publisher.published[0].Type)
+	assert.Equal(t, "product.updated", publisher.published[1].Type)
+	assert.Empty(t, outbox.Pending())
+
+	message, _ := outbox.Add("product.deleted", "1", nil)
+	assert.Equal(t, 3, message.Id)
+}
+
+func TestOutbox_Recorder(t *testing.T) {
+	outbox, err := NewOutbox("")
+	if err != nil {
+		panic(err)
+	}
+	record := outbox.Recorder(JSONEncoder)
+
+	err = record(events.Event{Type: "product.created", Id: 7, Data: map[string]string{"name": "Oil"}})
+
+	// Assertions
+	assert.NoError(t, err)
+	assert.Len(t, outbox.Pending(), 1)
+	assert.Equal(t, "product.created", outbox.Pending()[0].Type)
+	assert.Equal(t, "7", outbox.Pending()[0].Key)
+	assert.JSONEq(t, `{"type":"product.created","id":7,"data":{"name":"Oil"},"timestamp":"0001-01-01T00:00:00Z"}`, string(outbox.Pending()[0].Value))
+}
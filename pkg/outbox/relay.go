@@ -0,0 +1,72 @@
+package outbox
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// The Publisher interface defines a broker where the outbox messages are published.
+type Publisher interface {
+	Publish(ctx context.Context, message Message) error
+}
+
+/*
+The Relay struct moves the outbox messages to a publisher. Messages are published in order and
+only removed from the outbox after the publisher acknowledges them, so every event is delivered at
+least once.
+*/
+type Relay struct {
+	outbox    *Outbox
+	publisher Publisher
+}
+
+// The NewRelay function returns a new Relay between the given outbox and publisher.
+func NewRelay(outbox *Outbox, publisher Publisher) *Relay {
+	return &Relay{
+		outbox:    outbox,
+		publisher: publisher,
+	}
+}
+
+/*
+The Flush method publishes the pending messages, oldest first. It stops at the first failure to
+preserve the order of the events and returns the number of published messages.
+*/
+func (r *Relay) Flush(ctx context.Context) (int, error) {
+	published := 0
+	for _, message := range r.outbox.Pending() {
+		if err := r.publisher.Publish(ctx, message); err != nil {
+			if failErr := r.outbox.Fail(message.Id, err); failErr != nil {
+				log.Printf("outbox: could not record failure of message %d: %s\n", message.Id, failErr)
+			}
+			return published, err
+		}
+
+		if err := r.outbox.Ack(message.Id); err != nil {
+			return published, err
+		}
+		published++
+	}
+	return published, nil
+}
+
+/*
+The Run method flushes the outbox every interval until the stop channel is closed. It is meant to
+be run in its own goroutine.
+*/
+func (r *Relay) Run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if _, err := r.Flush(context.Background()); err != nil {
+				log.Printf("outbox: publishing failed: %s\n", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,4 @@
+// Package pb contains the protobuf messages and gRPC stubs generated from api/proto/product.proto.
+package pb
+
+//go:generate protoc -I ../../api/proto --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative product.proto
@@ -0,0 +1,135 @@
+/*
+Package resilience protects the calls to the external backends, like the databases and caches, with
+retries spaced by a random backoff and circuit breakers that fail fast while a backend is down.
+*/
+package resilience
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrOpen = errors.New("circuit open, the backend is unavailable")
+
+// States of a circuit breaker.
+const (
+	StateClosed   = "closed"
+	StateOpen     = "open"
+	StateHalfOpen = "half-open"
+)
+
+/*
+The Breaker struct is a circuit breaker. It trips open after threshold consecutive failures, and
+then fails every call with ErrOpen for the cooldown. Once the cooldown elapses it lets a single
+probe call through (half-open): its success closes the circuit, and its failure opens it again.
+It is safe for concurrent use.
+*/
+type Breaker struct {
+	mu        sync.Mutex
+	name      string
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openedAt  time.Time
+	state     string
+	probing   bool
+	nowFunc   func() time.Time
+}
+
+// The NewBreaker function returns a closed breaker of the named backend.
+func NewBreaker(name string, threshold int, cooldown time.Duration) *Breaker {
+	if threshold < 1 {
+		threshold = 1
+	}
+	return &Breaker{
+		name:      name,
+		threshold: threshold,
+		cooldown:  cooldown,
+		state:     StateClosed,
+		nowFunc:   time.Now,
+	}
+}
+
+// The Name method returns the name of the backend protected by the breaker.
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+/*
+The Do method calls fn unless the circuit is open, in which case it returns ErrOpen right away. The
+error of fn counts as a failure of the backend.
+*/
+func (b *Breaker) Do(fn func() error) error {
+	if err := b.acquire(); err != nil {
+		return err
+	}
+	err := fn()
+	b.record(err == nil)
+	return err
+}
+
+// The State method returns the state of the circuit: closed, open or half-open.
+func (b *Breaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == StateOpen && b.nowFunc().Sub(b.openedAt) >= b.cooldown {
+		return StateHalfOpen
+	}
+	return b.state
+}
+
+// The RetryAfter method returns the time left until the open circuit lets a probe call through, zero if it is not open.
+func (b *Breaker) RetryAfter() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state != StateOpen {
+		return 0
+	}
+	if left := b.cooldown - b.nowFunc().Sub(b.openedAt); left > 0 {
+		return left
+	}
+	return 0
+}
+
+// Auxiliary method that checks if a call can go through, reserving the probe of a half-open circuit.
+func (b *Breaker) acquire() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		if b.nowFunc().Sub(b.openedAt) < b.cooldown {
+			return ErrOpen
+		}
+		b.state = StateHalfOpen
+		b.probing = true
+	case StateHalfOpen:
+		if b.probing {
+			return ErrOpen
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+// Auxiliary method that records the outcome of a call.
+func (b *Breaker) record(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if success {
+		b.failures = 0
+		b.state = StateClosed
+		return
+	}
+
+	b.failures++
+	if b.state == StateHalfOpen || b.failures >= b.threshold {
+		b.state = StateOpen
+		b.openedAt = b.nowFunc()
+	}
+}
@@ -0,0 +1,105 @@
+package resilience
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+var errDown = errors.New("connection refused")
+
+func TestBreaker_Do(t *testing.T) {
+	now := time.Date(2030, time.May, 10, 12, 0, 0, 0, time.UTC)
+	breaker := NewBreaker("postgres", 2, 30*time.Second)
+	breaker.nowFunc = func() time.Time { return now }
+	calls := 0
+	failing := func() error {
+		calls++
+		return errDown
+	}
+
+	firstErr := breaker.Do(failing)
+	secondErr := breaker.Do(failing)
+	openErr := breaker.Do(failing)
+	openState, retryAfter := breaker.State(), breaker.RetryAfter()
+
+	// After the cooldown a single probe goes through, and its success closes the circuit
+	now = now.Add(30 * time.Second)
+	halfOpenState := breaker.State()
+	probeErr := breaker.Do(func() error { return nil })
+
+	// Assertions
+	assert.ErrorIs(t, firstErr, errDown)
+	assert.ErrorIs(t, secondErr, errDown)
+	assert.ErrorIs(t, openErr, ErrOpen)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, StateOpen, openState)
+	assert.Equal(t, 30*time.Second, retryAfter)
+	assert.Equal(t, StateHalfOpen, halfOpenState)
+	assert.NoError(t, probeErr)
+	assert.Equal(t, StateClosed, breaker.State())
+	assert.Zero(t, breaker.RetryAfter())
+}
+
+func TestBreaker_FailedProbe(t *testing.T) {
+	now := time.Date(2030, time.May, 10, 12, 0, 0, 0, time.UTC)
+	breaker := NewBreaker("redis", 1, time.Minute)
+	breaker.nowFunc = func() time.Time { return now }
+	_ = breaker.Do(func() error { return errDown })
+
+	now = now.Add(time.Minute)
+	probeErr := breaker.Do(func() error { return errDown })
+
+	// Assertions
+	assert.ErrorIs(t, probeErr, errDown)
+	assert.Equal(t, StateOpen, breaker.State())
+	assert.Equal(t, time.Minute, breaker.RetryAfter())
+}
+
+func TestCall(t *testing.T) {
+	retry := Retry{Attempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+
+	t.Run("retries until it succeeds", func(t *testing.T) {
+		calls := 0
+		err := Call(NewBreaker("postgres", 5, time.Minute), retry, func() error {
+			calls++
+			if calls < 3 {
+				return errDown
+			}
+			return nil
+		})
+
+		// Assertions
+		assert.NoError(t, err)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("stops retrying when the circuit opens", func(t *testing.T) {
+		calls := 0
+		breaker := NewBreaker("postgres", 2, time.Minute)
+		err := Call(breaker, retry, func() error {
+			calls++
+			return errDown
+		})
+
+		// Assertions
+		assert.ErrorIs(t, err, ErrOpen)
+		assert.Equal(t, 2, calls)
+		assert.Equal(t, StateOpen, breaker.State())
+	})
+
+	t.Run("does not retry the errors that are not retryable", func(t *testing.T) {
+		calls := 0
+		notRetried := retry
+		notRetried.Retryable = func(err error) bool { return !errors.Is(err, errDown) }
+		err := Call(NewBreaker("postgres", 5, time.Minute), notRetried, func() error {
+			calls++
+			return errDown
+		})
+
+		// Assertions
+		assert.ErrorIs(t, err, errDown)
+		assert.Equal(t, 1, calls)
+	})
+}
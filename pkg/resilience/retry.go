@@ -0,0 +1,56 @@
+package resilience
+
+import (
+	"errors"
+	"math/rand"
+	"time"
+)
+
+/*
+The Retry struct is a retry policy: up to Attempts calls in total, spaced by a random delay between
+zero and BaseDelay doubled on every retry, capped at MaxDelay (full jitter), so the clients of a
+recovering backend do not retry all at once. Only the errors accepted by Retryable are retried, or
+every error if it is nil, except ErrOpen.
+*/
+type Retry struct {
+	Attempts  int
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	Retryable func(err error) bool
+}
+
+// The Do method calls fn until it succeeds, it fails with an error that is not retried, or the attempts run out.
+func (r Retry) Do(fn func() error) error {
+	var err error
+	for attempt := 0; ; attempt++ {
+		if err = fn(); err == nil || errors.Is(err, ErrOpen) {
+			return err
+		}
+		if attempt+1 >= r.Attempts || (r.Retryable != nil && !r.Retryable(err)) {
+			return err
+		}
+		time.Sleep(r.backoff(attempt))
+	}
+}
+
+// Auxiliary method that returns the random delay before the retry following the given attempt.
+func (r Retry) backoff(attempt int) time.Duration {
+	ceiling := r.BaseDelay << attempt
+	if ceiling <= 0 || (r.MaxDelay > 0 && ceiling > r.MaxDelay) {
+		ceiling = r.MaxDelay
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(ceiling) + 1))
+}
+
+/*
+The Call function calls fn through the breaker, retrying the failures with the given policy. Every
+attempt goes through the breaker, so the retries stop as soon as the circuit opens.
+*/
+func Call(breaker *Breaker, retry Retry, fn func() error) error {
+	return retry.Do(func() error {
+		return breaker.Do(fn)
+	})
+}
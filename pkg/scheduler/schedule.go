@@ -0,0 +1,58 @@
+package scheduler
+
+import (
+	"fmt"
+	"time"
+)
+
+// The Schedule interface defines when a job runs.
+type Schedule interface {
+	// Next returns the first activation time strictly after the given time.
+	Next(after time.Time) time.Time
+	String() string
+}
+
+// The every struct is a Schedule that activates at a fixed interval.
+type every struct {
+	interval time.Duration
+}
+
+// The Every function returns a Schedule that activates every interval.
+func Every(interval time.Duration) Schedule {
+	return every{interval: interval}
+}
+
+// The Next method returns the given time plus the interval.
+func (e every) Next(after time.Time) time.Time {
+	return after.Add(e.interval)
+}
+
+// The String method returns a readable representation of the schedule.
+func (e every) String() string {
+	return "every " + e.interval.String()
+}
+
+// The daily struct is a Schedule that activates once a day at a fixed local time.
+type daily struct {
+	hour   int
+	minute int
+}
+
+// The Daily function returns a Schedule that activates every day at hour:minute, local time.
+func Daily(hour int, minute int) Schedule {
+	return daily{hour: hour, minute: minute}
+}
+
+// The Next method returns the next occurrence of hour:minute after the given time.
+func (d daily) Next(after time.Time) time.Time {
+	next := time.Date(after.Year(), after.Month(), after.Day(), d.hour, d.minute, 0, 0, after.Location())
+	if !next.After(after) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// The String method returns a readable representation of the schedule.
+func (d daily) String() string {
+	return fmt.Sprintf("daily at %02d:%02d", d.hour, d.minute)
+}
@@ -0,0 +1,216 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	ErrJobNotFound = errors.New("job not found")
+	ErrJobRunning  = errors.New("job is already running")
+	ErrJobExists   = errors.New("job already registered")
+)
+
+// The Task type is the function executed by a job.
+type Task func(ctx context.Context) error
+
+/*
+The Status struct describes a registered job: its schedule, when it runs next and the outcome of
+its last execution.
+*/
+type Status struct {
+	Name         string        `json:"name"`
+	Schedule     string        `json:"schedule"`
+	Running      bool          `json:"running"`
+	NextRun      time.Time     `json:"next_run"`
+	LastRun      *time.Time    `json:"last_run,omitempty"`
+	LastDuration time.Duration `json:"last_duration_ns"`
+	LastError    string        `json:"last_error,omitempty"`
+	Runs         int           `json:"runs"`
+	Failures     int           `json:"failures"`
+}
+
+// The job struct holds a registered task together with its status.
+type job struct {
+	task     Task
+	schedule Schedule
+	status   Status
+}
+
+/*
+The Scheduler struct runs tasks in the background following their schedules, in the spirit of
+cron. A job never overlaps with itself: an activation is skipped while the previous run of the
+same job is still in progress.
+*/
+type Scheduler struct {
+	mu      sync.Mutex
+	jobs    map[string]*job
+	started bool
+	stop    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// The NewScheduler function returns a new Scheduler without jobs.
+func NewScheduler() *Scheduler {
+	return &Scheduler{
+		jobs: make(map[string]*job),
+	}
+}
+
+/*
+The Add method registers a new job. Jobs added after the scheduler started begin running
+immediately following their schedule.
+*/
+func (s *Scheduler) Add(name string, schedule Schedule, task Task) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.jobs[name]; ok {
+		return ErrJobExists
+	}
+
+	j := &job{
+		task:     task,
+		schedule: schedule,
+		status: Status{
+			Name:     name,
+			Schedule: schedule.String(),
+			NextRun:  schedule.Next(time.Now()),
+		},
+	}
+	s.jobs[name] = j
+
+	if s.started {
+		s.loop(name, j)
+	}
+	return nil
+}
+
+// The Start method starts running every registered job in its own goroutine.
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return
+	}
+	s.started = true
+	s.stop = make(chan struct{})
+	for name, j := range s.jobs {
+		s.loop(name, j)
+	}
+}
+
+// The Stop method stops the scheduler and waits for the jobs in progress to finish.
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	if !s.started {
+		s.mu.Unlock()
+		return
+	}
+	s.started = false
+	close(s.stop)
+	s.mu.Unlock()
+
+	s.wg.Wait()
+}
+
+/*
+The RunNow method runs a job immediately, outside its schedule, and returns the task error. It
+returns ErrJobRunning if the job is already in progress.
+*/
+func (s *Scheduler) RunNow(ctx context.Context, name string) error {
+	s.mu.Lock()
+	j, ok := s.jobs[name]
+	s.mu.Unlock()
+	if !ok {
+		return ErrJobNotFound
+	}
+
+	return s.run(ctx, name, j)
+}
+
+// The Status method returns the status of every registered job, sorted by name.
+func (s *Scheduler) Status() []Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]Status, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		statuses = append(statuses, j.status)
+	}
+	sort.Slice(statuses, func(i, k int) bool {
+		return statuses[i].Name < statuses[k].Name
+	})
+	return statuses
+}
+
+// Auxiliary method that starts the goroutine running a job on its schedule. It must hold the lock.
+func (s *Scheduler) loop(name string, j *job) {
+	s.wg.Add(1)
+	stop := s.stop
+
+	go func() {
+		defer s.wg.Done()
+
+		for {
+			s.mu.Lock()
+			wait := time.Until(j.status.NextRun)
+			s.mu.Unlock()
+
+			timer := time.NewTimer(wait)
+			select {
+			case <-stop:
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+
+			s.mu.Lock()
+			j.status.NextRun = j.schedule.Next(time.Now())
+			s.mu.Unlock()
+
+			if err := s.run(context.Background(), name, j); err != nil && !errors.Is(err, ErrJobRunning) {
+				log.Printf("scheduler: job %s failed: %s\n", name, err)
+			}
+		}
+	}()
+}
+
+// Auxiliary method that executes a job once, recording its outcome.
+func (s *Scheduler) run(ctx context.Context, name string, j *job) (err error) {
+	s.mu.Lock()
+	if j.status.Running {
+		s.mu.Unlock()
+		return ErrJobRunning
+	}
+	j.status.Running = true
+	s.mu.Unlock()
+
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job %s panicked: %v", name, r)
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		j.status.Running = false
+		j.status.LastRun = &start
+		j.status.LastDuration = time.Since(start)
+		j.status.Runs++
+		j.status.LastError = ""
+		if err != nil {
+			j.status.Failures++
+			j.status.LastError = err.Error()
+		}
+	}()
+
+	return j.task(ctx)
+}
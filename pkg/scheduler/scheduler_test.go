@@ -0,0 +1,54 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestDaily_Next(t *testing.T) {
+	schedule := Daily(2, 30)
+	before := time.Date(2030, time.May, 10, 1, 0, 0, 0, time.UTC)
+	after := time.Date(2030, time.May, 10, 2, 30, 0, 0, time.UTC)
+
+	// Assertions
+	assert.Equal(t, time.Date(2030, time.May, 10, 2, 30, 0, 0, time.UTC), schedule.Next(before))
+	assert.Equal(t, time.Date(2030, time.May, 11, 2, 30, 0, 0, time.UTC), schedule.Next(after))
+	assert.Equal(t, "daily at 02:30", schedule.String())
+}
+
+func TestScheduler_Run(t *testing.T) {
+	s := NewScheduler()
+	runs := make(chan struct{}, 10)
+	err := s.Add("tick", Every(10*time.Millisecond), func(ctx context.Context) error {
+		runs <- struct{}{}
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+	_ = s.Add("broken", Every(time.Hour), func(ctx context.Context) error {
+		return errors.New("boom")
+	})
+
+	s.Start()
+	<-runs
+	<-runs
+	s.Stop()
+
+	runErr := s.RunNow(context.Background(), "broken")
+	statuses := s.Status()
+
+	// Assertions
+	assert.Equal(t, ErrJobExists, s.Add("tick", Every(time.Second), nil))
+	assert.Equal(t, ErrJobNotFound, s.RunNow(context.Background(), "missing"))
+	assert.EqualError(t, runErr, "boom")
+	assert.Equal(t, "broken", statuses[0].Name)
+	assert.Equal(t, 1, statuses[0].Failures)
+	assert.Equal(t, "boom", statuses[0].LastError)
+	assert.Equal(t, "tick", statuses[1].Name)
+	assert.GreaterOrEqual(t, statuses[1].Runs, 2)
+	assert.Equal(t, 0, statuses[1].Failures)
+}
@@ -0,0 +1,154 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Name given to a secret of AWS Secrets Manager holding a plain string instead of a JSON object.
+const plainSecretName = "TOKEN"
+
+// The AWSCredentials struct holds the access key signing the requests to AWS, and its session token if temporary.
+type AWSCredentials struct {
+	AccessKeyId     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// The awsProvider struct is a Provider reading a secret of AWS Secrets Manager.
+type awsProvider struct {
+	region      string
+	secretId    string
+	credentials AWSCredentials
+	endpoint    string
+	client      *http.Client
+	nowFunc     func() time.Time
+}
+
+/*
+The NewAWSProvider function returns a provider reading the secret with the given name or ARN from
+AWS Secrets Manager in region, with the requests signed by the given credentials (Signature
+Version 4). A secret holding a JSON object gives a secret of the server per key, and a secret
+holding a plain string is the TOKEN.
+*/
+func NewAWSProvider(region, secretId string, credentials AWSCredentials, client *http.Client) Provider {
+	return &awsProvider{
+		region:      region,
+		secretId:    secretId,
+		credentials: credentials,
+		endpoint:    fmt.Sprintf("https://secretsmanager.%s.amazonaws.com/", region),
+		client:      client,
+		nowFunc:     time.Now,
+	}
+}
+
+// The Fetch method reads the current version of the secret. Any failure returns ErrUnavailable.
+func (p *awsProvider) Fetch(ctx context.Context) (map[string]string, error) {
+	body, err := json.Marshal(map[string]string{"SecretId": p.secretId})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnavailable, err)
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnavailable, err)
+	}
+	request.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	request.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	p.sign(request, body)
+
+	response, err := p.client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnavailable, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: secrets manager answered %d for %s", ErrUnavailable, response.StatusCode, p.secretId)
+	}
+	var value struct {
+		SecretString string `json:"SecretString"`
+	}
+	if err = json.NewDecoder(response.Body).Decode(&value); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnavailable, err)
+	}
+
+	var secrets map[string]string
+	if json.Unmarshal([]byte(value.SecretString), &secrets) != nil {
+		secrets = map[string]string{plainSecretName: value.SecretString}
+	}
+	return secrets, nil
+}
+
+// Auxiliary method that adds the Signature Version 4 headers of AWS to a request with the given body.
+func (p *awsProvider) sign(request *http.Request, body []byte) {
+	now := p.nowFunc().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	scope := fmt.Sprintf("%s/%s/secretsmanager/aws4_request", amzDate[:8], p.region)
+
+	request.Header.Set("X-Amz-Date", amzDate)
+	if p.credentials.SessionToken != "" {
+		request.Header.Set("X-Amz-Security-Token", p.credentials.SessionToken)
+	}
+
+	// Canonical headers: the host and every header set above, lower case and sorted
+	headers := map[string]string{"host": request.URL.Host}
+	for name := range request.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(request.Header.Get(name))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := request.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		request.Method, path, canonicalQuery(request.URL.Query()), canonicalHeaders.String(), signedHeaders, hashHex(body),
+	}, "\n")
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, hashHex([]byte(canonicalRequest))}, "\n")
+
+	key := []byte("AWS4" + p.credentials.SecretAccessKey)
+	for _, part := range []string{amzDate[:8], p.region, "secretsmanager", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	request.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		p.credentials.AccessKeyId, scope, signedHeaders, signature))
+}
+
+// Auxiliary function that returns the query parameters sorted and escaped as Signature Version 4 expects.
+func canonicalQuery(query url.Values) string {
+	return strings.ReplaceAll(query.Encode(), "+", "%20")
+}
+
+// Auxiliary function that returns the hex encoded SHA-256 of the data.
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Auxiliary function that returns the HMAC-SHA256 of the data with the given key.
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,92 @@
+/*
+Package secrets loads the secrets of the server, like the API token, from a secret manager instead
+of the environment file, and applies them again when they are rotated.
+*/
+package secrets
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+var ErrUnavailable = errors.New("secrets unavailable")
+
+// The Provider interface is a secret manager holding the secrets of the server by name, like TOKEN.
+type Provider interface {
+	Fetch(ctx context.Context) (map[string]string, error)
+}
+
+/*
+The Rotator struct keeps the secrets of a provider applied: it fetches them periodically and
+applies them whenever they change, so a secret rotated in the secret manager reaches the server
+without a restart. While the provider fails, the last secrets stay applied.
+*/
+type Rotator struct {
+	mu       sync.Mutex
+	provider Provider
+	apply    func(secrets map[string]string)
+	current  map[string]string
+}
+
+// The NewRotator function returns a Rotator applying the secrets of the provider with the given function.
+func NewRotator(provider Provider, apply func(secrets map[string]string)) *Rotator {
+	return &Rotator{
+		provider: provider,
+		apply:    apply,
+	}
+}
+
+// The Load method fetches the secrets and applies them if they changed, returning whether they did.
+func (r *Rotator) Load(ctx context.Context) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	fetched, err := r.provider.Fetch(ctx)
+	if err != nil {
+		return false, err
+	}
+	if sameSecrets(fetched, r.current) {
+		return false, nil
+	}
+	r.apply(fetched)
+	r.current = fetched
+	return true, nil
+}
+
+// The Run method loads the secrets every interval until the stop channel is closed, logging the rotations and failures.
+func (r *Rotator) Run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			rotated, err := r.Load(ctx)
+			cancel()
+			if err != nil {
+				log.Printf("secrets: keeping the current secrets: %s\n", err)
+			} else if rotated {
+				log.Printf("secrets: rotated secrets applied\n")
+			}
+		}
+	}
+}
+
+// Auxiliary function that checks if two sets of secrets hold the same values.
+func sameSecrets(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, value := range a {
+		if other, ok := b[name]; !ok || other != value {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,92 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVaultProvider_Rotation(t *testing.T) {
+	token := "first"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/secret/data/go-web" || r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"data": map[string]string{"TOKEN": token}},
+		})
+	}))
+	defer server.Close()
+
+	var applied []string
+	rotator := NewRotator(NewVaultProvider(server.URL, "root", "secret", "/go-web", server.Client()),
+		func(secrets map[string]string) { applied = append(applied, secrets["TOKEN"]) })
+
+	loaded, err := rotator.Load(context.Background())
+	if err != nil {
+		panic(err)
+	}
+	unchanged, _ := rotator.Load(context.Background())
+	token = "second"
+	rotated, _ := rotator.Load(context.Background())
+	_, forbiddenErr := NewVaultProvider(server.URL, "wrong", "secret", "go-web", server.Client()).Fetch(context.Background())
+
+	// Assertions
+	assert.True(t, loaded)
+	assert.False(t, unchanged)
+	assert.True(t, rotated)
+	assert.Equal(t, []string{"first", "second"}, applied)
+	assert.ErrorIs(t, forbiddenErr, ErrUnavailable)
+}
+
+func TestAWSProvider_Fetch(t *testing.T) {
+	secretString := `{"TOKEN":"abc","SIGNING_SECRET":"def"}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization := r.Header.Get("Authorization")
+		if !strings.HasPrefix(authorization, "AWS4-HMAC-SHA256 Credential=AKID/20260102/us-east-1/secretsmanager/aws4_request") ||
+			r.Header.Get("X-Amz-Target") != "secretsmanager.GetSecretValue" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"SecretString": secretString})
+	}))
+	defer server.Close()
+
+	provider := NewAWSProvider("us-east-1", "go-web", AWSCredentials{AccessKeyId: "AKID", SecretAccessKey: "key"},
+		server.Client()).(*awsProvider)
+	provider.endpoint = server.URL + "/"
+	provider.nowFunc = func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	object, err := provider.Fetch(context.Background())
+	if err != nil {
+		panic(err)
+	}
+	secretString = "plain"
+	plain, err := provider.Fetch(context.Background())
+	if err != nil {
+		panic(err)
+	}
+
+	// Assertions
+	assert.Equal(t, map[string]string{"TOKEN": "abc", "SIGNING_SECRET": "def"}, object)
+	assert.Equal(t, map[string]string{"TOKEN": "plain"}, plain)
+}
+
+func TestValidToken(t *testing.T) {
+	t.Setenv("TOKEN", "")
+	withoutToken := ValidToken("")
+	t.Setenv("TOKEN", "Zx8kP2mQ9rT4")
+
+	// Assertions
+	assert.False(t, withoutToken)
+	assert.True(t, ValidToken("Zx8kP2mQ9rT4"))
+	assert.False(t, ValidToken("Zx8kP2mQ9rT5"))
+	assert.False(t, ValidToken("Zx8kP2"))
+	assert.False(t, ValidToken(""))
+}
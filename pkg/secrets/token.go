@@ -0,0 +1,16 @@
+package secrets
+
+import (
+	"crypto/subtle"
+	"os"
+)
+
+/*
+The ValidToken function reports whether the given token is the API token of the TOKEN environment
+variable, compared in constant time so the response times do not leak it. Without a configured
+token every token is rejected, since an empty one would match the requests without it.
+*/
+func ValidToken(token string) bool {
+	expected := os.Getenv("TOKEN")
+	return expected != "" && subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1
+}
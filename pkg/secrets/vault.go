@@ -0,0 +1,62 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// The vaultProvider struct is a Provider reading a secret of the KV version 2 engine of HashiCorp Vault.
+type vaultProvider struct {
+	address string
+	token   string
+	mount   string
+	path    string
+	client  *http.Client
+}
+
+/*
+The NewVaultProvider function returns a provider reading the secret at path in the KV version 2
+engine mounted at mount, like "secret", of the Vault server at address, authenticated with the
+given Vault token. Every key of the secret is a secret of the server, like TOKEN.
+*/
+func NewVaultProvider(address, token, mount, path string, client *http.Client) Provider {
+	return &vaultProvider{
+		address: strings.TrimRight(address, "/"),
+		token:   token,
+		mount:   strings.Trim(mount, "/"),
+		path:    strings.Trim(path, "/"),
+		client:  client,
+	}
+}
+
+// The Fetch method reads the latest version of the secret. Any failure returns ErrUnavailable.
+func (p *vaultProvider) Fetch(ctx context.Context) (map[string]string, error) {
+	url := fmt.Sprintf("%s/v1/%s/data/%s", p.address, p.mount, p.path)
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnavailable, err)
+	}
+	request.Header.Set("X-Vault-Token", p.token)
+
+	response, err := p.client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnavailable, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: vault answered %d for %s/%s", ErrUnavailable, response.StatusCode, p.mount, p.path)
+	}
+	var body struct {
+		Data struct {
+			Data map[string]string `json:"data"`
+		} `json:"data"`
+	}
+	if err = json.NewDecoder(response.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnavailable, err)
+	}
+	return body.Data.Data, nil
+}
@@ -0,0 +1,167 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"io"
+	"strings"
+)
+
+var (
+	ErrEncryptionKeyRequired = errors.New("the store file is encrypted and no encryption key was given")
+	ErrInvalidEncryptionKey  = errors.New("invalid encryption key, expected 16, 24 or 32 bytes")
+	ErrDecryptionFailed      = errors.New("the store file cannot be decrypted with the given key")
+)
+
+// Magic bytes at the start of the compressed and the encrypted store files.
+var (
+	gzipMagic      = []byte{0x1f, 0x8b}
+	encryptedMagic = []byte("GOWEBAES1")
+)
+
+/*
+The WithEncryptionKey function returns an Option that encrypts the saved files with AES-GCM using
+the given 16, 24 or 32 bytes key. Encrypted files are decrypted in memory when loaded.
+*/
+func WithEncryptionKey(key []byte) Option {
+	return func(s *jsonStore) {
+		s.encryptionKey = key
+	}
+}
+
+// The ValidateEncryptionKey function checks that a key can be used with WithEncryptionKey.
+func ValidateEncryptionKey(key []byte) error {
+	if _, err := aes.NewCipher(key); err != nil {
+		return ErrInvalidEncryptionKey
+	}
+	return nil
+}
+
+/*
+Auxiliary method that returns the reader of the JSON content of a store file. The compression and
+the encryption are detected by the magic bytes of the file, whatever its name.
+*/
+func (s *jsonStore) decode(file io.Reader) (io.Reader, error) {
+	reader := bufio.NewReader(file)
+	if magic, _ := reader.Peek(len(encryptedMagic)); bytes.Equal(magic, encryptedMagic) {
+		plaintext, err := s.decrypt(reader)
+		if err != nil {
+			return nil, err
+		}
+		reader = bufio.NewReader(bytes.NewReader(plaintext))
+	}
+
+	if magic, _ := reader.Peek(len(gzipMagic)); bytes.Equal(magic, gzipMagic) {
+		return gzip.NewReader(reader)
+	}
+	return reader, nil
+}
+
+/*
+Auxiliary method that returns a writer of the JSON content of a store file, along with the function
+that completes the file once everything has been written. Files whose name ends with .gz (or .gz.enc)
+are compressed, and every file is encrypted if the store has an encryption key.
+*/
+func (s *jsonStore) encode(file io.Writer) (io.Writer, func() error, error) {
+	var finishers []func() error
+	writer := file
+
+	if s.encryptionKey != nil {
+		if err := ValidateEncryptionKey(s.encryptionKey); err != nil {
+			return nil, nil, err
+		}
+		encrypted := &encryptingWriter{dst: file, key: s.encryptionKey}
+		writer = encrypted
+		finishers = append(finishers, encrypted.Close)
+	}
+	if strings.HasSuffix(s.filepath, ".gz") || strings.HasSuffix(s.filepath, ".gz.enc") {
+		compressed := gzip.NewWriter(writer)
+		writer = compressed
+		finishers = append(finishers, compressed.Close)
+	}
+	buffered := bufio.NewWriter(writer)
+	finishers = append(finishers, buffered.Flush)
+
+	// The outermost layers are completed first, so their remaining data reaches the inner ones
+	finish := func() error {
+		for i := len(finishers) - 1; i >= 0; i-- {
+			if err := finishers[i](); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return buffered, finish, nil
+}
+
+// Auxiliary method that decrypts the content of an encrypted store file.
+func (s *jsonStore) decrypt(reader io.Reader) ([]byte, error) {
+	if s.encryptionKey == nil {
+		return nil, ErrEncryptionKeyRequired
+	}
+	gcm, err := newGCM(s.encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	data = data[len(encryptedMagic):]
+	if len(data) < gcm.NonceSize() {
+		return nil, ErrDecryptionFailed
+	}
+	plaintext, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], encryptedMagic)
+	if err != nil {
+		return nil, ErrDecryptionFailed
+	}
+	return plaintext, nil
+}
+
+/*
+The encryptingWriter struct buffers the content of a store file and writes it encrypted with
+AES-GCM when closed, preceded by the magic bytes and the nonce.
+*/
+type encryptingWriter struct {
+	dst    io.Writer
+	key    []byte
+	buffer bytes.Buffer
+}
+
+func (w *encryptingWriter) Write(data []byte) (int, error) {
+	return w.buffer.Write(data)
+}
+
+func (w *encryptingWriter) Close() error {
+	gcm, err := newGCM(w.key)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return err
+	}
+
+	sealed := gcm.Seal(nil, nonce, w.buffer.Bytes(), encryptedMagic)
+	for _, part := range [][]byte{encryptedMagic, nonce, sealed} {
+		if _, err = w.dst.Write(part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Auxiliary function that returns the AES-GCM cipher of the given key.
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, ErrInvalidEncryptionKey
+	}
+	return cipher.NewGCM(block)
+}
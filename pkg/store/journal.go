@@ -0,0 +1,407 @@
+package store
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/pkg/lock"
+	"os"
+	"sync"
+	"time"
+)
+
+// Operations recorded in the journal.
+const (
+	OpPut     = "put"
+	OpDelete  = "delete"
+	OpReplace = "replace"
+	OpBatch   = "batch"
+)
+
+var ErrJournalClosed = errors.New("journal closed")
+
+/*
+The Entry struct is a change record of the journal: the new state of a product (OpPut), the
+removal of a product (OpDelete), the replacement of every product, like a restore (OpReplace), or
+the changes of a transaction (OpBatch), recorded in a single line so they are replayed all or none.
+LastId is the highest ID ever assigned to a product when the change was made.
+*/
+type Entry struct {
+	Op       string           `json:"op"`
+	Id       int              `json:"id,omitempty"`
+	Product  *domain.Product  `json:"product,omitempty"`
+	Products []domain.Product `json:"products,omitempty"`
+	Entries  []Entry          `json:"entries,omitempty"`
+	LastId   int              `json:"last_id,omitempty"`
+	Time     time.Time        `json:"time"`
+}
+
+/*
+The Journal struct is an append-only write-ahead log of the product changes, one JSON entry per
+line. Every entry is synced to disk before Append returns, so no acknowledged change is lost on a
+crash. Replaying the journal over the last saved file rebuilds the current state, and compacting
+saves that state and empties the journal.
+
+A journal shared by several instances of the server, along with its store file, is coordinated
+with a lock: the writes of every instance hold it, and an instance refreshes its products before
+writing if the journal was changed by another one since it last held the lock.
+*/
+type Journal struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+
+	// Lock shared with the other instances, nil if the journal is not shared
+	locker  lock.Locker
+	timeout time.Duration
+	// Serializes the lock holders of this instance, which may not reenter the lock
+	lockMu sync.Mutex
+	// Size and modification time of the journal when this instance last released the lock
+	seen os.FileInfo
+	// Function refreshing the products of this instance when another one changed the journal
+	refresh func() error
+}
+
+// The OpenJournal function opens the journal file at the given path, creating it if needed.
+func OpenJournal(path string) (*Journal, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &Journal{
+		path: path,
+		file: file,
+	}, nil
+}
+
+/*
+The WithLock method shares the journal with other instances of the server through the given lock,
+waiting up to timeout to acquire it, and returns the journal.
+*/
+func (j *Journal) WithLock(locker lock.Locker, timeout time.Duration) *Journal {
+	j.locker = locker
+	j.timeout = timeout
+	return j
+}
+
+/*
+The OnStale method sets the function that refreshes the products of this instance, run while the
+lock is held if another instance changed the journal since this one last held it.
+*/
+func (j *Journal) OnStale(refresh func() error) {
+	j.refresh = refresh
+}
+
+/*
+The Lock method acquires the lock of a shared journal, refreshing the products of this instance if
+they are stale, and returns the function releasing it. The writes recording entries with Append
+must hold it; Compact and Flush acquire it themselves. A journal that is not shared has nothing to
+lock.
+*/
+func (j *Journal) Lock() (func(), error) {
+	if j.locker == nil {
+		return func() {}, nil
+	}
+
+	j.lockMu.Lock()
+	ctx, cancel := context.WithTimeout(context.Background(), j.timeout)
+	defer cancel()
+	unlock, err := j.locker.Lock(ctx)
+	if err != nil {
+		j.lockMu.Unlock()
+		return nil, err
+	}
+	release := func() {
+		if info, err := os.Stat(j.path); err == nil {
+			j.seen = info
+		}
+		_ = unlock()
+		j.lockMu.Unlock()
+	}
+
+	if j.refresh != nil && j.stale() {
+		if err = j.refresh(); err != nil {
+			release()
+			return nil, err
+		}
+	}
+	return release, nil
+}
+
+// Auxiliary method that reports whether the journal changed since this instance last released its lock.
+func (j *Journal) stale() bool {
+	info, err := os.Stat(j.path)
+	if err != nil || j.seen == nil {
+		return true
+	}
+	return info.Size() != j.seen.Size() || !info.ModTime().Equal(j.seen.ModTime())
+}
+
+// The Append method records a change in the journal.
+func (j *Journal) Append(entry Entry) error {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.file == nil {
+		return ErrJournalClosed
+	}
+	if _, err = j.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return j.file.Sync()
+}
+
+/*
+The Entries method returns the entries recorded in the journal. A last line left incomplete by a
+crash is ignored, since its change was never acknowledged.
+*/
+func (j *Journal) Entries() ([]Entry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.entries()
+}
+
+// Auxiliary method that reads the entries of the journal, with the journal held.
+func (j *Journal) entries() ([]Entry, error) {
+	file, err := os.Open(j.path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var entry Entry
+		if err = json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			break
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+/*
+The FlushStats struct describes a flush of the products into the store file, which compacts the
+journal: the products and bytes written in the file, the entries and bytes the journal held, and
+how long it took.
+*/
+type FlushStats struct {
+	Records        int           `json:"records"`
+	Bytes          int64         `json:"bytes"`
+	JournalEntries int           `json:"journal_entries"`
+	JournalBytes   int64         `json:"journal_bytes"`
+	Duration       time.Duration `json:"duration_ns" swaggertype:"integer"`
+}
+
+/*
+The FileStats struct describes the store file and its journal: their sizes, the entries not yet
+saved in the file, and the time of the last write of either.
+*/
+type FileStats struct {
+	Path           string     `json:"path"`
+	Bytes          int64      `json:"bytes"`
+	JournalEntries int        `json:"journal_entries"`
+	JournalBytes   int64      `json:"journal_bytes"`
+	LastWrite      *time.Time `json:"last_write,omitempty"`
+}
+
+// The FileStats method returns the stats of the given store file and the journal.
+func (j *Journal) FileStats(path string) (FileStats, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	stats := FileStats{Path: path}
+	entries, err := j.entries()
+	if err != nil {
+		return FileStats{}, err
+	}
+	stats.JournalEntries = len(entries)
+
+	for _, file := range []string{path, j.path} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return FileStats{}, err
+		}
+		if file == path {
+			stats.Bytes = info.Size()
+		} else {
+			stats.JournalBytes = info.Size()
+		}
+		if modified := info.ModTime(); stats.LastWrite == nil || modified.After(*stats.LastWrite) {
+			stats.LastWrite = &modified
+		}
+	}
+	return stats, nil
+}
+
+/*
+The Compact method saves the current state with the given function and empties the journal. The
+changes are held while it runs, so every change is either part of the saved state or kept in the
+journal.
+*/
+func (j *Journal) Compact(save func() error) error {
+	unlock, err := j.Lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.file == nil {
+		return ErrJournalClosed
+	}
+
+	return j.compact(save)
+}
+
+/*
+The Flush method compacts the journal like Compact, saving the state returned by state in the given
+store file, and returns the stats of the flush.
+*/
+func (j *Journal) Flush(s Store, path string, state func() State) (FlushStats, error) {
+	start := time.Now()
+	unlock, err := j.Lock()
+	if err != nil {
+		return FlushStats{}, err
+	}
+	defer unlock()
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.file == nil {
+		return FlushStats{}, ErrJournalClosed
+	}
+
+	var stats FlushStats
+	entries, err := j.entries()
+	if err != nil {
+		return FlushStats{}, err
+	}
+	stats.JournalEntries = len(entries)
+	if info, err := j.file.Stat(); err == nil {
+		stats.JournalBytes = info.Size()
+	}
+
+	err = j.compact(func() error {
+		current := state()
+		stats.Records = len(current.Products)
+		return s.SaveState(current)
+	})
+	if err != nil {
+		return FlushStats{}, err
+	}
+	if info, err := os.Stat(path); err == nil {
+		stats.Bytes = info.Size()
+	}
+	stats.Duration = time.Since(start)
+	return stats, nil
+}
+
+// Auxiliary method that saves the current state and empties the journal, with the journal held.
+func (j *Journal) compact(save func() error) error {
+	if err := save(); err != nil {
+		return err
+	}
+	if err := j.file.Truncate(0); err != nil {
+		return err
+	}
+	return j.file.Sync()
+}
+
+// The Close method closes the journal file. The journal cannot be used afterwards.
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.file == nil {
+		return nil
+	}
+	err := j.file.Close()
+	j.file = nil
+	return err
+}
+
+/*
+The Replay function applies the journal entries, in order, to the given products and returns the
+resulting products. Replaying an entry twice has no further effect.
+*/
+func Replay(products []domain.Product, entries []Entry) []domain.Product {
+	return ReplayState(State{Products: products}, entries).Products
+}
+
+/*
+The ReplayState function applies the journal entries to the given state like Replay, and returns the
+resulting state, whose highest ID ever assigned is never lower than the one of the given state or of
+any entry.
+*/
+func ReplayState(state State, entries []Entry) State {
+	lastId := state.LastId
+	result := append([]domain.Product(nil), state.Products...)
+	index := make(map[int]int, len(result))
+	for i, product := range result {
+		index[product.Id] = i
+	}
+
+	var apply func(entry Entry)
+	apply = func(entry Entry) {
+		// The entries written before they recorded the highest ID still count their new products
+		if entry.LastId > lastId {
+			lastId = entry.LastId
+		}
+		if entry.Op == OpPut && entry.Id > lastId {
+			lastId = entry.Id
+		}
+		if entry.Op == OpBatch {
+			for _, batchEntry := range entry.Entries {
+				apply(batchEntry)
+			}
+			return
+		}
+		if entry.Op == OpReplace {
+			result = append([]domain.Product(nil), entry.Products...)
+			index = make(map[int]int, len(result))
+			for i, product := range result {
+				index[product.Id] = i
+			}
+			return
+		}
+
+		i, exists := index[entry.Id]
+		switch {
+		case entry.Op == OpPut && entry.Product != nil && exists:
+			result[i] = *entry.Product
+		case entry.Op == OpPut && entry.Product != nil:
+			index[entry.Id] = len(result)
+			result = append(result, *entry.Product)
+		case entry.Op == OpDelete && exists:
+			result = append(result[:i], result[i+1:]...)
+			delete(index, entry.Id)
+			for id, position := range index {
+				if position > i {
+					index[id] = position - 1
+				}
+			}
+		}
+	}
+	for _, entry := range entries {
+		apply(entry)
+	}
+	for _, product := range result {
+		if product.Id > lastId {
+			lastId = product.Id
+		}
+	}
+	return State{Products: result, LastId: lastId}
+}
@@ -2,9 +2,11 @@ package store
 
 import (
 	"encoding/json"
-	"errors"
+	"fmt"
 	"github.com/JoseObreque/go-web/internal/domain"
+	"io"
 	"os"
+	"path/filepath"
 )
 
 /*
@@ -13,6 +15,8 @@ The Store interface defines methods for interact with a JSON file of Products.
 type Store interface {
 	Load() ([]domain.Product, error)
 	Save([]domain.Product) error
+	LoadState() (State, error)
+	SaveState(state State) error
 	GetAll() ([]domain.Product, error)
 	GetOne(id int) (domain.Product, error)
 	AddOne(product domain.Product) error
@@ -20,45 +24,142 @@ type Store interface {
 	DeleteOne(id int) error
 }
 
+/*
+The State struct is the content of a store file: the products and the highest ID ever assigned to
+one of them, which the new products follow, so the IDs of the deleted products are never reused,
+even after a restart.
+*/
+type State struct {
+	Products []domain.Product
+	LastId   int
+}
+
 // The jsonStore struct is the implementation of the Store interface.
 type jsonStore struct {
 	filepath string
+	// Number of records between two progress reports, and the function receiving them
+	progressEvery int
+	progress      func(records int)
+	// AES key of the encrypted files, nil if the files are not encrypted
+	encryptionKey []byte
+}
+
+// The Option type configures a jsonStore.
+type Option func(s *jsonStore)
+
+/*
+The WithProgress function returns an Option that reports the number of products loaded so far
+every given number of records, and once more with the total when the load finishes.
+*/
+func WithProgress(every int, report func(records int)) Option {
+	return func(s *jsonStore) {
+		s.progressEvery = every
+		s.progress = report
+	}
 }
 
 // NewJsonStore is a constructor for a new jsonStore instance.
-func NewJsonStore(filepath string) Store {
-	return &jsonStore{
+func NewJsonStore(filepath string, options ...Option) Store {
+	s := &jsonStore{
 		filepath: filepath,
 	}
+	for _, option := range options {
+		option(s)
+	}
+	return s
 }
 
-// The Load method retrieves all the products from a JSON file as a slice of Products.
+// The Load method retrieves all the products from a JSON file as a slice of Products, like LoadState.
 func (s *jsonStore) Load() ([]domain.Product, error) {
-	// Read all the data from the JSON file
-	var products []domain.Product
-	data, err := os.ReadFile(s.filepath)
+	state, err := s.LoadState()
+	return state.Products, err
+}
+
+/*
+The LoadState method retrieves the products from a JSON file, along with the highest ID ever
+assigned to one of them. The file is decoded one product at a time, so large files are never held
+in memory as a whole unless they are encrypted. Compressed and encrypted files are detected and
+read transparently.
+*/
+func (s *jsonStore) LoadState() (State, error) {
+	var state State
+	file, err := os.Open(s.filepath)
 	if err != nil {
-		return products, err
+		return state, err
 	}
+	defer file.Close()
 
-	// Unmarshal the data into a slice of Product structs
-	if err = json.Unmarshal(data, &products); err != nil {
-		return products, err
+	content, err := s.decode(file)
+	if err != nil {
+		return state, err
 	}
 
-	return products, nil
+	decoder := json.NewDecoder(content)
+	token, err := decoder.Token()
+	if err != nil || token == nil {
+		// A file with just null holds no products
+		return state, err
+	}
+	if token == json.Delim('{') {
+		err = s.decodeObject(decoder, &state)
+	} else {
+		err = s.decodeProducts(decoder, token, &state)
+	}
+	if err != nil {
+		return state, err
+	}
+
+	for _, product := range state.Products {
+		if product.Id > state.LastId {
+			state.LastId = product.Id
+		}
+	}
+	if s.progress != nil {
+		s.progress(len(state.Products))
+	}
+	return state, nil
 }
 
-// The Save method saves all the products in a JSON file.
+// The Save method saves all the products in a JSON file, like SaveState with their highest ID.
 func (s *jsonStore) Save(products []domain.Product) error {
-	// Marshal the data into a JSON format
-	data, err := json.Marshal(products)
+	return s.SaveState(State{Products: products})
+}
+
+/*
+The SaveState method saves the products in a JSON file. The file is an array of the products, or an
+object with them and the highest ID ever assigned if it is not the one of a product. The products
+are encoded one at a time into a temporary file that replaces the previous one once complete, so a
+failed save never leaves a truncated file behind. The file and the rename are synced to disk before
+SaveState returns, since the journal is emptied once the products are saved. The file is compressed
+if its name ends with .gz, and encrypted if the store has an encryption key.
+*/
+func (s *jsonStore) SaveState(state State) error {
+	tmpPath := s.filepath + ".tmp"
+	file, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
 	if err != nil {
 		return err
 	}
 
-	// Write the data to the JSON file
-	return os.WriteFile(s.filepath, data, 0644)
+	writer, finish, err := s.encode(file)
+	if err == nil {
+		if err = encodeState(writer, state); err == nil {
+			if err = finish(); err == nil {
+				err = file.Sync()
+			}
+		}
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+
+	if err = os.Rename(tmpPath, s.filepath); err != nil {
+		return err
+	}
+	return syncDir(filepath.Dir(s.filepath))
 }
 
 // The GetAll method retrieves all the products from a JSON file as a slice of Products.
@@ -88,23 +189,24 @@ func (s *jsonStore) GetOne(id int) (domain.Product, error) {
 	}
 
 	// If no product was found, return an error
-	return domain.Product{}, errors.New("product not found")
+	return domain.Product{}, fmt.Errorf("%w: id %d", domain.ErrProductNotFound, id)
 }
 
 // The AddOne method adds a single product to a JSON file.
 func (s *jsonStore) AddOne(product domain.Product) error {
-	// Load the data from a JSON file using the Load method
-	products, err := s.Load()
+	// Load the data from a JSON file using the LoadState method
+	state, err := s.LoadState()
 	if err != nil {
 		return err
 	}
 
-	// Update the product id and append it in the slice
-	product.Id = len(products) + 1
-	products = append(products, product)
+	// The product gets the ID following the highest one ever assigned, so deleted IDs are not reused
+	state.LastId++
+	product.Id = state.LastId
+	state.Products = append(state.Products, product)
 
 	// Save the data to the JSON file
-	return s.Save(products)
+	return s.SaveState(state)
 }
 
 // The UpdateOne method updates a single product in a JSON file.
@@ -124,7 +226,7 @@ func (s *jsonStore) UpdateOne(updatedProduct domain.Product) error {
 	}
 
 	// If no product was found, return an error
-	return errors.New("product not found")
+	return fmt.Errorf("%w: id %d", domain.ErrProductNotFound, updatedProduct.Id)
 }
 
 // The DeleteOne method deletes a single product from a JSON file.
@@ -144,5 +246,117 @@ func (s *jsonStore) DeleteOne(id int) error {
 	}
 
 	// If no product was found, return an error
-	return errors.New("product not found")
+	return fmt.Errorf("%w: id %d", domain.ErrProductNotFound, id)
+}
+
+/*
+Auxiliary method that decodes the products and the highest ID of a store file written as an object,
+whose opening brace was already read, up to its closing brace. The unknown fields are skipped.
+*/
+func (s *jsonStore) decodeObject(decoder *json.Decoder, state *State) error {
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch key {
+		case "last_id":
+			err = decoder.Decode(&state.LastId)
+		case "products":
+			var token json.Token
+			if token, err = decoder.Token(); err == nil && token != nil {
+				err = s.decodeProducts(decoder, token, state)
+			}
+		default:
+			var skipped json.RawMessage
+			err = decoder.Decode(&skipped)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	_, err := decoder.Token()
+	return err
+}
+
+/*
+Auxiliary method that decodes an array of products, whose opening bracket was already read as the
+given token, up to its closing bracket, reporting the progress.
+*/
+func (s *jsonStore) decodeProducts(decoder *json.Decoder, token json.Token, state *State) error {
+	if token != json.Delim('[') {
+		return fmt.Errorf("invalid products file: expected '[', found %v", token)
+	}
+	for decoder.More() {
+		var product domain.Product
+		if err := decoder.Decode(&product); err != nil {
+			return fmt.Errorf("decoding product %d: %w", len(state.Products)+1, err)
+		}
+		state.Products = append(state.Products, product)
+
+		if s.progress != nil && s.progressEvery > 0 && len(state.Products)%s.progressEvery == 0 {
+			s.progress(len(state.Products))
+		}
+	}
+	_, err := decoder.Token()
+	return err
+}
+
+/*
+Auxiliary function that writes the products as a JSON array, within an object along with the highest
+ID ever assigned if it is not the one of a product, so the files without deleted IDs keep the format
+of the previous versions.
+*/
+func encodeState(writer io.Writer, state State) error {
+	highest := 0
+	for _, product := range state.Products {
+		if product.Id > highest {
+			highest = product.Id
+		}
+	}
+	if state.LastId <= highest {
+		return encodeProducts(writer, state.Products)
+	}
+
+	if _, err := fmt.Fprintf(writer, `{"last_id":%d,"products":`, state.LastId); err != nil {
+		return err
+	}
+	if err := encodeProducts(writer, state.Products); err != nil {
+		return err
+	}
+	_, err := io.WriteString(writer, "}")
+	return err
+}
+
+// Auxiliary function that writes the products as a JSON array, one product at a time.
+func encodeProducts(writer io.Writer, products []domain.Product) error {
+	if _, err := io.WriteString(writer, "["); err != nil {
+		return err
+	}
+	for i, product := range products {
+		if i > 0 {
+			if _, err := io.WriteString(writer, ","); err != nil {
+				return err
+			}
+		}
+		data, err := json.Marshal(product)
+		if err != nil {
+			return err
+		}
+		if _, err = writer.Write(data); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(writer, "]")
+	return err
+}
+
+// Auxiliary function that syncs a directory to disk, so the files renamed into it survive a crash.
+func syncDir(path string) error {
+	dir, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+	return dir.Sync()
 }
@@ -0,0 +1,226 @@
+package store
+
+import (
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/pkg/lock"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestJsonStore_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "products.json")
+	products := []domain.Product{
+		{Id: 1, Name: "Oil", Quantity: 10, CodeValue: "A1", Status: domain.StatusPublished, Expiration: "15/12/2030", Price: 71.42},
+		{Id: 2, Name: "Rice", Quantity: 5, CodeValue: "B2", Expiration: "15/12/2030", Price: 10},
+		{Id: 3, Name: "Salt", Quantity: 1, CodeValue: "C3", Expiration: "15/12/2030", Price: 2},
+	}
+
+	var reports []int
+	jsonStore := NewJsonStore(path, WithProgress(2, func(records int) {
+		reports = append(reports, records)
+	}))
+	err := jsonStore.Save(products)
+	if err != nil {
+		panic(err)
+	}
+	loaded, err := jsonStore.Load()
+
+	// Assertions
+	assert.NoError(t, err)
+	assert.Equal(t, products, loaded)
+	assert.Equal(t, []int{2, 3}, reports)
+	assert.NoFileExists(t, path+".tmp")
+
+	// A truncated file is reported with the failing record
+	err = os.WriteFile(path, []byte(`[{"id":1},{"id":`), 0644)
+	if err != nil {
+		panic(err)
+	}
+	_, err = jsonStore.Load()
+	assert.ErrorContains(t, err, "decoding product 2")
+}
+
+func TestJsonStore_LastId(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "products.json")
+	jsonStore := NewJsonStore(path)
+	products := []domain.Product{{Id: 1, Name: "Oil"}, {Id: 2, Name: "Rice"}}
+
+	// The file is only an object when the highest ID is not the one of a product
+	errArray := jsonStore.SaveState(State{Products: products, LastId: 2})
+	array, _ := os.ReadFile(path)
+	errObject := jsonStore.SaveState(State{Products: products, LastId: 5})
+	object, _ := os.ReadFile(path)
+	loaded, errLoad := jsonStore.LoadState()
+	errAdd := jsonStore.AddOne(domain.Product{Name: "Salt"})
+	added, _ := jsonStore.LoadState()
+
+	// Assertions
+	assert.NoError(t, errArray)
+	assert.Equal(t, byte('['), array[0])
+	assert.NoError(t, errObject)
+	assert.Equal(t, byte('{'), object[0])
+	assert.NoError(t, errLoad)
+	assert.Equal(t, State{Products: products, LastId: 5}, loaded)
+	assert.NoError(t, errAdd)
+	assert.Equal(t, 6, added.Products[2].Id)
+	assert.Equal(t, 6, added.LastId)
+
+	// The journal entries raise the highest ID, which is never lowered
+	replayed := ReplayState(loaded, []Entry{
+		{Op: OpPut, Id: 7, Product: &domain.Product{Id: 7, Name: "Salt"}},
+		{Op: OpDelete, Id: 7, LastId: 8},
+		{Op: OpReplace, Products: products[:1], LastId: 3},
+	})
+	assert.Equal(t, State{Products: products[:1], LastId: 8}, replayed)
+}
+
+func TestJsonStore_CompressedEncrypted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "products.json.gz.enc")
+	key := []byte("0123456789abcdef0123456789abcdef")
+	products := []domain.Product{
+		{Id: 1, Name: "Oil", Quantity: 10, CodeValue: "A1", Status: domain.StatusPublished, Expiration: "15/12/2030", Price: 71.42},
+	}
+
+	err := NewJsonStore(path, WithEncryptionKey(key)).Save(products)
+	if err != nil {
+		panic(err)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		panic(err)
+	}
+
+	loaded, err := NewJsonStore(path, WithEncryptionKey(key)).Load()
+	_, errNoKey := NewJsonStore(path).Load()
+	_, errWrongKey := NewJsonStore(path, WithEncryptionKey([]byte("fedcba9876543210"))).Load()
+
+	// Assertions
+	assert.NoError(t, err)
+	assert.Equal(t, products, loaded)
+	assert.NotContains(t, string(raw), "Oil")
+	assert.ErrorIs(t, errNoKey, ErrEncryptionKeyRequired)
+	assert.ErrorIs(t, errWrongKey, ErrDecryptionFailed)
+
+	// Compressed files are detected by their content too
+	gzPath := filepath.Join(t.TempDir(), "products.json.gz")
+	err = NewJsonStore(gzPath).Save(products)
+	assert.NoError(t, err)
+	loaded, err = NewJsonStore(gzPath).Load()
+	assert.NoError(t, err)
+	assert.Equal(t, products, loaded)
+}
+
+func TestJournal(t *testing.T) {
+	dir := t.TempDir()
+	journal, err := OpenJournal(filepath.Join(dir, "products.journal"))
+	if err != nil {
+		panic(err)
+	}
+	defer journal.Close()
+
+	saved := []domain.Product{{Id: 1, Name: "Oil"}, {Id: 2, Name: "Rice"}}
+	_ = journal.Append(Entry{Op: OpPut, Id: 3, Product: &domain.Product{Id: 3, Name: "Salt"}})
+	_ = journal.Append(Entry{Op: OpPut, Id: 1, Product: &domain.Product{Id: 1, Name: "Olive oil"}})
+	_ = journal.Append(Entry{Op: OpDelete, Id: 2})
+
+	// A line left incomplete by a crash is ignored
+	file, err := os.OpenFile(filepath.Join(dir, "products.journal"), os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		panic(err)
+	}
+	_, _ = file.WriteString(`{"op":"delete","id":`)
+	_ = file.Close()
+
+	entries, err := journal.Entries()
+	replayed := Replay(saved, entries)
+
+	// Assertions
+	assert.NoError(t, err)
+	assert.Len(t, entries, 3)
+	assert.Equal(t, []domain.Product{{Id: 1, Name: "Olive oil"}, {Id: 3, Name: "Salt"}}, replayed)
+	assert.Equal(t, replayed, Replay(replayed, entries))
+
+	err = journal.Compact(func() error { return nil })
+	entries, _ = journal.Entries()
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestJournal_Flush(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "products.json")
+	journal, err := OpenJournal(path + ".journal")
+	if err != nil {
+		panic(err)
+	}
+	defer journal.Close()
+
+	products := []domain.Product{{Id: 1, Name: "Oil"}, {Id: 2, Name: "Rice"}}
+	_ = journal.Append(Entry{Op: OpPut, Id: 2, Product: &products[1]})
+	_ = journal.Append(Entry{Op: OpDelete, Id: 3})
+	jsonStore := NewJsonStore(path)
+
+	stats, err := journal.Flush(jsonStore, path, func() State { return State{Products: products} })
+	loaded, _ := jsonStore.Load()
+	entries, _ := journal.Entries()
+	info, _ := os.Stat(path)
+
+	// Assertions
+	assert.NoError(t, err)
+	assert.Equal(t, 2, stats.Records)
+	assert.Equal(t, info.Size(), stats.Bytes)
+	assert.Equal(t, 2, stats.JournalEntries)
+	assert.Positive(t, stats.JournalBytes)
+	assert.Positive(t, stats.Duration)
+	assert.Equal(t, products, loaded)
+	assert.Empty(t, entries)
+}
+
+func TestJournal_Shared(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "products.json.journal")
+	locker := lock.NewFileLock(filepath.Join(dir, "products.json.lock"), time.Minute)
+	first, err := OpenJournal(path)
+	if err != nil {
+		panic(err)
+	}
+	defer first.Close()
+	second, err := OpenJournal(path)
+	if err != nil {
+		panic(err)
+	}
+	defer second.Close()
+
+	refreshes := 0
+	first.WithLock(locker, time.Second)
+	second.WithLock(locker, time.Second).OnStale(func() error {
+		refreshes++
+		return nil
+	})
+
+	// The second instance is stale the first time, and again once the first one writes
+	unlock, err := second.Lock()
+	if err != nil {
+		panic(err)
+	}
+	unlock()
+	unlock, _ = second.Lock()
+	unlock()
+	unchangedRefreshes := refreshes
+
+	unlock, _ = first.Lock()
+	_ = first.Append(Entry{Op: OpDelete, Id: 1})
+	_, timeoutErr := second.WithLock(locker, 100*time.Millisecond).Lock()
+	unlock()
+	unlock, err = second.WithLock(locker, time.Second).Lock()
+	unlock()
+
+	// Assertions
+	assert.Equal(t, 1, unchangedRefreshes)
+	assert.ErrorIs(t, timeoutErr, lock.ErrTimeout)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, refreshes)
+}
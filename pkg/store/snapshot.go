@@ -0,0 +1,159 @@
+package store
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Layout of the timestamp in the names of the snapshot files.
+const snapshotTimeLayout = "20060102T150405Z"
+
+var (
+	ErrChecksumMismatch = errors.New("snapshot checksum mismatch")
+	ErrSnapshotNotFound = errors.New("snapshot not found")
+)
+
+/*
+The Snapshot struct is a point in time copy of every product, along with the SHA-256 checksum of
+the products, which detects any corruption or tampering before it is restored. The checksum of a
+decoded snapshot is the one of its products as they were stored, so the snapshots taken before a
+change of the fields of the products are still verified.
+*/
+type Snapshot struct {
+	CreatedAt time.Time        `json:"created_at"`
+	Count     int              `json:"count"`
+	Checksum  string           `json:"checksum"`
+	Products  []domain.Product `json:"products"`
+	// JSON of the products as stored, nil for the snapshots not decoded
+	raw json.RawMessage
+}
+
+// The NewSnapshot function returns a snapshot of the given products taken at the given time.
+func NewSnapshot(products []domain.Product, createdAt time.Time) (Snapshot, error) {
+	checksum, err := productsChecksum(products)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	return Snapshot{
+		CreatedAt: createdAt.UTC(),
+		Count:     len(products),
+		Checksum:  checksum,
+		Products:  products,
+	}, nil
+}
+
+// The UnmarshalJSON method decodes a snapshot, keeping the JSON of its products for the checksum.
+func (s *Snapshot) UnmarshalJSON(data []byte) error {
+	type snapshot Snapshot
+	var stored struct {
+		snapshot
+		Products json.RawMessage `json:"products"`
+	}
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return err
+	}
+
+	*s = Snapshot(stored.snapshot)
+	// The products of an empty snapshot may be stored as null, whose checksum is the one of no products
+	if len(stored.Products) == 0 || string(stored.Products) == "null" {
+		return nil
+	}
+	s.raw = stored.Products
+	return json.Unmarshal(s.raw, &s.Products)
+}
+
+// The Verify method checks that the products of the snapshot match its count and checksum.
+func (s Snapshot) Verify() error {
+	checksum, err := s.checksum()
+	if err != nil {
+		return err
+	}
+	if checksum != s.Checksum || len(s.Products) != s.Count {
+		return ErrChecksumMismatch
+	}
+	return nil
+}
+
+// The FileName method returns the name of the snapshot file, which contains its creation time.
+func (s Snapshot) FileName() string {
+	return "products-" + s.CreatedAt.UTC().Format(snapshotTimeLayout) + ".json"
+}
+
+// The WriteSnapshot function writes the snapshot in the given directory and returns the path of the file.
+func WriteSnapshot(dir string, snapshot Snapshot) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return "", err
+	}
+
+	// The file only appears under its final name once it is complete
+	path := filepath.Join(dir, snapshot.FileName())
+	if err = os.WriteFile(path+".tmp", data, 0644); err != nil {
+		return "", err
+	}
+	return path, os.Rename(path+".tmp", path)
+}
+
+/*
+The ReadSnapshot function reads the snapshot file with the given name from the directory. Only the
+base of the name is used, so no file outside the directory can be read.
+*/
+func ReadSnapshot(dir, name string) (Snapshot, error) {
+	data, err := os.ReadFile(filepath.Join(dir, filepath.Base(name)))
+	if errors.Is(err, os.ErrNotExist) {
+		return Snapshot{}, ErrSnapshotNotFound
+	}
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	var snapshot Snapshot
+	if err = json.Unmarshal(data, &snapshot); err != nil {
+		return Snapshot{}, err
+	}
+	return snapshot, nil
+}
+
+/*
+Auxiliary method that returns the checksum of the products of the snapshot: the one of their JSON
+as stored, without the insignificant spaces, for a decoded snapshot, or else the one of their
+encoding.
+*/
+func (s Snapshot) checksum() (string, error) {
+	if s.raw == nil {
+		return productsChecksum(s.Products)
+	}
+	var data bytes.Buffer
+	if err := json.Compact(&data, s.raw); err != nil {
+		return "", err
+	}
+	return dataChecksum(data.Bytes()), nil
+}
+
+// Auxiliary function that returns the SHA-256 checksum of the JSON encoding of the products.
+func productsChecksum(products []domain.Product) (string, error) {
+	if products == nil {
+		products = []domain.Product{}
+	}
+	data, err := json.Marshal(products)
+	if err != nil {
+		return "", err
+	}
+	return dataChecksum(data), nil
+}
+
+// Auxiliary function that returns the SHA-256 checksum of the data, prefixed with the name of the algorithm.
+func dataChecksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
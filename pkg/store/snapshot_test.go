@@ -0,0 +1,43 @@
+package store
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSnapshot_Verify(t *testing.T) {
+	dir := t.TempDir()
+
+	// A snapshot taken when the products had a field they no longer have
+	products := `[{"id":1,"name":"Oil","quantity":10,"code_value":"A1","is_published":true,"expiration":"15/12/2030","price":71.42}]`
+	stored := `{"created_at":"2030-01-01T00:00:00Z","count":1,"checksum":"` + dataChecksum([]byte(products)) +
+		`","products":` + products + `}`
+	if err := os.WriteFile(filepath.Join(dir, "old.json"), []byte(stored), 0644); err != nil {
+		panic(err)
+	}
+	tampered := strings.Replace(stored, "71.42", "1", 1)
+	if err := os.WriteFile(filepath.Join(dir, "tampered.json"), []byte(tampered), 0644); err != nil {
+		panic(err)
+	}
+
+	old, errOld := ReadSnapshot(dir, "old.json")
+	changed, errChanged := ReadSnapshot(dir, "tampered.json")
+	empty, errEmpty := NewSnapshot(nil, time.Now())
+	path, errWrite := WriteSnapshot(dir, empty)
+	written, errWritten := ReadSnapshot(dir, filepath.Base(path))
+
+	// Assertions
+	assert.NoError(t, errOld)
+	assert.NoError(t, old.Verify())
+	assert.Equal(t, "Oil", old.Products[0].Name)
+	assert.NoError(t, errChanged)
+	assert.ErrorIs(t, changed.Verify(), ErrChecksumMismatch)
+	assert.NoError(t, errEmpty)
+	assert.NoError(t, errWrite)
+	assert.NoError(t, errWritten)
+	assert.NoError(t, written.Verify())
+}
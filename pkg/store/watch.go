@@ -0,0 +1,66 @@
+package store
+
+import (
+	"github.com/fsnotify/fsnotify"
+	"log"
+	"path/filepath"
+	"time"
+)
+
+/*
+The Watcher struct notifies the changes of a file. The directory of the file is watched instead of
+the file itself, so the file can be replaced by a rename, like the Save method of the store and
+most editors do. A burst of changes is notified once, after the file has been quiet for a delay.
+*/
+type Watcher struct {
+	watcher *fsnotify.Watcher
+	path    string
+	delay   time.Duration
+}
+
+// The NewWatcher function returns a Watcher of the file at the given path.
+func NewWatcher(path string, delay time.Duration) (*Watcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err = watcher.Add(filepath.Dir(path)); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+	return &Watcher{
+		watcher: watcher,
+		path:    filepath.Clean(path),
+		delay:   delay,
+	}, nil
+}
+
+// The Run method calls the given function after every change of the file, until the watcher is closed.
+func (w *Watcher) Run(onChange func()) {
+	var quiet <-chan time.Time
+	for {
+		select {
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(event.Name) != w.path || event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+				continue
+			}
+			quiet = time.After(w.delay)
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("store: watching %s: %s\n", w.path, err)
+		case <-quiet:
+			quiet = nil
+			onChange()
+		}
+	}
+}
+
+// The Close method stops watching the file.
+func (w *Watcher) Close() error {
+	return w.watcher.Close()
+}
@@ -0,0 +1,77 @@
+/*
+Package testutil contains helpers for the tests that need products: deterministic fixtures and
+isolated stores in temporary directories, removed automatically when the test ends, so the tests
+never share nor modify a data file.
+*/
+package testutil
+
+import (
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/seed"
+	"github.com/JoseObreque/go-web/pkg/store"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Date the expiration dates of the fixtures are counted from, so they are the same on every run.
+var fixturesFrom = time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+/*
+The Fixtures function returns the given number of valid products with the IDs 1 to n. Every call
+returns the same products, with expiration dates in 2030 and 2031.
+*/
+func Fixtures(n int) []domain.Product {
+	products := seed.NewGenerator(1, fixturesFrom).Products(n)
+	for i := range products {
+		products[i].Id = i + 1
+	}
+	return products
+}
+
+/*
+The Store struct is a JSON store of products in a temporary directory, with its journal. Both
+files are closed and removed when the test ends.
+*/
+type Store struct {
+	store.Store
+	Path    string
+	Journal *store.Journal
+}
+
+// The NewStore function returns an isolated store holding the given products.
+func NewStore(t testing.TB, products []domain.Product, options ...store.Option) *Store {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "products.json")
+	jsonStore := store.NewJsonStore(path, options...)
+	if err := jsonStore.Save(products); err != nil {
+		t.Fatalf("saving the fixtures: %s", err)
+	}
+	journal, err := store.OpenJournal(path + ".journal")
+	if err != nil {
+		t.Fatalf("opening the journal: %s", err)
+	}
+	t.Cleanup(func() { _ = journal.Close() })
+
+	return &Store{
+		Store:   jsonStore,
+		Path:    path,
+		Journal: journal,
+	}
+}
+
+// The Products method returns the products saved in the store with the changes of the journal replayed.
+func (s *Store) Products(t testing.TB) []domain.Product {
+	t.Helper()
+
+	products, err := s.Load()
+	if err != nil {
+		t.Fatalf("loading the products: %s", err)
+	}
+	entries, err := s.Journal.Entries()
+	if err != nil {
+		t.Fatalf("reading the journal: %s", err)
+	}
+	return store.Replay(products, entries)
+}
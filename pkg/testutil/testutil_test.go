@@ -0,0 +1,30 @@
+package testutil
+
+import (
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/pkg/store"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNewStore(t *testing.T) {
+	fixtures := Fixtures(5)
+	first := NewStore(t, fixtures)
+	second := NewStore(t, fixtures)
+
+	// The changes of a store are not seen by another one
+	err := first.Journal.Append(store.Entry{Op: store.OpDelete, Id: 1})
+	if err != nil {
+		panic(err)
+	}
+	err = second.Save([]domain.Product{fixtures[4]})
+	if err != nil {
+		panic(err)
+	}
+
+	// Assertions
+	assert.Equal(t, fixtures, Fixtures(5))
+	assert.NotEqual(t, first.Path, second.Path)
+	assert.Equal(t, fixtures[1:], first.Products(t))
+	assert.Equal(t, []domain.Product{fixtures[4]}, second.Products(t))
+}
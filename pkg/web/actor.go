@@ -0,0 +1,30 @@
+package web
+
+import (
+	"github.com/gin-gonic/gin"
+	"strings"
+)
+
+// HeaderActor is the request header naming the person or system behind a shared credential.
+const HeaderActor = "X-Actor"
+
+// Key of the actor of the request in the gin context.
+const actorKey = "web.actor"
+
+/*
+The SetActor function records the actor of an authenticated request: the kind of credential that
+authenticated it, followed by the name in the X-Actor header, if any, like "token/alice". The
+authentication middlewares call it, so the actor cannot be set without a valid credential.
+*/
+func SetActor(c *gin.Context, credential string) {
+	actor := credential
+	if name := strings.TrimSpace(c.GetHeader(HeaderActor)); name != "" {
+		actor += "/" + name
+	}
+	c.Set(actorKey, actor)
+}
+
+// The Actor function returns the actor of an authenticated request, or an empty string.
+func Actor(c *gin.Context) string {
+	return c.GetString(actorKey)
+}
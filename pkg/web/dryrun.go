@@ -0,0 +1,40 @@
+package web
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"strconv"
+)
+
+// Preference of the clients asking for a dry run of a mutation in the Prefer header.
+const PreferDryRun = "handling=dry-run"
+
+var ErrInvalidDryRun = errors.New("invalid dry run flag")
+
+/*
+The DryRun function reports whether the client asked for a dry run of the request, with the
+dryRun query parameter or the handling=dry-run preference. It returns ErrInvalidDryRun if the query
+parameter is not a boolean.
+*/
+func DryRun(c *gin.Context) (bool, error) {
+	if value, ok := c.GetQuery("dryRun"); ok {
+		dryRun, err := strconv.ParseBool(value)
+		if err != nil {
+			return false, ErrInvalidDryRun
+		}
+		return dryRun, nil
+	}
+	return Prefers(c, PreferDryRun), nil
+}
+
+/*
+The WithDryRun function returns an Option marking the response of a dry run: if applied, the
+Preference-Applied header confirms the dry run to the clients asking for it in the Prefer header.
+*/
+func WithDryRun(applied bool) Option {
+	return func(c *gin.Context, response *Response) {
+		if applied && Prefers(c, PreferDryRun) {
+			c.Header("Preference-Applied", PreferDryRun)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package web
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"sync"
+)
+
+// Error code of the errors without a registered mapping.
+const CodeInternal = "internal_error"
+
+// The errorMapping struct associates an error with its HTTP status and its error code.
+type errorMapping struct {
+	target error
+	status int
+	code   string
+}
+
+var (
+	mappingsMu sync.RWMutex
+	mappings   []errorMapping
+)
+
+/*
+The RegisterError function maps an error to the HTTP status and the machine readable error code
+returned to the clients. Errors wrapping the target, as reported by errors.Is, use the same
+mapping. Registering the same target again replaces its mapping.
+*/
+func RegisterError(target error, status int, code string) {
+	mappingsMu.Lock()
+	defer mappingsMu.Unlock()
+
+	for i := range mappings {
+		if mappings[i].target == target {
+			mappings[i].status, mappings[i].code = status, code
+			return
+		}
+	}
+	mappings = append(mappings, errorMapping{target: target, status: status, code: code})
+}
+
+/*
+The StatusOf function returns the HTTP status and the error code registered for an error. Errors
+without a mapping are internal errors (500).
+*/
+func StatusOf(err error) (int, string) {
+	if mapping, ok := lookup(err); ok {
+		return mapping.status, mapping.code
+	}
+	return http.StatusInternalServerError, CodeInternal
+}
+
+/*
+The Error function emits a failed response whose status is the one registered for the error,
+so handlers do not need to decide it themselves.
+*/
+func Error(c *gin.Context, err error) {
+	status, _ := StatusOf(err)
+	Failure(c, status, err)
+}
+
+// Auxiliary function that returns the mapping of the first registered target matching the error.
+func lookup(err error) (errorMapping, bool) {
+	mappingsMu.RLock()
+	defer mappingsMu.RUnlock()
+
+	for _, mapping := range mappings {
+		if errors.Is(err, mapping.target) {
+			return mapping, true
+		}
+	}
+	return errorMapping{}, false
+}
+
+// Auxiliary function that returns the error code registered for an error, if any.
+func codeOf(err error) string {
+	if mapping, ok := lookup(err); ok {
+		return mapping.code
+	}
+	return ""
+}
@@ -0,0 +1,39 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestError(t *testing.T) {
+	errDuplicate := errors.New("duplicate")
+	RegisterError(errDuplicate, http.StatusConflict, "duplicate_code_value")
+
+	router := gin.New()
+	router.GET("/mapped", func(c *gin.Context) {
+		Error(c, fmt.Errorf("creating product: %w", errDuplicate))
+	})
+	router.GET("/unmapped", func(c *gin.Context) {
+		Error(c, errors.New("boom"))
+	})
+
+	mapped := httptest.NewRecorder()
+	router.ServeHTTP(mapped, httptest.NewRequest(http.MethodGet, "/mapped", nil))
+	unmapped := httptest.NewRecorder()
+	router.ServeHTTP(unmapped, httptest.NewRequest(http.MethodGet, "/unmapped", nil))
+
+	var response ErrorResponse
+	err := json.Unmarshal(mapped.Body.Bytes(), &response)
+
+	// Assertions
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, mapped.Code)
+	assert.Equal(t, "duplicate_code_value", response.ErrorCode)
+	assert.Equal(t, http.StatusInternalServerError, unmapped.Code)
+}
@@ -0,0 +1,170 @@
+package web
+
+import (
+	"bytes"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// DefaultLanguage is the language of the messages when the client accepts none of the registered ones.
+const DefaultLanguage = "en"
+
+// Params holds the values used by the message templates of an error, like the ID of a product.
+type Params map[string]interface{}
+
+// The paramsError struct attaches the template params to an error, which it wraps.
+type paramsError struct {
+	err    error
+	params Params
+}
+
+func (e *paramsError) Error() string {
+	return e.err.Error()
+}
+
+func (e *paramsError) Unwrap() error {
+	return e.err
+}
+
+/*
+The WithParams function attaches the given params to an error, so the templates of its localized
+message can use them (for example {{.id}}). The returned error still matches the original one
+through errors.Is.
+*/
+func WithParams(err error, params Params) error {
+	return &paramsError{err: err, params: params}
+}
+
+var (
+	catalogsMu sync.RWMutex
+	// Templates of the messages of every registered language, keyed by error code
+	catalogs = map[string]map[string]*template.Template{}
+)
+
+/*
+The RegisterMessages function adds the messages of a language to its catalog. The messages are
+keyed by error code and are text/template templates executed with the params of the error.
+*/
+func RegisterMessages(language string, messages map[string]string) error {
+	parsed := make(map[string]*template.Template, len(messages))
+	for code, message := range messages {
+		tmpl, err := template.New(code).Option("missingkey=zero").Parse(message)
+		if err != nil {
+			return err
+		}
+		parsed[code] = tmpl
+	}
+
+	catalogsMu.Lock()
+	defer catalogsMu.Unlock()
+	language = strings.ToLower(language)
+	if catalogs[language] == nil {
+		catalogs[language] = map[string]*template.Template{}
+	}
+	for code, tmpl := range parsed {
+		catalogs[language][code] = tmpl
+	}
+	return nil
+}
+
+/*
+The Language function returns the registered language that best matches the Accept-Language header
+of the request, or DefaultLanguage if none does. Regional variants fall back to their base language,
+so "es-CL" matches "es".
+*/
+func Language(c *gin.Context) string {
+	return languageOf(c.GetHeader("Accept-Language"))
+}
+
+// The AcceptedLanguages function returns the language tags of the Accept-Language header of the request, in lower case and sorted by preference.
+func AcceptedLanguages(c *gin.Context) []string {
+	return acceptedLanguages(c.GetHeader("Accept-Language"))
+}
+
+// Auxiliary function that returns the registered language that best matches an Accept-Language header.
+func languageOf(acceptLanguage string) string {
+	catalogsMu.RLock()
+	defer catalogsMu.RUnlock()
+
+	for _, tag := range acceptedLanguages(acceptLanguage) {
+		if _, ok := catalogs[tag]; ok {
+			return tag
+		}
+		if base, _, found := strings.Cut(tag, "-"); found {
+			if _, ok := catalogs[base]; ok {
+				return base
+			}
+		}
+	}
+	return DefaultLanguage
+}
+
+/*
+The localize function returns the message of an error in the given language. Errors without a
+message in that language use the default language, and otherwise their own message.
+*/
+func localize(language string, err error) string {
+	code := codeOf(err)
+	if code == "" {
+		return err.Error()
+	}
+
+	var params Params
+	var withParams *paramsError
+	if errors.As(err, &withParams) {
+		params = withParams.params
+	}
+
+	catalogsMu.RLock()
+	tmpl, ok := catalogs[language][code]
+	if !ok {
+		tmpl, ok = catalogs[DefaultLanguage][code]
+	}
+	catalogsMu.RUnlock()
+	if !ok {
+		return err.Error()
+	}
+
+	var message bytes.Buffer
+	if tmpl.Execute(&message, params) != nil {
+		return err.Error()
+	}
+	return message.String()
+}
+
+// Auxiliary function that returns the language tags of an Accept-Language header sorted by quality.
+func acceptedLanguages(header string) []string {
+	type weighted struct {
+		tag     string
+		quality float64
+	}
+
+	var languages []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, param, _ := strings.Cut(strings.TrimSpace(part), ";")
+		quality := 1.0
+		if value, found := strings.CutPrefix(strings.TrimSpace(param), "q="); found {
+			if q, err := strconv.ParseFloat(value, 64); err == nil {
+				quality = q
+			}
+		}
+		if tag == "" || tag == "*" || quality <= 0 {
+			continue
+		}
+		languages = append(languages, weighted{tag: strings.ToLower(tag), quality: quality})
+	}
+	sort.SliceStable(languages, func(i, j int) bool {
+		return languages[i].quality > languages[j].quality
+	})
+
+	tags := make([]string, len(languages))
+	for i, language := range languages {
+		tags[i] = language.tag
+	}
+	return tags
+}
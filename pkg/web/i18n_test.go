@@ -0,0 +1,43 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFailure_Localized(t *testing.T) {
+	errMissing := errors.New("item not found")
+	RegisterError(errMissing, http.StatusNotFound, "item_not_found")
+	err := RegisterMessages("es", map[string]string{"item_not_found": "artículo {{.id}} no encontrado"})
+	if err != nil {
+		panic(err)
+	}
+
+	router := gin.New()
+	router.GET("/items/7", func(c *gin.Context) {
+		Error(c, WithParams(errMissing, Params{"id": 7}))
+	})
+
+	spanish := httptest.NewRequest(http.MethodGet, "/items/7", nil)
+	spanish.Header.Set("Accept-Language", "fr;q=0.9, es-CL, en;q=0.5")
+	spanishRecorder := httptest.NewRecorder()
+	router.ServeHTTP(spanishRecorder, spanish)
+	englishRecorder := httptest.NewRecorder()
+	router.ServeHTTP(englishRecorder, httptest.NewRequest(http.MethodGet, "/items/7", nil))
+
+	var spanishResponse, englishResponse ErrorResponse
+	_ = json.Unmarshal(spanishRecorder.Body.Bytes(), &spanishResponse)
+	_ = json.Unmarshal(englishRecorder.Body.Bytes(), &englishResponse)
+
+	// Assertions
+	assert.Equal(t, "artículo 7 no encontrado", spanishResponse.Message)
+	assert.Equal(t, "es", spanishRecorder.Header().Get("Content-Language"))
+	assert.Equal(t, "item not found", englishResponse.Message)
+	assert.Equal(t, "item_not_found", englishResponse.ErrorCode)
+	assert.True(t, errors.Is(WithParams(errMissing, nil), errMissing))
+}
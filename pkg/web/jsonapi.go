@@ -0,0 +1,168 @@
+package web
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
+	"net/http"
+	"reflect"
+	"strconv"
+)
+
+// MIMEJSONAPI is the media type defined by the JSON:API specification (https://jsonapi.org).
+const MIMEJSONAPI = "application/vnd.api+json"
+
+/*
+The Resource interface must be implemented by any type that is returned as a JSON:API resource
+object. Types that do not implement it are rendered inside the top level "meta" member.
+*/
+type Resource interface {
+	ResourceType() string
+	ResourceID() string
+}
+
+// The ResourceObject struct represents a JSON:API resource object.
+type ResourceObject struct {
+	Type          string                 `json:"type"`
+	Id            string                 `json:"id"`
+	Attributes    map[string]interface{} `json:"attributes,omitempty"`
+	Relationships map[string]interface{} `json:"relationships,omitempty"`
+}
+
+// The Links struct contains the JSON:API links of a top level document.
+type Links struct {
+	Self  string `json:"self"`
+	First string `json:"first,omitempty"`
+	Prev  string `json:"prev,omitempty"`
+	Next  string `json:"next,omitempty"`
+	Last  string `json:"last,omitempty"`
+}
+
+// The Document struct represents a JSON:API top level document.
+type Document struct {
+	Data   interface{}   `json:"data,omitempty"`
+	Errors []ErrorObject `json:"errors,omitempty"`
+	Meta   interface{}   `json:"meta,omitempty"`
+	Links  *Links        `json:"links,omitempty"`
+}
+
+// The ErrorObject struct represents a JSON:API error object.
+type ErrorObject struct {
+	Status string       `json:"status"`
+	Code   string       `json:"code,omitempty"`
+	Title  string       `json:"title"`
+	Detail string       `json:"detail"`
+	Source *ErrorSource `json:"source,omitempty"`
+}
+
+// The ErrorSource struct points to the member of the request document that caused an error.
+type ErrorSource struct {
+	Pointer string `json:"pointer"`
+}
+
+// Auxiliary function that emits a JSON:API document with the proper content type.
+func writeJSONAPI(c *gin.Context, status int, document Document) {
+	c.Header("Content-Type", MIMEJSONAPI)
+	c.Render(status, render.JSON{Data: document})
+}
+
+/*
+The jsonAPISuccess function converts the given response into a JSON:API document. A Resource, or a
+slice of them, becomes the primary data; any other value is placed in the "meta" member, as well as
+the pagination metadata, whose links become the pagination links of the document.
+*/
+func jsonAPISuccess(c *gin.Context, status int, response Response) {
+	data := response.Data
+	document := Document{
+		Links: &Links{Self: c.Request.URL.String()},
+	}
+	if response.Meta != nil {
+		document.Meta = response.Meta
+		document.Links.First = response.Meta.First
+		document.Links.Prev = response.Meta.Prev
+		document.Links.Next = response.Meta.Next
+		document.Links.Last = response.Meta.Last
+	}
+
+	value := reflect.ValueOf(data)
+	switch {
+	case data == nil:
+	case value.Kind() == reflect.Slice:
+		resources := make([]ResourceObject, 0, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			resource, ok := value.Index(i).Interface().(Resource)
+			if !ok {
+				document.Meta = data
+				resources = nil
+				break
+			}
+			resources = append(resources, toResourceObject(resource))
+		}
+		if resources != nil {
+			document.Data = resources
+		}
+	default:
+		if resource, ok := data.(Resource); ok {
+			document.Data = toResourceObject(resource)
+		} else {
+			document.Meta = data
+		}
+	}
+
+	writeJSONAPI(c, status, document)
+}
+
+/*
+The jsonAPIFailure function emits a JSON:API document with a single error object, or with one
+error object per invalid field, pointing to its attribute, if the error lists them.
+*/
+func jsonAPIFailure(c *gin.Context, status int, err error, language string) {
+	fields := fieldsOf(err)
+	if len(fields) == 0 {
+		writeJSONAPI(c, status, Document{
+			Errors: []ErrorObject{
+				{
+					Status: strconv.Itoa(status),
+					Code:   codeOf(err),
+					Title:  http.StatusText(status),
+					Detail: localize(language, err),
+				},
+			},
+		})
+		return
+	}
+
+	errorObjects := make([]ErrorObject, len(fields))
+	for i, field := range fields {
+		errorObjects[i] = ErrorObject{
+			Status: strconv.Itoa(status),
+			Code:   codeOf(err),
+			Title:  localize(language, err),
+			Detail: field.Message,
+			Source: &ErrorSource{Pointer: "/data/attributes/" + field.Field},
+		}
+	}
+	writeJSONAPI(c, status, Document{Errors: errorObjects})
+}
+
+/*
+The toResourceObject function builds a resource object from the JSON representation of the given
+resource. Every JSON member except "id" becomes an attribute.
+*/
+func toResourceObject(resource Resource) ResourceObject {
+	object := ResourceObject{
+		Type: resource.ResourceType(),
+		Id:   resource.ResourceID(),
+	}
+
+	raw, err := json.Marshal(resource)
+	if err != nil {
+		return object
+	}
+	if err = json.Unmarshal(raw, &object.Attributes); err != nil {
+		return object
+	}
+	delete(object.Attributes, "id")
+
+	return object
+}
@@ -0,0 +1,126 @@
+package web
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"strconv"
+)
+
+// Defaults and limits of the page size requested through the page_size query parameter.
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// Header with the number of items in the whole list of a list response, paginated or not.
+const HeaderTotalCount = "X-Total-Count"
+
+var ErrInvalidPage = errors.New("invalid page, expected positive page and page_size values")
+
+/*
+The Meta struct contains the pagination metadata of a list response.
+
+	Total (int): Number of items in the whole list. Example: 500.
+	Page (int): Number of the current page, starting at 1. Example: 2.
+	PageSize (int): Maximum number of items per page. Example: 20.
+	First, Prev, Next, Last (string): Links to the other pages, empty if they do not exist.
+*/
+type Meta struct {
+	Total    int    `json:"total"`
+	Page     int    `json:"page"`
+	PageSize int    `json:"page_size"`
+	First    string `json:"first,omitempty"`
+	Prev     string `json:"prev,omitempty"`
+	Next     string `json:"next,omitempty"`
+	Last     string `json:"last,omitempty"`
+}
+
+// The Page struct represents the page of a list requested by the client.
+type Page struct {
+	Number int
+	Size   int
+}
+
+/*
+The ParsePage function reads the page and page_size query parameters. The boolean result is false
+when the client did not ask for a page, so the whole list must be returned.
+*/
+func ParsePage(c *gin.Context) (Page, bool, error) {
+	rawNumber, hasNumber := c.GetQuery("page")
+	rawSize, hasSize := c.GetQuery("page_size")
+	if !hasNumber && !hasSize {
+		return Page{}, false, nil
+	}
+
+	page := Page{Number: 1, Size: DefaultPageSize}
+	var err error
+	if hasNumber {
+		if page.Number, err = strconv.Atoi(rawNumber); err != nil || page.Number < 1 {
+			return Page{}, true, ErrInvalidPage
+		}
+	}
+	if hasSize {
+		if page.Size, err = strconv.Atoi(rawSize); err != nil || page.Size < 1 {
+			return Page{}, true, ErrInvalidPage
+		}
+	}
+	if page.Size > MaxPageSize {
+		page.Size = MaxPageSize
+	}
+	return page, true, nil
+}
+
+/*
+The Bounds method returns the range [start, end) of the items of the page in a list with the given
+number of items. Pages beyond the end of the list are empty.
+*/
+func (p Page) Bounds(total int) (int, int) {
+	start := (p.Number - 1) * p.Size
+	if start > total {
+		start = total
+	}
+	end := start + p.Size
+	if end > total {
+		end = total
+	}
+	return start, end
+}
+
+// The WithPagination function returns an Option that adds the metadata of the page to the response.
+func WithPagination(page Page, total int) Option {
+	return func(c *gin.Context, response *Response) {
+		lastPage := (total + page.Size - 1) / page.Size
+		if lastPage < 1 {
+			lastPage = 1
+		}
+
+		meta := &Meta{
+			Total:    total,
+			Page:     page.Number,
+			PageSize: page.Size,
+			First:    pageLink(c, 1, page.Size),
+			Last:     pageLink(c, lastPage, page.Size),
+		}
+		switch {
+		case page.Number > lastPage+1:
+			// Pages beyond the end go back to the last one
+			meta.Prev = meta.Last
+		case page.Number > 1:
+			meta.Prev = pageLink(c, page.Number-1, page.Size)
+		}
+		if page.Number < lastPage {
+			meta.Next = pageLink(c, page.Number+1, page.Size)
+		}
+		response.Meta = meta
+	}
+}
+
+// Auxiliary function that returns the URL of the request pointing to another page.
+func pageLink(c *gin.Context, number, size int) string {
+	link := *c.Request.URL
+	query := link.Query()
+	query.Set("page", strconv.Itoa(number))
+	query.Set("page_size", strconv.Itoa(size))
+	link.RawQuery = query.Encode()
+	return link.RequestURI()
+}
@@ -0,0 +1,40 @@
+package web
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
+	"net/http"
+)
+
+// MIMEProblemJSON is the media type of the problem details defined by RFC 7807.
+const MIMEProblemJSON = "application/problem+json"
+
+/*
+The ProblemDetails struct represents an RFC 7807 problem details object. The ErrorId extension
+identifies the occurrence of the problem in the server logs.
+*/
+type ProblemDetails struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	ErrorId  string `json:"error_id,omitempty"`
+}
+
+/*
+The Problem function emits a problem details response and aborts the request. Problems without a
+type use "about:blank", and the title defaults to the text of the status.
+*/
+func Problem(c *gin.Context, problem ProblemDetails) {
+	if problem.Type == "" {
+		problem.Type = "about:blank"
+	}
+	if problem.Title == "" {
+		problem.Title = http.StatusText(problem.Status)
+	}
+
+	c.Header("Content-Type", MIMEProblemJSON)
+	c.Abort()
+	c.Render(problem.Status, render.JSON{Data: problem})
+}
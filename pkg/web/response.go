@@ -1,28 +1,125 @@
 package web
 
 import (
+	"encoding/json"
+	"errors"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/gin-gonic/gin/render"
 	"net/http"
+	"strings"
 )
 
+// Media types that can be negotiated through the Accept header. JSON is the default.
+var offeredFormats = []string{
+	binding.MIMEJSON,
+	binding.MIMEMSGPACK,
+	binding.MIMEMSGPACK2,
+	MIMEJSONAPI,
+}
+
 /*
 The ErrorResponse struct represents the response from the server when an error occurs.
 
 	Status (int): HTTP Status Code as an integer. Example: 200.
 	Code (string): HTTP Status Code as a string. Example: "OK".
 	Message (string): Error message.
+	ErrorCode (string): Machine readable code of the error, if registered. Example: "duplicate_code_value".
+	Fields ([]FieldError): Invalid fields of the request, if the error lists them.
 */
 type ErrorResponse struct {
-	Status  int    `json:"status"`
-	Code    string `json:"code"`
+	Status    int          `json:"status"`
+	Code      string       `json:"code"`
+	Message   string       `json:"message"`
+	ErrorCode string       `json:"error_code,omitempty"`
+	Fields    []FieldError `json:"fields,omitempty"`
+}
+
+/*
+The FieldError struct describes an invalid field of a request.
+
+	Field (string): Name of the field in the request body. Example: "quantity".
+	Rule (string): Machine readable name of the broken rule. Example: "min".
+	Message (string): Description of the problem. Example: "must not be negative".
+*/
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
 	Message string `json:"message"`
 }
 
+// The fieldsError struct attaches the invalid fields of a request to an error, which it wraps.
+type fieldsError struct {
+	err    error
+	fields []FieldError
+}
+
+func (e *fieldsError) Error() string {
+	return e.err.Error()
+}
+
+func (e *fieldsError) Unwrap() error {
+	return e.err
+}
+
+/*
+The WithFields function attaches the invalid fields of a request to an error, so the failed
+responses list them. The returned error still matches the original one through errors.Is.
+*/
+func WithFields(err error, fields []FieldError) error {
+	return &fieldsError{err: err, fields: fields}
+}
+
+// Auxiliary function that returns the invalid fields attached to an error, if any.
+func fieldsOf(err error) []FieldError {
+	var withFields *fieldsError
+	if errors.As(err, &withFields) {
+		return withFields.fields
+	}
+	return nil
+}
+
 /*
 The Response struct represents a successful response from the server.
 */
 type Response struct {
 	Data interface{} `json:"data"`
+	Meta *Meta       `json:"meta,omitempty"`
+}
+
+/*
+The Option type customizes a response before its body is written, for example by adding headers
+or the pagination metadata.
+*/
+type Option func(c *gin.Context, response *Response)
+
+// The WithHeader function returns an Option that sets the given header in the response.
+func WithHeader(key, value string) Option {
+	return func(c *gin.Context, response *Response) {
+		c.Header(key, value)
+	}
+}
+
+// The WithLocation function returns an Option that sets the Location header of the response.
+func WithLocation(location string) Option {
+	return WithHeader("Location", location)
+}
+
+/*
+The Prefers function reports whether the client asked for the given preference (for example
+"return=minimal") in the Prefer header defined by RFC 7240.
+*/
+func Prefers(c *gin.Context, preference string) bool {
+	for _, header := range c.Request.Header.Values("Prefer") {
+		for _, token := range strings.Split(header, ",") {
+			// Parameters of the preference (";param=value") are ignored
+			token, _, _ = strings.Cut(token, ";")
+			if strings.EqualFold(strings.TrimSpace(token), preference) {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 /*
@@ -30,11 +127,43 @@ The Success function emits a successful response to the client.
 
 	Status (int): HTTP Status Code as an integer. Example: 200.
 	Data (string): Any data required in the response to the client.
+	Options (...Option): Customizations of the response, like extra headers.
 */
-func Success(c *gin.Context, status int, data interface{}) {
-	c.JSON(status, Response{
+func Success(c *gin.Context, status int, data interface{}, options ...Option) {
+	response := Response{
 		Data: data,
-	})
+	}
+	for _, option := range options {
+		option(c, &response)
+	}
+
+	// Some statuses must not carry a body, not even an empty envelope
+	if !bodyAllowed(status) {
+		c.Status(status)
+		return
+	}
+
+	if c.NegotiateFormat(offeredFormats...) == MIMEJSONAPI {
+		jsonAPISuccess(c, status, response)
+		return
+	}
+
+	write(c, status, response)
+}
+
+/*
+The Created function emits a 201 response with the created resource in the envelope. The options
+usually include its Location.
+*/
+func Created(c *gin.Context, data interface{}, options ...Option) {
+	Success(c, http.StatusCreated, data, options...)
+}
+
+/*
+The NoContent function emits a 204 response without body, just the headers set by the options.
+*/
+func NoContent(c *gin.Context, options ...Option) {
+	Success(c, http.StatusNoContent, nil, options...)
 }
 
 /*
@@ -44,9 +173,94 @@ The Failure function emits a failed response to the client.
 	err (error): The error associated to the failed response to the client.
 */
 func Failure(c *gin.Context, status int, err error) {
-	c.JSON(status, ErrorResponse{
-		Status:  status,
-		Code:    http.StatusText(status),
-		Message: err.Error(),
-	})
+	// Server errors are attached to the request, so the error reporters receive them
+	if status >= http.StatusInternalServerError {
+		_ = c.Error(err)
+	}
+
+	// The message is localized in the language accepted by the client
+	language := Language(c)
+	c.Header("Content-Language", language)
+	c.Writer.Header().Add("Vary", "Accept-Language")
+
+	if c.NegotiateFormat(offeredFormats...) == MIMEJSONAPI {
+		jsonAPIFailure(c, status, err, language)
+		return
+	}
+
+	write(c, status, errorResponse(status, language, err))
+}
+
+/*
+The WriteSuccess function emits a successful JSON response through a plain http.ResponseWriter, for
+the handlers served without gin.
+*/
+func WriteSuccess(w http.ResponseWriter, status int, data interface{}) {
+	if !bodyAllowed(status) {
+		w.WriteHeader(status)
+		return
+	}
+	writeJSON(w, status, Response{Data: data})
+}
+
+/*
+The WriteError function emits the failed JSON response of an error through a plain
+http.ResponseWriter, with the status registered for the error and the message in the language
+accepted by the client, for the handlers served without gin.
+*/
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	status, _ := StatusOf(err)
+	language := languageOf(r.Header.Get("Accept-Language"))
+	w.Header().Set("Content-Language", language)
+	w.Header().Add("Vary", "Accept-Language")
+	writeJSON(w, status, errorResponse(status, language, err))
+}
+
+// Auxiliary function that returns the body of the failed response of an error, in the given language.
+func errorResponse(status int, language string, err error) ErrorResponse {
+	return ErrorResponse{
+		Status:    status,
+		Code:      http.StatusText(status),
+		Message:   localize(language, err),
+		ErrorCode: codeOf(err),
+		Fields:    fieldsOf(err),
+	}
+}
+
+// Auxiliary function that writes a JSON body through a plain http.ResponseWriter.
+func writeJSON(w http.ResponseWriter, status int, obj interface{}) {
+	// Marshaled like gin does, so both bodies are byte for byte the same
+	body, err := json.Marshal(obj)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
+
+/*
+The write function renders the given object using the encoding negotiated with the client through
+the Accept header. Clients asking for application/msgpack (or application/x-msgpack) receive a
+MessagePack body; everyone else receives JSON.
+*/
+func write(c *gin.Context, status int, obj interface{}) {
+	switch c.NegotiateFormat(offeredFormats...) {
+	case binding.MIMEMSGPACK, binding.MIMEMSGPACK2:
+		c.Render(status, render.MsgPack{Data: obj})
+	default:
+		c.JSON(status, obj)
+	}
+}
+
+// Auxiliary function that reports whether a response with the given status may have a body (RFC 9110).
+func bodyAllowed(status int) bool {
+	switch {
+	case status >= 100 && status <= 199:
+		return false
+	case status == http.StatusNoContent, status == http.StatusNotModified:
+		return false
+	}
+	return true
 }
@@ -0,0 +1,42 @@
+package web
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNoContentAndCreated(t *testing.T) {
+	router := gin.New()
+	router.DELETE("/items/1", func(c *gin.Context) {
+		NoContent(c, WithHeader("X-Deleted", "1"))
+	})
+	router.POST("/items", func(c *gin.Context) {
+		Created(c, map[string]int{"id": 1}, WithLocation("/items/1"))
+	})
+
+	deleted := httptest.NewRecorder()
+	router.ServeHTTP(deleted, httptest.NewRequest(http.MethodDelete, "/items/1", nil))
+	created := httptest.NewRecorder()
+	router.ServeHTTP(created, httptest.NewRequest(http.MethodPost, "/items", nil))
+
+	// Assertions
+	assert.Equal(t, http.StatusNoContent, deleted.Code)
+	assert.Empty(t, deleted.Body.String())
+	assert.Equal(t, "1", deleted.Header().Get("X-Deleted"))
+	assert.Equal(t, http.StatusCreated, created.Code)
+	assert.Equal(t, "/items/1", created.Header().Get("Location"))
+	assert.JSONEq(t, `{"data":{"id":1}}`, created.Body.String())
+}
+
+func TestPage_Bounds(t *testing.T) {
+	// Assertions
+	start, end := Page{Number: 2, Size: 20}.Bounds(30)
+	assert.Equal(t, 20, start)
+	assert.Equal(t, 30, end)
+	start, end = Page{Number: 3, Size: 20}.Bounds(30)
+	assert.Equal(t, 30, start)
+	assert.Equal(t, 30, end)
+}
@@ -0,0 +1,33 @@
+package web
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// HeaderTenant is the request header naming the tenant whose catalog is requested.
+const HeaderTenant = "X-Tenant"
+
+// Keys of the tenant of the request in the gin context.
+const (
+	tenantKey       = "web.tenant"
+	tenantByKeyFlag = "web.tenant_key"
+)
+
+/*
+The SetTenant function records the tenant of a request, and whether it was named by a tenant API
+key, which then authenticates the changes of its catalog.
+*/
+func SetTenant(c *gin.Context, tenant string, byKey bool) {
+	c.Set(tenantKey, tenant)
+	c.Set(tenantByKeyFlag, byKey)
+}
+
+// The Tenant function returns the tenant of a request, or an empty string for the main catalog.
+func Tenant(c *gin.Context) string {
+	return c.GetString(tenantKey)
+}
+
+// The TenantKey function reports whether the request was made with the API key of its tenant.
+func TenantKey(c *gin.Context) bool {
+	return c.GetBool(tenantByKeyFlag)
+}
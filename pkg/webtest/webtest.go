@@ -0,0 +1,154 @@
+/*
+Package webtest is a client of the API for the tests: it serves the requests through httptest,
+attaches the authentication headers, encodes the bodies as JSON and decodes the responses,
+unwrapping the data of the response envelope.
+*/
+package webtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/JoseObreque/go-web/pkg/web"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// The Client struct serves requests on a handler, with the same headers on every request.
+type Client struct {
+	t       testing.TB
+	handler http.Handler
+	header  http.Header
+}
+
+// The NewClient function returns a client serving the requests on the given handler, usually a gin engine.
+func NewClient(t testing.TB, handler http.Handler) *Client {
+	return &Client{
+		t:       t,
+		handler: handler,
+		header:  http.Header{},
+	}
+}
+
+// The WithHeader method returns a copy of the client sending the given header on every request.
+func (c *Client) WithHeader(key, value string) *Client {
+	client := *c
+	client.header = c.header.Clone()
+	client.header.Set(key, value)
+	return &client
+}
+
+// The WithToken method returns a copy of the client authenticated with the given API token.
+func (c *Client) WithToken(token string) *Client {
+	return c.WithHeader("token", token)
+}
+
+// The Get method serves a GET request.
+func (c *Client) Get(url string) *Response {
+	return c.Do(http.MethodGet, url, nil)
+}
+
+// The Post method serves a POST request with the given body.
+func (c *Client) Post(url string, body interface{}) *Response {
+	return c.Do(http.MethodPost, url, body)
+}
+
+// The Put method serves a PUT request with the given body.
+func (c *Client) Put(url string, body interface{}) *Response {
+	return c.Do(http.MethodPut, url, body)
+}
+
+// The Patch method serves a PATCH request with the given body.
+func (c *Client) Patch(url string, body interface{}) *Response {
+	return c.Do(http.MethodPatch, url, body)
+}
+
+// The Delete method serves a DELETE request.
+func (c *Client) Delete(url string) *Response {
+	return c.Do(http.MethodDelete, url, nil)
+}
+
+/*
+The Do method serves a request and returns its response. A string or []byte body is sent as is,
+any other body but nil is encoded as JSON. The requests with a body are sent as application/json
+unless the client sets another Content-Type.
+*/
+func (c *Client) Do(method, url string, body interface{}) *Response {
+	c.t.Helper()
+
+	request := httptest.NewRequest(method, url, c.encode(body))
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	for key, values := range c.header {
+		request.Header[key] = values
+	}
+
+	recorder := httptest.NewRecorder()
+	c.handler.ServeHTTP(recorder, request)
+	return &Response{
+		ResponseRecorder: recorder,
+		t:                c.t,
+	}
+}
+
+// Auxiliary method that returns the reader of a request body.
+func (c *Client) encode(body interface{}) io.Reader {
+	c.t.Helper()
+
+	switch body := body.(type) {
+	case nil:
+		return nil
+	case string:
+		return bytes.NewBufferString(body)
+	case []byte:
+		return bytes.NewBuffer(body)
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		c.t.Fatalf("webtest: could not encode the request body: %s", err)
+	}
+	return bytes.NewBuffer(data)
+}
+
+// The Response struct is the recorded response of a request.
+type Response struct {
+	*httptest.ResponseRecorder
+	t testing.TB
+}
+
+// The Error method returns the error of the response.
+func (r *Response) Error() web.ErrorResponse {
+	r.t.Helper()
+	return Decode[web.ErrorResponse](r)
+}
+
+// The Decode function returns the JSON body of the response decoded as T.
+func Decode[T any](r *Response) T {
+	r.t.Helper()
+
+	var value T
+	if err := json.Unmarshal(r.Body.Bytes(), &value); err != nil {
+		r.t.Fatalf("webtest: could not decode the response %q: %s", r.Body.String(), err)
+	}
+	return value
+}
+
+// The Data function returns the data of the response envelope decoded as T.
+func Data[T any](r *Response) T {
+	r.t.Helper()
+	data, _ := Envelope[T](r)
+	return data
+}
+
+// The Envelope function returns the data of the response envelope decoded as T and its pagination metadata, if any.
+func Envelope[T any](r *Response) (T, *web.Meta) {
+	r.t.Helper()
+
+	envelope := Decode[struct {
+		Data T         `json:"data"`
+		Meta *web.Meta `json:"meta"`
+	}](r)
+	return envelope.Data, envelope.Meta
+}
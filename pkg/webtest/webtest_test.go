@@ -0,0 +1,41 @@
+package webtest
+
+import (
+	"github.com/JoseObreque/go-web/pkg/web"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestClient(t *testing.T) {
+	type item struct {
+		Name string `json:"name"`
+	}
+
+	router := gin.New()
+	router.POST("/items", func(c *gin.Context) {
+		if c.GetHeader("token") != "secret" {
+			c.JSON(http.StatusUnauthorized, web.ErrorResponse{Status: http.StatusUnauthorized, Code: "Unauthorized"})
+			return
+		}
+		var body item
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, web.ErrorResponse{Status: http.StatusBadRequest, Message: err.Error()})
+			return
+		}
+		c.JSON(http.StatusCreated, web.Response{Data: body, Meta: &web.Meta{Total: 1}})
+	})
+	client := NewClient(t, router)
+
+	created := client.WithToken("secret").Post("/items", item{Name: "Milk"})
+	data, meta := Envelope[item](created)
+	unauthorized := client.Post("/items", `{"name":"Oil"}`)
+
+	// Assertions: the token of the copy is not sent by the original client
+	assert.Equal(t, http.StatusCreated, created.Code)
+	assert.Equal(t, item{Name: "Milk"}, data)
+	assert.Equal(t, 1, meta.Total)
+	assert.Equal(t, http.StatusUnauthorized, unauthorized.Code)
+	assert.Equal(t, "Unauthorized", unauthorized.Error().Code)
+}
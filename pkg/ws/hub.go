@@ -0,0 +1,230 @@
+package ws
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/JoseObreque/go-web/pkg/events"
+	"github.com/gorilla/websocket"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Keep-alive and write settings of every connection.
+const (
+	writeWait      = 10 * time.Second
+	pongWait       = 60 * time.Second
+	pingPeriod     = pongWait * 9 / 10
+	maxMessageSize = 1024
+	sendBuffer     = 64
+)
+
+var (
+	ErrTooManyConnections = errors.New("too many websocket connections")
+)
+
+/*
+The Message struct represents a command sent by a client. Action is either "subscribe" or
+"unsubscribe", and Topic is the name of the topic, as returned by the hub TopicsFunc.
+*/
+type Message struct {
+	Action string `json:"action"`
+	Topic  string `json:"topic"`
+}
+
+// TopicsFunc returns the topics an event belongs to.
+type TopicsFunc func(event events.Event) []string
+
+// The client struct represents a single websocket connection and its subscriptions.
+type client struct {
+	conn   *websocket.Conn
+	send   chan []byte
+	mu     sync.RWMutex
+	topics map[string]bool
+}
+
+/*
+The Hub struct keeps track of the connected clients and broadcasts every event of the bus to the
+clients subscribed to any of its topics.
+*/
+type Hub struct {
+	mu         sync.RWMutex
+	clients    map[*client]struct{}
+	maxClients int
+	topicsOf   TopicsFunc
+	upgrader   websocket.Upgrader
+}
+
+/*
+The NewHub function returns a new Hub that accepts up to maxClients simultaneous connections and
+uses topicsOf to route the events to the subscribed clients.
+*/
+func NewHub(maxClients int, topicsOf TopicsFunc) *Hub {
+	return &Hub{
+		clients:    make(map[*client]struct{}),
+		maxClients: maxClients,
+		topicsOf:   topicsOf,
+	}
+}
+
+/*
+The Run method subscribes the hub to the bus and broadcasts the received events until the
+subscription is closed. It is meant to be run in its own goroutine.
+*/
+func (h *Hub) Run(bus events.Bus) {
+	subscription, unsubscribe := bus.Subscribe()
+	defer unsubscribe()
+
+	for event := range subscription {
+		h.broadcast(event)
+	}
+}
+
+// The Count method returns the number of connected clients.
+func (h *Hub) Count() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.clients)
+}
+
+/*
+The Serve method upgrades the request to a websocket connection and registers the client. It
+returns ErrTooManyConnections, without upgrading, if the hub is already full.
+*/
+func (h *Hub) Serve(w http.ResponseWriter, r *http.Request) error {
+	h.mu.Lock()
+	if h.maxClients > 0 && len(h.clients) >= h.maxClients {
+		h.mu.Unlock()
+		return ErrTooManyConnections
+	}
+	// Reserve the slot before upgrading so concurrent requests can't exceed the limit
+	c := &client{
+		send:   make(chan []byte, sendBuffer),
+		topics: make(map[string]bool),
+	}
+	h.clients[c] = struct{}{}
+	h.mu.Unlock()
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		h.unregister(c)
+		return err
+	}
+	c.conn = conn
+
+	go h.writePump(c)
+	go h.readPump(c)
+	return nil
+}
+
+// Auxiliary method that sends an event to every client subscribed to any of its topics.
+func (h *Hub) broadcast(event events.Event) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	topics := h.topicsOf(event)
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for c := range h.clients {
+		if !c.subscribedToAny(topics) {
+			continue
+		}
+		// Slow clients lose events instead of blocking the hub
+		select {
+		case c.send <- payload:
+		default:
+		}
+	}
+}
+
+// Auxiliary method that removes a client from the hub.
+func (h *Hub) unregister(c *client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.clients[c]; ok {
+		delete(h.clients, c)
+		close(c.send)
+	}
+}
+
+/*
+The readPump method processes the subscription messages of a client until the connection fails
+or the client stops answering the pings.
+*/
+func (h *Hub) readPump(c *client) {
+	defer func() {
+		h.unregister(c)
+		_ = c.conn.Close()
+	}()
+
+	c.conn.SetReadLimit(maxMessageSize)
+	_ = c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		var message Message
+		if err := c.conn.ReadJSON(&message); err != nil {
+			var syntaxErr *json.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				continue
+			}
+			return
+		}
+
+		c.mu.Lock()
+		switch message.Action {
+		case "subscribe":
+			c.topics[message.Topic] = true
+		case "unsubscribe":
+			delete(c.topics, message.Topic)
+		}
+		c.mu.Unlock()
+	}
+}
+
+// The writePump method writes the queued events to the client and pings it periodically.
+func (h *Hub) writePump(c *client) {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		_ = c.conn.Close()
+	}()
+
+	for {
+		select {
+		case payload, ok := <-c.send:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if !ok {
+				_ = c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := c.conn.WriteMessage(websocket.TextMessage, payload); err != nil {
+				return
+			}
+		case <-ticker.C:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Auxiliary method that checks if the client is subscribed to any of the given topics.
+func (c *client) subscribedToAny(topics []string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, topic := range topics {
+		if c.topics[topic] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package ws
+
+import (
+	"github.com/JoseObreque/go-web/pkg/events"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func createServerForTestHub(maxClients int) (*httptest.Server, events.Bus, *Hub) {
+	bus := events.NewBus()
+	hub := NewHub(maxClients, func(event events.Event) []string {
+		return []string{"products", event.Type}
+	})
+	go hub.Run(bus)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := hub.Serve(w, r); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		}
+	}))
+	return server, bus, hub
+}
+
+func dialTestHub(server *httptest.Server) (*websocket.Conn, *http.Response, error) {
+	return websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+}
+
+func TestHub_Subscriptions(t *testing.T) {
+	server, bus, hub := createServerForTestHub(10)
+	defer server.Close()
+
+	conn, _, err := dialTestHub(server)
+	if err != nil {
+		panic(err)
+	}
+	defer conn.Close()
+
+	// Subscribe to a single topic and wait for the hub to process it
+	err = conn.WriteJSON(Message{Action: "subscribe", Topic: "product.deleted"})
+	if err != nil {
+		panic(err)
+	}
+	assert.Eventually(t, func() bool { return hub.Count() == 1 }, time.Second, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+
+	// Publish an event of another topic, then one of the subscribed topic
+	bus.Publish(events.Event{Type: "product.created", Id: 1})
+	bus.Publish(events.Event{Type: "product.deleted", Id: 2})
+
+	// Only the subscribed event is received
+	var received events.Event
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	err = conn.ReadJSON(&received)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "product.deleted", received.Type)
+	assert.Equal(t, 2, received.Id)
+}
+
+func TestHub_ConnectionLimit(t *testing.T) {
+	server, _, _ := createServerForTestHub(1)
+	defer server.Close()
+
+	conn, _, err := dialTestHub(server)
+	if err != nil {
+		panic(err)
+	}
+	defer conn.Close()
+
+	_, response, err := dialTestHub(server)
+
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, response.StatusCode)
+}
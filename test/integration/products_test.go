@@ -0,0 +1,150 @@
+//go:build integration
+
+/*
+Package integration runs the products API against real databases started with testcontainers: the
+full handler, service and SQL repository stack over HTTP. It needs Docker and runs with
+go test -tags integration ./test/integration/...
+*/
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/JoseObreque/go-web/cmd/server/router"
+	"github.com/JoseObreque/go-web/internal/domain"
+	"github.com/JoseObreque/go-web/internal/product"
+	"github.com/JoseObreque/go-web/pkg/events"
+	"github.com/JoseObreque/go-web/pkg/migrate"
+	"github.com/JoseObreque/go-web/pkg/webtest"
+	"github.com/gin-gonic/gin"
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+	"net/http"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+)
+
+// Token of the protected endpoints in the tests.
+const token = "integration"
+
+/*
+The startPostgres function starts a PostgreSQL container, applies the product migrations and
+returns the database. The container is terminated when the test finishes.
+*/
+func startPostgres(t *testing.T) *sql.DB {
+	ctx := context.Background()
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "postgres:15-alpine",
+			ExposedPorts: []string{"5432/tcp"},
+			Env: map[string]string{
+				"POSTGRES_USER":     "go-web",
+				"POSTGRES_PASSWORD": "go-web",
+				"POSTGRES_DB":       "products",
+			},
+			// The server restarts once after the initialization, so it is ready on the second message
+			WaitingFor: wait.ForLog("database system is ready to accept connections").
+				WithOccurrence(2).
+				WithStartupTimeout(time.Minute),
+		},
+		Started: true,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = container.Terminate(ctx)
+	})
+
+	host, err := container.Host(ctx)
+	require.NoError(t, err)
+	port, err := container.MappedPort(ctx, "5432")
+	require.NoError(t, err)
+
+	dsn := fmt.Sprintf("postgres://go-web:go-web@%s:%s/products?sslmode=disable", host, port.Port())
+	db, err := sql.Open("postgres", dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+
+	_, err = migrate.Up(db, product.Migrations())
+	require.NoError(t, err)
+	return db
+}
+
+// The newServer function returns the API served over the products of the given database.
+func newServer(db *sql.DB) *gin.Engine {
+	if err := os.Setenv("TOKEN", token); err != nil {
+		panic(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	service := product.NewService(product.NewSQLRepository(db), events.NewBus())
+	router.NewRouter(engine, router.Dependencies{
+		Products: service,
+		Bus:      events.NewBus(),
+	}).MapRoutes()
+	return engine
+}
+
+func TestPostgres_ProductLifecycle(t *testing.T) {
+	db := startPostgres(t)
+	client := webtest.NewClient(t, newServer(db)).WithToken(token)
+	pineapple := domain.Product{Name: "Pineapple", Quantity: 10, CodeValue: "PIN01", Status: domain.StatusPublished, Expiration: "25/08/2030", Price: 299}
+
+	// Create
+	response := client.Post("/api/v1/products", pineapple)
+	created := webtest.Data[domain.Product](response)
+	assert.Equal(t, http.StatusCreated, response.Code)
+	assert.Equal(t, 1, created.Id)
+
+	// Duplicated code value
+	response = client.Post("/api/v1/products", `{"name":"Other","quantity":1,"code_value":"PIN01","expiration":"25/08/2030","price":1}`)
+	assert.Equal(t, http.StatusConflict, response.Code)
+
+	// Read back from the database
+	response = client.Get("/api/v1/products/1")
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, created, webtest.Data[domain.Product](response))
+
+	// Partial update
+	response = client.Patch("/api/v1/products/1", `{"price":350,"status":"archived"}`)
+	patched := webtest.Data[domain.Product](response)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, 350.0, patched.Price)
+	assert.Equal(t, domain.StatusArchived, patched.Status)
+	assert.Equal(t, "Pineapple", patched.Name)
+
+	// Filter expression, translated to the WHERE clause of the query
+	response = client.Get("/api/v1/products?filter=" + url.QueryEscape(
+		`price > 300 AND is_published = false AND status = 'archived' AND name contains 'APPLE' AND expiration < "2031-01-01" AND currency = 'USD'`))
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, []domain.Product{patched}, webtest.Data[[]domain.Product](response))
+	response = client.Get("/api/v1/products?status=draft,published")
+	assert.Empty(t, webtest.Data[[]domain.Product](response))
+	response = client.Get("/api/v1/products?filter=" + url.QueryEscape("NOT (price > 300 OR quantity = 10)"))
+	assert.Empty(t, webtest.Data[[]domain.Product](response))
+
+	// Delete, and the ID of the deleted product is never reused
+	assert.Equal(t, http.StatusNoContent, client.Delete("/api/v1/products/1").Code)
+	assert.Equal(t, http.StatusNotFound, client.Get("/api/v1/products/1").Code)
+
+	response = client.Post("/api/v1/products", pineapple)
+	assert.Equal(t, http.StatusCreated, response.Code)
+	assert.Equal(t, 2, webtest.Data[domain.Product](response).Id)
+}
+
+func TestPostgres_Migrations(t *testing.T) {
+	db := startPostgres(t)
+
+	// Assertions: the migrations were applied by startPostgres, so a second run applies nothing
+	applied, err := migrate.Up(db, product.Migrations())
+	assert.NoError(t, err)
+	assert.Empty(t, applied)
+}